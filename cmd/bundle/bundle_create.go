@@ -0,0 +1,146 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// Define the `ec bundle create` command
+package bundle
+
+import (
+	"fmt"
+
+	hd "github.com/MakeNowJust/heredoc"
+	"github.com/spf13/cobra"
+
+	"github.com/enterprise-contract/ec-cli/internal/policy"
+	"github.com/enterprise-contract/ec-cli/internal/policy/source"
+	"github.com/enterprise-contract/ec-cli/internal/utils"
+	validate_utils "github.com/enterprise-contract/ec-cli/internal/validate"
+)
+
+func bundleCreateCmd() *cobra.Command {
+	var (
+		policyConfiguration string
+		publicKeys          []string
+		output              string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "create --policy <policy> --output <path>",
+		Short: "Package the sources of a policy into a bundle",
+
+		Long: hd.Doc(`
+			Package the sources of a policy into a bundle.
+
+			The policy configuration, all of its policy and data sources, and the given
+			public keys are packaged into a single gzip compressed tarball, along with
+			the digests of the content of the sources.
+
+			Use the bundle with ec bundle use to validate without network access to
+			the policy sources, e.g. in air-gapped environments.
+		`),
+
+		Example: hd.Doc(`
+			Package the sources of a policy configuration file and the public key the
+			images are signed with:
+
+			  ec bundle create --policy policy.yaml --public-key cosign.pub \
+			    --output bundle.tar.gz
+		`),
+
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			fs := utils.FS(cmd.Context())
+
+			staging, err := utils.CreateWorkDir(fs)
+			if err != nil {
+				return err
+			}
+			defer utils.CleanupWorkDir(fs, staging)
+
+			bundle := source.NewBundleWriter(staging)
+			ctx := source.WithBundleWriter(cmd.Context(), bundle)
+
+			config, err := validate_utils.GetPolicyConfig(ctx, policyConfiguration)
+			if err != nil {
+				return err
+			}
+
+			p, err := policy.NewInertPolicy(ctx, config)
+			if err != nil {
+				return err
+			}
+
+			workDir, err := utils.CreateWorkDir(fs)
+			if err != nil {
+				return err
+			}
+			defer utils.CleanupWorkDir(fs, workDir)
+
+			for _, s := range p.Spec().Sources {
+				urls := make([]*source.PolicyUrl, 0, len(s.Policy)+len(s.Data))
+				for _, url := range s.Policy {
+					urls = append(urls, &source.PolicyUrl{Url: url, Kind: source.PolicyKind})
+				}
+				for _, url := range s.Data {
+					urls = append(urls, &source.PolicyUrl{Url: url, Kind: source.DataKind})
+				}
+
+				for _, u := range urls {
+					if _, err := u.GetPolicy(ctx, workDir, false); err != nil {
+						return fmt.Errorf("fetching the source %s: %w", u.Url, err)
+					}
+				}
+			}
+
+			if err := bundle.AddPolicy(fs, []byte(config)); err != nil {
+				return fmt.Errorf("adding the policy configuration to the bundle: %w", err)
+			}
+
+			for _, key := range publicKeys {
+				if err := bundle.AddKey(fs, key); err != nil {
+					return fmt.Errorf("adding the public key %s to the bundle: %w", key, err)
+				}
+			}
+
+			manifest, err := bundle.Write(fs, output)
+			if err != nil {
+				return fmt.Errorf("writing the bundle: %w", err)
+			}
+
+			fmt.Fprintf(cmd.OutOrStdout(), "Bundled %d source(s) and %d key(s) in %s\n", len(manifest.Sources), len(manifest.Keys), output)
+
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&policyConfiguration, "policy", "p", policyConfiguration, hd.Doc(`
+		Policy configuration as:
+		  * Kubernetes reference ([<namespace>/]<name>)
+		  * file (policy.yaml)
+		  * git reference (github.com/user/repo//default?ref=main), or
+		  * inline JSON ('{sources: {...}, identity: {...}}')`))
+
+	cmd.Flags().StringArrayVar(&publicKeys, "public-key", publicKeys, "path to a public key file to include in the bundle, can be repeated")
+
+	cmd.Flags().StringVarP(&output, "output", "o", output, "path to write the bundle to")
+
+	for _, f := range []string{"policy", "output"} {
+		if err := cmd.MarkFlagRequired(f); err != nil {
+			panic(err)
+		}
+	}
+
+	return cmd
+}
@@ -0,0 +1,107 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package bundle
+
+import (
+	"bytes"
+	"context"
+	"path"
+	"testing"
+
+	"github.com/enterprise-contract/go-gather/metadata"
+	ociMetadata "github.com/enterprise-contract/go-gather/metadata/oci"
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/enterprise-contract/ec-cli/cmd/root"
+	"github.com/enterprise-contract/ec-cli/internal/policy/source"
+	"github.com/enterprise-contract/ec-cli/internal/utils"
+)
+
+type fakeDownloader struct {
+	fs afero.Fs
+}
+
+func (d fakeDownloader) Download(_ context.Context, dest string, sourceUrl string, _ bool) (metadata.Metadata, error) {
+	if err := d.fs.MkdirAll(dest, 0755); err != nil {
+		return nil, err
+	}
+	return &ociMetadata.OCIMetadata{Digest: "sha256:4e388ab32b10dc8dbc7e28144f552830adc74787c1e2c0824032078a79f227fb"},
+		afero.WriteFile(d.fs, path.Join(dest, "policy.rego"), []byte("package "+path.Base(sourceUrl)), 0400)
+}
+
+func TestBundleCreateAndUse(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", "/cache")
+
+	fs := afero.NewMemMapFs()
+	ctx := utils.WithFS(context.Background(), fs)
+	ctx = context.WithValue(ctx, source.DownloaderFuncKey, fakeDownloader{fs})
+	require.NoError(t, afero.WriteFile(fs, "/keys/cosign.pub", []byte("-----BEGIN PUBLIC KEY-----"), 0o644))
+
+	policy := `{"sources": [{"policy": ["oci::registry.io/bundle/release:1"], "data": ["oci::registry.io/bundle/data:1"]}]}`
+
+	create := setUpCobra(bundleCreateCmd())
+	create.SetContext(ctx)
+	stdout := bytes.Buffer{}
+	create.SetOut(&stdout)
+	create.SetArgs([]string{"bundle", "create", "--policy", policy, "--public-key", "/keys/cosign.pub", "--output", "bundle.tar.gz"})
+
+	require.NoError(t, create.Execute())
+	assert.Equal(t, "Bundled 2 source(s) and 1 key(s) in bundle.tar.gz\n", stdout.String())
+
+	use := setUpCobra(bundleUseCmd())
+	use.SetContext(ctx)
+	stdout.Reset()
+	use.SetOut(&stdout)
+	use.SetArgs([]string{"bundle", "use", "bundle.tar.gz"})
+
+	require.NoError(t, use.Execute())
+	assert.Equal(t, `Using the bundle with 2 source(s) in /cache/ec/bundle
+Policy configuration: /cache/ec/bundle/policy.yaml
+Public key: /cache/ec/bundle/keys/cosign.pub
+`, stdout.String())
+
+	config, err := afero.ReadFile(fs, "/cache/ec/bundle/policy.yaml")
+	require.NoError(t, err)
+	assert.Equal(t, policy, string(config))
+
+	key, err := afero.ReadFile(fs, "/cache/ec/bundle/keys/cosign.pub")
+	require.NoError(t, err)
+	assert.Equal(t, "-----BEGIN PUBLIC KEY-----", string(key))
+}
+
+func TestBundleUseMissing(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", "/cache")
+
+	cmd := setUpCobra(bundleUseCmd())
+	cmd.SetContext(utils.WithFS(context.Background(), afero.NewMemMapFs()))
+	cmd.SetArgs([]string{"bundle", "use", "missing.tar.gz"})
+
+	assert.ErrorContains(t, cmd.Execute(), "reading the bundle missing.tar.gz")
+}
+
+func setUpCobra(command *cobra.Command) *cobra.Command {
+	bundleCmd := NewBundleCmd()
+	bundleCmd.AddCommand(command)
+	cmd := root.NewRootCmd()
+	cmd.AddCommand(bundleCmd)
+	return cmd
+}
@@ -0,0 +1,83 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// Define the `ec bundle use` command
+package bundle
+
+import (
+	"fmt"
+	"path/filepath"
+
+	hd "github.com/MakeNowJust/heredoc"
+	"github.com/spf13/cobra"
+
+	"github.com/enterprise-contract/ec-cli/internal/policy/source"
+	"github.com/enterprise-contract/ec-cli/internal/utils"
+)
+
+func bundleUseCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "use <path>",
+		Short: "Use a bundle as the source of policies when offline",
+
+		Long: hd.Doc(`
+			Use a bundle as the source of policies when offline.
+
+			The bundle, as created by ec bundle create, is verified and installed in
+			$XDG_CACHE_HOME/ec/bundle, replacing any bundle previously in use. The
+			validation fails if the content of any source differs from the digest
+			recorded in the bundle.
+
+			With the --offline flag the policy configuration and the policy and data
+			sources are then fetched exclusively from the bundle, and fetching any
+			source not in the bundle fails. The policy configuration of the bundle is
+			used by ec validate image when the --policy flag is not given, and the
+			public keys of the bundle can be given to the --public-key flag from the
+			bundle directory.
+		`),
+
+		Example: hd.Doc(`
+			Use a bundle and validate an image offline with the policy and the public
+			key from the bundle:
+
+			  ec bundle use bundle.tar.gz
+
+			  ec validate image --offline --image registry/name:tag \
+			    --public-key $XDG_CACHE_HOME/ec/bundle/keys/cosign.pub
+		`),
+
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			dir, manifest, err := source.UseBundle(utils.FS(cmd.Context()), args[0])
+			if err != nil {
+				return err
+			}
+
+			out := cmd.OutOrStdout()
+			fmt.Fprintf(out, "Using the bundle with %d source(s) in %s\n", len(manifest.Sources), dir)
+			if manifest.Policy != "" {
+				fmt.Fprintf(out, "Policy configuration: %s\n", filepath.Join(dir, filepath.FromSlash(manifest.Policy)))
+			}
+			for _, key := range manifest.Keys {
+				fmt.Fprintf(out, "Public key: %s\n", filepath.Join(dir, filepath.FromSlash(key)))
+			}
+
+			return nil
+		},
+	}
+
+	return cmd
+}
@@ -0,0 +1,63 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// Define the `ec cache clean` command
+package cache
+
+import (
+	"fmt"
+
+	hd "github.com/MakeNowJust/heredoc"
+	"github.com/spf13/cobra"
+
+	"github.com/enterprise-contract/ec-cli/internal/policy/source"
+	"github.com/enterprise-contract/ec-cli/internal/utils"
+)
+
+func cacheCleanCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "clean",
+		Short: "Remove all policy sources from the persistent cache",
+
+		Long: hd.Doc(`
+			Remove all policy sources from the persistent cache.
+
+			Git and OCI policy sources are kept in the persistent cache, within
+			$XDG_CACHE_HOME/ec/sources, when the --cache-ttl flag is set. The sources
+			are fetched again on the next use.
+		`),
+
+		Example: hd.Doc(`
+			Remove the cached policy sources:
+
+			  ec cache clean
+		`),
+
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			dir, err := source.CleanCache(utils.FS(cmd.Context()))
+			if err != nil {
+				return fmt.Errorf("unable to clean the persistent cache: %w", err)
+			}
+
+			fmt.Fprintf(cmd.OutOrStdout(), "Removed the persistent cache in %s\n", dir)
+
+			return nil
+		},
+	}
+
+	return cmd
+}
@@ -0,0 +1,66 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package cache
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/enterprise-contract/ec-cli/cmd/root"
+	"github.com/enterprise-contract/ec-cli/internal/utils"
+)
+
+func TestCacheClean(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", "/cache")
+	fs := afero.NewMemMapFs()
+	ctx := utils.WithFS(context.Background(), fs)
+
+	require.NoError(t, afero.WriteFile(fs, "/cache/ec/sources/abc/entry.json", []byte("{}"), 0644))
+	require.NoError(t, afero.WriteFile(fs, "/cache/other/file", []byte("keep"), 0644))
+
+	cmd := setUpCobra(cacheCleanCmd())
+	cmd.SetContext(ctx)
+	stdout := bytes.Buffer{}
+	cmd.SetOut(&stdout)
+	cmd.SetArgs([]string{"cache", "clean"})
+
+	require.NoError(t, cmd.Execute())
+
+	assert.Equal(t, "Removed the persistent cache in /cache/ec/sources\n", stdout.String())
+	exists, err := afero.DirExists(fs, "/cache/ec/sources")
+	require.NoError(t, err)
+	assert.False(t, exists)
+	exists, err = afero.Exists(fs, "/cache/other/file")
+	require.NoError(t, err)
+	assert.True(t, exists)
+}
+
+func setUpCobra(command *cobra.Command) *cobra.Command {
+	cacheCmd := NewCacheCmd()
+	cacheCmd.AddCommand(command)
+	cmd := root.NewRootCmd()
+	cmd.AddCommand(cacheCmd)
+	return cmd
+}
@@ -0,0 +1,127 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// Define the `ec config migrate` command
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+
+	hd "github.com/MakeNowJust/heredoc"
+	ecc "github.com/enterprise-contract/enterprise-contract-controller/api/v1alpha1"
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/yaml"
+
+	"github.com/enterprise-contract/ec-cli/internal/policy"
+	"github.com/enterprise-contract/ec-cli/internal/utils"
+)
+
+func configMigrateCmd() *cobra.Command {
+	var outputFile string
+
+	cmd := &cobra.Command{
+		Use:   "migrate <file>",
+		Short: "Update a policy configuration to the current schema",
+
+		Long: hd.Doc(`
+			Update a policy configuration using deprecated fields to the current schema.
+
+			The file can hold either an EnterpriseContractPolicy Kubernetes custom
+			resource, or the spec part of it, in YAML or JSON. The updated policy
+			configuration is written as YAML, and a description of each change made is
+			written to standard error.
+
+			The top level configuration is deprecated. It applies only to the sources
+			without include or exclude criteria of their own, so its include and exclude
+			criteria are moved to the config of each such source. Collections are
+			converted to include entries with the "@" prefix.
+		`),
+
+		Example: hd.Doc(`
+			Print the updated policy configuration:
+
+			  ec config migrate policy.yaml
+
+			Write the updated policy configuration to a file:
+
+			  ec config migrate policy.yaml --output new-policy.yaml
+		`),
+
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			fs := utils.FS(cmd.Context())
+
+			data, err := afero.ReadFile(fs, args[0])
+			if err != nil {
+				return err
+			}
+
+			// The Kubernetes custom resource is recognized by its kind, anything
+			// else is assumed to be the spec. Fields of the custom resource other
+			// than the spec are kept as they are.
+			var resource map[string]any
+			if err := yaml.Unmarshal(data, &resource); err != nil {
+				return fmt.Errorf("unable to parse %s: %w", args[0], err)
+			}
+
+			specData := data
+			isResource := resource["kind"] == "EnterpriseContractPolicy"
+			if isResource {
+				if specData, err = json.Marshal(resource["spec"]); err != nil {
+					return err
+				}
+			}
+
+			var spec ecc.EnterpriseContractPolicySpec
+			if err := yaml.UnmarshalStrict(specData, &spec); err != nil {
+				return fmt.Errorf("unable to parse %s: %w", args[0], err)
+			}
+
+			spec, changes := policy.Migrate(spec)
+
+			var out any = spec
+			if isResource {
+				resource["spec"] = spec
+				out = resource
+			}
+
+			migrated, err := yaml.Marshal(out)
+			if err != nil {
+				return err
+			}
+
+			if len(changes) == 0 {
+				fmt.Fprintln(cmd.ErrOrStderr(), "No changes needed")
+			}
+			for _, change := range changes {
+				fmt.Fprintf(cmd.ErrOrStderr(), "* %s\n", change)
+			}
+
+			if outputFile == "" {
+				_, err = cmd.OutOrStdout().Write(migrated)
+				return err
+			}
+
+			return afero.WriteFile(fs, outputFile, migrated, 0644)
+		},
+	}
+
+	cmd.Flags().StringVarP(&outputFile, "output", "o", "", "file to write the updated policy configuration to. If not specified stdout will be used.")
+
+	return cmd
+}
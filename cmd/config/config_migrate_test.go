@@ -0,0 +1,161 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package config
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	hd "github.com/MakeNowJust/heredoc"
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/enterprise-contract/ec-cli/cmd/root"
+	"github.com/enterprise-contract/ec-cli/internal/utils"
+)
+
+func TestMigrateResource(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	ctx := utils.WithFS(context.Background(), fs)
+
+	require.NoError(t, afero.WriteFile(fs, "policy.yaml", []byte(hd.Doc(`
+		apiVersion: appstudio.redhat.com/v1alpha1
+		kind: EnterpriseContractPolicy
+		metadata:
+		  name: legacy
+		spec:
+		  publicKey: k8s://tekton-chains/public-key
+		  sources:
+		    - policy:
+		        - oci::quay.io/enterprise-contract/ec-release-policy:latest
+		  configuration:
+		    collections:
+		      - minimal
+		    exclude:
+		      - test
+	`)), 0644))
+
+	cmd := setUpCobra(configMigrateCmd())
+	cmd.SetContext(ctx)
+	stdout := bytes.Buffer{}
+	stderr := bytes.Buffer{}
+	cmd.SetOut(&stdout)
+	cmd.SetErr(&stderr)
+	cmd.SetArgs([]string{"config", "migrate", "policy.yaml"})
+
+	require.NoError(t, cmd.Execute())
+
+	assert.Equal(t, hd.Doc(`
+		apiVersion: appstudio.redhat.com/v1alpha1
+		kind: EnterpriseContractPolicy
+		metadata:
+		  name: legacy
+		spec:
+		  publicKey: k8s://tekton-chains/public-key
+		  sources:
+		  - config:
+		      exclude:
+		      - test
+		      include:
+		      - '@minimal'
+		    policy:
+		    - oci::quay.io/enterprise-contract/ec-release-policy:latest
+	`), stdout.String())
+
+	assert.Equal(t, hd.Doc(`
+		* collection "minimal" converted to include "@minimal"
+		* removed the deprecated configuration
+		* moved the include and exclude criteria of the configuration to the config of source #0
+	`), stderr.String())
+}
+
+func TestMigrateSpecToFile(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	ctx := utils.WithFS(context.Background(), fs)
+
+	require.NoError(t, afero.WriteFile(fs, "policy.json", []byte(`{
+		"sources": [{"name": "default", "policy": ["github.com/org/policy"]}],
+		"configuration": {"include": ["@redhat"]}
+	}`), 0644))
+
+	cmd := setUpCobra(configMigrateCmd())
+	cmd.SetContext(ctx)
+	stdout := bytes.Buffer{}
+	cmd.SetOut(&stdout)
+	cmd.SetErr(&bytes.Buffer{})
+	cmd.SetArgs([]string{"config", "migrate", "policy.json", "--output", "new-policy.yaml"})
+
+	require.NoError(t, cmd.Execute())
+	assert.Empty(t, stdout.String())
+
+	migrated, err := afero.ReadFile(fs, "new-policy.yaml")
+	require.NoError(t, err)
+	assert.Equal(t, hd.Doc(`
+		sources:
+		- config:
+		    include:
+		    - '@redhat'
+		  name: default
+		  policy:
+		  - github.com/org/policy
+	`), string(migrated))
+}
+
+func TestMigrateNoChanges(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	ctx := utils.WithFS(context.Background(), fs)
+
+	require.NoError(t, afero.WriteFile(fs, "policy.yaml", []byte("sources: [{policy: [github.com/org/policy]}]"), 0644))
+
+	cmd := setUpCobra(configMigrateCmd())
+	cmd.SetContext(ctx)
+	stderr := bytes.Buffer{}
+	cmd.SetOut(&bytes.Buffer{})
+	cmd.SetErr(&stderr)
+	cmd.SetArgs([]string{"config", "migrate", "policy.yaml"})
+
+	require.NoError(t, cmd.Execute())
+	assert.Equal(t, "No changes needed\n", stderr.String())
+}
+
+func TestMigrateUnknownField(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	ctx := utils.WithFS(context.Background(), fs)
+
+	require.NoError(t, afero.WriteFile(fs, "policy.yaml", []byte("spam: eggs"), 0644))
+
+	cmd := setUpCobra(configMigrateCmd())
+	cmd.SetContext(ctx)
+	cmd.SetOut(&bytes.Buffer{})
+	cmd.SetErr(&bytes.Buffer{})
+	cmd.SetArgs([]string{"config", "migrate", "policy.yaml"})
+
+	assert.ErrorContains(t, cmd.Execute(), `unable to parse policy.yaml`)
+}
+
+func setUpCobra(command *cobra.Command) *cobra.Command {
+	configCmd := NewConfigCmd()
+	configCmd.AddCommand(command)
+	cmd := root.NewRootCmd()
+	cmd.AddCommand(configCmd)
+	return cmd
+}
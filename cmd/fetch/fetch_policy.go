@@ -18,32 +18,44 @@
 package fetch
 
 import (
+	"fmt"
+
 	hd "github.com/MakeNowJust/heredoc"
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/afero"
 	"github.com/spf13/cobra"
 
+	"github.com/enterprise-contract/ec-cli/internal/logging"
+	"github.com/enterprise-contract/ec-cli/internal/policy"
 	"github.com/enterprise-contract/ec-cli/internal/policy/source"
 	"github.com/enterprise-contract/ec-cli/internal/utils"
+	validate_utils "github.com/enterprise-contract/ec-cli/internal/validate"
 )
 
 func fetchPolicyCmd() *cobra.Command {
 	var (
-		sourceUrls     []string
-		dataSourceUrls []string
-		destDir        string
-		useWorkDir     bool
+		policyConfiguration string
+		sourceUrls          []string
+		dataSourceUrls      []string
+		destDir             string
+		useWorkDir          bool
 	)
 
 	cmd := &cobra.Command{
-		Use:   "policy --source <source-url> --data-source <source-url>",
+		Use:   "policy --source <source-url> --data-source <source-url> | --policy <policy>",
 		Short: "Fetch policy rules from a git repository or other source",
 
 		Long: hd.Doc(`
 			Fetch policy rules (rego files) from a git repository or other source.
 
-			Each policy source will be downloaded into a separate unique directory inside
-			the "policy" directory under the destination directory specified. The
+			The sources are given by the --source and --data-source flags, or are all of
+			the policy and data sources of the policy configuration given by the --policy
+			flag. Each policy source will be downloaded into a separate unique directory
+			inside the "policy" directory under the destination directory specified, and
+			each data source inside the "data" directory, the same layout used when
+			validating. The directory, the revision the source was fetched at, i.e. the
+			git commit or the image digest, and the digest of the content of each source
+			are printed once fetched. The
 			destination directory is either an automatically generated temporary work dir
 			if --work-dir is set, the directory specified with the --dest flag, or the
 			current directory if neither flag is specified.
@@ -87,6 +99,11 @@ func fetchPolicyCmd() *cobra.Command {
 
 			  ec fetch policy --source quay.io/enterprise-contract/ec-release-policy:latest
 
+			Fetching all of the policy and data sources of a policy configuration file to
+			a specific directory:
+
+			  ec fetch policy --policy policy.yaml --dest fetched-policies
+
 			Notes:
 
 			- The --dest flag will be ignored if --work-dir is set
@@ -107,6 +124,27 @@ func fetchPolicyCmd() *cobra.Command {
 
 			sources := make([]*source.PolicyUrl, 0, len(sourceUrls)+len(dataSourceUrls))
 
+			if policyConfiguration != "" {
+				config, err := validate_utils.GetPolicyConfig(cmd.Context(), policyConfiguration)
+				if err != nil {
+					return err
+				}
+
+				p, err := policy.NewInertPolicy(cmd.Context(), config)
+				if err != nil {
+					return err
+				}
+
+				for _, s := range p.Spec().Sources {
+					for _, url := range s.Policy {
+						sources = append(sources, &source.PolicyUrl{Url: url, Kind: source.PolicyKind})
+					}
+					for _, url := range s.Data {
+						sources = append(sources, &source.PolicyUrl{Url: url, Kind: source.DataKind})
+					}
+				}
+			}
+
 			for _, url := range sourceUrls {
 				sources = append(sources, &source.PolicyUrl{Url: url, Kind: source.PolicyKind})
 			}
@@ -115,25 +153,49 @@ func fetchPolicyCmd() *cobra.Command {
 				sources = append(sources, &source.PolicyUrl{Url: url, Kind: source.DataKind})
 			}
 
+			// The lockfile records the revision and the digest of each source
+			lockfile := source.NewLockfileUpdate()
+			ctx := source.WithLockfile(cmd.Context(), lockfile)
+
+			out := cmd.OutOrStdout()
 			for _, s := range sources {
-				_, err := s.GetPolicy(cmd.Context(), destDir, true)
+				// Remote OPA servers evaluate the rules themselves, there is
+				// nothing to fetch
+				if _, ok := source.RemoteOPAServer(s.Url); ok {
+					log.Debugf("Skipping the remote OPA server %s", logging.RedactURL(s.Url))
+					continue
+				}
+
+				dir, err := s.GetPolicy(ctx, destDir, true)
 				if err != nil {
 					return err
 				}
+
+				fmt.Fprintf(out, "%s source %s\n  path: %s\n", s.Kind, logging.RedactCredentials(s.Url), dir)
+				if locked, ok := lockfile.Find(s.Url); ok {
+					if locked.Revision != "" {
+						fmt.Fprintf(out, "  revision: %s\n", locked.Revision)
+					}
+					fmt.Fprintf(out, "  digest: %s\n", locked.Digest)
+				}
 			}
 
 			return nil
 		},
 	}
 
+	cmd.Flags().StringVarP(&policyConfiguration, "policy", "p", policyConfiguration, hd.Doc(`
+		Policy configuration to fetch all of the policy and data sources of, as:
+		  * Kubernetes reference ([<namespace>/]<name>)
+		  * file (policy.yaml)
+		  * git reference (github.com/user/repo//default?ref=main), or
+		  * inline JSON ('{sources: {...}, identity: {...}}')`))
 	cmd.Flags().StringArrayVarP(&sourceUrls, "source", "s", []string{}, "policy source url. multiple values are allowed")
 	cmd.Flags().StringArrayVar(&dataSourceUrls, "data-source", []string{}, "data source url. multiple values are allowed")
 	cmd.Flags().StringVarP(&destDir, "dest", "d", ".", "use the specified download destination directory. ignored if --work-dir is set")
 	cmd.Flags().BoolVarP(&useWorkDir, "work-dir", "w", false, "use a temporary work dir as the download destination directory")
 
-	if err := cmd.MarkFlagRequired("source"); err != nil {
-		panic(err)
-	}
+	cmd.MarkFlagsOneRequired("policy", "source")
 
 	return cmd
 }
@@ -0,0 +1,91 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package fetch
+
+import (
+	"bytes"
+	"context"
+	"path"
+	"testing"
+
+	"github.com/enterprise-contract/go-gather/metadata"
+	ociMetadata "github.com/enterprise-contract/go-gather/metadata/oci"
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/enterprise-contract/ec-cli/cmd/root"
+	"github.com/enterprise-contract/ec-cli/internal/policy/source"
+	"github.com/enterprise-contract/ec-cli/internal/utils"
+)
+
+type fakeDownloader struct {
+	fs afero.Fs
+}
+
+func (d fakeDownloader) Download(_ context.Context, dest string, sourceUrl string, _ bool) (metadata.Metadata, error) {
+	if err := d.fs.MkdirAll(dest, 0755); err != nil {
+		return nil, err
+	}
+	return &ociMetadata.OCIMetadata{Digest: "sha256:4e388ab32b10dc8dbc7e28144f552830adc74787c1e2c0824032078a79f227fb"},
+		afero.WriteFile(d.fs, path.Join(dest, "policy.rego"), []byte("package "+path.Base(sourceUrl)), 0400)
+}
+
+func TestFetchPolicyConfiguration(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	ctx := utils.WithFS(context.Background(), fs)
+	ctx = context.WithValue(ctx, source.DownloaderFuncKey, fakeDownloader{fs})
+
+	cmd := setUpCobra(fetchPolicyCmd())
+	cmd.SetContext(ctx)
+	stdout := bytes.Buffer{}
+	cmd.SetOut(&stdout)
+	cmd.SetArgs([]string{"fetch", "policy", "--dest", "/fetched", "--policy",
+		`{"sources": [{"policy": ["oci::registry.io/fetch/release:1"], "data": ["oci::registry.io/fetch/data:1"]}]}`})
+
+	require.NoError(t, cmd.Execute())
+
+	digest := `sha256:[0-9a-f]{64}`
+	assert.Regexp(t, `^policy source oci::registry.io/fetch/release:1
+  path: /fetched/policy/\w+
+  revision: sha256:4e388ab32b10dc8dbc7e28144f552830adc74787c1e2c0824032078a79f227fb
+  digest: `+digest+`
+data source oci::registry.io/fetch/data:1
+  path: /fetched/data/\w+
+  revision: sha256:4e388ab32b10dc8dbc7e28144f552830adc74787c1e2c0824032078a79f227fb
+  digest: `+digest+`
+$`, stdout.String())
+}
+
+func TestFetchPolicyRequiresSources(t *testing.T) {
+	cmd := setUpCobra(fetchPolicyCmd())
+	cmd.SetContext(utils.WithFS(context.Background(), afero.NewMemMapFs()))
+	cmd.SetArgs([]string{"fetch", "policy"})
+
+	assert.ErrorContains(t, cmd.Execute(), "at least one of the flags in the group [policy source] is required")
+}
+
+func setUpCobra(command *cobra.Command) *cobra.Command {
+	fetchCmd := NewFetchCmd()
+	fetchCmd.AddCommand(command)
+	cmd := root.NewRootCmd()
+	cmd.AddCommand(fetchCmd)
+	return cmd
+}
@@ -63,6 +63,13 @@ func inspectPolicyCmd() *cobra.Command {
 			including the rule annotations which include the rule's title and description
 			and custom fields used by ec to filter the results produced by conftest.
 
+			The command fails if two or more rules share the same code, listing the
+			location of each definition, as the results of such rules can not be told
+			apart.
+
+			A warning is logged for each use of a deprecated rego built-in function, or of
+			deprecated data, within the policy, along with its location.
+
 			Note that this command is not typically required to verify the Enterprise
 			Contract. It has been made available for troubleshooting and debugging purposes.
 		`),
@@ -132,10 +139,23 @@ func inspectPolicyCmd() *cobra.Command {
 					return err
 				}
 
+				// Warn about uses of deprecated built-ins and data
+				deprecations, err := opa.CheckDeprecations(fs, policyDir)
+				if err != nil {
+					return err
+				}
+				for _, d := range deprecations {
+					log.Warnf("Deprecated usage in %s at %s", s.PolicyUrl(), d)
+				}
+
 				// Collect results
 				allResults[s.PolicyUrl()] = result
 			}
 
+			if err := opa.CheckDuplicateCodes(allResults); err != nil {
+				return err
+			}
+
 			var err error
 			allResults, err = filterResults(allResults, ruleFilter, packageFilter, collectionFilter)
 			if err != nil {
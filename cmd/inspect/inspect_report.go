@@ -0,0 +1,99 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// Define the `ec inspect report` command
+package inspect
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	hd "github.com/MakeNowJust/heredoc"
+	"github.com/spf13/cobra"
+	"golang.org/x/exp/slices"
+	"sigs.k8s.io/yaml"
+
+	"github.com/enterprise-contract/ec-cli/internal/applicationsnapshot"
+)
+
+func inspectReportCmd() *cobra.Command {
+	var (
+		imageRef     string
+		outputFormat string
+	)
+
+	validFormats := []string{"json", "yaml"}
+
+	cmd := &cobra.Command{
+		Use:   "report --image <image>",
+		Short: "Display the latest validation report pushed for an image",
+
+		Long: hd.Doc(`
+			Display the latest validation report pushed for an image.
+
+			Reports are pushed to the registry by ec validate image with the oci output
+			target, e.g. --output oci=<image>, as artifacts referring to the image. This
+			fetches the most recent of them via the Referrers API.
+		`),
+
+		Example: hd.Doc(`
+			Print the latest report of an image:
+
+			ec inspect report --image registry.io/org/image@sha256:...
+		`),
+
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if !slices.Contains(validFormats, outputFormat) {
+				return fmt.Errorf("invalid value for --output '%s'. accepted values: %s", outputFormat, strings.Join(validFormats, ", "))
+			}
+
+			report, err := applicationsnapshot.FetchReport(cmd.Context(), imageRef)
+			if err != nil {
+				return err
+			}
+
+			out := cmd.OutOrStdout()
+			if outputFormat == "yaml" {
+				yamlOutput, err := yaml.JSONToYAML(report)
+				if err != nil {
+					return err
+				}
+				_, err = out.Write(yamlOutput)
+				return err
+			}
+
+			var buf bytes.Buffer
+			if err := json.Indent(&buf, report, "", "  "); err != nil {
+				return err
+			}
+			buf.WriteByte('\n')
+			_, err = buf.WriteTo(out)
+			return err
+		},
+	}
+
+	cmd.Flags().StringVarP(&imageRef, "image", "i", "", "OCI image reference")
+	cmd.Flags().StringVarP(&outputFormat, "output", "o", "json", fmt.Sprintf("output format. one of: %s", strings.Join(validFormats, ", ")))
+
+	if err := cmd.MarkFlagRequired("image"); err != nil {
+		panic(err)
+	}
+
+	return cmd
+}
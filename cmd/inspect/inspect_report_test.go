@@ -0,0 +1,66 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package inspect
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/enterprise-contract/ec-cli/internal/applicationsnapshot"
+)
+
+func TestInspectReport(t *testing.T) {
+	registry := httptest.NewServer(registry.New(registry.Logger(log.New(io.Discard, "", 0))))
+	t.Cleanup(registry.Close)
+
+	u, err := url.Parse(registry.URL)
+	require.NoError(t, err)
+
+	image := fmt.Sprintf("localhost:%s/repository/image:tag", u.Port())
+	ref, err := name.ParseReference(image)
+	require.NoError(t, err)
+	img, err := random.Image(1024, 1)
+	require.NoError(t, err)
+	require.NoError(t, remote.Push(ref, img))
+
+	_, err = applicationsnapshot.PushReport(context.Background(), image, []byte(`{"success":true}`))
+	require.NoError(t, err)
+
+	cmd := setUpCobra(inspectReportCmd())
+	cmd.SetContext(context.Background())
+	buffy := bytes.Buffer{}
+	cmd.SetOut(&buffy)
+	cmd.SetArgs([]string{"inspect", "report", "--image", image, "--output", "yaml"})
+
+	require.NoError(t, cmd.Execute())
+	assert.Equal(t, "success: true\n", buffy.String())
+}
@@ -0,0 +1,131 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// Define the `ec policy lock` command
+package policy
+
+import (
+	"fmt"
+
+	hd "github.com/MakeNowJust/heredoc"
+	"github.com/spf13/cobra"
+
+	"github.com/enterprise-contract/ec-cli/internal/policy"
+	"github.com/enterprise-contract/ec-cli/internal/policy/source"
+	"github.com/enterprise-contract/ec-cli/internal/utils"
+	validate_utils "github.com/enterprise-contract/ec-cli/internal/validate"
+)
+
+func policyLockCmd() *cobra.Command {
+	var (
+		policyConfiguration string
+		lockfilePath        string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "lock --policy <policy> --lockfile <path>",
+		Short: "Write a lockfile pinning the sources of a policy",
+
+		Long: hd.Doc(`
+			Write a lockfile pinning the sources of a policy.
+
+			All of the policy and data sources of the policy configuration, and the
+			policy configuration itself when fetched from a git repository, are fetched
+			and the digest of the content of each is written to the lockfile, along with
+			the git commit or the image digest the source was fetched at.
+
+			Use the lockfile with the --lockfile flag of ec validate image to evaluate
+			the policy reproducibly. The sources are then fetched as pinned by the
+			lockfile, OCI sources by their image digest, and the validation fails if the
+			content of any source differs from the lockfile.
+		`),
+
+		Example: hd.Doc(`
+			Pin the sources of a policy configuration file:
+
+			  ec policy lock --policy policy.yaml --lockfile policy.lock.yaml
+
+			Use the lockfile when validating an image:
+
+			  ec validate image --image registry/name:tag --policy policy.yaml \
+			    --lockfile policy.lock.yaml
+		`),
+
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			lockfile := source.NewLockfileUpdate()
+			ctx := source.WithLockfile(cmd.Context(), lockfile)
+			fs := utils.FS(ctx)
+
+			config, err := validate_utils.GetPolicyConfig(ctx, policyConfiguration)
+			if err != nil {
+				return err
+			}
+
+			p, err := policy.NewInertPolicy(ctx, config)
+			if err != nil {
+				return err
+			}
+
+			workDir, err := utils.CreateWorkDir(fs)
+			if err != nil {
+				return err
+			}
+			defer utils.CleanupWorkDir(fs, workDir)
+
+			for _, s := range p.Spec().Sources {
+				urls := make([]*source.PolicyUrl, 0, len(s.Policy)+len(s.Data))
+				for _, url := range s.Policy {
+					urls = append(urls, &source.PolicyUrl{Url: url, Kind: source.PolicyKind})
+				}
+				for _, url := range s.Data {
+					urls = append(urls, &source.PolicyUrl{Url: url, Kind: source.DataKind})
+				}
+
+				for _, u := range urls {
+					if _, err := u.GetPolicy(ctx, workDir, false); err != nil {
+						return fmt.Errorf("fetching the source %s: %w", u.Url, err)
+					}
+				}
+			}
+
+			if err := lockfile.Write(fs, lockfilePath); err != nil {
+				return fmt.Errorf("writing the lockfile: %w", err)
+			}
+
+			fmt.Fprintf(cmd.OutOrStdout(), "Locked %d source(s) in %s\n", len(lockfile.Sources), lockfilePath)
+
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&policyConfiguration, "policy", "p", policyConfiguration, hd.Doc(`
+		Policy configuration as:
+		  * Kubernetes reference ([<namespace>/]<name>)
+		  * file (policy.yaml)
+		  * git reference (github.com/user/repo//default?ref=main), or
+		  * inline JSON ('{sources: {...}, identity: {...}}')`))
+
+	cmd.Flags().StringVar(&lockfilePath, "lockfile", lockfilePath, "path to write the lockfile to")
+
+	for _, f := range []string{"policy", "lockfile"} {
+		if err := cmd.MarkFlagRequired(f); err != nil {
+			panic(err)
+		}
+	}
+
+	return cmd
+}
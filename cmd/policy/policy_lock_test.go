@@ -0,0 +1,84 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package policy
+
+import (
+	"bytes"
+	"context"
+	"path"
+	"testing"
+
+	"github.com/enterprise-contract/go-gather/metadata"
+	ociMetadata "github.com/enterprise-contract/go-gather/metadata/oci"
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/enterprise-contract/ec-cli/cmd/root"
+	"github.com/enterprise-contract/ec-cli/internal/policy/source"
+	"github.com/enterprise-contract/ec-cli/internal/utils"
+)
+
+type fakeDownloader struct {
+	fs afero.Fs
+}
+
+func (d fakeDownloader) Download(_ context.Context, dest string, sourceUrl string, _ bool) (metadata.Metadata, error) {
+	if err := d.fs.MkdirAll(dest, 0755); err != nil {
+		return nil, err
+	}
+	return &ociMetadata.OCIMetadata{Digest: "sha256:4e388ab32b10dc8dbc7e28144f552830adc74787c1e2c0824032078a79f227fb"},
+		afero.WriteFile(d.fs, path.Join(dest, "policy.rego"), []byte("package "+path.Base(sourceUrl)), 0400)
+}
+
+func TestPolicyLock(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	ctx := utils.WithFS(context.Background(), fs)
+	ctx = context.WithValue(ctx, source.DownloaderFuncKey, fakeDownloader{fs})
+
+	cmd := setUpCobra(policyLockCmd())
+	cmd.SetContext(ctx)
+	stdout := bytes.Buffer{}
+	cmd.SetOut(&stdout)
+	cmd.SetArgs([]string{"policy", "lock", "--lockfile", "policy.lock.yaml", "--policy",
+		`{"sources": [{"policy": ["oci::registry.io/lock/release:1"], "data": ["oci::registry.io/lock/data:1"]}]}`})
+
+	require.NoError(t, cmd.Execute())
+	assert.Equal(t, "Locked 2 source(s) in policy.lock.yaml\n", stdout.String())
+
+	lockfile, err := source.LoadLockfile(fs, "policy.lock.yaml")
+	require.NoError(t, err)
+	require.Len(t, lockfile.Sources, 2)
+	assert.Equal(t, "oci::registry.io/lock/data:1", lockfile.Sources[0].URL)
+	assert.Equal(t, "oci::registry.io/lock/release:1", lockfile.Sources[1].URL)
+	for _, s := range lockfile.Sources {
+		assert.Regexp(t, "^sha256:[0-9a-f]{64}$", s.Digest)
+		assert.Equal(t, "sha256:4e388ab32b10dc8dbc7e28144f552830adc74787c1e2c0824032078a79f227fb", s.Revision)
+	}
+	assert.NotEqual(t, lockfile.Sources[0].Digest, lockfile.Sources[1].Digest)
+}
+
+func setUpCobra(command *cobra.Command) *cobra.Command {
+	policyCmd := NewPolicyCmd()
+	policyCmd.AddCommand(command)
+	cmd := root.NewRootCmd()
+	cmd.AddCommand(policyCmd)
+	return cmd
+}
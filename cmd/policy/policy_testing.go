@@ -0,0 +1,201 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// Define the `ec policy test` command
+package policy
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+
+	hd "github.com/MakeNowJust/heredoc"
+	ecc "github.com/enterprise-contract/enterprise-contract-controller/api/v1alpha1"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	_ "github.com/enterprise-contract/ec-cli/internal/evaluator" // imports EC OPA builtins
+	"github.com/enterprise-contract/ec-cli/internal/format"
+	"github.com/enterprise-contract/ec-cli/internal/logging"
+	"github.com/enterprise-contract/ec-cli/internal/opa"
+	"github.com/enterprise-contract/ec-cli/internal/policy"
+	"github.com/enterprise-contract/ec-cli/internal/policy/source"
+	"github.com/enterprise-contract/ec-cli/internal/utils"
+	validate_utils "github.com/enterprise-contract/ec-cli/internal/validate"
+)
+
+func policyTestCmd() *cobra.Command {
+	var (
+		policyConfiguration string
+		policySources       []string
+		dataSources         []string
+		run                 string
+		output              []string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "test",
+		Short: "Run the rego unit tests of the policy sources",
+
+		Long: hd.Doc(`
+			Run the rego unit tests of the policy sources.
+
+			The policy and data sources, of the policy configuration or given by the
+			--source and --data flags, are fetched the same way as when validating, and
+			the rules prefixed with test_ in each of the policy sources are run, as with
+			opa test, with the documents of the data sources of the same source group.
+			The functions provided by ec to the policy rules are available to the tests.
+
+			The results of the tests of all of the policy sources are reported together,
+			the command fails if any of the tests fails.
+		`),
+
+		Example: hd.Doc(`
+			Run the tests of the policy sources of a policy configuration file:
+
+			  ec policy test --policy policy.yaml
+
+			Run the tests of a policy source with a data source, writing the results in
+			JUnit format to a file:
+
+			  ec policy test --source github.com/org/policy//policy/release \
+			    --data github.com/org/policy//data --output junit=results.xml
+
+			Run only the tests with names matching a regular expression:
+
+			  ec policy test --policy policy.yaml --run 'test_.*_attestation'
+		`),
+
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			ctx := cmd.Context()
+			fs := utils.FS(ctx)
+
+			var sourceGroups []ecc.Source
+			if policyConfiguration != "" {
+				config, err := validate_utils.GetPolicyConfig(ctx, policyConfiguration)
+				if err != nil {
+					return err
+				}
+
+				p, err := policy.NewInertPolicy(ctx, config)
+				if err != nil {
+					return err
+				}
+				sourceGroups = p.Spec().Sources
+			}
+			if len(policySources) > 0 {
+				sourceGroups = append(sourceGroups, ecc.Source{Policy: policySources, Data: dataSources})
+			}
+
+			workDir, err := utils.CreateWorkDir(fs)
+			if err != nil {
+				return err
+			}
+			defer utils.CleanupWorkDir(fs, workDir)
+
+			var results []opa.TestResult
+			for _, s := range sourceGroups {
+				dataDirs := make([]string, 0, len(s.Data))
+				for _, url := range s.Data {
+					u := source.PolicyUrl{Url: url, Kind: source.DataKind}
+					dir, err := u.GetPolicy(ctx, workDir, false)
+					if err != nil {
+						return fmt.Errorf("fetching the source %s: %w", logging.RedactURL(url), err)
+					}
+					dataDirs = append(dataDirs, dir)
+				}
+
+				for _, url := range s.Policy {
+					// Remote OPA servers evaluate the rules themselves, there are
+					// no tests to run
+					if _, ok := source.RemoteOPAServer(url); ok {
+						log.Debugf("Skipping the remote OPA server %s", logging.RedactURL(url))
+						continue
+					}
+
+					u := source.PolicyUrl{Url: url, Kind: source.PolicyKind}
+					dir, err := u.GetPolicy(ctx, workDir, false)
+					if err != nil {
+						return fmt.Errorf("fetching the source %s: %w", logging.RedactURL(url), err)
+					}
+
+					r, err := opa.RunTests(ctx, logging.RedactCredentials(url), dir, dataDirs, run)
+					if err != nil {
+						return fmt.Errorf("running the tests of the source %s: %w", logging.RedactURL(url), err)
+					}
+					results = append(results, r...)
+				}
+			}
+
+			report := opa.NewTestReport(results)
+
+			outputs := output
+			if len(outputs) == 0 {
+				outputs = []string{opa.TestReportText}
+			}
+
+			p := format.NewTargetParser(opa.TestReportText, format.Options{}, cmd.OutOrStdout(), fs)
+			for _, o := range outputs {
+				target, err := p.Parse(o)
+				if err != nil {
+					return err
+				}
+
+				data, err := report.Format(target.Format)
+				if err != nil {
+					return err
+				}
+				if !bytes.HasSuffix(data, []byte{'\n'}) {
+					data = append(data, '\n')
+				}
+
+				if _, err := target.Write(data); err != nil {
+					return err
+				}
+			}
+
+			if !report.Success {
+				return errors.New("success criteria not met")
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&policyConfiguration, "policy", "p", policyConfiguration, hd.Doc(`
+		Policy configuration as:
+		  * Kubernetes reference ([<namespace>/]<name>)
+		  * file (policy.yaml)
+		  * git reference (github.com/user/repo//default?ref=main), or
+		  * inline JSON ('{sources: {...}, identity: {...}}')`))
+
+	cmd.Flags().StringArrayVar(&policySources, "source", policySources, "URL of a policy source to test, can be repeated")
+
+	cmd.Flags().StringArrayVar(&dataSources, "data", dataSources, "URL of a data source used by the tests of the sources given by --source, can be repeated")
+
+	cmd.Flags().StringVarP(&run, "run", "r", run, "run only the tests matching the regular expression")
+
+	cmd.Flags().StringSliceVarP(&output, "output", "o", output, hd.Doc(`
+		Write the results in a specific format, to a file given as <format>=<path> or
+		to stdout, can be repeated. Possible formats are: `+strings.Join(opa.TestReportFormats, ", ")+`.
+		The results are written as text to stdout by default.`))
+
+	cmd.MarkFlagsOneRequired("policy", "source")
+
+	return cmd
+}
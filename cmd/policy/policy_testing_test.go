@@ -0,0 +1,127 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package policy
+
+import (
+	"bytes"
+	"context"
+	"path"
+	"testing"
+
+	"github.com/enterprise-contract/go-gather/metadata"
+	ociMetadata "github.com/enterprise-contract/go-gather/metadata/oci"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/enterprise-contract/ec-cli/internal/policy/source"
+	"github.com/enterprise-contract/ec-cli/internal/utils"
+)
+
+// testSourcesDownloader writes a policy with a passing and a failing test, or
+// the data used by the policy
+type testSourcesDownloader struct {
+	fs afero.Fs
+}
+
+func (d testSourcesDownloader) Download(_ context.Context, dest string, sourceUrl string, _ bool) (metadata.Metadata, error) {
+	if err := d.fs.MkdirAll(dest, 0755); err != nil {
+		return nil, err
+	}
+
+	file, content := "data.json", `{"allowed_kinds": ["Pod"]}`
+	if path.Base(sourceUrl) == "release:1" {
+		file, content = "release.rego", `package release
+
+import rego.v1
+
+deny contains "not allowed" if {
+	not input.kind in data.allowed_kinds
+}
+
+test_allowed if {
+	count(deny) == 0 with input as {"kind": "Pod"}
+}
+
+test_not_allowed if {
+	count(deny) == 0 with input as {"kind": "Secret"}
+}
+`
+	}
+
+	return &ociMetadata.OCIMetadata{Digest: "sha256:4e388ab32b10dc8dbc7e28144f552830adc74787c1e2c0824032078a79f227fb"},
+		afero.WriteFile(d.fs, path.Join(dest, file), []byte(content), 0400)
+}
+
+func TestPolicyTest(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+
+	fs := afero.NewOsFs()
+	ctx := utils.WithFS(context.Background(), fs)
+	ctx = context.WithValue(ctx, source.DownloaderFuncKey, testSourcesDownloader{fs})
+
+	cases := []struct {
+		name     string
+		args     []string
+		expected string
+		err      string
+	}{
+		{
+			name: "policy configuration",
+			args: []string{"--policy", `{"sources": [{"policy": ["oci::registry.io/test/release:1"], "data": ["oci::registry.io/test/data:1"]}]}`},
+			expected: `PASS: release.test_allowed (oci::registry.io/test/release:1)
+  release.rego:9
+FAIL: release.test_not_allowed (oci::registry.io/test/release:1)
+  release.rego:13
+PASS: 1/2
+FAIL: 1/2
+`,
+			err: "success criteria not met",
+		},
+		{
+			name: "sources",
+			args: []string{"--source", "oci::registry.io/test/release:1", "--data", "oci::registry.io/test/data:1", "--run", "test_allowed"},
+			expected: `PASS: release.test_allowed (oci::registry.io/test/release:1)
+  release.rego:9
+PASS: 1/1
+`,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			// the downloaded sources are removed with the work directory
+			t.Cleanup(source.ResetDownloadCache)
+
+			cmd := setUpCobra(policyTestCmd())
+			cmd.SetContext(ctx)
+			stdout := bytes.Buffer{}
+			cmd.SetOut(&stdout)
+			cmd.SetArgs(append([]string{"policy", "test"}, c.args...))
+
+			err := cmd.Execute()
+			if c.err != "" {
+				assert.EqualError(t, err, c.err)
+			} else {
+				require.NoError(t, err)
+			}
+			assert.Equal(t, c.expected, stdout.String())
+		})
+	}
+}
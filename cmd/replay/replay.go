@@ -0,0 +1,159 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// Define the `ec replay` command
+package replay
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+
+	hd "github.com/MakeNowJust/heredoc"
+	"github.com/spf13/cobra"
+
+	"github.com/enterprise-contract/ec-cli/internal/applicationsnapshot"
+	"github.com/enterprise-contract/ec-cli/internal/evaluator"
+	"github.com/enterprise-contract/ec-cli/internal/format"
+	"github.com/enterprise-contract/ec-cli/internal/input"
+	"github.com/enterprise-contract/ec-cli/internal/policy/source"
+	"github.com/enterprise-contract/ec-cli/internal/replay"
+	"github.com/enterprise-contract/ec-cli/internal/utils"
+)
+
+type replayFunc func(context.Context, string, bool) ([]replay.Result, error)
+
+var ReplayCmd *cobra.Command
+
+func init() {
+	ReplayCmd = NewReplayCmd(replay.Replay)
+}
+
+func NewReplayCmd(replayInputs replayFunc) *cobra.Command {
+	data := struct {
+		info          bool
+		output        []string
+		showSuccesses bool
+		strict        bool
+	}{
+		strict: true,
+	}
+
+	cmd := &cobra.Command{
+		Use:   "replay <dir>",
+		Short: "Evaluate the policy inputs written by ec validate image --dump-input",
+
+		Long: hd.Doc(`
+			Evaluate the policy inputs written by ec validate image --dump-input.
+
+			The policy input of each component is evaluated again with the policy
+			configuration, the effective time and the policy and data sources recorded
+			when validating the image. The sources are fetched exclusively from the
+			directory, without any network access, so a failed validation, e.g. in CI,
+			can be reproduced and debugged locally, or the policy inputs can be used to
+			test changes to the policy rules.
+
+			Only the policy rules are evaluated, the checks of the image signature and
+			the attestations are not repeated, and the options of ec validate image
+			changing the results after the evaluation, e.g. --waivers, do not apply.
+		`),
+
+		Example: hd.Doc(`
+			Record the policy inputs when validating an image and evaluate them again:
+
+			  ec validate image --image registry/name:tag --policy policy.yaml \
+			    --public-key key.pub --dump-input ./replay
+
+			  ec replay ./replay
+		`),
+
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			source.SetOffline(true)
+
+			results, err := replayInputs(cmd.Context(), args[0], data.info)
+			if err != nil {
+				return err
+			}
+			if len(results) == 0 {
+				return errors.New("no policy inputs were recorded")
+			}
+
+			inputs := make([]input.Input, 0, len(results))
+			manyData := make([][]evaluator.Data, 0, len(results))
+			manyPolicyInput := make([][]byte, 0, len(results))
+			for _, r := range results {
+				i := input.Input{
+					FilePath:   filepath.Join(args[0], filepath.FromSlash(r.Component.Input)),
+					Violations: r.Output.Violations(),
+					Warnings:   r.Output.Warnings(),
+					Infos:      r.Output.Infos(),
+				}
+				successes := r.Output.Successes()
+				i.SuccessCount = len(successes)
+				if data.showSuccesses {
+					i.Successes = successes
+				}
+				i.Success = len(i.Violations) == 0
+
+				inputs = append(inputs, i)
+				manyData = append(manyData, r.Output.Data)
+				manyPolicyInput = append(manyPolicyInput, r.Output.PolicyInput)
+			}
+
+			// The policy of the first component is reported, as with the policy
+			// given to ec validate image
+			report, err := input.NewReport(inputs, results[0].Policy, manyData, manyPolicyInput)
+			if err != nil {
+				return err
+			}
+
+			p := format.NewTargetParser(input.JSON, format.Options{ShowSuccesses: data.showSuccesses}, cmd.OutOrStdout(), utils.FS(cmd.Context()))
+			if err := report.WriteAll(data.output, p); err != nil {
+				return err
+			}
+
+			if data.strict && !report.Success {
+				return errors.New("success criteria not met")
+			}
+
+			return nil
+		},
+	}
+
+	validOutputFormats := applicationsnapshot.OutputFormats
+	cmd.Flags().StringSliceVarP(&data.output, "output", "o", data.output, hd.Doc(`
+		Write output to a file in a specific format, e.g. yaml=/tmp/output.yaml. Use empty string
+		path for stdout, e.g. yaml. May be used multiple times. Possible formats are:
+		`+strings.Join(validOutputFormats, ", ")+`. In following format and file path
+		additional options can be provided in key=value form following the question
+		mark (?) sign, for example: --output text=output.txt?show-successes=false
+	`))
+
+	cmd.Flags().BoolVarP(&data.strict, "strict", "s", data.strict,
+		"Return non-zero status on non-successful validation")
+
+	cmd.Flags().BoolVar(&data.showSuccesses, "show-successes", data.showSuccesses,
+		"Include the successful policy rules in the report")
+
+	cmd.Flags().BoolVar(&data.info, "info", data.info, hd.Doc(`
+		Include additional information on the failures. For instance for policy
+		violations, include the title and the description of the failed policy
+		rule.`))
+
+	return cmd
+}
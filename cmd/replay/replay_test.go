@@ -0,0 +1,108 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package replay
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/enterprise-contract/ec-cli/cmd/root"
+	"github.com/enterprise-contract/ec-cli/internal/evaluator"
+	"github.com/enterprise-contract/ec-cli/internal/output"
+	"github.com/enterprise-contract/ec-cli/internal/policy"
+	"github.com/enterprise-contract/ec-cli/internal/policy/source"
+	"github.com/enterprise-contract/ec-cli/internal/replay"
+	"github.com/enterprise-contract/ec-cli/internal/utils"
+)
+
+func TestReplay(t *testing.T) {
+	t.Cleanup(func() { source.SetOffline(false) })
+
+	ctx := utils.WithFS(context.Background(), afero.NewMemMapFs())
+	p, err := policy.NewOfflinePolicy(ctx, "2024-01-02T03:04:05Z")
+	require.NoError(t, err)
+
+	var offline bool
+	replayInputs := func(_ context.Context, dir string, _ bool) ([]replay.Result, error) {
+		offline = source.IsOffline()
+		assert.Equal(t, "/replay", dir)
+
+		out := &output.Output{PolicyInput: []byte(`{}`)}
+		out.SetPolicyCheck([]evaluator.Outcome{{
+			Failures: []evaluator.Result{{
+				Message:  "Fails always",
+				Metadata: map[string]any{"code": "main.rejector"},
+			}},
+		}})
+
+		return []replay.Result{{
+			Component: replay.Component{Name: "component", Input: "inputs/1-component.json"},
+			Policy:    p,
+			Output:    out,
+		}}, nil
+	}
+
+	cmd := setUpCobra(NewReplayCmd(replayInputs))
+	cmd.SetContext(ctx)
+	stdout := bytes.Buffer{}
+	cmd.SetOut(&stdout)
+	cmd.SetArgs([]string{"replay", "/replay", "--output", "json"})
+
+	assert.EqualError(t, cmd.Execute(), "success criteria not met")
+	assert.True(t, offline)
+
+	var report struct {
+		Success   bool `json:"success"`
+		FilePaths []struct {
+			FilePath   string             `json:"filepath"`
+			Violations []evaluator.Result `json:"violations"`
+		} `json:"filepaths"`
+	}
+	require.NoError(t, json.Unmarshal(stdout.Bytes(), &report))
+	assert.False(t, report.Success)
+	require.Len(t, report.FilePaths, 1)
+	assert.Equal(t, "/replay/inputs/1-component.json", report.FilePaths[0].FilePath)
+	require.Len(t, report.FilePaths[0].Violations, 1)
+	assert.Equal(t, "Fails always", report.FilePaths[0].Violations[0].Message)
+}
+
+func TestReplayNothingRecorded(t *testing.T) {
+	t.Cleanup(func() { source.SetOffline(false) })
+
+	cmd := setUpCobra(NewReplayCmd(func(context.Context, string, bool) ([]replay.Result, error) {
+		return nil, nil
+	}))
+	cmd.SetContext(utils.WithFS(context.Background(), afero.NewMemMapFs()))
+	cmd.SetArgs([]string{"replay", "/replay"})
+
+	assert.EqualError(t, cmd.Execute(), "no policy inputs were recorded")
+}
+
+func setUpCobra(command *cobra.Command) *cobra.Command {
+	cmd := root.NewRootCmd()
+	cmd.AddCommand(command)
+	return cmd
+}
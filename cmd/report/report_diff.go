@@ -0,0 +1,131 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// Define the `ec report diff` command
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	hd "github.com/MakeNowJust/heredoc"
+	"github.com/spf13/cobra"
+	"golang.org/x/exp/slices"
+	"sigs.k8s.io/yaml"
+
+	"github.com/enterprise-contract/ec-cli/internal/applicationsnapshot"
+	"github.com/enterprise-contract/ec-cli/internal/utils"
+)
+
+func reportDiffCmd() *cobra.Command {
+	var (
+		outputFormat string
+		strict       = true
+	)
+
+	validFormats := []string{"text", "json", "yaml"}
+
+	cmd := &cobra.Command{
+		Use:   "diff <old report> <new report>",
+		Short: "Compare two validation reports",
+
+		Long: hd.Doc(`
+			Compare two validation reports.
+
+			The reports are the JSON, or YAML, output of ec validate image, e.g. from
+			--output json=<file>. Components are matched by their name, or by the image
+			repository for components without a name, and are listed as added, removed
+			or changed. For changed components the new and the resolved violations and
+			warnings, the change of the image, and the added and removed image
+			signatures are listed.
+
+			Results are matched by their fingerprints, so a violation is not reported as
+			new and resolved when only the digests, or other volatile data, in its message
+			differ, see --fingerprint-rule of ec validate image.
+
+			The command fails when the new report has regressions: added components
+			that do not pass, and components with new violations or that no longer
+			pass. This allows gating on no new violations rather than on all of the
+			components passing. Use --strict=false to always succeed.
+		`),
+
+		Example: hd.Doc(`
+			Compare the report of a release with the report of the previous release:
+
+			  ec report diff previous.json current.json
+
+			Print the differences as JSON:
+
+			  ec report diff previous.json current.json --output json
+		`),
+
+		Args: cobra.ExactArgs(2),
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			if !slices.Contains(validFormats, outputFormat) {
+				return fmt.Errorf("invalid value for --output '%s'. accepted values: %s", outputFormat, strings.Join(validFormats, ", "))
+			}
+
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			fs := utils.FS(cmd.Context())
+
+			previous, err := applicationsnapshot.LoadReport(fs, args[0])
+			if err != nil {
+				return err
+			}
+
+			current, err := applicationsnapshot.LoadReport(fs, args[1])
+			if err != nil {
+				return err
+			}
+
+			diff := applicationsnapshot.DiffReports(previous, current)
+
+			out := cmd.OutOrStdout()
+			switch outputFormat {
+			case "json":
+				if err := json.NewEncoder(out).Encode(diff); err != nil {
+					return err
+				}
+			case "yaml":
+				y, err := yaml.Marshal(diff)
+				if err != nil {
+					return err
+				}
+				if _, err := out.Write(y); err != nil {
+					return err
+				}
+			default:
+				if err := diff.WriteText(out); err != nil {
+					return err
+				}
+			}
+
+			if regressions := diff.Regressions(); strict && len(regressions) > 0 {
+				return fmt.Errorf("regressions found in: %s", strings.Join(regressions, ", "))
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&outputFormat, "output", "o", "text", fmt.Sprintf("output format. one of: %s", strings.Join(validFormats, ", ")))
+	cmd.Flags().BoolVarP(&strict, "strict", "s", strict, "return non-zero status code when the new report has regressions")
+
+	return cmd
+}
@@ -0,0 +1,160 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package report
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/enterprise-contract/ec-cli/cmd/root"
+	"github.com/enterprise-contract/ec-cli/internal/utils"
+)
+
+const previousReport = `{
+  "success": false,
+  "components": [
+    {"name": "spam", "containerImage": "registry.io/spam@sha256:1", "success": false,
+     "violations": [{"msg": "bad", "metadata": {"code": "a.b"}}],
+     "signatures": [{"keyid": "key", "sig": "sig1"}]},
+    {"name": "bacon", "containerImage": "registry.io/bacon@sha256:1", "success": true}
+  ]
+}`
+
+func TestReportDiff(t *testing.T) {
+	cases := []struct {
+		name    string
+		current string
+		args    []string
+		output  string
+		err     string
+	}{
+		{
+			name: "improvement",
+			current: `{
+			  "success": true,
+			  "components": [
+			    {"name": "spam", "containerImage": "registry.io/spam@sha256:2", "success": true,
+			     "signatures": [{"keyid": "key", "sig": "sig2"}]},
+			    {"name": "bacon", "containerImage": "registry.io/bacon@sha256:1", "success": true}
+			  ]
+			}`,
+			output: "~ spam (registry.io/spam@sha256:2): failed -> passed\n" +
+				"    image changed from registry.io/spam@sha256:1\n" +
+				"    - violation [a.b] bad\n" +
+				"    + signature key\n" +
+				"    - signature key\n",
+		},
+		{
+			name: "regression",
+			current: `
+success: false
+components:
+- name: spam
+  containerImage: registry.io/spam@sha256:1
+  success: false
+  violations:
+  - msg: bad
+    metadata:
+      code: a.b
+  signatures:
+  - keyid: key
+    sig: sig1
+- name: bacon
+  containerImage: registry.io/bacon@sha256:1
+  success: false
+  violations:
+  - msg: worse
+`,
+			output: "~ bacon (registry.io/bacon@sha256:1): passed -> failed\n" +
+				"    + violation worse\n",
+			err: "regressions found in: bacon",
+		},
+		{
+			name: "regression not strict",
+			current: `{"components": [
+			  {"name": "spam", "containerImage": "registry.io/spam@sha256:1", "success": false,
+			   "violations": [{"msg": "bad", "metadata": {"code": "a.b"}}],
+			   "signatures": [{"keyid": "key", "sig": "sig1"}]},
+			  {"name": "bacon", "containerImage": "registry.io/bacon@sha256:1", "success": true},
+			  {"name": "eggs", "containerImage": "registry.io/eggs@sha256:1", "success": false}
+			]}`,
+			args:   []string{"--strict=false"},
+			output: "+ eggs (registry.io/eggs@sha256:1): failed\n",
+		},
+		{
+			name:    "not a report",
+			current: `{"spam": true}`,
+			err:     "the file current.json is not a validation report, it has no components",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			fs := afero.NewMemMapFs()
+			require.NoError(t, afero.WriteFile(fs, "previous.json", []byte(previousReport), 0644))
+			require.NoError(t, afero.WriteFile(fs, "current.json", []byte(c.current), 0644))
+
+			cmd := setUpCobra(reportDiffCmd())
+			cmd.SetContext(utils.WithFS(context.Background(), fs))
+			stdout := bytes.Buffer{}
+			cmd.SetOut(&stdout)
+			cmd.SetArgs(append([]string{"report", "diff", "previous.json", "current.json"}, c.args...))
+
+			err := cmd.Execute()
+			if c.err == "" {
+				require.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, c.err)
+			}
+			assert.Equal(t, c.output, stdout.String())
+		})
+	}
+}
+
+func TestReportDiffJSON(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "previous.json", []byte(previousReport), 0644))
+
+	cmd := setUpCobra(reportDiffCmd())
+	cmd.SetContext(utils.WithFS(context.Background(), fs))
+	stdout := bytes.Buffer{}
+	cmd.SetOut(&stdout)
+	cmd.SetArgs([]string{"report", "diff", "previous.json", "previous.json", "--output", "json"})
+
+	require.NoError(t, cmd.Execute())
+
+	var diff map[string]any
+	require.NoError(t, json.Unmarshal(stdout.Bytes(), &diff))
+	assert.Empty(t, diff)
+}
+
+func setUpCobra(command *cobra.Command) *cobra.Command {
+	reportCmd := NewReportCmd()
+	reportCmd.AddCommand(command)
+	cmd := root.NewRootCmd()
+	cmd.AddCommand(reportCmd)
+	return cmd
+}
@@ -21,16 +21,25 @@ import (
 
 	log "github.com/sirupsen/logrus"
 
+	"github.com/enterprise-contract/ec-cli/cmd/bundle"
+	"github.com/enterprise-contract/ec-cli/cmd/cache"
+	"github.com/enterprise-contract/ec-cli/cmd/config"
 	"github.com/enterprise-contract/ec-cli/cmd/fetch"
 	"github.com/enterprise-contract/ec-cli/cmd/initialize"
 	"github.com/enterprise-contract/ec-cli/cmd/inspect"
 	"github.com/enterprise-contract/ec-cli/cmd/opa"
+	"github.com/enterprise-contract/ec-cli/cmd/policy"
+	"github.com/enterprise-contract/ec-cli/cmd/replay"
+	"github.com/enterprise-contract/ec-cli/cmd/report"
 	"github.com/enterprise-contract/ec-cli/cmd/root"
+	"github.com/enterprise-contract/ec-cli/cmd/serve"
 	"github.com/enterprise-contract/ec-cli/cmd/sigstore"
 	"github.com/enterprise-contract/ec-cli/cmd/test"
 	"github.com/enterprise-contract/ec-cli/cmd/track"
 	"github.com/enterprise-contract/ec-cli/cmd/validate"
 	"github.com/enterprise-contract/ec-cli/cmd/version"
+	"github.com/enterprise-contract/ec-cli/cmd/waive"
+	"github.com/enterprise-contract/ec-cli/cmd/webhook"
 	"github.com/enterprise-contract/ec-cli/internal/utils"
 )
 
@@ -51,14 +60,23 @@ func Execute() {
 }
 
 func init() {
+	RootCmd.AddCommand(bundle.BundleCmd)
+	RootCmd.AddCommand(cache.CacheCmd)
+	RootCmd.AddCommand(config.ConfigCmd)
 	RootCmd.AddCommand(fetch.FetchCmd)
 	RootCmd.AddCommand(initialize.InitCmd)
 	RootCmd.AddCommand(inspect.InspectCmd)
 	RootCmd.AddCommand(track.TrackCmd)
 	RootCmd.AddCommand(validate.ValidateCmd)
 	RootCmd.AddCommand(version.VersionCmd)
+	RootCmd.AddCommand(waive.WaiveCmd)
 	RootCmd.AddCommand(opa.OPACmd)
+	RootCmd.AddCommand(policy.PolicyCmd)
+	RootCmd.AddCommand(replay.ReplayCmd)
+	RootCmd.AddCommand(report.ReportCmd)
 	RootCmd.AddCommand(sigstore.SigstoreCmd)
+	RootCmd.AddCommand(serve.ServeCmd)
+	RootCmd.AddCommand(webhook.WebhookCmd)
 	if utils.Experimental() {
 		RootCmd.AddCommand(test.TestCmd)
 	}
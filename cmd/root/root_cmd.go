@@ -23,6 +23,7 @@ import (
 	"os"
 	"runtime"
 	"runtime/pprof"
+	"strings"
 	"sync"
 	"time"
 
@@ -30,8 +31,13 @@ import (
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 
+	"github.com/enterprise-contract/ec-cli/internal/downloader"
+	"github.com/enterprise-contract/ec-cli/internal/http"
 	"github.com/enterprise-contract/ec-cli/internal/kubernetes"
 	"github.com/enterprise-contract/ec-cli/internal/logging"
+	"github.com/enterprise-contract/ec-cli/internal/policy/source"
+	"github.com/enterprise-contract/ec-cli/internal/tracing"
+	"github.com/enterprise-contract/ec-cli/internal/utils"
 )
 
 var (
@@ -41,7 +47,15 @@ var (
 	trace         bool = false
 	globalTimeout      = 5 * time.Minute
 	logfile       string
+	logRedaction         = logging.RedactionStrict
 	OnExit        func() = func() {}
+	seed          string
+	hostRateLimit float64
+	tokenCache    bool = true
+	cacheTTL      time.Duration
+	offline       bool
+	otlpEndpoint  string
+	sourceRetry   = downloader.Retry{Retries: 2, Backoff: http.DefaultBackoff.Duration}
 )
 
 type customDeadlineExceededError struct{}
@@ -66,14 +80,27 @@ func NewRootCmd() *cobra.Command {
 		SilenceUsage: true,
 
 		PersistentPreRun: func(cmd *cobra.Command, _ []string) {
-			logging.InitLogging(verbose, quiet, debug, trace, logfile)
+			logging.InitLogging(verbose, quiet, debug, trace, logfile, logRedaction)
 
 			// set a custom message for context.DeadlineExceeded error
 			context.DeadlineExceeded = customDeadlineExceededError{}
 
 			// Create a new context now that flags have been parsed so a custom timeout can be used.
 			ctx, cancel := context.WithTimeout(cmd.Context(), globalTimeout)
+			if cmd.Flags().Changed("seed") {
+				ctx = utils.WithSeed(ctx, seed)
+			}
+			shutdownTracing, err := tracing.Setup(ctx, otlpEndpoint)
+			if err != nil {
+				log.Warnf("unable to set up the export of traces: %v", err)
+			}
+			ctx, span := tracing.Start(ctx, cmd.CommandPath())
 			cmd.SetContext(ctx)
+			http.SetHostRateLimit(hostRateLimit)
+			http.SetTokenCaching(tokenCache)
+			source.SetCacheTTL(cacheTTL)
+			source.SetOffline(offline)
+			downloader.SetRetry(sourceRetry)
 			log.Debugf("globalTimeout is %d", globalTimeout)
 
 			// if trace is enabled setup CPU profiling
@@ -111,6 +138,14 @@ func NewRootCmd() *cobra.Command {
 					}
 				}
 
+				// export the trace, before the context is canceled
+				span.End()
+				shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 5*time.Second)
+				if err := shutdownTracing(shutdownCtx); err != nil {
+					log.Warnf("unable to export traces: %v", err)
+				}
+				shutdownCancel()
+
 				// perform resource cleanup
 				if f, ok := log.StandardLogger().Out.(io.Closer); ok {
 					f.Close()
@@ -134,5 +169,27 @@ func setFlags(rootCmd *cobra.Command) {
 	rootCmd.PersistentFlags().BoolVar(&trace, "trace", trace, "enable trace logging")
 	rootCmd.PersistentFlags().DurationVar(&globalTimeout, "timeout", globalTimeout, "max overall execution duration")
 	rootCmd.PersistentFlags().StringVar(&logfile, "logfile", "", "file to write the logging output. If not specified logging output will be written to stderr")
+	rootCmd.PersistentFlags().StringVar(&logRedaction, "log-redaction", logRedaction, fmt.Sprintf(
+		"how much detail of URLs to include in the logging output, one of: %s. Credentials are always omitted",
+		strings.Join(logging.RedactionModes, ", ")))
+	rootCmd.PersistentFlags().StringVar(&seed, "seed", seed,
+		"derive otherwise random values, e.g. the names of policy download directories, from the seed to make runs reproducible")
+	rootCmd.PersistentFlags().Float64Var(&hostRateLimit, "host-rate-limit", hostRateLimit,
+		"maximum number of requests per second sent to any single host when fetching sources and images, 0 for no limit")
+	rootCmd.PersistentFlags().BoolVar(&tokenCache, "registry-token-cache", tokenCache,
+		"reuse the bearer token obtained for a registry and scope across the operations of a run until the token expires")
+	rootCmd.PersistentFlags().DurationVar(&cacheTTL, "cache-ttl", cacheTTL,
+		"how long git and OCI policy sources are reused from the persistent cache in $XDG_CACHE_HOME/ec/sources before they are fetched again, "+
+			"sources pinned to a commit or a digest are reused regardless. The persistent cache is not used when 0")
+	rootCmd.PersistentFlags().BoolVar(&offline, "offline", offline,
+		"fetch the policy sources and the policy configuration exclusively from the bundle in use, see ec bundle use, failing for any source not in the bundle, the rego functions accessing registries and Rekor fail")
+	rootCmd.PersistentFlags().IntVar(&sourceRetry.Retries, "source-retries", sourceRetry.Retries,
+		"number of times the download of a policy source is retried when it fails, before any of its fallbacks, see --policy-fallback, are tried")
+	rootCmd.PersistentFlags().DurationVar(&sourceRetry.Backoff, "source-retry-backoff", sourceRetry.Backoff,
+		"wait before the first retry of a policy source download, doubling with each subsequent retry, with jitter")
+	rootCmd.PersistentFlags().DurationVar(&sourceRetry.Timeout, "source-attempt-timeout", sourceRetry.Timeout,
+		"maximum duration of each attempt to download a policy source, 0 for no limit")
+	rootCmd.PersistentFlags().StringVar(&otlpEndpoint, "otlp-endpoint", otlpEndpoint,
+		"URL of the OTLP (gRPC) endpoint to export traces of the fetch, compile, evaluate and render phases to, e.g. http://localhost:4317. The OTEL_EXPORTER_OTLP_ENDPOINT environment variable is honored as well")
 	kubernetes.AddKubeconfigFlag(rootCmd)
 }
@@ -0,0 +1,209 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// Define the `ec serve` command
+package serve
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	hd "github.com/MakeNowJust/heredoc"
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+	"golang.org/x/sync/errgroup"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+
+	"github.com/enterprise-contract/ec-cli/internal/image"
+	_ "github.com/enterprise-contract/ec-cli/internal/kms"
+	_ "github.com/enterprise-contract/ec-cli/internal/rego"
+	"github.com/enterprise-contract/ec-cli/internal/server"
+	"github.com/enterprise-contract/ec-cli/internal/utils"
+)
+
+var ServeCmd *cobra.Command
+
+func init() {
+	ServeCmd = serveCmd(image.ValidateImage)
+}
+
+func serveCmd(validate server.ValidateFunc) *cobra.Command {
+	data := struct {
+		address     string
+		grpcAddress string
+		tlsCertFile string
+		tlsKeyFile  string
+		tokenFile   string
+		options     server.Options
+	}{
+		address: "localhost:8080",
+		options: server.Options{
+			Workers:        5,
+			RequestTimeout: 5 * time.Minute,
+			SourceTTL:      10 * time.Minute,
+		},
+	}
+
+	cmd := &cobra.Command{
+		Use:   "serve",
+		Short: "Serve the validation of snapshots over HTTP",
+
+		Long: hd.Doc(`
+			Serve the validation of snapshots over HTTP.
+
+			Runs a long-running server validating the components of the snapshots sent to
+			it, so that platforms can call the Enterprise Contract as a service instead of
+			running ec for each validation.
+
+			POST /validate validates the snapshot in the request body against the policy
+			referenced in it, and responds with the report in JSON format, the same report
+			"ec validate image --output json" outputs:
+
+			  {
+			    "snapshot": {"components": [{"name": "...", "containerImage": "..."}]},
+			    "policy": "...",
+			    "effectiveTime": "now"
+			  }
+
+			The policy is referenced the same way --policy of "ec validate image" does, as
+			an EnterpriseContractPolicy in the cluster, a git or https URL, or the policy
+			configuration in JSON or YAML format, except that files on the server can not
+			be referenced. The policy and data sources of the policy can not reference the
+			files on the server or the ConfigMaps and the Secrets of the cluster, i.e. the
+			sources fetched with the access of the server, either. The effectiveTime is
+			optional.
+
+			The server listens on localhost by default, see --address. Serve over HTTPS
+			with the certificate and the key given by --tls-cert-file and --tls-key-file,
+			and require the requests to present the bearer token held in the file given by
+			--token-file, in the Authorization header, before listening on other
+			interfaces. GET /healthz is not authenticated.
+
+			Components that can not be evaluated are reported with the evaluation error,
+			the request fails only when the request or the policy is invalid.
+
+			The policy sources are fetched once and shared by the requests until they
+			expire, see --source-ttl, or until one of them fails to be fetched.
+
+			GET /healthz responds once the server is ready to serve the requests.
+
+			The gRPC API, see api/v1alpha1/validation.proto, is served on --grpc-address.
+			It streams the result of each component as soon as the component is validated,
+			followed by the report, so that the progress of the validation can be followed.
+			The gRPC API uses the same TLS certificate and bearer token, in the
+			authorization metadata, as the HTTP API.
+
+			The server stops on SIGINT or SIGTERM, completing the requests in flight.
+		`),
+
+		Example: hd.Doc(`
+			Serve on port 8080:
+
+			  ec serve
+
+			Validate a snapshot:
+
+			  curl -X POST http://localhost:8080/validate -d '{
+			    "snapshot": {"components": [{"name": "app", "containerImage": "registry/name:tag"}]},
+			    "policy": "github.com/org/config//policy"
+			  }'
+
+			Serve the gRPC API on port 9090 as well:
+
+			  ec serve --grpc-address localhost:9090
+
+			Serve on all interfaces over HTTPS, requiring a bearer token:
+
+			  ec serve --address :8443 --tls-cert-file tls.crt --tls-key-file tls.key \
+			    --token-file token
+
+			  curl -X POST https://server:8443/validate -H "Authorization: Bearer $(cat token)" -d '...'
+		`),
+
+		Args: cobra.NoArgs,
+		PreRunE: func(cmd *cobra.Command, _ []string) error {
+			if (data.tlsCertFile == "") != (data.tlsKeyFile == "") {
+				return errors.New("--tls-cert-file and --tls-key-file must be given together")
+			}
+
+			if data.tokenFile != "" {
+				token, err := afero.ReadFile(utils.FS(cmd.Context()), data.tokenFile)
+				if err != nil {
+					return fmt.Errorf("reading the bearer token: %w", err)
+				}
+				data.options.Token = strings.TrimSpace(string(token))
+				if data.options.Token == "" {
+					return fmt.Errorf("the bearer token file %s is empty", data.tokenFile)
+				}
+			}
+
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			// The server is not limited by --timeout, each request is
+			// limited by --request-timeout instead
+			ctx := context.WithoutCancel(cmd.Context())
+			s := server.New(validate, data.options)
+			defer s.Close(ctx)
+
+			var grpcOptions []grpc.ServerOption
+			if data.tlsCertFile != "" {
+				creds, err := credentials.NewServerTLSFromFile(data.tlsCertFile, data.tlsKeyFile)
+				if err != nil {
+					return err
+				}
+				grpcOptions = append(grpcOptions, grpc.Creds(creds))
+			}
+
+			// Stopping either of the servers stops the other
+			g, ctx := errgroup.WithContext(ctx)
+			g.Go(func() error {
+				return server.Run(ctx, data.address, s, data.tlsCertFile, data.tlsKeyFile)
+			})
+			if data.grpcAddress != "" {
+				g.Go(func() error {
+					return server.RunGRPC(ctx, data.grpcAddress, s.GRPCServer(ctx, grpcOptions...))
+				})
+			}
+
+			return g.Wait()
+		},
+	}
+
+	cmd.Flags().StringVar(&data.address, "address", data.address, "address to listen on, in host:port form")
+	cmd.Flags().StringVar(&data.tlsCertFile, "tls-cert-file", data.tlsCertFile,
+		"file with the TLS certificate of the server in PEM format, the server is served over plain HTTP when empty")
+	cmd.Flags().StringVar(&data.tlsKeyFile, "tls-key-file", data.tlsKeyFile, "file with the TLS private key of the server in PEM format")
+	cmd.Flags().StringVar(&data.tokenFile, "token-file", data.tokenFile,
+		"file with the bearer token the requests must present in the Authorization header, the requests are not authenticated when empty")
+	cmd.Flags().StringVar(&data.grpcAddress, "grpc-address", data.grpcAddress,
+		"address to serve the gRPC API on, in host:port form, the gRPC API is not served when empty")
+	cmd.Flags().IntVar(&data.options.Workers, "workers", data.options.Workers,
+		"number of components of a snapshot validated concurrently")
+	cmd.Flags().DurationVar(&data.options.RequestTimeout, "request-timeout", data.options.RequestTimeout,
+		"time allowed to validate the snapshot of a request, 0 for no limit")
+	cmd.Flags().DurationVar(&data.options.SourceTTL, "source-ttl", data.options.SourceTTL,
+		"how long the fetched policy sources are reused across requests before they are fetched again, 0 to reuse them until the server stops")
+
+	cmd.Flags().BoolVar(&data.options.IgnoreRekor, "ignore-rekor", data.options.IgnoreRekor,
+		"skip the lookup of the signatures in the Rekor transparency log, as --ignore-rekor of ec validate image does")
+
+	return cmd
+}
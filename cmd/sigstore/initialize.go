@@ -44,7 +44,9 @@ func sigstoreInitializeCmd(f sigstoreInitializeFunc) *cobra.Command {
 			To provide an out-of-band trusted initial root.json, use the --root flag with a file or
 			URL reference. This will enable you to point ec to a separate TUF root.
 
-			Any updated TUF repository will be written to $HOME/.sigstore/root/.
+			Any updated TUF repository will be written to $HOME/.sigstore/root/, or $TUF_ROOT if
+			set. The mirror is recorded there, use "ec sigstore refresh" to update the root from
+			it later on.
 
 			Trusted keys and certificate used in ec verification (e.g. verifying Fulcio issued certificates
 			with Fulcio root CA) are pulled form the trusted metadata.
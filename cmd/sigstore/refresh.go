@@ -0,0 +1,94 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package sigstore
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	hd "github.com/MakeNowJust/heredoc"
+	"github.com/sigstore/sigstore/pkg/tuf"
+	"github.com/spf13/cobra"
+)
+
+type sigstoreRefreshFunc func(ctx context.Context, out io.Writer) error
+
+// doRefresh updates the local TUF root from the mirror it was initialized
+// with, and prints the status of the updated root.
+func doRefresh(ctx context.Context, out io.Writer) error {
+	t, err := tuf.NewFromEnv(ctx)
+	if err != nil {
+		return fmt.Errorf("loading the local TUF root, see ec sigstore initialize: %w", err)
+	}
+
+	// A nil root keeps the root trusted locally, the update of the root from
+	// the mirror is verified against it
+	if err := tuf.Initialize(ctx, t.Mirror(), nil); err != nil {
+		return fmt.Errorf("refreshing the TUF root from mirror %s: %w", t.Mirror(), err)
+	}
+
+	status, err := tuf.GetRootStatus(ctx)
+	if err != nil {
+		return err
+	}
+	b, err := json.MarshalIndent(status, "", "\t")
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(out, "Root status: \n %s\n", b)
+	return err
+}
+
+func sigstoreRefreshCmd(f sigstoreRefreshFunc) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "refresh",
+		Short: "Refreshes the local Sigstore root from the mirror it was initialized with",
+
+		Long: hd.Doc(`
+			Refreshes the local Sigstore root from the mirror it was initialized with.
+
+			The TUF metadata and the trusted certificate and key targets are updated from the
+			mirror given to "ec sigstore initialize", or the public Sigstore mirror if the
+			root was not initialized. The update is verified against the locally trusted
+			root.json, so a root pinned with "ec sigstore initialize --root" stays the root
+			of trust.
+
+			The local root is in $TUF_ROOT, or $HOME/.sigstore/root/ by default. ec verifies
+			the signatures with the certificates and keys of the local root, refreshing it
+			only when its timestamp has expired. Use this command to pick up rotated keys of
+			a private Sigstore deployment before that.
+		`),
+
+		Example: hd.Doc(`
+			Refresh the local root from its mirror.
+			ec sigstore refresh
+
+			Refresh a root in a different location.
+			TUF_ROOT=/path/to/root ec sigstore refresh
+		`),
+
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return f(cmd.Context(), cmd.OutOrStdout())
+		},
+	}
+
+	return cmd
+}
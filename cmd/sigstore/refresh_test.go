@@ -0,0 +1,86 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package sigstore
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/enterprise-contract/ec-cli/cmd/root"
+)
+
+func TestRefreshCmd(t *testing.T) {
+	cases := []struct {
+		name string
+		args []string
+		err  error
+	}{
+		{
+			name: "refresh",
+		},
+		{
+			name: "failure",
+			err:  errors.New("expected"),
+		},
+		{
+			name: "no args accepted",
+			args: []string{"something"},
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			called := false
+			refreshF := func(ctx context.Context, out io.Writer) error {
+				called = true
+				_, err := out.Write([]byte("refreshed"))
+				require.NoError(t, err)
+				return tt.err
+			}
+
+			sigCmd := NewSigstoreCmd()
+			sigCmd.AddCommand(sigstoreRefreshCmd(refreshF))
+
+			rootCmd := root.NewRootCmd()
+			rootCmd.AddCommand(sigCmd)
+
+			out := bytes.Buffer{}
+			rootCmd.SetOut(&out)
+			rootCmd.SetContext(context.Background())
+			rootCmd.SetArgs(append([]string{"sigstore", "refresh"}, tt.args...))
+
+			err := rootCmd.Execute()
+			if len(tt.args) > 0 {
+				assert.Error(t, err)
+				assert.False(t, called)
+				return
+			}
+
+			assert.Equal(t, tt.err, err)
+			assert.True(t, called)
+			assert.Equal(t, "refreshed", out.String())
+		})
+	}
+}
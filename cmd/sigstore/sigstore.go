@@ -28,6 +28,7 @@ var SigstoreCmd *cobra.Command
 func init() {
 	SigstoreCmd = NewSigstoreCmd()
 	SigstoreCmd.AddCommand(sigstoreInitializeCmd(initialize.DoInitialize))
+	SigstoreCmd.AddCommand(sigstoreRefreshCmd(doRefresh))
 }
 
 func NewSigstoreCmd() *cobra.Command {
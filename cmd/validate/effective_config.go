@@ -0,0 +1,228 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package validate
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	ecc "github.com/enterprise-contract/enterprise-contract-controller/api/v1alpha1"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"sigs.k8s.io/yaml"
+
+	"github.com/enterprise-contract/ec-cli/internal/logging"
+	"github.com/enterprise-contract/ec-cli/internal/policy"
+	"github.com/enterprise-contract/ec-cli/internal/policy/source"
+)
+
+// Formats of the effective configuration
+const (
+	effectiveConfigJSON = "json"
+	effectiveConfigYAML = "yaml"
+)
+
+// effectiveConfig is the configuration a validation runs with, after the
+// policy configuration is resolved and modified by the command line options.
+type effectiveConfig struct {
+	// Policy is the resolved policy, including any extra rule data
+	Policy ecc.EnterpriseContractPolicySpec `json:"policy"`
+	// PolicyOverrides are the resolved policies of the components with a
+	// policy override, by container image
+	PolicyOverrides map[string]ecc.EnterpriseContractPolicySpec `json:"policyOverrides,omitempty"`
+	EffectiveTime   string                                      `json:"effectiveTime"`
+	// Sources describes where the policy and data sources are fetched from
+	Sources []effectiveSource `json:"sources,omitempty"`
+	// Options are the values of the command line options, including defaults
+	Options map[string]any `json:"options"`
+}
+
+// effectiveSource describes how a policy or data source is fetched
+type effectiveSource struct {
+	URL string `json:"url"`
+	// Location is fetched instead of the URL, as set in the lockfile
+	Location string `json:"location,omitempty"`
+	// Digest is the expected digest of the content, as set in the lockfile
+	Digest    string   `json:"digest,omitempty"`
+	Fallbacks []string `json:"fallbacks,omitempty"`
+}
+
+// newEffectiveConfig returns the effective configuration of the validation
+// using the given policy, and the policies of the components with a policy
+// override, by container image. Credentials are redacted.
+func newEffectiveConfig(cmd *cobra.Command, p policy.Policy, overrides map[string]policy.Policy, lockfile *source.Lockfile, fallbacks source.Fallbacks) effectiveConfig {
+	config := effectiveConfig{
+		Policy:        redactSpec(p.Spec()),
+		EffectiveTime: p.EffectiveTime().UTC().Format(time.RFC3339),
+		// The policy is reported resolved
+		Options: effectiveOptions(cmd, "policy", "print-effective-config", "help"),
+	}
+
+	specs := []ecc.EnterpriseContractPolicySpec{p.Spec()}
+	images := make([]string, 0, len(overrides))
+	for image := range overrides {
+		images = append(images, image)
+	}
+	sort.Strings(images)
+	for _, image := range images {
+		spec := overrides[image].Spec()
+		specs = append(specs, spec)
+		if config.PolicyOverrides == nil {
+			config.PolicyOverrides = map[string]ecc.EnterpriseContractPolicySpec{}
+		}
+		config.PolicyOverrides[image] = redactSpec(spec)
+	}
+	config.Sources = effectiveSources(specs, lockfile, fallbacks)
+
+	return config
+}
+
+// redactSpec returns the policy with the credentials in the source URLs
+// redacted
+func redactSpec(spec ecc.EnterpriseContractPolicySpec) ecc.EnterpriseContractPolicySpec {
+	sources := make([]ecc.Source, 0, len(spec.Sources))
+	for _, s := range spec.Sources {
+		s.Policy = redactAll(s.Policy)
+		s.Data = redactAll(s.Data)
+		sources = append(sources, s)
+	}
+	spec.Sources = sources
+
+	return spec
+}
+
+func redactAll(urls []string) []string {
+	if urls == nil {
+		return nil
+	}
+
+	redacted := make([]string, 0, len(urls))
+	for _, u := range urls {
+		redacted = append(redacted, logging.RedactCredentials(u))
+	}
+
+	return redacted
+}
+
+// redactOption redacts the credentials of any URL within the value of a
+// command line option, including URLs in <key>=<value> pairs
+func redactOption(value string) string {
+	if strings.ContainsAny(value, " \n{") {
+		return value
+	}
+
+	// The = of the query of a URL does not separate a pair
+	if k, v, ok := strings.Cut(value, "="); ok && !strings.Contains(k, "?") {
+		return redactOption(k) + "=" + redactOption(v)
+	}
+
+	if strings.Contains(value, "://") || strings.Contains(value, "::") {
+		return logging.RedactCredentials(value)
+	}
+
+	return value
+}
+
+// effectiveSources returns how each of the sources of the policies is fetched,
+// given the lockfile, if any, and the fallbacks.
+func effectiveSources(specs []ecc.EnterpriseContractPolicySpec, lockfile *source.Lockfile, fallbacks source.Fallbacks) []effectiveSource {
+	seen := map[string]bool{}
+	var sources []effectiveSource
+	for _, spec := range specs {
+		for _, s := range spec.Sources {
+			for _, u := range append(append([]string{}, s.Policy...), s.Data...) {
+				if seen[u] {
+					continue
+				}
+				seen[u] = true
+
+				es := effectiveSource{URL: logging.RedactCredentials(u), Fallbacks: redactAll(fallbacks[u])}
+				if lockfile != nil {
+					if locked, ok := lockfile.Find(u); ok {
+						es.Digest = locked.Digest
+						if locked.Location != "" {
+							es.Location = logging.RedactCredentials(locked.Location)
+						}
+					}
+				}
+				sources = append(sources, es)
+			}
+		}
+	}
+
+	return sources
+}
+
+// effectiveOptions returns the values of the options of the command, except
+// the ones given as excluded, which are reported otherwise
+func effectiveOptions(cmd *cobra.Command, excluded ...string) map[string]any {
+	options := map[string]any{}
+	cmd.Flags().VisitAll(func(f *pflag.Flag) {
+		if f.Hidden || f.Deprecated != "" {
+			return
+		}
+		for _, e := range excluded {
+			if f.Name == e {
+				return
+			}
+		}
+
+		if s, ok := f.Value.(pflag.SliceValue); ok {
+			values := make([]string, 0, len(s.GetSlice()))
+			for _, v := range s.GetSlice() {
+				values = append(values, redactOption(v))
+			}
+			options[f.Name] = values
+			return
+		}
+
+		options[f.Name] = redactOption(f.Value.String())
+	})
+
+	return options
+}
+
+// write writes the effective configuration in the given format, json or yaml
+func (c effectiveConfig) write(w io.Writer, format string) error {
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	switch format {
+	case effectiveConfigJSON:
+	case effectiveConfigYAML:
+		if data, err = yaml.JSONToYAML(data); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("invalid value %q for --print-effective-config, expected one of: %s, %s", format, effectiveConfigJSON, effectiveConfigYAML)
+	}
+
+	if _, err := w.Write(data); err != nil {
+		return err
+	}
+	if format == effectiveConfigJSON {
+		_, err = fmt.Fprintln(w)
+	}
+
+	return err
+}
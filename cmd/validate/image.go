@@ -17,32 +17,109 @@
 package validate
 
 import (
+	"cmp"
 	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
 	"sort"
 	"strings"
+	"sync"
+	"time"
 
 	hd "github.com/MakeNowJust/heredoc"
+	"github.com/dustin/go-humanize"
 	app "github.com/konflux-ci/application-api/api/v1alpha1"
+	"github.com/sigstore/cosign/v2/cmd/cosign/cli/initialize"
 	"github.com/sigstore/cosign/v2/pkg/cosign"
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 	extv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 
 	"github.com/enterprise-contract/ec-cli/internal/applicationsnapshot"
+	"github.com/enterprise-contract/ec-cli/internal/attestation"
+	"github.com/enterprise-contract/ec-cli/internal/evaluation_target/application_snapshot_image"
 	"github.com/enterprise-contract/ec-cli/internal/evaluator"
+	"github.com/enterprise-contract/ec-cli/internal/fingerprint"
 	"github.com/enterprise-contract/ec-cli/internal/format"
+	"github.com/enterprise-contract/ec-cli/internal/image"
 	"github.com/enterprise-contract/ec-cli/internal/output"
 	"github.com/enterprise-contract/ec-cli/internal/policy"
 	"github.com/enterprise-contract/ec-cli/internal/policy/source"
+	"github.com/enterprise-contract/ec-cli/internal/replay"
+	"github.com/enterprise-contract/ec-cli/internal/signature"
+	"github.com/enterprise-contract/ec-cli/internal/tracing"
 	"github.com/enterprise-contract/ec-cli/internal/utils"
+	"github.com/enterprise-contract/ec-cli/internal/utils/oci"
 	validate_utils "github.com/enterprise-contract/ec-cli/internal/validate"
+	"github.com/enterprise-contract/ec-cli/internal/vsa"
+	"github.com/enterprise-contract/ec-cli/internal/waiver"
 )
 
+// tufInitialize initializes the local TUF root, replaced in tests
+var tufInitialize = initialize.DoInitialize
+
 type imageValidationFunc func(context.Context, app.SnapshotComponent, *app.SnapshotSpec, policy.Policy, []evaluator.Evaluator, bool) (*output.Output, error)
 
+// verdict returns the verdict recorded in traces for the given success
+func verdict(success bool) string {
+	if success {
+		return "passed"
+	}
+	return "failed"
+}
+
+var errComponentTimeout = errors.New("component validation timed out")
+
+// validateWithTimeout runs the validation of a component, giving up on it once
+// the timeout passes. The validation is given a context canceled at that
+// point, it keeps running in the background until it returns and is tracked by
+// the pending wait group until then, so that the evaluators it uses are not
+// destroyed before it returns. A timeout of zero, or less, does not limit the
+// validation.
+func validateWithTimeout(ctx context.Context, timeout time.Duration, pending *sync.WaitGroup, validate func(context.Context) (*output.Output, error)) (*output.Output, error) {
+	if timeout <= 0 {
+		return validate(ctx)
+	}
+
+	componentCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	// The deadline of the component context was reached, and not the one of
+	// the overall validation, e.g. set by --timeout
+	timedOut := func() bool {
+		return errors.Is(componentCtx.Err(), context.DeadlineExceeded) && ctx.Err() == nil
+	}
+
+	type validation struct {
+		out *output.Output
+		err error
+	}
+	done := make(chan validation, 1)
+	pending.Add(1)
+	go func() {
+		defer pending.Done()
+		out, err := validate(componentCtx)
+		done <- validation{out, err}
+	}()
+
+	var v validation
+	select {
+	case v = <-done:
+	case <-componentCtx.Done():
+		v.err = componentCtx.Err()
+	}
+
+	if v.err != nil && timedOut() {
+		return nil, fmt.Errorf("%w after %s", errComponentTimeout, timeout)
+	}
+
+	return v.out, v.err
+}
+
 var newConftestEvaluator = evaluator.NewConftestEvaluator
 
 func validateImageCmd(validate imageValidationFunc) *cobra.Command {
@@ -55,6 +132,8 @@ func validateImageCmd(validate imageValidationFunc) *cobra.Command {
 		extraRuleData               []string
 		filePath                    string // Deprecated: images replaced this
 		imageRef                    string
+		imageConfigPath             string
+		imageConfig                 json.RawMessage
 		info                        bool
 		input                       string // Deprecated: images replaced this
 		ignoreRekor                 bool
@@ -62,22 +141,96 @@ func validateImageCmd(validate imageValidationFunc) *cobra.Command {
 		outputFile                  string
 		policy                      policy.Policy
 		policyConfiguration         string
+		policyOverrides             map[string]string
+		allowedPolicyOverrides      []string
+		priorities                  map[string]int
+		applications                map[string]string
+		componentOrder              []string
+		printEffectiveConfig        string
+		overridePolicies            map[string]policy.Policy
 		publicKey                   string
+		redact                      []string
 		rekorURL                    string
+		requireRekor                bool
+		sigstoreBundles             []string
+		signatureVerifier           string
+		signatureVerifiers          map[string]string
+		notationTrustPolicy         string
+		notationTrustStore          string
+		trustedRoot                 string
 		snapshot                    string
+		tufMirror                   string
+		tufRoot                     string
 		spec                        *app.SnapshotSpec
 		strict                      bool
+		strictDataSources           bool
+		strictInput                 bool
 		images                      string
+		verboseRules                bool
+		noApplicableRules           string
+		requireDigest               bool
+		approvedDigests             string
+		approved                    image.ApprovedDigests
+		waiverFile                  string
+		waivers                     *waiver.File
+		fingerprintRules            []string
+		normalizer                  fingerprint.Normalizer
+		minAttestationSigners       int
+		maxAttestationSize          string
+		unsignedImage               string
+		signatureTime               string
+		evaluationErrors            string
+		identityKey                 string
+		duplicateComponents         string
+		duplicates                  map[string][]string
+		platforms                   []string
+		imagePlatforms              map[string]applicationsnapshot.ImagePlatform
+		policyFallbacks             []string
+		policySourceKeys            []string
+		regoVersions                []string
+		policySourceHeaders         []string
+		policySourceCABundle        string
+		registryCredentials         string
+		lockfilePath                string
+		updateLockfile              bool
+		vsa                         bool
+		vsaSigningKey               string
+		vsaUpload                   bool
+		vsaOutputDir                string
+		lockfile                    *source.Lockfile
+		requiredAttestationTypes    []string
+		ruleEffectiveOn             []string
+		severityOverrides           []string
+		failOnSeverity              string
+		explain                     []string
+		coverage                    bool
+		dumpInput                   string
+		componentTimeout            time.Duration
+		requiredTypes               attestation.RequiredTypes
 		noColor                     bool
 		forceColor                  bool
 		workers                     int
+		benchmark                   int
+		watch                       bool
+		watchInterval               time.Duration
 	}{
-		strict:  true,
-		workers: 5,
+		noApplicableRules:   output.NoApplicableRulesPass,
+		unsignedImage:       output.UnsignedImageDeny,
+		signatureTime:       output.SignatureTimeIgnore,
+		signatureVerifier:   signature.CosignVerifier,
+		maxAttestationSize:  humanize.IBytes(attestation.DefaultMaxSize),
+		evaluationErrors:    output.EvaluationErrorAbort,
+		duplicateComponents: applicationsnapshot.DuplicatesDedupe,
+		strict:              true,
+		workers:             5,
+		watchInterval:       5 * time.Minute,
 	}
 
 	validOutputFormats := applicationsnapshot.OutputFormats
 
+	// lastReport receives the report of the validation when set
+	var lastReport *applicationsnapshot.Report
+
 	cmd := &cobra.Command{
 		Use:   "image",
 		Short: "Validate conformance of container images with the Enterprise Contract",
@@ -119,6 +272,35 @@ func validateImageCmd(validate imageValidationFunc) *cobra.Command {
 
 			  ec validate image --images '{"components":[{"containerImage":"<image url>"}]}'
 
+			Validate a component against a different policy than the rest of the components
+			by annotating it with the policy configuration to use. The value takes the same
+			form as the --policy flag and must be allowed with --allow-policy-override. The
+			policy used is recorded for the component in the report:
+
+			  ec validate image --policy my-policy --allow-policy-override infra-policy.yaml --images '{"components":[
+			    {"containerImage":"<image url>"},
+			    {"containerImage":"<infra image url>",
+			     "annotations":{"ec.enterprise-contract.dev/policy":"infra-policy.yaml"}}]}'
+
+			Validate a snapshot spanning multiple applications by annotating the components
+			with their application. The report holds the results of each application, in
+			addition to those of each component. Components without the annotation belong
+			to the application of the snapshot:
+
+			  ec validate image --policy my-policy --images '{"application":"frontend","components":[
+			    {"containerImage":"<image url>"},
+			    {"containerImage":"<backend image url>",
+			     "annotations":{"ec.enterprise-contract.dev/application":"backend"}}]}'
+
+			Verify the Notation signature of a component signed with notation, instead of its
+			cosign signature, against the trust policy and the trust store of the notation
+			configuration:
+
+			  ec validate image --policy my-policy --images '{"components":[
+			    {"containerImage":"<image url>"},
+			    {"containerImage":"<notation signed image url>",
+			     "annotations":{"ec.enterprise-contract.dev/signature-verifier":"notation"}}]}'
+
 			Use a different public key than the one from the EnterpriseContractPolicy resource:
 
 			  ec validate image --image registry/name:tag --public-key <path/to/public/key>
@@ -127,6 +309,18 @@ func validateImageCmd(validate imageValidationFunc) *cobra.Command {
 
 			  ec validate image --image registry/name:tag --rekor-url https://rekor.example.org
 
+			Look up the signatures and attestations in the Rekor transparency log, verifying
+			their inclusion in the log:
+
+			  ec validate image --image registry/name:tag --rekor-url https://rekor.example.org \
+			    --require-rekor
+
+			Verify an image signed with cosign's bundle output, without network access to
+			Rekor, using a detached Sigstore bundle:
+
+			  ec validate image --image registry/name@sha256:<digest> --public-key key.pub \
+			    --sigstore-bundle image.sigstore.json --trusted-root trusted_root.json
+
 			Return a non-zero status code on validation failure:
 
 			  ec validate image --image registry/name:tag
@@ -168,6 +362,26 @@ func validateImageCmd(validate imageValidationFunc) *cobra.Command {
 
 			  ec validate image --image registry/name:tag --output yaml --output appstudio=<path>
 
+			Show the full details of results from rules annotated as verbose in the text output
+
+			  ec validate image --image registry/name:tag --output text --verbose-rules
+
+			Write a report with the registry hosts and signer identities masked to a file
+			in JSON format, and the full report to stdout
+
+			  ec validate image --image registry/name:tag --output text \
+			    --output json=<path>?redact=registry,signer
+
+			Write a markdown summary of the components and their violations to a file, to be
+			posted as a pull request comment
+
+			  ec validate image --image registry/name:tag --output text --output markdown=<path>
+
+			Emit GitHub Actions workflow annotations for the violations and warnings. Within a
+			GitHub Actions job the markdown summary is also appended to the job summary
+
+			  ec validate image --image registry/name:tag --output github
+
 			Write the data used in the policy evaluation to a file in YAML format
 
 			  ec validate image --image registry/name:tag --output data=<path>
@@ -189,16 +403,305 @@ func validateImageCmd(validate imageValidationFunc) *cobra.Command {
 
 		PreRunE: func(cmd *cobra.Command, args []string) (allErrors error) {
 			ctx := cmd.Context()
-			if s, err := applicationsnapshot.DetermineInputSpec(ctx, applicationsnapshot.Input{
-				File:     data.filePath,
-				JSON:     data.input,
-				Image:    data.imageRef,
-				Snapshot: data.snapshot,
-				Images:   data.images,
+			if data.strictDataSources {
+				ctx = evaluator.WithStrictDataSources(ctx)
+				cmd.SetContext(ctx)
+			}
+			if data.strictInput {
+				ctx = evaluator.WithStrictInput(ctx)
+				cmd.SetContext(ctx)
+			}
+			if len(data.ruleEffectiveOn) > 0 {
+				if effectiveOn, err := evaluator.ParseRuleEffectiveOn(data.ruleEffectiveOn); err != nil {
+					allErrors = errors.Join(allErrors, err)
+				} else {
+					ctx = evaluator.WithRuleEffectiveOn(ctx, effectiveOn)
+					cmd.SetContext(ctx)
+				}
+			}
+			if len(data.severityOverrides) > 0 {
+				if overrides, err := evaluator.ParseSeverityOverrides(data.severityOverrides); err != nil {
+					allErrors = errors.Join(allErrors, err)
+				} else {
+					ctx = evaluator.WithSeverityOverrides(ctx, overrides)
+					cmd.SetContext(ctx)
+				}
+			}
+			if len(data.explain) > 0 {
+				ctx = evaluator.WithExplain(ctx, data.explain)
+				cmd.SetContext(ctx)
+			}
+			if data.failOnSeverity != "" && !slices.Contains(evaluator.SeverityLevels, data.failOnSeverity) {
+				allErrors = errors.Join(allErrors, fmt.Errorf("invalid value %q for --fail-on-severity, expected one of: %s", data.failOnSeverity, strings.Join(evaluator.SeverityLevels, ", ")))
+			}
+			if len(data.policyFallbacks) > 0 {
+				if fallbacks, err := source.ParseFallbacks(data.policyFallbacks); err != nil {
+					allErrors = errors.Join(allErrors, err)
+				} else {
+					ctx = source.WithFallbacks(ctx, fallbacks)
+					cmd.SetContext(ctx)
+				}
+			}
+			if len(data.regoVersions) > 0 {
+				if versions, err := source.ParseRegoVersions(data.regoVersions); err != nil {
+					allErrors = errors.Join(allErrors, err)
+				} else {
+					ctx = source.WithRegoVersions(ctx, versions)
+					cmd.SetContext(ctx)
+				}
+			}
+			if len(data.policySourceKeys) > 0 {
+				if keys, err := source.ParseSourceKeys(data.policySourceKeys); err != nil {
+					allErrors = errors.Join(allErrors, err)
+				} else {
+					ctx = source.WithSourceKeys(ctx, keys)
+					cmd.SetContext(ctx)
+				}
+			}
+			if len(data.policySourceHeaders) > 0 || data.policySourceCABundle != "" {
+				if headers, err := source.ParseHTTPHeaders(data.policySourceHeaders); err != nil {
+					allErrors = errors.Join(allErrors, err)
+				} else {
+					ctx = source.WithHTTPOptions(ctx, source.HTTPOptions{Headers: headers, CABundle: data.policySourceCABundle})
+					cmd.SetContext(ctx)
+				}
+			}
+			if data.vsa {
+				if data.vsaSigningKey == "" {
+					allErrors = errors.Join(allErrors, errors.New("--vsa requires --vsa-signing-key to be set"))
+				}
+				if !data.vsaUpload && data.vsaOutputDir == "" {
+					allErrors = errors.Join(allErrors, errors.New("--vsa requires --vsa-upload or --vsa-output-dir to be set"))
+				}
+			}
+			if data.updateLockfile && data.lockfilePath == "" {
+				allErrors = errors.Join(allErrors, errors.New("--update-lockfile requires --lockfile to be set"))
+			} else if data.lockfilePath != "" {
+				if data.updateLockfile {
+					data.lockfile = source.NewLockfileUpdate()
+				} else if l, err := source.LoadLockfile(utils.FS(ctx), data.lockfilePath); err != nil {
+					allErrors = errors.Join(allErrors, err)
+				} else {
+					data.lockfile = l
+				}
+				if data.lockfile != nil {
+					ctx = source.WithLockfile(ctx, data.lockfile)
+					cmd.SetContext(ctx)
+				}
+			}
+			if data.registryCredentials != "" {
+				if credentials, err := oci.LoadCredentials(utils.FS(ctx), data.registryCredentials); err != nil {
+					allErrors = errors.Join(allErrors, err)
+				} else {
+					ctx = oci.WithCredentials(ctx, credentials)
+					cmd.SetContext(ctx)
+				}
+			}
+			if len(data.sigstoreBundles) > 0 || data.trustedRoot != "" {
+				var bundleOpts application_snapshot_image.SigstoreBundleOptions
+				if bundles, err := application_snapshot_image.LoadSigstoreBundles(utils.FS(ctx), data.sigstoreBundles); err != nil {
+					allErrors = errors.Join(allErrors, err)
+				} else {
+					bundleOpts.Bundles = bundles
+				}
+				if data.trustedRoot != "" {
+					if trustedRoot, err := application_snapshot_image.LoadTrustedRoot(utils.FS(ctx), data.trustedRoot); err != nil {
+						allErrors = errors.Join(allErrors, err)
+					} else {
+						bundleOpts.TrustedRoot = trustedRoot
+					}
+				}
+				ctx = application_snapshot_image.WithSigstoreBundleOptions(ctx, bundleOpts)
+				cmd.SetContext(ctx)
+			}
+			if !slices.Contains(output.NoApplicableRulesModes, data.noApplicableRules) {
+				allErrors = errors.Join(allErrors, fmt.Errorf("invalid value %q for --no-applicable-rules, expected one of: %s",
+					data.noApplicableRules, strings.Join(output.NoApplicableRulesModes, ", ")))
+			}
+
+			if !slices.Contains(output.SignatureTimeModes, data.signatureTime) {
+				allErrors = errors.Join(allErrors, fmt.Errorf("invalid value %q for --signature-time, expected one of: %s",
+					data.signatureTime, strings.Join(output.SignatureTimeModes, ", ")))
+			}
+
+			if !slices.Contains(output.UnsignedImageModes, data.unsignedImage) {
+				allErrors = errors.Join(allErrors, fmt.Errorf("invalid value %q for --unsigned-image, expected one of: %s",
+					data.unsignedImage, strings.Join(output.UnsignedImageModes, ", ")))
+			}
+
+			for _, f := range data.redact {
+				if !slices.Contains(applicationsnapshot.RedactionFields, f) {
+					allErrors = errors.Join(allErrors, fmt.Errorf("invalid value %q for --redact, expected one of: %s",
+						f, strings.Join(applicationsnapshot.RedactionFields, ", ")))
+				}
+			}
+
+			if data.workers < 1 {
+				allErrors = errors.Join(allErrors, fmt.Errorf("invalid value %d for --workers, expected at least 1", data.workers))
+			}
+
+			if !slices.Contains(output.EvaluationErrorModes, data.evaluationErrors) {
+				allErrors = errors.Join(allErrors, fmt.Errorf("invalid value %q for --evaluation-errors, expected one of: %s",
+					data.evaluationErrors, strings.Join(output.EvaluationErrorModes, ", ")))
+			}
+
+			if !slices.Contains(signature.Verifiers, data.signatureVerifier) {
+				allErrors = errors.Join(allErrors, fmt.Errorf("invalid value %q for --signature-verifier, expected one of: %s",
+					data.signatureVerifier, strings.Join(signature.Verifiers, ", ")))
+			}
+			if data.notationTrustPolicy != "" || data.notationTrustStore != "" {
+				var notationOpts signature.NotationOptions
+				if data.notationTrustPolicy == "" {
+					allErrors = errors.Join(allErrors, errors.New("--notation-trust-store requires --notation-trust-policy to be set"))
+				} else if trustPolicy, err := signature.LoadNotationTrustPolicy(utils.FS(ctx), data.notationTrustPolicy); err != nil {
+					allErrors = errors.Join(allErrors, err)
+				} else {
+					notationOpts = signature.NotationOptions{TrustPolicy: trustPolicy, TrustStore: data.notationTrustStore}
+				}
+				ctx = signature.WithNotationOptions(ctx, notationOpts)
+				cmd.SetContext(ctx)
+			}
+
+			if data.identityKey != "" && !slices.Contains(applicationsnapshot.IdentityKeys, data.identityKey) {
+				allErrors = errors.Join(allErrors, fmt.Errorf("invalid value %q for --identity-key, expected one of: %s",
+					data.identityKey, strings.Join(applicationsnapshot.IdentityKeys, ", ")))
+			}
+
+			if !slices.Contains(applicationsnapshot.DuplicateModes, data.duplicateComponents) {
+				allErrors = errors.Join(allErrors, fmt.Errorf("invalid value %q for --duplicate-components, expected one of: %s",
+					data.duplicateComponents, strings.Join(applicationsnapshot.DuplicateModes, ", ")))
+			}
+
+			if len(data.requiredAttestationTypes) > 0 {
+				if required, err := attestation.ParseRequiredTypes(data.requiredAttestationTypes); err != nil {
+					allErrors = errors.Join(allErrors, err)
+				} else {
+					data.requiredTypes = required
+				}
+			}
+
+			if data.printEffectiveConfig != "" && data.printEffectiveConfig != effectiveConfigJSON && data.printEffectiveConfig != effectiveConfigYAML {
+				allErrors = errors.Join(allErrors, fmt.Errorf("invalid value %q for --print-effective-config, expected one of: %s, %s",
+					data.printEffectiveConfig, effectiveConfigJSON, effectiveConfigYAML))
+			}
+
+			if data.benchmark < 0 {
+				allErrors = errors.Join(allErrors, fmt.Errorf("invalid value %d for --benchmark, expected 0 or more", data.benchmark))
+			}
+
+			if data.watch {
+				if data.watchInterval <= 0 {
+					allErrors = errors.Join(allErrors, fmt.Errorf("invalid value %s for --watch-interval, expected more than 0", data.watchInterval))
+				}
+				if data.benchmark > 0 || data.imageConfigPath != "" || data.updateLockfile {
+					allErrors = errors.Join(allErrors, errors.New("--watch can not be used with --benchmark, --image-config or --update-lockfile"))
+				}
+			}
+
+			if data.minAttestationSigners < 0 {
+				allErrors = errors.Join(allErrors, fmt.Errorf("invalid value %d for --min-attestation-signers, expected 0 or more", data.minAttestationSigners))
+			}
+
+			if size, err := humanize.ParseBytes(data.maxAttestationSize); err != nil || size == 0 {
+				allErrors = errors.Join(allErrors, fmt.Errorf("invalid value %q for --max-attestation-size, expected a size such as 64MiB", data.maxAttestationSize))
+			} else if err := attestation.SetMaxSize(size); err != nil {
+				allErrors = errors.Join(allErrors, err)
+			}
+
+			if s, err := applicationsnapshot.DetermineInput(ctx, applicationsnapshot.Input{
+				File:      data.filePath,
+				JSON:      data.input,
+				Image:     data.imageRef,
+				Snapshot:  data.snapshot,
+				Images:    data.images,
+				Offline:   data.imageConfigPath != "",
+				Platforms: data.platforms,
 			}); err != nil {
 				allErrors = errors.Join(allErrors, err)
 			} else {
-				data.spec = s
+				data.spec = &s.SnapshotSpec
+				data.policyOverrides = s.PolicyOverrides
+				data.priorities = s.Priorities
+				data.applications = s.Applications
+				data.signatureVerifiers = s.SignatureVerifiers
+				data.imagePlatforms = s.Platforms
+			}
+
+			// Components with the same identity are evaluated once, unless
+			// all are to be evaluated. By default the same image, by digest,
+			// is evaluated once.
+			identityKey := data.identityKey
+			if identityKey == "" {
+				identityKey = applicationsnapshot.IdentityDigest
+			}
+			if data.spec != nil && slices.Contains(applicationsnapshot.IdentityKeys, identityKey) &&
+				data.duplicateComponents != applicationsnapshot.DuplicatesEvaluateAll {
+				// Components with a different policy override, or signature
+				// verifier, are evaluated separately
+				unique, duplicates := applicationsnapshot.DedupeComponents(data.spec.Components, identityKey, func(c app.SnapshotComponent) string {
+					return data.policyOverrides[c.ContainerImage] + "\x00" + data.signatureVerifiers[c.ContainerImage]
+				})
+				kept := make([]string, 0, len(duplicates))
+				for image := range duplicates {
+					kept = append(kept, image)
+				}
+				sort.Strings(kept)
+				if len(kept) > 0 && data.duplicateComponents == applicationsnapshot.DuplicatesError {
+					for _, image := range kept {
+						allErrors = errors.Join(allErrors, fmt.Errorf("duplicate components with the same %s: %s",
+							identityKey, strings.Join(append([]string{image}, duplicates[image]...), ", ")))
+					}
+				} else {
+					for _, image := range kept {
+						log.Infof("Evaluating %s once for the components with the same %s: %s", image, identityKey, strings.Join(duplicates[image], ", "))
+					}
+					data.spec.Components = unique
+					data.duplicates = duplicates
+				}
+			}
+
+			if data.approvedDigests != "" {
+				if approved, err := image.LoadApprovedDigests(ctx, data.approvedDigests); err != nil {
+					allErrors = errors.Join(allErrors, err)
+				} else {
+					data.approved = approved
+				}
+			}
+
+			if data.waiverFile != "" {
+				if waivers, err := waiver.Load(utils.FS(ctx), data.waiverFile); err != nil {
+					allErrors = errors.Join(allErrors, err)
+				} else {
+					data.waivers = waivers
+				}
+			}
+
+			if rules, err := fingerprint.ParseRules(data.fingerprintRules); err != nil {
+				allErrors = errors.Join(allErrors, err)
+			} else {
+				data.normalizer = fingerprint.NewNormalizer(rules...)
+			}
+
+			if data.tufRoot != "" && data.tufMirror == "" {
+				allErrors = errors.Join(allErrors, errors.New("--tuf-root requires --tuf-mirror to be set"))
+			}
+
+			if data.tufMirror != "" {
+				// The trusted material for keyless verification is loaded
+				// from the TUF root, which needs to be initialized from the
+				// mirror before the policy is created.
+				if err := tufInitialize(ctx, data.tufRoot, data.tufMirror); err != nil {
+					allErrors = errors.Join(allErrors, fmt.Errorf("initializing the TUF root from mirror %s: %w", data.tufMirror, err))
+					return
+				}
+			}
+
+			if data.policyConfiguration == "" && source.IsOffline() {
+				// Offline the default policy can not be fetched from the cluster,
+				// the policy configuration of the bundle is used instead
+				if dir, err := source.BundleDir(); err == nil {
+					data.policyConfiguration = filepath.Join(dir, source.BundlePolicy)
+				}
 			}
 
 			policyConfiguration, err := validate_utils.GetPolicyConfig(ctx, data.policyConfiguration)
@@ -208,21 +711,43 @@ func validateImageCmd(validate imageValidationFunc) *cobra.Command {
 			}
 			data.policyConfiguration = policyConfiguration
 
-			if p, err := policy.NewPolicy(cmd.Context(), policy.Options{
-				EffectiveTime: data.effectiveTime,
-				Identity: cosign.Identity{
-					Issuer:        data.certificateOIDCIssuer,
-					IssuerRegExp:  data.certificateOIDCIssuerRegExp,
-					Subject:       data.certificateIdentity,
-					SubjectRegExp: data.certificateIdentityRegExp,
-				},
-				IgnoreRekor: data.ignoreRekor,
-				PolicyRef:   data.policyConfiguration,
-				PublicKey:   data.publicKey,
-				RekorURL:    data.rekorURL,
-			}); err != nil {
-				allErrors = errors.Join(allErrors, err)
-			} else {
+			if data.imageConfigPath != "" {
+				if data.imageRef == "" {
+					allErrors = errors.Join(allErrors, errors.New("--image-config requires --image to be set"))
+				} else if b, err := validate_utils.ReadFile(ctx, data.imageConfigPath); err != nil {
+					allErrors = errors.Join(allErrors, err)
+				} else if config, err := image.ParseImageConfig([]byte(b)); err != nil {
+					allErrors = errors.Join(allErrors, fmt.Errorf("%s: %w", data.imageConfigPath, err))
+				} else {
+					data.imageConfig = config
+				}
+			}
+
+			newPolicy := func(policyRef string) (policy.Policy, error) {
+				if data.imageConfigPath != "" {
+					// Nothing is verified using the signature options when
+					// validating the image config offline
+					return policy.NewInputPolicy(ctx, policyRef, data.effectiveTime)
+				}
+
+				p, err := policy.NewPolicy(ctx, policy.Options{
+					EffectiveTime: data.effectiveTime,
+					Identity: cosign.Identity{
+						Issuer:        data.certificateOIDCIssuer,
+						IssuerRegExp:  data.certificateOIDCIssuerRegExp,
+						Subject:       data.certificateIdentity,
+						SubjectRegExp: data.certificateIdentityRegExp,
+					},
+					IgnoreRekor:  data.ignoreRekor,
+					PolicyRef:    policyRef,
+					PublicKey:    data.publicKey,
+					RekorURL:     data.rekorURL,
+					RequireRekor: data.requireRekor,
+				})
+				if err != nil {
+					return nil, err
+				}
+
 				// inject extra variables into rule data per source
 				if len(data.extraRuleData) > 0 {
 					policySpec := p.Spec()
@@ -250,6 +775,14 @@ func validateImageCmd(validate imageValidationFunc) *cobra.Command {
 							if len(parts) < 2 {
 								log.Errorf("Incorrect syntax for --extra-rule-data")
 							}
+							if validate_utils.IsCUEFile(parts[1]) {
+								value, err := validate_utils.ReadCUE(ctx, parts[1])
+								if err != nil {
+									return nil, fmt.Errorf("unable to load the rule data %q: %w", parts[0], err)
+								}
+								unmarshaled[parts[0]] = value
+								continue
+							}
 							extraRuleDataPolicyConfig, err := validate_utils.GetPolicyConfig(ctx, parts[1])
 							if err != nil {
 								log.Errorf("Unable to load data from extraRuleData: %s", err.Error())
@@ -273,7 +806,41 @@ func validateImageCmd(validate imageValidationFunc) *cobra.Command {
 					policySpec.Sources = sources
 					p = p.WithSpec(policySpec)
 				}
+
+				return p, nil
+			}
+
+			if p, err := newPolicy(data.policyConfiguration); err != nil {
+				allErrors = errors.Join(allErrors, err)
+			} else {
 				data.policy = p
+				if _, err := evaluator.PolicyFailOnSeverity(p.Spec()); err != nil {
+					allErrors = errors.Join(allErrors, err)
+				}
+			}
+
+			// Policies of components with a policy override, by the policy
+			// configuration given in the override
+			data.overridePolicies = map[string]policy.Policy{}
+			for image, ref := range data.policyOverrides {
+				if _, ok := data.overridePolicies[ref]; ok {
+					continue
+				}
+
+				// The snapshot is not trusted to select the policy, only the
+				// policies allowed by the operator are used
+				if !slices.Contains(data.allowedPolicyOverrides, ref) {
+					allErrors = errors.Join(allErrors, fmt.Errorf("policy override of component %s: %q is not allowed, allow it with --allow-policy-override", image, ref))
+					continue
+				}
+
+				policyConfiguration, err := validate_utils.GetPolicyConfig(ctx, ref)
+				if err == nil {
+					data.overridePolicies[ref], err = newPolicy(policyConfiguration)
+				}
+				if err != nil {
+					allErrors = errors.Join(allErrors, fmt.Errorf("policy override of component %s: %w", image, err))
+				}
 			}
 
 			return
@@ -285,57 +852,211 @@ func validateImageCmd(validate imageValidationFunc) *cobra.Command {
 				component   applicationsnapshot.Component
 				data        []evaluator.Data
 				policyInput []byte
+				policy      policy.Policy
+				target      string
+				duration    time.Duration
+			}
+
+			if data.printEffectiveConfig != "" {
+				overrides := map[string]policy.Policy{}
+				for image, ref := range data.policyOverrides {
+					overrides[image] = data.overridePolicies[ref]
+				}
+				// Already validated in PreRunE
+				fallbacks, _ := source.ParseFallbacks(data.policyFallbacks)
+				config := newEffectiveConfig(cmd, data.policy, overrides, data.lockfile, fallbacks)
+				return config.write(cmd.OutOrStdout(), data.printEffectiveConfig)
 			}
 
 			appComponents := data.spec.Components
-			evaluators := []evaluator.Evaluator{}
 
-			// Return an evaluator for each of these
-			for _, sourceGroup := range data.policy.Spec().Sources {
-				// Todo: Make each fetch run concurrently
-				log.Debugf("Fetching policy source group '%s'", sourceGroup.Name)
-				policySources, err := source.FetchPolicySources(sourceGroup)
+			// failOnSeverity returns the severity level from which violations
+			// fail the validation with the policy, --fail-on-severity takes
+			// precedence over the policy configuration
+			failOnSeverity := func(p policy.Policy) string {
+				if data.failOnSeverity != "" {
+					return data.failOnSeverity
+				}
+				level, _ := evaluator.PolicyFailOnSeverity(p.Spec())
+				return level
+			}
+
+			if data.waivers != nil {
+				for _, w := range data.waivers.Expired(data.policy.EffectiveTime()) {
+					log.Warnf("The waiver of %s for %s expired on %s", w.Code, cmp.Or(w.Component, w.Image), w.Expires)
+				}
+			}
+
+			if data.imageConfig != nil {
+				validate = func(ctx context.Context, comp app.SnapshotComponent, snap *app.SnapshotSpec, _ policy.Policy, evaluators []evaluator.Evaluator, detailed bool) (*output.Output, error) {
+					return image.ValidateImageConfig(ctx, comp, snap, data.imageConfig, evaluators, detailed)
+				}
+			}
+
+			// The attestation types required by the rule collections the
+			// policy selects
+			var requiredTypes []string
+			if len(data.requiredTypes) > 0 {
+				requiredTypes = data.requiredTypes.For(evaluator.SelectedCollections(data.policy))
+			}
+
+			// newEvaluators returns an evaluator for each of the source groups of
+			// the policy
+			newEvaluators := func(p policy.Policy) ([]evaluator.Evaluator, error) {
+				evaluators := []evaluator.Evaluator{}
+				for _, sourceGroup := range p.Spec().Sources {
+					log.Debugf("Fetching policy source group '%s'", sourceGroup.Name)
+					policySources, err := source.FetchPolicySources(sourceGroup)
+					if err != nil {
+						log.Debugf("Failed to fetch policy source group '%s'!", sourceGroup.Name)
+						return evaluators, err
+					}
+
+					for _, policySource := range policySources {
+						log.Debugf("policySource: %#v", policySource)
+					}
+
+					c, err := newConftestEvaluator(cmd.Context(), policySources, p, sourceGroup)
+					if err != nil {
+						log.Debug("Failed to initialize the conftest evaluator!")
+						return evaluators, err
+					}
+
+					evaluators = append(evaluators, c)
+				}
+				return evaluators, nil
+			}
+
+			// Record the sources fetched, along with the policy inputs, to be
+			// replayed using ec replay
+			var recorder *replay.Recorder
+			if data.dumpInput != "" {
+				staging, err := utils.CreateWorkDir(utils.FS(cmd.Context()))
 				if err != nil {
-					log.Debugf("Failed to fetch policy source group '%s'!", sourceGroup.Name)
 					return err
 				}
+				defer utils.CleanupWorkDir(utils.FS(cmd.Context()), staging)
+				recorder = replay.NewRecorder(staging)
+				cmd.SetContext(recorder.Context(cmd.Context()))
+			}
 
-				for _, policySource := range policySources {
-					log.Debugf("policySource: %#v", policySource)
+			var allEvaluators []evaluator.Evaluator
+			defer func() {
+				for _, e := range allEvaluators {
+					e.Destroy()
 				}
+			}()
+
+			// Fetch the sources of all of the policies up front, concurrently,
+			// the evaluators then reuse the fetched sources. Sources that can
+			// not be fetched are reported when evaluated.
+			policies := []policy.Policy{data.policy}
+			for _, p := range data.overridePolicies {
+				policies = append(policies, p)
+			}
+			var allSources []source.PolicySource
+			for _, p := range policies {
+				for _, sourceGroup := range p.Spec().Sources {
+					policySources, err := source.FetchPolicySources(sourceGroup)
+					if err != nil {
+						return err
+					}
+					allSources = append(allSources, policySources...)
+				}
+			}
+			fs := utils.FS(cmd.Context())
+			prefetchDir, err := utils.CreateWorkDir(fs)
+			if err != nil {
+				return err
+			}
+			defer func() {
+				_ = fs.RemoveAll(prefetchDir)
+			}()
+			if err := source.PreFetch(cmd.Context(), prefetchDir, allSources); err != nil {
+				log.Debugf("Unable to fetch some of the policy sources: %v", err)
+			}
+
+			evaluators, err := newEvaluators(data.policy)
+			allEvaluators = append(allEvaluators, evaluators...)
+			if err != nil {
+				return err
+			}
 
-				c, err := newConftestEvaluator(cmd.Context(), policySources, data.policy, sourceGroup)
+			overrideEvaluators := map[string][]evaluator.Evaluator{}
+			for ref, p := range data.overridePolicies {
+				evaluators, err := newEvaluators(p)
+				allEvaluators = append(allEvaluators, evaluators...)
 				if err != nil {
-					log.Debug("Failed to initialize the conftest evaluator!")
 					return err
 				}
-
-				evaluators = append(evaluators, c)
-				defer c.Destroy()
+				overrideEvaluators[ref] = evaluators
 			}
 
+			// The validations given up on after --component-timeout keep
+			// running in the background, the evaluators and the fetched
+			// sources they use are removed only once they return
+			var pending sync.WaitGroup
+			defer pending.Wait()
+
 			showSuccesses, _ := cmd.Flags().GetBool("show-successes")
 
+			var coverage *evaluator.Coverage
+			if data.coverage {
+				coverage = evaluator.NewCoverage()
+			}
+
 			// worker is responsible for processing one component at a time from the jobs channel,
 			// and for emitting a corresponding result for the component on the results channel.
 			worker := func(id int, jobs <-chan app.SnapshotComponent, results chan<- result) {
 				log.Debugf("Starting worker %d", id)
 				for comp := range jobs {
 					log.Debugf("Worker %d got a component %q", id, comp.ContainerImage)
-					ctx := cmd.Context()
-					out, err := validate(ctx, comp, data.spec, data.policy, evaluators, data.info)
+					ctx, span := tracing.Start(cmd.Context(), "evaluate",
+						tracing.ComponentName.String(comp.Name),
+						tracing.ComponentImage.String(comp.ContainerImage))
+					if coverage != nil {
+						ctx = evaluator.WithCoverage(ctx, coverage)
+					}
+					p, evaluators := data.policy, evaluators
+					override, overridden := data.policyOverrides[comp.ContainerImage]
+					if overridden {
+						log.Debugf("Using the policy override %q for component %q", override, comp.ContainerImage)
+						p, evaluators = data.overridePolicies[override], overrideEvaluators[override]
+					}
+					verifier := data.signatureVerifier
+					if v, ok := data.signatureVerifiers[comp.ContainerImage]; ok {
+						verifier = v
+					}
+					ctx = signature.WithVerifier(ctx, verifier)
+					start := time.Now()
+					out, err := validateWithTimeout(ctx, data.componentTimeout, &pending, func(ctx context.Context) (*output.Output, error) {
+						return validate(ctx, comp, data.spec, p, evaluators, data.info)
+					})
+					timedOut := errors.Is(err, errComponentTimeout)
 					res := result{
 						err: err,
 						component: applicationsnapshot.Component{
 							SnapshotComponent: comp,
 							Success:           err == nil,
+							PolicyOverride:    override,
+							Duplicates:        data.duplicates[comp.ContainerImage],
 						},
 					}
+					if len(data.applications) > 0 {
+						// Components without an application belong to the
+						// application of the snapshot
+						res.component.Application = data.applications[comp.ContainerImage]
+						if res.component.Application == "" {
+							res.component.Application = data.spec.Application
+						}
+					}
 
 					// Skip on err to not panic. Error is return on routine completion.
 					if err == nil {
+						out.UnsignedImage = data.unsignedImage
 						res.component.Violations = out.Violations()
 						res.component.Warnings = out.Warnings()
+						res.component.Infos = out.Infos()
 
 						successes := out.Successes()
 						res.component.SuccessCount = len(successes)
@@ -349,8 +1070,106 @@ func validateImageCmd(validate imageValidationFunc) *cobra.Command {
 						res.data = out.Data
 						res.component.Attestations = out.Attestations
 						res.policyInput = out.PolicyInput
+						res.policy = p
+						res.target = out.Target
+
+						if out.NoApplicableRules {
+							res.component.NoApplicableRules = true
+							switch data.noApplicableRules {
+							case output.NoApplicableRulesWarn:
+								res.component.Warnings = append(res.component.Warnings, out.NoApplicableRulesResult())
+							case output.NoApplicableRulesFail:
+								res.component.Violations = append(res.component.Violations, out.NoApplicableRulesResult())
+							}
+						}
+
+						switch data.signatureTime {
+						case output.SignatureTimeWarn:
+							res.component.Warnings = append(res.component.Warnings, out.SignatureTimeResults()...)
+						case output.SignatureTimeFail:
+							res.component.Violations = append(res.component.Violations, out.SignatureTimeResults()...)
+						}
+
+						if data.minAttestationSigners > 0 {
+							res.component.Violations = append(res.component.Violations, out.AttestationSignersResults(data.minAttestationSigners)...)
+						}
+
+						if data.requireDigest {
+							// The images of an image index are referenced by
+							// digest, the reference of the index is checked
+							original := comp.ContainerImage
+							if p, ok := data.imagePlatforms[comp.ContainerImage]; ok {
+								original = p.Index.ContainerImage
+							}
+							ref, err := image.NewImageReference(original)
+							pinned := err == nil && ref.Digest != ""
+							if pinned {
+								res.component.SuccessCount++
+								if showSuccesses {
+									res.component.Successes = append(res.component.Successes, out.ImageDigestPinnedResult(pinned))
+								}
+							} else {
+								res.component.Violations = append(res.component.Violations, out.ImageDigestPinnedResult(pinned))
+							}
+						}
+
+						for _, predicateType := range requiredTypes {
+							present := out.HasAttestationType(predicateType)
+							if present {
+								res.component.SuccessCount++
+								if showSuccesses {
+									res.component.Successes = append(res.component.Successes, out.RequiredAttestationTypeResult(predicateType, present))
+								}
+							} else {
+								res.component.Violations = append(res.component.Violations, out.RequiredAttestationTypeResult(predicateType, present))
+							}
+						}
+
+						if data.approved != nil {
+							var digest string
+							if ref, err := image.NewImageReference(out.ImageURL); err == nil {
+								digest = ref.Digest
+							}
+							if data.approved.Contains(digest) {
+								res.component.SuccessCount++
+								if showSuccesses {
+									res.component.Successes = append(res.component.Successes, out.ApprovedDigestResult(digest, true))
+								}
+							} else {
+								res.component.Violations = append(res.component.Violations, out.ApprovedDigestResult(digest, false))
+							}
+						}
+
+						// The waivers can match the violations by fingerprint
+						res.component.SetFingerprints(data.normalizer)
+						data.waivers.Apply(&res.component, p.EffectiveTime())
 					}
-					res.component.Success = err == nil && len(res.component.Violations) == 0
+					threshold := failOnSeverity(p)
+					res.component.Success = err == nil && !slices.ContainsFunc(res.component.Violations, func(v evaluator.Result) bool {
+						return evaluator.Blocking(v, threshold)
+					})
+
+					// Unless aborting, an evaluation error is reported for the
+					// component instead of stopping the validation. A component
+					// that timed out is always reported, so that it does not
+					// stop the validation of the other components.
+					if err != nil && (data.evaluationErrors != output.EvaluationErrorAbort || timedOut) {
+						log.Debugf("Reporting the evaluation error of component %q: %v", comp.ContainerImage, err)
+						res.err = nil
+						res.component.EvaluationError = err.Error()
+						res.component.TimedOut = timedOut
+						res.component.Success = data.evaluationErrors == output.EvaluationErrorWarn
+					}
+					res.duration = time.Since(start)
+
+					if ref, err := image.NewImageReference(res.component.ContainerImage); err == nil && ref.Digest != "" {
+						span.SetAttributes(tracing.ComponentDigest.String(ref.Digest))
+					}
+					span.SetAttributes(
+						tracing.Verdict.String(verdict(res.component.Success)),
+						tracing.Violations.Int(len(res.component.Violations)),
+						tracing.Warnings.Int(len(res.component.Warnings)))
+					tracing.End(span, err)
 
 					results <- res
 				}
@@ -362,33 +1181,43 @@ func validateImageCmd(validate imageValidationFunc) *cobra.Command {
 			// Set numWorkers to the value from our flag. The default is 5.
 			numWorkers := data.workers
 
-			jobs := make(chan app.SnapshotComponent, numComponents)
-			results := make(chan result, numComponents)
+			// In benchmark mode each component is validated repeatedly
+			iterations := 1
+			if data.benchmark > 0 {
+				iterations = data.benchmark
+			}
+			numJobs := numComponents * iterations
+			cacheHits, cacheMisses := source.DownloadCacheStats()
+			start := time.Now()
+
+			jobs := make(chan app.SnapshotComponent, numJobs)
+			results := make(chan result, numJobs)
 			// Initialize each worker. They will wait patiently until a job is sent to the jobs
 			// channel, or the jobs channel is closed.
-			for i := 0; i <= numWorkers; i++ {
+			for i := 0; i < numWorkers; i++ {
 				go worker(i, jobs, results)
 			}
 			// Initialize all the jobs. Each worker will pick a job from the channel when the worker
-			// is ready to consume a new job.
-			for _, c := range appComponents {
-				jobs <- c
+			// is ready to consume a new job. Prioritized components are dispatched first.
+			ordered := applicationsnapshot.OrderComponents(appComponents, data.priorities, data.componentOrder)
+			for i := 0; i < iterations; i++ {
+				for _, c := range ordered {
+					jobs <- c
+				}
 			}
 			close(jobs)
 
-			var components []applicationsnapshot.Component
-			var manyData [][]evaluator.Data
-			var manyPolicyInput [][]byte
+			var completed []result
 			var allErrors error = nil
-			for i := 0; i < numComponents; i++ {
+			latencies := make([]time.Duration, 0, numJobs)
+			for i := 0; i < numJobs; i++ {
 				r := <-results
+				latencies = append(latencies, r.duration)
 				if r.err != nil {
 					e := fmt.Errorf("error validating image %s of component %s: %w", r.component.ContainerImage, r.component.Name, r.err)
 					allErrors = errors.Join(allErrors, e)
-				} else {
-					components = append(components, r.component)
-					manyData = append(manyData, r.data)
-					manyPolicyInput = append(manyPolicyInput, r.policyInput)
+				} else if data.benchmark == 0 {
+					completed = append(completed, r)
 				}
 			}
 			close(results)
@@ -396,26 +1225,112 @@ func validateImageCmd(validate imageValidationFunc) *cobra.Command {
 				return allErrors
 			}
 
-			// Ensure some consistency in output.
-			sort.Slice(components, func(i, j int) bool {
-				return components[i].ContainerImage > components[j].ContainerImage
+			// The workers complete in any order, ensure some consistency in
+			// output, including the data and the policy input of the components.
+			slices.SortStableFunc(completed, func(a, b result) int {
+				if c := cmp.Compare(b.component.ContainerImage, a.component.ContainerImage); c != 0 {
+					return c
+				}
+				return cmp.Compare(a.component.Name, b.component.Name)
 			})
 
+			components := make([]applicationsnapshot.Component, 0, len(completed))
+			manyData := make([][]evaluator.Data, 0, len(completed))
+			manyPolicyInput := make([][]byte, 0, len(completed))
+			for _, r := range completed {
+				components = append(components, r.component)
+				manyData = append(manyData, r.data)
+				manyPolicyInput = append(manyPolicyInput, r.policyInput)
+				// Components without a policy input, e.g. without attestations,
+				// were not evaluated
+				if recorder != nil && r.policyInput != nil {
+					recorder.Add(r.component.SnapshotComponent, r.policy, r.target, r.policyInput)
+				}
+			}
+
+			if recorder != nil {
+				if err := recorder.Write(utils.FS(cmd.Context()), data.dumpInput); err != nil {
+					return fmt.Errorf("writing the policy inputs to %s: %w", data.dumpInput, err)
+				}
+			}
+
+			if data.benchmark > 0 {
+				hits, misses := source.DownloadCacheStats()
+				return applicationsnapshot.Benchmark{
+					Iterations:  iterations,
+					Components:  numComponents,
+					Elapsed:     time.Since(start),
+					Latencies:   latencies,
+					CacheHits:   hits - cacheHits,
+					CacheMisses: misses - cacheMisses,
+				}.WriteText(cmd.OutOrStdout())
+			}
+
+			switch {
+			case data.identityKey == "":
+			case data.duplicateComponents == applicationsnapshot.DuplicatesEvaluateAll:
+				// All of the evaluated components are reported
+				components = applicationsnapshot.SortComponents(components, data.identityKey)
+			default:
+				// Components with the same identity are reported once
+				components = applicationsnapshot.UniqueComponents(components, data.identityKey)
+			}
+
+			// The results of the platforms of image indexes are reported
+			// within the component of the image index
+			components = applicationsnapshot.NestPlatforms(components, data.imagePlatforms)
+
+			outputs := slices.Clone(data.output)
 			if len(data.outputFile) > 0 {
-				data.output = append(data.output, fmt.Sprintf("%s=%s", applicationsnapshot.JSON, data.outputFile))
+				outputs = append(outputs, fmt.Sprintf("%s=%s", applicationsnapshot.JSON, data.outputFile))
 			}
 
 			report, err := applicationsnapshot.NewReport(data.snapshot, components, data.policy, manyData, manyPolicyInput, showSuccesses)
 			if err != nil {
 				return err
 			}
-			p := format.NewTargetParser(applicationsnapshot.JSON, format.Options{ShowSuccesses: showSuccesses}, cmd.OutOrStdout(), utils.FS(cmd.Context()))
+			report.FailOnSeverity = failOnSeverity(data.policy)
+			report.IdentityKey = data.identityKey
+			report.PolicyFallbacks = applicationsnapshot.NewPolicyFallbacks(source.UsedFallbacks())
+			report.PolicySources = applicationsnapshot.NewPolicySources(source.ResolvedSources())
+			if coverage != nil {
+				report.Coverage = coverage.Summary()
+			}
+			p := format.NewTargetParser(applicationsnapshot.JSON, format.Options{ShowSuccesses: showSuccesses, VerboseRules: data.verboseRules, Redact: data.redact}, cmd.OutOrStdout(), utils.FS(cmd.Context()))
+			p.RegisterFormatSink(applicationsnapshot.OCI, applicationsnapshot.JSON, applicationsnapshot.NewReferrerSink(cmd.Context()))
+			summaryPath := os.Getenv("GITHUB_STEP_SUMMARY")
+			if summaryPath != "" {
+				p.RegisterSink(applicationsnapshot.GitHubStepSummary, format.NewAppendFileSink(summaryPath, utils.FS(cmd.Context())))
+			}
+			outputs = applicationsnapshot.WithGitHubStepSummary(outputs, summaryPath)
 			utils.SetColorEnabled(data.noColor, data.forceColor)
-			if err := report.WriteAll(data.output, p); err != nil {
+			_, span := tracing.Start(cmd.Context(), "render",
+				tracing.OutputFormats.StringSlice(outputs),
+				tracing.Verdict.String(verdict(report.Success)))
+			err = report.WriteAll(outputs, p)
+			tracing.End(span, err)
+			if err != nil {
 				return err
 			}
 
-			if data.strict && !report.Success {
+			if data.vsa {
+				opts := vsa.Options{SigningKey: data.vsaSigningKey, Upload: data.vsaUpload, OutputDir: data.vsaOutputDir}
+				if err := vsa.Generate(cmd.Context(), utils.FS(cmd.Context()), &report, opts); err != nil {
+					return fmt.Errorf("generating the VSAs: %w", err)
+				}
+			}
+
+			if data.updateLockfile {
+				if err := data.lockfile.Write(utils.FS(cmd.Context()), data.lockfilePath); err != nil {
+					return fmt.Errorf("writing the lockfile: %w", err)
+				}
+			}
+
+			if lastReport != nil {
+				*lastReport = report
+			}
+
+			if data.strict && !report.Success && !data.watch {
 				return errors.New("success criteria not met")
 			}
 
@@ -423,6 +1338,34 @@ func validateImageCmd(validate imageValidationFunc) *cobra.Command {
 		},
 	}
 
+	// In watch mode the validation is run repeatedly, the report of each run
+	// is kept to be compared with the next one
+	validateOnce := cmd.RunE
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		if !data.watch {
+			return validateOnce(cmd, args)
+		}
+
+		return watch(cmd, data.watchInterval, func(ctx context.Context) (*applicationsnapshot.Report, error) {
+			var report applicationsnapshot.Report
+			lastReport = &report
+			defer func() { lastReport = nil }()
+
+			cmd.SetContext(ctx)
+			if err := validateOnce(cmd, args); err != nil {
+				return nil, err
+			}
+			return &report, nil
+		}, func(ctx context.Context) (revisions, error) {
+			policies := []policy.Policy{data.policy}
+			for _, p := range data.overridePolicies {
+				policies = append(policies, p)
+			}
+			// Sources pinned by the lockfile do not change
+			return currentRevisions(ctx, data.spec.Components, policies, data.lockfile == nil)
+		})
+	}
+
 	cmd.Flags().StringVarP(&data.policyConfiguration, "policy", "p", data.policyConfiguration, hd.Doc(`
 		Policy configuration as:
 		  * Kubernetes reference ([<namespace>/]<name>)
@@ -432,8 +1375,15 @@ func validateImageCmd(validate imageValidationFunc) *cobra.Command {
 
 	cmd.Flags().StringVarP(&data.imageRef, "image", "i", data.imageRef, "OCI image reference")
 
+	cmd.Flags().StringVar(&data.imageConfigPath, "image-config", data.imageConfigPath, hd.Doc(`
+		Path to a saved image config file, e.g. as produced by skopeo inspect --config,
+		of the image given by --image. The policy is evaluated against the image config
+		without accessing the registry, e.g. to check the image labels offline. The
+		image signature and attestations are not verified, and the policy input has no
+		attestations.`))
+
 	cmd.Flags().StringVarP(&data.publicKey, "public-key", "k", data.publicKey,
-		"path to the public key. Overrides publicKey from EnterpriseContractPolicy")
+		"path to the public key, or a KMS key reference such as awskms://, gcpkms://, azurekms:// or hashivault://. Overrides publicKey from EnterpriseContractPolicy")
 
 	cmd.Flags().StringVarP(&data.rekorURL, "rekor-url", "r", data.rekorURL,
 		"Rekor URL. Overrides rekorURL from EnterpriseContractPolicy")
@@ -441,6 +1391,44 @@ func validateImageCmd(validate imageValidationFunc) *cobra.Command {
 	cmd.Flags().BoolVar(&data.ignoreRekor, "ignore-rekor", data.ignoreRekor,
 		"Skip Rekor transparency log checks during validation.")
 
+	cmd.Flags().BoolVar(&data.requireRekor, "require-rekor", data.requireRekor, hd.Doc(`
+		Look up each signature and attestation in the Rekor transparency log at the
+		Rekor URL, verifying the inclusion proof and the signed entry timestamp of its
+		entry, even if it carries a bundle. The log index and the integrated time of the
+		entry are included in the report.`))
+
+	cmd.MarkFlagsMutuallyExclusive("ignore-rekor", "require-rekor")
+
+	cmd.Flags().StringVar(&data.signatureVerifier, "signature-verifier", data.signatureVerifier, hd.Doc(`
+		Verifier of the image signatures, one of: `+strings.Join(signature.Verifiers, ", ")+`.
+		With notation the Notation (Notary Project) signatures attached to the images
+		are verified against the Notation trust policy instead of the cosign signatures,
+		see --notation-trust-policy. The attestations are verified with cosign either
+		way. A component can select its verifier with the
+		`+applicationsnapshot.SignatureVerifierAnnotation+` annotation.`))
+
+	cmd.Flags().StringVar(&data.notationTrustPolicy, "notation-trust-policy", data.notationTrustPolicy, hd.Doc(`
+		Path to the Notation trust policy (trustpolicy.json) to verify the Notation
+		signatures against. Defaults to the trust policy and the trust store of the
+		notation configuration in $XDG_CONFIG_HOME/notation.`))
+
+	cmd.Flags().StringVar(&data.notationTrustStore, "notation-trust-store", data.notationTrustStore, hd.Doc(`
+		Path to the directory of the Notation trust store, holding the certificates
+		referenced by the trust policy in x509/<type>/<name>/ subdirectories. Defaults
+		to the notation configuration directory.`))
+
+	cmd.Flags().StringSliceVar(&data.sigstoreBundles, "sigstore-bundle", data.sigstoreBundles, hd.Doc(`
+		Path to a detached Sigstore bundle (application/vnd.dev.sigstore.bundle), e.g. as
+		written by cosign sign --new-bundle-format. May be used multiple times. Images
+		without signatures, or attestations, in the cosign format are verified with the
+		bundles for their digest, and with the bundles attached to them as OCI referrers.
+		The bundles are verified offline using the Rekor entries within them.`))
+
+	cmd.Flags().StringVar(&data.trustedRoot, "trusted-root", data.trustedRoot, hd.Doc(`
+		Path to the Sigstore trusted root (trusted_root.json) to verify the Sigstore
+		bundles with. Defaults to the trusted root from the TUF root, see
+		ec sigstore initialize.`))
+
 	cmd.Flags().StringVar(&data.certificateIdentity, "certificate-identity", data.certificateIdentity,
 		"URL of the certificate identity for keyless verification")
 
@@ -464,12 +1452,27 @@ func validateImageCmd(validate imageValidationFunc) *cobra.Command {
 	cmd.Flags().StringVar(&data.images, "images", data.images,
 		"path to ApplicationSnapshot Spec JSON file or JSON representation of an ApplicationSnapshot Spec")
 
+	cmd.Flags().StringSliceVar(&data.platforms, "platform", data.platforms, hd.Doc(`
+		Validate only the images of the given platforms of components that are image
+		indexes, given as <os>[/<arch>[/<variant>]], e.g. linux/amd64. Can be repeated.
+		By default the images of all the platforms of an image index are validated.`))
+
 	cmd.Flags().StringSliceVar(&data.output, "output", data.output, hd.Doc(`
 		write output to a file in a specific format. Use empty string path for stdout.
 		May be used multiple times. Possible formats are:
 		`+strings.Join(validOutputFormats, ", ")+`. In following format and file path
 		additional options can be provided in key=value form following the question
-		mark (?) sign, for example: --output text=output.txt?show-successes=false
+		mark (?) sign, for example: --output text=output.txt?show-successes=false.
+		The template format renders the report using the Go template file given as its
+		path, for example: --output template=report.tmpl, or by the template option to
+		write it to a file, for example: --output template=report.txt?template=report.tmpl
+		The compact format lists each component with a pass or fail indicator and its
+		results indented beneath, colored unless the output is not a terminal.
+		The oci target pushes the JSON report to the registry as an artifact referring
+		to the given image, for example: --output oci=registry.io/org/image:tag. The
+		report can then be retrieved with ec inspect report. Programs embedding ec can
+		deliver the report to a sink registered with the pkg/sink package, for example:
+		--output json=sink:<name>.
 	`))
 
 	cmd.Flags().StringVarP(&data.outputFile, "output-file", "o", data.outputFile,
@@ -487,6 +1490,8 @@ func validateImageCmd(validate imageValidationFunc) *cobra.Command {
 
 	cmd.Flags().StringSliceVar(&data.extraRuleData, "extra-rule-data", data.extraRuleData, hd.Doc(`
 		Extra data to be provided to the Rego policy evaluator. Use format 'key=value'. May be used multiple times.
+		A value that is the path of a CUE file, with the .cue extension, is evaluated to
+		concrete data, and the validation fails if any of the CUE constraints is violated.
 	`))
 
 	cmd.Flags().StringVar(&data.snapshot, "snapshot", "", hd.Doc(`
@@ -504,8 +1509,291 @@ func validateImageCmd(validate imageValidationFunc) *cobra.Command {
 	cmd.Flags().BoolVar(&data.forceColor, "color", data.info, hd.Doc(`
 		Enable color when using text output even when the current terminal does not support it`))
 
+	cmd.Flags().StringVar(&data.noApplicableRules, "no-applicable-rules", data.noApplicableRules, hd.Doc(`
+		How to report an image for which none of the policy rules were applicable, e.g.
+		due to the include and exclude criteria. Possible values are: `+strings.Join(output.NoApplicableRulesModes, ", ")+`.
+		Such images are always marked as having no applicable rules in the report.`))
+
+	cmd.Flags().StringVar(&data.signatureTime, "signature-time", data.signatureTime, hd.Doc(`
+		How to report an image signature made before the image was created, as that
+		suggests the signature was replayed or backdated. Possible values are: `+strings.Join(output.SignatureTimeModes, ", ")+`.
+		The time of a signature is taken from its transparency log entry, or lacking one
+		from the start of the validity of its certificate. The image creation time is
+		taken from the image config. A signature of unknown time, or an image of unknown
+		creation time, is reported the same way.`))
+
+	cmd.Flags().IntVar(&data.minAttestationSigners, "min-attestation-signers", data.minAttestationSigners, hd.Doc(`
+		Fail the validation of any image with an attestation signed by fewer distinct
+		signers than the given number. Signers are distinguished by the identity of
+		their signing certificate, all signatures verified with the public key count
+		as a single signer. Disabled when 0, the default.`))
+
+	cmd.Flags().StringVar(&data.maxAttestationSize, "max-attestation-size", data.maxAttestationSize, hd.Doc(`
+		Maximum size of an attestation, e.g. 64MiB. Larger attestations are rejected
+		with an error instead of being read into memory.`))
+
+	cmd.Flags().BoolVar(&data.strictDataSources, "strict-data-sources", data.strictDataSources, hd.Doc(`
+		Fail if any of the data sources of the policy provided no data, e.g. due to a
+		wrong URL. By default such data sources are reported with a warning.`))
+
+	cmd.Flags().BoolVar(&data.strictInput, "strict-input", data.strictInput, hd.Doc(`
+		Report a warning for each reference the policy rules make to a path that is
+		not present in the input, e.g. input.image.ref when the input has no such key.
+		Helps to catch typos in rules. Off by default.`))
+
+	cmd.Flags().StringArrayVar(&data.ruleEffectiveOn, "rule-effective-on", data.ruleEffectiveOn, hd.Doc(`
+		Effective time of a policy rule, given as <rule code>=<time>, e.g.
+		tasks.required_tasks_found=2024-06-30. The time is a date or a RFC3339
+		timestamp. Until then, failures of the rule are reported as warnings, after it
+		they are blocking. Takes precedence over the effective_on annotation of the
+		rule. Can be repeated.`))
+
+	cmd.Flags().StringArrayVar(&data.severityOverrides, "severity-override", data.severityOverrides, hd.Doc(`
+		Severity to report the results of a policy rule with, given as
+		<rule code>=<severity>, e.g. tasks.required_tasks_found=warning. The severity
+		is violation, warning or info, or a level of violation: `+strings.Join(evaluator.SeverityLevels, ", ")+`.
+		A package name in place of the rule code applies to all rules of the package.
+		The original severity is recorded in the original_severity metadata of the
+		result. Takes precedence over the severity_overrides in the rule data of the
+		policy sources. Can be repeated.`))
+
+	cmd.Flags().StringVar(&data.failOnSeverity, "fail-on-severity", data.failOnSeverity, hd.Doc(`
+		Fail only on violations of the given level or above, one of: `+strings.Join(evaluator.SeverityLevels, ", ")+`.
+		The level of a violation is set by the severity annotation of the rule, or by
+		an override, violations without a level always fail. Other violations are
+		reported, but do not fail the validation. Takes precedence over the
+		fail_on_severity in the rule data of the policy sources.`))
+
+	cmd.Flags().StringSliceVar(&data.explain, "explain", data.explain, hd.Doc(`
+		Code of a policy rule to explain, e.g. tasks.required_tasks_found. The
+		evaluation of the rule is traced, showing each expression of the rule that
+		was evaluated or failed, with the values bound to its variables. The trace is
+		included in the trace metadata of the results of the rule, and shown in the
+		text output. Can be repeated.`))
+
+	cmd.Flags().BoolVar(&data.coverage, "coverage", data.coverage, hd.Doc(`
+		Include the coverage of the policy rules in the report: the rules that were
+		evaluated, the rules skipped by the include and exclude policy configuration,
+		and the rules that reported no result for any of the components. Helps to find
+		rules that are never checked.`))
+
+	cmd.Flags().StringVar(&data.dumpInput, "dump-input", data.dumpInput, hd.Doc(`
+		Write the policy input of each component, exactly as given to the policy
+		rules, to the directory, along with the policy configuration and the policy
+		and data sources. Use ec replay to evaluate the policy inputs again without
+		network access, e.g. to reproduce a failed validation.`))
+
+	cmd.Flags().StringArrayVar(&data.allowedPolicyOverrides, "allow-policy-override", data.allowedPolicyOverrides, hd.Doc(`
+		Policy configuration a component may be validated against instead of the policy
+		given by --policy, selected by the `+applicationsnapshot.PolicyOverrideAnnotation+` annotation
+		of the component. The value must match the annotation exactly. Can be repeated.
+		The validation fails for components selecting any other policy.`))
+
+	cmd.Flags().StringArrayVar(&data.policyFallbacks, "policy-fallback", data.policyFallbacks, hd.Doc(`
+		Fallback for a policy or data source of the policy, given as <source>=<fallback>,
+		e.g. a source mirror or a known-good bundle. The fallback is used if the source
+		can not be fetched. Can be repeated to give multiple fallbacks for a source,
+		these are tried in the order given. The use of a fallback is noted in the report.`))
+
+	cmd.Flags().StringArrayVar(&data.regoVersions, "rego-version", data.regoVersions, hd.Doc(`
+		Version of the Rego language the policy of a source is written in, given as
+		<source>=<version>, where the version is v0 or v1, e.g. v1 for policies written
+		for OPA 1.0. By default the version is detected for each module of the source,
+		modules that can only be read as Rego v1 are read as such. Can be repeated.`))
+
+	cmd.Flags().StringArrayVar(&data.policySourceKeys, "policy-source-key", data.policySourceKeys, hd.Doc(`
+		Public key the content of a policy or data source of the policy must be signed
+		with, given as <source>=<key>. The key is a path to a public key file, or any
+		key reference supported by cosign, e.g. k8s://namespace/secret or a KMS key such
+		as gcpkms://projects/p/locations/l/keyRings/r/cryptoKeys/k. The source must
+		be an OCI source, its cosign signature is verified for the image digest fetched
+		and the validation fails if no signature matches the key. The source can also
+		be an OPA bundle, its .signatures.json is verified with the key, given as a path
+		to a PEM file, before the bundle is extracted. A fallback source is verified with
+		its own key, if given. Can be repeated.`))
+
+	cmd.Flags().StringArrayVar(&data.policySourceHeaders, "policy-source-header", data.policySourceHeaders, hd.Doc(`
+		HTTP header to send when fetching the policy and data sources hosted on the
+		given HTTPS server, e.g. an artifact server, given as <host>=<name>: <value>.
+		Environment variables in the value are expanded, e.g. for bearer authentication
+		use 'artifacts.example.com=Authorization: Bearer ${TOKEN}'. Can be repeated.`))
+
+	cmd.Flags().StringVar(&data.policySourceCABundle, "policy-source-ca-bundle", data.policySourceCABundle, hd.Doc(`
+		Path to a PEM file with the certificates of the certificate authorities trusted,
+		in addition to the system ones, when fetching the policy and data sources hosted
+		on HTTPS servers.`))
+
+	cmd.Flags().StringVar(&data.registryCredentials, "registry-credentials", data.registryCredentials, hd.Doc(`
+		Path to a file with credentials for the registries, in the format of the Docker
+		config.json file, i.e. {"auths": {"registry.io": {"auth": "<base64 of user:password>"}}}.
+		The keys are registry hosts, optionally with a repository path. For each image
+		the credentials of the longest matching key within the registry of the image are
+		used, falling back to the Docker configuration. Credentials are never sent to a
+		registry other than the one they are given for.`))
+
+	cmd.Flags().StringVar(&data.lockfilePath, "lockfile", data.lockfilePath, hd.Doc(`
+		Path to a lockfile pinning the policy, data and configuration sources to the
+		digests of their content, for hermetic runs. Only the sources listed in the
+		lockfile are fetched, from the location given for the source if any, e.g. a
+		mirror, or else OCI sources by the image digest recorded in the lockfile. The
+		validation fails if the content of any source differs from the lockfile. See
+		ec policy lock, or --update-lockfile, to create the lockfile.`))
+
+	cmd.Flags().BoolVar(&data.updateLockfile, "update-lockfile", data.updateLockfile, hd.Doc(`
+		Write the digests of all sources fetched during the validation to the file given
+		by --lockfile instead of verifying them.`))
+
+	cmd.Flags().BoolVar(&data.vsa, "vsa", data.vsa, hd.Doc(`
+		Produce a signed SLSA Verification Summary Attestation (VSA) for each component,
+		recording the outcome of the validation, so that consumers of the image can
+		rely on it instead of validating the image again. Requires --vsa-signing-key,
+		and --vsa-upload or --vsa-output-dir.`))
+
+	cmd.Flags().StringVar(&data.vsaSigningKey, "vsa-signing-key", data.vsaSigningKey, hd.Doc(`
+		Reference of the key the VSAs are signed with, a path to a cosign private key or
+		any key reference supported by cosign, e.g. k8s://namespace/secret or a KMS key
+		such as awskms:///arn:aws:kms:us-east-1:111122223333:key/1234abcd. The password
+		of an encrypted key is read from the COSIGN_PASSWORD environment variable.`))
+
+	cmd.Flags().BoolVar(&data.vsaUpload, "vsa-upload", data.vsaUpload, hd.Doc(`
+		Attach the VSA of each component to its image in the registry, alongside the
+		attestations of the image.`))
+
+	cmd.Flags().StringVar(&data.vsaOutputDir, "vsa-output-dir", data.vsaOutputDir, hd.Doc(`
+		Directory to write the VSA of each component to, as a DSSE envelope in a file
+		named by the image digest, e.g. sha256-<digest>.vsa.json.`))
+
+	cmd.Flags().StringArrayVar(&data.requiredAttestationTypes, "required-attestation-type", data.requiredAttestationTypes, hd.Doc(`
+		Predicate type of an attestation required when a rule collection is selected by
+		the policy, given as <collection>=<predicate type>, e.g.
+		minimal=https://slsa.dev/provenance/v0.2. Use * as the collection to require the
+		attestation type regardless of the selected collections. Can be repeated. Images
+		without an attestation of a required type fail the validation.`))
+
+	cmd.Flags().BoolVar(&data.requireDigest, "require-digest", data.requireDigest, hd.Doc(`
+		Fail the validation of any image that is referenced only by a tag and not
+		by a digest, e.g. registry.io/repository/image@sha256:<digest>. The images of
+		an image index are checked by the reference of the image index. Off by default.`))
+
+	cmd.Flags().StringSliceVar(&data.redact, "redact", data.redact, hd.Doc(`
+		Mask the given fields in the output to produce a report that can be shared.
+		Possible values are: `+strings.Join(applicationsnapshot.RedactionFields, ", ")+`.
+		The masked values are replaced with REDACTED and the redacted fields are
+		listed in the report. Can be set per output with the redact option, e.g.
+		--output json=report.json?redact=registry,source, an empty value disables
+		redaction for the output. The data, attestation and policy-input formats
+		can not be redacted.`))
+
+	cmd.Flags().StringVar(&data.approvedDigests, "approved-digests", data.approvedDigests, hd.Doc(`
+		Path or URL of a list of approved image digests, one per line. Any image with
+		a digest not in the list fails the validation. Lines can hold a digest, e.g.
+		sha256:<hex>, or an image reference with a digest, lines starting with # are
+		ignored. URLs are fetched the same way as policy sources, e.g.
+		git::https://github.com/org/repo//approved.txt.`))
+
+	cmd.Flags().StringVar(&data.waiverFile, "waivers", data.waiverFile, hd.Doc(`
+		Path of a YAML file with waivers of policy violations. Each waiver names the
+		code of a rule, the image repository or the component name, or both, an expiry
+		date and a justification, and optionally the fingerprint of the violation, see
+		--fingerprint-rule. Matching violations are reported as waived instead of
+		violations and do not fail the validation, until the waiver expires. Use ec
+		waive add to create waivers from a report.`))
+
+	cmd.Flags().StringArrayVar(&data.fingerprintRules, "fingerprint-rule", data.fingerprintRules, hd.Doc(`
+		Rule normalizing the messages of the results before their fingerprints are
+		computed, as <pattern>=<replacement> where the replacement can refer to the
+		submatches of the regular expression, or the name of a default rule: digest,
+		timestamp, uuid or commit. Can be repeated, the rules are applied in order and
+		replace the default rules. The fingerprints identify the same result across
+		reports regardless of the digests, or other volatile data, in their messages.`))
+
+	cmd.Flags().StringVar(&data.tufMirror, "tuf-mirror", data.tufMirror, hd.Doc(`
+		URL of a mirror of the Sigstore TUF repository to load the Fulcio and Rekor
+		trusted material used for keyless verification from, instead of the public
+		Sigstore TUF repository. The local TUF root, in $TUF_ROOT or $HOME/.sigstore/root,
+		is initialized from the mirror before the validation.`))
+
+	cmd.Flags().StringVar(&data.tufRoot, "tuf-root", data.tufRoot, hd.Doc(`
+		Path or URL of the initial trusted root.json of the TUF repository given by
+		--tuf-mirror. By default the Sigstore root embedded in ec is used.`))
+
+	cmd.Flags().StringVar(&data.identityKey, "identity-key", data.identityKey, hd.Doc(`
+		Identify components by the given key, one of: `+strings.Join(applicationsnapshot.IdentityKeys, ", ")+`.
+		The report lists the components sorted by the key and components with the
+		same identity only once, unless --duplicate-components is evaluate-all, e.g.
+		with digest an image referenced by different tags is reported once. A failed
+		component is kept over a successful one. Components are listed as given by
+		default.`))
+
+	cmd.Flags().StringVar(&data.duplicateComponents, "duplicate-components", data.duplicateComponents, hd.Doc(`
+		How to handle components with the same identity, as given by --identity-key, or
+		with the same image digest, or image reference, by default. Only components with
+		images in the same repository, and with the same policy override and signature
+		verifier, are duplicates. Possible values are: `+strings.Join(applicationsnapshot.DuplicateModes, ", ")+`.
+		With dedupe only the first of the components is evaluated, and the others are
+		listed as its duplicates in the report. With error the validation fails. With
+		evaluate-all all of the components are evaluated and reported.`))
+
+	cmd.Flags().StringVar(&data.evaluationErrors, "evaluation-errors", data.evaluationErrors, hd.Doc(`
+		How to handle an error evaluating an image, e.g. due to invalid input or a
+		runtime error in a policy rule, as opposed to the image violating the policy.
+		Possible values are: `+strings.Join(output.EvaluationErrorModes, ", ")+`. With abort the
+		validation stops with the error. With fail or warn the error is reported as the
+		evaluation error of the image, distinct from its violations, and the image
+		fails or passes respectively.`))
+
+	cmd.Flags().DurationVar(&data.componentTimeout, "component-timeout", data.componentTimeout, hd.Doc(`
+		Maximum duration of the validation of a single component, e.g. 2m. A component
+		that takes longer is reported as timed out, with an evaluation error, while the
+		validation of the other components proceeds. The overall duration is still
+		limited by --timeout. Not limited by default.`))
+
+	cmd.Flags().StringVar(&data.unsignedImage, "unsigned-image", data.unsignedImage, hd.Doc(`
+		How to handle an image without a verifiable signature. Possible values are:
+		`+strings.Join(output.UnsignedImageModes, ", ")+`. With allow or warn the failed image
+		signature check is reported as a success or a warning respectively, labeled
+		with the mode in effect, instead of a violation.`))
+
+	cmd.Flags().BoolVar(&data.verboseRules, "verbose-rules", data.verboseRules, hd.Doc(`
+		Show all the details of results from rules annotated as verbose. By default
+		such results are collapsed to a one-line summary with a count of the details.`))
+
 	cmd.Flags().IntVar(&data.workers, "workers", data.workers, hd.Doc(`
-		Number of workers to use for validation. Defaults to 5.`))
+		Number of workers to use for validation, i.e. the number of components validated
+		concurrently. Defaults to 5. The results are reported in the same order
+		regardless of the number of workers.`))
+
+	cmd.Flags().StringSliceVar(&data.componentOrder, "component-order", data.componentOrder, hd.Doc(`
+		Names or container images of the components to evaluate first, in the given
+		order. The other components follow by descending priority, set with the
+		`+applicationsnapshot.PriorityAnnotation+` annotation of the component in the
+		snapshot, and otherwise in snapshot order. The order of the evaluation does
+		not change the order of the components in the report.`))
+
+	cmd.Flags().StringVar(&data.printEffectiveConfig, "print-effective-config", data.printEffectiveConfig, hd.Doc(`
+		Print the effective configuration instead of validating the images, in the given
+		format, json (the default) or yaml. The configuration holds the resolved policy,
+		including any extra rule data, the policies of components with a policy override,
+		where each source is fetched from, as set by --lockfile and --policy-fallback,
+		and the values of all the options. Credentials in URLs are redacted.`))
+	cmd.Flags().Lookup("print-effective-config").NoOptDefVal = effectiveConfigJSON
+
+	cmd.Flags().BoolVar(&data.watch, "watch", data.watch, hd.Doc(`
+		Keep validating the images, for long running compliance dashboards. The
+		digests of the images referenced by tag and the content of the policy sources
+		are checked every --watch-interval, and the images are validated again when
+		any of them changed. The report of each validation is written to the outputs,
+		followed by the differences from the previous report on stdout. Runs until
+		interrupted, each validation limited by --timeout, and --strict is ignored.`))
+
+	cmd.Flags().DurationVar(&data.watchInterval, "watch-interval", data.watchInterval,
+		"how often the image digests and the policy sources are checked for changes with --watch")
+
+	cmd.Flags().IntVar(&data.benchmark, "benchmark", data.benchmark, hd.Doc(`
+		Validate each component the given number of times and report the throughput,
+		the p50 and p95 latency of validating a component, and the effectiveness of
+		the policy download cache instead of the validation report. The results of
+		the validation do not affect the exit code. Disabled when 0, the default.`))
 
 	if len(data.input) > 0 || len(data.filePath) > 0 || len(data.images) > 0 {
 		if err := cmd.MarkFlagRequired("image"); err != nil {
@@ -41,14 +41,24 @@ type InputValidationFunc func(context.Context, string, policy.Policy, bool) (*ou
 
 func validateInputCmd(validate InputValidationFunc) *cobra.Command {
 	data := struct {
+		dir                 string
 		effectiveTime       string
+		excludeGlobs        []string
 		filePaths           []string
+		includeGlobs        []string
 		info                bool
+		inputURLs           []string
+		inputs              []input.File
+		cleanup             func()
 		namespaces          []string
 		output              []string
 		policy              policy.Policy
 		policyConfiguration string
+		recursive           bool
 		strict              bool
+		strictDataSources   bool
+		strictInput         bool
+		ruleEffectiveOn     []string
 	}{
 		strict: true,
 	}
@@ -83,9 +93,35 @@ func validateInputCmd(validate InputValidationFunc) *cobra.Command {
 
 			  ec validate input --file /path/to/file.yaml --policy github.com/user/repo
 
+			Validate all JSON and YAML files within a directory and its subdirectories, skipping
+			files matching the exclude pattern. Each document of a multi-document YAML file is
+			validated separately.
+
+			  ec validate input --dir ./manifests --recursive --exclude-glob "*.test.yaml" --policy my-policy.yaml
+
+			Validate a file hosted at a URL without downloading it first.
+
+			  ec validate input --input-url https://example.com/manifests/deploy.yaml --policy my-policy.yaml
+
 `),
 		PreRunE: func(cmd *cobra.Command, args []string) (allErrors error) {
 			ctx := cmd.Context()
+			if data.strictDataSources {
+				ctx = evaluator.WithStrictDataSources(ctx)
+				cmd.SetContext(ctx)
+			}
+			if data.strictInput {
+				ctx = evaluator.WithStrictInput(ctx)
+				cmd.SetContext(ctx)
+			}
+			if len(data.ruleEffectiveOn) > 0 {
+				if effectiveOn, err := evaluator.ParseRuleEffectiveOn(data.ruleEffectiveOn); err != nil {
+					allErrors = errors.Join(allErrors, err)
+				} else {
+					ctx = evaluator.WithRuleEffectiveOn(ctx, effectiveOn)
+					cmd.SetContext(ctx)
+				}
+			}
 
 			policyConfiguration, err := validate_utils.GetPolicyConfig(ctx, data.policyConfiguration)
 			if err != nil {
@@ -94,6 +130,40 @@ func validateInputCmd(validate InputValidationFunc) *cobra.Command {
 			}
 			data.policyConfiguration = policyConfiguration
 
+			for _, f := range data.filePaths {
+				data.inputs = append(data.inputs, input.File{Label: f, Path: f})
+			}
+
+			if data.dir != "" {
+				discovered, cleanup, err := input.Discover(ctx, data.dir, input.DiscoverOptions{
+					Recursive: data.recursive,
+					Include:   data.includeGlobs,
+					Exclude:   data.excludeGlobs,
+				})
+				if err != nil {
+					allErrors = errors.Join(allErrors, err)
+				} else {
+					data.inputs = append(data.inputs, discovered...)
+					data.cleanup = cleanup
+					// RunE, which removes the documents after the evaluation,
+					// is not invoked on error
+					defer func() {
+						if allErrors != nil {
+							cleanup()
+						}
+					}()
+				}
+			}
+
+			for _, u := range data.inputURLs {
+				fetched, err := input.Fetch(ctx, u)
+				if err != nil {
+					allErrors = errors.Join(allErrors, err)
+				} else {
+					data.inputs = append(data.inputs, fetched...)
+				}
+			}
+
 			if p, err := policy.NewInputPolicy(cmd.Context(), data.policyConfiguration, data.effectiveTime); err != nil {
 				allErrors = errors.Join(allErrors, err)
 			} else {
@@ -102,6 +172,10 @@ func validateInputCmd(validate InputValidationFunc) *cobra.Command {
 			return
 		},
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if data.cleanup != nil {
+				defer data.cleanup()
+			}
+
 			type result struct {
 				err         error
 				input       input.Input
@@ -109,23 +183,23 @@ func validateInputCmd(validate InputValidationFunc) *cobra.Command {
 				policyInput []byte
 			}
 
-			ch := make(chan result, len(data.filePaths))
+			ch := make(chan result, len(data.inputs))
 
 			var lock sync.WaitGroup
 
 			showSuccesses, _ := cmd.Flags().GetBool("show-successes")
 
-			for _, f := range data.filePaths {
+			for _, f := range data.inputs {
 				lock.Add(1)
-				go func(fpath string) {
+				go func(f input.File) {
 					defer lock.Done()
 
 					ctx := cmd.Context()
-					out, err := validate(ctx, fpath, data.policy, data.info)
+					out, err := validate(ctx, f.Path, data.policy, data.info)
 					res := result{
 						err: err,
 						input: input.Input{
-							FilePath: fpath,
+							FilePath: f.Label,
 							Success:  err == nil,
 						},
 					}
@@ -133,6 +207,7 @@ func validateInputCmd(validate InputValidationFunc) *cobra.Command {
 					if err == nil {
 						res.input.Violations = out.Violations()
 						res.input.Warnings = out.Warnings()
+						res.input.Infos = out.Infos()
 
 						successes := out.Successes()
 						res.input.SuccessCount = len(successes)
@@ -191,7 +266,31 @@ func validateInputCmd(validate InputValidationFunc) *cobra.Command {
 		},
 	}
 
-	cmd.Flags().StringSliceVarP(&data.filePaths, "file", "f", data.filePaths, "path to input YAML/JSON file (required)")
+	cmd.Flags().StringSliceVarP(&data.filePaths, "file", "f", data.filePaths, "path to input YAML/JSON file")
+
+	cmd.Flags().StringVar(&data.dir, "dir", data.dir, hd.Doc(`
+		path to a directory containing input YAML/JSON files. Files with other
+		extensions are ignored. Each document of a multi-document YAML file is
+		validated separately.`))
+
+	cmd.Flags().StringSliceVar(&data.inputURLs, "input-url", data.inputURLs, hd.Doc(`
+		URL of an input YAML/JSON file to fetch and validate. The file is fetched
+		the same way policy sources are. Files without a .json, .yaml or .yml
+		extension are treated as YAML. May be used multiple times.`))
+
+	cmd.Flags().BoolVar(&data.recursive, "recursive", data.recursive, hd.Doc(`
+		Descend into subdirectories of the directory given by --dir. Symbolic
+		links to directories are not followed.`))
+
+	cmd.Flags().StringSliceVar(&data.includeGlobs, "include-glob", data.includeGlobs, hd.Doc(`
+		Only validate files within --dir matching the glob pattern. The pattern
+		is matched against the path relative to the directory and against the
+		file name. May be used multiple times.`))
+
+	cmd.Flags().StringSliceVar(&data.excludeGlobs, "exclude-glob", data.excludeGlobs, hd.Doc(`
+		Do not validate files within --dir matching the glob pattern. The pattern
+		is matched against the path relative to the directory and against the
+		file name. May be used multiple times.`))
 
 	cmd.Flags().StringVarP(&data.policyConfiguration, "policy", "p", data.policyConfiguration, hd.Doc(`
 		Policy configuration as:
@@ -216,14 +315,28 @@ func validateInputCmd(validate InputValidationFunc) *cobra.Command {
 		effective dates in the future. The value can be "now" (default) - for
 		current time, or a RFC3339 formatted value, e.g. 2022-11-18T00:00:00Z.`))
 
+	cmd.Flags().BoolVar(&data.strictDataSources, "strict-data-sources", data.strictDataSources, hd.Doc(`
+		Fail if any of the data sources of the policy provided no data, e.g. due to a
+		wrong URL. By default such data sources are reported with a warning.`))
+
+	cmd.Flags().BoolVar(&data.strictInput, "strict-input", data.strictInput, hd.Doc(`
+		Report a warning for each reference the policy rules make to a path that is
+		not present in the input, e.g. input.image.ref when the input has no such key.
+		Helps to catch typos in rules. Off by default.`))
+
+	cmd.Flags().StringArrayVar(&data.ruleEffectiveOn, "rule-effective-on", data.ruleEffectiveOn, hd.Doc(`
+		Effective time of a policy rule, given as <rule code>=<time>, e.g.
+		tasks.required_tasks_found=2024-06-30. The time is a date or a RFC3339
+		timestamp. Until then, failures of the rule are reported as warnings, after it
+		they are blocking. Takes precedence over the effective_on annotation of the
+		rule. Can be repeated.`))
+
 	cmd.Flags().BoolVar(&data.info, "info", data.info, hd.Doc(`
 		Include additional information on the failures. For instance for policy
 		violations, include the title and the description of the failed policy
 		rule.`))
 
-	if err := cmd.MarkFlagRequired("file"); err != nil {
-		panic(err)
-	}
+	cmd.MarkFlagsOneRequired("file", "dir", "input-url")
 
 	if err := cmd.MarkFlagRequired("policy"); err != nil {
 		panic(err)
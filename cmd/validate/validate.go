@@ -21,6 +21,7 @@ import (
 
 	"github.com/enterprise-contract/ec-cli/internal/image"
 	"github.com/enterprise-contract/ec-cli/internal/input"
+	_ "github.com/enterprise-contract/ec-cli/internal/kms"
 	"github.com/enterprise-contract/ec-cli/internal/policy"
 	_ "github.com/enterprise-contract/ec-cli/internal/rego"
 )
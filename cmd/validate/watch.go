@@ -0,0 +1,190 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package validate
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"slices"
+	"strings"
+	"syscall"
+	"time"
+
+	app "github.com/konflux-ci/application-api/api/v1alpha1"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/enterprise-contract/ec-cli/internal/applicationsnapshot"
+	"github.com/enterprise-contract/ec-cli/internal/image"
+	"github.com/enterprise-contract/ec-cli/internal/policy"
+	"github.com/enterprise-contract/ec-cli/internal/policy/source"
+	"github.com/enterprise-contract/ec-cli/internal/utils"
+)
+
+// revisions holds what the validation depends on and can change without the
+// arguments changing: the digests of the images referenced by tag, keyed by
+// "image <reference>", and the content digests of the policy sources, keyed
+// by "source <url>".
+type revisions map[string]string
+
+// changed returns the sorted keys with a different value in the other
+// revisions, including the keys present in only one of them.
+func (r revisions) changed(other revisions) []string {
+	var keys []string
+	for k, v := range r {
+		if o, ok := other[k]; !ok || o != v {
+			keys = append(keys, k)
+		}
+	}
+	for k := range other {
+		if _, ok := r[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	slices.Sort(keys)
+
+	return keys
+}
+
+// currentRevisions resolves the digests of the component images referenced by
+// tag and, if withSources is set, fetches the sources of the policies to
+// compute the digests of their content.
+func currentRevisions(ctx context.Context, components []app.SnapshotComponent, policies []policy.Policy, withSources bool) (revisions, error) {
+	revs := revisions{}
+	for _, c := range components {
+		// Images referenced by digest do not change
+		if strings.Contains(c.ContainerImage, "@") {
+			continue
+		}
+		ref, err := image.ParseAndResolve(ctx, c.ContainerImage)
+		if err != nil {
+			return nil, err
+		}
+		revs["image "+c.ContainerImage] = ref.Digest
+	}
+
+	if !withSources {
+		return revs, nil
+	}
+
+	var allSources []source.PolicySource
+	for _, p := range policies {
+		for _, sourceGroup := range p.Spec().Sources {
+			policySources, err := source.FetchPolicySources(sourceGroup)
+			if err != nil {
+				return nil, err
+			}
+			allSources = append(allSources, policySources...)
+		}
+	}
+
+	fs := utils.FS(ctx)
+	workDir, err := utils.CreateWorkDir(fs)
+	if err != nil {
+		return nil, err
+	}
+	// The download cache would return the sources as previously fetched, and
+	// would refer to the work directory once it is removed
+	source.ResetDownloadCache()
+	defer func() {
+		source.ResetDownloadCache()
+		_ = fs.RemoveAll(workDir)
+	}()
+
+	// Fetching the sources while updating a lockfile records the digests of
+	// their content
+	lock := source.NewLockfileUpdate()
+	if err := source.PreFetch(source.WithLockfile(ctx, lock), workDir, allSources); err != nil {
+		return nil, err
+	}
+	for _, s := range lock.Sources {
+		revs["source "+s.URL] = s.Digest
+	}
+
+	return revs, nil
+}
+
+// watch runs the validation, then checks every interval for changes using
+// current and runs the validation again when anything changed. After each
+// repeated validation the differences from the previous report are written to
+// the standard output. Each check and validation is limited by the global
+// timeout. Runs until interrupted.
+func watch(cmd *cobra.Command, interval time.Duration, run func(context.Context) (*applicationsnapshot.Report, error), current func(context.Context) (revisions, error)) error {
+	// The command context is limited by the global timeout, which here applies
+	// to each validation instead
+	ctx, stop := signal.NotifyContext(context.WithoutCancel(cmd.Context()), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	timeout, _ := cmd.Flags().GetDuration("timeout")
+	withTimeout := func() (context.Context, context.CancelFunc) {
+		if timeout > 0 {
+			return context.WithTimeout(ctx, timeout)
+		}
+		return context.WithCancel(ctx)
+	}
+
+	var previous *applicationsnapshot.Report
+	var seen revisions
+	for {
+		iterCtx, cancel := withTimeout()
+		err := func() error {
+			revs, err := current(iterCtx)
+			if err != nil {
+				return fmt.Errorf("checking for changes: %w", err)
+			}
+
+			// Until a validation succeeds it is attempted on every check
+			if previous != nil {
+				changed := seen.changed(revs)
+				if len(changed) == 0 {
+					log.Debug("No changes, skipping the validation")
+					return nil
+				}
+				fmt.Fprintf(cmd.ErrOrStderr(), "Validating again, changed: %s\n", strings.Join(changed, ", "))
+			}
+
+			source.ResetDownloadCache()
+			report, err := run(iterCtx)
+			if err != nil {
+				return err
+			}
+			seen = revs
+
+			if previous != nil {
+				if err := applicationsnapshot.DiffReports(previous, report).WriteText(cmd.OutOrStdout()); err != nil {
+					return err
+				}
+			}
+			previous = report
+
+			return nil
+		}()
+		cancel()
+
+		if err != nil && ctx.Err() == nil {
+			fmt.Fprintf(cmd.ErrOrStderr(), "Error: %v\n", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(interval):
+		}
+	}
+}
@@ -0,0 +1,58 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package validate
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	app "github.com/konflux-ci/application-api/api/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/enterprise-contract/ec-cli/internal/image"
+)
+
+func TestRevisionsChanged(t *testing.T) {
+	previous := revisions{"image a": "sha256:1", "image b": "sha256:1", "source c": "sha256:1"}
+
+	assert.Empty(t, previous.changed(previous))
+	assert.Equal(t, []string{"image b", "source c", "source d"}, previous.changed(revisions{
+		"image a":  "sha256:1",
+		"image b":  "sha256:2",
+		"source d": "sha256:1",
+	}))
+}
+
+func TestCurrentRevisions(t *testing.T) {
+	digest := v1.Hash{Algorithm: "sha256", Hex: "da54bca5477bf4e3449bc37de1822888fa0fbb8d89c640218cb31b987374d357"}
+	ctx := context.WithValue(context.Background(), image.RemoteHead, func(name.Reference, ...remote.Option) (*v1.Descriptor, error) {
+		return &v1.Descriptor{Digest: digest}, nil
+	})
+
+	revs, err := currentRevisions(ctx, []app.SnapshotComponent{
+		{ContainerImage: "registry.io/repository/image:latest"},
+		{ContainerImage: "registry.io/repository/image@" + digest.String()},
+	}, nil, false)
+	require.NoError(t, err)
+	assert.Equal(t, revisions{"image registry.io/repository/image:latest": digest.String()}, revs)
+}
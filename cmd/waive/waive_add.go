@@ -0,0 +1,163 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// Define the `ec waive add` command
+package waive
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	hd "github.com/MakeNowJust/heredoc"
+	"github.com/spf13/cobra"
+	"golang.org/x/exp/slices"
+
+	"github.com/enterprise-contract/ec-cli/internal/applicationsnapshot"
+	"github.com/enterprise-contract/ec-cli/internal/utils"
+	"github.com/enterprise-contract/ec-cli/internal/waiver"
+)
+
+func waiveAddCmd() *cobra.Command {
+	var data = struct {
+		report        string
+		file          string
+		expires       string
+		justification string
+		codes         []string
+		components    []string
+		byFingerprint bool
+	}{
+		file: "waivers.yaml",
+	}
+
+	cmd := &cobra.Command{
+		Use:   "add --report <report> --expires <date> --justification <text>",
+		Short: "Add waivers for the violations in a report",
+
+		Long: hd.Doc(`
+			Add waivers for the violations in a report.
+
+			A waiver is added to the waiver file for each of the violations in the
+			report, the JSON or the YAML output of ec validate image, optionally only for
+			the given rule codes and components. The waivers apply to the image
+			repository and the name of the component. A waiver for the same code, image
+			and component is not added again. The waiver file is created if it does not
+			exist.
+
+			With --by-fingerprint, the waivers apply only to the violations with the same
+			fingerprint as the violations in the report, i.e. a new violation of the same
+			rule is not waived. The fingerprints of the violations are computed with the
+			normalization rules of ec validate image --fingerprint-rule, so they do not
+			depend on the digests, or other volatile data, in the messages.
+
+			Use the waiver file with ec validate image --waivers to report the waived
+			violations as waived until the waivers expire.
+		`),
+
+		Example: hd.Doc(`
+			Waive all violations in the report until the end of the year:
+
+			  ec waive add --report report.json --expires 2026-12-31 \
+			    --justification "Accepted until the migration to the new build pipeline"
+
+			Waive the violations of a rule of a component in the given waiver file:
+
+			  ec waive add --report report.json --file policy/waivers.yaml \
+			    --code tasks.required_tasks_found --component my-component \
+			    --expires 2026-12-31 --justification "Tasks added in the next release"
+		`),
+
+		Args: cobra.NoArgs,
+		PreRunE: func(cmd *cobra.Command, _ []string) error {
+			if _, err := (waiver.Waiver{Expires: data.expires}).ExpiresAt(); err != nil {
+				return fmt.Errorf("invalid value %q for --expires, expected a date such as 2006-01-02 or an RFC3339 time", data.expires)
+			}
+
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			fs := utils.FS(cmd.Context())
+
+			report, err := applicationsnapshot.LoadReport(fs, data.report)
+			if err != nil {
+				return err
+			}
+
+			waivers, err := waiver.Load(fs, data.file)
+			if errors.Is(err, os.ErrNotExist) {
+				waivers, err = &waiver.File{}, nil
+			}
+			if err != nil {
+				return err
+			}
+
+			added := 0
+			for _, c := range report.Components {
+				if len(data.components) > 0 && !slices.Contains(data.components, c.Name) {
+					continue
+				}
+
+				for _, v := range c.Violations {
+					code, _ := v.Metadata["code"].(string)
+					if code == "" || (len(data.codes) > 0 && !slices.Contains(data.codes, code)) {
+						continue
+					}
+
+					w := waiver.Waiver{
+						Code:          code,
+						Image:         waiver.Repository(c.ContainerImage),
+						Component:     c.Name,
+						Expires:       data.expires,
+						Justification: data.justification,
+					}
+					if data.byFingerprint {
+						w.Fingerprint = v.Fingerprint
+					}
+					if waivers.Add(w) {
+						added++
+					}
+				}
+			}
+
+			if added > 0 {
+				if err := waivers.Write(fs, data.file); err != nil {
+					return err
+				}
+			}
+
+			fmt.Fprintf(cmd.OutOrStdout(), "Added %d waivers to %s\n", added, data.file)
+
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&data.report, "report", "r", data.report, "path of the report with the violations to waive")
+	cmd.Flags().StringVarP(&data.file, "file", "f", data.file, "path of the waiver file to add the waivers to")
+	cmd.Flags().StringVar(&data.expires, "expires", data.expires, "date, e.g. 2006-01-02, or RFC3339 time from which the waivers no longer apply")
+	cmd.Flags().StringVar(&data.justification, "justification", data.justification, "justification of the waivers")
+	cmd.Flags().StringSliceVar(&data.codes, "code", data.codes, "add waivers only for the violations of the rule with the code, can be repeated")
+	cmd.Flags().StringSliceVar(&data.components, "component", data.components, "add waivers only for the violations of the component with the name, can be repeated")
+	cmd.Flags().BoolVar(&data.byFingerprint, "by-fingerprint", data.byFingerprint, "add waivers only for the violations with the fingerprints of the violations in the report")
+
+	for _, f := range []string{"report", "expires", "justification"} {
+		if err := cmd.MarkFlagRequired(f); err != nil {
+			panic(err)
+		}
+	}
+
+	return cmd
+}
@@ -0,0 +1,157 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package waive
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/enterprise-contract/ec-cli/cmd/root"
+	"github.com/enterprise-contract/ec-cli/internal/utils"
+)
+
+const report = `{
+  "success": false,
+  "components": [
+    {"name": "spam", "containerImage": "registry.io/spam@sha256:1", "success": false,
+     "violations": [
+       {"msg": "bad", "metadata": {"code": "a.b"}},
+       {"msg": "worse", "metadata": {"code": "c.d"}, "fingerprint": "f00d"}
+     ]},
+    {"name": "bacon", "containerImage": "registry.io/bacon:latest", "success": false,
+     "violations": [{"msg": "bad", "metadata": {"code": "a.b"}}]}
+  ]
+}`
+
+func TestWaiveAdd(t *testing.T) {
+	cases := []struct {
+		name     string
+		existing string
+		args     []string
+		output   string
+		waivers  string
+		err      string
+	}{
+		{
+			name:   "all violations",
+			output: "Added 3 waivers to waivers.yaml\n",
+			waivers: `waivers:
+- code: a.b
+  component: spam
+  expires: "2026-12-31"
+  image: registry.io/spam
+  justification: accepted
+- code: c.d
+  component: spam
+  expires: "2026-12-31"
+  image: registry.io/spam
+  justification: accepted
+- code: a.b
+  component: bacon
+  expires: "2026-12-31"
+  image: registry.io/bacon
+  justification: accepted
+`,
+		},
+		{
+			name: "filtered and existing",
+			existing: `waivers:
+- code: a.b
+  component: spam
+  image: registry.io/spam
+  expires: "2026-06-30"
+  justification: earlier
+`,
+			args:   []string{"--code", "a.b", "--component", "spam,bacon"},
+			output: "Added 1 waivers to waivers.yaml\n",
+			waivers: `waivers:
+- code: a.b
+  component: spam
+  expires: "2026-06-30"
+  image: registry.io/spam
+  justification: earlier
+- code: a.b
+  component: bacon
+  expires: "2026-12-31"
+  image: registry.io/bacon
+  justification: accepted
+`,
+		},
+		{
+			name:   "by fingerprint",
+			args:   []string{"--code", "c.d", "--by-fingerprint"},
+			output: "Added 1 waivers to waivers.yaml\n",
+			waivers: `waivers:
+- code: c.d
+  component: spam
+  expires: "2026-12-31"
+  fingerprint: f00d
+  image: registry.io/spam
+  justification: accepted
+`,
+		},
+		{
+			name: "invalid expiry",
+			args: []string{"--expires", "soon"},
+			err:  `invalid value "soon" for --expires, expected a date such as 2006-01-02 or an RFC3339 time`,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			fs := afero.NewMemMapFs()
+			require.NoError(t, afero.WriteFile(fs, "report.json", []byte(report), 0644))
+			if c.existing != "" {
+				require.NoError(t, afero.WriteFile(fs, "waivers.yaml", []byte(c.existing), 0644))
+			}
+
+			cmd := setUpCobra(waiveAddCmd())
+			cmd.SetContext(utils.WithFS(context.Background(), fs))
+			stdout := bytes.Buffer{}
+			cmd.SetOut(&stdout)
+			cmd.SetArgs(append([]string{"waive", "add", "--report", "report.json", "--expires", "2026-12-31", "--justification", "accepted"}, c.args...))
+
+			err := cmd.Execute()
+			if c.err != "" {
+				assert.EqualError(t, err, c.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, c.output, stdout.String())
+
+			waivers, err := afero.ReadFile(fs, "waivers.yaml")
+			require.NoError(t, err)
+			assert.Equal(t, c.waivers, string(waivers))
+		})
+	}
+}
+
+func setUpCobra(command *cobra.Command) *cobra.Command {
+	waiveCmd := NewWaiveCmd()
+	waiveCmd.AddCommand(command)
+	cmd := root.NewRootCmd()
+	cmd.AddCommand(waiveCmd)
+	return cmd
+}
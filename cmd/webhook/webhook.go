@@ -0,0 +1,132 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// Define the `ec webhook` command
+package webhook
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	hd "github.com/MakeNowJust/heredoc"
+	"github.com/spf13/cobra"
+
+	"github.com/enterprise-contract/ec-cli/internal/image"
+	_ "github.com/enterprise-contract/ec-cli/internal/kms"
+	_ "github.com/enterprise-contract/ec-cli/internal/rego"
+	"github.com/enterprise-contract/ec-cli/internal/server"
+	"github.com/enterprise-contract/ec-cli/internal/webhook"
+)
+
+var WebhookCmd *cobra.Command
+
+func init() {
+	WebhookCmd = webhookCmd(image.ValidateImage)
+}
+
+func webhookCmd(validate server.ValidateFunc) *cobra.Command {
+	data := struct {
+		address       string
+		tlsCertFile   string
+		tlsKeyFile    string
+		serverOptions server.Options
+		options       webhook.Options
+	}{
+		address: ":8443",
+		serverOptions: server.Options{
+			Workers:   5,
+			SourceTTL: 10 * time.Minute,
+		},
+		options: webhook.Options{
+			ResultTTL: 10 * time.Minute,
+			Timeout:   25 * time.Second,
+		},
+	}
+
+	cmd := &cobra.Command{
+		Use:   "webhook",
+		Short: "Serve a Kubernetes admission webhook validating the images of Pods and Deployments",
+
+		Long: hd.Doc(`
+			Serve a Kubernetes admission webhook validating the images of Pods and Deployments.
+
+			Runs a validating admission webhook denying the creation, and the update, of the
+			Pods and the Deployments with container images, including the init and the
+			ephemeral containers, that do not conform to the EnterpriseContractPolicy given
+			by --policy. The images are validated as "ec validate image" does, and an image
+			that can not be validated is denied. Objects of other kinds are allowed.
+
+			The admission reviews are served on POST /validate over HTTPS, using the
+			certificate and the key given by --tls-cert-file and --tls-key-file. Register the
+			webhook with a ValidatingWebhookConfiguration selecting the CREATE and UPDATE
+			operations on pods and deployments, with the CA of the certificate. GET /healthz
+			responds once the webhook is ready to serve the reviews.
+
+			The images are validated by digest. The result of the validation of an image is
+			reused for the same revision of the policy, i.e. until the policy changes or the
+			result expires, see --result-ttl. The policy sources are shared by the reviews,
+			see --source-ttl.
+
+			The webhook stops on SIGINT or SIGTERM, completing the reviews in flight.
+		`),
+
+		Example: hd.Doc(`
+			Serve the webhook validating the images against the policy in the ec namespace:
+
+			  ec webhook --policy ec/default --tls-cert-file tls.crt --tls-key-file tls.key
+		`),
+
+		Args: cobra.NoArgs,
+		PreRunE: func(cmd *cobra.Command, _ []string) (allErrors error) {
+			if data.options.Policy == "" {
+				allErrors = errors.Join(allErrors, errors.New("--policy is required"))
+			}
+			// Kubernetes calls the admission webhooks over HTTPS only
+			if data.tlsCertFile == "" || data.tlsKeyFile == "" {
+				allErrors = errors.Join(allErrors, errors.New("--tls-cert-file and --tls-key-file are required"))
+			}
+			return
+		},
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			// The webhook is not limited by --timeout, each review is
+			// limited by --review-timeout instead
+			ctx := context.WithoutCancel(cmd.Context())
+			s := server.New(validate, data.serverOptions)
+			defer s.Close(ctx)
+
+			return server.Run(ctx, data.address, webhook.New(s, data.options), data.tlsCertFile, data.tlsKeyFile)
+		},
+	}
+
+	cmd.Flags().StringVar(&data.address, "address", data.address, "address to listen on, in host:port form")
+	cmd.Flags().StringVar(&data.tlsCertFile, "tls-cert-file", data.tlsCertFile, "file with the TLS certificate of the webhook in PEM format")
+	cmd.Flags().StringVar(&data.tlsKeyFile, "tls-key-file", data.tlsKeyFile, "file with the TLS private key of the webhook in PEM format")
+	cmd.Flags().StringVar(&data.options.Policy, "policy", data.options.Policy,
+		"EnterpriseContractPolicy to validate the images against, in the namespace/name form")
+	cmd.Flags().IntVar(&data.serverOptions.Workers, "workers", data.serverOptions.Workers,
+		"number of images of a review validated concurrently")
+	cmd.Flags().DurationVar(&data.options.Timeout, "review-timeout", data.options.Timeout,
+		"time allowed to review a request, below the timeout of the webhook configuration, 0 for no limit")
+	cmd.Flags().DurationVar(&data.options.ResultTTL, "result-ttl", data.options.ResultTTL,
+		"how long the result of the validation of an image is reused for the same revision of the policy, 0 to validate the images on each review")
+	cmd.Flags().DurationVar(&data.serverOptions.SourceTTL, "source-ttl", data.serverOptions.SourceTTL,
+		"how long the fetched policy sources are reused across reviews before they are fetched again, 0 to reuse them until the webhook stops")
+	cmd.Flags().BoolVar(&data.serverOptions.IgnoreRekor, "ignore-rekor", data.serverOptions.IgnoreRekor,
+		"skip the lookup of the signatures in the Rekor transparency log, as --ignore-rekor of ec validate image does")
+
+	return cmd
+}
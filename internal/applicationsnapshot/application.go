@@ -0,0 +1,76 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package applicationsnapshot
+
+import (
+	"cmp"
+	"slices"
+)
+
+// ApplicationResult is the rollup of the results of the components of one of
+// the applications of a snapshot.
+type ApplicationResult struct {
+	Name string `json:"name"`
+	// Success is set when all the components of the application are
+	// successful
+	Success    bool `json:"success"`
+	Components int  `json:"components"`
+	Violations int  `json:"violations"`
+	Warnings   int  `json:"warnings"`
+	Successes  int  `json:"successes"`
+}
+
+// NewApplicationResults groups the components by their application, returning
+// the results of each application ordered by its name. Nothing is returned if
+// none of the components belongs to an application.
+func NewApplicationResults(components []Component) []ApplicationResult {
+	byName := map[string]*ApplicationResult{}
+	var results []*ApplicationResult
+	for _, c := range components {
+		if c.Application == "" {
+			continue
+		}
+
+		r, ok := byName[c.Application]
+		if !ok {
+			r = &ApplicationResult{Name: c.Application, Success: true}
+			byName[c.Application] = r
+			results = append(results, r)
+		}
+
+		r.Success = r.Success && c.Success
+		r.Components++
+		r.Violations += len(c.Violations)
+		r.Warnings += len(c.Warnings)
+		r.Successes += c.SuccessCount
+	}
+
+	if len(results) == 0 {
+		return nil
+	}
+
+	slices.SortFunc(results, func(a, b *ApplicationResult) int {
+		return cmp.Compare(a.Name, b.Name)
+	})
+
+	applications := make([]ApplicationResult, 0, len(results))
+	for _, r := range results {
+		applications = append(applications, *r)
+	}
+
+	return applications
+}
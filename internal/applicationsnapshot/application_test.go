@@ -0,0 +1,88 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package applicationsnapshot
+
+import (
+	"testing"
+
+	app "github.com/konflux-ci/application-api/api/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/enterprise-contract/ec-cli/internal/evaluator"
+)
+
+func applicationComponents() []Component {
+	violation := evaluator.Result{Metadata: map[string]interface{}{"code": "violation-1"}, Message: "Violation 1 message"}
+	warning := evaluator.Result{Metadata: map[string]interface{}{"code": "warning-1"}, Message: "Warning 1 message"}
+
+	return []Component{
+		{
+			SnapshotComponent: app.SnapshotComponent{Name: "spam", ContainerImage: "registry.io/spam:latest"},
+			Application:       "frontend",
+			Success:           true,
+			Warnings:          []evaluator.Result{warning},
+			SuccessCount:      3,
+		},
+		{
+			SnapshotComponent: app.SnapshotComponent{Name: "eggs", ContainerImage: "registry.io/eggs:latest"},
+			Application:       "backend",
+			Success:           true,
+			SuccessCount:      2,
+		},
+		{
+			SnapshotComponent: app.SnapshotComponent{Name: "ham", ContainerImage: "registry.io/ham:latest"},
+			Application:       "frontend",
+			Violations:        []evaluator.Result{violation},
+			SuccessCount:      1,
+		},
+	}
+}
+
+func TestNewApplicationResults(t *testing.T) {
+	assert.Nil(t, NewApplicationResults(nil))
+	assert.Nil(t, NewApplicationResults([]Component{{Success: true}}))
+
+	assert.Equal(t, []ApplicationResult{
+		{Name: "backend", Success: true, Components: 1, Successes: 2},
+		{Name: "frontend", Success: false, Components: 2, Violations: 1, Warnings: 1, Successes: 4},
+	}, NewApplicationResults(applicationComponents()))
+}
+
+func TestTextReportApplications(t *testing.T) {
+	components := applicationComponents()
+	report := Report{Components: components, Applications: NewApplicationResults(components)}
+
+	output, err := generateTextReport(&report)
+	require.NoError(t, err)
+	assert.Contains(t, string(output), `
+Applications:
+- Name: backend
+  Success: true
+  Components: 1, Violations: 0, Warnings: 0, Successes: 2
+- Name: frontend
+  Success: false
+  Components: 2, Violations: 1, Warnings: 1, Successes: 4
+`)
+	assert.Contains(t, string(output), `- Name: spam
+  ImageRef: registry.io/spam:latest
+  Application: frontend
+  Violations: 0, Warnings: 1, Successes: 3
+`)
+}
@@ -0,0 +1,91 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package applicationsnapshot
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"slices"
+	"time"
+)
+
+// Benchmark holds the measurements taken while repeatedly validating the
+// components of a snapshot.
+type Benchmark struct {
+	// Iterations is the number of times each component was validated.
+	Iterations int
+	// Components is the number of components in the snapshot.
+	Components int
+	// Elapsed is the wall clock duration of all validations.
+	Elapsed time.Duration
+	// Latencies holds the duration of each validation of a component.
+	Latencies []time.Duration
+	// CacheHits and CacheMisses are the number of policy source downloads
+	// served from and missing from the download cache.
+	CacheHits   int64
+	CacheMisses int64
+}
+
+// Throughput returns the number of components validated per second.
+func (b Benchmark) Throughput() float64 {
+	if b.Elapsed <= 0 {
+		return 0
+	}
+	return float64(len(b.Latencies)) / b.Elapsed.Seconds()
+}
+
+// Percentile returns the latency below or at which the given percentage of
+// the validations completed, using the nearest-rank method.
+func (b Benchmark) Percentile(p float64) time.Duration {
+	if len(b.Latencies) == 0 {
+		return 0
+	}
+
+	sorted := slices.Clone(b.Latencies)
+	slices.Sort(sorted)
+
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	}
+
+	return sorted[rank-1]
+}
+
+// WriteText writes a human readable summary of the benchmark.
+func (b Benchmark) WriteText(w io.Writer) error {
+	hitRatio := 0.0
+	if lookups := b.CacheHits + b.CacheMisses; lookups > 0 {
+		hitRatio = float64(b.CacheHits) / float64(lookups) * 100
+	}
+
+	_, err := fmt.Fprintf(w, `Benchmark results
+  Components:  %d
+  Iterations:  %d
+  Validations: %d
+  Elapsed:     %s
+  Throughput:  %.2f components/s
+  Latency p50: %s
+  Latency p95: %s
+  Download cache: %d hits, %d misses (%.1f%% hit ratio)
+`, b.Components, b.Iterations, len(b.Latencies), b.Elapsed.Round(time.Millisecond), b.Throughput(),
+		b.Percentile(50).Round(time.Millisecond), b.Percentile(95).Round(time.Millisecond),
+		b.CacheHits, b.CacheMisses, hitRatio)
+
+	return err
+}
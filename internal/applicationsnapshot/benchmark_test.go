@@ -0,0 +1,71 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package applicationsnapshot
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBenchmark(t *testing.T) {
+	latencies := make([]time.Duration, 0, 20)
+	for i := 20; i > 0; i-- {
+		latencies = append(latencies, time.Duration(i)*time.Millisecond)
+	}
+
+	b := Benchmark{
+		Iterations:  10,
+		Components:  2,
+		Elapsed:     2 * time.Second,
+		Latencies:   latencies,
+		CacheHits:   19,
+		CacheMisses: 1,
+	}
+
+	assert.Equal(t, 10.0, b.Throughput())
+	assert.Equal(t, 10*time.Millisecond, b.Percentile(50))
+	assert.Equal(t, 19*time.Millisecond, b.Percentile(95))
+	assert.Equal(t, 20*time.Millisecond, b.Percentile(100))
+	assert.Equal(t, 1*time.Millisecond, b.Percentile(0))
+	// the latencies are left unsorted
+	assert.Equal(t, 20*time.Millisecond, b.Latencies[0])
+
+	var out bytes.Buffer
+	require.NoError(t, b.WriteText(&out))
+	assert.Equal(t, `Benchmark results
+  Components:  2
+  Iterations:  10
+  Validations: 20
+  Elapsed:     2s
+  Throughput:  10.00 components/s
+  Latency p50: 10ms
+  Latency p95: 19ms
+  Download cache: 19 hits, 1 misses (95.0% hit ratio)
+`, out.String())
+}
+
+func TestBenchmarkEmpty(t *testing.T) {
+	b := Benchmark{}
+	assert.Equal(t, 0.0, b.Throughput())
+	assert.Equal(t, time.Duration(0), b.Percentile(50))
+}
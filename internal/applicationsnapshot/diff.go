@@ -0,0 +1,308 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package applicationsnapshot
+
+import (
+	"bufio"
+	"cmp"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/enterprise-contract/ec-cli/internal/evaluator"
+	"github.com/enterprise-contract/ec-cli/internal/fingerprint"
+	"github.com/enterprise-contract/ec-cli/internal/signature"
+)
+
+// ReportDiff holds the differences between two reports of validating the
+// same components.
+type ReportDiff struct {
+	// Added are the components only in the current report
+	Added []Component `json:"added,omitempty"`
+	// Removed are the components only in the previous report
+	Removed []Component `json:"removed,omitempty"`
+	// Changed are the components with different results
+	Changed []ComponentDiff `json:"changed,omitempty"`
+}
+
+// ComponentDiff holds the differences in the results of a component.
+type ComponentDiff struct {
+	Name           string `json:"name"`
+	ContainerImage string `json:"containerImage"`
+	// PreviousImage is set when the image of the component changed
+	PreviousImage           string             `json:"previousImage,omitempty"`
+	Success                 bool               `json:"success"`
+	PreviousSuccess         bool               `json:"previousSuccess"`
+	NewViolations           []evaluator.Result `json:"newViolations,omitempty"`
+	ResolvedViolations      []evaluator.Result `json:"resolvedViolations,omitempty"`
+	NewWarnings             []evaluator.Result `json:"newWarnings,omitempty"`
+	ResolvedWarnings        []evaluator.Result `json:"resolvedWarnings,omitempty"`
+	EvaluationError         string             `json:"evaluationError,omitempty"`
+	PreviousEvaluationError string             `json:"previousEvaluationError,omitempty"`
+	// NewSignatures and RemovedSignatures are the image signatures only in
+	// the current and only in the previous report
+	NewSignatures     []signature.EntitySignature `json:"newSignatures,omitempty"`
+	RemovedSignatures []signature.EntitySignature `json:"removedSignatures,omitempty"`
+}
+
+// diffKey identifies the component across reports: by the name, or by the
+// image repository for components without a name, so that a component is the
+// same component when its image is updated.
+func diffKey(c Component) string {
+	if c.Name != "" && c.Name != unnamed {
+		return "name " + c.Name
+	}
+
+	repository, _, _ := strings.Cut(c.ContainerImage, "@")
+	if i := strings.LastIndex(repository, ":"); i > strings.LastIndex(repository, "/") {
+		repository = repository[:i]
+	}
+
+	return "image " + repository
+}
+
+// resultKey identifies the result across reports by its fingerprint, so that
+// a result is the same result when only the volatile data in its message, e.g.
+// the image digest, changed. The fingerprint is computed using the default
+// rules for the results of reports made before fingerprints were reported.
+func resultKey(r evaluator.Result) string {
+	if r.Fingerprint != "" {
+		return r.Fingerprint
+	}
+
+	return fingerprint.NewNormalizer().Fingerprint(r)
+}
+
+// diffResults returns the results only in current and the results only in
+// previous.
+func diffResults(previous, current []evaluator.Result) (added, removed []evaluator.Result) {
+	only := func(results, other []evaluator.Result) []evaluator.Result {
+		keys := make(map[string]bool, len(other))
+		for _, r := range other {
+			keys[resultKey(r)] = true
+		}
+
+		var found []evaluator.Result
+		for _, r := range results {
+			if !keys[resultKey(r)] {
+				found = append(found, r)
+			}
+		}
+
+		return found
+	}
+
+	return only(current, previous), only(previous, current)
+}
+
+// diffSignatures returns the signatures only in current and the signatures
+// only in previous, identified by the key and the signature.
+func diffSignatures(previous, current []signature.EntitySignature) (added, removed []signature.EntitySignature) {
+	only := func(signatures, other []signature.EntitySignature) []signature.EntitySignature {
+		keys := make(map[string]bool, len(other))
+		for _, s := range other {
+			keys[s.KeyID+"\x00"+s.Signature] = true
+		}
+
+		var found []signature.EntitySignature
+		for _, s := range signatures {
+			if !keys[s.KeyID+"\x00"+s.Signature] {
+				found = append(found, s)
+			}
+		}
+
+		return found
+	}
+
+	return only(current, previous), only(previous, current)
+}
+
+// DiffReports compares the components of the previous and the current
+// reports.
+func DiffReports(previous, current *Report) ReportDiff {
+	var diff ReportDiff
+
+	previousComponents := make(map[string]Component, len(previous.Components))
+	for _, c := range previous.Components {
+		previousComponents[diffKey(c)] = c
+	}
+
+	seen := make(map[string]bool, len(current.Components))
+	for _, c := range current.Components {
+		key := diffKey(c)
+		seen[key] = true
+
+		p, ok := previousComponents[key]
+		if !ok {
+			diff.Added = append(diff.Added, c)
+			continue
+		}
+
+		d := ComponentDiff{
+			Name:                    c.Name,
+			ContainerImage:          c.ContainerImage,
+			Success:                 c.Success,
+			PreviousSuccess:         p.Success,
+			EvaluationError:         c.EvaluationError,
+			PreviousEvaluationError: p.EvaluationError,
+		}
+		if p.ContainerImage != c.ContainerImage {
+			d.PreviousImage = p.ContainerImage
+		}
+		d.NewViolations, d.ResolvedViolations = diffResults(p.Violations, c.Violations)
+		d.NewWarnings, d.ResolvedWarnings = diffResults(p.Warnings, c.Warnings)
+		d.NewSignatures, d.RemovedSignatures = diffSignatures(p.Signatures, c.Signatures)
+
+		if d.changed() {
+			diff.Changed = append(diff.Changed, d)
+		}
+	}
+
+	for _, c := range previous.Components {
+		if !seen[diffKey(c)] {
+			diff.Removed = append(diff.Removed, c)
+		}
+	}
+
+	byKey := func(a, b Component) int {
+		return cmp.Compare(diffKey(a), diffKey(b))
+	}
+	slices.SortStableFunc(diff.Added, byKey)
+	slices.SortStableFunc(diff.Removed, byKey)
+	slices.SortStableFunc(diff.Changed, func(a, b ComponentDiff) int {
+		return cmp.Compare(a.Name, b.Name)
+	})
+
+	return diff
+}
+
+func (d ComponentDiff) changed() bool {
+	return d.PreviousImage != "" || d.Success != d.PreviousSuccess ||
+		d.EvaluationError != d.PreviousEvaluationError ||
+		len(d.NewViolations) > 0 || len(d.ResolvedViolations) > 0 ||
+		len(d.NewWarnings) > 0 || len(d.ResolvedWarnings) > 0 ||
+		len(d.NewSignatures) > 0 || len(d.RemovedSignatures) > 0
+}
+
+// regressed returns true if the component has new violations, or no longer
+// passes.
+func (d ComponentDiff) regressed() bool {
+	return len(d.NewViolations) > 0 ||
+		(d.PreviousSuccess && !d.Success) ||
+		(d.PreviousEvaluationError == "" && d.EvaluationError != "")
+}
+
+// Regressions returns the names of the components, or the images of unnamed
+// components, which fare worse in the current report: added components that
+// do not pass and changed components with new violations or that no longer
+// pass. Removed components are not regressions.
+func (d ReportDiff) Regressions() []string {
+	var regressions []string
+	for _, c := range d.Added {
+		if !c.Success || c.EvaluationError != "" {
+			regressions = append(regressions, cmp.Or(c.Name, c.ContainerImage))
+		}
+	}
+	for _, c := range d.Changed {
+		if c.regressed() {
+			regressions = append(regressions, cmp.Or(c.Name, c.ContainerImage))
+		}
+	}
+
+	return regressions
+}
+
+// Empty returns true if the reports have no differences.
+func (d ReportDiff) Empty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Changed) == 0
+}
+
+// WriteText writes a human readable summary of the differences, a line per
+// component prefixed by "+" when added, "-" when removed and "~" when changed,
+// followed by the changed results of the component.
+func (d ReportDiff) WriteText(w io.Writer) error {
+	out := bufio.NewWriter(w)
+
+	if d.Empty() {
+		fmt.Fprintln(out, "No differences")
+		return out.Flush()
+	}
+
+	status := func(success bool, evaluationError string) string {
+		switch {
+		case evaluationError != "":
+			return "error"
+		case success:
+			return "passed"
+		default:
+			return "failed"
+		}
+	}
+
+	name := func(n string) string {
+		if n == "" {
+			return unnamed
+		}
+		return n
+	}
+
+	results := func(prefix, kind string, results []evaluator.Result) {
+		for _, r := range results {
+			if code, _ := r.Metadata["code"].(string); code != "" {
+				fmt.Fprintf(out, "    %s %s [%s] %s\n", prefix, kind, code, r.Message)
+			} else {
+				fmt.Fprintf(out, "    %s %s %s\n", prefix, kind, r.Message)
+			}
+		}
+	}
+
+	signatures := func(prefix string, signatures []signature.EntitySignature) {
+		for _, s := range signatures {
+			signer := s.KeyID
+			if s.CertificateIdentity != nil && s.CertificateIdentity.Subject != "" {
+				signer = s.CertificateIdentity.Subject
+			}
+			fmt.Fprintf(out, "    %s signature %s\n", prefix, cmp.Or(signer, "(no key id)"))
+		}
+	}
+
+	for _, c := range d.Added {
+		fmt.Fprintf(out, "+ %s (%s): %s\n", name(c.Name), c.ContainerImage, status(c.Success, c.EvaluationError))
+	}
+	for _, c := range d.Removed {
+		fmt.Fprintf(out, "- %s (%s)\n", name(c.Name), c.ContainerImage)
+	}
+	for _, c := range d.Changed {
+		fmt.Fprintf(out, "~ %s (%s): %s -> %s\n", name(c.Name), c.ContainerImage,
+			status(c.PreviousSuccess, c.PreviousEvaluationError), status(c.Success, c.EvaluationError))
+		if c.PreviousImage != "" {
+			fmt.Fprintf(out, "    image changed from %s\n", c.PreviousImage)
+		}
+		if c.EvaluationError != "" && c.EvaluationError != c.PreviousEvaluationError {
+			fmt.Fprintf(out, "    evaluation error: %s\n", c.EvaluationError)
+		}
+		results("+", "violation", c.NewViolations)
+		results("-", "violation", c.ResolvedViolations)
+		results("+", "warning", c.NewWarnings)
+		results("-", "warning", c.ResolvedWarnings)
+		signatures("+", c.NewSignatures)
+		signatures("-", c.RemovedSignatures)
+	}
+
+	return out.Flush()
+}
@@ -0,0 +1,128 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package applicationsnapshot
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/gkampitakis/go-snaps/snaps"
+	app "github.com/konflux-ci/application-api/api/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/enterprise-contract/ec-cli/internal/evaluator"
+)
+
+func TestDiffReports(t *testing.T) {
+	component := func(name, image string, violations, warnings []evaluator.Result) Component {
+		return Component{
+			SnapshotComponent: app.SnapshotComponent{Name: name, ContainerImage: image},
+			Violations:        violations,
+			Warnings:          warnings,
+			Success:           len(violations) == 0,
+		}
+	}
+	result := func(code, msg string) evaluator.Result {
+		return evaluator.Result{Message: msg, Metadata: map[string]any{"code": code}}
+	}
+
+	previous := &Report{Components: []Component{
+		component("spam", "registry.io/spam@sha256:1", []evaluator.Result{result("a.b", "one")}, nil),
+		component("bacon", "registry.io/bacon@sha256:1", nil, []evaluator.Result{result("c.d", "two")}),
+		component("ham", "registry.io/ham@sha256:1", nil, nil),
+		component("", "registry.io/unnamed:v1", nil, nil),
+		component("eggs", "registry.io/eggs@sha256:1", nil, nil),
+	}}
+	current := &Report{Components: []Component{
+		component("spam", "registry.io/spam@sha256:2", nil, nil),
+		component("bacon", "registry.io/bacon@sha256:1", []evaluator.Result{result("e.f", "three")}, []evaluator.Result{result("c.d", "two")}),
+		component("ham", "registry.io/ham@sha256:1", nil, nil),
+		component("", "registry.io/unnamed@sha256:2", nil, nil),
+		component("beans", "registry.io/beans@sha256:1", nil, nil),
+	}}
+
+	diff := DiffReports(previous, current)
+
+	require.Len(t, diff.Added, 1)
+	assert.Equal(t, "beans", diff.Added[0].Name)
+	require.Len(t, diff.Removed, 1)
+	assert.Equal(t, "eggs", diff.Removed[0].Name)
+	require.Len(t, diff.Changed, 3)
+	assert.Equal(t, "", diff.Changed[0].Name)
+	assert.Equal(t, "registry.io/unnamed:v1", diff.Changed[0].PreviousImage)
+	assert.Equal(t, "bacon", diff.Changed[1].Name)
+	assert.Equal(t, []evaluator.Result{result("e.f", "three")}, diff.Changed[1].NewViolations)
+	assert.Empty(t, diff.Changed[1].NewWarnings)
+	assert.Equal(t, "spam", diff.Changed[2].Name)
+	assert.Equal(t, []evaluator.Result{result("a.b", "one")}, diff.Changed[2].ResolvedViolations)
+	assert.Equal(t, []string{"bacon"}, diff.Regressions())
+
+	var buf bytes.Buffer
+	require.NoError(t, diff.WriteText(&buf))
+	snaps.MatchSnapshot(t, buf.String())
+}
+
+func TestDiffReportsEmpty(t *testing.T) {
+	report := &Report{Components: []Component{
+		{SnapshotComponent: app.SnapshotComponent{Name: "spam", ContainerImage: "registry.io/spam@sha256:1"}, Success: true},
+	}}
+
+	diff := DiffReports(report, report)
+	assert.True(t, diff.Empty())
+	assert.Empty(t, diff.Regressions())
+
+	var buf bytes.Buffer
+	require.NoError(t, diff.WriteText(&buf))
+	assert.Equal(t, "No differences\n", buf.String())
+}
+
+func TestDiffReportsFingerprint(t *testing.T) {
+	component := func(image string, violations ...evaluator.Result) Component {
+		return Component{
+			SnapshotComponent: app.SnapshotComponent{Name: "spam", ContainerImage: image},
+			Violations:        violations,
+		}
+	}
+	result := func(msg, fingerprint string) evaluator.Result {
+		return evaluator.Result{Message: msg, Metadata: map[string]any{"code": "a.b"}, Fingerprint: fingerprint}
+	}
+
+	digest1 := "sha256:" + strings.Repeat("1", 64)
+	digest2 := "sha256:" + strings.Repeat("2", 64)
+
+	// Reports without fingerprints use the default rules
+	previous := &Report{Components: []Component{component("registry.io/spam@"+digest1, result("Image "+digest1+" is old", ""))}}
+	current := &Report{Components: []Component{component("registry.io/spam@"+digest2, result("Image "+digest2+" is old", ""))}}
+
+	diff := DiffReports(previous, current)
+	require.Len(t, diff.Changed, 1)
+	assert.Empty(t, diff.Changed[0].NewViolations)
+	assert.Empty(t, diff.Changed[0].ResolvedViolations)
+
+	// The reported fingerprints take precedence
+	previous = &Report{Components: []Component{component("registry.io/spam@"+digest1, result("one", "f00d"))}}
+	current = &Report{Components: []Component{component("registry.io/spam@"+digest2, result("two", "f00d"), result("three", "beef"))}}
+
+	diff = DiffReports(previous, current)
+	require.Len(t, diff.Changed, 1)
+	assert.Equal(t, []evaluator.Result{result("three", "beef")}, diff.Changed[0].NewViolations)
+	assert.Empty(t, diff.Changed[0].ResolvedViolations)
+}
@@ -0,0 +1,35 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package applicationsnapshot
+
+import (
+	"github.com/enterprise-contract/ec-cli/internal/evaluator"
+	"github.com/enterprise-contract/ec-cli/internal/fingerprint"
+)
+
+// SetFingerprints sets the fingerprint of each of the results of the
+// component, including the results of its platforms, so that the results can
+// be matched across reports, see DiffReports, and by waivers.
+func (c *Component) SetFingerprints(n fingerprint.Normalizer) {
+	for _, results := range [][]evaluator.Result{c.Violations, c.Warnings, c.Successes, c.Waived, c.Infos} {
+		n.SetFingerprints(results)
+	}
+
+	for i := range c.Platforms {
+		c.Platforms[i].SetFingerprints(n)
+	}
+}
@@ -0,0 +1,96 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package applicationsnapshot
+
+import (
+	"bytes"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/enterprise-contract/ec-cli/internal/evaluator"
+	"github.com/enterprise-contract/ec-cli/pkg/sink"
+)
+
+// GitHubStepSummary is the name of the sink the markdown report is appended to
+// when the github format is used within a GitHub Actions job.
+const GitHubStepSummary = "github-step-summary"
+
+var (
+	// see https://github.com/actions/toolkit/blob/main/packages/core/src/command.ts
+	gitHubDataEscaper     = strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A")
+	gitHubPropertyEscaper = strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A", ":", "%3A", ",", "%2C")
+)
+
+// WithGitHubStepSummary returns the targets extended with a markdown target
+// writing to the GitHubStepSummary sink for each github target, when running
+// in a GitHub Actions job, i.e. when the path of the job summary file is set.
+func WithGitHubStepSummary(targets []string, summaryPath string) []string {
+	if summaryPath == "" {
+		return targets
+	}
+
+	extended := slices.Clone(targets)
+	for _, target := range targets {
+		formatAndPath, opts, foundOpts := strings.Cut(target, "?")
+		format, _, _ := strings.Cut(formatAndPath, "=")
+		if format != GitHub {
+			continue
+		}
+		summary := Markdown + "=" + sink.Scheme + GitHubStepSummary
+		if foundOpts {
+			summary += "?" + opts
+		}
+		extended = append(extended, summary)
+	}
+
+	return extended
+}
+
+// renderGitHub renders the report as GitHub Actions workflow commands, an
+// error annotation for each violation and evaluation error and a warning
+// annotation for each warning, titled with the rule code and the image.
+func (r *Report) renderGitHub() ([]byte, error) {
+	var buf bytes.Buffer
+
+	for _, c := range r.Components {
+		if c.EvaluationError != "" {
+			writeGitHubAnnotation(&buf, "error", "evaluation error", c.ContainerImage, c.EvaluationError)
+		}
+		for _, v := range c.Violations {
+			writeGitHubAnnotation(&buf, "error", gitHubTitle(v), c.ContainerImage, v.Message)
+		}
+		for _, w := range c.Warnings {
+			writeGitHubAnnotation(&buf, "warning", gitHubTitle(w), c.ContainerImage, w.Message)
+		}
+	}
+
+	return buf.Bytes(), nil
+}
+
+func gitHubTitle(result evaluator.Result) string {
+	if code, ok := result.Metadata["code"].(string); ok && code != "" {
+		return code
+	}
+	return "policy"
+}
+
+func writeGitHubAnnotation(buf *bytes.Buffer, command, title, image, message string) {
+	fmt.Fprintf(buf, "::%s title=%s::%s\n", command,
+		gitHubPropertyEscaper.Replace(fmt.Sprintf("%s (%s)", title, image)),
+		gitHubDataEscaper.Replace(message))
+}
@@ -0,0 +1,106 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package applicationsnapshot
+
+import (
+	"testing"
+
+	"github.com/gkampitakis/go-snaps/snaps"
+	app "github.com/konflux-ci/application-api/api/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/enterprise-contract/ec-cli/internal/evaluator"
+)
+
+func Test_GitHubReport(t *testing.T) {
+	report := Report{
+		Components: []Component{
+			{
+				SnapshotComponent: app.SnapshotComponent{
+					Name:           "spam",
+					ContainerImage: "registry.io/repository/spam:latest",
+				},
+				Success: true,
+				Warnings: []evaluator.Result{
+					{Message: "warning", Metadata: map[string]any{"code": "policy.warn"}},
+				},
+			},
+			{
+				SnapshotComponent: app.SnapshotComponent{
+					Name:           "eggs",
+					ContainerImage: "registry.io/repository/eggs:latest",
+				},
+				Violations: []evaluator.Result{
+					{Message: "100% broken\nsecond line", Metadata: map[string]any{"code": "policy.rule"}},
+					{Message: "no code"},
+				},
+			},
+			{
+				SnapshotComponent: app.SnapshotComponent{
+					ContainerImage: "registry.io/repository/ham:latest",
+				},
+				EvaluationError: "runtime error",
+			},
+		},
+	}
+
+	data, err := report.toFormat(GitHub)
+	require.NoError(t, err)
+	snaps.MatchSnapshot(t, string(data))
+}
+
+func TestWithGitHubStepSummary(t *testing.T) {
+	cases := []struct {
+		name     string
+		targets  []string
+		path     string
+		expected []string
+	}{
+		{
+			name:     "outside of GitHub Actions",
+			targets:  []string{"github"},
+			expected: []string{"github"},
+		},
+		{
+			name:     "no github target",
+			targets:  []string{"json", "markdown=out.md"},
+			path:     "/summary",
+			expected: []string{"json", "markdown=out.md"},
+		},
+		{
+			name:     "github target",
+			targets:  []string{"text", "github"},
+			path:     "/summary",
+			expected: []string{"text", "github", "markdown=sink:github-step-summary"},
+		},
+		{
+			name:     "github target with options",
+			targets:  []string{"github?show-successes=true"},
+			path:     "/summary",
+			expected: []string{"github?show-successes=true", "markdown=sink:github-step-summary?show-successes=true"},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			assert.Equal(t, c.expected, WithGitHubStepSummary(c.targets, c.path))
+		})
+	}
+}
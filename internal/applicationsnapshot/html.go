@@ -0,0 +1,85 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package applicationsnapshot
+
+import (
+	"bytes"
+	"embed"
+	"encoding/json"
+	"html/template"
+	"time"
+
+	"github.com/enterprise-contract/ec-cli/internal/attestation"
+	"github.com/enterprise-contract/ec-cli/internal/evaluator"
+)
+
+// The HTML report is rendered with html/template, apart from the text
+// templates, so that all values are escaped.
+//
+//go:embed templates/html/*.tmpl
+var htmlfs embed.FS
+
+var htmlHelpers = template.FuncMap{
+	"results":   func(typ string, results []evaluator.Result) htmlResults { return htmlResults{typ, results} },
+	"statement": htmlStatement,
+	"timestamp": func(t time.Time) string { return t.UTC().Format(time.RFC3339) },
+}
+
+// htmlResults are the results of a component of the given type, i.e.
+// violation, warning or success
+type htmlResults struct {
+	Type    string
+	Results []evaluator.Result
+}
+
+// renderHTML renders the report as a self-contained HTML page, with a section
+// for each component holding its results, signatures and attestations. The
+// raw attestations are left out of a redacted report.
+func (r *Report) renderHTML() ([]byte, error) {
+	t, err := template.New("report.tmpl").Funcs(htmlHelpers).ParseFS(htmlfs, "templates/html/*.tmpl")
+	if err != nil {
+		return nil, err
+	}
+
+	input := struct {
+		Report     *Report
+		TestReport TestReport
+	}{
+		Report:     r.withCollapsedVerboseRules(),
+		TestReport: r.toAppstudioReport(),
+	}
+
+	var buf bytes.Buffer
+	if err := t.Execute(&buf, input); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// htmlStatement returns the statement of the attestation indented for
+// display, or as is if it is not valid JSON.
+func htmlStatement(a attestation.Attestation) string {
+	statement := a.Statement()
+
+	var buf bytes.Buffer
+	if err := json.Indent(&buf, statement, "", "  "); err != nil {
+		return string(statement)
+	}
+
+	return buf.String()
+}
@@ -0,0 +1,107 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package applicationsnapshot
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gkampitakis/go-snaps/snaps"
+	app "github.com/konflux-ci/application-api/api/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/enterprise-contract/ec-cli/internal/attestation"
+	"github.com/enterprise-contract/ec-cli/internal/evaluator"
+	"github.com/enterprise-contract/ec-cli/internal/signature"
+)
+
+func Test_HTMLReport(t *testing.T) {
+	signedAt := time.Date(2024, 1, 2, 3, 0, 0, 0, time.UTC)
+	report := Report{
+		Snapshot:      "snappy",
+		created:       time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		EffectiveTime: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		EcVersion:     "v0.1.2",
+		Components: []Component{
+			{
+				SnapshotComponent: app.SnapshotComponent{
+					Name:           "spam",
+					ContainerImage: "registry.io/repository/spam:latest",
+				},
+				Success:      true,
+				SuccessCount: 1,
+				Signatures: []signature.EntitySignature{
+					{
+						KeyID:    "key-1",
+						SignedAt: &signedAt,
+						Metadata: map[string]string{"predicateType": "https://slsa.dev/provenance/v0.2"},
+						CertificateIdentity: &signature.CertificateIdentity{
+							Subject: "https://github.com/org/repo/.github/workflows/push.yaml@refs/heads/main",
+							Issuer:  "https://token.actions.githubusercontent.com",
+						},
+					},
+				},
+				Attestations: []attestation.Attestation{att(`{"_type":"https://in-toto.io/Statement/v0.1"}`)},
+			},
+			{
+				SnapshotComponent: app.SnapshotComponent{
+					Name:           "eggs & ham",
+					ContainerImage: "registry.io/repository/eggs:latest",
+				},
+				Violations: []evaluator.Result{
+					{
+						Message: "<script>alert(1)</script>",
+						Metadata: map[string]any{
+							"code":     "test.violation",
+							"title":    "Violation",
+							"solution": "Fix it",
+						},
+					},
+				},
+				Warnings: []evaluator.Result{{Message: "warning", Metadata: map[string]any{"code": "test.warning"}}},
+			},
+		},
+	}
+
+	data, err := report.toFormat(HTML)
+	require.NoError(t, err)
+	snaps.MatchSnapshot(t, string(data))
+
+	assert.NotContains(t, string(data), "<script>")
+	assert.Contains(t, string(data), "&lt;script&gt;")
+}
+
+func Test_HTMLReportRedacted(t *testing.T) {
+	report := Report{
+		Components: []Component{
+			{
+				SnapshotComponent: app.SnapshotComponent{Name: "spam"},
+				Attestations:      []attestation.Attestation{att(`{"secret":"value"}`)},
+			},
+		},
+		Redacted: []string{RedactSigner},
+	}
+
+	data, err := report.toFormat(HTML)
+	require.NoError(t, err)
+
+	assert.NotContains(t, string(data), "secret")
+	assert.Contains(t, string(data), "The statement is not included in a redacted report.")
+}
@@ -0,0 +1,143 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package applicationsnapshot
+
+import (
+	"cmp"
+	"slices"
+	"strings"
+
+	app "github.com/konflux-ci/application-api/api/v1alpha1"
+)
+
+// Keys by which components can be identified, e.g. to find the same component
+// in different reports.
+const (
+	// IdentityImage identifies components by the image reference
+	IdentityImage = "image"
+	// IdentityDigest identifies components by the image digest, so the same
+	// image referenced by different tags is the same component
+	IdentityDigest = "digest"
+	// IdentityName identifies components by the component name
+	IdentityName = "name"
+)
+
+var IdentityKeys = []string{IdentityImage, IdentityDigest, IdentityName}
+
+// Modes of handling components of a snapshot with the same identity
+const (
+	// DuplicatesDedupe evaluates only the first of the components with the
+	// same identity
+	DuplicatesDedupe = "dedupe"
+	// DuplicatesError fails the validation of a snapshot with components with
+	// the same identity
+	DuplicatesError = "error"
+	// DuplicatesEvaluateAll evaluates all the components
+	DuplicatesEvaluateAll = "evaluate-all"
+)
+
+var DuplicateModes = []string{DuplicatesDedupe, DuplicatesError, DuplicatesEvaluateAll}
+
+// Identity returns the identity of the component by the given key. The image
+// reference is used if the component has no value for the key, e.g. when the
+// component has no name or the image is not referenced by digest.
+func (c Component) Identity(key string) string {
+	switch key {
+	case IdentityDigest:
+		if _, digest, found := strings.Cut(c.ContainerImage, "@"); found {
+			return digest
+		}
+	case IdentityName:
+		if c.Name != "" && c.Name != unnamed {
+			return c.Name
+		}
+	}
+
+	return c.ContainerImage
+}
+
+// SortComponents sorts the components by their identity. Of the components
+// with the same identity, the failed components come first.
+func SortComponents(components []Component, key string) []Component {
+	sorted := slices.Clone(components)
+	slices.SortStableFunc(sorted, func(a, b Component) int {
+		if c := cmp.Compare(a.Identity(key), b.Identity(key)); c != 0 {
+			return c
+		}
+		// failed components first
+		if a.Success != b.Success {
+			if a.Success {
+				return 1
+			}
+			return -1
+		}
+		return cmp.Compare(a.ContainerImage, b.ContainerImage)
+	})
+
+	return sorted
+}
+
+// UniqueComponents sorts the components by their identity and removes the
+// components with the same identity, keeping only one. A failed component is
+// kept over a successful one, so that removing duplicates does not hide a
+// failure.
+func UniqueComponents(components []Component, key string) []Component {
+	return slices.CompactFunc(SortComponents(components, key), func(a, b Component) bool {
+		return a.Identity(key) == b.Identity(key)
+	})
+}
+
+// Repository returns the repository of the image of the component, i.e. the
+// image reference without the tag and the digest.
+func (c Component) Repository() string {
+	repository, _, _ := strings.Cut(c.ContainerImage, "@")
+	if i := strings.LastIndex(repository, ":"); i > strings.LastIndex(repository, "/") {
+		repository = repository[:i]
+	}
+
+	return repository
+}
+
+// DedupeComponents removes the components with the same identity, by the given
+// key, as an earlier component. The container images of the removed components
+// are returned by the container image of the component kept in their place.
+// Components are duplicates only if their images are in the same repository,
+// as the signatures and the attestations of an image are stored along with it,
+// and if they are evaluated in the same way, as given by the evaluation
+// function, e.g. with the same policy.
+func DedupeComponents(components []app.SnapshotComponent, key string, evaluation func(app.SnapshotComponent) string) ([]app.SnapshotComponent, map[string][]string) {
+	kept := map[string]string{}
+	var unique []app.SnapshotComponent
+	var duplicates map[string][]string
+	for _, c := range components {
+		component := Component{SnapshotComponent: c}
+		identity := strings.Join([]string{component.Identity(key), component.Repository(), evaluation(c)}, "\x00")
+		image, found := kept[identity]
+		if !found {
+			kept[identity] = c.ContainerImage
+			unique = append(unique, c)
+			continue
+		}
+
+		if duplicates == nil {
+			duplicates = map[string][]string{}
+		}
+		duplicates[image] = append(duplicates[image], c.ContainerImage)
+	}
+
+	return unique, duplicates
+}
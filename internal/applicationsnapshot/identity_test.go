@@ -0,0 +1,142 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package applicationsnapshot
+
+import (
+	"testing"
+
+	app "github.com/konflux-ci/application-api/api/v1alpha1"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIdentity(t *testing.T) {
+	component := Component{}
+	component.Name = "spam"
+	component.ContainerImage = "registry.io/spam:latest@sha256:a3c8e5bb4e3d3c1bd1e0e0d7e3b6c8d0f3f8d8a8c8e8b8a8d8c8e8b8a8d8c8e8"
+
+	assert.Equal(t, component.ContainerImage, component.Identity(IdentityImage))
+	assert.Equal(t, "sha256:a3c8e5bb4e3d3c1bd1e0e0d7e3b6c8d0f3f8d8a8c8e8b8a8d8c8e8b8a8d8c8e8", component.Identity(IdentityDigest))
+	assert.Equal(t, "spam", component.Identity(IdentityName))
+
+	unnamedTag := Component{}
+	unnamedTag.Name = unnamed
+	unnamedTag.ContainerImage = "registry.io/spam:latest"
+
+	assert.Equal(t, unnamedTag.ContainerImage, unnamedTag.Identity(IdentityDigest))
+	assert.Equal(t, unnamedTag.ContainerImage, unnamedTag.Identity(IdentityName))
+}
+
+func TestUniqueComponents(t *testing.T) {
+	component := func(name, image string, success bool) Component {
+		c := Component{Success: success}
+		c.Name = name
+		c.ContainerImage = image
+		return c
+	}
+
+	components := []Component{
+		component("spam", "registry.io/spam:1@sha256:aaa", true),
+		component("eggs", "registry.io/eggs@sha256:bbb", true),
+		component("spam", "registry.io/spam:2@sha256:aaa", false),
+		component("ham", "registry.io/ham@sha256:aaa", true),
+	}
+
+	cases := []struct {
+		name     string
+		key      string
+		expected []Component
+	}{
+		{
+			name:     "image",
+			key:      IdentityImage,
+			expected: []Component{components[1], components[3], components[0], components[2]},
+		},
+		{
+			name:     "digest",
+			key:      IdentityDigest,
+			expected: []Component{components[2], components[1]},
+		},
+		{
+			name:     "name",
+			key:      IdentityName,
+			expected: []Component{components[1], components[3], components[2]},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			assert.Equal(t, c.expected, UniqueComponents(components, c.key))
+		})
+	}
+
+	// components with the same identity are kept when only sorting
+	assert.Equal(t, []Component{components[2], components[3], components[0], components[1]}, SortComponents(components, IdentityDigest))
+
+	// the given components are not modified
+	assert.Equal(t, "registry.io/spam:1@sha256:aaa", components[0].ContainerImage)
+}
+
+func TestDedupeComponents(t *testing.T) {
+	components := []app.SnapshotComponent{
+		{Name: "spam", ContainerImage: "registry.io/spam:1@sha256:aaa"},
+		{Name: "eggs", ContainerImage: "registry.io/eggs@sha256:bbb"},
+		{Name: "spam", ContainerImage: "registry.io/spam:2@sha256:aaa"},
+		{Name: "ham", ContainerImage: "registry.io/ham@sha256:aaa"},
+	}
+
+	same := func(app.SnapshotComponent) string { return "" }
+
+	unique, duplicates := DedupeComponents(components, IdentityImage, same)
+	assert.Equal(t, components, unique)
+	assert.Nil(t, duplicates)
+
+	// The image in another repository is not a duplicate, its signatures and
+	// attestations are in that repository
+	unique, duplicates = DedupeComponents(components, IdentityDigest, same)
+	assert.Equal(t, []app.SnapshotComponent{components[0], components[1], components[3]}, unique)
+	assert.Equal(t, map[string][]string{
+		"registry.io/spam:1@sha256:aaa": {"registry.io/spam:2@sha256:aaa"},
+	}, duplicates)
+
+	unique, duplicates = DedupeComponents(components, IdentityName, same)
+	assert.Equal(t, []app.SnapshotComponent{components[0], components[1], components[3]}, unique)
+	assert.Equal(t, map[string][]string{
+		"registry.io/spam:1@sha256:aaa": {"registry.io/spam:2@sha256:aaa"},
+	}, duplicates)
+
+	// Components evaluated differently, e.g. with a policy override, are not
+	// duplicates
+	unique, duplicates = DedupeComponents(components, IdentityDigest, func(c app.SnapshotComponent) string {
+		return c.ContainerImage
+	})
+	assert.Equal(t, components, unique)
+	assert.Nil(t, duplicates)
+}
+
+func TestRepository(t *testing.T) {
+	component := Component{}
+	component.ContainerImage = "registry.io/spam:latest@sha256:a3c8e5bb4e3d3c1bd1e0e0d7e3b6c8d0f3f8d8a8c8e8b8a8d8c8e8b8a8d8c8e8"
+	assert.Equal(t, "registry.io/spam", component.Repository())
+
+	component.ContainerImage = "registry.io:5000/spam:latest"
+	assert.Equal(t, "registry.io:5000/spam", component.Repository())
+
+	component.ContainerImage = "registry.io:5000/spam"
+	assert.Equal(t, "registry.io:5000/spam", component.Repository())
+}
@@ -20,8 +20,11 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strconv"
+	"strings"
 
 	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
 	app "github.com/konflux-ci/application-api/api/v1alpha1"
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/afero"
@@ -29,6 +32,7 @@ import (
 	"sigs.k8s.io/yaml"
 
 	"github.com/enterprise-contract/ec-cli/internal/kubernetes"
+	"github.com/enterprise-contract/ec-cli/internal/signature"
 	"github.com/enterprise-contract/ec-cli/internal/utils"
 	"github.com/enterprise-contract/ec-cli/internal/utils/oci"
 )
@@ -41,10 +45,149 @@ type Input struct {
 	Image    string
 	Snapshot string
 	Images   string
+	// Offline skips the expansion of image indexes, which requires access
+	// to the registry
+	Offline bool
+	// Platforms restricts the images of image indexes validated to those of
+	// the given platforms, e.g. linux/amd64, see expandImageIndex
+	Platforms []string
+}
+
+// PolicyOverrideAnnotation is the annotation of a component within the
+// snapshot selecting the policy configuration the component is validated
+// against instead of the policy configuration given for the whole snapshot.
+// The value takes the same form as the --policy flag of ec validate image,
+// and is only used when allowed with the --allow-policy-override flag.
+const PolicyOverrideAnnotation = "ec.enterprise-contract.dev/policy"
+
+// PriorityAnnotation is the annotation of a component within the snapshot
+// setting the priority of the component, an integer. Components with a higher
+// priority are dispatched for evaluation before the others, see
+// OrderComponents. Components without the annotation have the priority 0.
+const PriorityAnnotation = "ec.enterprise-contract.dev/priority"
+
+// ApplicationAnnotation is the annotation of a component within the snapshot
+// setting the application the component belongs to, for snapshots spanning
+// multiple applications. The report groups the components by application, see
+// NewApplicationResults. Components without the annotation belong to the
+// application of the snapshot.
+const ApplicationAnnotation = "ec.enterprise-contract.dev/application"
+
+// SignatureVerifierAnnotation is the annotation of a component within the
+// snapshot selecting the verifier of the image signature of the component,
+// one of signature.Verifiers, instead of the verifier given for the whole
+// snapshot.
+const SignatureVerifierAnnotation = "ec.enterprise-contract.dev/signature-verifier"
+
+// Snapshot holds the components to validate.
+type Snapshot struct {
+	app.SnapshotSpec
+	// PolicyOverrides maps the container image of a component to the policy
+	// configuration set by the PolicyOverrideAnnotation of the component.
+	PolicyOverrides map[string]string
+	// Priorities maps the container image of a component to the priority
+	// set by the PriorityAnnotation of the component.
+	Priorities map[string]int
+	// Applications maps the container image of a component to the
+	// application set by the ApplicationAnnotation of the component.
+	Applications map[string]string
+	// SignatureVerifiers maps the container image of a component to the
+	// verifier set by the SignatureVerifierAnnotation of the component.
+	SignatureVerifiers map[string]string
+	// Platforms maps the container image of a component expanded from an
+	// image index to the image index component and the platform of the
+	// image, see NestPlatforms.
+	Platforms map[string]ImagePlatform
+}
+
+// ImagePlatform identifies the image of a platform within an image index.
+type ImagePlatform struct {
+	// Index is the component of the image index
+	Index app.SnapshotComponent
+	// Platform of the image, e.g. linux/amd64, empty for images without a
+	// platform
+	Platform string
 }
 
 type snapshot struct {
 	app.SnapshotSpec
+	policyOverrides map[string]string
+	priorities      map[string]int
+	applications    map[string]string
+	verifiers       map[string]string
+}
+
+// componentAnnotations holds the values of the annotations of the components
+// by their container image.
+type componentAnnotations struct {
+	policyOverrides map[string]string
+	priorities      map[string]int
+	applications    map[string]string
+	verifiers       map[string]string
+}
+
+// annotatedSnapshot is used to read the annotations of the components which
+// are not part of app.SnapshotComponent.
+type annotatedSnapshot struct {
+	Components []struct {
+		ContainerImage string            `json:"containerImage"`
+		Annotations    map[string]string `json:"annotations"`
+	} `json:"components"`
+}
+
+func (s *snapshot) mergeAnnotations(annotations componentAnnotations) {
+	for image, policy := range annotations.policyOverrides {
+		if s.policyOverrides == nil {
+			s.policyOverrides = map[string]string{}
+		}
+		if _, ok := s.policyOverrides[image]; !ok {
+			s.policyOverrides[image] = policy
+		}
+	}
+	for image, priority := range annotations.priorities {
+		if s.priorities == nil {
+			s.priorities = map[string]int{}
+		}
+		if _, ok := s.priorities[image]; !ok {
+			s.priorities[image] = priority
+		}
+	}
+	for image, application := range annotations.applications {
+		if s.applications == nil {
+			s.applications = map[string]string{}
+		}
+		if _, ok := s.applications[image]; !ok {
+			s.applications[image] = application
+		}
+	}
+	for image, verifier := range annotations.verifiers {
+		if s.verifiers == nil {
+			s.verifiers = map[string]string{}
+		}
+		if _, ok := s.verifiers[image]; !ok {
+			s.verifiers[image] = verifier
+		}
+	}
+}
+
+// inheritAnnotations sets the values of the annotations of the image index
+// components on the components of the images of their platforms.
+func (s *snapshot) inheritAnnotations(platforms map[string]ImagePlatform) {
+	for image, p := range platforms {
+		index := p.Index.ContainerImage
+		if policy, ok := s.policyOverrides[index]; ok {
+			s.policyOverrides[image] = policy
+		}
+		if priority, ok := s.priorities[index]; ok {
+			s.priorities[image] = priority
+		}
+		if application, ok := s.applications[index]; ok {
+			s.applications[image] = application
+		}
+		if verifier, ok := s.verifiers[index]; ok {
+			s.verifiers[image] = verifier
+		}
+	}
 }
 
 func (s *snapshot) merge(snap app.SnapshotSpec) {
@@ -81,6 +224,18 @@ func (s *snapshot) merge(snap app.SnapshotSpec) {
 }
 
 func DetermineInputSpec(ctx context.Context, input Input) (*app.SnapshotSpec, error) {
+	s, err := DetermineInput(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+
+	return &s.SnapshotSpec, nil
+}
+
+// DetermineInput returns the snapshot to validate, including the policy
+// overrides, priorities, applications and signature verifiers set on its
+// components, from the given input.
+func DetermineInput(ctx context.Context, input Input) (*Snapshot, error) {
 	var snapshot snapshot
 	provided := false
 
@@ -95,11 +250,12 @@ func DetermineInputSpec(ctx context.Context, input Input) (*app.SnapshotSpec, er
 			content = []byte(input.Images)
 		}
 
-		file, err := readSnapshotSource(content)
+		file, annotations, err := readSnapshotSource(content)
 		if err != nil {
 			return nil, err
 		}
 		snapshot.merge(file)
+		snapshot.mergeAnnotations(annotations)
 		provided = true
 	}
 
@@ -110,21 +266,23 @@ func DetermineInputSpec(ctx context.Context, input Input) (*app.SnapshotSpec, er
 		if err != nil {
 			return nil, err
 		}
-		file, err := readSnapshotSource(content)
+		file, annotations, err := readSnapshotSource(content)
 		if err != nil {
 			return nil, err
 		}
 		snapshot.merge(file)
+		snapshot.mergeAnnotations(annotations)
 		provided = true
 	}
 
 	// read Snapshot provided as a string
 	if input.JSON != "" {
-		json, err := readSnapshotSource([]byte(input.JSON))
+		json, annotations, err := readSnapshotSource([]byte(input.JSON))
 		if err != nil {
 			return nil, err
 		}
 		snapshot.merge(json)
+		snapshot.mergeAnnotations(annotations)
 		provided = true
 	}
 
@@ -163,27 +321,103 @@ func DetermineInputSpec(ctx context.Context, input Input) (*app.SnapshotSpec, er
 		log.Debug("No application snapshot available")
 		return nil, errors.New("neither Snapshot nor image reference provided to validate")
 	}
-	expandImageIndex(ctx, &snapshot.SnapshotSpec)
 
-	return &snapshot.SnapshotSpec, nil
+	var platforms map[string]ImagePlatform
+	if !input.Offline {
+		var err error
+		if platforms, err = expandImageIndex(ctx, &snapshot.SnapshotSpec, input.Platforms); err != nil {
+			return nil, err
+		}
+		snapshot.inheritAnnotations(platforms)
+	}
+
+	return &Snapshot{SnapshotSpec: snapshot.SnapshotSpec, PolicyOverrides: snapshot.policyOverrides, Priorities: snapshot.priorities, Applications: snapshot.applications, SignatureVerifiers: snapshot.verifiers, Platforms: platforms}, nil
 }
 
-func readSnapshotSource(input []byte) (app.SnapshotSpec, error) {
+// readSnapshotSource parses the snapshot specification, returning it along
+// with the policy overrides, priorities, applications and signature verifiers,
+// by container image, set via the PolicyOverrideAnnotation, PriorityAnnotation,
+// ApplicationAnnotation and SignatureVerifierAnnotation of its components.
+func readSnapshotSource(input []byte) (app.SnapshotSpec, componentAnnotations, error) {
 	var file app.SnapshotSpec
 	err := yaml.Unmarshal(input, &file)
 	if err != nil {
 		log.Debugf("Problem parsing application snapshot from file %s", input)
-		return app.SnapshotSpec{}, fmt.Errorf("unable to parse Snapshot specification from %s: %w", input, err)
+		return app.SnapshotSpec{}, componentAnnotations{}, fmt.Errorf("unable to parse Snapshot specification from %s: %w", input, err)
+	}
+
+	var annotated annotatedSnapshot
+	if err := yaml.Unmarshal(input, &annotated); err != nil {
+		return app.SnapshotSpec{}, componentAnnotations{}, fmt.Errorf("unable to parse Snapshot specification from %s: %w", input, err)
+	}
+
+	var annotations componentAnnotations
+	// The policy overrides are looked up by the container image, components
+	// with the same image must be validated against the same policy
+	overrides := map[string]string{}
+	for _, c := range annotated.Components {
+		policy := c.Annotations[PolicyOverrideAnnotation]
+		if previous, ok := overrides[c.ContainerImage]; ok && previous != policy {
+			return app.SnapshotSpec{}, componentAnnotations{}, fmt.Errorf("components with the same image %q have different policy overrides", c.ContainerImage)
+		}
+		overrides[c.ContainerImage] = policy
+
+		if policy != "" {
+			if annotations.policyOverrides == nil {
+				annotations.policyOverrides = map[string]string{}
+			}
+			annotations.policyOverrides[c.ContainerImage] = policy
+		}
+		if value, ok := c.Annotations[PriorityAnnotation]; ok && value != "" {
+			priority, err := strconv.Atoi(value)
+			if err != nil {
+				return app.SnapshotSpec{}, componentAnnotations{}, fmt.Errorf("invalid priority %q of component %q, expected an integer", value, c.ContainerImage)
+			}
+			if annotations.priorities == nil {
+				annotations.priorities = map[string]int{}
+			}
+			annotations.priorities[c.ContainerImage] = priority
+		}
+		if application, ok := c.Annotations[ApplicationAnnotation]; ok && application != "" {
+			if annotations.applications == nil {
+				annotations.applications = map[string]string{}
+			}
+			annotations.applications[c.ContainerImage] = application
+		}
+		if verifier, ok := c.Annotations[SignatureVerifierAnnotation]; ok && verifier != "" {
+			if !slices.Contains(signature.Verifiers, verifier) {
+				return app.SnapshotSpec{}, componentAnnotations{}, fmt.Errorf("invalid signature verifier %q of component %q, expected one of: %s", verifier, c.ContainerImage, strings.Join(signature.Verifiers, ", "))
+			}
+			if annotations.verifiers == nil {
+				annotations.verifiers = map[string]string{}
+			}
+			annotations.verifiers[c.ContainerImage] = verifier
+		}
 	}
 
 	log.Debugf("Read application snapshot from file %s", input)
-	return file, nil
+	return file, annotations, nil
 }
 
-func expandImageIndex(ctx context.Context, snap *app.SnapshotSpec) {
+// expandImageIndex replaces each component of an image index with a component
+// for the image of each of its platforms, so that the image of each platform
+// is validated. When platforms are given only the images of the platforms
+// satisfying any of them are kept. The image index component and the platform
+// of each of the images are returned by the container image of the image.
+func expandImageIndex(ctx context.Context, snap *app.SnapshotSpec, platforms []string) (map[string]ImagePlatform, error) {
+	filter := make([]v1.Platform, 0, len(platforms))
+	for _, p := range platforms {
+		platform, err := v1.ParsePlatform(p)
+		if err != nil || platform.OS == "" {
+			return nil, fmt.Errorf("invalid platform %q, expected <os>[/<arch>[/<variant>]]", p)
+		}
+		filter = append(filter, *platform)
+	}
+
 	client := oci.NewClient(ctx)
 	// For an image index, remove the original component and replace it with an expanded component with all its image manifests
 	var components []app.SnapshotComponent
+	var expanded map[string]ImagePlatform
 	// Do not raise an error if the image is inaccessible, it will be handled as a violation when evaluated against the policy
 	// This is to retain the original behavior of the `ec validate` command.
 	var allErrors error = nil
@@ -220,7 +454,13 @@ func expandImageIndex(ctx context.Context, snap *app.SnapshotSpec) {
 
 		// The image is an image index and accessible so remove the image index itself and add index manifests
 		components = components[:len(components)-1]
+		matched := 0
 		for i, manifest := range indexManifest.Manifests {
+			if len(filter) > 0 && !satisfiesAny(manifest.Platform, filter) {
+				continue
+			}
+			matched++
+
 			var arch string
 			if manifest.Platform != nil && manifest.Platform.Architecture != "" {
 				arch = manifest.Platform.Architecture
@@ -231,6 +471,15 @@ func expandImageIndex(ctx context.Context, snap *app.SnapshotSpec) {
 			archComponent.Name = fmt.Sprintf("%s-%s-%s", component.Name, manifest.Digest, arch)
 			archComponent.ContainerImage = fmt.Sprintf("%s@%s", ref.Context().Name(), manifest.Digest)
 			components = append(components, archComponent)
+
+			if expanded == nil {
+				expanded = map[string]ImagePlatform{}
+			}
+			expanded[archComponent.ContainerImage] = ImagePlatform{Index: component, Platform: platformName(manifest.Platform)}
+		}
+
+		if len(filter) > 0 && matched == 0 {
+			return nil, fmt.Errorf("none of the images of the image index %s is for the platforms %s", component.ContainerImage, strings.Join(platforms, ", "))
 		}
 	}
 
@@ -240,4 +489,28 @@ func expandImageIndex(ctx context.Context, snap *app.SnapshotSpec) {
 		log.Warnf("Encountered error while checking for Image Index: %v", allErrors)
 	}
 	log.Debugf("Snap component after expanding the image index is %v", snap.Components)
+
+	return expanded, nil
+}
+
+// satisfiesAny returns true if the platform satisfies any of the given
+// platforms, images without a platform satisfy none.
+func satisfiesAny(platform *v1.Platform, platforms []v1.Platform) bool {
+	if platform == nil {
+		return false
+	}
+
+	return slices.ContainsFunc(platforms, platform.Satisfies)
+}
+
+// platformName returns the platform as <os>/<arch>[/<variant>], or only the
+// architecture when the platform has no operating system set.
+func platformName(platform *v1.Platform) string {
+	if platform == nil {
+		return ""
+	}
+	if p := platform.String(); p != "" {
+		return p
+	}
+	return platform.Architecture
 }
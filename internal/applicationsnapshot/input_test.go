@@ -36,6 +36,7 @@ import (
 	"github.com/spf13/afero"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 
 	"github.com/enterprise-contract/ec-cli/internal/kubernetes"
 	"github.com/enterprise-contract/ec-cli/internal/policy"
@@ -211,7 +212,7 @@ func TestReadSnapshotFile(t *testing.T) {
 
 		content, err := afero.ReadFile(fs, "/correct.json")
 		assert.NoError(t, err)
-		got, err := readSnapshotSource(content)
+		got, _, err := readSnapshotSource(content)
 		assert.Equal(t, snapshotSpec, got)
 		assert.NoError(t, err)
 	})
@@ -228,13 +229,84 @@ func TestReadSnapshotFile(t *testing.T) {
 
 		content, err := afero.ReadFile(fs, specFile)
 		assert.NoError(t, err)
-		_, err = readSnapshotSource(content)
+		_, _, err = readSnapshotSource(content)
 		wrapped := errors.New("error unmarshaling JSON: while decoding JSON: json: cannot unmarshal string into Go value of type v1alpha1.SnapshotSpec")
 		expected := fmt.Errorf("unable to parse Snapshot specification from %s: %w", spec, wrapped)
 		assert.Error(t, err, expected)
 	})
 }
 
+func TestDetermineInputPolicyOverrides(t *testing.T) {
+	images := `{"components":[
+		{"name": "app", "containerImage": "registry.io/repository/app@sha256:0123"},
+		{"name": "infra", "containerImage": "registry.io/repository/infra@sha256:4567",
+		 "annotations": {"ec.enterprise-contract.dev/policy": "/policies/infra.yaml", "other": "ignored"}}
+	]}`
+
+	ctx := utils.WithFS(context.Background(), afero.NewMemMapFs())
+	s, err := DetermineInput(ctx, Input{Images: images})
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]string{
+		"registry.io/repository/infra@sha256:4567": "/policies/infra.yaml",
+	}, s.PolicyOverrides)
+	assert.Len(t, s.Components, 2)
+}
+
+func TestDetermineInputConflictingPolicyOverrides(t *testing.T) {
+	images := `{"components":[
+		{"name": "app", "containerImage": "registry.io/repository/app@sha256:0123",
+		 "annotations": {"ec.enterprise-contract.dev/policy": "/policies/app.yaml"}},
+		{"name": "other", "containerImage": "registry.io/repository/app@sha256:0123"}
+	]}`
+
+	ctx := utils.WithFS(context.Background(), afero.NewMemMapFs())
+	_, err := DetermineInput(ctx, Input{Images: images})
+	assert.ErrorContains(t, err, `components with the same image "registry.io/repository/app@sha256:0123" have different policy overrides`)
+}
+
+func TestDetermineInputPriorities(t *testing.T) {
+	images := `{"components":[
+		{"name": "app", "containerImage": "registry.io/repository/app@sha256:0123"},
+		{"name": "infra", "containerImage": "registry.io/repository/infra@sha256:4567",
+		 "annotations": {"ec.enterprise-contract.dev/priority": "10"}}
+	]}`
+
+	ctx := utils.WithFS(context.Background(), afero.NewMemMapFs())
+	s, err := DetermineInput(ctx, Input{Images: images})
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]int{
+		"registry.io/repository/infra@sha256:4567": 10,
+	}, s.Priorities)
+
+	invalid := `{"components":[{"name": "app", "containerImage": "registry.io/repository/app@sha256:0123",
+		"annotations": {"ec.enterprise-contract.dev/priority": "high"}}]}`
+	_, err = DetermineInput(ctx, Input{Images: invalid})
+	assert.EqualError(t, err, `invalid priority "high" of component "registry.io/repository/app@sha256:0123", expected an integer`)
+}
+
+func TestDetermineInputSignatureVerifiers(t *testing.T) {
+	images := `{"components":[
+		{"name": "app", "containerImage": "registry.io/repository/app@sha256:0123"},
+		{"name": "infra", "containerImage": "registry.io/repository/infra@sha256:4567",
+		 "annotations": {"ec.enterprise-contract.dev/signature-verifier": "notation"}}
+	]}`
+
+	ctx := utils.WithFS(context.Background(), afero.NewMemMapFs())
+	s, err := DetermineInput(ctx, Input{Images: images})
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]string{
+		"registry.io/repository/infra@sha256:4567": "notation",
+	}, s.SignatureVerifiers)
+
+	invalid := `{"components":[{"name": "app", "containerImage": "registry.io/repository/app@sha256:0123",
+		"annotations": {"ec.enterprise-contract.dev/signature-verifier": "gpg"}}]}`
+	_, err = DetermineInput(ctx, Input{Images: invalid})
+	assert.EqualError(t, err, `invalid signature verifier "gpg" of component "registry.io/repository/app@sha256:0123", expected one of: cosign, notation`)
+}
+
 func TestExpandImageIndex(t *testing.T) {
 	client := fake.FakeClient{}
 	expectedRef := name.MustParseReference("registry.io/repository/image:tag")
@@ -275,7 +347,8 @@ func TestExpandImageIndex(t *testing.T) {
 		},
 	}
 
-	expandImageIndex(ctx, snap)
+	platforms, err := expandImageIndex(ctx, snap, nil)
+	require.NoError(t, err)
 	assert.True(t, len(snap.Components) == 3, "Image Index itself should be removed and be replaced by individual image manifests")
 
 	amd64Image, arm64Image, noarchImage := false, false, false
@@ -293,6 +366,109 @@ func TestExpandImageIndex(t *testing.T) {
 	assert.True(t, amd64Image, "An amd64 image should be present in the component")
 	assert.True(t, arm64Image, "An arm64 image should be present in the component")
 	assert.True(t, noarchImage, "A noarch image should be present in the component")
+
+	component := app.SnapshotComponent{Name: "some-image-name", ContainerImage: "registry.io/repository/image:tag"}
+	assert.Equal(t, map[string]ImagePlatform{
+		"registry.io/repository/image@sha256:digest1": {Index: component, Platform: "amd64"},
+		"registry.io/repository/image@sha256:digest2": {Index: component, Platform: "arm64"},
+		"registry.io/repository/image@sha256:digest3": {Index: component},
+	}, platforms)
+}
+
+func TestExpandImageIndexPlatforms(t *testing.T) {
+	ref := name.MustParseReference("registry.io/repository/image:tag")
+
+	index := gcrfake.FakeImageIndex{}
+	index.IndexManifestReturns(&v1.IndexManifest{
+		Manifests: []v1.Descriptor{
+			{
+				MediaType: types.OCIManifestSchema1,
+				Platform:  &v1.Platform{OS: "linux", Architecture: "amd64"},
+				Digest:    v1.Hash{Algorithm: "sha256", Hex: "amd64"},
+			},
+			{
+				MediaType: types.OCIManifestSchema1,
+				Platform:  &v1.Platform{OS: "linux", Architecture: "arm64", Variant: "v8"},
+				Digest:    v1.Hash{Algorithm: "sha256", Hex: "arm64"},
+			},
+			{
+				MediaType: types.OCIManifestSchema1,
+				Platform:  &v1.Platform{OS: "linux", Architecture: "s390x"},
+				Digest:    v1.Hash{Algorithm: "sha256", Hex: "s390x"},
+			},
+			{
+				MediaType: types.OCIManifestSchema1,
+				Digest:    v1.Hash{Algorithm: "sha256", Hex: "noarch"},
+			},
+		},
+	}, nil)
+
+	cases := []struct {
+		name      string
+		platforms []string
+		expected  map[string]string
+		err       string
+	}{
+		{
+			name:      "os and architecture",
+			platforms: []string{"linux/amd64"},
+			expected:  map[string]string{"registry.io/repository/image@sha256:amd64": "linux/amd64"},
+		},
+		{
+			name:      "any variant",
+			platforms: []string{"linux/arm64", "linux/s390x"},
+			expected: map[string]string{
+				"registry.io/repository/image@sha256:arm64": "linux/arm64/v8",
+				"registry.io/repository/image@sha256:s390x": "linux/s390x",
+			},
+		},
+		{
+			name:      "only os",
+			platforms: []string{"linux"},
+			expected: map[string]string{
+				"registry.io/repository/image@sha256:amd64": "linux/amd64",
+				"registry.io/repository/image@sha256:arm64": "linux/arm64/v8",
+				"registry.io/repository/image@sha256:s390x": "linux/s390x",
+			},
+		},
+		{
+			name:      "no match",
+			platforms: []string{"windows/amd64"},
+			err:       "none of the images of the image index registry.io/repository/image:tag is for the platforms windows/amd64",
+		},
+		{
+			name:      "invalid",
+			platforms: []string{"/amd64"},
+			err:       `invalid platform "/amd64", expected <os>[/<arch>[/<variant>]]`,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			client := fake.FakeClient{}
+			client.On("Head", ref).Return(&v1.Descriptor{MediaType: types.OCIImageIndex}, nil)
+			client.On("Index", ref).Return(&index, nil)
+			ctx := oci.WithClient(context.Background(), &client)
+
+			snap := &app.SnapshotSpec{
+				Components: []app.SnapshotComponent{{Name: "multi", ContainerImage: ref.String()}},
+			}
+
+			platforms, err := expandImageIndex(ctx, snap, c.platforms)
+			if c.err != "" {
+				assert.EqualError(t, err, c.err)
+				return
+			}
+			require.NoError(t, err)
+
+			images := map[string]string{}
+			for image, p := range platforms {
+				images[image] = p.Platform
+			}
+			assert.Equal(t, c.expected, images)
+			assert.Len(t, snap.Components, len(c.expected))
+		})
+	}
 }
 
 func TestExpandImageImage_Errors(t *testing.T) {
@@ -360,7 +536,8 @@ func TestExpandImageImage_Errors(t *testing.T) {
 					},
 				},
 			}
-			expandImageIndex(ctx, snapshot)
+			_, err := expandImageIndex(ctx, snapshot, nil)
+			require.NoError(t, err)
 
 			found := false
 			for _, entry := range hook.AllEntries() {
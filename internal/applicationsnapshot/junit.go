@@ -90,6 +90,16 @@ func (r *Report) toJUnit() junit.Testsuites {
 				Value: s.Signature,
 			})
 
+			if id := s.CertificateIdentity; id != nil {
+				properties = append(properties, junit.Property{
+					Name:  "identity.subject",
+					Value: id.Subject,
+				}, junit.Property{
+					Name:  "identity.issuer",
+					Value: id.Issuer,
+				})
+			}
+
 			metaProps := make([]junit.Property, 0, len(s.Metadata))
 			for k, v := range s.Metadata {
 				metaProps = append(metaProps, junit.Property{
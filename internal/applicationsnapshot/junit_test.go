@@ -119,6 +119,10 @@ func TestToJunit(t *testing.T) {
 									"A": "B",
 									"B": "C",
 								},
+								CertificateIdentity: &signature.CertificateIdentity{
+									Subject: "subject",
+									Issuer:  "issuer",
+								},
 							},
 						},
 						Violations: []evaluator.Result{
@@ -191,6 +195,14 @@ func TestToJunit(t *testing.T) {
 								Name:  "signature",
 								Value: "signature2",
 							},
+							{
+								Name:  "identity.subject",
+								Value: "subject",
+							},
+							{
+								Name:  "identity.issuer",
+								Value: "issuer",
+							},
 							{
 								Name:  "metadata.A",
 								Value: "B",
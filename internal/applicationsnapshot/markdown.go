@@ -0,0 +1,100 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package applicationsnapshot
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// maxMarkdownViolations is the number of violations listed by the markdown
+// report, keeping it short enough for a pull request comment.
+const maxMarkdownViolations = 10
+
+var markdownEscaper = strings.NewReplacer("|", `\|`, "\r", "", "\n", " ", "`", "'")
+
+// renderMarkdown renders the report as GitHub-flavored markdown for posting
+// as a pull request comment: the overall result, a table of the components with
+// their status and counts of results, and the first violations.
+func (r *Report) renderMarkdown() ([]byte, error) {
+	var buf bytes.Buffer
+
+	if r.Success {
+		buf.WriteString("### :white_check_mark: Enterprise Contract passed\n\n")
+	} else {
+		buf.WriteString("### :x: Enterprise Contract failed\n\n")
+	}
+
+	buf.WriteString("| Component | Image | Status | Violations | Warnings | Successes |\n")
+	buf.WriteString("|-----------|-------|--------|------------|----------|-----------|\n")
+
+	type violation struct {
+		component string
+		code      string
+		message   string
+	}
+	var violations []violation
+	total := 0
+	for _, c := range r.Components {
+		status := ":white_check_mark: Passed"
+		switch {
+		case c.EvaluationError != "":
+			status = ":x: Error"
+		case !c.Success:
+			status = ":x: Failed"
+		case len(c.Warnings) > 0:
+			status = ":warning: Passed"
+		}
+
+		component := c.Name
+		if component == "" {
+			component = unnamed
+		}
+		component = markdownEscaper.Replace(component)
+
+		fmt.Fprintf(&buf, "| %s | `%s` | %s | %d | %d | %d |\n", component, markdownEscaper.Replace(c.ContainerImage),
+			status, len(c.Violations), len(c.Warnings), c.SuccessCount)
+
+		total += len(c.Violations)
+		for _, v := range c.Violations {
+			if len(violations) == maxMarkdownViolations {
+				break
+			}
+			code, _ := v.Metadata["code"].(string)
+			if code != "" {
+				code = "`" + markdownEscaper.Replace(code) + "`"
+			}
+			message, _, _ := strings.Cut(v.Message, "\n")
+			violations = append(violations, violation{component, code, markdownEscaper.Replace(message)})
+		}
+	}
+
+	if len(violations) > 0 {
+		buf.WriteString("\n#### Violations\n\n")
+		buf.WriteString("| Component | Rule | Message |\n")
+		buf.WriteString("|-----------|------|---------|\n")
+		for _, v := range violations {
+			fmt.Fprintf(&buf, "| %s | %s | %s |\n", v.component, v.code, v.message)
+		}
+		if more := total - len(violations); more > 0 {
+			fmt.Fprintf(&buf, "\n_and %d more violation(s), see the full report_\n", more)
+		}
+	}
+
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,102 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package applicationsnapshot
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/gkampitakis/go-snaps/snaps"
+	app "github.com/konflux-ci/application-api/api/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/enterprise-contract/ec-cli/internal/evaluator"
+)
+
+func Test_MarkdownReport(t *testing.T) {
+	var violations []evaluator.Result
+	for i := 0; i < 12; i++ {
+		violations = append(violations, evaluator.Result{
+			Message:  fmt.Sprintf("violation %d | with a pipe\nand a second line", i),
+			Metadata: map[string]any{"code": fmt.Sprintf("policy.rule_%d", i)},
+		})
+	}
+
+	report := Report{
+		Components: []Component{
+			{
+				SnapshotComponent: app.SnapshotComponent{
+					Name:           "spam",
+					ContainerImage: "registry.io/repository/spam:latest",
+				},
+				Success:      true,
+				SuccessCount: 3,
+			},
+			{
+				SnapshotComponent: app.SnapshotComponent{
+					Name:           "bacon",
+					ContainerImage: "registry.io/repository/bacon:latest",
+				},
+				Success:      true,
+				SuccessCount: 2,
+				Warnings:     []evaluator.Result{{Message: "warning"}},
+			},
+			{
+				SnapshotComponent: app.SnapshotComponent{
+					Name:           "eggs",
+					ContainerImage: "registry.io/repository/eggs:latest",
+				},
+				Violations: violations,
+			},
+			{
+				SnapshotComponent: app.SnapshotComponent{
+					ContainerImage: "registry.io/repository/ham:latest",
+				},
+				EvaluationError: "runtime error",
+				Violations:      []evaluator.Result{{Message: "no code"}},
+			},
+		},
+	}
+
+	data, err := report.toFormat(Markdown)
+	require.NoError(t, err)
+	snaps.MatchSnapshot(t, string(data))
+
+	report = Report{
+		Success: true,
+		Components: []Component{
+			{
+				SnapshotComponent: app.SnapshotComponent{
+					Name:           "spam",
+					ContainerImage: "registry.io/repository/spam:latest",
+				},
+				Success:      true,
+				SuccessCount: 3,
+			},
+		},
+	}
+
+	data, err = report.toFormat(Markdown)
+	require.NoError(t, err)
+	assert.Equal(t, "### :white_check_mark: Enterprise Contract passed\n\n"+
+		"| Component | Image | Status | Violations | Warnings | Successes |\n"+
+		"|-----------|-------|--------|------------|----------|-----------|\n"+
+		"| spam | `registry.io/repository/spam:latest` | :white_check_mark: Passed | 0 | 0 | 3 |\n", string(data))
+}
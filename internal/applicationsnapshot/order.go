@@ -0,0 +1,52 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package applicationsnapshot
+
+import (
+	"cmp"
+	"slices"
+
+	app "github.com/konflux-ci/application-api/api/v1alpha1"
+)
+
+// OrderComponents returns the components in the order they are dispatched for
+// evaluation. The components given in order, by name or container image, come
+// first in that order, followed by the rest by descending priority, as given
+// by priorities keyed by container image. Components of the same priority keep
+// their order in the snapshot. The order of the dispatch does not change the
+// order of the components in the report.
+func OrderComponents(components []app.SnapshotComponent, priorities map[string]int, order []string) []app.SnapshotComponent {
+	position := func(c app.SnapshotComponent) int {
+		if i := slices.Index(order, c.Name); i != -1 {
+			return i
+		}
+		if i := slices.Index(order, c.ContainerImage); i != -1 {
+			return i
+		}
+		return len(order)
+	}
+
+	ordered := slices.Clone(components)
+	slices.SortStableFunc(ordered, func(a, b app.SnapshotComponent) int {
+		if c := cmp.Compare(position(a), position(b)); c != 0 {
+			return c
+		}
+		return cmp.Compare(priorities[b.ContainerImage], priorities[a.ContainerImage])
+	})
+
+	return ordered
+}
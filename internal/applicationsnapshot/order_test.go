@@ -0,0 +1,85 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package applicationsnapshot
+
+import (
+	"testing"
+
+	app "github.com/konflux-ci/application-api/api/v1alpha1"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOrderComponents(t *testing.T) {
+	components := []app.SnapshotComponent{
+		{Name: "spam", ContainerImage: "registry.io/spam:latest"},
+		{Name: "eggs", ContainerImage: "registry.io/eggs:latest"},
+		{Name: "ham", ContainerImage: "registry.io/ham:latest"},
+		{Name: "bacon", ContainerImage: "registry.io/bacon:latest"},
+	}
+
+	names := func(components []app.SnapshotComponent) []string {
+		n := make([]string, 0, len(components))
+		for _, c := range components {
+			n = append(n, c.Name)
+		}
+		return n
+	}
+
+	cases := []struct {
+		name       string
+		priorities map[string]int
+		order      []string
+		expected   []string
+	}{
+		{
+			name:     "snapshot order",
+			expected: []string{"spam", "eggs", "ham", "bacon"},
+		},
+		{
+			name: "by priority",
+			priorities: map[string]int{
+				"registry.io/ham:latest":   10,
+				"registry.io/bacon:latest": 1,
+				"registry.io/spam:latest":  -1,
+			},
+			expected: []string{"ham", "bacon", "eggs", "spam"},
+		},
+		{
+			name:     "by order",
+			order:    []string{"bacon", "registry.io/eggs:latest", "unknown"},
+			expected: []string{"bacon", "eggs", "spam", "ham"},
+		},
+		{
+			name: "order before priority",
+			priorities: map[string]int{
+				"registry.io/ham:latest": 10,
+			},
+			order:    []string{"bacon"},
+			expected: []string{"bacon", "ham", "spam", "eggs"},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			ordered := OrderComponents(components, c.priorities, c.order)
+			assert.Equal(t, c.expected, names(ordered))
+			assert.Equal(t, []string{"spam", "eggs", "ham", "bacon"}, names(components))
+		})
+	}
+}
@@ -0,0 +1,129 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package applicationsnapshot
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/enterprise-contract/ec-cli/internal/evaluator"
+)
+
+// NestPlatforms replaces the components of the images of the platforms of an
+// image index, see Snapshot.Platforms, with a component for the image index
+// holding their results in its Platforms. The results of the platforms are
+// also included in the results of the image index component, with the
+// platform in their metadata, so that the image index component fails if any
+// of its platforms fails. The image index component takes the place of the
+// first of its platforms, the order of the components is kept otherwise.
+func NestPlatforms(components []Component, platforms map[string]ImagePlatform) []Component {
+	if len(platforms) == 0 {
+		return components
+	}
+
+	nested := make([]Component, 0, len(components))
+	indexes := map[string]int{}
+	for _, c := range components {
+		p, ok := platforms[c.ContainerImage]
+		if !ok {
+			nested = append(nested, c)
+			continue
+		}
+
+		i, ok := indexes[p.Index.ContainerImage]
+		if !ok {
+			i = len(nested)
+			indexes[p.Index.ContainerImage] = i
+			nested = append(nested, Component{
+				SnapshotComponent: p.Index,
+				Success:           true,
+				NoApplicableRules: true,
+				Application:       c.Application,
+				PolicyOverride:    c.PolicyOverride,
+			})
+		}
+
+		c.Platform = p.Platform
+		nested[i].addPlatform(c)
+	}
+
+	return nested
+}
+
+// addPlatform adds the results of the image of a platform to the results of
+// the image index component.
+func (c *Component) addPlatform(platform Component) {
+	c.Platforms = append(c.Platforms, platform)
+
+	c.Success = c.Success && platform.Success
+	c.SuccessCount += platform.SuccessCount
+	c.NoApplicableRules = c.NoApplicableRules && platform.NoApplicableRules
+	c.TimedOut = c.TimedOut || platform.TimedOut
+	c.Violations = append(c.Violations, withPlatform(platform.Violations, platform.Platform)...)
+	c.Warnings = append(c.Warnings, withPlatform(platform.Warnings, platform.Platform)...)
+	c.Successes = append(c.Successes, withPlatform(platform.Successes, platform.Platform)...)
+	c.Waived = append(c.Waived, withPlatform(platform.Waived, platform.Platform)...)
+	c.Infos = append(c.Infos, withPlatform(platform.Infos, platform.Platform)...)
+
+	if platform.EvaluationError != "" {
+		msg := fmt.Sprintf("%s: %s", platformOrImage(platform), platform.EvaluationError)
+		if c.EvaluationError != "" {
+			msg = c.EvaluationError + "\n" + msg
+		}
+		c.EvaluationError = msg
+	}
+}
+
+// withPlatform returns copies of the results with the platform set in their
+// metadata.
+func withPlatform(results []evaluator.Result, platform string) []evaluator.Result {
+	if len(results) == 0 || platform == "" {
+		return results
+	}
+
+	out := make([]evaluator.Result, 0, len(results))
+	for _, r := range results {
+		r.Metadata = maps.Clone(r.Metadata)
+		if r.Metadata == nil {
+			r.Metadata = map[string]any{}
+		}
+		r.Metadata["platform"] = platform
+		out = append(out, r)
+	}
+
+	return out
+}
+
+// platformOrImage returns the platform of the component, or its image for
+// images without a platform.
+func platformOrImage(c Component) string {
+	if c.Platform != "" {
+		return c.Platform
+	}
+	return c.ContainerImage
+}
+
+// withPlatforms returns the components followed by the components of the
+// images of their platforms.
+func withPlatforms(components []Component) []Component {
+	all := slices.Clone(components)
+	for _, c := range components {
+		all = append(all, c.Platforms...)
+	}
+	return all
+}
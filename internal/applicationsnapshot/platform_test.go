@@ -0,0 +1,106 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package applicationsnapshot
+
+import (
+	"testing"
+
+	app "github.com/konflux-ci/application-api/api/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/enterprise-contract/ec-cli/internal/evaluator"
+)
+
+func TestNestPlatforms(t *testing.T) {
+	index := app.SnapshotComponent{Name: "multi", ContainerImage: "registry.io/multi:tag"}
+
+	violation := evaluator.Result{Message: "violated", Metadata: map[string]any{"code": "main.rule"}}
+	components := []Component{
+		{
+			SnapshotComponent: app.SnapshotComponent{Name: "single", ContainerImage: "registry.io/single:tag"},
+			Success:           true,
+		},
+		{
+			SnapshotComponent: app.SnapshotComponent{Name: "multi-sha256:amd64-amd64", ContainerImage: "registry.io/multi@sha256:amd64"},
+			Success:           true,
+			SuccessCount:      2,
+			Application:       "app",
+		},
+		{
+			SnapshotComponent: app.SnapshotComponent{Name: "multi-sha256:arm64-arm64", ContainerImage: "registry.io/multi@sha256:arm64"},
+			Violations:        []evaluator.Result{violation},
+			SuccessCount:      1,
+			EvaluationError:   "rego runtime error",
+			Application:       "app",
+		},
+	}
+
+	nested := NestPlatforms(components, map[string]ImagePlatform{
+		"registry.io/multi@sha256:amd64": {Index: index, Platform: "linux/amd64"},
+		"registry.io/multi@sha256:arm64": {Index: index, Platform: "linux/arm64"},
+	})
+
+	require.Len(t, nested, 2)
+	assert.Equal(t, components[0], nested[0])
+
+	multi := nested[1]
+	assert.Equal(t, index, multi.SnapshotComponent)
+	assert.False(t, multi.Success)
+	assert.Equal(t, 3, multi.SuccessCount)
+	assert.Equal(t, "app", multi.Application)
+	assert.Equal(t, "linux/arm64: rego runtime error", multi.EvaluationError)
+	assert.Equal(t, []evaluator.Result{
+		{Message: "violated", Metadata: map[string]any{"code": "main.rule", "platform": "linux/arm64"}},
+	}, multi.Violations)
+
+	require.Len(t, multi.Platforms, 2)
+	assert.Equal(t, "linux/amd64", multi.Platforms[0].Platform)
+	assert.True(t, multi.Platforms[0].Success)
+	assert.Equal(t, "linux/arm64", multi.Platforms[1].Platform)
+	assert.Equal(t, []evaluator.Result{violation}, multi.Platforms[1].Violations)
+
+	// the results of the platforms are left intact
+	assert.Equal(t, map[string]any{"code": "main.rule"}, violation.Metadata)
+}
+
+func TestNestPlatformsWithoutPlatforms(t *testing.T) {
+	components := []Component{{SnapshotComponent: app.SnapshotComponent{Name: "single", ContainerImage: "registry.io/single:tag"}}}
+
+	assert.Equal(t, components, NestPlatforms(components, nil))
+}
+
+func TestRedactPlatforms(t *testing.T) {
+	report := Report{
+		Components: []Component{
+			{
+				SnapshotComponent: app.SnapshotComponent{ContainerImage: "registry.io/multi:tag"},
+				Platforms: []Component{
+					{SnapshotComponent: app.SnapshotComponent{ContainerImage: "registry.io/multi@sha256:amd64"}, Platform: "linux/amd64"},
+				},
+			},
+		},
+	}
+
+	redacted, err := report.withRedactions([]string{RedactRegistry})
+	require.NoError(t, err)
+	assert.Equal(t, "REDACTED/multi:tag", redacted.Components[0].ContainerImage)
+	assert.Equal(t, "REDACTED/multi@sha256:amd64", redacted.Components[0].Platforms[0].ContainerImage)
+	assert.Equal(t, "registry.io/multi@sha256:amd64", report.Components[0].Platforms[0].ContainerImage)
+}
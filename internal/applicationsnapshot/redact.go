@@ -0,0 +1,292 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package applicationsnapshot
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	ecc "github.com/enterprise-contract/enterprise-contract-controller/api/v1alpha1"
+
+	"github.com/enterprise-contract/ec-cli/internal/attestation"
+	"github.com/enterprise-contract/ec-cli/internal/evaluator"
+	"github.com/enterprise-contract/ec-cli/internal/signature"
+)
+
+// Fields of the report that can be redacted.
+const (
+	// RedactRegistry masks the registry host of component images, including
+	// within result messages.
+	RedactRegistry = "registry"
+	// RedactSigner masks the identities of the signers of images and
+	// attestations.
+	RedactSigner = "signer"
+	// RedactSource masks the component source, the policy source URLs and any
+	// per-component policy override.
+	RedactSource = "source"
+)
+
+var RedactionFields = []string{RedactRegistry, RedactSigner, RedactSource}
+
+// Formats that output the raw data used in the evaluation, these can not be
+// redacted.
+var unredactableFormats = []string{Data, Attestation, PolicyInput}
+
+// redacted replaces the masked values
+const redacted = "REDACTED"
+
+// withRedactions returns a copy of the report with the given fields masked and
+// listed in the Redacted field of the report. The report is returned as is
+// when no fields are given.
+func (r *Report) withRedactions(fields []string) (*Report, error) {
+	if len(fields) == 0 {
+		return r, nil
+	}
+
+	for _, f := range fields {
+		if !slices.Contains(RedactionFields, f) {
+			return nil, fmt.Errorf("unknown field %q to redact, expected one of: %s", f, strings.Join(RedactionFields, ", "))
+		}
+	}
+
+	out := *r
+	out.Redacted = slices.Clone(fields)
+	slices.Sort(out.Redacted)
+	out.Redacted = slices.Compact(out.Redacted)
+
+	out.Components = make([]Component, 0, len(r.Components))
+	for _, c := range r.Components {
+		out.Components = append(out.Components, redactComponent(c, fields))
+	}
+
+	if slices.Contains(fields, RedactSource) {
+		out.Policy = redactPolicySources(r.Policy)
+		out.PolicyFallbacks = redactPolicyFallbacks(r.PolicyFallbacks)
+		out.PolicySources = redactResolvedSources(r.PolicySources)
+	}
+
+	return &out, nil
+}
+
+// redactComponent masks the given fields of the component, including of the
+// images of its platforms.
+func redactComponent(c Component, fields []string) Component {
+	if slices.Contains(fields, RedactRegistry) {
+		c = redactRegistry(c)
+	}
+	if slices.Contains(fields, RedactSigner) {
+		c = redactSigner(c)
+	}
+	if slices.Contains(fields, RedactSource) {
+		c = redactComponentSource(c)
+	}
+
+	if c.Platforms != nil {
+		platforms := make([]Component, 0, len(c.Platforms))
+		for _, p := range c.Platforms {
+			platforms = append(platforms, redactComponent(p, fields))
+		}
+		c.Platforms = platforms
+	}
+
+	return c
+}
+
+// registryHost returns the registry host of the image reference, if the
+// reference includes one.
+func registryHost(ref string) string {
+	host, _, found := strings.Cut(ref, "/")
+	if !found {
+		return ""
+	}
+	if strings.ContainsAny(host, ".:") || host == "localhost" {
+		return host
+	}
+	return ""
+}
+
+func redactRegistry(c Component) Component {
+	host := registryHost(c.ContainerImage)
+	if host == "" {
+		return c
+	}
+
+	replace := func(s string) string {
+		return strings.ReplaceAll(s, host+"/", redacted+"/")
+	}
+
+	c.ContainerImage = replace(c.ContainerImage)
+	c.Violations = redactResults(c.Violations, replace)
+	c.Warnings = redactResults(c.Warnings, replace)
+	c.Successes = redactResults(c.Successes, replace)
+
+	return c
+}
+
+func redactResults(results []evaluator.Result, replace func(string) string) []evaluator.Result {
+	if results == nil {
+		return nil
+	}
+
+	out := make([]evaluator.Result, 0, len(results))
+	for _, r := range results {
+		r.Message = replace(r.Message)
+		out = append(out, r)
+	}
+	return out
+}
+
+func redactSignatures(signatures []signature.EntitySignature) []signature.EntitySignature {
+	if signatures == nil {
+		return nil
+	}
+
+	out := make([]signature.EntitySignature, 0, len(signatures))
+	for _, s := range signatures {
+		if s.Certificate != "" {
+			s.Certificate = redacted
+		}
+		if len(s.Chain) > 0 {
+			s.Chain = []string{redacted}
+		}
+		if len(s.Metadata) > 0 {
+			metadata := make(map[string]string, len(s.Metadata))
+			for k := range s.Metadata {
+				metadata[k] = redacted
+			}
+			s.Metadata = metadata
+		}
+		if s.CertificateIdentity != nil {
+			s.CertificateIdentity = &signature.CertificateIdentity{Subject: redacted, Issuer: redacted}
+		}
+		out = append(out, s)
+	}
+	return out
+}
+
+// redactedAttestation is an attestation with the signer identities of its
+// signatures masked.
+type redactedAttestation struct {
+	attestation.Attestation
+	signatures []signature.EntitySignature
+}
+
+func (a redactedAttestation) Signatures() []signature.EntitySignature {
+	return a.signatures
+}
+
+func (a redactedAttestation) MarshalJSON() ([]byte, error) {
+	data, err := json.Marshal(a.Attestation)
+	if err != nil {
+		return nil, err
+	}
+
+	var m map[string]any
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, err
+	}
+	m["signatures"] = a.signatures
+
+	return json.Marshal(m)
+}
+
+func redactSigner(c Component) Component {
+	c.Signatures = redactSignatures(c.Signatures)
+
+	if c.Attestations != nil {
+		attestations := make([]attestation.Attestation, 0, len(c.Attestations))
+		for _, a := range c.Attestations {
+			attestations = append(attestations, redactedAttestation{a, redactSignatures(a.Signatures())})
+		}
+		c.Attestations = attestations
+	}
+
+	return c
+}
+
+func redactComponentSource(c Component) Component {
+	if c.PolicyOverride != "" {
+		c.PolicyOverride = redacted
+	}
+
+	if c.Source.GitSource == nil {
+		return c
+	}
+
+	git := *c.Source.GitSource
+	for _, s := range []*string{&git.URL, &git.DevfileURL, &git.DockerfileURL} {
+		if *s != "" {
+			*s = redacted
+		}
+	}
+	c.Source.GitSource = &git
+
+	return c
+}
+
+func redactPolicySources(spec ecc.EnterpriseContractPolicySpec) ecc.EnterpriseContractPolicySpec {
+	redactAll := func(urls []string) []string {
+		if urls == nil {
+			return nil
+		}
+		out := make([]string, len(urls))
+		for i := range urls {
+			out[i] = redacted
+		}
+		return out
+	}
+
+	sources := make([]ecc.Source, 0, len(spec.Sources))
+	for _, s := range spec.Sources {
+		s.Policy = redactAll(s.Policy)
+		s.Data = redactAll(s.Data)
+		sources = append(sources, s)
+	}
+	spec.Sources = sources
+
+	return spec
+}
+
+func redactPolicyFallbacks(fallbacks []PolicyFallback) []PolicyFallback {
+	if fallbacks == nil {
+		return nil
+	}
+
+	out := make([]PolicyFallback, len(fallbacks))
+	for i := range fallbacks {
+		out[i] = PolicyFallback{Source: redacted, Fallback: redacted}
+	}
+	return out
+}
+
+// redactResolvedSources masks the URLs of the resolved sources, keeping the
+// revisions and the digests which do not reveal where the sources are hosted.
+func redactResolvedSources(sources []PolicySource) []PolicySource {
+	if sources == nil {
+		return nil
+	}
+
+	out := make([]PolicySource, len(sources))
+	for i, s := range sources {
+		s.URL = redacted
+		s.Ref = redacted
+		out[i] = s
+	}
+	return out
+}
@@ -0,0 +1,207 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package applicationsnapshot
+
+import (
+	"encoding/json"
+	"testing"
+
+	ecc "github.com/enterprise-contract/enterprise-contract-controller/api/v1alpha1"
+	app "github.com/konflux-ci/application-api/api/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/enterprise-contract/ec-cli/internal/attestation"
+	"github.com/enterprise-contract/ec-cli/internal/evaluator"
+	"github.com/enterprise-contract/ec-cli/internal/format"
+	"github.com/enterprise-contract/ec-cli/internal/signature"
+)
+
+type signedAttestation struct {
+	mockAttestation
+	signatures []signature.EntitySignature
+}
+
+func (a signedAttestation) Signatures() []signature.EntitySignature {
+	return a.signatures
+}
+
+func (a signedAttestation) MarshalJSON() ([]byte, error) {
+	return json.Marshal(map[string]any{
+		"type":       a.Type(),
+		"signatures": a.signatures,
+	})
+}
+
+func Test_Redact(t *testing.T) {
+	sig := signature.EntitySignature{
+		KeyID:       "key-id",
+		Signature:   "signature",
+		Certificate: "-----BEGIN CERTIFICATE-----",
+		Chain:       []string{"-----BEGIN CERTIFICATE-----"},
+		Metadata: map[string]string{
+			"Subject Alternative Name": "https://github.com/org/repo/.github/workflows/push.yaml@refs/heads/main",
+		},
+		CertificateIdentity: &signature.CertificateIdentity{
+			Subject: "https://github.com/org/repo/.github/workflows/push.yaml@refs/heads/main",
+			Issuer:  "https://token.actions.githubusercontent.com",
+		},
+	}
+
+	report := Report{
+		Components: []Component{
+			{
+				SnapshotComponent: app.SnapshotComponent{
+					ContainerImage: "registry.io/repository/component@sha256:abc",
+					Source: app.ComponentSource{
+						ComponentSourceUnion: app.ComponentSourceUnion{
+							GitSource: &app.GitSource{URL: "https://github.com/org/repo", Revision: "main"},
+						},
+					},
+				},
+				Violations: []evaluator.Result{
+					{Message: "Image registry.io/repository/component@sha256:abc is not allowed"},
+				},
+				Signatures:   []signature.EntitySignature{sig},
+				Attestations: []attestation.Attestation{signedAttestation{signatures: []signature.EntitySignature{sig}}},
+			},
+		},
+		Policy: ecc.EnterpriseContractPolicySpec{
+			Sources: []ecc.Source{
+				{
+					Policy: []string{"oci::registry.io/policy:latest"},
+					Data:   []string{"git::https://github.com/org/data"},
+				},
+			},
+		},
+		PolicySources: []PolicySource{
+			{
+				URL:      "oci::registry.io/policy:latest",
+				Kind:     "policy",
+				Type:     "oci",
+				Revision: "sha256:abc",
+				Ref:      "oci::registry.io/policy:latest@sha256:abc",
+				Digest:   "sha256:def",
+			},
+		},
+	}
+
+	cases := []struct {
+		name     string
+		redact   []string
+		format   string
+		expected []string
+		excluded []string
+		err      string
+	}{
+		{
+			name:     "not redacted",
+			format:   JSON,
+			expected: []string{"registry.io/repository/component", "Subject Alternative Name", "https://github.com/org/repo", "oci::registry.io/policy:latest"},
+			excluded: []string{"REDACTED", `"redacted"`},
+		},
+		{
+			name:     "registry",
+			redact:   []string{RedactRegistry},
+			format:   JSON,
+			expected: []string{`"redacted":["registry"]`, `"containerImage":"REDACTED/repository/component@sha256:abc"`, "Image REDACTED/repository/component@sha256:abc is not allowed", "https://github.com/org/repo"},
+			excluded: []string{"registry.io/repository"},
+		},
+		{
+			name:     "signer",
+			redact:   []string{RedactSigner},
+			format:   JSON,
+			expected: []string{`"redacted":["signer"]`, `"Subject Alternative Name":"REDACTED"`, `"certificate":"REDACTED"`, `"chain":["REDACTED"]`, `"identity":{"subject":"REDACTED","issuer":"REDACTED"}`},
+			excluded: []string{"push.yaml", "BEGIN CERTIFICATE", "token.actions.githubusercontent.com"},
+		},
+		{
+			name:     "source",
+			redact:   []string{RedactSource},
+			format:   YAML,
+			expected: []string{"- source", "url: REDACTED", "- REDACTED", "revision: main", "ref: REDACTED", "digest: sha256:def"},
+			excluded: []string{"url: https://github.com/org/repo", "github.com/org/data", "oci::registry.io/policy"},
+		},
+		{
+			name:     "text marks redaction",
+			redact:   []string{RedactSource, RedactRegistry, RedactSource},
+			format:   Text,
+			expected: []string{"Redacted: registry, source\n", "REDACTED/repository/component"},
+		},
+		{
+			name:   "unknown field",
+			redact: []string{"spam"},
+			format: JSON,
+			err:    `unknown field "spam" to redact, expected one of: registry, signer, source`,
+		},
+		{
+			name:   "raw format",
+			redact: []string{RedactRegistry},
+			format: Data,
+			err:    `the "data" format can not be redacted`,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			r := report
+			r.applyOptions(format.Options{ShowSuccesses: true})
+
+			redacted, err := r.withRedactions(c.redact)
+			var output []byte
+			if err == nil {
+				output, err = redacted.toFormat(c.format)
+			}
+
+			if c.err != "" {
+				assert.EqualError(t, err, c.err)
+				return
+			}
+			require.NoError(t, err)
+
+			for _, e := range c.expected {
+				assert.Contains(t, string(output), e)
+			}
+			for _, e := range c.excluded {
+				assert.NotContains(t, string(output), e)
+			}
+		})
+	}
+
+	// the report itself is left intact
+	assert.Equal(t, "registry.io/repository/component@sha256:abc", report.Components[0].ContainerImage)
+	assert.Equal(t, sig, report.Components[0].Signatures[0])
+	assert.Equal(t, "https://github.com/org/repo", report.Components[0].Source.GitSource.URL)
+	assert.Equal(t, []string{"oci::registry.io/policy:latest"}, report.Policy.Sources[0].Policy)
+}
+
+func Test_RegistryHost(t *testing.T) {
+	cases := map[string]string{
+		"registry.io/repository/image:tag": "registry.io",
+		"localhost:5000/image":             "localhost:5000",
+		"localhost/image":                  "localhost",
+		"repository/image":                 "",
+		"image":                            "",
+	}
+
+	for ref, expected := range cases {
+		t.Run(ref, func(t *testing.T) {
+			assert.Equal(t, expected, registryHost(ref))
+		})
+	}
+}
@@ -0,0 +1,176 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package applicationsnapshot
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/static"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/enterprise-contract/ec-cli/internal/utils/oci"
+	"github.com/enterprise-contract/ec-cli/pkg/sink"
+)
+
+const (
+	// OCI is the target pushing the JSON report to the registry as an artifact
+	// referring to the image given as its location, e.g. oci=registry.io/image:tag
+	OCI = "oci"
+	// ReportArtifactType is the artifact type of the reports pushed to the
+	// registry
+	ReportArtifactType = "application/vnd.enterprisecontract.report.v1+json"
+
+	createdAnnotation = "org.opencontainers.image.created"
+	// createdFormat is RFC 3339 with a fixed precision, so that the creation
+	// times sort chronologically
+	createdFormat = "2006-01-02T15:04:05.000000000Z07:00"
+)
+
+// NewReferrerSink returns a function creating the sinks that push the report to
+// the registry, referring to the image given as the location of the target.
+func NewReferrerSink(ctx context.Context) func(string) sink.ReportSink {
+	return func(image string) sink.ReportSink {
+		return sink.SinkFunc(func(_ context.Context, _ string, data []byte) error {
+			_, err := PushReport(ctx, image, data)
+			return err
+		})
+	}
+}
+
+// PushReport pushes the JSON report to the registry as an artifact with the
+// image as its subject, so that it can be found via the Referrers API. The
+// reference of the pushed artifact is returned.
+func PushReport(ctx context.Context, image string, report []byte) (name.Digest, error) {
+	ref, err := name.ParseReference(image)
+	if err != nil {
+		return name.Digest{}, err
+	}
+
+	client := oci.NewClient(ctx)
+	subject, err := client.Head(ref)
+	if err != nil {
+		return name.Digest{}, fmt.Errorf("fetching the descriptor of the image %s: %w", image, err)
+	}
+
+	img, err := mutate.Append(empty.Image, mutate.Addendum{
+		Layer:       static.NewLayer(report, types.MediaType("application/json")),
+		Annotations: map[string]string{"org.opencontainers.image.title": "report.json"},
+	})
+	if err != nil {
+		return name.Digest{}, err
+	}
+	img = mutate.MediaType(img, types.OCIManifestSchema1)
+	img = mutate.ConfigMediaType(img, ReportArtifactType)
+	img = mutate.Annotations(img, map[string]string{createdAnnotation: time.Now().UTC().Format(createdFormat)}).(v1.Image)
+	img = mutate.Subject(img, *subject).(v1.Image)
+
+	digest, err := img.Digest()
+	if err != nil {
+		return name.Digest{}, err
+	}
+
+	dst := ref.Context().Digest(digest.String())
+	if err := client.WriteImage(dst, img); err != nil {
+		return name.Digest{}, fmt.Errorf("pushing the report to %s: %w", dst, err)
+	}
+	log.Debugf("Pushed the report of %s to %s", image, dst)
+
+	return dst, nil
+}
+
+// FetchReport returns the latest JSON report pushed to the registry for the
+// image, see PushReport.
+func FetchReport(ctx context.Context, image string) ([]byte, error) {
+	ref, err := name.ParseReference(image)
+	if err != nil {
+		return nil, err
+	}
+
+	client := oci.NewClient(ctx)
+	digest, ok := ref.(name.Digest)
+	if !ok {
+		d, err := client.ResolveDigest(ref)
+		if err != nil {
+			return nil, fmt.Errorf("resolving the digest of the image %s: %w", image, err)
+		}
+		digest = ref.Context().Digest(d)
+	}
+
+	index, err := client.Referrers(digest, ReportArtifactType)
+	if err != nil {
+		return nil, fmt.Errorf("fetching the referrers of the image %s: %w", image, err)
+	}
+	manifest, err := index.IndexManifest()
+	if err != nil {
+		return nil, err
+	}
+
+	var latest v1.Image
+	var latestCreated string
+	for _, m := range manifest.Manifests {
+		if m.ArtifactType != ReportArtifactType {
+			continue
+		}
+
+		img, err := client.Image(ref.Context().Digest(m.Digest.String()))
+		if err != nil {
+			return nil, err
+		}
+
+		// Not all registries copy the annotations to the referrers index, so
+		// they are read from the manifest
+		created := m.Annotations[createdAnnotation]
+		if created == "" {
+			manifest, err := img.Manifest()
+			if err != nil {
+				return nil, err
+			}
+			created = manifest.Annotations[createdAnnotation]
+		}
+
+		if latest == nil || created > latestCreated {
+			latest, latestCreated = img, created
+		}
+	}
+	if latest == nil {
+		return nil, fmt.Errorf("no report refers to the image %s", image)
+	}
+
+	layers, err := latest.Layers()
+	if err != nil {
+		return nil, err
+	}
+	if len(layers) != 1 {
+		return nil, fmt.Errorf("unexpected number of layers in the report of the image %s: %d", image, len(layers))
+	}
+
+	rc, err := layers[0].Uncompressed()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+
+	return io.ReadAll(rc)
+}
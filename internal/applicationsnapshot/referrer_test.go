@@ -0,0 +1,73 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package applicationsnapshot
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/enterprise-contract/ec-cli/internal/format"
+)
+
+func TestPushAndFetchReport(t *testing.T) {
+	registry := httptest.NewServer(registry.New(registry.Logger(log.New(io.Discard, "", 0))))
+	t.Cleanup(registry.Close)
+
+	u, err := url.Parse(registry.URL)
+	require.NoError(t, err)
+
+	image := fmt.Sprintf("localhost:%s/repository/image:tag", u.Port())
+	ref, err := name.ParseReference(image)
+	require.NoError(t, err)
+	img, err := random.Image(1024, 1)
+	require.NoError(t, err)
+	require.NoError(t, remote.Push(ref, img))
+
+	ctx := context.Background()
+
+	_, err = FetchReport(ctx, image)
+	assert.ErrorContains(t, err, "no report refers to the image")
+
+	_, err = PushReport(ctx, image, []byte(`{"success":false}`))
+	require.NoError(t, err)
+
+	parser := format.NewTargetParser(JSON, format.Options{}, io.Discard, nil)
+	parser.RegisterFormatSink(OCI, JSON, NewReferrerSink(ctx))
+	target, err := parser.Parse("oci=" + image)
+	require.NoError(t, err)
+	assert.Equal(t, JSON, target.Format)
+	_, err = target.Write([]byte(`{"success":true}`))
+	require.NoError(t, err)
+
+	report, err := FetchReport(ctx, image)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"success":true}`, string(report))
+}
@@ -23,16 +23,21 @@ import (
 	"encoding/xml"
 	"errors"
 	"fmt"
+	"slices"
+	"strings"
+	"sync"
 	"time"
 
 	ecc "github.com/enterprise-contract/enterprise-contract-controller/api/v1alpha1"
 	app "github.com/konflux-ci/application-api/api/v1alpha1"
+	"github.com/spf13/afero"
 	"sigs.k8s.io/yaml"
 
 	"github.com/enterprise-contract/ec-cli/internal/attestation"
 	"github.com/enterprise-contract/ec-cli/internal/evaluator"
 	"github.com/enterprise-contract/ec-cli/internal/format"
 	"github.com/enterprise-contract/ec-cli/internal/policy"
+	"github.com/enterprise-contract/ec-cli/internal/policy/source"
 	"github.com/enterprise-contract/ec-cli/internal/signature"
 	"github.com/enterprise-contract/ec-cli/internal/utils"
 	"github.com/enterprise-contract/ec-cli/internal/version"
@@ -47,6 +52,38 @@ type Component struct {
 	SuccessCount int                         `json:"-"`
 	Signatures   []signature.EntitySignature `json:"signatures,omitempty"`
 	Attestations []attestation.Attestation   `json:"attestations,omitempty"`
+	// Waived are the violations waived by a waiver file, they do not fail the
+	// component, see the waiver package.
+	Waived []evaluator.Result `json:"waived,omitempty"`
+	// Infos are the results reclassified as informational by a severity
+	// override, they do not fail the component.
+	Infos []evaluator.Result `json:"infos,omitempty"`
+	// NoApplicableRules is set when none of the policy rules were applicable
+	// to the component.
+	NoApplicableRules bool `json:"noApplicableRules,omitempty"`
+	// PolicyOverride is the policy configuration the component was validated
+	// against, set when it differs from the policy of the report.
+	PolicyOverride string `json:"policyOverride,omitempty"`
+	// EvaluationError is set when the component could not be evaluated, e.g.
+	// due to invalid input or a runtime error in the policy. It is distinct
+	// from the component violating the policy.
+	EvaluationError string `json:"evaluationError,omitempty"`
+	// TimedOut is set when the evaluation of the component exceeded the per
+	// component timeout, the EvaluationError holds the details.
+	TimedOut bool `json:"timedOut,omitempty"`
+	// Application is the application the component belongs to, set for
+	// snapshots spanning multiple applications, see ApplicationAnnotation.
+	Application string `json:"application,omitempty"`
+	// Duplicates are the container images of the components with the same
+	// identity as the component which were not evaluated, see
+	// DedupeComponents.
+	Duplicates []string `json:"duplicates,omitempty"`
+	// Platform is the platform of the image, set for the images of the
+	// platforms of an image index, see NestPlatforms.
+	Platform string `json:"platform,omitempty"`
+	// Platforms are the results of the images of the platforms, set when the
+	// component is an image index, see NestPlatforms.
+	Platforms []Component `json:"platforms,omitempty"`
 }
 
 type Report struct {
@@ -61,6 +98,96 @@ type Report struct {
 	EffectiveTime time.Time                        `json:"effective-time"`
 	PolicyInput   [][]byte                         `json:"-"`
 	ShowSuccesses bool                             `json:"-"`
+	VerboseRules  bool                             `json:"-"`
+	Redacted      []string                         `json:"redacted,omitempty"`
+	// PolicyFallbacks lists the policy sources that could not be fetched and
+	// the fallback sources used instead
+	PolicyFallbacks []PolicyFallback `json:"policyFallbacks,omitempty"`
+	// PolicySources records what each of the fetched policy and data sources
+	// resolved to, identifying the exact policy content evaluated
+	PolicySources []PolicySource `json:"policy-sources,omitempty"`
+	// Applications holds the results by application, set when the
+	// components belong to applications
+	Applications []ApplicationResult `json:"applications,omitempty"`
+	// VEX summarizes the OpenVEX statements attested for the components, set
+	// when any of the components has OpenVEX attestations
+	VEX *VEXSummary `json:"vex,omitempty"`
+	// IdentityKey is the key by which components are identified, see
+	// Component.Identity
+	IdentityKey string `json:"-"`
+	// FailOnSeverity is the level from which violations fail the validation,
+	// violations of lower levels do not fail the components, see
+	// evaluator.Blocking
+	FailOnSeverity string `json:"failOnSeverity,omitempty"`
+	// Coverage lists the policy rules by their coverage, set when requested
+	Coverage *evaluator.CoverageSummary `json:"coverage,omitempty"`
+	// template holds the user-supplied template for the template format
+	template string
+}
+
+// PolicyFallback records the use of a fallback source in place of a policy
+// source that could not be fetched.
+type PolicyFallback struct {
+	Source   string `json:"source"`
+	Fallback string `json:"fallback"`
+}
+
+// NewPolicyFallbacks returns the used fallbacks, given as a map of the source
+// to the fallback, ordered by the source.
+func NewPolicyFallbacks(used map[string]string) []PolicyFallback {
+	if len(used) == 0 {
+		return nil
+	}
+
+	fallbacks := make([]PolicyFallback, 0, len(used))
+	for src, fallback := range used {
+		fallbacks = append(fallbacks, PolicyFallback{Source: src, Fallback: fallback})
+	}
+	slices.SortFunc(fallbacks, func(a, b PolicyFallback) int {
+		return strings.Compare(a.Source, b.Source)
+	})
+
+	return fallbacks
+}
+
+// PolicySource records the revision and the content digest a policy or data
+// source resolved to when it was fetched.
+type PolicySource struct {
+	// URL is the source URL as fetched
+	URL  string `json:"url"`
+	Kind string `json:"kind"`
+	// Type is git or oci, empty for other types of sources
+	Type string `json:"type,omitempty"`
+	// Revision is the git commit SHA or the OCI image digest
+	Revision string `json:"revision,omitempty"`
+	// Ref is the URL pinned to the revision
+	Ref string `json:"ref"`
+	// Digest is the digest of the fetched content
+	Digest    string    `json:"digest,omitempty"`
+	FetchedAt time.Time `json:"fetchedAt"`
+}
+
+// NewPolicySources returns the resolved sources as recorded when fetching them,
+// see source.ResolvedSources.
+func NewPolicySources(resolved []source.ResolvedSource) []PolicySource {
+	if len(resolved) == 0 {
+		return nil
+	}
+
+	sources := make([]PolicySource, 0, len(resolved))
+	for _, r := range resolved {
+		sources = append(sources, PolicySource{
+			URL:       r.URL,
+			Kind:      r.Kind,
+			Type:      r.Type,
+			Revision:  r.Revision,
+			Ref:       r.Ref,
+			Digest:    r.Digest,
+			FetchedAt: r.FetchedAt,
+		})
+	}
+
+	return sources
 }
 
 type summary struct {
@@ -100,14 +227,21 @@ const (
 	JSON            = "json"
 	YAML            = "yaml"
 	Text            = "text"
+	Compact         = "compact"
 	AppStudio       = "appstudio"
 	Summary         = "summary"
 	SummaryMarkdown = "summary-markdown"
+	Markdown        = "markdown"
+	GitHub          = "github"
 	JUnit           = "junit"
 	Data            = "data"
 	Attestation     = "attestation"
 	PolicyInput     = "policy-input"
 	VSA             = "vsa"
+	Template        = "template"
+	SPDX            = "spdx"
+	Verdict         = "verdict"
+	HTML            = "html"
 	// Deprecated old version of appstudio. Remove some day.
 	HACBS = "hacbs"
 )
@@ -116,14 +250,21 @@ var OutputFormats = []string{
 	JSON,
 	YAML,
 	Text,
+	Compact,
 	AppStudio,
 	Summary,
 	SummaryMarkdown,
+	Markdown,
+	GitHub,
 	JUnit,
 	Data,
 	Attestation,
 	PolicyInput,
 	VSA,
+	Template,
+	SPDX,
+	Verdict,
+	HTML,
 }
 
 // WriteReport returns a new instance of Report representing the state of
@@ -152,6 +293,8 @@ func NewReport(snapshot string, components []Component, policy policy.Policy, da
 		Snapshot:      snapshot,
 		Success:       success,
 		Components:    components,
+		Applications:  NewApplicationResults(components),
+		VEX:           NewVEXSummary(components),
 		created:       time.Now().UTC(),
 		Key:           string(key),
 		Policy:        policy.Spec(),
@@ -163,30 +306,91 @@ func NewReport(snapshot string, components []Component, policy policy.Policy, da
 	}, nil
 }
 
-// WriteAll writes the report to all the given targets.
+// LoadReport reads a report, as written by the JSON or the YAML format, from
+// the file.
+func LoadReport(fs afero.Fs, path string) (*Report, error) {
+	data, err := afero.ReadFile(fs, path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read the report: %w", err)
+	}
+
+	var report Report
+	if err := yaml.Unmarshal(data, &report); err != nil {
+		return nil, fmt.Errorf("unable to parse the report %s: %w", path, err)
+	}
+
+	if report.Components == nil {
+		return nil, fmt.Errorf("the file %s is not a validation report, it has no components", path)
+	}
+
+	return &report, nil
+}
+
+// WriteAll writes the report to all the given targets. The targets are
+// rendered concurrently, each from its own copy of the report, and written in
+// the order given so that the output to a shared destination is predictable.
+// A failure to render or write one target does not prevent the others from
+// being written.
 func (r Report) WriteAll(targets []string, p format.TargetParser) (allErrors error) {
 	if len(targets) == 0 {
 		targets = append(targets, Text)
 	}
-	for _, targetName := range targets {
+
+	type rendered struct {
+		target *format.Target
+		data   []byte
+		err    error
+	}
+
+	results := make([]rendered, len(targets))
+
+	var wg sync.WaitGroup
+	for i, targetName := range targets {
 		target, err := p.Parse(targetName)
 		if err != nil {
-			allErrors = errors.Join(allErrors, err)
+			results[i].err = err
 			continue
 		}
-		r.applyOptions(target.Options)
+		results[i].target = target
+
+		wg.Add(1)
+		go func(i int, target *format.Target) {
+			defer wg.Done()
+
+			// The options differ between targets, so each target is rendered
+			// from a copy of the report. The renderers must not modify the
+			// data shared between the copies.
+			report := r
+			report.applyOptions(target.Options)
+			report.template = target.Template
+
+			redacted, err := report.withRedactions(target.Options.Redact)
+			if err != nil {
+				results[i].err = err
+				return
+			}
 
-		data, err := r.toFormat(target.Format)
-		if err != nil {
-			allErrors = errors.Join(allErrors, err)
-			continue
-		}
+			data, err := redacted.toFormat(target.Format)
+			if err != nil {
+				results[i].err = err
+				return
+			}
 
-		if !bytes.HasSuffix(data, []byte{'\n'}) {
-			data = append(data, "\n"...)
+			if !bytes.HasSuffix(data, []byte{'\n'}) {
+				data = append(data, "\n"...)
+			}
+			results[i].data = data
+		}(i, target)
+	}
+	wg.Wait()
+
+	for _, res := range results {
+		if res.err != nil {
+			allErrors = errors.Join(allErrors, res.err)
+			continue
 		}
 
-		if _, err := target.Write(data); err != nil {
+		if _, err := res.target.Write(res.data); err != nil {
 			allErrors = errors.Join(allErrors, err)
 		}
 	}
@@ -195,19 +399,29 @@ func (r Report) WriteAll(targets []string, p format.TargetParser) (allErrors err
 
 // toFormat converts the report into the given format.
 func (r *Report) toFormat(format string) (data []byte, err error) {
+	if len(r.Redacted) > 0 && slices.Contains(unredactableFormats, format) {
+		return nil, fmt.Errorf("the %q format can not be redacted", format)
+	}
+
 	switch format {
 	case JSON:
 		data, err = json.Marshal(r)
 	case YAML:
 		data, err = yaml.Marshal(r)
 	case Text:
-		data, err = generateTextReport(r)
+		data, err = generateTextReport(r.withCollapsedVerboseRules())
+	case Compact:
+		data, err = generateCompactReport(r.withCollapsedVerboseRules())
 	case AppStudio, HACBS:
 		data, err = json.Marshal(r.toAppstudioReport())
 	case Summary:
-		data, err = json.Marshal(r.toSummary())
+		data, err = json.Marshal(r.withCollapsedVerboseRules().toSummary())
 	case SummaryMarkdown:
-		data, err = generateMarkdownSummary(r)
+		data, err = generateMarkdownSummary(r.withCollapsedVerboseRules())
+	case Markdown:
+		data, err = r.withCollapsedVerboseRules().renderMarkdown()
+	case GitHub:
+		data, err = r.withCollapsedVerboseRules().renderGitHub()
 	case JUnit:
 		data, err = xml.Marshal(r.toJUnit())
 	case Data:
@@ -218,12 +432,34 @@ func (r *Report) toFormat(format string) (data []byte, err error) {
 		data = bytes.Join(r.PolicyInput, []byte("\n"))
 	case VSA:
 		data, err = r.toVSA()
+	case Template:
+		data, err = r.renderTemplate()
+	case SPDX:
+		data, err = r.renderSPDX()
+	case Verdict:
+		data = r.toVerdict()
+	case HTML:
+		data, err = r.renderHTML()
 	default:
 		return nil, fmt.Errorf("%q is not a valid report format", format)
 	}
 	return
 }
 
+// Created returns the time the report was created, i.e. the time of the
+// validation.
+func (r *Report) Created() time.Time {
+	return r.created
+}
+
+// toVerdict returns only the overall verdict, PASS or FAIL, of the report.
+func (r *Report) toVerdict() []byte {
+	if r.Success {
+		return []byte("PASS\n")
+	}
+	return []byte("FAIL\n")
+}
+
 func (r *Report) toVSA() ([]byte, error) {
 	vsa, err := NewVSA(*r)
 	if err != nil {
@@ -263,6 +499,55 @@ func (r *Report) toSummary() summary {
 
 func (r *Report) applyOptions(opts format.Options) {
 	r.ShowSuccesses = opts.ShowSuccesses
+	r.VerboseRules = opts.VerboseRules
+}
+
+// withCollapsedVerboseRules returns a copy of the report where the messages of
+// results from rules annotated as verbose are reduced to a one-line summary.
+// The report is returned as is when the verbose rules are to be expanded.
+func (r *Report) withCollapsedVerboseRules() *Report {
+	if r.VerboseRules {
+		return r
+	}
+
+	collapsed := *r
+	collapsed.Components = make([]Component, 0, len(r.Components))
+	for _, c := range r.Components {
+		c.Violations = collapseVerboseResults(c.Violations)
+		c.Warnings = collapseVerboseResults(c.Warnings)
+		c.Successes = collapseVerboseResults(c.Successes)
+		collapsed.Components = append(collapsed.Components, c)
+	}
+
+	return &collapsed
+}
+
+// collapseVerboseResults replaces the multi-line message of each result
+// produced by a verbose rule with its first line and a count of the remaining
+// detail lines.
+func collapseVerboseResults(results []evaluator.Result) []evaluator.Result {
+	if results == nil {
+		return nil
+	}
+
+	collapsed := make([]evaluator.Result, 0, len(results))
+	for _, result := range results {
+		if verbose, ok := result.Metadata["verbose"].(bool); ok && verbose {
+			summary, rest, found := strings.Cut(strings.TrimSpace(result.Message), "\n")
+			if found {
+				details := 0
+				for _, line := range strings.Split(rest, "\n") {
+					if strings.TrimSpace(line) != "" {
+						details++
+					}
+				}
+				result.Message = fmt.Sprintf("%s (%d more details, use --verbose-rules to expand)", strings.TrimSpace(summary), details)
+			}
+		}
+		collapsed = append(collapsed, result)
+	}
+
+	return collapsed
 }
 
 // condensedMsg reduces repetitive error messages.
@@ -336,6 +621,30 @@ func generateTextReport(r *Report) ([]byte, error) {
 	return utils.RenderFromTemplatesWithMain(input, "text_report.tmpl", efs)
 }
 
+// generateCompactReport renders each component on a line with a pass or fail
+// indicator, followed by its results indented beneath. Without color the
+// output is plain text that can be compared with diff.
+func generateCompactReport(r *Report) ([]byte, error) {
+	input := struct {
+		Report *Report
+	}{
+		Report: r,
+	}
+
+	return utils.RenderFromTemplatesWithMain(input, "compact_report.tmpl", efs)
+}
+
+// renderTemplate renders the report using the user-supplied template. The
+// report is the data of the template, see the report templates documentation
+// for the fields available.
+func (r *Report) renderTemplate() ([]byte, error) {
+	if r.template == "" {
+		return nil, errors.New("the template format requires a template file, e.g. --output template=report.tmpl")
+	}
+
+	return utils.RenderFromText(r.withCollapsedVerboseRules(), "report", r.template)
+}
+
 func writeMarkdownField(buffer *bytes.Buffer, name string, value any, icon string) {
 	valueStr := fmt.Sprintf("%v", value)
 	buffer.WriteString(fmt.Sprintf("| %s | %s | %s |\n", name, valueStr, icon))
@@ -20,10 +20,12 @@ package applicationsnapshot
 
 import (
 	"bufio"
+	"bytes"
 	"context"
 	_ "embed"
 	"encoding/json"
 	"fmt"
+	"strings"
 	"testing"
 	"time"
 
@@ -36,6 +38,7 @@ import (
 	"github.com/enterprise-contract/ec-cli/internal/evaluator"
 	"github.com/enterprise-contract/ec-cli/internal/format"
 	"github.com/enterprise-contract/ec-cli/internal/policy"
+	"github.com/enterprise-contract/ec-cli/internal/policy/source"
 	"github.com/enterprise-contract/ec-cli/internal/utils"
 )
 
@@ -830,6 +833,53 @@ func Test_ReportPolicyInput(t *testing.T) {
 	matchesJSONLFile(t, fs, policyInput, "default")
 }
 
+func Test_WriteAllMultipleFormats(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	defaultWriter := &bytes.Buffer{}
+
+	components := []Component{
+		{
+			SnapshotComponent: app.SnapshotComponent{Name: "spam", ContainerImage: "registry.io/spam@sha256:abc"},
+			Violations:        []evaluator.Result{{Message: "violation", Metadata: map[string]interface{}{"code": "spam.violation"}}},
+			Successes:         []evaluator.Result{{Message: "success", Metadata: map[string]interface{}{"code": "spam.success"}}},
+			SuccessCount:      1,
+		},
+	}
+
+	ctx := context.Background()
+	report, err := NewReport("snapshot", components, createTestPolicy(t, ctx), "data", nil, true)
+	require.NoError(t, err)
+
+	p := format.NewTargetParser(JSON, format.Options{}, defaultWriter, fs)
+	err = report.WriteAll([]string{
+		"json=report.json",
+		"yaml=report.yaml?show-successes=false",
+		"summary=summary.json",
+		"junit=report.xml",
+		"text=report.txt?show-successes=true",
+		"bogus=bogus.out",
+		"json",
+		"yaml",
+	}, p)
+	assert.EqualError(t, err, `"bogus" is not a valid report format`)
+
+	for _, path := range []string{"report.json", "report.yaml", "summary.json", "report.xml", "report.txt"} {
+		data, err := afero.ReadFile(fs, path)
+		require.NoError(t, err, path)
+		assert.NotEmpty(t, data, path)
+	}
+
+	exists, err := afero.Exists(fs, "bogus.out")
+	require.NoError(t, err)
+	assert.False(t, exists)
+
+	// Output written to the same destination follows the order of the targets.
+	jsonReport, err := afero.ReadFile(fs, "report.json")
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(defaultWriter.String(), string(jsonReport)))
+	assert.Contains(t, defaultWriter.String(), "success: false")
+}
+
 func Test_TextReport(t *testing.T) {
 	warnings := []evaluator.Result{
 		{
@@ -934,6 +984,390 @@ func Test_TextReport(t *testing.T) {
 	}
 }
 
+func Test_VerboseRules(t *testing.T) {
+	verbose := evaluator.Result{
+		Metadata: map[string]interface{}{
+			"code":    "verbose.rule",
+			"verbose": true,
+		},
+		Message: "Found 2 problems:\n- problem one\n- problem two",
+	}
+	regular := evaluator.Result{
+		Metadata: map[string]interface{}{
+			"code": "regular.rule",
+		},
+		Message: "Regular message\nwith a second line",
+	}
+
+	report := Report{
+		Components: []Component{
+			{
+				SnapshotComponent: app.SnapshotComponent{
+					ContainerImage: "registry.io/repository/component:tag",
+				},
+				Violations: []evaluator.Result{verbose, regular},
+			},
+		},
+	}
+
+	cases := []struct {
+		name     string
+		options  format.Options
+		format   string
+		expected []string
+		excluded []string
+	}{
+		{
+			name:     "text collapsed by default",
+			format:   Text,
+			expected: []string{"Found 2 problems: (2 more details, use --verbose-rules to expand)", "Regular message"},
+			excluded: []string{"problem one"},
+		},
+		{
+			name:     "text expanded",
+			options:  format.Options{VerboseRules: true},
+			format:   Text,
+			expected: []string{"Found 2 problems:", "- problem one", "- problem two"},
+			excluded: []string{"use --verbose-rules to expand"},
+		},
+		{
+			name:     "summary collapsed by default",
+			format:   Summary,
+			expected: []string{"Found 2 problems: (2 more details, use --verbose-rules to expand)"},
+			excluded: []string{"problem one"},
+		},
+		{
+			name:     "summary expanded",
+			options:  format.Options{VerboseRules: true},
+			format:   Summary,
+			expected: []string{`Found 2 problems:\n- problem one\n- problem two`},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			r := report
+			r.applyOptions(c.options)
+			output, err := r.toFormat(c.format)
+			require.NoError(t, err)
+
+			for _, e := range c.expected {
+				assert.Contains(t, string(output), e)
+			}
+			for _, e := range c.excluded {
+				assert.NotContains(t, string(output), e)
+			}
+		})
+	}
+
+	// the report itself is left intact
+	assert.Equal(t, verbose.Message, report.Components[0].Violations[0].Message)
+}
+
+func Test_TextReportNoApplicableRules(t *testing.T) {
+	single := Report{
+		Components: []Component{
+			{
+				SnapshotComponent: app.SnapshotComponent{
+					Name:           "single",
+					ContainerImage: "registry.io/repository/component-1:tag",
+				},
+				NoApplicableRules: true,
+			},
+		},
+	}
+
+	output, err := generateTextReport(&single)
+	require.NoError(t, err)
+	assert.Contains(t, string(output), "ImageRef: registry.io/repository/component-1:tag\nNo applicable rules\n")
+
+	multiple := single
+	multiple.Components = append(multiple.Components, Component{
+		SnapshotComponent: app.SnapshotComponent{
+			Name:           "other",
+			ContainerImage: "registry.io/repository/component-2:tag",
+		},
+	})
+
+	output, err = generateTextReport(&multiple)
+	require.NoError(t, err)
+	assert.Contains(t, string(output), "Successes: 0\n  No applicable rules\n")
+	assert.Equal(t, 1, strings.Count(string(output), "No applicable rules"))
+}
+
+func Test_TextReportEvaluationError(t *testing.T) {
+	report := Report{
+		Components: []Component{
+			{
+				SnapshotComponent: app.SnapshotComponent{
+					Name:           "single",
+					ContainerImage: "registry.io/repository/component-1:tag",
+				},
+				EvaluationError: "rego runtime error",
+			},
+		},
+	}
+
+	output, err := generateTextReport(&report)
+	require.NoError(t, err)
+	assert.Contains(t, string(output), "ImageRef: registry.io/repository/component-1:tag\nEvaluation error: rego runtime error\n")
+}
+
+func Test_VerdictReport(t *testing.T) {
+	for _, success := range []bool{true, false} {
+		report := Report{Success: success, Components: []Component{{Success: success}}}
+
+		output, err := report.toFormat(Verdict)
+		require.NoError(t, err)
+		if success {
+			assert.Equal(t, "PASS\n", string(output))
+		} else {
+			assert.Equal(t, "FAIL\n", string(output))
+		}
+	}
+}
+
+func Test_TextReportPolicyFallbacks(t *testing.T) {
+	report := Report{
+		PolicyFallbacks: NewPolicyFallbacks(map[string]string{
+			"oci::registry.io/policy:latest": "oci::mirror.io/policy:latest",
+			"oci::registry.io/data:latest":   "oci::mirror.io/data:latest",
+		}),
+	}
+
+	output, err := generateTextReport(&report)
+	require.NoError(t, err)
+	assert.Contains(t, string(output), `WARNING: Policy source oci::registry.io/data:latest could not be fetched, used the fallback oci::mirror.io/data:latest
+WARNING: Policy source oci::registry.io/policy:latest could not be fetched, used the fallback oci::mirror.io/policy:latest
+`)
+}
+
+func Test_ReportPolicySources(t *testing.T) {
+	fetchedAt := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	report := Report{
+		PolicySources: NewPolicySources([]source.ResolvedSource{
+			{
+				URL:       "git::https://github.com/org/policy//policy?ref=main",
+				Kind:      "policy",
+				Type:      "git",
+				Revision:  "0123456789abcdef0123456789abcdef01234567",
+				Ref:       "git::https://github.com/org/policy//policy?ref=0123456789abcdef0123456789abcdef01234567",
+				Digest:    "sha256:abc",
+				FetchedAt: fetchedAt,
+			},
+			{
+				URL:       "https://example.com/data.json",
+				Kind:      "data",
+				Ref:       "https://example.com/data.json",
+				FetchedAt: fetchedAt,
+			},
+		}),
+	}
+
+	output, err := report.toFormat(JSON)
+	require.NoError(t, err)
+
+	var result struct {
+		PolicySources json.RawMessage `json:"policy-sources"`
+	}
+	require.NoError(t, json.Unmarshal(output, &result))
+	assert.JSONEq(t, `[
+		{
+			"url": "git::https://github.com/org/policy//policy?ref=main",
+			"kind": "policy",
+			"type": "git",
+			"revision": "0123456789abcdef0123456789abcdef01234567",
+			"ref": "git::https://github.com/org/policy//policy?ref=0123456789abcdef0123456789abcdef01234567",
+			"digest": "sha256:abc",
+			"fetchedAt": "2024-01-02T03:04:05Z"
+		},
+		{
+			"url": "https://example.com/data.json",
+			"kind": "data",
+			"ref": "https://example.com/data.json",
+			"fetchedAt": "2024-01-02T03:04:05Z"
+		}
+	]`, string(result.PolicySources))
+
+	assert.Nil(t, NewPolicySources(nil))
+}
+
+func Test_TextReportWaived(t *testing.T) {
+	report := Report{
+		Success: true,
+		Components: []Component{
+			{
+				SnapshotComponent: app.SnapshotComponent{
+					Name:           "single",
+					ContainerImage: "registry.io/repository/component-1:tag",
+				},
+				Success: true,
+				Waived: []evaluator.Result{{
+					Message: "violated",
+					Metadata: map[string]any{
+						"code":   "a.b",
+						"waiver": map[string]any{"expires": "2026-12-31", "justification": "accepted"},
+					},
+				}},
+			},
+		},
+	}
+
+	output, err := generateTextReport(&report)
+	require.NoError(t, err)
+	assert.Contains(t, string(output), `Results:
+* [Waived] a.b
+  ImageRef: registry.io/repository/component-1:tag
+  Reason: violated
+  Justification: accepted
+  Expires: 2026-12-31
+`)
+
+	output, err = report.toFormat(Compact)
+	require.NoError(t, err)
+	assert.Contains(t, string(output), "  * a.b (waived: accepted)\n    violated\n")
+}
+
+func Test_TextReportInfos(t *testing.T) {
+	report := Report{
+		Success:        true,
+		FailOnSeverity: "high",
+		Components: []Component{
+			{
+				SnapshotComponent: app.SnapshotComponent{
+					Name:           "single",
+					ContainerImage: "registry.io/repository/component-1:tag",
+				},
+				Success: true,
+				Infos: []evaluator.Result{{
+					Message:  "noted",
+					Metadata: map[string]any{"code": "a.b", "original_severity": "warning"},
+				}},
+			},
+		},
+	}
+
+	output, err := generateTextReport(&report)
+	require.NoError(t, err)
+	assert.Contains(t, string(output), "Success: true\nFail on severity: high\n")
+	assert.Contains(t, string(output), `Results:
+* [Info] a.b
+  ImageRef: registry.io/repository/component-1:tag
+  Reason: noted
+  Original severity: warning
+`)
+}
+
+func Test_TextReportTrace(t *testing.T) {
+	report := Report{
+		Components: []Component{
+			{
+				SnapshotComponent: app.SnapshotComponent{
+					Name:           "single",
+					ContainerImage: "registry.io/repository/component-1:tag",
+				},
+				Violations: []evaluator.Result{{
+					Message: "Image is not pinned",
+					Metadata: map[string]any{"code": "a.b", "trace": []any{
+						`a.rego:10: Enter deny contains result if {`,
+						`a.rego:12: Fail contains(ref, "@") (ref = "registry.io/repository/component-1:tag")`,
+					}},
+				}},
+			},
+		},
+	}
+
+	output, err := generateTextReport(&report)
+	require.NoError(t, err)
+	assert.Contains(t, string(output), `[Violation] a.b
+  ImageRef: registry.io/repository/component-1:tag
+  Reason: Image is not pinned
+  Trace:
+    a.rego:10: Enter deny contains result if {
+    a.rego:12: Fail contains(ref, "@") (ref = "registry.io/repository/component-1:tag")
+`)
+}
+
+func Test_TextReportCoverage(t *testing.T) {
+	report := Report{
+		Success: true,
+		Coverage: &evaluator.CoverageSummary{
+			Rules:     4,
+			Evaluated: []string{"a.b", "a.c"},
+			Skipped:   []string{"a.d"},
+			Unmatched: []string{"a.e"},
+		},
+	}
+
+	output, err := generateTextReport(&report)
+	require.NoError(t, err)
+	assert.Contains(t, string(output), `Coverage: 2 of 4 rules evaluated, 1 skipped, 1 unmatched
+  Skipped: a.d
+  Unmatched: a.e
+`)
+}
+
+func Test_CompactReport(t *testing.T) {
+	report := Report{
+		Components: []Component{
+			{
+				SnapshotComponent: app.SnapshotComponent{
+					Name:           "failing",
+					ContainerImage: "registry.io/repository/component-1:tag",
+				},
+				Violations: []evaluator.Result{
+					{Metadata: map[string]interface{}{"code": "violation-1"}, Message: "Violation 1 message"},
+				},
+				Warnings: []evaluator.Result{
+					{Metadata: map[string]interface{}{"code": "warning-1"}, Message: "Warning 1 message"},
+				},
+			},
+			{
+				SnapshotComponent: app.SnapshotComponent{
+					Name:           unnamed,
+					ContainerImage: "registry.io/repository/component-2:tag",
+				},
+				Success: true,
+				Successes: []evaluator.Result{
+					{Metadata: map[string]interface{}{"code": "success-1"}, Message: "Pass"},
+				},
+				SuccessCount: 1,
+			},
+			{
+				SnapshotComponent: app.SnapshotComponent{
+					Name:           "broken",
+					ContainerImage: "registry.io/repository/component-3:tag",
+				},
+				EvaluationError: "rego runtime error",
+			},
+		},
+	}
+
+	output, err := generateCompactReport(&report)
+	require.NoError(t, err)
+	assert.Equal(t, `✕ registry.io/repository/component-1:tag (failing)
+  ✕ violation-1
+    Violation 1 message
+  › warning-1
+    Warning 1 message
+✓ registry.io/repository/component-2:tag
+✕ registry.io/repository/component-3:tag (broken)
+  ✕ evaluation error
+    rego runtime error
+`, string(output))
+
+	report.ShowSuccesses = true
+	output, err = generateCompactReport(&report)
+	require.NoError(t, err)
+	assert.Contains(t, string(output), "✓ registry.io/repository/component-2:tag\n  ✓ success-1\n✕")
+
+	utils.ColorEnabled = true
+	t.Cleanup(func() { utils.ColorEnabled = false })
+	output, err = generateCompactReport(&report)
+	require.NoError(t, err)
+	assert.Contains(t, string(output), "\x1b[31m✕\x1b[0m registry.io/repository/component-1:tag (failing)\n")
+}
+
 func matchesJSONLFile(t *testing.T, fs afero.Fs, expected [][]byte, filename string) {
 	f, err := fs.Open(filename)
 	require.NoError(t, err)
@@ -998,3 +1432,74 @@ func createTestPolicy(t *testing.T, ctx context.Context) policy.Policy {
 	assert.NoError(t, err)
 	return p
 }
+
+func Test_TemplateReport(t *testing.T) {
+	report := Report{
+		Success: false,
+		Components: []Component{
+			{
+				SnapshotComponent: app.SnapshotComponent{
+					Name:           "spam",
+					ContainerImage: "registry.io/repository/spam:tag",
+				},
+				Violations: []evaluator.Result{
+					{Message: "violation", Metadata: map[string]interface{}{"code": "policy.rule"}},
+				},
+			},
+		},
+	}
+
+	cases := []struct {
+		name     string
+		template string
+		expected string
+		err      string
+	}{
+		{
+			name:     "custom format",
+			template: `{{ range .Components }}{{ .Name }},{{ .ContainerImage }},{{ len .Violations }}{{ range .Violations }},{{ index .Metadata "code" }}{{ end }}{{ nl }}{{ end }}`,
+			expected: "spam,registry.io/repository/spam:tag,1,policy.rule\n",
+		},
+		{
+			name:     "helper functions",
+			template: `{{ range .Components }}{{ indent 2 .Name }}{{ end }}`,
+			expected: "  spam\n",
+		},
+		{
+			name: "no template",
+			err:  "the template format requires a template file, e.g. --output template=report.tmpl",
+		},
+		{
+			name:     "parse error",
+			template: `{{ .Success `,
+			err:      "parsing the template: template: report:1: unclosed action",
+		},
+		{
+			name:     "execution error",
+			template: `{{ .Unknown }}`,
+			err:      `executing the template: template: report:1:3: executing "report" at <.Unknown>: can't evaluate field Unknown in type *applicationsnapshot.Report`,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			fs := afero.NewMemMapFs()
+			require.NoError(t, afero.WriteFile(fs, "report.tmpl", []byte(c.template), 0400))
+
+			target := "template"
+			if c.template != "" {
+				target += "=report.tmpl"
+			}
+
+			var out bytes.Buffer
+			p := format.NewTargetParser(JSON, format.Options{}, &out, fs)
+			err := report.WriteAll([]string{target}, p)
+			if c.err != "" {
+				assert.EqualError(t, err, c.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, c.expected, out.String())
+		})
+	}
+}
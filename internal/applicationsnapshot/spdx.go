@@ -0,0 +1,158 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package applicationsnapshot
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/package-url/packageurl-go"
+	spdxjson "github.com/spdx/tools-golang/json"
+	"github.com/spdx/tools-golang/spdx/v2/common"
+	spdx "github.com/spdx/tools-golang/spdx/v2/v2_3"
+)
+
+// spdxNamespace is the prefix of the SPDX document namespace, which must be
+// unique for each document.
+const spdxNamespace = "https://enterprisecontract.dev/spdx/"
+
+var invalidSPDXIDChars = regexp.MustCompile(`[^a-zA-Z0-9.-]+`)
+
+// renderSPDX renders the report as an SPDX 2.3 document. Each component is a
+// package with the image digest as its checksum, the verdict and the signers of
+// the component are recorded as annotations of the package.
+func (r *Report) renderSPDX() ([]byte, error) {
+	created := r.created.UTC().Format(time.RFC3339)
+	tool := common.Creator{CreatorType: "Tool", Creator: "ec-" + r.EcVersion}
+
+	docName := r.Snapshot
+	if docName == "" {
+		docName = "ec-report"
+	}
+
+	namespace, err := r.spdxNamespace(docName)
+	if err != nil {
+		return nil, err
+	}
+
+	doc := spdx.Document{
+		SPDXVersion:       spdx.Version,
+		DataLicense:       spdx.DataLicense,
+		SPDXIdentifier:    "DOCUMENT",
+		DocumentName:      docName,
+		DocumentNamespace: namespace,
+		CreationInfo: &spdx.CreationInfo{
+			Creators: []common.Creator{tool},
+			Created:  created,
+		},
+		Packages: make([]*spdx.Package, 0, len(r.Components)),
+	}
+
+	for i, c := range r.Components {
+		id := common.ElementID(fmt.Sprintf("Component-%d-%s", i, invalidSPDXIDChars.ReplaceAllString(c.Name, "-")))
+
+		pkg := spdx.Package{
+			PackageName:             c.Name,
+			PackageSPDXIdentifier:   id,
+			PackageDownloadLocation: "NOASSERTION",
+			PrimaryPackagePurpose:   "CONTAINER",
+			PackageComment:          c.ContainerImage,
+		}
+
+		if digest, err := name.NewDigest(c.ContainerImage); err == nil {
+			algorithm, value, _ := strings.Cut(digest.DigestStr(), ":")
+			pkg.PackageChecksums = []common.Checksum{{
+				Algorithm: common.ChecksumAlgorithm(strings.ToUpper(algorithm)),
+				Value:     value,
+			}}
+
+			purl := packageurl.NewPackageURL(packageurl.TypeOCI, "", imageName(digest), digest.DigestStr(),
+				packageurl.QualifiersFromMap(map[string]string{"repository_url": digest.Context().Name()}), "")
+			pkg.PackageExternalReferences = []*spdx.PackageExternalReference{{
+				Category: common.CategoryPackageManager,
+				RefType:  common.TypePackageManagerPURL,
+				Locator:  purl.ToString(),
+			}}
+		}
+
+		annotate := func(comment string) {
+			pkg.Annotations = append(pkg.Annotations, spdx.Annotation{
+				Annotator:                common.Annotator{AnnotatorType: tool.CreatorType, Annotator: tool.Creator},
+				AnnotationDate:           created,
+				AnnotationType:           "REVIEW",
+				AnnotationSPDXIdentifier: common.MakeDocElementID("", string(id)),
+				AnnotationComment:        comment,
+			})
+		}
+
+		annotate(spdxVerdict(c))
+		for _, s := range c.Signatures {
+			annotate("signer: " + s.Identity())
+		}
+
+		doc.Packages = append(doc.Packages, &pkg)
+		doc.Relationships = append(doc.Relationships, &spdx.Relationship{
+			RefA:         common.MakeDocElementID("", "DOCUMENT"),
+			RefB:         common.MakeDocElementID("", string(id)),
+			Relationship: common.TypeRelationshipDescribe,
+		})
+	}
+
+	var buf bytes.Buffer
+	if err := spdxjson.Write(&doc, &buf); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// spdxNamespace returns a namespace for the SPDX document derived from the
+// content of the report, so that different reports have different namespaces.
+func (r *Report) spdxNamespace(docName string) (string, error) {
+	content, err := json.Marshal(r.Components)
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256(append(content, r.created.UTC().Format(time.RFC3339Nano)...))
+
+	return fmt.Sprintf("%s%s-%x", spdxNamespace, invalidSPDXIDChars.ReplaceAllString(docName, "-"), sum[:8]), nil
+}
+
+// spdxVerdict describes the outcome of the validation of the component.
+func spdxVerdict(c Component) string {
+	verdict := "passed"
+	if !c.Success {
+		verdict = "failed"
+	}
+
+	return fmt.Sprintf("ec verdict: %s, violations: %d, warnings: %d, successes: %d",
+		verdict, len(c.Violations), len(c.Warnings), c.SuccessCount)
+}
+
+// imageName returns the last path element of the image repository, which is
+// used as the name in the package URL.
+func imageName(ref name.Digest) string {
+	repo := ref.Context().RepositoryStr()
+	return repo[strings.LastIndex(repo, "/")+1:]
+}
@@ -0,0 +1,86 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package applicationsnapshot
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/gkampitakis/go-snaps/snaps"
+	app "github.com/konflux-ci/application-api/api/v1alpha1"
+	spdxjson "github.com/spdx/tools-golang/json"
+	"github.com/spdx/tools-golang/spdx/v2/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/enterprise-contract/ec-cli/internal/evaluator"
+	"github.com/enterprise-contract/ec-cli/internal/signature"
+)
+
+func Test_SPDXReport(t *testing.T) {
+	report := Report{
+		Snapshot:  "snappy",
+		created:   time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		EcVersion: "v0.1.2",
+		Components: []Component{
+			{
+				SnapshotComponent: app.SnapshotComponent{
+					Name:           "spam",
+					ContainerImage: "registry.io/repository/spam@sha256:a3c8e5bb4e3d3c1bd1e0e0d7e3b6c8d0f3f8d8a8c8e8b8a8d8c8e8b8a8d8c8e8",
+				},
+				Success:      true,
+				SuccessCount: 3,
+				Signatures: []signature.EntitySignature{
+					{KeyID: "key-1"},
+				},
+			},
+			{
+				SnapshotComponent: app.SnapshotComponent{
+					Name:           "eggs & ham",
+					ContainerImage: "registry.io/repository/eggs:latest",
+				},
+				Violations: []evaluator.Result{{Message: "violation"}},
+			},
+		},
+	}
+
+	data, err := report.toFormat(SPDX)
+	require.NoError(t, err)
+	snaps.MatchJSON(t, data)
+
+	doc, err := spdxjson.Read(bytes.NewReader(data))
+	require.NoError(t, err)
+
+	require.Len(t, doc.Packages, 2)
+	assert.Equal(t, "SPDX-2.3", doc.SPDXVersion)
+
+	spam := doc.Packages[0]
+	assert.Equal(t, common.ElementID("Component-0-spam"), spam.PackageSPDXIdentifier)
+	assert.Equal(t, []common.Checksum{{Algorithm: common.SHA256, Value: "a3c8e5bb4e3d3c1bd1e0e0d7e3b6c8d0f3f8d8a8c8e8b8a8d8c8e8b8a8d8c8e8"}}, spam.PackageChecksums)
+	require.Len(t, spam.Annotations, 2)
+	assert.Equal(t, "ec verdict: passed, violations: 0, warnings: 0, successes: 3", spam.Annotations[0].AnnotationComment)
+	assert.Equal(t, "signer: key:key-1", spam.Annotations[1].AnnotationComment)
+
+	eggs := doc.Packages[1]
+	assert.Equal(t, common.ElementID("Component-1-eggs-ham"), eggs.PackageSPDXIdentifier)
+	assert.Empty(t, eggs.PackageChecksums)
+	require.Len(t, eggs.Annotations, 1)
+	assert.Equal(t, "ec verdict: failed, violations: 1, warnings: 0, successes: 0", eggs.Annotations[0].AnnotationComment)
+}
@@ -0,0 +1,98 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package applicationsnapshot
+
+import (
+	"cmp"
+	"slices"
+
+	"github.com/enterprise-contract/ec-cli/internal/attestation"
+)
+
+// VEXSummary is the rollup of the OpenVEX statements attested for the
+// components.
+type VEXSummary struct {
+	// Statuses counts the statements by their status
+	Statuses map[string]int `json:"statuses"`
+	// NotAffected lists the vulnerabilities that do not affect the
+	// components, with the justification given
+	NotAffected []VEXNotAffected `json:"notAffected,omitempty"`
+}
+
+// VEXNotAffected is a vulnerability stated not to affect some components
+type VEXNotAffected struct {
+	Vulnerability string `json:"vulnerability"`
+	// Justification is the justification, or the impact statement if no
+	// justification was given
+	Justification string   `json:"justification"`
+	Components    []string `json:"components"`
+}
+
+// NewVEXSummary summarizes the OpenVEX statements of the attestations of the
+// components. Nothing is returned if none of the components has OpenVEX
+// attestations.
+func NewVEXSummary(components []Component) *VEXSummary {
+	var summary *VEXSummary
+	notAffected := map[[2]string]*VEXNotAffected{}
+	for _, c := range withPlatforms(components) {
+		for _, a := range c.Attestations {
+			v, ok := a.(attestation.VEX)
+			if !ok {
+				continue
+			}
+
+			if summary == nil {
+				summary = &VEXSummary{Statuses: map[string]int{}}
+			}
+
+			for _, s := range v.Summary().Statements {
+				summary.Statuses[s.Status]++
+				if s.Status != attestation.VEXNotAffected {
+					continue
+				}
+
+				justification := s.Justification
+				if justification == "" {
+					justification = s.ImpactStatement
+				}
+
+				key := [2]string{s.Vulnerability, justification}
+				n, ok := notAffected[key]
+				if !ok {
+					n = &VEXNotAffected{Vulnerability: s.Vulnerability, Justification: justification}
+					notAffected[key] = n
+				}
+				if !slices.Contains(n.Components, c.Name) {
+					n.Components = append(n.Components, c.Name)
+				}
+			}
+		}
+	}
+
+	if summary == nil {
+		return nil
+	}
+
+	for _, n := range notAffected {
+		summary.NotAffected = append(summary.NotAffected, *n)
+	}
+	slices.SortFunc(summary.NotAffected, func(a, b VEXNotAffected) int {
+		return cmp.Or(cmp.Compare(a.Vulnerability, b.Vulnerability), cmp.Compare(a.Justification, b.Justification))
+	})
+
+	return summary
+}
@@ -0,0 +1,97 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package applicationsnapshot
+
+import (
+	"testing"
+
+	"github.com/in-toto/in-toto-golang/in_toto"
+	app "github.com/konflux-ci/application-api/api/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/enterprise-contract/ec-cli/internal/attestation"
+	"github.com/enterprise-contract/ec-cli/internal/signature"
+)
+
+type fakeVEX struct {
+	statements []attestation.VEXStatement
+}
+
+func (fakeVEX) Type() string                            { return in_toto.StatementInTotoV01 }
+func (fakeVEX) PredicateType() string                   { return attestation.PredicateOpenVEX }
+func (fakeVEX) Statement() []byte                       { return nil }
+func (fakeVEX) Signatures() []signature.EntitySignature { return nil }
+func (fakeVEX) Subject() []in_toto.Subject              { return nil }
+
+func (f fakeVEX) Summary() attestation.VEXSummary {
+	return attestation.VEXSummary{Statements: f.statements}
+}
+
+func vexComponents() []Component {
+	return []Component{
+		{
+			SnapshotComponent: app.SnapshotComponent{Name: "spam"},
+			Attestations: []attestation.Attestation{fakeVEX{statements: []attestation.VEXStatement{
+				{Vulnerability: "CVE-2024-0002", Status: attestation.VEXNotAffected, Justification: "vulnerable_code_not_present"},
+				{Vulnerability: "CVE-2024-0001", Status: attestation.VEXNotAffected, ImpactStatement: "The function is never called"},
+				{Vulnerability: "CVE-2024-0003", Status: attestation.VEXFixed},
+			}}},
+		},
+		{
+			SnapshotComponent: app.SnapshotComponent{Name: "eggs"},
+			Attestations: []attestation.Attestation{fakeVEX{statements: []attestation.VEXStatement{
+				{Vulnerability: "CVE-2024-0002", Status: attestation.VEXNotAffected, Justification: "vulnerable_code_not_present"},
+				{Vulnerability: "CVE-2024-0004", Status: attestation.VEXUnderInvestigation},
+			}}},
+		},
+		{
+			SnapshotComponent: app.SnapshotComponent{Name: "bacon"},
+		},
+	}
+}
+
+func TestNewVEXSummary(t *testing.T) {
+	assert.Nil(t, NewVEXSummary(nil))
+	assert.Nil(t, NewVEXSummary([]Component{{Success: true}}))
+
+	assert.Equal(t, &VEXSummary{
+		Statuses: map[string]int{
+			attestation.VEXNotAffected:        3,
+			attestation.VEXFixed:              1,
+			attestation.VEXUnderInvestigation: 1,
+		},
+		NotAffected: []VEXNotAffected{
+			{Vulnerability: "CVE-2024-0001", Justification: "The function is never called", Components: []string{"spam"}},
+			{Vulnerability: "CVE-2024-0002", Justification: "vulnerable_code_not_present", Components: []string{"spam", "eggs"}},
+		},
+	}, NewVEXSummary(vexComponents()))
+}
+
+func TestTextReportVEX(t *testing.T) {
+	components := vexComponents()
+	report := Report{Components: components, VEX: NewVEXSummary(components)}
+
+	output, err := generateTextReport(&report)
+	require.NoError(t, err)
+	assert.Contains(t, string(output), `VEX statements: fixed: 1 not_affected: 3 under_investigation: 1
+  CVE-2024-0001 does not affect spam: The function is never called
+  CVE-2024-0002 does not affect spam, eggs: vulnerable_code_not_present
+`)
+}
@@ -17,13 +17,19 @@
 package attestation
 
 import (
+	"bytes"
 	"encoding/base64"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"sync/atomic"
 
 	"github.com/in-toto/in-toto-golang/in_toto"
 	"github.com/sigstore/cosign/v2/pkg/cosign"
+	"github.com/sigstore/cosign/v2/pkg/cosign/env"
 	"github.com/sigstore/cosign/v2/pkg/oci"
 	"github.com/sigstore/cosign/v2/pkg/types"
 
@@ -41,6 +47,33 @@ type Attestation interface {
 	Subject() []in_toto.Subject
 }
 
+// DefaultMaxSize is the default maximum size of an attestation in bytes, the
+// same as the default maximum size of an attachment in cosign.
+const DefaultMaxSize uint64 = 128 * 1024 * 1024
+
+// ErrAttestationTooLarge is returned for attestations larger than the maximum
+// size.
+var ErrAttestationTooLarge = errors.New("attestation exceeds the maximum size")
+
+var maxSize atomic.Uint64
+
+func init() {
+	maxSize.Store(DefaultMaxSize)
+}
+
+// SetMaxSize sets the maximum size of an attestation in bytes. Larger
+// attestations are rejected before they are read into memory, both when
+// verified by cosign and when parsed.
+func SetMaxSize(size uint64) error {
+	maxSize.Store(size)
+	return os.Setenv(env.VariableMaxAttachmentSize.String(), strconv.FormatUint(size, 10))
+}
+
+// MaxSize returns the maximum size of an attestation in bytes, see SetMaxSize.
+func MaxSize() uint64 {
+	return maxSize.Load()
+}
+
 // Extract the payload from a DSSE signature OCI layer
 func payloadFromSig(sig oci.Signature) (cosign.AttestationPayload, error) {
 	var payload cosign.AttestationPayload
@@ -64,7 +97,19 @@ func payloadFromSig(sig oci.Signature) (cosign.AttestationPayload, error) {
 	}
 	defer reader.Close()
 
-	err = json.NewDecoder(reader).Decode(&payload)
+	// The size of the layer is checked by cosign before it is read, but the
+	// uncompressed data can be larger. Read at most one byte over the maximum
+	// to bound the memory used.
+	limit := maxSize.Load()
+	data, err := io.ReadAll(io.LimitReader(reader, int64(limit)+1))
+	if err != nil {
+		return payload, fmt.Errorf("malformed attestation data: %w", err)
+	}
+	if uint64(len(data)) > limit {
+		return payload, fmt.Errorf("%w of %d bytes, see --max-attestation-size", ErrAttestationTooLarge, limit)
+	}
+
+	err = json.NewDecoder(bytes.NewReader(data)).Decode(&payload)
 	if err != nil {
 		return payload, fmt.Errorf("malformed attestation data: %w", err)
 	}
@@ -21,13 +21,17 @@ package attestation
 import (
 	"crypto/x509"
 	"fmt"
+	"os"
+	"strings"
 	"testing"
 
 	"github.com/gkampitakis/go-snaps/snaps"
 	"github.com/google/go-containerregistry/pkg/v1/types"
+	"github.com/sigstore/cosign/v2/pkg/cosign/bundle"
 	ct "github.com/sigstore/cosign/v2/pkg/types"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 
 	"github.com/enterprise-contract/ec-cli/internal/signature"
 )
@@ -82,6 +86,7 @@ func TestProvenanceFromSignature(t *testing.T) {
 				l.On("Base64Signature").Return("", nil)
 				l.On("Cert").Return(&x509.Certificate{}, nil)
 				l.On("Chain").Return([]*x509.Certificate{}, nil)
+				l.On("Bundle").Return((*bundle.RekorBundle)(nil), nil)
 			},
 			data: payloadJson1,
 		},
@@ -93,6 +98,7 @@ func TestProvenanceFromSignature(t *testing.T) {
 				l.On("Base64Signature").Return("sig-from-cert", nil)
 				l.On("Cert").Return(signature.ParseChainguardReleaseCert(), nil)
 				l.On("Chain").Return(signature.ParseSigstoreChainCert(), nil)
+				l.On("Bundle").Return((*bundle.RekorBundle)(nil), nil)
 			},
 			data: payloadJson1,
 		},
@@ -104,6 +110,7 @@ func TestProvenanceFromSignature(t *testing.T) {
 				l.On("Base64Signature").Return("sig-from-cert", nil)
 				l.On("Cert").Return(signature.ParseChainguardReleaseCert(), nil)
 				l.On("Chain").Return(signature.ParseSigstoreChainCert(), nil)
+				l.On("Bundle").Return((*bundle.RekorBundle)(nil), nil)
 			},
 			data: payloadJson2, // String payload remains as a string
 			// data: payloadJson1, // String payload is marshaled
@@ -125,3 +132,22 @@ func TestProvenanceFromSignature(t *testing.T) {
 		})
 	}
 }
+
+func TestProvenanceFromSignatureTooLarge(t *testing.T) {
+	t.Cleanup(func() {
+		require.NoError(t, SetMaxSize(DefaultMaxSize))
+	})
+
+	att := fmt.Sprintf(`{"signatures": [], "payload": "%s"}`, encode(`{"predicate": {"padding": "`+strings.Repeat("x", 1024)+`"}}`))
+
+	require.NoError(t, SetMaxSize(512))
+	assert.Equal(t, "512", os.Getenv("COSIGN_MAX_ATTACHMENT_SIZE"))
+
+	sig := mockSignature{&mock.Mock{}}
+	sig.On("MediaType").Return(types.MediaType(ct.DssePayloadType), nil)
+	sig.On("Uncompressed").Return(buffy(att), nil)
+
+	_, err := ProvenanceFromSignature(sig)
+	assert.ErrorIs(t, err, ErrAttestationTooLarge)
+	assert.EqualError(t, err, "attestation exceeds the maximum size of 512 bytes, see --max-attestation-size")
+}
@@ -0,0 +1,74 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package attestation
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/sigstore/cosign/v2/pkg/oci"
+)
+
+const (
+	PredicateCycloneDX = "https://cyclonedx.org/bom"
+)
+
+// cycloneDXComponent is the part of a CycloneDX component that is summarized,
+// components can be nested within components
+type cycloneDXComponent struct {
+	Name       string               `json:"name"`
+	Version    string               `json:"version"`
+	PURL       string               `json:"purl"`
+	Components []cycloneDXComponent `json:"components"`
+}
+
+type cycloneDXBOM struct {
+	BOMFormat   string               `json:"bomFormat"`
+	SpecVersion string               `json:"specVersion"`
+	Components  []cycloneDXComponent `json:"components"`
+}
+
+// CycloneDXSBOMFromSignature parses the CycloneDX SBOM from the provided OCI
+// layer. Expects that the layer contains DSSE JSON with the embedded CycloneDX
+// BOM, in JSON format, as the predicate. The BOM is validated against the
+// CycloneDX schema when the syntax of the attestations is validated.
+func CycloneDXSBOMFromSignature(sig oci.Signature) (Attestation, error) {
+	return sbomFromSignature(sig, PredicateCycloneDX, decodeCycloneDX)
+}
+
+func decodeCycloneDX(predicate json.RawMessage) (SBOMSummary, error) {
+	var bom cycloneDXBOM
+	if err := json.Unmarshal(predicate, &bom); err != nil {
+		return SBOMSummary{}, fmt.Errorf("invalid CycloneDX BOM: %w", err)
+	}
+
+	if bom.BOMFormat != "CycloneDX" {
+		return SBOMSummary{}, fmt.Errorf("invalid CycloneDX BOM: unsupported bomFormat %q", bom.BOMFormat)
+	}
+
+	packages := []SBOMPackage{}
+	var collect func([]cycloneDXComponent)
+	collect = func(components []cycloneDXComponent) {
+		for _, c := range components {
+			packages = append(packages, SBOMPackage{Name: c.Name, Version: c.Version, PURL: c.PURL})
+			collect(c.Components)
+		}
+	}
+	collect(bom.Components)
+
+	return SBOMSummary{Format: SBOMFormatCycloneDX, SpecVersion: bom.SpecVersion, Packages: packages}, nil
+}
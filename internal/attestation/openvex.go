@@ -0,0 +1,244 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package attestation
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/in-toto/in-toto-golang/in_toto"
+	"github.com/sigstore/cosign/v2/pkg/oci"
+
+	"github.com/enterprise-contract/ec-cli/internal/signature"
+)
+
+const (
+	// PredicateOpenVEX is the predicate type of OpenVEX attestations created
+	// by cosign
+	PredicateOpenVEX = "https://openvex.dev/ns"
+	// PredicateOpenVEXv020 is the predicate type of OpenVEX attestations
+	// created by vexctl, the context of the OpenVEX document
+	PredicateOpenVEXv020 = "https://openvex.dev/ns/v0.2.0"
+)
+
+// The statuses of a VEX statement
+const (
+	VEXNotAffected        = "not_affected"
+	VEXAffected           = "affected"
+	VEXFixed              = "fixed"
+	VEXUnderInvestigation = "under_investigation"
+)
+
+var vexStatuses = []string{VEXNotAffected, VEXAffected, VEXFixed, VEXUnderInvestigation}
+
+// VEX is an attestation holding Vulnerability Exploitability eXchange
+// statements.
+type VEX interface {
+	Attestation
+	Summary() VEXSummary
+}
+
+// VEXSummary holds the statements of an OpenVEX document, normalized across
+// the versions of OpenVEX.
+type VEXSummary struct {
+	Statements []VEXStatement `json:"statements"`
+}
+
+// VEXStatement is the status of a vulnerability in the products
+type VEXStatement struct {
+	// Vulnerability is the name of the vulnerability, e.g. CVE-2024-1234
+	Vulnerability string `json:"vulnerability"`
+	// Products are the identifiers of the products, e.g. package URLs
+	Products []string `json:"products"`
+	// Status is one of not_affected, affected, fixed or under_investigation
+	Status          string `json:"status"`
+	Justification   string `json:"justification,omitempty"`
+	ImpactStatement string `json:"impact_statement,omitempty"`
+	ActionStatement string `json:"action_statement,omitempty"`
+}
+
+// openVEXIdentifier is the identifier of a vulnerability or a product, either
+// a plain string, as in OpenVEX v0.0.x, or an object with the identifier, as
+// in OpenVEX v0.2.0
+type openVEXIdentifier string
+
+func (i *openVEXIdentifier) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		*i = openVEXIdentifier(s)
+		return nil
+	}
+
+	var o struct {
+		ID   string `json:"@id"`
+		Name string `json:"name"`
+	}
+	if err := json.Unmarshal(data, &o); err != nil {
+		return err
+	}
+
+	// Vulnerabilities are identified by name, products by @id
+	*i = openVEXIdentifier(o.ID)
+	if o.Name != "" {
+		*i = openVEXIdentifier(o.Name)
+	}
+
+	return nil
+}
+
+type openVEXStatement struct {
+	Vulnerability   openVEXIdentifier   `json:"vulnerability"`
+	Products        []openVEXIdentifier `json:"products"`
+	Status          string              `json:"status"`
+	Justification   string              `json:"justification"`
+	ImpactStatement string              `json:"impact_statement"`
+	ActionStatement string              `json:"action_statement"`
+}
+
+type openVEXStatementEnvelope struct {
+	in_toto.StatementHeader
+	Predicate struct {
+		Statements []openVEXStatement `json:"statements"`
+	} `json:"predicate"`
+}
+
+// OpenVEXFromSignature parses the OpenVEX document from the provided OCI
+// layer. Expects that the layer contains DSSE JSON with the embedded OpenVEX
+// document as the predicate. Each of the statements must have a known status,
+// and the statements of vulnerabilities that do not affect the products must
+// give the justification or the impact statement.
+func OpenVEXFromSignature(sig oci.Signature) (Attestation, error) {
+	payload, err := payloadFromSig(sig)
+	if err != nil {
+		return nil, err
+	}
+
+	embedded, err := decodedPayload(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	var statement openVEXStatementEnvelope
+	if err := json.Unmarshal(embedded, &statement); err != nil {
+		return nil, fmt.Errorf("malformed attestation data: %w", err)
+	}
+
+	if statement.Type != in_toto.StatementInTotoV01 && statement.Type != StatementInTotoV1 {
+		return nil, fmt.Errorf("unsupported attestation type: %s", statement.Type)
+	}
+
+	if statement.PredicateType != PredicateOpenVEX && statement.PredicateType != PredicateOpenVEXv020 {
+		return nil, fmt.Errorf("unsupported attestation predicate type: %s", statement.PredicateType)
+	}
+
+	summary := VEXSummary{Statements: make([]VEXStatement, 0, len(statement.Predicate.Statements))}
+	var invalid error
+	for i, s := range statement.Predicate.Statements {
+		if err := validateVEXStatement(s); err != nil {
+			invalid = errors.Join(invalid, fmt.Errorf("statement %d: %w", i, err))
+			continue
+		}
+
+		products := make([]string, 0, len(s.Products))
+		for _, p := range s.Products {
+			products = append(products, string(p))
+		}
+
+		summary.Statements = append(summary.Statements, VEXStatement{
+			Vulnerability:   string(s.Vulnerability),
+			Products:        products,
+			Status:          s.Status,
+			Justification:   s.Justification,
+			ImpactStatement: s.ImpactStatement,
+			ActionStatement: s.ActionStatement,
+		})
+	}
+	if invalid != nil {
+		return nil, fmt.Errorf("invalid OpenVEX document: %w", invalid)
+	}
+
+	signatures, err := createEntitySignatures(sig, payload)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create signed entity: %w", err)
+	}
+
+	return openVEX{statement: statement, data: embedded, signatures: signatures, summary: summary}, nil
+}
+
+func validateVEXStatement(s openVEXStatement) error {
+	if s.Vulnerability == "" {
+		return errors.New("missing vulnerability")
+	}
+
+	if !slices.Contains(vexStatuses, s.Status) {
+		return fmt.Errorf("unsupported status %q", s.Status)
+	}
+
+	if s.Status == VEXNotAffected && s.Justification == "" && s.ImpactStatement == "" {
+		return errors.New("not_affected status without justification or impact_statement")
+	}
+
+	return nil
+}
+
+type openVEX struct {
+	statement  openVEXStatementEnvelope
+	data       []byte
+	signatures []signature.EntitySignature
+	summary    VEXSummary
+}
+
+func (a openVEX) Type() string {
+	return a.statement.Type
+}
+
+func (a openVEX) PredicateType() string {
+	return a.statement.PredicateType
+}
+
+// This returns the raw json, not the content of a.statement
+func (a openVEX) Statement() []byte {
+	return a.data
+}
+
+func (a openVEX) Signatures() []signature.EntitySignature {
+	return a.signatures
+}
+
+func (a openVEX) Subject() []in_toto.Subject {
+	return a.statement.Subject
+}
+
+func (a openVEX) Summary() VEXSummary {
+	return a.summary
+}
+
+func (a openVEX) MarshalJSON() ([]byte, error) {
+	val := struct {
+		Type          string                      `json:"type"`
+		PredicateType string                      `json:"predicateType"`
+		Signatures    []signature.EntitySignature `json:"signatures"`
+	}{
+		Type:          a.statement.Type,
+		PredicateType: a.statement.PredicateType,
+		Signatures:    a.signatures,
+	}
+
+	return json.Marshal(val)
+}
@@ -0,0 +1,126 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package attestation
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOpenVEXFromSignature(t *testing.T) {
+	cases := []struct {
+		name      string
+		statement string
+		expected  []VEXStatement
+		err       string
+	}{
+		{
+			name: "v0.2.0",
+			statement: `{
+				"_type": "https://in-toto.io/Statement/v0.1",
+				"predicateType": "https://openvex.dev/ns/v0.2.0",
+				"predicate": {
+					"@context": "https://openvex.dev/ns/v0.2.0",
+					"statements": [
+						{
+							"vulnerability": {"name": "CVE-2024-0001"},
+							"products": [{"@id": "pkg:oci/image@sha256%3Aabc"}],
+							"status": "not_affected",
+							"justification": "vulnerable_code_not_present"
+						},
+						{
+							"vulnerability": {"@id": "https://nvd.nist.gov/vuln/detail/CVE-2024-0002", "name": "CVE-2024-0002"},
+							"products": [{"@id": "pkg:oci/image@sha256%3Aabc"}],
+							"status": "affected",
+							"action_statement": "Upgrade to 2.0"
+						}
+					]
+				}
+			}`,
+			expected: []VEXStatement{
+				{Vulnerability: "CVE-2024-0001", Products: []string{"pkg:oci/image@sha256%3Aabc"}, Status: "not_affected", Justification: "vulnerable_code_not_present"},
+				{Vulnerability: "CVE-2024-0002", Products: []string{"pkg:oci/image@sha256%3Aabc"}, Status: "affected", ActionStatement: "Upgrade to 2.0"},
+			},
+		},
+		{
+			name: "v0.0.1",
+			statement: `{
+				"_type": "https://in-toto.io/Statement/v0.1",
+				"predicateType": "https://openvex.dev/ns",
+				"predicate": {
+					"@context": "https://openvex.dev/ns",
+					"statements": [
+						{
+							"vulnerability": "CVE-2024-0001",
+							"products": ["pkg:oci/image@sha256%3Aabc"],
+							"status": "not_affected",
+							"impact_statement": "The function is never called"
+						}
+					]
+				}
+			}`,
+			expected: []VEXStatement{
+				{Vulnerability: "CVE-2024-0001", Products: []string{"pkg:oci/image@sha256%3Aabc"}, Status: "not_affected", ImpactStatement: "The function is never called"},
+			},
+		},
+		{
+			name: "invalid statements",
+			statement: `{
+				"_type": "https://in-toto.io/Statement/v0.1",
+				"predicateType": "https://openvex.dev/ns",
+				"predicate": {
+					"statements": [
+						{"vulnerability": "CVE-2024-0001", "status": "not_affected"},
+						{"vulnerability": "CVE-2024-0002", "status": "spam"},
+						{"status": "fixed"}
+					]
+				}
+			}`,
+			err: "invalid OpenVEX document: statement 0: not_affected status without justification or impact_statement\n" +
+				"statement 1: unsupported status \"spam\"\n" +
+				"statement 2: missing vulnerability",
+		},
+		{
+			name:      "unexpected predicate type",
+			statement: `{"_type": "https://in-toto.io/Statement/v0.1", "predicateType": "https://cyclonedx.org/bom"}`,
+			err:       "unsupported attestation predicate type: https://cyclonedx.org/bom",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			sig := mockSignature{&mock.Mock{}}
+			signedStatement(&sig, c.statement)
+
+			v, err := OpenVEXFromSignature(sig)
+			if c.err != "" {
+				assert.EqualError(t, err, c.err)
+				assert.Nil(t, v)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.JSONEq(t, c.statement, string(v.Statement()))
+			assert.Equal(t, VEXSummary{Statements: c.expected}, v.(VEX).Summary())
+		})
+	}
+}
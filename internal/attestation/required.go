@@ -0,0 +1,65 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package attestation
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// AnyCollection is used in place of a collection name for the attestation
+// types required regardless of the selected collections.
+const AnyCollection = "*"
+
+// RequiredTypes maps the name of a policy rule collection to the predicate
+// types of the attestations required when the collection is selected.
+type RequiredTypes map[string][]string
+
+// ParseRequiredTypes parses the required types given as
+// <collection>=<predicate type> pairs.
+func ParseRequiredTypes(values []string) (RequiredTypes, error) {
+	required := RequiredTypes{}
+	for _, v := range values {
+		collection, predicateType, found := strings.Cut(v, "=")
+		if !found || collection == "" || predicateType == "" {
+			return nil, fmt.Errorf("invalid required attestation type %q, expected <collection>=<predicate type>", v)
+		}
+		collection = strings.TrimPrefix(collection, "@")
+		if !slices.Contains(required[collection], predicateType) {
+			required[collection] = append(required[collection], predicateType)
+		}
+	}
+
+	return required, nil
+}
+
+// For returns the predicate types required for the given selected
+// collections, including the types required for any collection.
+func (r RequiredTypes) For(collections []string) []string {
+	types := []string{}
+	for _, c := range append([]string{AnyCollection}, collections...) {
+		for _, t := range r[c] {
+			if !slices.Contains(types, t) {
+				types = append(types, t)
+			}
+		}
+	}
+	slices.Sort(types)
+
+	return types
+}
@@ -0,0 +1,62 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package attestation
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRequiredTypes(t *testing.T) {
+	required, err := ParseRequiredTypes([]string{
+		"@minimal=https://slsa.dev/provenance/v0.2",
+		"minimal=https://slsa.dev/provenance/v0.2",
+		"slsa3=https://spdx.dev/Document",
+		"*=https://example.com/test-result",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, RequiredTypes{
+		"minimal": {"https://slsa.dev/provenance/v0.2"},
+		"slsa3":   {"https://spdx.dev/Document"},
+		"*":       {"https://example.com/test-result"},
+	}, required)
+
+	for _, invalid := range []string{"minimal", "=https://spdx.dev/Document", "minimal="} {
+		_, err := ParseRequiredTypes([]string{invalid})
+		assert.EqualError(t, err, `invalid required attestation type "`+invalid+`", expected <collection>=<predicate type>`)
+	}
+}
+
+func TestRequiredTypesFor(t *testing.T) {
+	required := RequiredTypes{
+		"minimal": {"https://slsa.dev/provenance/v0.2"},
+		"slsa3":   {"https://spdx.dev/Document", "https://slsa.dev/provenance/v0.2"},
+		"*":       {"https://example.com/test-result"},
+	}
+
+	assert.Equal(t, []string{"https://example.com/test-result"}, required.For(nil))
+	assert.Equal(t, []string{
+		"https://example.com/test-result",
+		"https://slsa.dev/provenance/v0.2",
+		"https://spdx.dev/Document",
+	}, required.For([]string{"minimal", "slsa3"}))
+	assert.Empty(t, RequiredTypes{}.For([]string{"minimal"}))
+}
@@ -0,0 +1,168 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package attestation
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/in-toto/in-toto-golang/in_toto"
+	"github.com/sigstore/cosign/v2/pkg/oci"
+
+	"github.com/enterprise-contract/ec-cli/internal/signature"
+)
+
+// SBOM is an attestation holding a Software Bill of Materials of any of the
+// supported formats.
+type SBOM interface {
+	Attestation
+	Summary() SBOMSummary
+}
+
+// SBOMSummary holds the details of a Software Bill of Materials that are
+// common to all of its formats, normalized so that policies do not need to
+// account for the differences between the formats.
+type SBOMSummary struct {
+	// Format is either "spdx" or "cyclonedx"
+	Format string `json:"format"`
+	// SpecVersion is the version of the specification of the format, e.g.
+	// SPDX-2.3 or 1.5
+	SpecVersion string `json:"spec_version"`
+	// Packages are the packages, or components, listed in the SBOM
+	Packages []SBOMPackage `json:"packages"`
+}
+
+// SBOMPackage is a package listed in a Software Bill of Materials
+type SBOMPackage struct {
+	Name    string `json:"name"`
+	Version string `json:"version,omitempty"`
+	PURL    string `json:"purl,omitempty"`
+}
+
+// Formats of the SBOMs
+const (
+	SBOMFormatSPDX      = "spdx"
+	SBOMFormatCycloneDX = "cyclonedx"
+)
+
+// SummarizeSBOM returns the normalized details of the SBOM document, in JSON,
+// of the given format, e.g. of an SBOM attached to an image rather than held
+// in an attestation.
+func SummarizeSBOM(format string, document []byte) (SBOMSummary, error) {
+	switch format {
+	case SBOMFormatSPDX:
+		return decodeSPDX(document)
+	case SBOMFormatCycloneDX:
+		return decodeCycloneDX(document)
+	}
+
+	return SBOMSummary{}, fmt.Errorf("unsupported SBOM format: %q", format)
+}
+
+// sbomStatement is an in-toto statement with the SBOM as its predicate
+type sbomStatement struct {
+	in_toto.StatementHeader
+	Predicate json.RawMessage `json:"predicate"`
+}
+
+// sbomFromSignature parses the in-toto statement holding an SBOM of the
+// predicate type from the provided OCI layer, the predicate is decoded by the
+// decode function.
+func sbomFromSignature(sig oci.Signature, predicateType string, decode func(json.RawMessage) (SBOMSummary, error)) (Attestation, error) {
+	payload, err := payloadFromSig(sig)
+	if err != nil {
+		return nil, err
+	}
+
+	embedded, err := decodedPayload(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	var statement sbomStatement
+	if err := json.Unmarshal(embedded, &statement); err != nil {
+		return nil, fmt.Errorf("malformed attestation data: %w", err)
+	}
+
+	if statement.Type != in_toto.StatementInTotoV01 && statement.Type != StatementInTotoV1 {
+		return nil, fmt.Errorf("unsupported attestation type: %s", statement.Type)
+	}
+
+	if statement.PredicateType != predicateType {
+		return nil, fmt.Errorf("unsupported attestation predicate type: %s", statement.PredicateType)
+	}
+
+	summary, err := decode(statement.Predicate)
+	if err != nil {
+		return nil, err
+	}
+
+	signatures, err := createEntitySignatures(sig, payload)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create signed entity: %w", err)
+	}
+
+	return sbom{statement: statement, data: embedded, signatures: signatures, summary: summary}, nil
+}
+
+type sbom struct {
+	statement  sbomStatement
+	data       []byte
+	signatures []signature.EntitySignature
+	summary    SBOMSummary
+}
+
+func (a sbom) Type() string {
+	return a.statement.Type
+}
+
+func (a sbom) PredicateType() string {
+	return a.statement.PredicateType
+}
+
+// This returns the raw json, not the content of a.statement
+func (a sbom) Statement() []byte {
+	return a.data
+}
+
+func (a sbom) Signatures() []signature.EntitySignature {
+	return a.signatures
+}
+
+func (a sbom) Subject() []in_toto.Subject {
+	return a.statement.Subject
+}
+
+func (a sbom) Summary() SBOMSummary {
+	return a.summary
+}
+
+func (a sbom) MarshalJSON() ([]byte, error) {
+	val := struct {
+		Type          string                      `json:"type"`
+		PredicateType string                      `json:"predicateType"`
+		Format        string                      `json:"format"`
+		Signatures    []signature.EntitySignature `json:"signatures"`
+	}{
+		Type:          a.statement.Type,
+		PredicateType: a.statement.PredicateType,
+		Format:        a.summary.Format,
+		Signatures:    a.signatures,
+	}
+
+	return json.Marshal(val)
+}
@@ -0,0 +1,204 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package attestation
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/gkampitakis/go-snaps/snaps"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+const spdxStatement = `{
+	"_type": "https://in-toto.io/Statement/v0.1",
+	"predicateType": "https://spdx.dev/Document",
+	"predicate": {
+		"spdxVersion": "SPDX-2.3",
+		"dataLicense": "CC0-1.0",
+		"SPDXID": "SPDXRef-DOCUMENT",
+		"name": "image",
+		"documentNamespace": "https://example.com/image",
+		"creationInfo": {"created": "2024-01-01T00:00:00Z", "creators": ["Tool: syft"]},
+		"packages": [
+			{
+				"name": "spam",
+				"SPDXID": "SPDXRef-Package-spam",
+				"versionInfo": "1.0",
+				"downloadLocation": "NOASSERTION",
+				"externalRefs": [
+					{"referenceCategory": "SECURITY", "referenceType": "cpe23Type", "referenceLocator": "cpe:2.3:a:spam:spam:1.0:*:*:*:*:*:*:*"},
+					{"referenceCategory": "PACKAGE-MANAGER", "referenceType": "purl", "referenceLocator": "pkg:rpm/redhat/spam@1.0"}
+				]
+			},
+			{"name": "eggs", "SPDXID": "SPDXRef-Package-eggs", "downloadLocation": "NOASSERTION"}
+		]
+	}
+}`
+
+const cycloneDXStatement = `{
+	"_type": "https://in-toto.io/Statement/v0.1",
+	"predicateType": "https://cyclonedx.org/bom",
+	"predicate": {
+		"bomFormat": "CycloneDX",
+		"specVersion": "1.5",
+		"components": [
+			{
+				"type": "library",
+				"name": "spam",
+				"version": "1.0",
+				"purl": "pkg:rpm/redhat/spam@1.0",
+				"components": [{"type": "library", "name": "bacon", "version": "2.0"}]
+			},
+			{"type": "library", "name": "eggs"}
+		]
+	}
+}`
+
+func TestSPDXSBOMFromSignature(t *testing.T) {
+	cases := []struct {
+		name      string
+		statement string
+		err       string
+	}{
+		{name: "valid", statement: spdxStatement},
+		{
+			name:      "unsupported version",
+			statement: `{"_type": "https://in-toto.io/Statement/v0.1", "predicateType": "https://spdx.dev/Document", "predicate": {"spdxVersion": "SPDX-9.9"}}`,
+			err:       "invalid SPDX document: unsupported SDPX version: SPDX-9.9",
+		},
+		{
+			name:      "missing fields",
+			statement: `{"_type": "https://in-toto.io/Statement/v0.1", "predicateType": "https://spdx.dev/Document", "predicate": {"spdxVersion": "SPDX-2.3", "name": "image"}}`,
+			err:       "invalid SPDX document: missing SPDXID\nmissing documentNamespace\nmissing dataLicense\nmissing creationInfo",
+		},
+		{
+			name:      "unexpected predicate type",
+			statement: cycloneDXStatement,
+			err:       "unsupported attestation predicate type: https://cyclonedx.org/bom",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			sig := mockSignature{&mock.Mock{}}
+			signedStatement(&sig, c.statement)
+
+			sb, err := SPDXSBOMFromSignature(sig)
+			if c.err != "" {
+				assert.EqualError(t, err, c.err)
+				assert.Nil(t, sb)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.JSONEq(t, c.statement, string(sb.Statement()))
+			assert.Equal(t, SBOMSummary{
+				Format:      "spdx",
+				SpecVersion: "SPDX-2.3",
+				Packages: []SBOMPackage{
+					{Name: "spam", Version: "1.0", PURL: "pkg:rpm/redhat/spam@1.0"},
+					{Name: "eggs"},
+				},
+			}, sb.(SBOM).Summary())
+		})
+	}
+}
+
+func TestCycloneDXSBOMFromSignature(t *testing.T) {
+	cases := []struct {
+		name      string
+		statement string
+		err       string
+	}{
+		{name: "valid", statement: cycloneDXStatement},
+		{
+			name:      "not CycloneDX",
+			statement: `{"_type": "https://in-toto.io/Statement/v0.1", "predicateType": "https://cyclonedx.org/bom", "predicate": {"bomFormat": "SPDX"}}`,
+			err:       `invalid CycloneDX BOM: unsupported bomFormat "SPDX"`,
+		},
+		{
+			name:      "malformed",
+			statement: `{"_type": "https://in-toto.io/Statement/v0.1", "predicateType": "https://cyclonedx.org/bom", "predicate": {"components": {}}}`,
+			err:       "invalid CycloneDX BOM: json: cannot unmarshal object into Go struct field cycloneDXBOM.components of type []attestation.cycloneDXComponent",
+		},
+		{
+			name:      "unsupported statement type",
+			statement: `{"_type": "https://example.com/Statement", "predicateType": "https://cyclonedx.org/bom"}`,
+			err:       "unsupported attestation type: https://example.com/Statement",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			sig := mockSignature{&mock.Mock{}}
+			signedStatement(&sig, c.statement)
+
+			sb, err := CycloneDXSBOMFromSignature(sig)
+			if c.err != "" {
+				assert.EqualError(t, err, c.err)
+				assert.Nil(t, sb)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, PredicateCycloneDX, sb.PredicateType())
+			assert.Equal(t, SBOMSummary{
+				Format:      "cyclonedx",
+				SpecVersion: "1.5",
+				Packages: []SBOMPackage{
+					{Name: "spam", Version: "1.0", PURL: "pkg:rpm/redhat/spam@1.0"},
+					{Name: "bacon", Version: "2.0"},
+					{Name: "eggs"},
+				},
+			}, sb.(SBOM).Summary())
+		})
+	}
+}
+
+func TestMarshalSBOM(t *testing.T) {
+	sig := mockSignature{&mock.Mock{}}
+	signedStatement(&sig, cycloneDXStatement)
+
+	sb, err := CycloneDXSBOMFromSignature(sig)
+	require.NoError(t, err)
+
+	j, err := json.Marshal(sb)
+	require.NoError(t, err)
+
+	snaps.MatchJSON(t, j)
+}
+
+func TestSummarizeSBOM(t *testing.T) {
+	summary, err := SummarizeSBOM(SBOMFormatCycloneDX, []byte(`{"bomFormat": "CycloneDX", "specVersion": "1.5", "components": [{"name": "spam", "version": "1.0"}]}`))
+	require.NoError(t, err)
+	assert.Equal(t, SBOMSummary{
+		Format:      SBOMFormatCycloneDX,
+		SpecVersion: "1.5",
+		Packages:    []SBOMPackage{{Name: "spam", Version: "1.0"}},
+	}, summary)
+
+	_, err = SummarizeSBOM(SBOMFormatSPDX, []byte(`{"spdxVersion": "SPDX-2.3"}`))
+	assert.ErrorContains(t, err, "invalid SPDX document")
+
+	_, err = SummarizeSBOM("syft", []byte(`{}`))
+	assert.EqualError(t, err, `unsupported SBOM format: "syft"`)
+}
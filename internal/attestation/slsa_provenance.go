@@ -0,0 +1,46 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package attestation
+
+import "github.com/in-toto/in-toto-golang/in_toto/slsa_provenance/common"
+
+// Provenance is an attestation holding SLSA Provenance of any of the supported
+// versions.
+type Provenance interface {
+	Attestation
+	Summary() ProvenanceSummary
+}
+
+// ProvenanceSummary holds the details of SLSA Provenance that are common to
+// all of its versions, normalized so that policies do not need to account for
+// the differences between the versions.
+type ProvenanceSummary struct {
+	// BuilderID is the builder.id in v0.2, and runDetails.builder.id in v1.0
+	BuilderID string `json:"builder_id"`
+	// BuildType is the buildType in v0.2, and buildDefinition.buildType in v1.0
+	BuildType string `json:"build_type"`
+	// ResolvedDependencies are the materials in v0.2, and
+	// buildDefinition.resolvedDependencies in v1.0
+	ResolvedDependencies []ResolvedDependency `json:"resolved_dependencies"`
+}
+
+// ResolvedDependency is an artifact the build depended on
+type ResolvedDependency struct {
+	URI    string           `json:"uri,omitempty"`
+	Digest common.DigestSet `json:"digest,omitempty"`
+	Name   string           `json:"name,omitempty"`
+}
@@ -94,6 +94,19 @@ func (a slsaProvenance) Subject() []in_toto.Subject {
 	return a.statement.Subject
 }
 
+func (a slsaProvenance) Summary() ProvenanceSummary {
+	dependencies := make([]ResolvedDependency, 0, len(a.statement.Predicate.Materials))
+	for _, m := range a.statement.Predicate.Materials {
+		dependencies = append(dependencies, ResolvedDependency{URI: m.URI, Digest: m.Digest})
+	}
+
+	return ProvenanceSummary{
+		BuilderID:            a.statement.Predicate.Builder.ID,
+		BuildType:            a.statement.Predicate.BuildType,
+		ResolvedDependencies: dependencies,
+	}
+}
+
 // Todo: It seems odd that this does not contain the statement.
 // (See also the equivalent method in attestation.go)
 func (a slsaProvenance) MarshalJSON() ([]byte, error) {
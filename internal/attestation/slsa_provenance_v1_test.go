@@ -0,0 +1,177 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package attestation
+
+import (
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/gkampitakis/go-snaps/snaps"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+	"github.com/in-toto/in-toto-golang/in_toto/slsa_provenance/common"
+	"github.com/sigstore/cosign/v2/pkg/cosign/bundle"
+	ct "github.com/sigstore/cosign/v2/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+const slsaV1Statement = `{
+	"_type": "https://in-toto.io/Statement/v1",
+	"subject": [{"name": "registry.io/repository/image", "digest": {"sha256": "abc"}}],
+	"predicateType": "https://slsa.dev/provenance/v1",
+	"predicate": {
+		"buildDefinition": {
+			"buildType": "https://tekton.dev/chains/v2/slsa",
+			"externalParameters": {},
+			"resolvedDependencies": [
+				{"uri": "git+https://github.com/org/repo", "digest": {"sha1": "def"}, "name": "source"},
+				{"uri": "oci://registry.io/task/buildah", "digest": {"sha256": "123"}}
+			]
+		},
+		"runDetails": {
+			"builder": {"id": "https://tekton.dev/chains/v2"}
+		}
+	}
+}`
+
+func signedStatement(l *mockSignature, statement string) {
+	l.On("MediaType").Return(types.MediaType(ct.DssePayloadType), nil)
+	l.On("Uncompressed").Return(buffy(
+		fmt.Sprintf(`{"payload": "%s", "signatures": [{"keyid": "key-id-1", "sig": "sig-1"}]}`, encode(statement)),
+	), nil)
+	l.On("Base64Signature").Return("", nil)
+	l.On("Cert").Return(&x509.Certificate{}, nil)
+	l.On("Chain").Return([]*x509.Certificate{}, nil)
+	l.On("Bundle").Return((*bundle.RekorBundle)(nil), nil)
+}
+
+func TestSLSAProvenanceV1FromSignature(t *testing.T) {
+	cases := []struct {
+		name      string
+		statement string
+		err       error
+	}{
+		{
+			name:      "valid",
+			statement: slsaV1Statement,
+		},
+		{
+			name: "in-toto v0.1 statement",
+			statement: `{
+				"_type": "https://in-toto.io/Statement/v0.1",
+				"predicateType": "https://slsa.dev/provenance/v1",
+				"predicate": {"buildDefinition": {"buildType": "https://my.build.type"}}
+			}`,
+		},
+		{
+			name: "unsupported statement type",
+			statement: `{
+				"_type": "https://in-toto.io/Statement/v2",
+				"predicateType": "https://slsa.dev/provenance/v1"
+			}`,
+			err: errors.New("unsupported attestation type: https://in-toto.io/Statement/v2"),
+		},
+		{
+			name: "unexpected predicate type",
+			statement: `{
+				"_type": "https://in-toto.io/Statement/v1",
+				"predicateType": "https://slsa.dev/provenance/v0.2"
+			}`,
+			err: errors.New("unsupported attestation predicate type: https://slsa.dev/provenance/v0.2"),
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			sig := mockSignature{&mock.Mock{}}
+			signedStatement(&sig, c.statement)
+
+			sp, err := SLSAProvenanceV1FromSignature(sig)
+			if c.err != nil {
+				assert.EqualError(t, err, c.err.Error())
+				assert.Nil(t, sp)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.JSONEq(t, c.statement, string(sp.Statement()))
+			assert.Equal(t, PredicateSLSAProvenanceV1, sp.PredicateType())
+			assert.Len(t, sp.Signatures(), 1)
+		})
+	}
+}
+
+func TestProvenanceSummary(t *testing.T) {
+	v1 := mockSignature{&mock.Mock{}}
+	signedStatement(&v1, slsaV1Statement)
+
+	v02 := mockSignature{&mock.Mock{}}
+	signedStatement(&v02, `{
+		"_type": "https://in-toto.io/Statement/v0.1",
+		"predicateType": "https://slsa.dev/provenance/v0.2",
+		"predicate": {
+			"builder": {"id": "https://tekton.dev/chains/v2"},
+			"buildType": "https://tekton.dev/attestations/chains/pipelinerun@v2",
+			"materials": [
+				{"uri": "git+https://github.com/org/repo", "digest": {"sha1": "def"}},
+				{"uri": "oci://registry.io/task/buildah", "digest": {"sha256": "123"}}
+			]
+		}
+	}`)
+
+	a1, err := SLSAProvenanceV1FromSignature(v1)
+	require.NoError(t, err)
+	a02, err := SLSAProvenanceFromSignature(v02)
+	require.NoError(t, err)
+
+	assert.Equal(t, ProvenanceSummary{
+		BuilderID: "https://tekton.dev/chains/v2",
+		BuildType: "https://tekton.dev/chains/v2/slsa",
+		ResolvedDependencies: []ResolvedDependency{
+			{URI: "git+https://github.com/org/repo", Digest: common.DigestSet{"sha1": "def"}, Name: "source"},
+			{URI: "oci://registry.io/task/buildah", Digest: common.DigestSet{"sha256": "123"}},
+		},
+	}, a1.(Provenance).Summary())
+
+	assert.Equal(t, ProvenanceSummary{
+		BuilderID: "https://tekton.dev/chains/v2",
+		BuildType: "https://tekton.dev/attestations/chains/pipelinerun@v2",
+		ResolvedDependencies: []ResolvedDependency{
+			{URI: "git+https://github.com/org/repo", Digest: common.DigestSet{"sha1": "def"}},
+			{URI: "oci://registry.io/task/buildah", Digest: common.DigestSet{"sha256": "123"}},
+		},
+	}, a02.(Provenance).Summary())
+}
+
+func TestMarshalV1(t *testing.T) {
+	sig := mockSignature{&mock.Mock{}}
+	signedStatement(&sig, slsaV1Statement)
+
+	att, err := SLSAProvenanceV1FromSignature(sig)
+	require.NoError(t, err)
+
+	j, err := json.Marshal(att)
+	require.NoError(t, err)
+
+	snaps.MatchJSON(t, j)
+}
@@ -16,9 +16,63 @@
 
 package attestation
 
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/sigstore/cosign/v2/pkg/oci"
+	spdxjson "github.com/spdx/tools-golang/json"
+)
+
 const (
 	PredicateSpdxDocument = "https://spdx.dev/Document"
 )
 
-// Todo: More code here in future probably, e.g. for unmarshaling an SPDX
-// document to confirm it is formatted correctly
+// SPDXSBOMFromSignature parses the SPDX SBOM from the provided OCI layer.
+// Expects that the layer contains DSSE JSON with the embedded SPDX document,
+// in JSON format, as the predicate. The document is decoded as any of the
+// SPDX 2.x versions and must hold the required document fields.
+func SPDXSBOMFromSignature(sig oci.Signature) (Attestation, error) {
+	return sbomFromSignature(sig, PredicateSpdxDocument, decodeSPDX)
+}
+
+func decodeSPDX(predicate json.RawMessage) (SBOMSummary, error) {
+	doc, err := spdxjson.Read(bytes.NewReader(predicate))
+	if err != nil {
+		return SBOMSummary{}, fmt.Errorf("invalid SPDX document: %w", err)
+	}
+
+	var missing error
+	for _, f := range []struct{ name, value string }{
+		{"SPDXID", string(doc.SPDXIdentifier)},
+		{"name", doc.DocumentName},
+		{"documentNamespace", doc.DocumentNamespace},
+		{"dataLicense", doc.DataLicense},
+	} {
+		if f.value == "" {
+			missing = errors.Join(missing, fmt.Errorf("missing %s", f.name))
+		}
+	}
+	if doc.CreationInfo == nil {
+		missing = errors.Join(missing, errors.New("missing creationInfo"))
+	}
+	if missing != nil {
+		return SBOMSummary{}, fmt.Errorf("invalid SPDX document: %w", missing)
+	}
+
+	packages := make([]SBOMPackage, 0, len(doc.Packages))
+	for _, p := range doc.Packages {
+		pkg := SBOMPackage{Name: p.PackageName, Version: p.PackageVersion}
+		for _, ref := range p.PackageExternalReferences {
+			if ref.RefType == "purl" {
+				pkg.PURL = ref.Locator
+				break
+			}
+		}
+		packages = append(packages, pkg)
+	}
+
+	return SBOMSummary{Format: SBOMFormatSPDX, SpecVersion: doc.SPDXVersion, Packages: packages}, nil
+}
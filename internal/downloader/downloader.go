@@ -21,6 +21,8 @@ package downloader
 import (
 	"context"
 	"fmt"
+	"os"
+	"path/filepath"
 	"regexp"
 	"strings"
 	"sync"
@@ -29,10 +31,13 @@ import (
 	ghttp "github.com/enterprise-contract/go-gather/gather/http"
 	goci "github.com/enterprise-contract/go-gather/gather/oci"
 	"github.com/enterprise-contract/go-gather/metadata"
+	gitMetadata "github.com/enterprise-contract/go-gather/metadata/git"
+	"github.com/go-git/go-git/v5/plumbing/transport/ssh"
 	"github.com/sirupsen/logrus"
 	"oras.land/oras-go/v2/registry/remote/retry"
 
 	"github.com/enterprise-contract/ec-cli/internal/http"
+	"github.com/enterprise-contract/ec-cli/internal/logging"
 )
 
 type key int
@@ -51,6 +56,9 @@ var gatherFunc = func(ctx context.Context, source, destination string) (metadata
 }
 
 var _initialize = func() {
+	goci.Transport = http.NewRateLimitingRoundTripper(goci.Transport)
+	ghttp.Transport = http.NewRateLimitingRoundTripper(ghttp.Transport)
+
 	if log.IsLevelEnabled(logrus.TraceLevel) {
 		goci.Transport = http.NewTracingRoundTripperWithLogger(goci.Transport, log)
 		ghttp.Transport = http.NewTracingRoundTripperWithLogger(ghttp.Transport, log)
@@ -68,13 +76,17 @@ var _initialize = func() {
 		return policy
 	}
 
-	ociTransport := retry.NewTransport(goci.Transport)
+	// Downloads interrupted while reading the response body are resumed,
+	// requests that fail outright are retried
+	ociTransport := retry.NewTransport(http.NewResumingRoundTripper(goci.Transport))
 	ociTransport.Policy = policyfn
 	goci.Transport = ociTransport
 
-	httpTransport := retry.NewTransport(ghttp.Transport)
+	httpTransport := retry.NewTransport(http.NewResumingRoundTripper(ghttp.Transport))
 	httpTransport.Policy = policyfn
 	ghttp.Transport = httpTransport
+
+	ssh.DefaultAuthBuilder = sshAuth
 }
 
 var initialize = sync.OnceFunc(_initialize)
@@ -84,23 +96,166 @@ func WithDownloadImpl(ctx context.Context, d downloadImpl) context.Context {
 	return context.WithValue(ctx, downloadImplKey, d)
 }
 
-// Download is used to download files from various sources.
+// Download is used to download files from various sources. Failed downloads
+// are retried as configured by SetRetry.
 func Download(ctx context.Context, destDir string, sourceUrl string, showMsg bool) (metadata.Metadata, error) {
 	if !isSecure(sourceUrl) {
-		return nil, fmt.Errorf("attempting to download from insecure source: %s", sourceUrl)
+		return nil, fmt.Errorf("attempting to download from insecure source: %s", logging.RedactURL(sourceUrl))
 	}
 
-	msg := fmt.Sprintf("Downloading %s to %s", sourceUrl, destDir)
+	msg := fmt.Sprintf("Downloading %s to %s", logging.RedactURL(sourceUrl), destDir)
 	log.Debug(msg)
 	if showMsg {
 		fmt.Println(msg)
 	}
 
-	m, err := gatherFunc(ctx, sourceUrl, destDir)
+	if ref, subpath, ok := splitOCISubpath(sourceUrl); ok {
+		return withRetry(ctx, sourceUrl, destDir, func(ctx context.Context) (metadata.Metadata, error) {
+			return downloadOCISubpath(ctx, destDir, ref, subpath)
+		})
+	}
+
+	authenticatedUrl, secret := withGitCredentials(sourceUrl)
+
+	m, err := withRetry(ctx, sourceUrl, destDir, func(ctx context.Context) (metadata.Metadata, error) {
+		m, err := gatherSource(ctx, authenticatedUrl, destDir)
+		if err != nil && secret != "" {
+			err = &redactedError{err: err, secret: secret}
+		}
+		return m, err
+	})
 	if err != nil {
 		log.Debug("Download failed!")
+		return m, err
+	}
+
+	// The remote of the cloned repository holds the credentials added to its
+	// url, only the files of the repository are kept so that the credentials
+	// end up in neither the persistent cache nor the bundles
+	if _, ok := m.(*gitMetadata.GitMetadata); ok {
+		if err := os.RemoveAll(filepath.Join(destDir, ".git")); err != nil {
+			return nil, fmt.Errorf("removing the git metadata of %s: %w", logging.RedactURL(sourceUrl), err)
+		}
 	}
-	return m, err
+
+	return m, nil
+}
+
+// redactedError hides the secret from the message of the wrapped error
+type redactedError struct {
+	err    error
+	secret string
+}
+
+func (r *redactedError) Error() string {
+	return strings.ReplaceAll(r.err.Error(), r.secret, "REDACTED")
+}
+
+func (r *redactedError) Unwrap() error {
+	return r.err
+}
+
+// splitOCISubpath splits an OCI source url with a subpath, in the form of
+// oci::registry.io/repository/image:tag//path/within, into the reference of
+// the artifact and the path within the artifact. Similarly to go-getter's
+// subdirectories, this allows loading only a part of an artifact, e.g. the
+// policy directory of a Helm chart.
+func splitOCISubpath(sourceUrl string) (string, string, bool) {
+	var prefix string
+	switch {
+	case strings.HasPrefix(sourceUrl, "oci::"):
+		prefix = "oci::"
+	case strings.HasPrefix(sourceUrl, "oci://"):
+		prefix = "oci://"
+	default:
+		return "", "", false
+	}
+
+	rest := strings.TrimPrefix(sourceUrl, prefix)
+	if scheme, r, found := strings.Cut(rest, "://"); found {
+		prefix, rest = prefix+scheme+"://", r
+	}
+
+	ref, subpath, found := strings.Cut(rest, "//")
+	if !found {
+		return "", "", false
+	}
+
+	return prefix + ref, subpath, true
+}
+
+// downloadOCISubpath downloads the OCI artifact to a temporary directory and
+// copies only the given subpath of it to the destination directory.
+func downloadOCISubpath(ctx context.Context, destDir, ref, subpath string) (metadata.Metadata, error) {
+	subpath = filepath.Clean(subpath)
+	if !filepath.IsLocal(subpath) {
+		return nil, fmt.Errorf("invalid path %q within the OCI artifact %s", subpath, ref)
+	}
+
+	tmp, err := os.MkdirTemp("", "ec-oci-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(tmp)
+
+	m, err := gatherSource(ctx, ref, tmp)
+	if err != nil {
+		log.Debug("Download failed!")
+		return nil, err
+	}
+
+	src := filepath.Join(tmp, subpath)
+	info, err := os.Stat(src)
+	if err != nil {
+		return nil, fmt.Errorf("path %q not found within the OCI artifact %s", subpath, ref)
+	}
+
+	log.Debugf("Copying %s from the OCI artifact %s to %s", subpath, ref, destDir)
+
+	if !info.IsDir() {
+		return m, copyFile(src, filepath.Join(destDir, filepath.Base(src)))
+	}
+
+	err = filepath.WalkDir(src, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(destDir, rel)
+
+		if d.IsDir() {
+			return os.MkdirAll(target, 0o755)
+		}
+
+		if !d.Type().IsRegular() {
+			log.Debugf("Skipping %s within the OCI artifact, not a regular file", rel)
+			return nil
+		}
+
+		return copyFile(path, target)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
+
+func copyFile(src, dest string) error {
+	data, err := os.ReadFile(src)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(dest), 0o755); err != nil {
+		return err
+	}
+
+	return os.WriteFile(dest, data, 0o644)
 }
 
 // matches insecure protocols, such as `git::http://...`
@@ -0,0 +1,186 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package downloader
+
+import (
+	"bufio"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/go-git/go-git/v5/plumbing/transport/ssh"
+)
+
+const (
+	// gitSSHKeyEnv holds the path to the private key used to access git
+	// repositories over SSH, when not set the keys from the ssh-agent are used
+	gitSSHKeyEnv = "EC_GIT_SSH_KEY"
+	// gitSSHKeyPasswordEnv holds the password of the private key, if any
+	gitSSHKeyPasswordEnv = "EC_GIT_SSH_KEY_PASSWORD"
+)
+
+type gitToken struct {
+	env  string
+	user string
+}
+
+// gitTokens holds, per git host, the environment variable holding the access
+// token and the user name the token is provided with
+var gitTokens = map[string]gitToken{
+	"github.com": {env: "GITHUB_TOKEN", user: "x-access-token"},
+	"gitlab.com": {env: "GITLAB_TOKEN", user: "oauth2"},
+}
+
+// sshAuth is used as the default SSH authentication method for git, it uses
+// the private key from EC_GIT_SSH_KEY if set, and the ssh-agent otherwise
+func sshAuth(user string) (ssh.AuthMethod, error) {
+	key := os.Getenv(gitSSHKeyEnv)
+	if key == "" {
+		return ssh.NewSSHAgentAuth(user)
+	}
+
+	return ssh.NewPublicKeysFromFile(user, key, os.Getenv(gitSSHKeyPasswordEnv))
+}
+
+// withGitCredentials returns the source url with the credentials for the git
+// repository it points to, if the repository is accessed over HTTPS, the url
+// holds no credentials already and the credentials are found in the
+// environment, i.e. GITHUB_TOKEN, GITLAB_TOKEN or the netrc file. The second
+// return value is the secret that was added to the url, if any.
+func withGitCredentials(sourceUrl string) (string, string) {
+	forced, rest, found := strings.Cut(sourceUrl, "::")
+	if !found {
+		forced, rest = "", sourceUrl
+	}
+
+	switch {
+	case forced == "git":
+	case forced == "":
+		// go-gather handles these as git repositories without forcing
+		if !strings.HasPrefix(rest, "github.com/") && !strings.HasPrefix(rest, "gitlab.com/") {
+			return sourceUrl, ""
+		}
+	default:
+		return sourceUrl, ""
+	}
+
+	if !strings.Contains(rest, "://") {
+		if strings.HasPrefix(rest, "git@") {
+			// SSH, handled by sshAuth
+			return sourceUrl, ""
+		}
+		rest = "https://" + rest
+	}
+
+	u, err := url.Parse(rest)
+	if err != nil || u.Scheme != "https" || u.User != nil {
+		return sourceUrl, ""
+	}
+
+	user, password, ok := gitCredentials(u.Hostname())
+	if !ok {
+		return sourceUrl, ""
+	}
+
+	u.User = url.UserPassword(user, password)
+
+	return "git::" + u.String(), password
+}
+
+// gitCredentials finds the user name and password for the git host, either
+// from the access token in the environment or from the netrc file
+func gitCredentials(host string) (string, string, bool) {
+	if token, ok := gitTokens[host]; ok {
+		if t := os.Getenv(token.env); t != "" {
+			return token.user, t, true
+		}
+	}
+
+	return netrcCredentials(host)
+}
+
+// netrcCredentials looks up the login and password for the host in the file
+// pointed to by NETRC, or ~/.netrc by default
+func netrcCredentials(host string) (string, string, bool) {
+	path := os.Getenv("NETRC")
+	if path == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", "", false
+		}
+		path = filepath.Join(home, ".netrc")
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return "", "", false
+	}
+	defer f.Close()
+
+	return parseNetrc(bufio.NewScanner(f), host)
+}
+
+// parseNetrc finds the login and password of the machine matching the host in
+// the netrc tokens, falling back to the default entry if there is no match
+func parseNetrc(s *bufio.Scanner, host string) (string, string, bool) {
+	s.Split(bufio.ScanWords)
+
+	type entry struct {
+		login, password string
+	}
+
+	var current, fallback *entry
+	matched := false
+	for s.Scan() {
+		switch s.Text() {
+		case "machine":
+			if matched {
+				return current.login, current.password, true
+			}
+			current = nil
+			if s.Scan() && s.Text() == host {
+				current = &entry{}
+				matched = true
+			}
+		case "default":
+			if matched {
+				return current.login, current.password, true
+			}
+			fallback = &entry{}
+			current = fallback
+		case "login":
+			if s.Scan() && current != nil {
+				current.login = s.Text()
+			}
+		case "password":
+			if s.Scan() && current != nil {
+				current.password = s.Text()
+			}
+		}
+	}
+
+	if matched {
+		return current.login, current.password, true
+	}
+
+	if fallback != nil {
+		return fallback.login, fallback.password, true
+	}
+
+	return "", "", false
+}
@@ -0,0 +1,195 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package downloader
+
+import (
+	"archive/tar"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/enterprise-contract/go-gather/metadata"
+	ociMetadata "github.com/enterprise-contract/go-gather/metadata/oci"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+
+	"github.com/enterprise-contract/ec-cli/internal/utils/oci"
+)
+
+// titleAnnotation names the file a layer of an OCI artifact is saved as, it is
+// set on the layers pushed by conftest and ORAS
+const titleAnnotation = "org.opencontainers.image.title"
+
+// archiveMediaTypes are the media types of layers holding a tar archive of
+// policy files, e.g. OPA bundles, which are extracted when the layer has no
+// title
+var archiveMediaTypes = []types.MediaType{
+	types.OCILayer,
+	types.OCIUncompressedLayer,
+	types.DockerLayer,
+	types.DockerUncompressedLayer,
+	"application/vnd.cncf.openpolicyagent.layer.v1.tar+gzip",
+}
+
+// isOCI returns true if the source URL is of an OCI artifact
+func isOCI(sourceUrl string) bool {
+	return strings.HasPrefix(sourceUrl, "oci::") || strings.HasPrefix(sourceUrl, "oci://")
+}
+
+// gatherSource fetches the source to the destination directory. For OCI artifacts
+// the layers with a title, as pushed by conftest or ORAS, are saved as files
+// named by the title. Layers without a title are extracted if they hold a tar
+// archive, any other layer without a title fails the download instead of its
+// content being left out.
+func gatherSource(ctx context.Context, source, destination string) (metadata.Metadata, error) {
+	m, err := gatherFunc(ctx, source, destination)
+	if err != nil || !isOCI(source) {
+		return m, err
+	}
+
+	if err := extractArchiveLayers(ctx, source, m, destination); err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
+
+// extractArchiveLayers extracts the layers of the OCI artifact without a
+// title to the destination directory. The artifact is looked up by the digest
+// it was fetched at, so that it is the same artifact even if its tag has since
+// been moved.
+func extractArchiveLayers(ctx context.Context, source string, m metadata.Metadata, destination string) error {
+	ref, err := ociReference(source, m)
+	if err != nil || ref == nil {
+		return err
+	}
+
+	img, err := oci.NewClient(ctx).Image(ref)
+	if err != nil {
+		return fmt.Errorf("inspecting the OCI artifact %s: %w", source, err)
+	}
+
+	manifest, err := img.Manifest()
+	if err != nil {
+		return fmt.Errorf("inspecting the OCI artifact %s: %w", source, err)
+	}
+
+	for _, l := range manifest.Layers {
+		if _, ok := l.Annotations[titleAnnotation]; ok {
+			continue
+		}
+
+		if !slices.Contains(archiveMediaTypes, l.MediaType) {
+			return fmt.Errorf("unsupported media type %q of the layer %s of the OCI artifact %s, only layers with the %s annotation or holding a tar archive are supported",
+				l.MediaType, l.Digest, source, titleAnnotation)
+		}
+
+		layer, err := img.LayerByDigest(l.Digest)
+		if err != nil {
+			return err
+		}
+
+		log.Debugf("Extracting the layer %s of the OCI artifact %s", l.Digest, source)
+		if err := extractLayer(layer.Uncompressed, destination); err != nil {
+			return fmt.Errorf("extracting the layer %s of the OCI artifact %s: %w", l.Digest, source, err)
+		}
+	}
+
+	return nil
+}
+
+// ociReference returns the reference of the OCI artifact of the source URL,
+// pinned to the digest it was fetched at. Nil is returned if the digest is
+// not known.
+func ociReference(source string, m metadata.Metadata) (name.Reference, error) {
+	om, ok := m.(*ociMetadata.OCIMetadata)
+	if !ok || om.Digest == "" {
+		return nil, nil
+	}
+
+	ref := strings.TrimPrefix(strings.TrimPrefix(source, "oci::"), "oci://")
+	if _, r, found := strings.Cut(ref, "://"); found {
+		ref = r
+	}
+
+	parsed, err := name.ParseReference(ref)
+	if err != nil {
+		return nil, fmt.Errorf("invalid OCI artifact reference %q: %w", ref, err)
+	}
+
+	if d, ok := parsed.(name.Digest); ok && d.DigestStr() != om.Digest {
+		return nil, fmt.Errorf("the OCI artifact %s was fetched at the digest %s instead of the pinned digest", source, om.Digest)
+	}
+
+	return parsed.Context().Digest(om.Digest), nil
+}
+
+// extractLayer extracts the regular files and directories from the tar
+// archive of the layer to the destination directory
+func extractLayer(open func() (io.ReadCloser, error), destination string) error {
+	content, err := open()
+	if err != nil {
+		return err
+	}
+	defer content.Close()
+
+	archive := tar.NewReader(content)
+	for {
+		header, err := archive.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		p := filepath.Clean(header.Name)
+		if !filepath.IsLocal(p) {
+			return fmt.Errorf("invalid path %q within the archive", header.Name)
+		}
+		target := filepath.Join(destination, p)
+
+		switch header.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, 0o755); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+				return err
+			}
+			f, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o644)
+			if err != nil {
+				return err
+			}
+			_, err = io.Copy(f, archive)
+			if cerr := f.Close(); err == nil {
+				err = cerr
+			}
+			if err != nil {
+				return err
+			}
+		default:
+			log.Debugf("Skipping %s within the archive, not a regular file", header.Name)
+		}
+	}
+}
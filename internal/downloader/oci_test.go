@@ -0,0 +1,185 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package downloader
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/enterprise-contract/go-gather/metadata"
+	ociMetadata "github.com/enterprise-contract/go-gather/metadata/oci"
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/static"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/enterprise-contract/ec-cli/internal/utils/oci"
+	"github.com/enterprise-contract/ec-cli/internal/utils/oci/fake"
+)
+
+func archive(t *testing.T, files map[string]string) []byte {
+	var buf bytes.Buffer
+	w := tar.NewWriter(&buf)
+	for n, content := range files {
+		require.NoError(t, w.WriteHeader(&tar.Header{Name: n, Mode: 0o644, Size: int64(len(content)), Typeflag: tar.TypeReg}))
+		_, err := w.Write([]byte(content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, w.Close())
+
+	return buf.Bytes()
+}
+
+func TestGatherOCIArtifact(t *testing.T) {
+	originalGatherFunction := gatherFunc
+	t.Cleanup(func() {
+		gatherFunc = originalGatherFunction
+	})
+
+	titled := mutate.Addendum{
+		Layer:       static.NewLayer([]byte("package conftest"), "application/vnd.cncf.openpolicyagent.policy.layer.v1+rego"),
+		Annotations: map[string]string{titleAnnotation: "policy/conftest.rego"},
+	}
+
+	cases := []struct {
+		name     string
+		layers   []mutate.Addendum
+		source   func(v1.Hash) string
+		digest   func(v1.Hash) string
+		expected map[string]string
+		err      string
+	}{
+		{
+			name:   "titled layers",
+			layers: []mutate.Addendum{titled},
+		},
+		{
+			name: "archive layer",
+			layers: []mutate.Addendum{titled, {
+				Layer: static.NewLayer(archive(t, map[string]string{
+					"policy/bundle.rego": "package bundle",
+					"data/data.json":     "{}",
+				}), types.OCIUncompressedLayer),
+			}},
+			expected: map[string]string{
+				"policy/bundle.rego": "package bundle",
+				"data/data.json":     "{}",
+			},
+		},
+		{
+			name: "pinned digest",
+			layers: []mutate.Addendum{{
+				Layer: static.NewLayer(archive(t, map[string]string{"policy.rego": "package pinned"}), types.OCIUncompressedLayer),
+			}},
+			source: func(h v1.Hash) string {
+				return "oci::registry.io/repository/policy:1.0@" + h.String()
+			},
+			expected: map[string]string{"policy.rego": "package pinned"},
+		},
+		{
+			name:   "pinned digest mismatch",
+			layers: []mutate.Addendum{titled},
+			source: func(_ v1.Hash) string {
+				return "oci::registry.io/repository/policy@sha256:0000000000000000000000000000000000000000000000000000000000000000"
+			},
+			err: "was fetched at the digest sha256:",
+		},
+		{
+			name: "unsupported media type",
+			layers: []mutate.Addendum{{
+				Layer: static.NewLayer([]byte("?"), "application/vnd.example.unknown"),
+			}},
+			err: `unsupported media type "application/vnd.example.unknown" of the layer`,
+		},
+		{
+			name: "path outside of the destination",
+			layers: []mutate.Addendum{{
+				Layer: static.NewLayer(archive(t, map[string]string{"../evil.rego": "package evil"}), types.OCIUncompressedLayer),
+			}},
+			err: `invalid path "../evil.rego" within the archive`,
+		},
+		{
+			name:   "unknown digest",
+			layers: []mutate.Addendum{titled},
+			digest: func(_ v1.Hash) string { return "" },
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			img, err := mutate.Append(empty.Image, c.layers...)
+			require.NoError(t, err)
+			digest, err := img.Digest()
+			require.NoError(t, err)
+
+			source := "oci::registry.io/repository/policy:1.0"
+			if c.source != nil {
+				source = c.source(digest)
+			}
+			gathered := digest.String()
+			if c.digest != nil {
+				gathered = c.digest(digest)
+			}
+
+			gatherFunc = func(_ context.Context, _ string, _ string) (metadata.Metadata, error) {
+				return &ociMetadata.OCIMetadata{Digest: gathered}, nil
+			}
+
+			ref, err := name.NewDigest("registry.io/repository/policy@" + digest.String())
+			require.NoError(t, err)
+			client := fake.FakeClient{}
+			client.On("Image", ref).Return(img, nil)
+			ctx := oci.WithClient(context.Background(), &client)
+
+			dest := t.TempDir()
+			_, err = Download(ctx, dest, source, false)
+			if c.err != "" {
+				assert.ErrorContains(t, err, c.err)
+				return
+			}
+			require.NoError(t, err)
+
+			files := map[string]string{}
+			require.NoError(t, filepath.WalkDir(dest, func(p string, d os.DirEntry, err error) error {
+				if err != nil || d.IsDir() {
+					return err
+				}
+				rel, err := filepath.Rel(dest, p)
+				if err != nil {
+					return err
+				}
+				content, err := os.ReadFile(p)
+				files[rel] = string(content)
+				return err
+			}))
+			if c.expected == nil {
+				c.expected = map[string]string{}
+			}
+			assert.Equal(t, c.expected, files)
+		})
+	}
+}
@@ -0,0 +1,132 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package downloader
+
+import (
+	"context"
+	"errors"
+	"math"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/enterprise-contract/go-gather/metadata"
+
+	"github.com/enterprise-contract/ec-cli/internal/http"
+	"github.com/enterprise-contract/ec-cli/internal/logging"
+)
+
+// Retry configures how a failed download of a source is retried
+type Retry struct {
+	// Retries is the number of times a failed download is retried, none when
+	// zero
+	Retries int
+	// Backoff is the wait before the first retry, the wait grows exponentially
+	// with each subsequent retry by the factor and jitter of
+	// http.DefaultBackoff
+	Backoff time.Duration
+	// Timeout limits the duration of each attempt, not limited when zero
+	Timeout time.Duration
+}
+
+var (
+	retryMu      sync.Mutex
+	retryOptions = Retry{Backoff: http.DefaultBackoff.Duration}
+)
+
+// SetRetry sets how failed downloads of sources are retried
+func SetRetry(r Retry) {
+	retryMu.Lock()
+	defer retryMu.Unlock()
+
+	retryOptions = r
+}
+
+func currentRetry() Retry {
+	retryMu.Lock()
+	defer retryMu.Unlock()
+
+	return retryOptions
+}
+
+// wait returns how long to wait before the given retry, counted from zero
+func (r Retry) wait(retry int) time.Duration {
+	d := float64(r.Backoff) * math.Pow(http.DefaultBackoff.Factor, float64(retry))
+	jitter := http.DefaultBackoff.Jitter * (2*rand.Float64() - 1)
+
+	return time.Duration(d * (1 + jitter))
+}
+
+// withRetry invokes the download, retrying it when it fails according to the
+// current Retry configuration. Each attempt starts with an emptied destination
+// directory. The error of the last attempt is returned.
+func withRetry(ctx context.Context, sourceUrl, destDir string, download func(context.Context) (metadata.Metadata, error)) (metadata.Metadata, error) {
+	r := currentRetry()
+
+	for attempt := 0; ; attempt++ {
+		m, err := attemptWithTimeout(ctx, r.Timeout, download)
+		if err == nil || attempt >= r.Retries || ctx.Err() != nil {
+			return m, err
+		}
+
+		wait := r.wait(attempt)
+		log.Warnf("Unable to download %s, retrying in %s (%d/%d): %v", logging.RedactURL(sourceUrl), wait.Round(time.Millisecond), attempt+1, r.Retries, err)
+
+		if err := clearDir(destDir); err != nil {
+			return nil, err
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, errors.Join(err, ctx.Err())
+		case <-time.After(wait):
+		}
+	}
+}
+
+// attemptWithTimeout invokes the download once, limited to the timeout if set
+func attemptWithTimeout(ctx context.Context, timeout time.Duration, download func(context.Context) (metadata.Metadata, error)) (metadata.Metadata, error) {
+	if timeout <= 0 {
+		return download(ctx)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	return download(ctx)
+}
+
+// clearDir removes the content of the directory, leaving the directory in
+// place if it exists
+func clearDir(dir string) error {
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	for _, e := range entries {
+		if err := os.RemoveAll(filepath.Join(dir, e.Name())); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
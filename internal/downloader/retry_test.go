@@ -0,0 +1,142 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package downloader
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/enterprise-contract/go-gather/metadata"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRetryWait(t *testing.T) {
+	r := Retry{Backoff: time.Second}
+
+	for retry, expected := range []time.Duration{time.Second, 2 * time.Second, 4 * time.Second} {
+		wait := r.wait(retry)
+		assert.InDelta(t, float64(expected), float64(wait), float64(expected)/10)
+	}
+}
+
+func TestDownloadRetries(t *testing.T) {
+	originalGatherFunction := gatherFunc
+	t.Cleanup(func() {
+		gatherFunc = originalGatherFunction
+		SetRetry(Retry{Backoff: time.Second})
+	})
+
+	failure := errors.New("connection reset")
+
+	cases := []struct {
+		name     string
+		retries  int
+		failures int
+		attempts int
+		err      error
+	}{
+		{name: "no retries", retries: 0, failures: 1, attempts: 1, err: failure},
+		{name: "recovers", retries: 2, failures: 2, attempts: 3},
+		{name: "exhausted", retries: 2, failures: 5, attempts: 3, err: failure},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			SetRetry(Retry{Retries: c.retries, Backoff: time.Millisecond})
+
+			dest := t.TempDir()
+			attempts := 0
+			gatherFunc = func(_ context.Context, _ string, destination string) (metadata.Metadata, error) {
+				attempts++
+				// Every attempt starts with an empty destination
+				entries, err := os.ReadDir(destination)
+				require.NoError(t, err)
+				assert.Empty(t, entries)
+
+				if err := os.WriteFile(filepath.Join(destination, "partial"), nil, 0o600); err != nil {
+					return nil, err
+				}
+
+				if attempts <= c.failures {
+					return nil, failure
+				}
+				return nil, nil
+			}
+
+			_, err := Download(context.Background(), dest, "git::https://example.com/org/repo", false)
+			assert.Equal(t, c.attempts, attempts)
+			if c.err != nil {
+				assert.ErrorIs(t, err, c.err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestDownloadAttemptTimeout(t *testing.T) {
+	originalGatherFunction := gatherFunc
+	t.Cleanup(func() {
+		gatherFunc = originalGatherFunction
+		SetRetry(Retry{Backoff: time.Second})
+	})
+
+	SetRetry(Retry{Retries: 1, Backoff: time.Millisecond, Timeout: 10 * time.Millisecond})
+
+	attempts := 0
+	gatherFunc = func(ctx context.Context, _ string, _ string) (metadata.Metadata, error) {
+		attempts++
+		if attempts == 1 {
+			<-ctx.Done()
+			return nil, ctx.Err()
+		}
+		return nil, nil
+	}
+
+	_, err := Download(context.Background(), t.TempDir(), "git::https://example.com/org/repo", false)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, attempts)
+}
+
+func TestDownloadRetryCanceled(t *testing.T) {
+	originalGatherFunction := gatherFunc
+	t.Cleanup(func() {
+		gatherFunc = originalGatherFunction
+		SetRetry(Retry{Backoff: time.Second})
+	})
+
+	SetRetry(Retry{Retries: 5, Backoff: time.Hour})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	attempts := 0
+	gatherFunc = func(_ context.Context, _ string, _ string) (metadata.Metadata, error) {
+		attempts++
+		cancel()
+		return nil, errors.New("failure")
+	}
+
+	_, err := Download(ctx, t.TempDir(), "git::https://example.com/org/repo", false)
+	assert.EqualError(t, err, "failure")
+	assert.Equal(t, 1, attempts)
+}
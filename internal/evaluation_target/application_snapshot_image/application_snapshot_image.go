@@ -24,6 +24,8 @@ import (
 	"fmt"
 	"os"
 	"path"
+	"sync"
+	"time"
 
 	"github.com/google/go-containerregistry/pkg/name"
 	app "github.com/konflux-ci/application-api/api/v1alpha1"
@@ -36,6 +38,7 @@ import (
 	"github.com/enterprise-contract/ec-cli/internal/evaluator"
 	"github.com/enterprise-contract/ec-cli/internal/fetchers/oci/config"
 	"github.com/enterprise-contract/ec-cli/internal/fetchers/oci/files"
+	"github.com/enterprise-contract/ec-cli/internal/fetchers/oci/sbom"
 	"github.com/enterprise-contract/ec-cli/internal/policy"
 	"github.com/enterprise-contract/ec-cli/internal/signature"
 	"github.com/enterprise-contract/ec-cli/internal/utils"
@@ -47,10 +50,17 @@ var attestationSchemas = map[string]*jsonschema.Schema{
 	"https://slsa.dev/provenance/v0.2": schema.SLSA_Provenance_v0_2,
 }
 
+// predicateSchemas are the schemas of the predicates, rather than of the whole
+// statements, by predicate type
+var predicateSchemas = map[string]*jsonschema.Schema{
+	attestation.PredicateCycloneDX: schema.CycloneDX_BOM_v1_6,
+}
+
 // ApplicationSnapshotImage represents the structure needed to evaluate an Application Snapshot Image
 type ApplicationSnapshotImage struct {
 	reference        name.Reference
 	checkOpts        cosign.CheckOpts
+	requireRekor     bool
 	signatures       []signature.EntitySignature
 	configJSON       json.RawMessage
 	parentConfigJSON json.RawMessage
@@ -58,6 +68,7 @@ type ApplicationSnapshotImage struct {
 	attestations     []attestation.Attestation
 	Evaluators       []evaluator.Evaluator
 	files            map[string]json.RawMessage
+	sboms            []sbom.SBOM
 	component        app.SnapshotComponent
 	snapshot         app.SnapshotSpec
 }
@@ -73,9 +84,27 @@ func NewApplicationSnapshotImage(ctx context.Context, component app.SnapshotComp
 		return nil, err
 	}
 	a := &ApplicationSnapshotImage{
-		checkOpts: *opts,
-		component: component,
-		snapshot:  snap,
+		checkOpts:    *opts,
+		requireRekor: p.RequireRekor(),
+		component:    component,
+		snapshot:     snap,
+	}
+
+	if err := a.SetImageURL(component.ContainerImage); err != nil {
+		return nil, err
+	}
+
+	return a, nil
+}
+
+// NewOfflineApplicationSnapshotImage returns an ApplicationSnapshotImage with
+// the given image config, for evaluating the policy without accessing the
+// registry.
+func NewOfflineApplicationSnapshotImage(component app.SnapshotComponent, snap app.SnapshotSpec, config json.RawMessage) (*ApplicationSnapshotImage, error) {
+	a := &ApplicationSnapshotImage{
+		configJSON: config,
+		component:  component,
+		snapshot:   snap,
 	}
 
 	if err := a.SetImageURL(component.ContainerImage); err != nil {
@@ -110,6 +139,7 @@ func (a *ApplicationSnapshotImage) SetImageURL(url string) error {
 	// Reset internal state relevant to the image
 	a.attestations = []attestation.Attestation{}
 	a.signatures = []signature.EntitySignature{}
+	a.sboms = nil
 
 	return nil
 }
@@ -120,6 +150,12 @@ func (a *ApplicationSnapshotImage) FetchImageConfig(ctx context.Context) error {
 	return err
 }
 
+// ImageCreated returns the creation time of the image as recorded in its
+// config, or nil if the config holds none.
+func (a *ApplicationSnapshotImage) ImageCreated(ctx context.Context) (*time.Time, error) {
+	return config.FetchImageCreated(ctx, a.reference)
+}
+
 func (a *ApplicationSnapshotImage) FetchParentImageConfig(ctx context.Context) error {
 	var err error
 	a.parentRef, err = config.FetchParentImage(ctx, a.reference)
@@ -137,17 +173,44 @@ func (a *ApplicationSnapshotImage) FetchImageFiles(ctx context.Context) error {
 	return err
 }
 
+// FetchSBOMs discovers the SBOMs attached to the image via the OCI referrers
+// API and the cosign .sbom tag convention. The SBOMs found are kept even when
+// fetching some of the others fails, with the error returned.
+func (a *ApplicationSnapshotImage) FetchSBOMs(ctx context.Context) error {
+	digest, err := a.digest(ctx)
+	if err != nil {
+		return err
+	}
+
+	a.sboms, err = sbom.Discover(ctx, digest)
+	return err
+}
+
 // ValidateImageSignature executes the cosign.VerifyImageSignature method on the ApplicationSnapshotImage image ref.
+// The Notation signatures are verified instead when the Notation verifier is
+// selected, see signature.WithVerifier.
 func (a *ApplicationSnapshotImage) ValidateImageSignature(ctx context.Context) error {
+	if signature.Verifier(ctx) == signature.NotationVerifier {
+		return a.validateNotationSignature(ctx)
+	}
+
 	// Set the ClaimVerifier on a shallow *copy* of CheckOpts to avoid unexpected side-effects
 	opts := a.checkOpts
 	opts.ClaimVerifier = cosign.SimpleClaimVerifier
 	signatures, _, err := oci.NewClient(ctx).VerifyImageSignatures(a.reference, &opts)
 	if err != nil {
-		return err
+		if signatures, err = a.bundledSignatures(ctx, err, false); err != nil {
+			return err
+		}
 	}
 
 	for _, s := range signatures {
+		if a.requireRekor {
+			if s, err = signature.VerifyInTransparencyLog(ctx, s, &a.checkOpts); err != nil {
+				return err
+			}
+		}
+
 		es, err := signature.NewEntitySignature(s)
 		if err != nil {
 			return err
@@ -158,20 +221,57 @@ func (a *ApplicationSnapshotImage) ValidateImageSignature(ctx context.Context) e
 	return nil
 }
 
+// validateNotationSignature verifies the Notation signatures of the image in
+// place of the cosign signatures.
+func (a *ApplicationSnapshotImage) validateNotationSignature(ctx context.Context) error {
+	digest, err := a.digest(ctx)
+	if err != nil {
+		return err
+	}
+
+	signatures, err := signature.VerifyNotation(ctx, digest)
+	if err != nil {
+		return err
+	}
+	a.signatures = append(a.signatures, signatures...)
+
+	return nil
+}
+
 // ValidateAttestationSignature executes the cosign.VerifyImageAttestations method
 func (a *ApplicationSnapshotImage) ValidateAttestationSignature(ctx context.Context) error {
 	// Set the ClaimVerifier on a shallow *copy* of CheckOpts to avoid unexpected side-effects
 	opts := a.checkOpts
-	opts.ClaimVerifier = cosign.IntotoSubjectClaimVerifier
+	var mismatches subjectMismatches
+	manifest := sync.OnceValues(func() ([]byte, error) {
+		img, err := oci.NewClient(ctx).Image(a.reference)
+		if err != nil {
+			return nil, err
+		}
+		return img.RawManifest()
+	})
+	opts.ClaimVerifier = mismatches.verifier(subjectClaimVerifier(manifest))
 
 	layers, _, err := oci.NewClient(ctx).VerifyImageAttestations(a.reference, &opts)
 	if err != nil {
-		return err
+		if layers, err = a.bundledSignatures(ctx, err, true); err != nil {
+			return mismatches.wrap(err, a.reference.Identifier())
+		}
+	}
+
+	if n := mismatches.count(); n > 0 {
+		log.Warnf("Ignored attestations of image %s for %d other subject(s), the registry may have served stale content", a.reference, n)
 	}
 
 	// Extract the signatures from the attestations here in order to also validate that
 	// the signatures do exist in the expected format.
 	for _, sig := range layers {
+		if a.requireRekor {
+			if sig, err = signature.VerifyInTransparencyLog(ctx, sig, &a.checkOpts); err != nil {
+				return err
+			}
+		}
+
 		att, err := attestation.ProvenanceFromSignature(sig)
 		if err != nil {
 			return fmt.Errorf("unable to parse untyped provenance: %w", err)
@@ -190,13 +290,33 @@ func (a *ApplicationSnapshotImage) ValidateAttestationSignature(ctx context.Cont
 			}
 			a.attestations = append(a.attestations, sp)
 
+		case attestation.PredicateSLSAProvenanceV1:
+			sp, err := attestation.SLSAProvenanceV1FromSignature(sig)
+			if err != nil {
+				return fmt.Errorf("unable to parse as SLSA v1.0: %w", err)
+			}
+			a.attestations = append(a.attestations, sp)
+
+		case attestation.PredicateOpenVEX, attestation.PredicateOpenVEXv020:
+			v, err := attestation.OpenVEXFromSignature(sig)
+			if err != nil {
+				return fmt.Errorf("unable to parse as OpenVEX: %w", err)
+			}
+			a.attestations = append(a.attestations, v)
+
 		case attestation.PredicateSpdxDocument:
-			// It's an SPDX format SBOM
-			// Todo maybe: We could unmarshal it into a suitable SPDX struct
-			// similar to how it's done for SLSA above
-			a.attestations = append(a.attestations, att)
+			sb, err := attestation.SPDXSBOMFromSignature(sig)
+			if err != nil {
+				return fmt.Errorf("unable to parse as SPDX: %w", err)
+			}
+			a.attestations = append(a.attestations, sb)
 
-		// Todo: CycloneDX format SBOM
+		case attestation.PredicateCycloneDX:
+			sb, err := attestation.CycloneDXSBOMFromSignature(sig)
+			if err != nil {
+				return fmt.Errorf("unable to parse as CycloneDX: %w", err)
+			}
+			a.attestations = append(a.attestations, sb)
 
 		default:
 			// It's some other kind of attestation
@@ -219,7 +339,9 @@ func (a ApplicationSnapshotImage) ValidateAttestationSyntax(ctx context.Context)
 	var validationErr error
 	for _, sp := range a.attestations {
 		pt := sp.PredicateType()
-		if schema, ok := attestationSchemas[pt]; ok {
+		schema, ok := attestationSchemas[pt]
+		predicateSchema, predicateOk := predicateSchemas[pt]
+		if ok || predicateOk {
 			// Found a validator for this predicate type so let's use it
 			log.Debugf("Attempting to validate an attestation with predicateType %s", pt)
 
@@ -228,7 +350,15 @@ func (a ApplicationSnapshotImage) ValidateAttestationSyntax(ctx context.Context)
 				return fmt.Errorf("unable to decode attestation data from attestation image: %w", err)
 			}
 
-			if err := schema.Validate(statement); err != nil {
+			validated := statement
+			if predicateOk {
+				schema = predicateSchema
+				if m, isObject := statement.(map[string]any); isObject {
+					validated = m["predicate"]
+				}
+			}
+
+			if err := schema.Validate(validated); err != nil {
 				if _, ok = err.(*jsonschema.ValidationError); !ok {
 					// Error while trying to validate
 					return fmt.Errorf("unable to validate attestation data from attestation image: %w", err)
@@ -273,6 +403,12 @@ func (a *ApplicationSnapshotImage) ResolveDigest(ctx context.Context) (string, e
 type attestationData struct {
 	Statement  json.RawMessage             `json:"statement"`
 	Signatures []signature.EntitySignature `json:"signatures,omitempty"`
+	// Provenance holds the normalized details of SLSA Provenance statements
+	Provenance *attestation.ProvenanceSummary `json:"provenance,omitempty"`
+	// SBOM holds the normalized details of SPDX and CycloneDX statements
+	SBOM *attestation.SBOMSummary `json:"sbom,omitempty"`
+	// VEX holds the normalized statements of OpenVEX documents
+	VEX *attestation.VEXSummary `json:"vex,omitempty"`
 }
 
 // MarshalJSON returns a JSON representation of the attestationData. It is customized to take into
@@ -310,6 +446,48 @@ func (a attestationData) MarshalJSON() ([]byte, error) {
 		}
 	}
 
+	if a.Provenance != nil {
+		_, err = buffy.WriteString(`, "provenance":`)
+		if err != nil {
+			return nil, fmt.Errorf("write provenance key: %w", err)
+		}
+		provenance, err := json.Marshal(a.Provenance)
+		if err != nil {
+			return nil, fmt.Errorf("marshal json provenance: %w", err)
+		}
+		if _, err := buffy.Write(provenance); err != nil {
+			return nil, fmt.Errorf("write provenance value: %w", err)
+		}
+	}
+
+	if a.SBOM != nil {
+		_, err = buffy.WriteString(`, "sbom":`)
+		if err != nil {
+			return nil, fmt.Errorf("write sbom key: %w", err)
+		}
+		sbom, err := json.Marshal(a.SBOM)
+		if err != nil {
+			return nil, fmt.Errorf("marshal json sbom: %w", err)
+		}
+		if _, err := buffy.Write(sbom); err != nil {
+			return nil, fmt.Errorf("write sbom value: %w", err)
+		}
+	}
+
+	if a.VEX != nil {
+		_, err = buffy.WriteString(`, "vex":`)
+		if err != nil {
+			return nil, fmt.Errorf("write vex key: %w", err)
+		}
+		vex, err := json.Marshal(a.VEX)
+		if err != nil {
+			return nil, fmt.Errorf("marshal json vex: %w", err)
+		}
+		if _, err := buffy.Write(vex); err != nil {
+			return nil, fmt.Errorf("write vex value: %w", err)
+		}
+	}
+
 	if err := buffy.WriteByte('}'); err != nil {
 		return nil, fmt.Errorf("close json: %w", err)
 	}
@@ -324,6 +502,9 @@ type image struct {
 	Parent     any                         `json:"parent,omitempty"`
 	Files      map[string]json.RawMessage  `json:"files,omitempty"`
 	Source     any                         `json:"source,omitempty"`
+	// SBOMs holds the SBOMs of the image, from the attestations and attached
+	// to the image, regardless of their format
+	SBOMs []sbom.SBOM `json:"sboms,omitempty"`
 }
 
 type Input struct {
@@ -338,10 +519,23 @@ func (a *ApplicationSnapshotImage) WriteInputFile(ctx context.Context) (string,
 
 	var attestations []attestationData
 	for _, a := range a.attestations {
-		attestations = append(attestations, attestationData{
+		data := attestationData{
 			Statement:  a.Statement(),
 			Signatures: a.Signatures(),
-		})
+		}
+		if p, ok := a.(attestation.Provenance); ok {
+			summary := p.Summary()
+			data.Provenance = &summary
+		}
+		if sb, ok := a.(attestation.SBOM); ok {
+			summary := sb.Summary()
+			data.SBOM = &summary
+		}
+		if v, ok := a.(attestation.VEX); ok {
+			summary := v.Summary()
+			data.VEX = &summary
+		}
+		attestations = append(attestations, data)
 	}
 
 	input := Input{
@@ -352,6 +546,7 @@ func (a *ApplicationSnapshotImage) WriteInputFile(ctx context.Context) (string,
 			Config:     a.configJSON,
 			Files:      a.files,
 			Source:     a.component.Source,
+			SBOMs:      append(sbom.FromAttestations(a.attestations), a.sboms...),
 		},
 		AppSnapshot: a.snapshot,
 	}
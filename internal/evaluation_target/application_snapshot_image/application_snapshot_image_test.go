@@ -24,6 +24,7 @@ import (
 	"encoding/base64"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"regexp"
 	"strings"
 	"testing"
@@ -32,6 +33,7 @@ import (
 	"github.com/google/go-containerregistry/pkg/crane"
 	"github.com/google/go-containerregistry/pkg/name"
 	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
 	"github.com/google/go-containerregistry/pkg/v1/mutate"
 	"github.com/google/go-containerregistry/pkg/v1/types"
 	"github.com/in-toto/in-toto-golang/in_toto"
@@ -50,6 +52,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/enterprise-contract/ec-cli/internal/attestation"
+	"github.com/enterprise-contract/ec-cli/internal/fetchers/oci/sbom"
 	"github.com/enterprise-contract/ec-cli/internal/policy"
 	"github.com/enterprise-contract/ec-cli/internal/signature"
 	"github.com/enterprise-contract/ec-cli/internal/utils"
@@ -93,6 +96,47 @@ func (f fakeAtt) Subject() []in_toto.Subject {
 	return []in_toto.Subject{}
 }
 
+// fakeProvenance is a fakeAtt exposing the normalized SLSA Provenance
+type fakeProvenance struct {
+	fakeAtt
+}
+
+func (f fakeProvenance) Summary() attestation.ProvenanceSummary {
+	return attestation.ProvenanceSummary{
+		BuilderID: f.statement.Predicate.Builder.ID,
+		BuildType: f.statement.Predicate.BuildType,
+		ResolvedDependencies: []attestation.ResolvedDependency{
+			{URI: "git+https://github.com/org/repo", Digest: map[string]string{"sha1": "abc"}},
+		},
+	}
+}
+
+// fakeSBOM is a rawAtt exposing the normalized SBOM
+type fakeSBOM struct {
+	rawAtt
+}
+
+func (f fakeSBOM) Summary() attestation.SBOMSummary {
+	return attestation.SBOMSummary{
+		Format:      "cyclonedx",
+		SpecVersion: "1.5",
+		Packages:    []attestation.SBOMPackage{{Name: "spam", Version: "1.0", PURL: "pkg:rpm/spam@1.0"}},
+	}
+}
+
+// fakeVEX is a rawAtt exposing the normalized OpenVEX statements
+type fakeVEX struct {
+	rawAtt
+}
+
+func (f fakeVEX) Summary() attestation.VEXSummary {
+	return attestation.VEXSummary{
+		Statements: []attestation.VEXStatement{
+			{Vulnerability: "CVE-2024-0001", Products: []string{"pkg:oci/image"}, Status: "not_affected", Justification: "vulnerable_code_not_present"},
+		},
+	}
+}
+
 type opts func(*fakeAtt)
 
 func createSimpleAttestation(statement *in_toto.ProvenanceStatementSLSA02, o ...opts) attestation.Attestation {
@@ -192,6 +236,51 @@ func TestWriteInputFile(t *testing.T) {
 				})},
 			},
 		},
+		{
+			name: "provenance attestation",
+			snapshot: ApplicationSnapshotImage{
+				reference: name.MustParseReference("registry.io/repository/image:tag"),
+				attestations: []attestation.Attestation{fakeProvenance{
+					fakeAtt: createSimpleAttestation(nil).(fakeAtt),
+				}},
+			},
+		},
+		{
+			name: "sbom attestation",
+			snapshot: ApplicationSnapshotImage{
+				reference: name.MustParseReference("registry.io/repository/image:tag"),
+				attestations: []attestation.Attestation{fakeSBOM{rawAtt{
+					predicateType: attestation.PredicateCycloneDX,
+					statement:     `{"_type": "https://in-toto.io/Statement/v0.1", "predicateType": "https://cyclonedx.org/bom", "predicate": {}}`,
+				}}},
+			},
+		},
+		{
+			name: "attached sboms",
+			snapshot: ApplicationSnapshotImage{
+				reference: name.MustParseReference("registry.io/repository/image:tag"),
+				sboms: []sbom.SBOM{{
+					Source:    sbom.SourceReferrer,
+					Ref:       "registry.io/repository/image@sha256:" + strings.Repeat("a", 64),
+					MediaType: "application/spdx+json",
+					SBOMSummary: attestation.SBOMSummary{
+						Format:      attestation.SBOMFormatSPDX,
+						SpecVersion: "SPDX-2.3",
+						Packages:    []attestation.SBOMPackage{{Name: "ham", Version: "2.0"}},
+					},
+				}},
+			},
+		},
+		{
+			name: "vex attestation",
+			snapshot: ApplicationSnapshotImage{
+				reference: name.MustParseReference("registry.io/repository/image:tag"),
+				attestations: []attestation.Attestation{fakeVEX{rawAtt{
+					predicateType: attestation.PredicateOpenVEX,
+					statement:     `{"_type": "https://in-toto.io/Statement/v0.1", "predicateType": "https://openvex.dev/ns", "predicate": {}}`,
+				}}},
+			},
+		},
 		{
 			name: "component with source",
 			snapshot: ApplicationSnapshotImage{
@@ -416,6 +505,47 @@ func TestSyntaxValidation(t *testing.T) {
 	}
 }
 
+// rawAtt is an attestation of any predicate type with the given statement
+type rawAtt struct {
+	predicateType string
+	statement     string
+}
+
+func (r rawAtt) Type() string                            { return in_toto.StatementInTotoV01 }
+func (r rawAtt) PredicateType() string                   { return r.predicateType }
+func (r rawAtt) Statement() []byte                       { return []byte(r.statement) }
+func (r rawAtt) Signatures() []signature.EntitySignature { return nil }
+func (r rawAtt) Subject() []in_toto.Subject              { return nil }
+
+func TestSyntaxValidationCycloneDX(t *testing.T) {
+	cases := []struct {
+		name      string
+		predicate string
+		err       string
+	}{
+		{name: "valid", predicate: `{"bomFormat": "CycloneDX", "specVersion": "1.5", "components": [{"type": "library", "name": "spam"}]}`},
+		{name: "invalid", predicate: `{"bomFormat": "CycloneDX", "specVersion": "1.5", "components": [{"type": "library"}]}`, err: "attestation syntax validation failed: .*missing properties: 'name'"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			a := ApplicationSnapshotImage{
+				attestations: []attestation.Attestation{rawAtt{
+					predicateType: attestation.PredicateCycloneDX,
+					statement:     fmt.Sprintf(`{"_type": "https://in-toto.io/Statement/v0.1", "predicateType": "https://cyclonedx.org/bom", "predicate": %s}`, c.predicate),
+				}},
+			}
+
+			err := a.ValidateAttestationSyntax(context.TODO())
+			if c.err == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.Regexp(t, c.err, err)
+			}
+		})
+	}
+}
+
 func TestValidateImageSignatureClaims(t *testing.T) {
 	ref := name.MustParseReference("registry.io/repository/image:tag")
 	a := ApplicationSnapshotImage{
@@ -756,3 +886,39 @@ kind: ClusterServiceVersion`),
 		"manifests/csv.yaml": json.RawMessage(`{"apiVersion":"operators.coreos.com/v1alpha1","kind":"ClusterServiceVersion"}`),
 	}, a.files)
 }
+
+func TestValidateAttestationSignatureStale(t *testing.T) {
+	const digest = "sha256:4e388ab32b10dc8dbc7e28144f552830adc74787c1e2c0824032078a79f227fb"
+	ref := name.MustParseReference("registry.io/repository/image@" + digest)
+	a := ApplicationSnapshotImage{
+		reference: ref,
+	}
+
+	statement, err := json.Marshal(in_toto.Statement{
+		StatementHeader: in_toto.StatementHeader{
+			Subject: []in_toto.Subject{
+				{Digest: map[string]string{"sha256": "dead10cc"}},
+			},
+		},
+	})
+	require.NoError(t, err)
+	payload, err := json.Marshal(dsse.Envelope{Payload: base64.StdEncoding.EncodeToString(statement)})
+	require.NoError(t, err)
+	stale, err := static.NewSignature(payload, "signature")
+	require.NoError(t, err)
+
+	c := fake.FakeClient{}
+	ctx := o.WithClient(context.Background(), &c)
+
+	c.On("VerifyImageAttestations", ref, mock.Anything).Run(func(args mock.Arguments) {
+		checkOpts := args.Get(1).(*cosign.CheckOpts)
+		h, err := v1.NewHash(digest)
+		require.NoError(t, err)
+		assert.EqualError(t, checkOpts.ClaimVerifier(stale, h, nil), "no matching subject digest found")
+	}).Return([]oci.Signature{}, false, &cosign.ErrNoMatchingAttestations{})
+	c.On("Referrers", ref, "").Return(empty.Index, nil)
+
+	err = a.ValidateAttestationSignature(ctx)
+	assert.ErrorIs(t, err, ErrStaleAttestation)
+	assert.ErrorContains(t, err, "found attestations for sha256:dead10cc instead of "+digest+", the registry may have served stale content")
+}
@@ -0,0 +1,483 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package application_snapshot_image
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/sigstore/cosign/v2/pkg/cosign"
+	cosignbundle "github.com/sigstore/cosign/v2/pkg/cosign/bundle"
+	"github.com/sigstore/cosign/v2/pkg/oci"
+	"github.com/sigstore/cosign/v2/pkg/oci/static"
+	"github.com/sigstore/sigstore-go/pkg/bundle"
+	"github.com/sigstore/sigstore-go/pkg/fulcio/certificate"
+	"github.com/sigstore/sigstore-go/pkg/root"
+	"github.com/sigstore/sigstore-go/pkg/verify"
+	"github.com/sigstore/sigstore/pkg/tuf"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
+
+	ecoci "github.com/enterprise-contract/ec-cli/internal/utils/oci"
+)
+
+// SigstoreBundleMediaType is the prefix of the media types of the Sigstore
+// bundle format, e.g. application/vnd.dev.sigstore.bundle.v0.3+json, used as
+// the artifact type of bundles attached to images as OCI referrers.
+const SigstoreBundleMediaType = "application/vnd.dev.sigstore.bundle"
+
+// cosignSignPredicateType is the predicate type of the in-toto statements
+// cosign signs images with when writing Sigstore bundles.
+const cosignSignPredicateType = "https://sigstore.dev/cosign/sign/v1"
+
+// trustedRootTarget is the name of the TUF target holding the Sigstore trusted
+// root.
+const trustedRootTarget = "trusted_root.json"
+
+// SigstoreBundleOptions configures the verification of images signed with the
+// Sigstore bundle format.
+type SigstoreBundleOptions struct {
+	// Bundles are the detached bundles, used in addition to the bundles
+	// attached to the images. Only the bundles for the digest of an image are
+	// used for it.
+	Bundles []*bundle.ProtobufBundle
+	// TrustedRoot holds the Fulcio, Rekor, CT log and timestamp authority keys
+	// to verify the bundles with. When nil, the trusted root from the TUF root
+	// is used.
+	TrustedRoot *root.TrustedRoot
+}
+
+type contextKey string
+
+const sigstoreBundleOptionsKey contextKey = "ec.application_snapshot_image.sigstore_bundle_options"
+
+// WithSigstoreBundleOptions returns a context in which the given options are
+// used to verify the Sigstore bundles of images.
+func WithSigstoreBundleOptions(ctx context.Context, opts SigstoreBundleOptions) context.Context {
+	return context.WithValue(ctx, sigstoreBundleOptionsKey, opts)
+}
+
+func sigstoreBundleOptions(ctx context.Context) SigstoreBundleOptions {
+	if opts, ok := ctx.Value(sigstoreBundleOptionsKey).(SigstoreBundleOptions); ok {
+		return opts
+	}
+
+	return SigstoreBundleOptions{}
+}
+
+// LoadSigstoreBundles reads the Sigstore bundles from the files at the given
+// paths.
+func LoadSigstoreBundles(fs afero.Fs, paths []string) ([]*bundle.ProtobufBundle, error) {
+	bundles := make([]*bundle.ProtobufBundle, 0, len(paths))
+	for _, path := range paths {
+		content, err := afero.ReadFile(fs, path)
+		if err != nil {
+			return nil, err
+		}
+
+		var b bundle.ProtobufBundle
+		if err := b.UnmarshalJSON(content); err != nil {
+			return nil, fmt.Errorf("reading Sigstore bundle from %s: %w", path, err)
+		}
+		bundles = append(bundles, &b)
+	}
+
+	return bundles, nil
+}
+
+// LoadTrustedRoot reads the Sigstore trusted root from the file at the given
+// path.
+func LoadTrustedRoot(fs afero.Fs, path string) (*root.TrustedRoot, error) {
+	content, err := afero.ReadFile(fs, path)
+	if err != nil {
+		return nil, err
+	}
+
+	trustedRoot, err := root.NewTrustedRootFromJSON(content)
+	if err != nil {
+		return nil, fmt.Errorf("reading Sigstore trusted root from %s: %w", path, err)
+	}
+
+	return trustedRoot, nil
+}
+
+// noSignatures returns true if the error is cosign not finding any valid
+// signatures, or attestations, of the image, in which case the signatures of
+// the image in the Sigstore bundle format are used.
+func noSignatures(err error) bool {
+	var noSignaturesFound *cosign.ErrNoSignaturesFound
+	var noMatchingSignatures *cosign.ErrNoMatchingSignatures
+	var noMatchingAttestations *cosign.ErrNoMatchingAttestations
+	return errors.As(err, &noSignaturesFound) ||
+		errors.As(err, &noMatchingSignatures) ||
+		errors.As(err, &noMatchingAttestations)
+}
+
+// bundledSignatures returns the signatures, or attestations, of the image in
+// the Sigstore bundle format when cosign did not find any signatures of the
+// image, otherwise the error of cosign is returned. The error of cosign is also
+// returned if there are no Sigstore bundles of the image, while the error
+// verifying them is returned if there are.
+func (a *ApplicationSnapshotImage) bundledSignatures(ctx context.Context, cosignErr error, attestations bool) ([]oci.Signature, error) {
+	if !noSignatures(cosignErr) {
+		return nil, cosignErr
+	}
+
+	signatures, err := a.verifySigstoreBundles(ctx, &a.checkOpts, attestations)
+	if err != nil {
+		log.Debugf("Unable to verify the Sigstore bundles of %s: %v", a.reference, err)
+		return nil, err
+	}
+	if len(signatures) == 0 {
+		return nil, cosignErr
+	}
+
+	return signatures, nil
+}
+
+// sigstoreBundles returns the Sigstore bundles for the image, the bundles
+// attached to the image as OCI referrers followed by the detached bundles for
+// its digest. With attestations only the bundles holding attestations are
+// returned, otherwise only those holding image signatures, see
+// isImageSignature.
+func (a *ApplicationSnapshotImage) sigstoreBundles(ctx context.Context, digest name.Digest, attestations bool) ([]*bundle.ProtobufBundle, error) {
+	client := ecoci.NewClient(ctx)
+
+	var bundles []*bundle.ProtobufBundle
+	index, err := client.Referrers(digest, "")
+	if err != nil {
+		return nil, err
+	}
+	manifest, err := index.IndexManifest()
+	if err != nil {
+		return nil, err
+	}
+	for _, m := range manifest.Manifests {
+		if !strings.HasPrefix(m.ArtifactType, SigstoreBundleMediaType) {
+			continue
+		}
+
+		attached, err := attachedBundles(client, digest.Context().Digest(m.Digest.String()))
+		if err != nil {
+			return nil, fmt.Errorf("fetching the Sigstore bundle %s: %w", m.Digest, err)
+		}
+		bundles = append(bundles, attached...)
+	}
+
+	for _, b := range sigstoreBundleOptions(ctx).Bundles {
+		if bundleFor(b, digest.DigestStr()) {
+			bundles = append(bundles, b)
+		}
+	}
+
+	matching := make([]*bundle.ProtobufBundle, 0, len(bundles))
+	for _, b := range bundles {
+		if isImageSignature(b) != attestations {
+			matching = append(matching, b)
+		}
+	}
+
+	return matching, nil
+}
+
+// attachedBundles returns the Sigstore bundles held in the layers of the
+// referrer manifest.
+func attachedBundles(client ecoci.Client, ref name.Digest) ([]*bundle.ProtobufBundle, error) {
+	img, err := client.Image(ref)
+	if err != nil {
+		return nil, err
+	}
+
+	layers, err := img.Layers()
+	if err != nil {
+		return nil, err
+	}
+
+	var bundles []*bundle.ProtobufBundle
+	for _, l := range layers {
+		mt, err := l.MediaType()
+		if err != nil {
+			return nil, err
+		}
+		if !strings.HasPrefix(string(mt), SigstoreBundleMediaType) {
+			continue
+		}
+
+		rc, err := l.Uncompressed()
+		if err != nil {
+			return nil, err
+		}
+		var content json.RawMessage
+		err = json.NewDecoder(rc).Decode(&content)
+		rc.Close()
+		if err != nil {
+			return nil, err
+		}
+
+		var b bundle.ProtobufBundle
+		if err := b.UnmarshalJSON(content); err != nil {
+			return nil, err
+		}
+		bundles = append(bundles, &b)
+	}
+
+	return bundles, nil
+}
+
+// isImageSignature returns true if the bundle holds a signature of the image,
+// i.e. a message signature or a statement of the cosign sign predicate type,
+// rather than an attestation.
+func isImageSignature(b *bundle.ProtobufBundle) bool {
+	if b.GetMessageSignature() != nil {
+		return true
+	}
+
+	envelope, err := b.Envelope()
+	if err != nil {
+		return false
+	}
+	statement, err := envelope.Statement()
+	if err != nil {
+		return false
+	}
+
+	return statement.PredicateType == cosignSignPredicateType
+}
+
+// bundleFor returns true if the bundle is for the given digest, i.e. the
+// digest of the message signature, or one of the subjects of the attestation,
+// is the given digest.
+func bundleFor(b *bundle.ProtobufBundle, digest string) bool {
+	algorithm, hexDigest, _ := strings.Cut(digest, ":")
+
+	if m := b.GetMessageSignature(); m != nil {
+		return algorithm == "sha256" && hex.EncodeToString(m.GetMessageDigest().GetDigest()) == hexDigest
+	}
+
+	envelope, err := b.Envelope()
+	if err != nil {
+		return false
+	}
+	statement, err := envelope.Statement()
+	if err != nil {
+		return false
+	}
+	for _, s := range statement.Subject {
+		if s.Digest[algorithm] == hexDigest {
+			return true
+		}
+	}
+
+	return false
+}
+
+// verifySigstoreBundles verifies the Sigstore bundles for the image, see
+// sigstoreBundles, with the keys and the identity of the check options. The
+// bundles are verified offline, using the Rekor entries within them. The
+// returned signatures hold the verified signatures, or attestations.
+func (a *ApplicationSnapshotImage) verifySigstoreBundles(ctx context.Context, opts *cosign.CheckOpts, attestations bool) ([]oci.Signature, error) {
+	digest, err := a.digest(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	bundles, err := a.sigstoreBundles(ctx, digest, attestations)
+	if err != nil {
+		return nil, err
+	}
+	if len(bundles) == 0 {
+		return nil, nil
+	}
+
+	verifier, policyOpts, err := bundleVerifier(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	artifactDigest, err := hex.DecodeString(strings.TrimPrefix(digest.DigestStr(), "sha256:"))
+	if err != nil {
+		return nil, err
+	}
+
+	signatures := make([]oci.Signature, 0, len(bundles))
+	for _, b := range bundles {
+		if _, err := verifier.Verify(b, verify.NewPolicy(verify.WithArtifactDigest("sha256", artifactDigest), policyOpts...)); err != nil {
+			return nil, fmt.Errorf("verifying the Sigstore bundle of %s: %w", digest, err)
+		}
+
+		sig, err := bundleSignature(b)
+		if err != nil {
+			return nil, err
+		}
+		signatures = append(signatures, sig)
+	}
+	log.Debugf("Verified %d Sigstore bundle(s) of %s", len(signatures), digest)
+
+	return signatures, nil
+}
+
+// digest returns the reference of the image by its digest.
+func (a *ApplicationSnapshotImage) digest(ctx context.Context) (name.Digest, error) {
+	if d, ok := a.reference.(name.Digest); ok {
+		return d, nil
+	}
+
+	resolved, err := ecoci.NewClient(ctx).ResolveDigest(a.reference)
+	if err != nil {
+		return name.Digest{}, err
+	}
+
+	return a.reference.Context().Digest(resolved), nil
+}
+
+// keyTrustedMaterial is the trusted root with the public key of the
+// long-lived key workflow.
+type keyTrustedMaterial struct {
+	root.TrustedMaterial
+	key *root.ExpiringKey
+}
+
+func (k keyTrustedMaterial) PublicKeyVerifier(string) (root.TimeConstrainedVerifier, error) {
+	return k.key, nil
+}
+
+// bundleVerifier returns the verifier of the bundles and the options of the
+// verification policy, based on the check options: the public key of the
+// long-lived key workflow or the certificate identity of the keyless workflow,
+// and whether the Rekor transparency log is ignored.
+func bundleVerifier(ctx context.Context, opts *cosign.CheckOpts) (*verify.SignedEntityVerifier, []verify.PolicyOption, error) {
+	trustedRoot := sigstoreBundleOptions(ctx).TrustedRoot
+	if trustedRoot == nil {
+		t, err := tuf.NewFromEnv(ctx)
+		if err != nil {
+			return nil, nil, fmt.Errorf("initializing the TUF client: %w", err)
+		}
+		content, err := t.GetTarget(trustedRootTarget)
+		if err != nil {
+			return nil, nil, fmt.Errorf("fetching the Sigstore trusted root from the TUF root: %w", err)
+		}
+		if trustedRoot, err = root.NewTrustedRootFromJSON(content); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	var material root.TrustedMaterial = trustedRoot
+	var verifierOpts []verify.VerifierOption
+	var policyOpts []verify.PolicyOption
+	if opts.SigVerifier != nil {
+		material = keyTrustedMaterial{trustedRoot, root.NewExpiringKey(opts.SigVerifier, time.Time{}, time.Time{})}
+		policyOpts = append(policyOpts, verify.WithKey())
+	} else {
+		verifierOpts = append(verifierOpts, verify.WithSignedCertificateTimestamps(1))
+		for _, id := range opts.Identities {
+			sans, err := verify.NewSANMatcher(id.Subject, id.SubjectRegExp)
+			if err != nil {
+				return nil, nil, err
+			}
+			issuer, err := verify.NewIssuerMatcher(id.Issuer, id.IssuerRegExp)
+			if err != nil {
+				return nil, nil, err
+			}
+			identity, err := verify.NewCertificateIdentity(sans, issuer, certificate.Extensions{})
+			if err != nil {
+				return nil, nil, err
+			}
+			policyOpts = append(policyOpts, verify.WithCertificateIdentity(identity))
+		}
+	}
+
+	if opts.IgnoreTlog {
+		verifierOpts = append(verifierOpts, verify.WithoutAnyObserverTimestampsUnsafe())
+	} else {
+		verifierOpts = append(verifierOpts, verify.WithTransparencyLog(1), verify.WithIntegratedTimestamps(1))
+	}
+
+	verifier, err := verify.NewSignedEntityVerifier(material, verifierOpts...)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return verifier, policyOpts, nil
+}
+
+// bundleSignature returns the signature, or the attestation, held in the
+// verified bundle in the form of the signatures attached by cosign, i.e. with
+// the DSSE envelope as the payload of attestations, and the Rekor entry of the
+// bundle as the Rekor bundle.
+func bundleSignature(b *bundle.ProtobufBundle) (oci.Signature, error) {
+	var opts []static.Option
+
+	vc, err := b.VerificationContent()
+	if err != nil {
+		return nil, err
+	}
+	if cert := vc.GetCertificate(); cert != nil {
+		opts = append(opts, static.WithCertChain(pem.EncodeToMemory(&pem.Block{
+			Type:  "CERTIFICATE",
+			Bytes: cert.Raw,
+		}), nil))
+	}
+
+	if entries := b.GetVerificationMaterial().GetTlogEntries(); len(entries) > 0 {
+		e := entries[0]
+		opts = append(opts, static.WithBundle(&cosignbundle.RekorBundle{
+			SignedEntryTimestamp: e.GetInclusionPromise().GetSignedEntryTimestamp(),
+			Payload: cosignbundle.RekorPayload{
+				Body:           base64.StdEncoding.EncodeToString(e.GetCanonicalizedBody()),
+				IntegratedTime: e.GetIntegratedTime(),
+				LogIndex:       e.GetLogIndex(),
+				LogID:          hex.EncodeToString(e.GetLogId().GetKeyId()),
+			},
+		}))
+	}
+
+	if m := b.GetMessageSignature(); m != nil {
+		return static.NewSignature(nil, base64.StdEncoding.EncodeToString(m.GetSignature()), opts...)
+	}
+
+	envelope, err := b.Envelope()
+	if err != nil {
+		return nil, err
+	}
+
+	if isImageSignature(b) {
+		raw := envelope.RawEnvelope()
+		if len(raw.Signatures) == 0 {
+			return nil, errors.New("no signatures in the DSSE envelope of the Sigstore bundle")
+		}
+		payload, err := base64.StdEncoding.DecodeString(raw.Payload)
+		if err != nil {
+			return nil, err
+		}
+		return static.NewSignature(payload, raw.Signatures[0].Sig, opts...)
+	}
+
+	payload, err := json.Marshal(envelope.RawEnvelope())
+	if err != nil {
+		return nil, err
+	}
+
+	return static.NewAttestation(payload, opts...)
+}
@@ -0,0 +1,245 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package application_snapshot_image
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/in-toto/in-toto-golang/in_toto"
+	"github.com/sigstore/cosign/v2/pkg/cosign"
+	"github.com/sigstore/cosign/v2/pkg/oci"
+	protobundle "github.com/sigstore/protobuf-specs/gen/pb-go/bundle/v1"
+	protocommon "github.com/sigstore/protobuf-specs/gen/pb-go/common/v1"
+	protodsse "github.com/sigstore/protobuf-specs/gen/pb-go/dsse"
+	"github.com/sigstore/sigstore-go/pkg/bundle"
+	"github.com/sigstore/sigstore-go/pkg/root"
+	"github.com/sigstore/sigstore/pkg/signature"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	o "github.com/enterprise-contract/ec-cli/internal/utils/oci"
+	"github.com/enterprise-contract/ec-cli/internal/utils/oci/fake"
+)
+
+const bundleMediaType = "application/vnd.dev.sigstore.bundle.v0.3+json"
+
+func messageSignatureBundle(t *testing.T, digest []byte, sig []byte) *bundle.ProtobufBundle {
+	b, err := bundle.NewProtobufBundle(&protobundle.Bundle{
+		MediaType: bundleMediaType,
+		VerificationMaterial: &protobundle.VerificationMaterial{
+			Content: &protobundle.VerificationMaterial_PublicKey{
+				PublicKey: &protocommon.PublicKeyIdentifier{Hint: "key"},
+			},
+		},
+		Content: &protobundle.Bundle_MessageSignature{
+			MessageSignature: &protocommon.MessageSignature{
+				MessageDigest: &protocommon.HashOutput{
+					Algorithm: protocommon.HashAlgorithm_SHA2_256,
+					Digest:    digest,
+				},
+				Signature: sig,
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	return b
+}
+
+func dsseBundle(t *testing.T, predicateType string, subjectDigest string) *bundle.ProtobufBundle {
+	statement, err := json.Marshal(in_toto.Statement{
+		StatementHeader: in_toto.StatementHeader{
+			Type:          in_toto.StatementInTotoV01,
+			PredicateType: predicateType,
+			Subject: []in_toto.Subject{
+				{Name: "registry.io/repository/image", Digest: map[string]string{"sha256": subjectDigest}},
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	b, err := bundle.NewProtobufBundle(&protobundle.Bundle{
+		MediaType: bundleMediaType,
+		VerificationMaterial: &protobundle.VerificationMaterial{
+			Content: &protobundle.VerificationMaterial_PublicKey{
+				PublicKey: &protocommon.PublicKeyIdentifier{Hint: "key"},
+			},
+		},
+		Content: &protobundle.Bundle_DsseEnvelope{
+			DsseEnvelope: &protodsse.Envelope{
+				Payload:     statement,
+				PayloadType: "application/vnd.in-toto+json",
+				Signatures:  []*protodsse.Signature{{Sig: []byte("signature")}},
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	return b
+}
+
+func TestLoadSigstoreBundles(t *testing.T) {
+	fs := afero.NewMemMapFs()
+
+	b := messageSignatureBundle(t, []byte("digest"), []byte("signature"))
+	content, err := b.MarshalJSON()
+	require.NoError(t, err)
+	require.NoError(t, afero.WriteFile(fs, "bundle.json", content, 0o644))
+	require.NoError(t, afero.WriteFile(fs, "invalid.json", []byte(`{"mediaType": "application/vnd.dev.sigstore.bundle.v0.9+json"}`), 0o644))
+
+	bundles, err := LoadSigstoreBundles(fs, []string{"bundle.json"})
+	require.NoError(t, err)
+	require.Len(t, bundles, 1)
+	assert.Equal(t, []byte("signature"), bundles[0].GetMessageSignature().GetSignature())
+
+	_, err = LoadSigstoreBundles(fs, []string{"invalid.json"})
+	assert.ErrorContains(t, err, "reading Sigstore bundle from invalid.json")
+
+	_, err = LoadSigstoreBundles(fs, []string{"missing.json"})
+	assert.Error(t, err)
+}
+
+func TestBundleFor(t *testing.T) {
+	digest := sha256.Sum256([]byte("image"))
+	hexDigest := hex.EncodeToString(digest[:])
+
+	cases := []struct {
+		name     string
+		bundle   *bundle.ProtobufBundle
+		expected bool
+	}{
+		{
+			name:     "message signature",
+			bundle:   messageSignatureBundle(t, digest[:], []byte("signature")),
+			expected: true,
+		},
+		{
+			name:     "message signature of other digest",
+			bundle:   messageSignatureBundle(t, []byte("other"), []byte("signature")),
+			expected: false,
+		},
+		{
+			name:     "attestation",
+			bundle:   dsseBundle(t, "https://slsa.dev/provenance/v1", hexDigest),
+			expected: true,
+		},
+		{
+			name:     "attestation of other subject",
+			bundle:   dsseBundle(t, "https://slsa.dev/provenance/v1", "dead10cc"),
+			expected: false,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			assert.Equal(t, c.expected, bundleFor(c.bundle, "sha256:"+hexDigest))
+		})
+	}
+}
+
+func TestIsImageSignature(t *testing.T) {
+	assert.True(t, isImageSignature(messageSignatureBundle(t, []byte("digest"), []byte("signature"))))
+	assert.True(t, isImageSignature(dsseBundle(t, cosignSignPredicateType, "dead10cc")))
+	assert.False(t, isImageSignature(dsseBundle(t, "https://slsa.dev/provenance/v1", "dead10cc")))
+}
+
+func TestValidateImageSignatureSigstoreBundle(t *testing.T) {
+	digest := sha256.Sum256([]byte("image"))
+	ref, err := name.NewDigest("registry.io/repository/image@sha256:" + hex.EncodeToString(digest[:]))
+	require.NoError(t, err)
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	sig, err := ecdsa.SignASN1(rand.Reader, key, digest[:])
+	require.NoError(t, err)
+	verifier, err := signature.LoadVerifier(key.Public(), crypto.SHA256)
+	require.NoError(t, err)
+
+	cases := []struct {
+		name    string
+		bundles []*bundle.ProtobufBundle
+		err     string
+		noSigs  bool
+	}{
+		{
+			name:    "verified bundle",
+			bundles: []*bundle.ProtobufBundle{messageSignatureBundle(t, digest[:], sig)},
+		},
+		{
+			name:    "bundle with invalid signature",
+			bundles: []*bundle.ProtobufBundle{messageSignatureBundle(t, digest[:], []byte("signature"))},
+			err:     "verifying the Sigstore bundle of " + ref.String(),
+		},
+		{
+			name:    "no bundle for the image",
+			bundles: []*bundle.ProtobufBundle{messageSignatureBundle(t, []byte("other"), sig)},
+			noSigs:  true,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			client := fake.FakeClient{}
+			client.On("VerifyImageSignatures", ref, mock.Anything).Return([]oci.Signature{}, false, &cosign.ErrNoMatchingSignatures{})
+			client.On("Referrers", ref, "").Return(empty.Index, nil)
+
+			ctx := o.WithClient(context.Background(), &client)
+			ctx = WithSigstoreBundleOptions(ctx, SigstoreBundleOptions{
+				Bundles:     c.bundles,
+				TrustedRoot: &root.TrustedRoot{},
+			})
+
+			a := ApplicationSnapshotImage{
+				reference: ref,
+				checkOpts: cosign.CheckOpts{
+					SigVerifier: verifier,
+					IgnoreTlog:  true,
+				},
+			}
+
+			err := a.ValidateImageSignature(ctx)
+			if c.noSigs {
+				var noMatchingSignatures *cosign.ErrNoMatchingSignatures
+				assert.ErrorAs(t, err, &noMatchingSignatures)
+				return
+			}
+			if c.err != "" {
+				assert.ErrorContains(t, err, c.err)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Len(t, a.signatures, 1)
+			assert.Equal(t, base64.StdEncoding.EncodeToString(sig), a.signatures[0].Signature)
+		})
+	}
+}
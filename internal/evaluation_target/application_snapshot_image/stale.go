@@ -0,0 +1,148 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package application_snapshot_image
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/in-toto/in-toto-golang/in_toto"
+	"github.com/secure-systems-lab/go-securesystemslib/dsse"
+	"github.com/sigstore/cosign/v2/pkg/oci"
+)
+
+// ErrStaleAttestation is returned when none of the attestations fetched for an
+// image are about the image, i.e. the digests of the attestation subjects do
+// not match the digest of the image. This happens when a registry, e.g. a
+// pull-through cache, serves stale content for the attestation tag.
+var ErrStaleAttestation = errors.New("the attestations are not about the image, their subject digests do not match the image digest")
+
+// claimVerifier has the signature of cosign.CheckOpts.ClaimVerifier
+type claimVerifier func(sig oci.Signature, imageDigest v1.Hash, annotations map[string]any) error
+
+// subjectMismatches collects the subject digests of attestations rejected by
+// the claim verifier for not matching the image digest.
+type subjectMismatches struct {
+	mu      sync.Mutex
+	digests map[string]bool
+}
+
+// verifier verifies the attestation claims using the given verifier and
+// records the subject digests of any attestation that is not about the image.
+// Attestations are verified concurrently.
+func (m *subjectMismatches) verifier(verifier claimVerifier) claimVerifier {
+	return func(sig oci.Signature, imageDigest v1.Hash, annotations map[string]any) error {
+		err := verifier(sig, imageDigest, annotations)
+		if err == nil {
+			return nil
+		}
+
+		digests, perr := subjectDigests(sig)
+		if perr != nil || len(digests) == 0 {
+			return err
+		}
+
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		if m.digests == nil {
+			m.digests = map[string]bool{}
+		}
+		for _, d := range digests {
+			m.digests[d] = true
+		}
+
+		return err
+	}
+}
+
+// wrap returns an error explaining that the attestations are stale if any
+// mismatched subjects were recorded, or the given error as is.
+func (m *subjectMismatches) wrap(err error, imageDigest string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if len(m.digests) == 0 {
+		return err
+	}
+
+	digests := make([]string, 0, len(m.digests))
+	for d := range m.digests {
+		digests = append(digests, d)
+	}
+	sort.Strings(digests)
+
+	return fmt.Errorf("%w: found attestations for %s instead of %s, the registry may have served stale content, e.g. from a pull-through cache: %w",
+		ErrStaleAttestation, strings.Join(digests, ", "), imageDigest, err)
+}
+
+// count returns the number of distinct mismatched subject digests
+func (m *subjectMismatches) count() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return len(m.digests)
+}
+
+// subjectDigests returns the sha256 digests of the subjects of the in-toto
+// statement within the DSSE envelope of the attestation.
+func subjectDigests(sig oci.Signature) ([]string, error) {
+	statement, err := statementFromSignature(sig)
+	if err != nil {
+		return nil, err
+	}
+
+	var digests []string
+	for _, s := range statement.Subject {
+		if d, ok := s.Digest["sha256"]; ok {
+			digests = append(digests, "sha256:"+d)
+		}
+	}
+
+	return digests, nil
+}
+
+// statementFromSignature returns the in-toto statement within the DSSE
+// envelope of the attestation.
+func statementFromSignature(sig oci.Signature) (in_toto.Statement, error) {
+	payload, err := sig.Payload()
+	if err != nil {
+		return in_toto.Statement{}, err
+	}
+
+	var envelope dsse.Envelope
+	if err := json.Unmarshal(payload, &envelope); err != nil {
+		return in_toto.Statement{}, err
+	}
+
+	data, err := base64.StdEncoding.DecodeString(envelope.Payload)
+	if err != nil {
+		return in_toto.Statement{}, err
+	}
+
+	var statement in_toto.Statement
+	if err := json.Unmarshal(data, &statement); err != nil {
+		return in_toto.Statement{}, err
+	}
+
+	return statement, nil
+}
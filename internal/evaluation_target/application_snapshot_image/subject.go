@@ -0,0 +1,112 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package application_snapshot_image
+
+import (
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"strings"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/sigstore/cosign/v2/pkg/oci"
+)
+
+// errNoMatchingSubject is returned by the subject claim verifier, it matches
+// the error of cosign.IntotoSubjectClaimVerifier.
+var errNoMatchingSubject = errors.New("no matching subject digest found")
+
+// digestAlgorithms are the algorithms of the subject digests which can be
+// matched against the image by computing the digest of the image manifest.
+var digestAlgorithms = map[string]func() hash.Hash{
+	"sha256": sha256.New,
+	"sha384": sha512.New384,
+	"sha512": sha512.New,
+}
+
+// subjectClaimVerifier returns a claim verifier matching the subjects of the
+// in-toto statement of an attestation against the image. Unlike
+// cosign.IntotoSubjectClaimVerifier, a subject listing the digest of the image
+// only under a different algorithm than that of the image digest, e.g. sha512,
+// also matches. The digest is then computed from the image manifest returned
+// by manifest, which is only called when no subject matches the image digest
+// as is.
+func subjectClaimVerifier(manifest func() ([]byte, error)) claimVerifier {
+	return func(sig oci.Signature, imageDigest v1.Hash, _ map[string]any) error {
+		statement, err := statementFromSignature(sig)
+		if err != nil {
+			return err
+		}
+
+		for _, s := range statement.Subject {
+			if d, ok := s.Digest[imageDigest.Algorithm]; ok && d == imageDigest.Hex {
+				return nil
+			}
+		}
+
+		var content []byte
+		for _, s := range statement.Subject {
+			for algorithm, d := range s.Digest {
+				if _, ok := digestAlgorithms[algorithm]; !ok || algorithm == imageDigest.Algorithm {
+					continue
+				}
+
+				if content == nil {
+					if content, err = verifiedManifest(manifest, imageDigest); err != nil {
+						return fmt.Errorf("unable to compute the %s digest of the image: %w", algorithm, err)
+					}
+				}
+
+				if digestOf(algorithm, content) == strings.ToLower(d) {
+					return nil
+				}
+			}
+		}
+
+		return errNoMatchingSubject
+	}
+}
+
+// verifiedManifest returns the image manifest after checking that it is the
+// manifest with the given digest.
+func verifiedManifest(manifest func() ([]byte, error), imageDigest v1.Hash) ([]byte, error) {
+	if _, ok := digestAlgorithms[imageDigest.Algorithm]; !ok {
+		return nil, fmt.Errorf("unsupported digest algorithm %q of the image digest", imageDigest.Algorithm)
+	}
+
+	content, err := manifest()
+	if err != nil {
+		return nil, err
+	}
+
+	if digestOf(imageDigest.Algorithm, content) != imageDigest.Hex {
+		return nil, fmt.Errorf("the image manifest does not match the image digest %s", imageDigest)
+	}
+
+	return content, nil
+}
+
+// digestOf returns the hex encoded digest of the content using the given
+// algorithm, which must be one of digestAlgorithms.
+func digestOf(algorithm string, content []byte) string {
+	h := digestAlgorithms[algorithm]()
+	h.Write(content)
+	return hex.EncodeToString(h.Sum(nil))
+}
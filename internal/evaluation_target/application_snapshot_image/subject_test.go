@@ -0,0 +1,129 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package application_snapshot_image
+
+import (
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/in-toto/in-toto-golang/in_toto"
+	"github.com/secure-systems-lab/go-securesystemslib/dsse"
+	"github.com/sigstore/cosign/v2/pkg/oci/static"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSubjectClaimVerifier(t *testing.T) {
+	manifest := []byte(`{"schemaVersion":2}`)
+	sum256 := sha256.Sum256(manifest)
+	sum512 := sha512.Sum512(manifest)
+	imageDigest := v1.Hash{Algorithm: "sha256", Hex: hex.EncodeToString(sum256[:])}
+	sha512Hex := hex.EncodeToString(sum512[:])
+
+	cases := []struct {
+		name     string
+		subject  map[string]string
+		manifest []byte
+		fetched  bool
+		err      string
+	}{
+		{
+			name:    "same algorithm",
+			subject: map[string]string{"sha256": imageDigest.Hex, "sha512": "dead10cc"},
+		},
+		{
+			name:     "different algorithm",
+			subject:  map[string]string{"sha512": sha512Hex},
+			manifest: manifest,
+			fetched:  true,
+		},
+		{
+			name:     "different algorithm mismatch",
+			subject:  map[string]string{"sha512": "dead10cc"},
+			manifest: manifest,
+			fetched:  true,
+			err:      "no matching subject digest found",
+		},
+		{
+			name:    "unsupported algorithm",
+			subject: map[string]string{"md5": "dead10cc"},
+			err:     "no matching subject digest found",
+		},
+		{
+			name:     "manifest of another image",
+			subject:  map[string]string{"sha512": sha512Hex},
+			manifest: []byte(`{"schemaVersion":2,"other":true}`),
+			fetched:  true,
+			err:      "unable to compute the sha512 digest of the image: the image manifest does not match the image digest " + imageDigest.String(),
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			statement, err := json.Marshal(in_toto.Statement{
+				StatementHeader: in_toto.StatementHeader{
+					Subject: []in_toto.Subject{{Digest: c.subject}},
+				},
+			})
+			require.NoError(t, err)
+			payload, err := json.Marshal(dsse.Envelope{Payload: base64.StdEncoding.EncodeToString(statement)})
+			require.NoError(t, err)
+			sig, err := static.NewSignature(payload, "signature")
+			require.NoError(t, err)
+
+			fetched := false
+			verifier := subjectClaimVerifier(func() ([]byte, error) {
+				fetched = true
+				return c.manifest, nil
+			})
+
+			err = verifier(sig, imageDigest, nil)
+			if c.err == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, c.err)
+			}
+			assert.Equal(t, c.fetched, fetched)
+		})
+	}
+
+	t.Run("manifest not available", func(t *testing.T) {
+		statement, err := json.Marshal(in_toto.Statement{
+			StatementHeader: in_toto.StatementHeader{
+				Subject: []in_toto.Subject{{Digest: map[string]string{"sha512": sha512Hex}}},
+			},
+		})
+		require.NoError(t, err)
+		payload, err := json.Marshal(dsse.Envelope{Payload: base64.StdEncoding.EncodeToString(statement)})
+		require.NoError(t, err)
+		sig, err := static.NewSignature(payload, "signature")
+		require.NoError(t, err)
+
+		verifier := subjectClaimVerifier(func() ([]byte, error) {
+			return nil, errors.New("expected")
+		})
+		assert.EqualError(t, verifier(sig, imageDigest, nil), "unable to compute the sha512 digest of the image: expected")
+	})
+}
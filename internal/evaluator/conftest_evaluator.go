@@ -19,16 +19,22 @@ package evaluator
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"maps"
 	"net/url"
 	"os"
 	"path"
 	"path/filepath"
+	"slices"
+	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	ecc "github.com/enterprise-contract/enterprise-contract-controller/api/v1alpha1"
 	"github.com/open-policy-agent/conftest/output"
+	"github.com/open-policy-agent/conftest/parser"
 	conftest "github.com/open-policy-agent/conftest/policy"
 	"github.com/open-policy-agent/conftest/runner"
 	"github.com/open-policy-agent/opa/ast"
@@ -37,19 +43,27 @@ import (
 	"github.com/spf13/afero"
 	"k8s.io/apimachinery/pkg/util/sets"
 
+	"github.com/enterprise-contract/ec-cli/internal/logging"
 	"github.com/enterprise-contract/ec-cli/internal/opa"
 	"github.com/enterprise-contract/ec-cli/internal/opa/rule"
 	"github.com/enterprise-contract/ec-cli/internal/policy"
 	"github.com/enterprise-contract/ec-cli/internal/policy/source"
+	"github.com/enterprise-contract/ec-cli/internal/tracing"
 	"github.com/enterprise-contract/ec-cli/internal/utils"
 )
 
 type contextKey string
 
 const (
-	runnerKey        contextKey = "ec.evaluator.runner"
-	capabilitiesKey  contextKey = "ec.evaluator.capabilities"
-	effectiveTimeKey contextKey = "ec.evaluator.effective_time"
+	runnerKey            contextKey = "ec.evaluator.runner"
+	capabilitiesKey      contextKey = "ec.evaluator.capabilities"
+	effectiveTimeKey     contextKey = "ec.evaluator.effective_time"
+	strictDataKey        contextKey = "ec.evaluator.strict_data_sources"
+	strictInputKey       contextKey = "ec.evaluator.strict_input"
+	ruleEffectiveOnKey   contextKey = "ec.evaluator.rule_effective_on"
+	severityOverridesKey contextKey = "ec.evaluator.severity_overrides"
+	explainKey           contextKey = "ec.evaluator.explain"
+	coverageKey          contextKey = "ec.evaluator.coverage"
 )
 
 // trim removes all failure, warning, success or skipped results that depend on
@@ -160,6 +174,32 @@ func excludeDirectives(code string, rawTerm any) string {
 	return fmt.Sprintf("%s%s", prefix, strings.Join(output, ", "))
 }
 
+// ErrNoApplicableRules is returned from Evaluate when none of the policy rules
+// were applicable to the evaluation target.
+var ErrNoApplicableRules = errors.New("no successes, warnings, or failures, check input")
+
+// ErrMissingRuleData is returned from Evaluate when rule data required by one
+// or more of the included policy rules was not provided.
+var ErrMissingRuleData = errors.New("missing required rule data")
+
+// ErrEmptyDataSource is returned, in strict mode, when a data source provided
+// no data, most likely due to a wrong URL.
+var ErrEmptyDataSource = errors.New("data source provided no data")
+
+// emptyDataSources holds the URLs of the data sources that were reported as
+// empty, so each is reported once rather than for every evaluation
+var emptyDataSources sync.Map
+
+// WithStrictDataSources returns a context in which evaluators fail on data
+// sources that provided no data, instead of logging a warning.
+func WithStrictDataSources(ctx context.Context) context.Context {
+	return context.WithValue(ctx, strictDataKey, true)
+}
+
+// ruleDataNamespaces lists the keys within the data document where rule data
+// is looked up, see internal/policy/source.
+var ruleDataNamespaces = []string{"rule_data__configuration__", "rule_data_custom", "rule_data"}
+
 type testRunner interface {
 	Run(context.Context, []string) ([]Outcome, Data, error)
 }
@@ -175,6 +215,7 @@ const (
 	metadataSolution    = "solution"
 	metadataTerm        = "term"
 	metadataTitle       = "title"
+	metadataVerbose     = "verbose"
 )
 
 // ConfigProvider is a subset of the policy.Policy interface. Its purpose is to codify which parts
@@ -197,23 +238,97 @@ type conftestEvaluator struct {
 	exclude       *Criteria
 	fs            afero.Fs
 	namespace     []string
+	engine        *engineCache
+	wasm          *wasmEngine
+	// severityOverrides are the overrides configured in the rule data of the
+	// source group
+	severityOverrides SeverityOverrides
 }
 
 type conftestRunner struct {
 	runner.TestRunner
+	engine *engineCache
+}
+
+// engineCache holds the policy engine with the compiled policy modules and the
+// loaded data. The policy does not change between evaluation targets, so the
+// engine is loaded once and shared by all evaluations performed by the
+// evaluator.
+type engineCache struct {
+	once   sync.Once
+	engine *conftest.Engine
+	err    error
+}
+
+// load returns the engine, loading it on first use. Subsequent and concurrent
+// calls return the same engine. The engine is safe for concurrent use: the
+// compiled modules are not modified once loaded, and each query evaluates
+// within its own transaction on the store. The only data written to the store
+// by the engine is data.conftest.file, which, when evaluating concurrently,
+// might not describe the input being evaluated.
+func (e *engineCache) load(ctx context.Context, r runner.TestRunner) (*conftest.Engine, error) {
+	e.once.Do(func() {
+		_, span := tracing.Start(ctx, "compile")
+		defer func() { tracing.End(span, e.err) }()
+
+		e.engine, e.err = conftest.LoadWithData(r.Policy, r.Data, r.Capabilities, r.Strict)
+		if e.err != nil {
+			e.err = fmt.Errorf("load: %w", e.err)
+			return
+		}
+
+		if r.Trace {
+			e.engine.EnableTracing()
+		}
+	})
+
+	return e.engine, e.err
 }
 
+// Run evaluates the given files, or files within the given directories, using
+// the shared engine. This needs to remain the same as runner.TestRunner's Run
+// function apart from loading the engine.
 func (r conftestRunner) Run(ctx context.Context, fileList []string) (result []Outcome, data Data, err error) {
 	if log.IsLevelEnabled(log.TraceLevel) {
 		r.Trace = true
 	}
 
-	var conftestResult []output.CheckResult
-	conftestResult, err = r.TestRunner.Run(ctx, fileList)
+	var engine *conftest.Engine
+	engine, err = r.engine.load(ctx, r.TestRunner)
+	if err != nil {
+		return
+	}
+
+	var files []string
+	files, err = listFiles(fileList)
 	if err != nil {
+		err = fmt.Errorf("parse files: %w", err)
 		return
 	}
 
+	var configurations map[string]any
+	configurations, err = parser.ParseConfigurations(files)
+	if err != nil {
+		err = fmt.Errorf("parse configurations: %w", err)
+		return
+	}
+
+	namespaces := r.Namespace
+	if r.AllNamespaces {
+		namespaces = engine.Namespaces()
+	}
+
+	var conftestResult []output.CheckResult
+	for _, namespace := range namespaces {
+		var checkResult []output.CheckResult
+		checkResult, err = engine.Check(ctx, configurations, namespace)
+		if err != nil {
+			err = fmt.Errorf("query rule: %w", err)
+			return
+		}
+		conftestResult = append(conftestResult, checkResult...)
+	}
+
 	for _, res := range conftestResult {
 		if log.IsLevelEnabled(log.TraceLevel) {
 			for _, q := range res.Queries {
@@ -243,15 +358,6 @@ func (r conftestRunner) Run(ctx context.Context, fileList []string) (result []Ou
 		})
 	}
 
-	// we can't reference the engine from the test runner or from the results so
-	// we need to recreate it, this needs to remain the same as in
-	// runner.TestRunner's Run function
-	var engine *conftest.Engine
-	engine, err = conftest.LoadWithData(r.Policy, r.Data, r.Capabilities, r.Strict)
-	if err != nil {
-		return
-	}
-
 	store := engine.Store()
 
 	var txn storage.Transaction
@@ -259,23 +365,73 @@ func (r conftestRunner) Run(ctx context.Context, fileList []string) (result []Ou
 	if err != nil {
 		return
 	}
-
-	ids := []string{} // everything
+	defer store.Abort(ctx, txn)
 
 	var d any
-	d, err = store.Read(ctx, txn, ids)
+	d, err = store.Read(ctx, txn, storage.Path{})
 	if err != nil {
 		return
 	}
 
-	var ok bool
-	if data, ok = d.(map[string]any); !ok {
+	all, ok := d.(map[string]any)
+	if !ok {
 		err = fmt.Errorf("could not retrieve data from the policy engine: Data is: %v", d)
+		return
+	}
+
+	// The file information is written to the shared store by each check, it is
+	// not part of the policy data.
+	data = make(Data, len(all))
+	for k, v := range all {
+		if k != "conftest" {
+			data[k] = v
+		}
 	}
 
 	return
 }
 
+// listFiles returns the given files, and the supported files within the given
+// directories.
+func listFiles(fileList []string) ([]string, error) {
+	var files []string
+	for _, file := range fileList {
+		if file == "" {
+			continue
+		}
+
+		info, err := os.Stat(file)
+		if err != nil {
+			return nil, fmt.Errorf("get file info: %w", err)
+		}
+
+		if !info.IsDir() {
+			files = append(files, file)
+			continue
+		}
+
+		if err := filepath.Walk(file, func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				return fmt.Errorf("walk path: %w", err)
+			}
+
+			if !info.IsDir() && parser.FileSupported(path) {
+				files = append(files, path)
+			}
+
+			return nil
+		}); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(files) == 0 {
+		return nil, errors.New("no files found")
+	}
+
+	return files, nil
+}
+
 // NewConftestEvaluator returns initialized conftestEvaluator implementing
 // Evaluator interface
 func NewConftestEvaluator(ctx context.Context, policySources []source.PolicySource, p ConfigProvider, source ecc.Source) (Evaluator, error) {
@@ -291,10 +447,18 @@ func NewConftestEvaluatorWithNamespace(ctx context.Context, policySources []sour
 		policy:        p,
 		fs:            fs,
 		namespace:     namespace,
+		engine:        &engineCache{},
+		wasm:          &wasmEngine{},
 	}
 
 	c.include, c.exclude = computeIncludeExclude(source, p)
 
+	severity, err := sourceSeverityConfig(source)
+	if err != nil {
+		return nil, err
+	}
+	c.severityOverrides = severity.Overrides
+
 	dir, err := utils.CreateWorkDir(fs)
 	if err != nil {
 		log.Debug("Failed to create work dir!")
@@ -363,8 +527,26 @@ func (c conftestEvaluator) Evaluate(ctx context.Context, target EvaluationTarget
 	// exist with the same code in two separate sources the collected rule
 	// information is not deterministic
 	rules := policyRules{}
+	// the policy sources evaluated using Rego and the ones compiled to
+	// WebAssembly
+	var regoDirs, wasmDirs []string
+	// the packages evaluated by remote OPA servers
+	var servers []opaServer
+	// the rules of the CUE files of the policy sources
+	var cue []cueRule
 	// Download all sources
 	for _, s := range c.policySources {
+		if u, ok := source.RemoteOPAServer(s.PolicyUrl()); ok && s.Subdir() == "policy" {
+			server, err := newOPAServer(u)
+			if err != nil {
+				return nil, nil, err
+			}
+			if len(c.namespace) == 0 || slices.Contains(c.namespace, server.namespace) {
+				servers = append(servers, server)
+			}
+			continue
+		}
+
 		dir, err := s.GetPolicy(ctx, c.workDir, false)
 		if err != nil {
 			log.Debugf("Unable to download source from %s!", s.PolicyUrl())
@@ -374,9 +556,34 @@ func (c conftestEvaluator) Evaluate(ctx context.Context, target EvaluationTarget
 
 		annotations := []*ast.AnnotationsRef{}
 		fs := utils.FS(ctx)
+
+		if s.Subdir() == "data" {
+			if err := checkDataSource(ctx, fs, s, dir); err != nil {
+				return nil, nil, err
+			}
+		}
+
 		// We only want to inspect the directory of policy subdirs, not config or data subdirs.
 		if s.Subdir() == "policy" {
+			wasm, err := isWasmSource(fs, dir)
+			if err != nil {
+				return nil, nil, err
+			}
+
+			cueSource, err := cueRules(fs, dir)
+			if err != nil {
+				return nil, nil, err
+			}
+
 			annotations, err = opa.InspectDir(fs, dir)
+			rego := true
+			if err != nil && (wasm || len(cueSource) > 0) && err.Error() == "no rego files found in policy subdirectory" {
+				// The Rego modules are not required for the sources compiled
+				// to WebAssembly, they only provide the rule metadata, nor for
+				// the sources with CUE files
+				rego = false
+				err = nil
+			}
 			if err != nil {
 				errMsg := err
 				if err.Error() == "no rego files found in policy subdirectory" {
@@ -399,6 +606,24 @@ func (c conftestEvaluator) Evaluate(ctx context.Context, target EvaluationTarget
 				}
 				return nil, nil, errMsg
 			}
+
+			switch {
+			case wasm:
+				wasmDirs = append(wasmDirs, dir)
+			case rego:
+				regoDirs = append(regoDirs, dir)
+			}
+
+			for _, r := range cueSource {
+				if len(c.namespace) > 0 && !slices.Contains(c.namespace, r.namespace) {
+					continue
+				}
+				if _, ok := rules[r.code]; ok {
+					return nil, nil, fmt.Errorf("found a second rule with the same code: `%s`", r.code)
+				}
+				rules[r.code] = r.info()
+				cue = append(cue, r)
+			}
 		}
 
 		for _, a := range annotations {
@@ -411,27 +636,19 @@ func (c conftestEvaluator) Evaluate(ctx context.Context, target EvaluationTarget
 		}
 	}
 
+	// The configured effective times take precedence over the effective_on
+	// annotations of the rules
+	for code, effectiveOn := range ruleEffectiveOnFrom(ctx) {
+		if info, ok := rules[code]; ok {
+			info.EffectiveOn = effectiveOn
+			rules[code] = info
+		}
+	}
+
 	var r testRunner
 	var ok bool
 	if r, ok = ctx.Value(runnerKey).(testRunner); r == nil || !ok {
-
-		// should there be a namespace defined or not
-		allNamespaces := true
-		if len(c.namespace) > 0 {
-			allNamespaces = false
-		}
-
-		r = &conftestRunner{
-			runner.TestRunner{
-				Data:          []string{c.dataDir},
-				Policy:        []string{c.policyDir},
-				Namespace:     c.namespace,
-				AllNamespaces: allNamespaces,
-				NoFail:        true,
-				Output:        c.outputFormat,
-				Capabilities:  c.CapabilitiesPath(),
-			},
-		}
+		r = c.runner(regoDirs, wasmDirs, servers, cue)
 	}
 
 	log.Debugf("runner: %#v", r)
@@ -443,9 +660,19 @@ func (c conftestEvaluator) Evaluate(ctx context.Context, target EvaluationTarget
 		return nil, nil, err
 	}
 
+	if err := c.checkRequiredRuleData(rules, data, target.Target); err != nil {
+		return nil, nil, err
+	}
+
 	effectiveTime := c.policy.EffectiveTime()
 	ctx = context.WithValue(ctx, effectiveTimeKey, effectiveTime)
 
+	// The overrides given via the context take precedence over the ones in
+	// the policy configuration
+	overrides := SeverityOverrides{}
+	maps.Copy(overrides, c.severityOverrides)
+	maps.Copy(overrides, severityOverridesFrom(ctx))
+
 	// Track how many rules have been processed. This is used later on to determine if anything
 	// at all was processed.
 	totalRules := 0
@@ -456,9 +683,27 @@ func (c conftestEvaluator) Evaluate(ctx context.Context, target EvaluationTarget
 		log.Debugf("Evaluation result at %d: %#v", i, result)
 		warnings := []Result{}
 		failures := []Result{}
+		infos := []Result{}
 		exceptions := []Result{}
 		skipped := []Result{}
 
+		// classify reports the result with the severity, unless overridden
+		classify := func(r Result, severity string) {
+			switch overrides.reclassify(&r, severity) {
+			case SeverityViolation:
+				if !isResultEffective(r, effectiveTime) {
+					// TODO: Instead of moving to warnings, create new attribute: "futureViolations"
+					warnings = append(warnings, r)
+				} else {
+					failures = append(failures, r)
+				}
+			case SeverityWarning:
+				warnings = append(warnings, r)
+			case SeverityInfo:
+				infos = append(infos, r)
+			}
+		}
+
 		for i := range result.Warnings {
 			warning := result.Warnings[i]
 			addRuleMetadata(ctx, &warning, rules)
@@ -467,7 +712,7 @@ func (c conftestEvaluator) Evaluate(ctx context.Context, target EvaluationTarget
 				log.Debugf("Skipping result warning: %#v", warning)
 				continue
 			}
-			warnings = append(warnings, warning)
+			classify(warning, SeverityWarning)
 		}
 
 		for i := range result.Failures {
@@ -478,13 +723,7 @@ func (c conftestEvaluator) Evaluate(ctx context.Context, target EvaluationTarget
 				log.Debugf("Skipping result failure: %#v", failure)
 				continue
 			}
-
-			if !isResultEffective(failure, effectiveTime) {
-				// TODO: Instead of moving to warnings, create new attribute: "futureViolations"
-				warnings = append(warnings, failure)
-			} else {
-				failures = append(failures, failure)
-			}
+			classify(failure, SeverityViolation)
 		}
 
 		for i := range result.Exceptions {
@@ -501,29 +740,211 @@ func (c conftestEvaluator) Evaluate(ctx context.Context, target EvaluationTarget
 
 		result.Warnings = warnings
 		result.Failures = failures
+		if len(infos) > 0 {
+			result.Infos = infos
+		}
 		result.Exceptions = exceptions
 		result.Skipped = skipped
 
 		// Replace the placeholder successes slice with the actual successes.
 		result.Successes = c.computeSuccesses(result, rules, effectiveTime, target.Target)
 
-		totalRules += len(result.Warnings) + len(result.Failures) + len(result.Successes)
+		// the rules compiled to WebAssembly and the rules of remote OPA
+		// servers without metadata pass without a result
+		totalRules += len(result.Warnings) + len(result.Failures) + len(result.Infos) + len(result.Successes) + result.Evaluated
 
 		results = append(results, result)
 	}
 
 	trim(&results)
 
+	if coverage := coverageFrom(ctx); coverage != nil {
+		coverage.record(rules, results, func(info rule.Info) bool {
+			return c.isResultIncluded(Result{Metadata: map[string]any{
+				metadataCode:        info.Code,
+				metadataCollections: info.Collections,
+			}}, target.Target)
+		})
+	}
+
 	// If no rules were checked, then we have effectively failed, because no tests were actually
-	// ran due to input error, etc.
+	// ran due to input error, etc. It is up to the caller to decide how to treat this.
 	if totalRules == 0 {
-		log.Error("no successes, warnings, or failures, check input")
-		return nil, nil, fmt.Errorf("no successes, warnings, or failures, check input")
+		log.Debug(ErrNoApplicableRules.Error())
+		return nil, nil, ErrNoApplicableRules
+	}
+
+	// The rules compiled to WebAssembly are not traced
+	if codes := explainFrom(ctx); len(codes) > 0 && len(regoDirs) > 0 {
+		engine, err := c.engine.load(ctx, c.testRunner(c.regoPolicy(regoDirs, wasmDirs)))
+		if err != nil {
+			return nil, nil, err
+		}
+		documents, err := readInputs(utils.FS(ctx), target.Inputs)
+		if err != nil {
+			return nil, nil, err
+		}
+		traces, err := explain(ctx, engine, c.policyDir, codes, documents)
+		if err != nil {
+			return nil, nil, err
+		}
+		withTraces(results, traces)
+	}
+
+	if strict, ok := ctx.Value(strictInputKey).(bool); ok && strict {
+		undefined, err := c.undefinedInputWarnings(ctx, target.Inputs)
+		if err != nil {
+			return nil, nil, err
+		}
+		results = withUndefinedInputWarnings(results, undefined)
 	}
 
 	return results, data, nil
 }
 
+// runner returns the runner evaluating the policy sources: Conftest evaluates
+// the Rego sources, the sources compiled to WebAssembly are evaluated using
+// the WebAssembly engine, the remote OPA servers evaluate their packages and
+// the inputs are validated against the CUE files.
+func (c conftestEvaluator) runner(regoDirs, wasmDirs []string, servers []opaServer, cue []cueRule) testRunner {
+	var runners multiRunner
+	if len(regoDirs) > 0 || len(wasmDirs)+len(servers)+len(cue) == 0 {
+		runners = append(runners, &conftestRunner{
+			c.testRunner(c.regoPolicy(regoDirs, wasmDirs)),
+			c.engine,
+		})
+	}
+
+	if len(wasmDirs) > 0 {
+		engine := c.wasm
+		if engine == nil {
+			engine = &wasmEngine{}
+		}
+		runners = append(runners, &wasmRunner{
+			policyDirs: wasmDirs,
+			dataDir:    c.dataDir,
+			namespaces: c.namespace,
+			engine:     engine,
+		})
+	}
+
+	if len(servers) > 0 {
+		runners = append(runners, &opaServerRunner{servers: servers})
+	}
+
+	if len(cue) > 0 {
+		runners = append(runners, &cueRunner{rules: cue})
+	}
+
+	if len(runners) == 1 {
+		return runners[0]
+	}
+
+	return runners
+}
+
+// regoPolicy returns the policy paths evaluated by Conftest. The sources
+// compiled to WebAssembly might include their Rego modules, those must not be
+// evaluated again by Conftest.
+func (c conftestEvaluator) regoPolicy(regoDirs, wasmDirs []string) []string {
+	if len(wasmDirs) == 0 {
+		return []string{c.policyDir}
+	}
+
+	return regoDirs
+}
+
+// multiRunner runs each of the runners, combining their outcomes. The data is
+// the same for all runners that provide it, the data from the first of those
+// is returned.
+type multiRunner []testRunner
+
+func (m multiRunner) Run(ctx context.Context, fileList []string) ([]Outcome, Data, error) {
+	var outcomes []Outcome
+	var data Data
+	for _, r := range m {
+		o, d, err := r.Run(ctx, fileList)
+		if err != nil {
+			return nil, nil, err
+		}
+		outcomes = append(outcomes, o...)
+		if data == nil {
+			data = d
+		}
+	}
+
+	return outcomes, data, nil
+}
+
+// testRunner returns the configuration of the conftest runner evaluating the
+// given policy paths.
+func (c conftestEvaluator) testRunner(policy []string) runner.TestRunner {
+	// should there be a namespace defined or not
+	allNamespaces := true
+	if len(c.namespace) > 0 {
+		allNamespaces = false
+	}
+
+	return runner.TestRunner{
+		Data:          []string{c.dataDir},
+		Policy:        policy,
+		Namespace:     c.namespace,
+		AllNamespaces: allNamespaces,
+		NoFail:        true,
+		Output:        c.outputFormat,
+		Capabilities:  c.CapabilitiesPath(),
+	}
+}
+
+// checkDataSource verifies that the data source downloaded to the given
+// directory provided at least one non-empty data file. A data source that
+// provided no data is reported with a warning, or an error in strict mode.
+func checkDataSource(ctx context.Context, fs afero.Fs, s source.PolicySource, dir string) error {
+	found, err := containsData(fs, dir)
+	if err != nil {
+		return err
+	}
+	if found {
+		return nil
+	}
+
+	err = fmt.Errorf("%w: %s", ErrEmptyDataSource, logging.RedactURL(s.PolicyUrl()))
+	if strict, ok := ctx.Value(strictDataKey).(bool); ok && strict {
+		return err
+	}
+
+	if _, reported := emptyDataSources.LoadOrStore(s.PolicyUrl(), true); !reported {
+		log.Warnf("%s, check that the URL of the data source is correct. Rules depending on the data may pass without checking anything.", err)
+	}
+
+	return nil
+}
+
+// containsData returns true if the directory, or any of its subdirectories,
+// contains a non-empty JSON or YAML file, the files loaded as data.
+func containsData(fs afero.Fs, dir string) (bool, error) {
+	entries, err := afero.ReadDir(fs, dir)
+	if err != nil {
+		return false, err
+	}
+
+	for _, e := range entries {
+		p := filepath.Join(dir, e.Name())
+		if e.IsDir() {
+			if found, err := containsData(fs, p); err != nil || found {
+				return found, err
+			}
+			continue
+		}
+
+		if e.Size() > 0 && utils.HasJsonOrYamlExt(p) {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
 func toRules(results []output.Result) []Result {
 	var eResults []Result
 	for _, r := range results {
@@ -537,6 +958,59 @@ func toRules(results []output.Result) []Result {
 	return eResults
 }
 
+// checkRequiredRuleData verifies that the rule data declared via the
+// required_rule_data annotation of each included rule is present in any of
+// the rule data namespaces. All missing keys are reported at once, along with
+// the rules requiring them.
+func (c conftestEvaluator) checkRequiredRuleData(rules policyRules, data Data, target string) error {
+	provided := map[string]bool{}
+	for _, ns := range ruleDataNamespaces {
+		if d, ok := data[ns].(map[string]any); ok {
+			for k := range d {
+				provided[k] = true
+			}
+		}
+	}
+
+	missing := map[string][]string{}
+	for code, rule := range rules {
+		if len(rule.RequiredRuleData) == 0 {
+			continue
+		}
+
+		result := Result{
+			Metadata: map[string]interface{}{
+				metadataCode: code,
+			},
+		}
+		if len(rule.Collections) > 0 {
+			result.Metadata[metadataCollections] = rule.Collections
+		}
+		if !c.isResultIncluded(result, target) {
+			continue
+		}
+
+		for _, key := range rule.RequiredRuleData {
+			if !provided[key] {
+				missing[key] = append(missing[key], code)
+			}
+		}
+	}
+
+	if len(missing) == 0 {
+		return nil
+	}
+
+	keys := make([]string, 0, len(missing))
+	for key, codes := range missing {
+		sort.Strings(codes)
+		keys = append(keys, fmt.Sprintf("%q required by %s", key, strings.Join(codes, ", ")))
+	}
+	sort.Strings(keys)
+
+	return fmt.Errorf("%w: %s", ErrMissingRuleData, strings.Join(keys, "; "))
+}
+
 // computeSuccesses generates success results, these are not provided in the
 // Conftest results, so we reconstruct these from the parsed rules, any rule
 // that hasn't been touched by adding metadata must have succeeded
@@ -544,7 +1018,7 @@ func (c conftestEvaluator) computeSuccesses(result Outcome, rules policyRules, e
 	// what rules, by code, have we seen in the Conftest results, use map to
 	// take advantage of hashing for quicker lookup
 	seenRules := map[string]bool{}
-	for _, o := range [][]Result{result.Failures, result.Warnings, result.Skipped, result.Exceptions} {
+	for _, o := range [][]Result{result.Failures, result.Warnings, result.Infos, result.Skipped, result.Exceptions} {
 		for _, r := range o {
 			if code, ok := r.Metadata[metadataCode].(string); ok {
 				seenRules[code] = true
@@ -656,12 +1130,18 @@ func addMetadataToResults(ctx context.Context, r *Result, rule rule.Info) {
 	if rule.Solution != "" {
 		r.Metadata[metadataSolution] = rule.Solution
 	}
+	if _, ok := r.Metadata[metadataSeverity]; !ok && rule.Severity != "" {
+		r.Metadata[metadataSeverity] = rule.Severity
+	}
 	if len(rule.Collections) > 0 {
 		r.Metadata[metadataCollections] = rule.Collections
 	}
 	if len(rule.DependsOn) > 0 {
 		r.Metadata[metadataDependsOn] = rule.DependsOn
 	}
+	if rule.Verbose {
+		r.Metadata[metadataVerbose] = true
+	}
 
 	// If the rule has been effective for a long time, we'll consider
 	// the effective_on date not relevant and not bother including it
@@ -31,17 +31,22 @@ import (
 	"path/filepath"
 	"sort"
 	"strings"
+	"sync"
 	"testing"
+	"testing/fstest"
 	"time"
 
 	"github.com/MakeNowJust/heredoc"
 	ecc "github.com/enterprise-contract/enterprise-contract-controller/api/v1alpha1"
 	"github.com/gkampitakis/go-snaps/snaps"
 	"github.com/open-policy-agent/opa/ast"
+	log "github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus/hooks/test"
 	"github.com/spf13/afero"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
+	extv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	"k8s.io/kube-openapi/pkg/util/sets"
 
 	"github.com/enterprise-contract/ec-cli/internal/downloader"
@@ -314,6 +319,7 @@ func TestConftestEvaluatorEvaluateNoSuccessWarningsOrFailures(t *testing.T) {
 			assert.NoError(t, err)
 			actualResults, data, err := evaluator.Evaluate(ctx, inputs)
 			assert.ErrorContains(t, err, "no successes, warnings, or failures, check input")
+			assert.ErrorIs(t, err, ErrNoApplicableRules)
 			assert.Nil(t, actualResults)
 			assert.Nil(t, data)
 		})
@@ -1285,16 +1291,17 @@ func TestCollectAnnotationData(t *testing.T) {
 
 	assert.Equal(t, policyRules{
 		"a.b.c.short": {
-			Code:        "a.b.c.short",
-			CodePackage: "a.b.c",
-			Collections: []string{"A", "B", "C"},
-			DependsOn:   []string{"a.b.c"},
-			Description: "Description",
-			EffectiveOn: "2022-01-01T00:00:00Z",
-			Kind:        rule.Deny,
-			Package:     "a.b.c",
-			ShortName:   "short",
-			Title:       "Title",
+			Code:             "a.b.c.short",
+			CodePackage:      "a.b.c",
+			Collections:      []string{"A", "B", "C"},
+			DependsOn:        []string{"a.b.c"},
+			Description:      "Description",
+			EffectiveOn:      "2022-01-01T00:00:00Z",
+			Kind:             rule.Deny,
+			Package:          "a.b.c",
+			RequiredRuleData: []string{},
+			ShortName:        "short",
+			Title:            "Title",
 		},
 	}, rules)
 }
@@ -1324,6 +1331,10 @@ func TestRuleMetadata(t *testing.T) {
 			Description: "Warning 3 description",
 			EffectiveOn: effectiveOnTest,
 		},
+		"verbose1": rule.Info{
+			Title:   "Verbose1",
+			Verbose: true,
+		},
 	}
 	cases := []struct {
 		name   string
@@ -1403,6 +1414,22 @@ func TestRuleMetadata(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "mark verbose rule",
+			result: Result{
+				Metadata: map[string]any{
+					"code": "verbose1",
+				},
+			},
+			rules: rules,
+			want: Result{
+				Metadata: map[string]any{
+					"code":    "verbose1",
+					"title":   "Verbose1",
+					"verbose": true,
+				},
+			},
+		},
 		{
 			name: "rule not found",
 			result: Result{
@@ -1744,6 +1771,59 @@ func TestConftestEvaluatorEvaluate(t *testing.T) {
 	snaps.MatchSnapshot(t, results, data)
 }
 
+func TestConftestEvaluatorEvaluateSharesEngine(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(path.Join(dir, "inputs"), 0755))
+	require.NoError(t, os.WriteFile(path.Join(dir, "inputs", "data.json"), []byte("{}"), 0600))
+
+	rego, err := fs.Sub(policies, "__testdir__/simple")
+	require.NoError(t, err)
+
+	rules, err := rulesArchive(t, rego)
+	require.NoError(t, err)
+
+	ctx := withCapabilities(context.Background(), testCapabilities)
+
+	p, err := policy.NewInertPolicy(ctx, "")
+	require.NoError(t, err)
+
+	e, err := NewConftestEvaluator(ctx, []source.PolicySource{
+		&source.PolicyUrl{
+			Url:  rules,
+			Kind: source.PolicyKind,
+		},
+	}, p, ecc.Source{})
+	require.NoError(t, err)
+
+	target := EvaluationTarget{Inputs: []string{path.Join(dir, "inputs")}}
+	expected, _, err := e.Evaluate(ctx, target)
+	require.NoError(t, err)
+
+	engine := e.(conftestEvaluator).engine.engine
+	require.NotNil(t, engine)
+
+	const concurrent = 5
+	var wg sync.WaitGroup
+	results := make([][]Outcome, concurrent)
+	errs := make([]error, concurrent)
+	for i := 0; i < concurrent; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i], _, errs[i] = e.Evaluate(ctx, target)
+		}(i)
+	}
+	wg.Wait()
+
+	for i := 0; i < concurrent; i++ {
+		require.NoError(t, errs[i])
+		assert.ElementsMatch(t, expected, results[i])
+	}
+
+	// the engine is loaded on first use and reused afterwards
+	assert.Same(t, engine, e.(conftestEvaluator).engine.engine)
+}
+
 type mockConfigProvider struct {
 	mock.Mock
 }
@@ -2121,3 +2201,621 @@ func rulesArchive(t *testing.T, files fs.FS) (string, error) {
 
 	return rules, nil
 }
+
+func TestCheckRequiredRuleData(t *testing.T) {
+	rules := policyRules{
+		"registry.allowed": rule.Info{
+			Code:             "registry.allowed",
+			RequiredRuleData: []string{"allowed_registries"},
+		},
+		"registry.denied": rule.Info{
+			Code:             "registry.denied",
+			RequiredRuleData: []string{"allowed_registries", "denied_registries"},
+		},
+		"packages.allowed": rule.Info{
+			Code:             "packages.allowed",
+			Collections:      []string{"redhat"},
+			RequiredRuleData: []string{"allowed_packages"},
+		},
+		"other.rule": rule.Info{
+			Code: "other.rule",
+		},
+	}
+
+	cases := []struct {
+		name     string
+		data     Data
+		include  []string
+		exclude  []string
+		expected string
+	}{
+		{
+			name:     "all missing",
+			include:  []string{"*"},
+			expected: `missing required rule data: "allowed_packages" required by packages.allowed; "allowed_registries" required by registry.allowed, registry.denied; "denied_registries" required by registry.denied`,
+		},
+		{
+			name: "provided across namespaces",
+			data: Data{
+				"rule_data":                  map[string]any{"allowed_registries": []any{}},
+				"rule_data_custom":           map[string]any{"denied_registries": []any{}},
+				"rule_data__configuration__": map[string]any{"allowed_packages": []any{}},
+			},
+			include: []string{"*"},
+		},
+		{
+			name:     "excluded rules are not checked",
+			data:     Data{"rule_data": map[string]any{"allowed_registries": []any{}}},
+			include:  []string{"*"},
+			exclude:  []string{"registry.denied", "@redhat"},
+			expected: "",
+		},
+		{
+			name:     "only included collection",
+			include:  []string{"@redhat"},
+			expected: `missing required rule data: "allowed_packages" required by packages.allowed`,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			e := conftestEvaluator{
+				include: &Criteria{defaultItems: c.include},
+				exclude: &Criteria{defaultItems: c.exclude},
+			}
+
+			err := e.checkRequiredRuleData(rules, c.data, "")
+			if c.expected == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, ErrMissingRuleData)
+				assert.EqualError(t, err, c.expected)
+			}
+		})
+	}
+}
+
+type testDataSource struct {
+	url string
+}
+
+func (t testDataSource) GetPolicy(ctx context.Context, dest string, showMsg bool) (string, error) {
+	return "/data", nil
+}
+
+func (t testDataSource) PolicyUrl() string {
+	return t.url
+}
+
+func (t testDataSource) Subdir() string {
+	return "data"
+}
+
+func TestCheckDataSource(t *testing.T) {
+	cases := []struct {
+		name   string
+		files  map[string]string
+		strict bool
+		err    string
+		warned bool
+	}{
+		{
+			name:  "data file",
+			files: map[string]string{"/data/rule_data.yml": "allowed_registries: []"},
+		},
+		{
+			name:  "data file in subdirectory",
+			files: map[string]string{"/data/nested/config.json": "{}"},
+		},
+		{
+			name:   "no data files",
+			files:  map[string]string{"/data/README.md": "# data", "/data/empty.json": ""},
+			warned: true,
+		},
+		{
+			name:   "no data files in strict mode",
+			files:  map[string]string{"/data/README.md": "# data"},
+			strict: true,
+			err:    "data source provided no data: git::https://github.com/REDACTED",
+		},
+	}
+
+	for i, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			fs := afero.NewMemMapFs()
+			require.NoError(t, fs.MkdirAll("/data", 0755))
+			for f, content := range c.files {
+				require.NoError(t, afero.WriteFile(fs, f, []byte(content), 0400))
+			}
+
+			ctx := context.Background()
+			if c.strict {
+				ctx = WithStrictDataSources(ctx)
+			}
+
+			hook := test.NewGlobal()
+			t.Cleanup(hook.Reset)
+
+			// unique URL per case, as each empty data source is reported once
+			s := testDataSource{url: "git::https://github.com/org/data"}
+			if !c.strict {
+				s.url = fmt.Sprintf("%s?case=%d", s.url, i)
+			}
+
+			err := checkDataSource(ctx, fs, s, "/data")
+			if c.err != "" {
+				assert.ErrorIs(t, err, ErrEmptyDataSource)
+				assert.EqualError(t, err, c.err)
+				return
+			}
+			require.NoError(t, err)
+
+			if c.warned {
+				require.NotNil(t, hook.LastEntry())
+				assert.Equal(t, log.WarnLevel, hook.LastEntry().Level)
+				assert.Contains(t, hook.LastEntry().Message, "data source provided no data")
+
+				// reported only once
+				hook.Reset()
+				require.NoError(t, checkDataSource(ctx, fs, s, "/data"))
+				assert.Nil(t, hook.LastEntry())
+			} else {
+				assert.Nil(t, hook.LastEntry())
+			}
+		})
+	}
+}
+
+func TestConftestEvaluatorEvaluateStrictInput(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(path.Join(dir, "inputs"), 0755))
+	require.NoError(t, os.WriteFile(path.Join(dir, "inputs", "input.json"), []byte(`{"image": {"ref": "registry.io/spam"}, "attestations": []}`), 0600))
+
+	rules, err := rulesArchive(t, fstest.MapFS{
+		"strict.rego": &fstest.MapFile{Data: []byte(heredoc.Doc(`
+			package strict
+
+			import rego.v1
+
+			# METADATA
+			# custom:
+			#   short_name: ref
+			deny contains result if {
+				input.image.ref == "registry.io/eggs"
+				result := {"code": "strict.ref", "msg": "Wrong image"}
+			}
+
+			# METADATA
+			# custom:
+			#   short_name: digest
+			deny contains result if {
+				input.image.digset == ""
+				some att in input.attestations
+				att.statement.predicateType == ""
+				result := {"code": "strict.digest", "msg": "No digest"}
+			}
+		`))},
+	})
+	require.NoError(t, err)
+
+	ctx := withCapabilities(context.Background(), testCapabilities)
+
+	config := &mockConfigProvider{}
+	config.On("EffectiveTime").Return(time.Now())
+	config.On("SigstoreOpts").Return(policy.SigstoreOpts{}, nil)
+	config.On("Spec").Return(ecc.EnterpriseContractPolicySpec{})
+
+	evaluator, err := NewConftestEvaluator(ctx, []source.PolicySource{
+		&source.PolicyUrl{
+			Url:  rules,
+			Kind: source.PolicyKind,
+		},
+	}, config, ecc.Source{})
+	require.NoError(t, err)
+
+	target := EvaluationTarget{Inputs: []string{path.Join(dir, "inputs")}}
+
+	results, _, err := evaluator.Evaluate(ctx, target)
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Empty(t, results[0].Warnings)
+
+	results, _, err = evaluator.Evaluate(WithStrictInput(ctx), target)
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, []Result{
+		{
+			Message:  "Reference to undefined input path input.image.digset at strict.rego:17",
+			Metadata: map[string]any{"code": "builtin.input.undefined_reference"},
+		},
+	}, results[0].Warnings)
+}
+
+func TestConftestEvaluatorEvaluateRuleEffectiveOn(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(path.Join(dir, "inputs"), 0755))
+	require.NoError(t, os.WriteFile(path.Join(dir, "inputs", "input.json"), []byte(`{}`), 0600))
+
+	rules, err := rulesArchive(t, fstest.MapFS{
+		"grace.rego": &fstest.MapFile{Data: []byte(heredoc.Doc(`
+			package grace
+
+			import rego.v1
+
+			# METADATA
+			# title: New rule
+			# custom:
+			#   short_name: new
+			deny contains result if {
+				result := {"code": "grace.new", "msg": "Strict new rule"}
+			}
+		`))},
+	})
+	require.NoError(t, err)
+
+	ctx := withCapabilities(context.Background(), testCapabilities)
+
+	effectiveOn, err := ParseRuleEffectiveOn([]string{"grace.new=2024-06-30"})
+	require.NoError(t, err)
+	ctx = WithRuleEffectiveOn(ctx, effectiveOn)
+
+	cases := []struct {
+		name     string
+		now      string
+		warnings int
+		failures int
+	}{
+		{name: "grace period", now: "2024-06-29", warnings: 1},
+		{name: "effective", now: "2024-07-01", failures: 1},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			now, err := time.Parse(policy.DateFormat, c.now)
+			require.NoError(t, err)
+
+			config := &mockConfigProvider{}
+			config.On("EffectiveTime").Return(now)
+			config.On("SigstoreOpts").Return(policy.SigstoreOpts{}, nil)
+			config.On("Spec").Return(ecc.EnterpriseContractPolicySpec{})
+
+			evaluator, err := NewConftestEvaluator(ctx, []source.PolicySource{
+				&source.PolicyUrl{
+					Url:  rules,
+					Kind: source.PolicyKind,
+				},
+			}, config, ecc.Source{})
+			require.NoError(t, err)
+
+			results, _, err := evaluator.Evaluate(ctx, EvaluationTarget{Inputs: []string{path.Join(dir, "inputs")}})
+			require.NoError(t, err)
+			require.Len(t, results, 1)
+			assert.Len(t, results[0].Warnings, c.warnings)
+			assert.Len(t, results[0].Failures, c.failures)
+			for _, r := range append(results[0].Warnings, results[0].Failures...) {
+				assert.Equal(t, "2024-06-30T00:00:00Z", r.Metadata["effective_on"])
+			}
+		})
+	}
+}
+
+func TestConftestEvaluatorEvaluateSeverityOverrides(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(path.Join(dir, "inputs"), 0755))
+	require.NoError(t, os.WriteFile(path.Join(dir, "inputs", "input.json"), []byte(`{}`), 0600))
+
+	rules, err := rulesArchive(t, fstest.MapFS{
+		"severity.rego": &fstest.MapFile{Data: []byte(heredoc.Doc(`
+			package severity
+
+			import rego.v1
+
+			# METADATA
+			# title: Critical rule
+			# custom:
+			#   short_name: critical
+			#   severity: critical
+			deny contains result if {
+				result := {"code": "severity.critical", "msg": "Critical"}
+			}
+
+			# METADATA
+			# title: Minor rule
+			# custom:
+			#   short_name: minor
+			deny contains result if {
+				result := {"code": "severity.minor", "msg": "Minor"}
+			}
+
+			# METADATA
+			# title: Noisy rule
+			# custom:
+			#   short_name: noisy
+			warn contains result if {
+				result := {"code": "severity.noisy", "msg": "Noisy"}
+			}
+
+			# METADATA
+			# title: Important rule
+			# custom:
+			#   short_name: important
+			warn contains result if {
+				result := {"code": "severity.important", "msg": "Important"}
+			}
+		`))},
+	})
+	require.NoError(t, err)
+
+	config := &mockConfigProvider{}
+	config.On("EffectiveTime").Return(time.Now())
+	config.On("SigstoreOpts").Return(policy.SigstoreOpts{}, nil)
+	config.On("Spec").Return(ecc.EnterpriseContractPolicySpec{})
+
+	ctx := withCapabilities(context.Background(), testCapabilities)
+	// The overrides given via the context take precedence over the rule data
+	ctx = WithSeverityOverrides(ctx, SeverityOverrides{"severity.noisy": SeverityInfo})
+
+	evaluator, err := NewConftestEvaluator(ctx, []source.PolicySource{
+		&source.PolicyUrl{
+			Url:  rules,
+			Kind: source.PolicyKind,
+		},
+	}, config, ecc.Source{
+		RuleData: &extv1.JSON{Raw: []byte(`{"severity_overrides": {
+			"severity.minor": "low",
+			"severity.noisy": "violation",
+			"severity.important": "violation"
+		}}`)},
+	})
+	require.NoError(t, err)
+
+	results, _, err := evaluator.Evaluate(ctx, EvaluationTarget{Inputs: []string{path.Join(dir, "inputs")}})
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+
+	codes := func(results []Result) map[string]any {
+		found := map[string]any{}
+		for _, r := range results {
+			found[r.Metadata["code"].(string)] = []any{r.Metadata["severity"], r.Metadata["original_severity"]}
+		}
+		return found
+	}
+	assert.Equal(t, map[string]any{
+		"severity.critical":  []any{"critical", nil},
+		"severity.minor":     []any{"low", "violation"},
+		"severity.important": []any{nil, "warning"},
+	}, codes(results[0].Failures))
+	assert.Empty(t, results[0].Warnings)
+	assert.Equal(t, map[string]any{"severity.noisy": []any{nil, "warning"}}, codes(results[0].Infos))
+	assert.Empty(t, results[0].Successes)
+
+	_, err = NewConftestEvaluator(ctx, nil, config, ecc.Source{
+		Name:     "invalid",
+		RuleData: &extv1.JSON{Raw: []byte(`{"severity_overrides": {"severity.minor": "meh"}}`)},
+	})
+	assert.EqualError(t, err, `invalid severity "meh" for "severity.minor" in the severity_overrides of source "invalid"`)
+}
+
+func TestParseRuleEffectiveOn(t *testing.T) {
+	effectiveOn, err := ParseRuleEffectiveOn([]string{
+		"grace.new=2024-06-30",
+		"grace.newer=2024-07-01T12:00:00+02:00",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, RuleEffectiveOn{
+		"grace.new":   "2024-06-30T00:00:00Z",
+		"grace.newer": "2024-07-01T10:00:00Z",
+	}, effectiveOn)
+
+	for _, invalid := range []string{"grace.new", "=2024-06-30", "grace.new=tomorrow"} {
+		_, err := ParseRuleEffectiveOn([]string{invalid})
+		assert.EqualError(t, err, `invalid rule effective time "`+invalid+`", expected <rule code>=<YYYY-MM-DD or RFC3339 timestamp>`)
+	}
+}
+
+func TestIsDefined(t *testing.T) {
+	input := map[string]any{
+		"image":        map[string]any{"ref": "registry.io/spam"},
+		"attestations": []any{},
+	}
+
+	assert.True(t, isDefined(input, []string{"image"}))
+	assert.True(t, isDefined(input, []string{"image", "ref"}))
+	assert.False(t, isDefined(input, []string{"image", "digest"}))
+	assert.False(t, isDefined(input, []string{"spam"}))
+	// paths through arrays are not checked
+	assert.True(t, isDefined(input, []string{"attestations", "statement"}))
+}
+
+func TestConftestEvaluatorEvaluateExplain(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(path.Join(dir, "inputs"), 0755))
+	require.NoError(t, os.WriteFile(path.Join(dir, "inputs", "input.json"), []byte(`{"image": {"ref": "registry.io/repository/image:latest"}}`), 0600))
+
+	rules, err := rulesArchive(t, fstest.MapFS{
+		"explain.rego": &fstest.MapFile{Data: []byte(heredoc.Doc(`
+			package explain
+
+			import rego.v1
+
+			# METADATA
+			# title: Pinned image
+			# custom:
+			#   short_name: pinned
+			deny contains result if {
+				ref := input.image.ref
+				not contains(ref, "@")
+				result := {"code": "explain.pinned", "msg": "Image is not pinned"}
+			}
+
+			# METADATA
+			# title: Image registry
+			# custom:
+			#   short_name: registry
+			deny contains result if {
+				ref := input.image.ref
+				not startswith(ref, "registry.io/")
+				result := {"code": "explain.registry", "msg": "Image is not from the registry"}
+			}
+		`))},
+	})
+	require.NoError(t, err)
+
+	config := &mockConfigProvider{}
+	config.On("EffectiveTime").Return(time.Now())
+	config.On("SigstoreOpts").Return(policy.SigstoreOpts{}, nil)
+	config.On("Spec").Return(ecc.EnterpriseContractPolicySpec{})
+
+	ctx := withCapabilities(context.Background(), testCapabilities)
+	ctx = WithExplain(ctx, []string{"explain.registry", "explain.unknown"})
+
+	evaluator, err := NewConftestEvaluator(ctx, []source.PolicySource{
+		&source.PolicyUrl{
+			Url:  rules,
+			Kind: source.PolicyKind,
+		},
+	}, config, ecc.Source{})
+	require.NoError(t, err)
+
+	results, _, err := evaluator.Evaluate(ctx, EvaluationTarget{Inputs: []string{path.Join(dir, "inputs")}})
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+
+	require.Len(t, results[0].Failures, 1)
+	assert.NotContains(t, results[0].Failures[0].Metadata, "trace")
+
+	require.Len(t, results[0].Successes, 1)
+	assert.Equal(t, []string{
+		`explain.rego:19: Enter deny contains result if {`,
+		`explain.rego:20: Eval ref := input.image.ref`,
+		`explain.rego:21: Eval not startswith(ref, "registry.io/") (ref = "registry.io/repository/image:latest")`,
+		`explain.rego:21: Enter not startswith(ref, "registry.io/")`,
+		`explain.rego:21: Eval not startswith(ref, "registry.io/") (ref = "registry.io/repository/image:latest")`,
+		`explain.rego:21: Exit not startswith(ref, "registry.io/")`,
+		`explain.rego:21: Fail not startswith(ref, "registry.io/") (ref = "registry.io/repository/image:latest")`,
+	}, results[0].Successes[0].Metadata["trace"])
+}
+
+func TestConftestEvaluatorEvaluateCoverage(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(path.Join(dir, "inputs"), 0755))
+	require.NoError(t, os.WriteFile(path.Join(dir, "inputs", "input.json"), []byte(`{}`), 0600))
+
+	rules, err := rulesArchive(t, fstest.MapFS{
+		"coverage.rego": &fstest.MapFile{Data: []byte(heredoc.Doc(`
+			package coverage
+
+			import rego.v1
+
+			# METADATA
+			# title: Failing
+			# custom:
+			#   short_name: failing
+			deny contains result if {
+				result := {"code": "coverage.failing", "msg": "Failing"}
+			}
+
+			# METADATA
+			# title: Passing
+			# custom:
+			#   short_name: passing
+			deny contains result if {
+				false
+				result := {"code": "coverage.passing", "msg": "Passing"}
+			}
+
+			# METADATA
+			# title: Excluded
+			# custom:
+			#   short_name: excluded
+			deny contains result if {
+				result := {"code": "coverage.excluded", "msg": "Excluded"}
+			}
+
+			# METADATA
+			# title: Dependent
+			# custom:
+			#   short_name: dependent
+			#   depends_on:
+			#   - coverage.failing
+			deny contains result if {
+				false
+				result := {"code": "coverage.dependent", "msg": "Dependent"}
+			}
+		`))},
+	})
+	require.NoError(t, err)
+
+	config := &mockConfigProvider{}
+	config.On("EffectiveTime").Return(time.Now())
+	config.On("SigstoreOpts").Return(policy.SigstoreOpts{}, nil)
+	config.On("Spec").Return(ecc.EnterpriseContractPolicySpec{})
+
+	coverage := NewCoverage()
+	ctx := withCapabilities(context.Background(), testCapabilities)
+	ctx = WithCoverage(ctx, coverage)
+
+	evaluator, err := NewConftestEvaluator(ctx, []source.PolicySource{
+		&source.PolicyUrl{
+			Url:  rules,
+			Kind: source.PolicyKind,
+		},
+	}, config, ecc.Source{
+		Config: &ecc.SourceConfig{Exclude: []string{"coverage.excluded"}},
+	})
+	require.NoError(t, err)
+
+	_, _, err = evaluator.Evaluate(ctx, EvaluationTarget{Inputs: []string{path.Join(dir, "inputs")}})
+	require.NoError(t, err)
+
+	assert.Equal(t, &CoverageSummary{
+		Rules:     4,
+		Evaluated: []string{"coverage.failing", "coverage.passing"},
+		Skipped:   []string{"coverage.excluded"},
+		Unmatched: []string{"coverage.dependent"},
+	}, coverage.Summary())
+}
+
+func TestConftestEvaluatorEvaluateRegoV1(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(path.Join(dir, "inputs"), 0755))
+	require.NoError(t, os.WriteFile(path.Join(dir, "inputs", "input.json"), []byte(`{}`), 0600))
+
+	// Written for OPA 1.0, without the rego.v1 import
+	rules, err := rulesArchive(t, fstest.MapFS{
+		"v1.rego": &fstest.MapFile{Data: []byte(heredoc.Doc(`
+			package v1
+
+			# METADATA
+			# title: Rego v1
+			# custom:
+			#   short_name: v1
+			deny contains result if {
+				result := {"code": "v1.v1", "msg": "Rego v1"}
+			}
+		`))},
+	})
+	require.NoError(t, err)
+
+	config := &mockConfigProvider{}
+	config.On("EffectiveTime").Return(time.Now())
+	config.On("SigstoreOpts").Return(policy.SigstoreOpts{}, nil)
+	config.On("Spec").Return(ecc.EnterpriseContractPolicySpec{})
+
+	ctx := withCapabilities(context.Background(), testCapabilities)
+
+	evaluator, err := NewConftestEvaluator(ctx, []source.PolicySource{
+		&source.PolicyUrl{
+			Url:  rules,
+			Kind: source.PolicyKind,
+		},
+	}, config, ecc.Source{})
+	require.NoError(t, err)
+
+	results, _, err := evaluator.Evaluate(ctx, EvaluationTarget{Inputs: []string{path.Join(dir, "inputs")}})
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	require.Len(t, results[0].Failures, 1)
+	assert.Equal(t, "Rego v1", results[0].Failures[0].Message)
+	assert.Equal(t, "Rego v1", results[0].Failures[0].Metadata["title"])
+}
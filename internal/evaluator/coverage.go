@@ -0,0 +1,136 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package evaluator
+
+import (
+	"context"
+	"slices"
+	"sync"
+
+	"github.com/enterprise-contract/ec-cli/internal/opa/rule"
+)
+
+// coverageStatus is the status of a rule in the coverage, in the order of
+// precedence: a rule evaluated for any of the inputs is evaluated, regardless
+// of it being skipped for other inputs.
+type coverageStatus int
+
+const (
+	coverageUnmatched coverageStatus = iota
+	coverageSkipped
+	coverageEvaluated
+)
+
+// Coverage records which of the rules of the policy sources were evaluated
+// by the evaluators. It is safe for concurrent use.
+type Coverage struct {
+	mu    sync.Mutex
+	rules map[string]coverageStatus
+}
+
+// CoverageSummary lists the rules, by code, of the policy sources by their
+// coverage.
+type CoverageSummary struct {
+	// Rules is the number of rules in the policy sources
+	Rules int `json:"rules"`
+	// Evaluated are the rules that reported a result for any of the inputs
+	Evaluated []string `json:"evaluated,omitempty"`
+	// Skipped are the rules that were excluded by the policy configuration,
+	// for all of the inputs
+	Skipped []string `json:"skipped,omitempty"`
+	// Unmatched are the rules that reported no result for any of the inputs,
+	// e.g. rules of packages not queried or rules depending on a failed rule
+	Unmatched []string `json:"unmatched,omitempty"`
+}
+
+// NewCoverage returns an empty coverage.
+func NewCoverage() *Coverage {
+	return &Coverage{rules: map[string]coverageStatus{}}
+}
+
+// WithCoverage returns a context in which evaluators record the coverage of
+// the rules in the given coverage.
+func WithCoverage(ctx context.Context, coverage *Coverage) context.Context {
+	return context.WithValue(ctx, coverageKey, coverage)
+}
+
+func coverageFrom(ctx context.Context) *Coverage {
+	if coverage, ok := ctx.Value(coverageKey).(*Coverage); ok {
+		return coverage
+	}
+
+	return nil
+}
+
+// record records the coverage of the rules by the results of an evaluation,
+// the included function reports if the rule is included by the policy
+// configuration.
+func (c *Coverage) record(rules policyRules, results []Outcome, included func(rule.Info) bool) {
+	status := make(map[string]coverageStatus, len(rules))
+	for code, info := range rules {
+		if included(info) {
+			status[code] = coverageUnmatched
+		} else {
+			status[code] = coverageSkipped
+		}
+	}
+
+	mark := func(rs []Result, to coverageStatus) {
+		for _, r := range rs {
+			code := ExtractStringFromMetadata(r, metadataCode)
+			if s, ok := status[code]; ok {
+				status[code] = max(s, to)
+			}
+		}
+	}
+
+	for _, o := range results {
+		mark(o.Skipped, coverageSkipped)
+		for _, rs := range [][]Result{o.Successes, o.Failures, o.Warnings, o.Infos, o.Exceptions} {
+			mark(rs, coverageEvaluated)
+		}
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for code, s := range status {
+		c.rules[code] = max(c.rules[code], s)
+	}
+}
+
+// Summary returns the coverage of the rules recorded so far.
+func (c *Coverage) Summary() *CoverageSummary {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	summary := CoverageSummary{Rules: len(c.rules)}
+	for code, s := range c.rules {
+		switch s {
+		case coverageEvaluated:
+			summary.Evaluated = append(summary.Evaluated, code)
+		case coverageSkipped:
+			summary.Skipped = append(summary.Skipped, code)
+		default:
+			summary.Unmatched = append(summary.Unmatched, code)
+		}
+	}
+	slices.Sort(summary.Evaluated)
+	slices.Sort(summary.Skipped)
+	slices.Sort(summary.Unmatched)
+
+	return &summary
+}
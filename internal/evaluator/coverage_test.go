@@ -0,0 +1,62 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package evaluator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/enterprise-contract/ec-cli/internal/opa/rule"
+)
+
+func TestCoverageRecord(t *testing.T) {
+	rules := policyRules{
+		"pkg.a": rule.Info{Code: "pkg.a"},
+		"pkg.b": rule.Info{Code: "pkg.b"},
+		"pkg.c": rule.Info{Code: "pkg.c"},
+		"pkg.d": rule.Info{Code: "pkg.d"},
+	}
+
+	result := func(code string) Result {
+		return Result{Metadata: map[string]any{"code": code}}
+	}
+
+	coverage := NewCoverage()
+
+	// pkg.b is excluded for the first input, but evaluated for the second
+	coverage.record(rules, []Outcome{{
+		Failures: []Result{result("pkg.a")},
+		Skipped:  []Result{result("pkg.c")},
+	}}, func(info rule.Info) bool {
+		return info.Code != "pkg.b"
+	})
+	coverage.record(rules, []Outcome{{
+		Successes: []Result{result("pkg.b"), result("unknown")},
+	}}, func(info rule.Info) bool {
+		return true
+	})
+
+	assert.Equal(t, &CoverageSummary{
+		Rules:     4,
+		Evaluated: []string{"pkg.a", "pkg.b"},
+		Skipped:   []string{"pkg.c"},
+		Unmatched: []string{"pkg.d"},
+	}, coverage.Summary())
+}
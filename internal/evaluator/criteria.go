@@ -18,6 +18,8 @@ package evaluator
 
 import (
 	"fmt"
+	"slices"
+	"strings"
 	"time"
 
 	ecc "github.com/enterprise-contract/enterprise-contract-controller/api/v1alpha1"
@@ -130,3 +132,20 @@ func computeIncludeExclude(src ecc.Source, p ConfigProvider) (*Criteria, *Criter
 
 	return include, exclude
 }
+
+// SelectedCollections returns the names of the rule collections included by
+// the policy, by any of its sources, e.g. minimal for an include of @minimal.
+func SelectedCollections(p ConfigProvider) []string {
+	collections := []string{}
+	for _, src := range p.Spec().Sources {
+		include, _ := computeIncludeExclude(src, p)
+		for _, item := range include.get("") {
+			if name, ok := strings.CutPrefix(item, "@"); ok && !slices.Contains(collections, name) {
+				collections = append(collections, name)
+			}
+		}
+	}
+	slices.Sort(collections)
+
+	return collections
+}
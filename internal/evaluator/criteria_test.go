@@ -21,6 +21,7 @@ package evaluator
 import (
 	"testing"
 
+	ecc "github.com/enterprise-contract/enterprise-contract-controller/api/v1alpha1"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -223,3 +224,27 @@ func TestGet(t *testing.T) {
 	assert.ElementsMatch(t, expectedDefaultItems, c.get("key2"))
 
 }
+
+func TestSelectedCollections(t *testing.T) {
+	config := &mockConfigProvider{}
+	config.On("Spec").Return(ecc.EnterpriseContractPolicySpec{
+		Configuration: &ecc.EnterpriseContractPolicyConfiguration{
+			Collections: []string{"legacy"},
+		},
+		Sources: []ecc.Source{
+			{
+				Config: &ecc.SourceConfig{
+					Include: []string{"@minimal", "cve", "@slsa3"},
+				},
+			},
+			{
+				Config: &ecc.SourceConfig{
+					Include: []string{"@minimal"},
+				},
+			},
+			{},
+		},
+	})
+
+	assert.Equal(t, []string{"legacy", "minimal", "slsa3"}, SelectedCollections(config))
+}
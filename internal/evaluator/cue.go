@@ -0,0 +1,190 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package evaluator
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/cuecontext"
+	cueerrors "cuelang.org/go/cue/errors"
+	cueparser "cuelang.org/go/cue/parser"
+	cuejson "cuelang.org/go/encoding/json"
+	"github.com/open-policy-agent/conftest/parser"
+	"github.com/spf13/afero"
+
+	"github.com/enterprise-contract/ec-cli/internal/opa/rule"
+)
+
+// cueDefaultPackage is the namespace of the CUE files without a package
+// clause, same as the default namespace of Conftest
+const cueDefaultPackage = "main"
+
+// cueRule is a CUE file of a policy source, the inputs are validated against
+// the constraints in the file.
+type cueRule struct {
+	code      string
+	namespace string
+	file      string
+	source    []byte
+}
+
+// info returns the rule information of the CUE file, used to report it as a
+// success when the input satisfies its constraints
+func (r cueRule) info() rule.Info {
+	return rule.Info{
+		Code:      r.code,
+		Package:   r.namespace,
+		ShortName: strings.TrimPrefix(r.code, r.namespace+"."),
+	}
+}
+
+// cueRules returns the rules of the CUE files within the policy source
+// directory. The code of each rule is made of the package of the file and the
+// name of the file without the extension, e.g. release.image for the file
+// image.cue in the release package.
+func cueRules(afs afero.Fs, dir string) ([]cueRule, error) {
+	var rules []cueRule
+	err := afero.Walk(afs, dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if info.IsDir() || filepath.Ext(path) != ".cue" {
+			return nil
+		}
+
+		source, err := afero.ReadFile(afs, path)
+		if err != nil {
+			return err
+		}
+
+		f, err := cueparser.ParseFile(path, source, cueparser.PackageClauseOnly)
+		if err != nil {
+			return fmt.Errorf("invalid CUE in %s:\n%s", path, strings.TrimSpace(cueerrors.Details(err, nil)))
+		}
+
+		namespace := f.PackageName()
+		if namespace == "" {
+			namespace = cueDefaultPackage
+		}
+
+		rules = append(rules, cueRule{
+			code:      namespace + "." + strings.TrimSuffix(filepath.Base(path), ".cue"),
+			namespace: namespace,
+			file:      path,
+			source:    source,
+		})
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return rules, nil
+}
+
+// cueRunner validates the inputs against the constraints of the CUE files of
+// the policy sources. Each violated constraint is reported as a failure of the
+// rule of its file.
+type cueRunner struct {
+	rules []cueRule
+}
+
+func (r cueRunner) Run(ctx context.Context, fileList []string) ([]Outcome, Data, error) {
+	cctx := cuecontext.New()
+
+	schemas := make([]cue.Value, 0, len(r.rules))
+	for _, cr := range r.rules {
+		v := cctx.CompileBytes(cr.source, cue.Filename(cr.file))
+		if err := v.Err(); err != nil {
+			return nil, nil, fmt.Errorf("invalid CUE in %s:\n%s", cr.file, strings.TrimSpace(cueerrors.Details(err, nil)))
+		}
+		schemas = append(schemas, v)
+	}
+
+	files, err := listFiles(fileList)
+	if err != nil {
+		return nil, nil, fmt.Errorf("parse files: %w", err)
+	}
+
+	configurations, err := parser.ParseConfigurations(files)
+	if err != nil {
+		return nil, nil, fmt.Errorf("parse configurations: %w", err)
+	}
+
+	var outcomes []Outcome
+	for _, file := range files {
+		// The configurations hold the numbers as floats, extracting them
+		// from JSON keeps the integers as CUE integers
+		data, err := json.Marshal(configurations[file])
+		if err != nil {
+			return nil, nil, fmt.Errorf("encode %s: %w", file, err)
+		}
+
+		expr, err := cuejson.Extract(file, data)
+		if err != nil {
+			return nil, nil, fmt.Errorf("encode %s: %w", file, err)
+		}
+
+		input := cctx.BuildExpr(expr)
+		if err := input.Err(); err != nil {
+			return nil, nil, fmt.Errorf("encode %s: %w", file, err)
+		}
+
+		byNamespace := map[string]*Outcome{}
+		var namespaces []string
+		for i, cr := range r.rules {
+			outcome, ok := byNamespace[cr.namespace]
+			if !ok {
+				outcome = &Outcome{
+					FileName:   file,
+					Namespace:  cr.namespace,
+					Successes:  []Result{},
+					Skipped:    []Result{},
+					Warnings:   []Result{},
+					Failures:   []Result{},
+					Exceptions: []Result{},
+				}
+				byNamespace[cr.namespace] = outcome
+				namespaces = append(namespaces, cr.namespace)
+			}
+
+			err := schemas[i].Unify(input).Validate(cue.Concrete(true))
+			for _, e := range cueerrors.Errors(err) {
+				outcome.Failures = append(outcome.Failures, Result{
+					Message: e.Error(),
+					Metadata: map[string]any{
+						metadataCode: cr.code,
+					},
+				})
+			}
+		}
+
+		for _, n := range namespaces {
+			outcomes = append(outcomes, *byNamespace[n])
+		}
+	}
+
+	return outcomes, nil, nil
+}
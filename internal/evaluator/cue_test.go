@@ -0,0 +1,136 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package evaluator
+
+import (
+	"context"
+	"os"
+	"path"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	ecc "github.com/enterprise-contract/enterprise-contract-controller/api/v1alpha1"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/enterprise-contract/ec-cli/internal/policy"
+	"github.com/enterprise-contract/ec-cli/internal/policy/source"
+)
+
+const cueReplicas = `package release
+
+replicas: int & <=3
+`
+
+const cueName = `package release
+
+name!: string
+`
+
+func TestCUERules(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "/policy/replicas.cue", []byte(cueReplicas), 0600))
+	require.NoError(t, afero.WriteFile(fs, "/policy/lib/defaults.cue", []byte("replicas: *1 | int\n"), 0600))
+	require.NoError(t, afero.WriteFile(fs, "/policy/policy.rego", []byte("package release\n"), 0600))
+
+	rules, err := cueRules(fs, "/policy")
+	require.NoError(t, err)
+	require.Len(t, rules, 2)
+	assert.Equal(t, "main.defaults", rules[0].code)
+	assert.Equal(t, "main", rules[0].namespace)
+	assert.Equal(t, "release.replicas", rules[1].code)
+	assert.Equal(t, "release", rules[1].namespace)
+	assert.Equal(t, "replicas", rules[1].info().ShortName)
+
+	require.NoError(t, afero.WriteFile(fs, "/bad/bad.cue", []byte("package {"), 0600))
+	_, err = cueRules(fs, "/bad")
+	assert.ErrorContains(t, err, "invalid CUE in /bad/bad.cue")
+}
+
+func TestConftestEvaluatorEvaluateCUE(t *testing.T) {
+	cases := []struct {
+		name      string
+		input     string
+		failures  []string
+		successes []string
+	}{
+		{
+			name:      "passing",
+			input:     `{"name": "app", "replicas": 2}`,
+			successes: []string{"release.name", "release.replicas"},
+		},
+		{
+			name:      "violated constraint",
+			input:     `{"name": "app", "replicas": 5}`,
+			failures:  []string{"release.replicas"},
+			successes: []string{"release.name"},
+		},
+		{
+			name:     "missing required field",
+			input:    `{"replicas": 5}`,
+			failures: []string{"release.name", "release.replicas"},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			dir := t.TempDir()
+			require.NoError(t, os.MkdirAll(path.Join(dir, "inputs"), 0755))
+			require.NoError(t, os.WriteFile(path.Join(dir, "inputs", "input.json"), []byte(c.input), 0600))
+
+			rules, err := rulesArchive(t, fstest.MapFS{
+				"replicas.cue": &fstest.MapFile{Data: []byte(cueReplicas)},
+				"name.cue":     &fstest.MapFile{Data: []byte(cueName)},
+			})
+			require.NoError(t, err)
+
+			config := &mockConfigProvider{}
+			config.On("EffectiveTime").Return(time.Now())
+			config.On("SigstoreOpts").Return(policy.SigstoreOpts{}, nil)
+			config.On("Spec").Return(ecc.EnterpriseContractPolicySpec{})
+
+			ctx := withCapabilities(context.Background(), testCapabilities)
+
+			evaluator, err := NewConftestEvaluator(ctx, []source.PolicySource{
+				&source.PolicyUrl{
+					Url:  rules,
+					Kind: source.PolicyKind,
+				},
+			}, config, ecc.Source{})
+			require.NoError(t, err)
+
+			results, _, err := evaluator.Evaluate(ctx, EvaluationTarget{Inputs: []string{path.Join(dir, "inputs")}})
+			require.NoError(t, err)
+			require.Len(t, results, 1)
+			assert.Equal(t, "release", results[0].Namespace)
+
+			codes := func(results []Result) []string {
+				c := []string{}
+				for _, r := range results {
+					c = append(c, r.Metadata[metadataCode].(string))
+				}
+				return c
+			}
+			assert.ElementsMatch(t, append([]string{}, c.failures...), codes(results[0].Failures))
+			assert.ElementsMatch(t, append([]string{}, c.successes...), codes(results[0].Successes))
+		})
+	}
+}
@@ -0,0 +1,67 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package evaluator
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// RuleEffectiveOn maps rule codes to the time from which failures of the rule
+// are blocking, formatted like the effective_on rule annotation. Until then
+// the failures are reported as warnings, giving a grace period for newly
+// added rules.
+type RuleEffectiveOn map[string]string
+
+// ParseRuleEffectiveOn parses the effective times given as <rule code>=<time>
+// pairs. The time is either a date, e.g. 2024-06-30, or a RFC3339 timestamp.
+func ParseRuleEffectiveOn(values []string) (RuleEffectiveOn, error) {
+	effectiveOn := RuleEffectiveOn{}
+	for _, v := range values {
+		code, value, found := strings.Cut(v, "=")
+		if !found || code == "" {
+			return nil, fmt.Errorf("invalid rule effective time %q, expected <rule code>=<YYYY-MM-DD or RFC3339 timestamp>", v)
+		}
+
+		t, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			if t, err = time.Parse("2006-01-02", value); err != nil {
+				return nil, fmt.Errorf("invalid rule effective time %q, expected <rule code>=<YYYY-MM-DD or RFC3339 timestamp>", v)
+			}
+		}
+
+		effectiveOn[code] = t.UTC().Format(effectiveOnFormat)
+	}
+
+	return effectiveOn, nil
+}
+
+// WithRuleEffectiveOn returns a context in which evaluators use the given
+// effective times in place of the effective_on annotations of the rules.
+func WithRuleEffectiveOn(ctx context.Context, effectiveOn RuleEffectiveOn) context.Context {
+	return context.WithValue(ctx, ruleEffectiveOnKey, effectiveOn)
+}
+
+func ruleEffectiveOnFrom(ctx context.Context) RuleEffectiveOn {
+	if effectiveOn, ok := ctx.Value(ruleEffectiveOnKey).(RuleEffectiveOn); ok {
+		return effectiveOn
+	}
+
+	return nil
+}
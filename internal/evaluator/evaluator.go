@@ -38,17 +38,27 @@ type Evaluator interface {
 type Data map[string]any
 
 type Outcome struct {
-	FileName   string   `json:"filename"`
-	Namespace  string   `json:"namespace"`
-	Successes  []Result `json:"successes,omitempty"`
-	Skipped    []Result `json:"skipped,omitempty"`
-	Warnings   []Result `json:"warnings,omitempty"`
-	Failures   []Result `json:"failures,omitempty"`
+	FileName  string   `json:"filename"`
+	Namespace string   `json:"namespace"`
+	Successes []Result `json:"successes,omitempty"`
+	Skipped   []Result `json:"skipped,omitempty"`
+	Warnings  []Result `json:"warnings,omitempty"`
+	Failures  []Result `json:"failures,omitempty"`
+	// Infos are the results reclassified as informational, see
+	// SeverityOverrides
+	Infos      []Result `json:"infos,omitempty"`
 	Exceptions []Result `json:"exceptions,omitempty"`
+	// Evaluated is the number of rules evaluated by runners that do not
+	// report the successes, i.e. the rules compiled to WebAssembly and the
+	// rules evaluated by remote OPA servers
+	Evaluated int `json:"-"`
 }
 
 type Result struct {
 	Message  string                 `json:"msg"`
 	Metadata map[string]interface{} `json:"metadata,omitempty"`
 	Outputs  []string               `json:"outputs,omitempty"`
+	// Fingerprint identifies the result across runs regardless of the
+	// volatile data in its message, see the fingerprint package
+	Fingerprint string `json:"fingerprint,omitempty"`
 }
@@ -0,0 +1,216 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package evaluator
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	conftest "github.com/open-policy-agent/conftest/policy"
+	"github.com/open-policy-agent/opa/ast"
+	"github.com/open-policy-agent/opa/rego"
+	"github.com/open-policy-agent/opa/topdown"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/enterprise-contract/ec-cli/internal/opa/rule"
+)
+
+const metadataTrace = "trace"
+
+// maxBoundValueLength limits the length of the bound values shown in the
+// trace, values such as the attestations can be very large
+const maxBoundValueLength = 80
+
+// explainedOps are the trace events reported when explaining a rule: entering
+// the rule, evaluating each of its expressions, expressions that failed and
+// leaving the rule with a result.
+var explainedOps = []topdown.Op{topdown.EnterOp, topdown.EvalOp, topdown.FailOp, topdown.ExitOp}
+
+// WithExplain returns a context in which evaluators capture the evaluation
+// trace of the rules with the given codes. The trace is added to the results
+// of those rules in the trace metadata.
+func WithExplain(ctx context.Context, codes []string) context.Context {
+	return context.WithValue(ctx, explainKey, codes)
+}
+
+func explainFrom(ctx context.Context) []string {
+	if codes, ok := ctx.Value(explainKey).([]string); ok {
+		return codes
+	}
+
+	return nil
+}
+
+// explain evaluates the rules with the given codes against each of the input
+// documents, and returns the trace of the evaluation, by rule code.
+func explain(ctx context.Context, engine *conftest.Engine, policyDir string, codes []string, documents []any) (map[string][]string, error) {
+	compiler := engine.Compiler()
+
+	traces := map[string][]string{}
+	for _, a := range compiler.GetAnnotationSet().Flatten() {
+		r := a.GetRule()
+		if r == nil {
+			continue
+		}
+
+		code := rule.RuleInfo(a).Code
+		if !slices.Contains(codes, code) {
+			continue
+		}
+
+		for _, doc := range documents {
+			tracer := topdown.NewBufferTracer()
+			_, err := rego.New(
+				rego.Query(a.Path.String()),
+				rego.Compiler(compiler),
+				rego.Store(engine.Store()),
+				rego.Runtime(engine.Runtime()),
+				rego.Input(doc),
+				rego.QueryTracer(tracer),
+			).Eval(ctx)
+			if err != nil {
+				return nil, fmt.Errorf("explaining rule %s: %w", code, err)
+			}
+
+			traces[code] = append(traces[code], ruleTrace(*tracer, r, policyDir)...)
+		}
+	}
+
+	for _, code := range codes {
+		if _, ok := traces[code]; !ok {
+			log.Debugf("No rule with the code %q to explain", code)
+		}
+	}
+
+	return traces, nil
+}
+
+// ruleTrace formats the trace events within the body of the given rule, the
+// events of other rules evaluated by the same query are left out.
+func ruleTrace(events []*topdown.Event, r *ast.Rule, policyDir string) []string {
+	if r.Location == nil {
+		return nil
+	}
+	first := r.Location.Row
+	last := first + strings.Count(string(r.Location.Text), "\n")
+
+	lines := []string{}
+	for _, e := range events {
+		if e.Location == nil || e.Location.File != r.Location.File || e.Location.Row < first || e.Location.Row > last {
+			continue
+		}
+		if !slices.Contains(explainedOps, e.Op) {
+			continue
+		}
+		lines = append(lines, formatEvent(e, policyDir))
+	}
+
+	return lines
+}
+
+// formatEvent formats the event as the location, the operation and the
+// source of the expression, followed by the values bound to its variables,
+// e.g.:
+//
+//	release/attestation.rego:12: Fail count(att) > 0 (att = [])
+func formatEvent(e *topdown.Event, policyDir string) string {
+	// Each policy source is downloaded to its own directory within the
+	// policy directory, the file is shown relative to the source directory
+	file := e.Location.File
+	if rel, err := filepath.Rel(policyDir, file); err == nil && !strings.HasPrefix(rel, "..") {
+		if _, f, found := strings.Cut(filepath.ToSlash(rel), "/"); found {
+			file = f
+		}
+	}
+
+	source, _, _ := strings.Cut(string(e.Location.Text), "\n")
+	line := fmt.Sprintf("%s:%d: %s %s", file, e.Location.Row, e.Op, strings.TrimSpace(source))
+
+	if bound := boundValues(e); len(bound) > 0 {
+		line += fmt.Sprintf(" (%s)", strings.Join(bound, ", "))
+	}
+
+	return line
+}
+
+// boundValues returns the values bound to the variables of the expression of
+// the event, using the variable names as written in the policy.
+func boundValues(e *topdown.Event) []string {
+	expr, ok := e.Node.(*ast.Expr)
+	if !ok || e.Locals == nil {
+		return nil
+	}
+
+	bound := []string{}
+	seen := map[ast.Var]bool{}
+	ast.WalkVars(expr, func(v ast.Var) bool {
+		if seen[v] {
+			return false
+		}
+		seen[v] = true
+
+		name := v
+		if m, ok := e.LocalMetadata[v]; ok {
+			name = m.Name
+		}
+		if name.IsGenerated() || name.IsWildcard() {
+			return false
+		}
+
+		value := e.Locals.Get(v)
+		if value == nil {
+			return false
+		}
+
+		s := value.String()
+		if len(s) > maxBoundValueLength {
+			s = s[:maxBoundValueLength] + "..."
+		}
+		bound = append(bound, fmt.Sprintf("%s = %s", name, s))
+
+		return false
+	})
+
+	return bound
+}
+
+// withTraces adds the trace of the rule to each of the results of the rule.
+func withTraces(results []Outcome, traces map[string][]string) {
+	add := func(rs []Result) {
+		for i := range rs {
+			code := ExtractStringFromMetadata(rs[i], metadataCode)
+			if trace, ok := traces[code]; ok {
+				if rs[i].Metadata == nil {
+					rs[i].Metadata = map[string]any{}
+				}
+				rs[i].Metadata[metadataTrace] = trace
+			}
+		}
+	}
+
+	for _, o := range results {
+		add(o.Successes)
+		add(o.Warnings)
+		add(o.Failures)
+		add(o.Infos)
+		add(o.Exceptions)
+		add(o.Skipped)
+	}
+}
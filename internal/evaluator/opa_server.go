@@ -0,0 +1,192 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package evaluator
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/open-policy-agent/conftest/parser"
+
+	"github.com/enterprise-contract/ec-cli/internal/logging"
+	"github.com/enterprise-contract/ec-cli/internal/policy/source"
+)
+
+// opaDataAPI is the path of the Data API of the OPA server, the package of the
+// rules evaluated follows it
+const opaDataAPI = "/v1/data/"
+
+// maxOPADecisionSize is the maximum size of the decision read from the OPA
+// server, in bytes
+var maxOPADecisionSize int64 = 10 << 20
+
+// opaServer is a package of warn and deny rules evaluated by a remote OPA
+// server.
+type opaServer struct {
+	url       *url.URL
+	namespace string
+}
+
+// newOPAServer returns the package of rules evaluated using the Data API of
+// the OPA server at the given URL, e.g.
+// https://opa.example.com/v1/data/release for the release package. The server
+// must be queried over HTTPS, as the inputs and the headers configured for the
+// server, e.g. for authorization, are sent to it.
+func newOPAServer(u *url.URL) (opaServer, error) {
+	if u.Scheme != "https" {
+		return opaServer{}, fmt.Errorf("the OPA server %s must be queried over HTTPS, use opa+https://", logging.RedactURL(u.String()))
+	}
+
+	pkg, ok := strings.CutPrefix(u.Path, opaDataAPI)
+	pkg = strings.Trim(pkg, "/")
+	if !ok || pkg == "" {
+		return opaServer{}, fmt.Errorf("the URL of the OPA server %s does not point to a package, expected %s<package path>", logging.RedactURL(u.String()), opaDataAPI)
+	}
+
+	return opaServer{
+		url:       u,
+		namespace: strings.ReplaceAll(pkg, "/", "."),
+	}, nil
+}
+
+// opaServerRunner evaluates the warn and deny rules of the packages evaluated
+// by remote OPA servers. The servers evaluate the rules with their own data,
+// the data sources are not provided to them. Exceptions and the number of
+// successes are not reported by the servers.
+type opaServerRunner struct {
+	servers []opaServer
+}
+
+func (r opaServerRunner) Run(ctx context.Context, fileList []string) ([]Outcome, Data, error) {
+	if source.IsOffline() {
+		return nil, nil, errors.New("the policy cannot be evaluated by a remote OPA server when offline")
+	}
+
+	files, err := listFiles(fileList)
+	if err != nil {
+		return nil, nil, fmt.Errorf("parse files: %w", err)
+	}
+
+	configurations, err := parser.ParseConfigurations(files)
+	if err != nil {
+		return nil, nil, fmt.Errorf("parse configurations: %w", err)
+	}
+
+	var outcomes []Outcome
+	for _, file := range files {
+		for _, s := range r.servers {
+			outcome, err := s.evaluate(ctx, configurations[file])
+			if err != nil {
+				return nil, nil, err
+			}
+			outcome.FileName = file
+			outcomes = append(outcomes, outcome)
+		}
+	}
+
+	return outcomes, nil, nil
+}
+
+// evaluate posts the input to the Data API of the OPA server, returning the
+// outcome of the warn and deny rules of the package.
+func (s opaServer) evaluate(ctx context.Context, input any) (Outcome, error) {
+	body, err := json.Marshal(map[string]any{"input": input})
+	if err != nil {
+		return Outcome{}, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url.String(), bytes.NewReader(body))
+	if err != nil {
+		return Outcome{}, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := source.DoHTTP(ctx, req)
+	if err != nil {
+		return Outcome{}, fmt.Errorf("unable to query the OPA server %s: %w", logging.RedactURL(s.url.String()), err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return Outcome{}, fmt.Errorf("unable to query the OPA server %s: %s %s", logging.RedactURL(s.url.String()), resp.Status, strings.TrimSpace(string(msg)))
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxOPADecisionSize+1))
+	if err != nil {
+		return Outcome{}, fmt.Errorf("unable to read the response of the OPA server %s: %w", logging.RedactURL(s.url.String()), err)
+	}
+	if int64(len(data)) > maxOPADecisionSize {
+		return Outcome{}, fmt.Errorf("the response of the OPA server %s exceeds the maximum size of %d bytes", logging.RedactURL(s.url.String()), maxOPADecisionSize)
+	}
+
+	var decision struct {
+		Result map[string]any `json:"result"`
+	}
+	if err := json.Unmarshal(data, &decision); err != nil {
+		return Outcome{}, fmt.Errorf("unable to read the response of the OPA server %s: %w", logging.RedactURL(s.url.String()), err)
+	}
+	if decision.Result == nil {
+		return Outcome{}, fmt.Errorf("the package %s is not defined by the OPA server %s", s.namespace, logging.RedactURL(s.url.String()))
+	}
+
+	outcome := Outcome{
+		Namespace:  s.namespace,
+		Successes:  []Result{},
+		Skipped:    []Result{},
+		Warnings:   []Result{},
+		Failures:   []Result{},
+		Exceptions: []Result{},
+	}
+
+	// Go maps are not ordered, the rules are reported in the order of their
+	// names
+	names := make([]string, 0, len(decision.Result))
+	for name := range decision.Result {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		warning := warningRuleRegex.MatchString(name)
+		if !warning && !failureRuleRegex.MatchString(name) {
+			continue
+		}
+
+		results, err := ruleResults(decision.Result[name])
+		if err != nil {
+			return Outcome{}, fmt.Errorf("rule %s of the OPA server %s: %w", name, logging.RedactURL(s.url.String()), err)
+		}
+		outcome.Evaluated++
+
+		if warning {
+			outcome.Warnings = append(outcome.Warnings, toRules(results)...)
+		} else {
+			outcome.Failures = append(outcome.Failures, toRules(results)...)
+		}
+	}
+
+	return outcome, nil
+}
@@ -0,0 +1,211 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package evaluator
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path"
+	"strings"
+	"testing"
+	"time"
+
+	ecc "github.com/enterprise-contract/enterprise-contract-controller/api/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/enterprise-contract/ec-cli/internal/policy"
+	"github.com/enterprise-contract/ec-cli/internal/policy/source"
+)
+
+func TestNewOPAServer(t *testing.T) {
+	u, err := url.Parse("https://opa.example.com/v1/data/release/lib/")
+	require.NoError(t, err)
+	s, err := newOPAServer(u)
+	require.NoError(t, err)
+	assert.Equal(t, "release.lib", s.namespace)
+
+	for _, invalid := range []string{"https://opa.example.com", "https://opa.example.com/v1/data/", "https://opa.example.com/release"} {
+		u, err := url.Parse(invalid)
+		require.NoError(t, err)
+		_, err = newOPAServer(u)
+		assert.ErrorContains(t, err, "does not point to a package", invalid)
+	}
+
+	u, err = url.Parse("http://opa.example.com/v1/data/release")
+	require.NoError(t, err)
+	_, err = newOPAServer(u)
+	assert.EqualError(t, err, "the OPA server http://opa.example.com/REDACTED must be queried over HTTPS, use opa+https://")
+}
+
+// opaServerContext returns a context trusting the certificate of the test
+// server
+func opaServerContext(t *testing.T, server *httptest.Server) context.Context {
+	bundle := path.Join(t.TempDir(), "ca.pem")
+	require.NoError(t, os.WriteFile(bundle, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw}), 0600))
+
+	return source.WithHTTPOptions(context.Background(), source.HTTPOptions{CABundle: bundle})
+}
+
+func TestConftestEvaluatorEvaluateOPAServer(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost && r.URL.Path == "/v1/data/norules" {
+			_, _ = w.Write([]byte(`{"result": {"allow": true}}`))
+			return
+		}
+		if r.Method != http.MethodPost || r.URL.Path != "/v1/data/release" {
+			w.WriteHeader(http.StatusOK)
+			_, _ = w.Write([]byte(`{}`))
+			return
+		}
+
+		var req struct {
+			Input map[string]any `json:"input"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		result := map[string]any{"deny": []any{}, "warn": []any{}, "allow": true}
+		if req.Input["denied"] == true {
+			result["deny"] = []any{map[string]any{"code": "release.denied", "msg": "Denied"}}
+		}
+		if req.Input["warned"] == true {
+			result["warn_other"] = []any{"Warned"}
+		}
+
+		_ = json.NewEncoder(w).Encode(map[string]any{"result": result})
+	}))
+	t.Cleanup(server.Close)
+
+	cases := []struct {
+		name     string
+		url      string
+		input    string
+		failures []string
+		warnings []string
+		err      string
+	}{
+		{
+			name:     "violations and warnings",
+			url:      "opa+" + server.URL + "/v1/data/release",
+			input:    `{"denied": true, "warned": true}`,
+			failures: []string{"Denied"},
+			warnings: []string{"Warned"},
+		},
+		{
+			name:     "passing",
+			url:      "opa+" + server.URL + "/v1/data/release",
+			input:    `{}`,
+			failures: []string{},
+			warnings: []string{},
+		},
+		{
+			name:  "package without rules",
+			url:   "opa+" + server.URL + "/v1/data/norules",
+			input: `{}`,
+			err:   ErrNoApplicableRules.Error(),
+		},
+		{
+			name:  "undefined package",
+			url:   "opa+" + server.URL + "/v1/data/nope",
+			input: `{}`,
+			err:   "the package nope is not defined by the OPA server",
+		},
+		{
+			name:  "plain HTTP",
+			url:   "opa+http://" + server.Listener.Addr().String() + "/v1/data/release",
+			input: `{}`,
+			err:   "must be queried over HTTPS, use opa+https://",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			dir := t.TempDir()
+			require.NoError(t, os.MkdirAll(path.Join(dir, "inputs"), 0755))
+			require.NoError(t, os.WriteFile(path.Join(dir, "inputs", "input.json"), []byte(c.input), 0600))
+
+			config := &mockConfigProvider{}
+			config.On("EffectiveTime").Return(time.Now())
+			config.On("SigstoreOpts").Return(policy.SigstoreOpts{}, nil)
+			config.On("Spec").Return(ecc.EnterpriseContractPolicySpec{})
+
+			ctx := withCapabilities(opaServerContext(t, server), testCapabilities)
+
+			evaluator, err := NewConftestEvaluator(ctx, []source.PolicySource{
+				&source.PolicyUrl{
+					Url:  c.url,
+					Kind: source.PolicyKind,
+				},
+			}, config, ecc.Source{})
+			require.NoError(t, err)
+
+			results, _, err := evaluator.Evaluate(ctx, EvaluationTarget{Inputs: []string{path.Join(dir, "inputs")}})
+			if c.err != "" {
+				assert.ErrorContains(t, err, c.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Len(t, results, 1)
+			assert.Equal(t, "release", results[0].Namespace)
+
+			messages := func(results []Result) []string {
+				m := []string{}
+				for _, r := range results {
+					m = append(m, r.Message)
+				}
+				return m
+			}
+			assert.ElementsMatch(t, c.failures, messages(results[0].Failures))
+			assert.ElementsMatch(t, c.warnings, messages(results[0].Warnings))
+		})
+	}
+}
+
+func TestOPAServerDecisionSize(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"result": {"deny": ["` + strings.Repeat("x", 100) + `"]}}`))
+	}))
+	t.Cleanup(server.Close)
+
+	u, err := url.Parse(server.URL + "/v1/data/release")
+	require.NoError(t, err)
+	s, err := newOPAServer(u)
+	require.NoError(t, err)
+
+	ctx := opaServerContext(t, server)
+
+	outcome, err := s.evaluate(ctx, map[string]any{})
+	require.NoError(t, err)
+	assert.Len(t, outcome.Failures, 1)
+
+	original := maxOPADecisionSize
+	t.Cleanup(func() { maxOPADecisionSize = original })
+	maxOPADecisionSize = 100
+
+	_, err = s.evaluate(ctx, map[string]any{})
+	assert.ErrorContains(t, err, "exceeds the maximum size of 100 bytes")
+}
@@ -0,0 +1,197 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package evaluator
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	ecc "github.com/enterprise-contract/enterprise-contract-controller/api/v1alpha1"
+)
+
+// Severities a result can be classified as. Violations fail the validation,
+// warnings and informational results do not.
+const (
+	SeverityViolation = "violation"
+	SeverityWarning   = "warning"
+	SeverityInfo      = "info"
+)
+
+// SeverityLevels are the levels of violations, from the least to the most
+// severe. The level of a violation is set by the severity annotation of the
+// rule, or by an override, and is held in the severity metadata of the result.
+var SeverityLevels = []string{"low", "medium", "high", "critical"}
+
+const (
+	metadataSeverity         = "severity"
+	metadataOriginalSeverity = "original_severity"
+)
+
+// SeverityOverrides maps rule codes, or packages, to the severity the results
+// of the rules are reported with: violation, warning, info or any of the
+// SeverityLevels, which makes the results violations of that level.
+type SeverityOverrides map[string]string
+
+func validSeverity(severity string) bool {
+	return severity == SeverityViolation || severity == SeverityWarning || severity == SeverityInfo ||
+		slices.Contains(SeverityLevels, severity)
+}
+
+// ParseSeverityOverrides parses the overrides given as <rule code>=<severity>
+// pairs.
+func ParseSeverityOverrides(values []string) (SeverityOverrides, error) {
+	overrides := SeverityOverrides{}
+	for _, v := range values {
+		code, severity, found := strings.Cut(v, "=")
+		if !found || code == "" || !validSeverity(severity) {
+			return nil, fmt.Errorf("invalid severity override %q, expected <rule code>=<%s>", v,
+				strings.Join(append([]string{SeverityViolation, SeverityWarning, SeverityInfo}, SeverityLevels...), "|"))
+		}
+		overrides[code] = severity
+	}
+
+	return overrides, nil
+}
+
+// WithSeverityOverrides returns a context in which evaluators apply the given
+// overrides, taking precedence over the overrides in the policy configuration.
+func WithSeverityOverrides(ctx context.Context, overrides SeverityOverrides) context.Context {
+	return context.WithValue(ctx, severityOverridesKey, overrides)
+}
+
+func severityOverridesFrom(ctx context.Context) SeverityOverrides {
+	if overrides, ok := ctx.Value(severityOverridesKey).(SeverityOverrides); ok {
+		return overrides
+	}
+
+	return nil
+}
+
+// severityConfig is the severity configuration within the rule data of a
+// source group of the policy
+type severityConfig struct {
+	Overrides      SeverityOverrides `json:"severity_overrides"`
+	FailOnSeverity string            `json:"fail_on_severity"`
+}
+
+func sourceSeverityConfig(s ecc.Source) (severityConfig, error) {
+	var config severityConfig
+	if s.RuleData == nil || len(s.RuleData.Raw) == 0 {
+		return config, nil
+	}
+
+	// Other rule data is not of interest here, only report invalid severity
+	// configuration
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(s.RuleData.Raw, &raw); err != nil {
+		return config, nil
+	}
+	if o, ok := raw["severity_overrides"]; ok {
+		if err := json.Unmarshal(o, &config.Overrides); err != nil {
+			return config, fmt.Errorf("invalid severity_overrides in the rule data of source %q: %w", s.Name, err)
+		}
+		for code, severity := range config.Overrides {
+			if !validSeverity(severity) {
+				return config, fmt.Errorf("invalid severity %q for %q in the severity_overrides of source %q", severity, code, s.Name)
+			}
+		}
+	}
+	if f, ok := raw["fail_on_severity"]; ok {
+		if err := json.Unmarshal(f, &config.FailOnSeverity); err != nil || !slices.Contains(SeverityLevels, config.FailOnSeverity) {
+			return config, fmt.Errorf("invalid fail_on_severity in the rule data of source %q, expected one of: %s", s.Name, strings.Join(SeverityLevels, ", "))
+		}
+	}
+
+	return config, nil
+}
+
+// PolicyFailOnSeverity returns the fail_on_severity set in the rule data of the
+// sources of the policy. The lowest level applies when set by multiple
+// sources.
+func PolicyFailOnSeverity(spec ecc.EnterpriseContractPolicySpec) (string, error) {
+	level := ""
+	for _, s := range spec.Sources {
+		config, err := sourceSeverityConfig(s)
+		if err != nil {
+			return "", err
+		}
+		if config.FailOnSeverity != "" && (level == "" || slices.Index(SeverityLevels, config.FailOnSeverity) < slices.Index(SeverityLevels, level)) {
+			level = config.FailOnSeverity
+		}
+	}
+
+	return level, nil
+}
+
+// lookup returns the override for the rule code, or for its package.
+func (o SeverityOverrides) lookup(code string) (string, bool) {
+	if severity, ok := o[code]; ok {
+		return severity, true
+	}
+	if i := strings.LastIndex(code, "."); i > 0 {
+		severity, ok := o[code[:i]]
+		return severity, ok
+	}
+
+	return "", false
+}
+
+// reclassify applies the override to the result reported with the given
+// severity, returning the severity to report the result with. The original
+// severity is recorded in the metadata of an overridden result.
+func (o SeverityOverrides) reclassify(r *Result, severity string) string {
+	code, _ := r.Metadata[metadataCode].(string)
+	override, ok := o.lookup(code)
+	if !ok {
+		return severity
+	}
+
+	original := severity
+	if level, ok := r.Metadata[metadataSeverity].(string); ok && severity == SeverityViolation && level != "" {
+		original = level
+	}
+	if override == original {
+		return severity
+	}
+
+	r.Metadata[metadataOriginalSeverity] = original
+	if slices.Contains(SeverityLevels, override) {
+		r.Metadata[metadataSeverity] = override
+		return SeverityViolation
+	}
+
+	return override
+}
+
+// Blocking returns true if the violation fails the validation with the given
+// threshold, i.e. if the level of the violation is at least the threshold.
+// Violations without a level are always blocking, as are all violations
+// without a threshold.
+func Blocking(r Result, threshold string) bool {
+	t := slices.Index(SeverityLevels, threshold)
+	if t == -1 {
+		return true
+	}
+
+	level, _ := r.Metadata[metadataSeverity].(string)
+	l := slices.Index(SeverityLevels, level)
+
+	return l == -1 || l >= t
+}
@@ -0,0 +1,99 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package evaluator
+
+import (
+	"testing"
+
+	ecc "github.com/enterprise-contract/enterprise-contract-controller/api/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	extv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+)
+
+func TestParseSeverityOverrides(t *testing.T) {
+	overrides, err := ParseSeverityOverrides([]string{"tasks.required_tasks_found=warning", "cve=critical"})
+	require.NoError(t, err)
+	assert.Equal(t, SeverityOverrides{"tasks.required_tasks_found": "warning", "cve": "critical"}, overrides)
+
+	for _, invalid := range []string{"tasks", "=info", "tasks=fatal"} {
+		_, err := ParseSeverityOverrides([]string{invalid})
+		assert.EqualError(t, err, `invalid severity override "`+invalid+`", expected <rule code>=<violation|warning|info|low|medium|high|critical>`)
+	}
+}
+
+func TestReclassify(t *testing.T) {
+	overrides := SeverityOverrides{"a.b": "info", "a": "high", "c.d": "violation"}
+
+	result := func(code string) Result {
+		return Result{Metadata: map[string]any{"code": code}}
+	}
+
+	r := result("a.b")
+	assert.Equal(t, SeverityInfo, overrides.reclassify(&r, SeverityViolation))
+	assert.Equal(t, "violation", r.Metadata["original_severity"])
+
+	r = result("a.c")
+	r.Metadata["severity"] = "low"
+	assert.Equal(t, SeverityViolation, overrides.reclassify(&r, SeverityViolation))
+	assert.Equal(t, "high", r.Metadata["severity"])
+	assert.Equal(t, "low", r.Metadata["original_severity"])
+
+	r = result("c.d")
+	assert.Equal(t, SeverityViolation, overrides.reclassify(&r, SeverityViolation))
+	assert.NotContains(t, r.Metadata, "original_severity")
+
+	r = result("e.f")
+	assert.Equal(t, SeverityWarning, overrides.reclassify(&r, SeverityWarning))
+	assert.NotContains(t, r.Metadata, "original_severity")
+}
+
+func TestBlocking(t *testing.T) {
+	withLevel := func(level string) Result {
+		return Result{Metadata: map[string]any{"severity": level}}
+	}
+
+	assert.True(t, Blocking(withLevel("low"), ""))
+	assert.True(t, Blocking(Result{}, "critical"))
+	assert.True(t, Blocking(withLevel("unknown"), "critical"))
+	assert.False(t, Blocking(withLevel("high"), "critical"))
+	assert.True(t, Blocking(withLevel("critical"), "critical"))
+	assert.True(t, Blocking(withLevel("high"), "medium"))
+}
+
+func TestPolicyFailOnSeverity(t *testing.T) {
+	source := func(ruleData string) ecc.Source {
+		return ecc.Source{Name: "source", RuleData: &extv1.JSON{Raw: []byte(ruleData)}}
+	}
+
+	level, err := PolicyFailOnSeverity(ecc.EnterpriseContractPolicySpec{Sources: []ecc.Source{
+		{},
+		source(`{"fail_on_severity": "critical"}`),
+		source(`{"fail_on_severity": "high", "other": true}`),
+	}})
+	require.NoError(t, err)
+	assert.Equal(t, "high", level)
+
+	level, err = PolicyFailOnSeverity(ecc.EnterpriseContractPolicySpec{})
+	require.NoError(t, err)
+	assert.Equal(t, "", level)
+
+	_, err = PolicyFailOnSeverity(ecc.EnterpriseContractPolicySpec{Sources: []ecc.Source{source(`{"fail_on_severity": "fatal"}`)}})
+	assert.EqualError(t, err, `invalid fail_on_severity in the rule data of source "source", expected one of: low, medium, high, critical`)
+}
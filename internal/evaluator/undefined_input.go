@@ -0,0 +1,253 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package evaluator
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/open-policy-agent/opa/ast"
+	"github.com/spf13/afero"
+	"sigs.k8s.io/yaml"
+
+	"github.com/enterprise-contract/ec-cli/internal/utils"
+)
+
+// undefinedInputCode is the code of the warnings reported for references to
+// paths that are not present in the input.
+const undefinedInputCode = "builtin.input.undefined_reference"
+
+// inputRef is a reference to a path within the input document made by a
+// policy rule.
+type inputRef struct {
+	// path holds the constant keys of the reference, e.g. image and ref for
+	// input.image.ref, up to the first non-constant term
+	path      []string
+	location  string
+	namespace string
+}
+
+func (r inputRef) String() string {
+	return "input." + strings.Join(r.path, ".")
+}
+
+// WithStrictInput returns a context in which evaluators report references the
+// policy rules make to paths not present in the input as warnings.
+func WithStrictInput(ctx context.Context) context.Context {
+	return context.WithValue(ctx, strictInputKey, true)
+}
+
+// inputRefs returns the references to the input document made in the rego
+// files, excluding tests, within the directory. Each path is returned once
+// with the location of its first reference.
+func inputRefs(afs afero.Fs, dir string) ([]inputRef, error) {
+	refs := []inputRef{}
+	seen := map[string]bool{}
+
+	err := afero.Walk(afs, dir, func(path string, info fs.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() || filepath.Ext(path) != ".rego" || strings.HasSuffix(path, "_test.rego") {
+			return nil
+		}
+
+		content, err := afero.ReadFile(afs, path)
+		if err != nil {
+			return err
+		}
+
+		module, err := ast.ParseModule(path, string(content))
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			rel = path
+		}
+		namespace := strings.TrimPrefix(module.Package.Path.String(), "data.")
+
+		ast.WalkRefs(module, func(ref ast.Ref) bool {
+			if !ref.HasPrefix(ast.InputRootRef) {
+				return false
+			}
+
+			r := inputRef{namespace: namespace}
+			for _, t := range ref[1:] {
+				s, ok := t.Value.(ast.String)
+				if !ok {
+					break
+				}
+				r.path = append(r.path, string(s))
+			}
+
+			if len(r.path) == 0 || seen[r.String()] {
+				return false
+			}
+			seen[r.String()] = true
+
+			r.location = rel
+			if ref[0].Location != nil {
+				r.location = fmt.Sprintf("%s:%d", rel, ref[0].Location.Row)
+			}
+			refs = append(refs, r)
+
+			return false
+		})
+
+		return nil
+	})
+
+	return refs, err
+}
+
+// isDefined returns true if the path is present in the input. Paths through
+// values other than objects, e.g. arrays, are considered present.
+func isDefined(input any, path []string) bool {
+	for _, p := range path {
+		obj, ok := input.(map[string]any)
+		if !ok {
+			return true
+		}
+
+		if input, ok = obj[p]; !ok {
+			return false
+		}
+	}
+
+	return true
+}
+
+// readInputs reads the input documents from the given files, or the JSON and
+// YAML files within the given directories.
+func readInputs(afs afero.Fs, inputs []string) ([]any, error) {
+	documents := make([]any, 0, len(inputs))
+	for _, i := range inputs {
+		err := afero.Walk(afs, i, func(path string, info fs.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			if info.IsDir() {
+				return nil
+			}
+			if path != i && !slices.Contains([]string{".json", ".yaml", ".yml"}, filepath.Ext(path)) {
+				return nil
+			}
+
+			content, err := afero.ReadFile(afs, path)
+			if err != nil {
+				return err
+			}
+
+			var doc any
+			if err := yaml.Unmarshal(content, &doc); err != nil {
+				return fmt.Errorf("reading input %s: %w", path, err)
+			}
+			documents = append(documents, doc)
+
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return documents, nil
+}
+
+// undefinedInputWarnings returns a warning for each reference made by the
+// policy rules to a path that is not present in any of the inputs.
+func (c conftestEvaluator) undefinedInputWarnings(ctx context.Context, inputs []string) (map[string][]Result, error) {
+	fs := utils.FS(ctx)
+
+	// each policy source is downloaded into its own directory
+	sources, err := afero.ReadDir(fs, c.policyDir)
+	if err != nil {
+		return nil, err
+	}
+
+	refs := []inputRef{}
+	for _, s := range sources {
+		if !s.IsDir() {
+			continue
+		}
+		r, err := inputRefs(fs, filepath.Join(c.policyDir, s.Name()))
+		if err != nil {
+			return nil, err
+		}
+		refs = append(refs, r...)
+	}
+
+	documents, err := readInputs(fs, inputs)
+	if err != nil {
+		return nil, err
+	}
+
+	warnings := map[string][]Result{}
+	for _, r := range refs {
+		defined := false
+		for _, doc := range documents {
+			if isDefined(doc, r.path) {
+				defined = true
+				break
+			}
+		}
+
+		if !defined {
+			warnings[r.namespace] = append(warnings[r.namespace], Result{
+				Message: fmt.Sprintf("Reference to undefined input path %s at %s", r, r.location),
+				Metadata: map[string]any{
+					metadataCode: undefinedInputCode,
+				},
+			})
+		}
+	}
+
+	return warnings, nil
+}
+
+// withUndefinedInputWarnings adds the warnings, given by namespace, to the
+// outcome of the namespace.
+func withUndefinedInputWarnings(results []Outcome, warnings map[string][]Result) []Outcome {
+	namespaces := make([]string, 0, len(warnings))
+	for ns := range warnings {
+		namespaces = append(namespaces, ns)
+	}
+	slices.Sort(namespaces)
+
+	for _, ns := range namespaces {
+		i := slices.IndexFunc(results, func(o Outcome) bool {
+			return o.Namespace == ns
+		})
+		if i == -1 {
+			o := Outcome{Namespace: ns}
+			if len(results) > 0 {
+				o.FileName = results[0].FileName
+			}
+			results = append(results, o)
+			i = len(results) - 1
+		}
+		results[i].Warnings = append(results[i].Warnings, warnings[ns]...)
+	}
+
+	return results
+}
@@ -0,0 +1,303 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package evaluator
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/open-policy-agent/conftest/output"
+	"github.com/open-policy-agent/conftest/parser"
+	"github.com/open-policy-agent/opa/bundle"
+	"github.com/open-policy-agent/opa/loader"
+	"github.com/open-policy-agent/opa/rego"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
+
+	"github.com/enterprise-contract/ec-cli/internal/tracing"
+)
+
+// wasmAvailable is set when the WebAssembly engine is included in the build,
+// which requires cgo
+var wasmAvailable = false
+
+// ErrWasmNotSupported is returned when evaluating policy sources compiled to
+// WebAssembly with a build that does not include the WebAssembly engine.
+var ErrWasmNotSupported = errors.New("policies compiled to WebAssembly are not supported by this build")
+
+// Same as the rules queried by Conftest
+var (
+	warningRuleRegex = regexp.MustCompile("^warn(_[a-zA-Z0-9]+)*$")
+	failureRuleRegex = regexp.MustCompile("^(deny|violation)(_[a-zA-Z0-9]+)*$")
+)
+
+// isWasmSource returns true if the policy source downloaded to the given
+// directory is an OPA bundle built for the wasm target, i.e. its manifest
+// declares entrypoints resolved by WebAssembly modules.
+func isWasmSource(afs afero.Fs, dir string) (bool, error) {
+	content, err := afero.ReadFile(afs, filepath.Join(dir, bundle.ManifestExt))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	var manifest bundle.Manifest
+	if err := json.Unmarshal(content, &manifest); err != nil {
+		return false, fmt.Errorf("reading the manifest of %s: %w", dir, err)
+	}
+
+	return len(manifest.WasmResolvers) > 0, nil
+}
+
+// wasmQuery is a prepared query of a warn or deny rule compiled to
+// WebAssembly.
+type wasmQuery struct {
+	namespace string
+	warning   bool
+	query     rego.PreparedEvalQuery
+}
+
+// wasmEngine holds the prepared queries of the rules compiled to WebAssembly.
+// Like the engineCache, it is prepared once and shared by all evaluations
+// performed by the evaluator.
+type wasmEngine struct {
+	once    sync.Once
+	queries []wasmQuery
+	data    Data
+	err     error
+}
+
+// load returns the prepared queries of the entrypoints of the given bundle
+// directories, with the data from the data directory, preparing them on first
+// use.
+func (e *wasmEngine) load(ctx context.Context, policyDirs []string, dataDir string, namespaces []string) ([]wasmQuery, Data, error) {
+	e.once.Do(func() {
+		_, span := tracing.Start(ctx, "compile wasm")
+		defer func() { tracing.End(span, e.err) }()
+
+		e.queries, e.data, e.err = prepareWasm(ctx, policyDirs, dataDir, namespaces)
+	})
+
+	return e.queries, e.data, e.err
+}
+
+func prepareWasm(ctx context.Context, policyDirs []string, dataDir string, namespaces []string) ([]wasmQuery, Data, error) {
+	if !wasmAvailable {
+		return nil, nil, ErrWasmNotSupported
+	}
+
+	data, err := loadData(dataDir)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var queries []wasmQuery
+	for _, dir := range policyDirs {
+		b, err := bundle.NewCustomReader(bundle.NewDirectoryLoader(dir)).WithSkipBundleVerification(true).Read()
+		if err != nil {
+			return nil, nil, fmt.Errorf("reading the bundle %s: %w", dir, err)
+		}
+
+		// The data of the policy sources is provided to the WebAssembly
+		// modules as part of the bundle. The bundle owns all of the data so
+		// that activating it does not remove the data of the other sources.
+		if b.Data == nil {
+			b.Data = map[string]any{}
+		}
+		for k, v := range data {
+			b.Data[k] = v
+		}
+		b.Manifest.Roots = &[]string{""}
+
+		for _, r := range b.Manifest.WasmResolvers {
+			path := strings.Split(strings.Trim(r.Entrypoint, "/"), "/")
+			name := path[len(path)-1]
+			warning := warningRuleRegex.MatchString(name)
+			if !warning && !failureRuleRegex.MatchString(name) {
+				continue
+			}
+
+			namespace := strings.Join(path[:len(path)-1], ".")
+			if len(namespaces) > 0 && !slices.Contains(namespaces, namespace) {
+				continue
+			}
+
+			query, err := rego.New(
+				rego.Query("data."+strings.Join(path, ".")),
+				rego.ParsedBundle(dir, &b),
+			).PrepareForEval(ctx)
+			if err != nil {
+				return nil, nil, fmt.Errorf("preparing the entrypoint %s: %w", r.Entrypoint, err)
+			}
+
+			queries = append(queries, wasmQuery{
+				namespace: namespace,
+				warning:   warning,
+				query:     query,
+			})
+		}
+	}
+
+	return queries, data, nil
+}
+
+// loadData loads the JSON and YAML data files within the data directory.
+func loadData(dataDir string) (Data, error) {
+	paths, err := loader.FilteredPaths([]string{dataDir}, func(_ string, info os.FileInfo, _ int) bool {
+		if info.IsDir() {
+			return false
+		}
+		return !slices.Contains([]string{".yaml", ".yml", ".json"}, filepath.Ext(info.Name()))
+	})
+	if err != nil {
+		return nil, fmt.Errorf("filter data paths: %w", err)
+	}
+
+	documents, err := loader.NewFileLoader().All(paths)
+	if err != nil {
+		return nil, fmt.Errorf("load documents: %w", err)
+	}
+
+	return documents.Documents, nil
+}
+
+// wasmRunner evaluates the warn and deny rules of policy sources compiled to
+// WebAssembly. Exceptions and the number of successes are not reported by the
+// rules compiled to WebAssembly.
+type wasmRunner struct {
+	policyDirs []string
+	dataDir    string
+	namespaces []string
+	engine     *wasmEngine
+}
+
+func (r wasmRunner) Run(ctx context.Context, fileList []string) ([]Outcome, Data, error) {
+	queries, data, err := r.engine.load(ctx, r.policyDirs, r.dataDir, r.namespaces)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	files, err := listFiles(fileList)
+	if err != nil {
+		return nil, nil, fmt.Errorf("parse files: %w", err)
+	}
+
+	configurations, err := parser.ParseConfigurations(files)
+	if err != nil {
+		return nil, nil, fmt.Errorf("parse configurations: %w", err)
+	}
+
+	var outcomes []Outcome
+	for _, file := range files {
+		byNamespace := map[string]*Outcome{}
+		var namespaces []string
+		for _, q := range queries {
+			results, err := evalWasm(ctx, q.query, configurations[file])
+			if err != nil {
+				return nil, nil, fmt.Errorf("query rule: %w", err)
+			}
+
+			outcome, ok := byNamespace[q.namespace]
+			if !ok {
+				outcome = &Outcome{
+					FileName:   file,
+					Namespace:  q.namespace,
+					Successes:  []Result{},
+					Skipped:    []Result{},
+					Warnings:   []Result{},
+					Failures:   []Result{},
+					Exceptions: []Result{},
+				}
+				byNamespace[q.namespace] = outcome
+				namespaces = append(namespaces, q.namespace)
+			}
+
+			outcome.Evaluated++
+			if q.warning {
+				outcome.Warnings = append(outcome.Warnings, toRules(results)...)
+			} else {
+				outcome.Failures = append(outcome.Failures, toRules(results)...)
+			}
+		}
+
+		for _, n := range namespaces {
+			outcomes = append(outcomes, *byNamespace[n])
+		}
+	}
+
+	return outcomes, data, nil
+}
+
+// evalWasm evaluates the prepared query with the given input, returning the
+// results the same way Conftest does.
+func evalWasm(ctx context.Context, query rego.PreparedEvalQuery, input any) ([]output.Result, error) {
+	rs, err := query.Eval(ctx, rego.EvalInput(input))
+	if err != nil {
+		return nil, err
+	}
+
+	var results []output.Result
+	for _, r := range rs {
+		for _, e := range r.Expressions {
+			res, err := ruleResults(e.Value)
+			if err != nil {
+				return nil, err
+			}
+			results = append(results, res...)
+		}
+	}
+
+	return results, nil
+}
+
+// ruleResults returns the results of a warn or deny rule from its value, the
+// same way Conftest does: either as messages or as objects with the msg and
+// any additional metadata.
+func ruleResults(value any) ([]output.Result, error) {
+	values, ok := value.([]any)
+	if !ok {
+		return nil, nil
+	}
+
+	var results []output.Result
+	for _, v := range values {
+		switch v := v.(type) {
+		case string:
+			results = append(results, output.Result{Message: v})
+		case map[string]any:
+			result, err := output.NewResult(v)
+			if err != nil {
+				return nil, err
+			}
+			results = append(results, result)
+		default:
+			log.Debugf("Ignoring unsupported result: %v", v)
+		}
+	}
+
+	return results, nil
+}
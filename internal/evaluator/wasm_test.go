@@ -0,0 +1,213 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package evaluator
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	ecc "github.com/enterprise-contract/enterprise-contract-controller/api/v1alpha1"
+	"github.com/open-policy-agent/opa/bundle"
+	"github.com/open-policy-agent/opa/compile"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/enterprise-contract/ec-cli/internal/policy"
+	"github.com/enterprise-contract/ec-cli/internal/policy/source"
+)
+
+const wasmPolicy = `package wasm
+
+import rego.v1
+
+# METADATA
+# title: Denied
+# custom:
+#   short_name: denied
+deny contains result if {
+	input.denied
+	result := {"code": "wasm.denied", "msg": "Denied"}
+}
+
+# METADATA
+# title: Warned
+# custom:
+#   short_name: warned
+warn contains result if {
+	input.warned
+	result := {"code": "wasm.warned", "msg": "Warned"}
+}
+`
+
+// wasmBundle compiles the policy to WebAssembly, returning the files of the
+// resulting bundle, optionally without the Rego modules
+func wasmBundle(t *testing.T, withRego bool) fstest.MapFS {
+	t.Helper()
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(path.Join(dir, "wasm.rego"), []byte(wasmPolicy), 0600))
+
+	var buff bytes.Buffer
+	require.NoError(t, compile.New().
+		WithTarget(compile.TargetWasm).
+		WithEntrypoints("wasm/deny", "wasm/warn").
+		WithPaths(dir).
+		WithOutput(&buff).
+		Build(context.Background()))
+
+	b, err := bundle.NewReader(&buff).Read()
+	require.NoError(t, err)
+	require.Len(t, b.WasmModules, 1)
+
+	manifest, err := json.Marshal(b.Manifest)
+	require.NoError(t, err)
+
+	files := fstest.MapFS{
+		bundle.ManifestExt: &fstest.MapFile{Data: manifest},
+		"policy.wasm":      &fstest.MapFile{Data: b.WasmModules[0].Raw},
+	}
+
+	if withRego {
+		files["wasm.rego"] = &fstest.MapFile{Data: []byte(wasmPolicy)}
+	}
+
+	return files
+}
+
+func TestIsWasmSource(t *testing.T) {
+	fs := afero.NewMemMapFs()
+
+	wasm, err := isWasmSource(fs, "/nope")
+	require.NoError(t, err)
+	assert.False(t, wasm)
+
+	require.NoError(t, afero.WriteFile(fs, "/rego/.manifest", []byte(`{"roots": [""]}`), 0600))
+	wasm, err = isWasmSource(fs, "/rego")
+	require.NoError(t, err)
+	assert.False(t, wasm)
+
+	require.NoError(t, afero.WriteFile(fs, "/wasm/.manifest", []byte(`{"wasm": [{"entrypoint": "wasm/deny", "module": "/policy.wasm"}]}`), 0600))
+	wasm, err = isWasmSource(fs, "/wasm")
+	require.NoError(t, err)
+	assert.True(t, wasm)
+
+	require.NoError(t, afero.WriteFile(fs, "/bad/.manifest", []byte(`{`), 0600))
+	_, err = isWasmSource(fs, "/bad")
+	assert.ErrorContains(t, err, "reading the manifest of /bad")
+}
+
+func TestConftestEvaluatorEvaluateWasm(t *testing.T) {
+	cases := []struct {
+		name      string
+		withRego  bool
+		input     string
+		failures  []string
+		warnings  []string
+		successes []string
+	}{
+		{
+			name:      "with rule metadata",
+			withRego:  true,
+			input:     `{"denied": true, "warned": true}`,
+			failures:  []string{"Denied"},
+			warnings:  []string{"Warned"},
+			successes: []string{},
+		},
+		{
+			name:      "successes from rule metadata",
+			withRego:  true,
+			input:     `{"warned": true}`,
+			warnings:  []string{"Warned"},
+			successes: []string{"wasm.denied"},
+		},
+		{
+			name:     "without rule metadata",
+			input:    `{"denied": true}`,
+			failures: []string{"Denied"},
+		},
+		{
+			name:  "passing without rule metadata",
+			input: `{}`,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			dir := t.TempDir()
+			require.NoError(t, os.MkdirAll(path.Join(dir, "inputs"), 0755))
+			require.NoError(t, os.WriteFile(path.Join(dir, "inputs", "input.json"), []byte(c.input), 0600))
+
+			rules, err := rulesArchive(t, wasmBundle(t, c.withRego))
+			require.NoError(t, err)
+
+			config := &mockConfigProvider{}
+			config.On("EffectiveTime").Return(time.Now())
+			config.On("SigstoreOpts").Return(policy.SigstoreOpts{}, nil)
+			config.On("Spec").Return(ecc.EnterpriseContractPolicySpec{})
+
+			ctx := withCapabilities(context.Background(), testCapabilities)
+
+			evaluator, err := NewConftestEvaluator(ctx, []source.PolicySource{
+				&source.PolicyUrl{
+					Url:  rules,
+					Kind: source.PolicyKind,
+				},
+			}, config, ecc.Source{})
+			require.NoError(t, err)
+
+			results, _, err := evaluator.Evaluate(ctx, EvaluationTarget{Inputs: []string{path.Join(dir, "inputs")}})
+			if !wasmAvailable {
+				assert.ErrorIs(t, err, ErrWasmNotSupported)
+				return
+			}
+			require.NoError(t, err)
+			require.Len(t, results, 1)
+			assert.Equal(t, "wasm", results[0].Namespace)
+
+			messages := func(results []Result) []string {
+				m := []string{}
+				for _, r := range results {
+					m = append(m, r.Message)
+				}
+				return m
+			}
+			assert.ElementsMatch(t, c.failures, messages(results[0].Failures))
+			assert.ElementsMatch(t, c.warnings, messages(results[0].Warnings))
+
+			successes := []string{}
+			for _, s := range results[0].Successes {
+				successes = append(successes, s.Metadata[metadataCode].(string))
+			}
+			assert.ElementsMatch(t, c.successes, successes)
+
+			if c.withRego {
+				for _, f := range results[0].Failures {
+					assert.Equal(t, "Denied", f.Metadata["title"])
+				}
+			}
+		})
+	}
+}
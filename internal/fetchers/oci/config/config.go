@@ -21,6 +21,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/google/go-containerregistry/pkg/name"
 
@@ -46,6 +47,28 @@ func FetchImageConfig(ctx context.Context, ref name.Reference) (json.RawMessage,
 	return config, nil
 }
 
+// FetchImageCreated retrieves the creation time of an image from its config in
+// its OCI registry. Nil is returned if the config holds no creation time, or
+// the Unix epoch as commonly set by reproducible builds.
+func FetchImageCreated(ctx context.Context, ref name.Reference) (*time.Time, error) {
+	image, err := oci.NewClient(ctx).Image(ref)
+	if err != nil {
+		return nil, err
+	}
+	configFile, err := image.ConfigFile()
+	if err != nil {
+		return nil, err
+	}
+
+	created := configFile.Created.Time
+	if created.IsZero() || created.Unix() == 0 {
+		return nil, nil
+	}
+	created = created.UTC()
+
+	return &created, nil
+}
+
 // FetchParentImage retrieves the reference to an image's parent image from its OCI registry.
 func FetchParentImage(ctx context.Context, ref name.Reference) (name.Reference, error) {
 	image, err := oci.NewClient(ctx).Image(ref)
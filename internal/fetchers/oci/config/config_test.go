@@ -23,6 +23,7 @@ import (
 	"errors"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/google/go-containerregistry/pkg/name"
 	v1 "github.com/google/go-containerregistry/pkg/v1"
@@ -98,6 +99,65 @@ func TestFetchImageConfig(t *testing.T) {
 	}
 }
 
+func TestFetchImageCreated(t *testing.T) {
+	ref := name.MustParseReference("registry.local/test-image:latest")
+	created := time.Date(2024, 5, 6, 7, 8, 9, 0, time.UTC)
+
+	withCreated := func(t time.Time) func(*fake.FakeClient) {
+		return func(client *fake.FakeClient) {
+			image, err := mutate.CreatedAt(empty.Image, v1.Time{Time: t})
+			if err != nil {
+				panic(err)
+			}
+			client.On("Image", ref).Return(image, nil)
+		}
+	}
+
+	testcases := []struct {
+		name     string
+		setup    func(*fake.FakeClient)
+		expected *time.Time
+		err      string
+	}{
+		{
+			name:     "success",
+			setup:    withCreated(created),
+			expected: &created,
+		},
+		{
+			name:  "no creation time",
+			setup: withCreated(time.Time{}),
+		},
+		{
+			name:  "unix epoch",
+			setup: withCreated(time.Unix(0, 0)),
+		},
+		{
+			name: "error fetching image",
+			setup: func(client *fake.FakeClient) {
+				client.On("Image", ref).Return(empty.Image, errors.New("kaboom!"))
+			},
+			err: "kaboom!",
+		},
+	}
+
+	for _, tt := range testcases {
+		t.Run(tt.name, func(t *testing.T) {
+			client := fake.FakeClient{}
+			tt.setup(&client)
+			ctx := oci.WithClient(context.Background(), &client)
+
+			out, err := FetchImageCreated(ctx, ref)
+			if tt.err != "" {
+				require.ErrorContains(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, out)
+		})
+	}
+}
+
 func TestFetchParentImage(t *testing.T) {
 	ref := name.MustParseReference("registry.local/test-image:latest")
 	parentURL := utils.WithDigest("registry.local/base-image")
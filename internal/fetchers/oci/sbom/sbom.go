@@ -0,0 +1,211 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// Package sbom discovers the SBOMs attached to an image, regardless of the
+// mechanism used to attach them, and normalizes them for the policy.
+package sbom
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/enterprise-contract/ec-cli/internal/attestation"
+	"github.com/enterprise-contract/ec-cli/internal/utils/oci"
+)
+
+// Mechanisms by which an SBOM is attached to an image
+const (
+	// SourceReferrer is an SBOM in an artifact referring to the image via
+	// the OCI referrers API, e.g. attached with oras attach
+	SourceReferrer = "referrer"
+	// SourceAttachment is an SBOM in the image tagged sha256-<hex>.sbom in
+	// the repository of the image, e.g. attached with cosign attach sbom
+	SourceAttachment = "attachment"
+	// SourceAttestation is an SBOM as the predicate of an attestation of
+	// the image
+	SourceAttestation = "attestation"
+)
+
+// mediaTypes maps the media types of the supported SBOM documents to their
+// format
+var mediaTypes = map[string]string{
+	"application/spdx+json":          attestation.SBOMFormatSPDX,
+	"text/spdx+json":                 attestation.SBOMFormatSPDX,
+	"application/vnd.cyclonedx+json": attestation.SBOMFormatCycloneDX,
+}
+
+// SBOM is an SBOM of the image, normalized so that policies do not need to
+// account for the format of the SBOM or the mechanism used to attach it.
+type SBOM struct {
+	// Source is the mechanism by which the SBOM is attached to the image,
+	// one of SourceReferrer, SourceAttachment or SourceAttestation
+	Source string `json:"source"`
+	// Ref is the reference of the artifact holding the SBOM, not set for
+	// SBOMs in attestations
+	Ref string `json:"ref,omitempty"`
+	// MediaType is the media type of the SBOM document, not set for SBOMs in
+	// attestations
+	MediaType string `json:"media_type,omitempty"`
+	attestation.SBOMSummary
+}
+
+// FromAttestations returns the SBOMs held in the attestations of the image.
+func FromAttestations(attestations []attestation.Attestation) []SBOM {
+	var sboms []SBOM
+	for _, a := range attestations {
+		if sb, ok := a.(attestation.SBOM); ok {
+			sboms = append(sboms, SBOM{Source: SourceAttestation, SBOMSummary: sb.Summary()})
+		}
+	}
+
+	return sboms
+}
+
+// Discover returns the SBOMs attached to the image, in artifacts referring to
+// the image and in the image tagged by the cosign convention. The SBOMs found
+// are returned along with the errors fetching or parsing any of the others,
+// SBOM documents of unsupported media types are skipped.
+func Discover(ctx context.Context, digest name.Digest) ([]SBOM, error) {
+	client := oci.NewClient(ctx)
+
+	referred, referrersErr := referrers(client, digest)
+	attached, attachmentErr := attachment(client, digest)
+
+	return append(referred, attached...), errors.Join(referrersErr, attachmentErr)
+}
+
+// referrers returns the SBOMs of the artifacts referring to the image with
+// the artifact type of an SBOM document.
+func referrers(client oci.Client, digest name.Digest) ([]SBOM, error) {
+	index, err := client.Referrers(digest, "")
+	if err != nil {
+		return nil, fmt.Errorf("fetching the referrers of %s: %w", digest, err)
+	}
+
+	manifest, err := index.IndexManifest()
+	if err != nil {
+		return nil, fmt.Errorf("fetching the referrers of %s: %w", digest, err)
+	}
+
+	var sboms []SBOM
+	var allErrors error
+	for _, m := range manifest.Manifests {
+		if _, ok := mediaTypes[m.ArtifactType]; !ok {
+			continue
+		}
+
+		ref := digest.Context().Digest(m.Digest.String())
+		img, err := client.Image(ref)
+		if err != nil {
+			allErrors = errors.Join(allErrors, fmt.Errorf("fetching the SBOM %s: %w", ref, err))
+			continue
+		}
+
+		found, err := fromImage(img, SourceReferrer, ref.String())
+		sboms = append(sboms, found...)
+		allErrors = errors.Join(allErrors, err)
+	}
+
+	return sboms, allErrors
+}
+
+// attachment returns the SBOMs of the image tagged sha256-<hex>.sbom, if the
+// image has one.
+func attachment(client oci.Client, digest name.Digest) ([]SBOM, error) {
+	tag := digest.Context().Tag(strings.Replace(digest.DigestStr(), ":", "-", 1) + ".sbom")
+
+	img, err := client.Image(tag)
+	if err != nil {
+		// Most images have no SBOM attached this way
+		log.Debugf("No SBOM attached to %s as %s: %v", digest, tag, err)
+		return nil, nil
+	}
+
+	return fromImage(img, SourceAttachment, tag.String())
+}
+
+// fromImage returns the SBOMs held in the layers of the image with the media
+// type of a supported SBOM document.
+func fromImage(img v1.Image, source, ref string) ([]SBOM, error) {
+	layers, err := img.Layers()
+	if err != nil {
+		return nil, fmt.Errorf("fetching the SBOM %s: %w", ref, err)
+	}
+
+	var sboms []SBOM
+	var allErrors error
+	for _, l := range layers {
+		mt, err := l.MediaType()
+		if err != nil {
+			allErrors = errors.Join(allErrors, fmt.Errorf("fetching the SBOM %s: %w", ref, err))
+			continue
+		}
+
+		format, ok := mediaTypes[string(mt)]
+		if !ok {
+			log.Debugf("Skipping the layer of %s with the unsupported media type %s", ref, mt)
+			continue
+		}
+
+		document, err := readLayer(l)
+		if err != nil {
+			allErrors = errors.Join(allErrors, fmt.Errorf("reading the SBOM %s: %w", ref, err))
+			continue
+		}
+
+		summary, err := attestation.SummarizeSBOM(format, document)
+		if err != nil {
+			allErrors = errors.Join(allErrors, fmt.Errorf("parsing the SBOM %s: %w", ref, err))
+			continue
+		}
+
+		sboms = append(sboms, SBOM{Source: source, Ref: ref, MediaType: string(mt), SBOMSummary: summary})
+	}
+
+	return sboms, allErrors
+}
+
+// readLayer reads the layer holding an SBOM document, the layers larger than
+// the maximum size of an attestation are rejected, see attestation.MaxSize.
+func readLayer(l v1.Layer) ([]byte, error) {
+	limit := attestation.MaxSize()
+	if size, err := l.Size(); err == nil && size > 0 && uint64(size) > limit {
+		return nil, fmt.Errorf("the SBOM of %d bytes exceeds the maximum size of %d bytes", size, limit)
+	}
+
+	rc, err := l.Uncompressed()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+
+	document, err := io.ReadAll(io.LimitReader(rc, int64(limit)+1))
+	if err != nil {
+		return nil, err
+	}
+	if uint64(len(document)) > limit {
+		return nil, fmt.Errorf("the SBOM exceeds the maximum size of %d bytes", limit)
+	}
+
+	return document, nil
+}
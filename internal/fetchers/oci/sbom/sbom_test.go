@@ -0,0 +1,179 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package sbom
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/static"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/enterprise-contract/ec-cli/internal/attestation"
+	"github.com/enterprise-contract/ec-cli/internal/utils/oci"
+	"github.com/enterprise-contract/ec-cli/internal/utils/oci/fake"
+)
+
+const (
+	spdxDocument = `{
+		"spdxVersion": "SPDX-2.3",
+		"dataLicense": "CC0-1.0",
+		"SPDXID": "SPDXRef-DOCUMENT",
+		"name": "image",
+		"documentNamespace": "https://example.com/image",
+		"creationInfo": {"created": "2024-01-01T00:00:00Z", "creators": ["Tool: syft"]},
+		"packages": [{"name": "ham", "SPDXID": "SPDXRef-Package-ham", "versionInfo": "2.0", "downloadLocation": "NOASSERTION"}]
+	}`
+	cycloneDXDocument = `{"bomFormat": "CycloneDX", "specVersion": "1.5", "components": [{"name": "spam", "version": "1.0", "purl": "pkg:rpm/spam@1.0"}]}`
+)
+
+func sbomImage(t *testing.T, document, mediaType string) v1.Image {
+	img, err := mutate.AppendLayers(empty.Image, static.NewLayer([]byte(document), types.MediaType(mediaType)))
+	require.NoError(t, err)
+
+	return img
+}
+
+func TestDiscover(t *testing.T) {
+	digest, err := name.NewDigest("registry.io/repository/image@sha256:" + strings.Repeat("a", 64))
+	require.NoError(t, err)
+	tag := digest.Context().Tag("sha256-" + strings.Repeat("a", 64) + ".sbom")
+
+	// The artifact type of the referrers is the media type of their config
+	referrer := func(document, artifactType string) (v1.Image, v1.Hash) {
+		img := mutate.ConfigMediaType(mutate.MediaType(sbomImage(t, document, artifactType), types.OCIManifestSchema1), types.MediaType(artifactType))
+		digest, err := img.Digest()
+		require.NoError(t, err)
+
+		return img, digest
+	}
+	spdxImage, spdxDigest := referrer(spdxDocument, "application/spdx+json")
+	otherImage, otherDigest := referrer("{}", "application/vnd.dev.sigstore.bundle.v0.3+json")
+
+	referrers := mutate.IndexMediaType(empty.Index, types.OCIImageIndex)
+	referrers = mutate.AppendManifests(referrers, mutate.IndexAddendum{Add: spdxImage}, mutate.IndexAddendum{Add: otherImage})
+
+	client := fake.FakeClient{}
+	client.On("Referrers", digest, "").Return(referrers, nil)
+	client.On("Image", digest.Context().Digest(spdxDigest.String())).Return(spdxImage, nil)
+	client.On("Image", tag).Return(sbomImage(t, cycloneDXDocument, "application/vnd.cyclonedx+json"), nil)
+
+	sboms, err := Discover(oci.WithClient(context.Background(), &client), digest)
+	require.NoError(t, err)
+
+	assert.Equal(t, []SBOM{
+		{
+			Source:    SourceReferrer,
+			Ref:       digest.Context().Digest(spdxDigest.String()).String(),
+			MediaType: "application/spdx+json",
+			SBOMSummary: attestation.SBOMSummary{
+				Format:      attestation.SBOMFormatSPDX,
+				SpecVersion: "SPDX-2.3",
+				Packages:    []attestation.SBOMPackage{{Name: "ham", Version: "2.0"}},
+			},
+		},
+		{
+			Source:    SourceAttachment,
+			Ref:       tag.String(),
+			MediaType: "application/vnd.cyclonedx+json",
+			SBOMSummary: attestation.SBOMSummary{
+				Format:      attestation.SBOMFormatCycloneDX,
+				SpecVersion: "1.5",
+				Packages:    []attestation.SBOMPackage{{Name: "spam", Version: "1.0", PURL: "pkg:rpm/spam@1.0"}},
+			},
+		},
+	}, sboms)
+	client.AssertNotCalled(t, "Image", digest.Context().Digest(otherDigest.String()))
+}
+
+func TestDiscoverBestEffort(t *testing.T) {
+	digest, err := name.NewDigest("registry.io/repository/image@sha256:" + strings.Repeat("a", 64))
+	require.NoError(t, err)
+	tag := digest.Context().Tag("sha256-" + strings.Repeat("a", 64) + ".sbom")
+
+	client := fake.FakeClient{}
+	client.On("Referrers", digest, "").Return(nil, errors.New("referrers API unavailable"))
+	client.On("Image", tag).Return(sbomImage(t, cycloneDXDocument, "application/vnd.cyclonedx+json"), nil)
+
+	sboms, err := Discover(oci.WithClient(context.Background(), &client), digest)
+	assert.ErrorContains(t, err, "referrers API unavailable")
+	require.Len(t, sboms, 1)
+	assert.Equal(t, SourceAttachment, sboms[0].Source)
+}
+
+func TestDiscoverNoSBOMs(t *testing.T) {
+	digest, err := name.NewDigest("registry.io/repository/image@sha256:" + strings.Repeat("a", 64))
+	require.NoError(t, err)
+
+	client := fake.FakeClient{}
+	client.On("Referrers", digest, "").Return(empty.Index, nil)
+	client.On("Image", mock.Anything).Return(nil, errors.New("MANIFEST_UNKNOWN"))
+
+	sboms, err := Discover(oci.WithClient(context.Background(), &client), digest)
+	assert.NoError(t, err)
+	assert.Empty(t, sboms)
+}
+
+func TestDiscoverUnsupportedAndInvalid(t *testing.T) {
+	digest, err := name.NewDigest("registry.io/repository/image@sha256:" + strings.Repeat("a", 64))
+	require.NoError(t, err)
+	tag := digest.Context().Tag("sha256-" + strings.Repeat("a", 64) + ".sbom")
+
+	img, err := mutate.AppendLayers(empty.Image,
+		static.NewLayer([]byte(`<spdx/>`), "application/spdx+xml"),
+		static.NewLayer([]byte(`***`), "application/spdx+json"),
+	)
+	require.NoError(t, err)
+
+	client := fake.FakeClient{}
+	client.On("Referrers", digest, "").Return(empty.Index, nil)
+	client.On("Image", tag).Return(img, nil)
+
+	sboms, err := Discover(oci.WithClient(context.Background(), &client), digest)
+	assert.ErrorContains(t, err, "parsing the SBOM "+tag.String())
+	assert.Empty(t, sboms)
+}
+
+func TestDiscoverMaxSize(t *testing.T) {
+	t.Cleanup(func() {
+		require.NoError(t, attestation.SetMaxSize(attestation.DefaultMaxSize))
+	})
+	require.NoError(t, attestation.SetMaxSize(10))
+
+	digest, err := name.NewDigest("registry.io/repository/image@sha256:" + strings.Repeat("a", 64))
+	require.NoError(t, err)
+	tag := digest.Context().Tag("sha256-" + strings.Repeat("a", 64) + ".sbom")
+
+	client := fake.FakeClient{}
+	client.On("Referrers", digest, "").Return(empty.Index, nil)
+	client.On("Image", tag).Return(sbomImage(t, spdxDocument, "application/spdx+json"), nil)
+
+	sboms, err := Discover(oci.WithClient(context.Background(), &client), digest)
+	assert.ErrorContains(t, err, "exceeds the maximum size of 10 bytes")
+	assert.Empty(t, sboms)
+}
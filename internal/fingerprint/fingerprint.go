@@ -0,0 +1,160 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// Package fingerprint computes stable fingerprints of results. Messages often
+// embed volatile data, e.g. image digests or timestamps, that differs between
+// runs reporting the same logical result. Such data is normalized before the
+// fingerprint is computed.
+package fingerprint
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/enterprise-contract/ec-cli/internal/evaluator"
+)
+
+// Rule replaces all substrings of a message matching the pattern with the
+// replacement. The replacement can refer to submatches of the pattern, see
+// regexp.Regexp.ReplaceAllString.
+type Rule struct {
+	Name        string
+	Pattern     *regexp.Regexp
+	Replacement string
+}
+
+// DefaultRules are the normalization rules applied, in order, when no rules
+// are given:
+//   - digest: algorithm prefixed digests, e.g. sha256:4f2d...ab, become
+//     sha256:<digest>
+//   - timestamp: RFC 3339 timestamps, e.g. 2024-05-31T10:15:00Z, become
+//     <timestamp>
+//   - uuid: UUIDs become <uuid>
+//   - commit: 40 character hexadecimal strings, e.g. git commit SHAs, become
+//     <commit>
+var DefaultRules = []Rule{
+	{
+		Name:        "digest",
+		Pattern:     regexp.MustCompile(`\b(sha256|sha384|sha512):[a-fA-F0-9]{32,}\b`),
+		Replacement: "$1:<digest>",
+	},
+	{
+		Name:        "timestamp",
+		Pattern:     regexp.MustCompile(`\b\d{4}-\d{2}-\d{2}[Tt ]\d{2}:\d{2}:\d{2}(\.\d+)?([Zz]|[+-]\d{2}:\d{2})?`),
+		Replacement: "<timestamp>",
+	},
+	{
+		Name:        "uuid",
+		Pattern:     regexp.MustCompile(`\b[a-fA-F0-9]{8}-[a-fA-F0-9]{4}-[a-fA-F0-9]{4}-[a-fA-F0-9]{4}-[a-fA-F0-9]{12}\b`),
+		Replacement: "<uuid>",
+	},
+	{
+		Name:        "commit",
+		Pattern:     regexp.MustCompile(`\b[a-f0-9]{40}\b`),
+		Replacement: "<commit>",
+	},
+}
+
+// ParseRule parses a rule given in the form of <pattern>=<replacement>. The
+// last equals sign separates the pattern from the replacement, so the pattern
+// can contain equals signs but the replacement can not.
+func ParseRule(spec string) (Rule, error) {
+	i := strings.LastIndex(spec, "=")
+	if i < 1 {
+		return Rule{}, fmt.Errorf("invalid normalization rule %q, expected <pattern>=<replacement>", spec)
+	}
+
+	pattern, err := regexp.Compile(spec[:i])
+	if err != nil {
+		return Rule{}, fmt.Errorf("invalid normalization rule %q: %w", spec, err)
+	}
+
+	return Rule{Name: spec, Pattern: pattern, Replacement: spec[i+1:]}, nil
+}
+
+// ParseRules parses the rules, each given either in the form accepted by
+// ParseRule or as the name of one of the DefaultRules, e.g. digest, so that
+// custom rules can be combined with some of the default rules.
+func ParseRules(specs []string) ([]Rule, error) {
+	rules := make([]Rule, 0, len(specs))
+	for _, spec := range specs {
+		if i := slices.IndexFunc(DefaultRules, func(r Rule) bool { return r.Name == spec }); i >= 0 {
+			rules = append(rules, DefaultRules[i])
+			continue
+		}
+
+		r, err := ParseRule(spec)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, r)
+	}
+
+	return rules, nil
+}
+
+// Normalizer normalizes messages and computes fingerprints of results.
+type Normalizer struct {
+	rules []Rule
+}
+
+// NewNormalizer returns a Normalizer applying the given rules, in order. The
+// DefaultRules are applied if no rules are given.
+func NewNormalizer(rules ...Rule) Normalizer {
+	if len(rules) == 0 {
+		rules = DefaultRules
+	}
+
+	return Normalizer{rules: rules}
+}
+
+// Normalize returns the message with the volatile data replaced.
+func (n Normalizer) Normalize(message string) string {
+	for _, r := range n.rules {
+		message = r.Pattern.ReplaceAllString(message, r.Replacement)
+	}
+
+	return message
+}
+
+// Fingerprint returns the hex encoded SHA-256 digest of the code, term and
+// normalized message of the result. Results reporting the same logical
+// violation have the same fingerprint even if the volatile data embedded in
+// their messages differs.
+func (n Normalizer) Fingerprint(result evaluator.Result) string {
+	h := sha256.New()
+	for _, v := range []string{
+		evaluator.ExtractStringFromMetadata(result, "code"),
+		evaluator.ExtractStringFromMetadata(result, "term"),
+		n.Normalize(result.Message),
+	} {
+		h.Write([]byte(v))
+		h.Write([]byte{0})
+	}
+
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// SetFingerprints sets the fingerprint of each of the results.
+func (n Normalizer) SetFingerprints(results []evaluator.Result) {
+	for i := range results {
+		results[i].Fingerprint = n.Fingerprint(results[i])
+	}
+}
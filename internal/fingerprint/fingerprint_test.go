@@ -0,0 +1,133 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package fingerprint
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/enterprise-contract/ec-cli/internal/evaluator"
+)
+
+func TestNormalize(t *testing.T) {
+	cases := []struct {
+		name     string
+		message  string
+		expected string
+	}{
+		{name: "no volatile data", message: "Image is not signed", expected: "Image is not signed"},
+		{
+			name:     "digest",
+			message:  "Image registry.io/repo@sha256:4d3b3a1c1d4a36b7ea0ec0c32ba2a0d3cc4f3a8f85b5a83b9b2a1b7fb0f2f5d1 is not allowed",
+			expected: "Image registry.io/repo@sha256:<digest> is not allowed",
+		},
+		{
+			name:     "timestamp",
+			message:  "Attestation created at 2024-05-31T10:15:00.123+02:00 is too old",
+			expected: "Attestation created at <timestamp> is too old",
+		},
+		{
+			name:     "uuid",
+			message:  "Pipeline run 123e4567-e89b-12d3-a456-426614174000 failed",
+			expected: "Pipeline run <uuid> failed",
+		},
+		{
+			name:     "commit",
+			message:  "Built from commit 0123456789abcdef0123456789abcdef01234567",
+			expected: "Built from commit <commit>",
+		},
+	}
+
+	n := NewNormalizer()
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			assert.Equal(t, c.expected, n.Normalize(c.message))
+		})
+	}
+}
+
+func TestFingerprint(t *testing.T) {
+	n := NewNormalizer()
+
+	result := func(msg, code string) evaluator.Result {
+		return evaluator.Result{Message: msg, Metadata: map[string]any{"code": code}}
+	}
+
+	run1 := result("Image registry.io/repo@sha256:4d3b3a1c1d4a36b7ea0ec0c32ba2a0d3cc4f3a8f85b5a83b9b2a1b7fb0f2f5d1 is not allowed", "pkg.rule")
+	run2 := result("Image registry.io/repo@sha256:9f8e7d6c5b4a39281706f5e4d3c2b1a09f8e7d6c5b4a39281706f5e4d3c2b1a0 is not allowed", "pkg.rule")
+	other := result("Image registry.io/other@sha256:9f8e7d6c5b4a39281706f5e4d3c2b1a09f8e7d6c5b4a39281706f5e4d3c2b1a0 is not allowed", "pkg.rule")
+	otherCode := result(run1.Message, "pkg.other")
+	withTerm := result(run1.Message, "pkg.rule")
+	withTerm.Metadata["term"] = "t1"
+
+	assert.Equal(t, n.Fingerprint(run1), n.Fingerprint(run2))
+	assert.Len(t, n.Fingerprint(run1), 64)
+	assert.NotEqual(t, n.Fingerprint(run1), n.Fingerprint(other))
+	assert.NotEqual(t, n.Fingerprint(run1), n.Fingerprint(otherCode))
+	assert.NotEqual(t, n.Fingerprint(run1), n.Fingerprint(withTerm))
+}
+
+func TestParseRule(t *testing.T) {
+	r, err := ParseRule(`build-\d+=build-<n>`)
+	require.NoError(t, err)
+
+	n := NewNormalizer(r)
+	assert.Equal(t, "Failed in build-<n>", n.Normalize("Failed in build-1234"))
+	// only the given rules are applied
+	assert.Equal(t, "sha256:abc", n.Normalize("sha256:abc"))
+
+	r, err = ParseRule(`a=b=c`)
+	require.NoError(t, err)
+	assert.Equal(t, "c", NewNormalizer(r).Normalize("a=b"))
+
+	_, err = ParseRule("no-replacement")
+	assert.EqualError(t, err, `invalid normalization rule "no-replacement", expected <pattern>=<replacement>`)
+
+	_, err = ParseRule("=x")
+	assert.Error(t, err)
+
+	_, err = ParseRule("(=x")
+	assert.ErrorContains(t, err, `invalid normalization rule "(=x"`)
+}
+
+func TestParseRules(t *testing.T) {
+	rules, err := ParseRules([]string{"digest", `build-\d+=build-<n>`})
+	require.NoError(t, err)
+
+	n := NewNormalizer(rules...)
+	assert.Equal(t, "sha256:<digest> in build-<n> at 2024-05-31T10:15:00Z",
+		n.Normalize("sha256:4d3b3a1c1d4a36b7ea0ec0c32ba2a0d3cc4f3a8f85b5a83b9b2a1b7fb0f2f5d1 in build-1234 at 2024-05-31T10:15:00Z"))
+
+	_, err = ParseRules([]string{"spam"})
+	assert.EqualError(t, err, `invalid normalization rule "spam", expected <pattern>=<replacement>`)
+}
+
+func TestSetFingerprints(t *testing.T) {
+	n := NewNormalizer()
+	results := []evaluator.Result{
+		{Message: "Image sha256:4d3b3a1c1d4a36b7ea0ec0c32ba2a0d3cc4f3a8f85b5a83b9b2a1b7fb0f2f5d1 is not allowed"},
+		{Message: "Image sha256:9f8e7d6c5b4a39281706f5e4d3c2b1a09f8e7d6c5b4a39281706f5e4d3c2b1a0 is not allowed"},
+	}
+
+	n.SetFingerprints(results)
+	assert.Equal(t, n.Fingerprint(results[0]), results[0].Fingerprint)
+	assert.Equal(t, results[0].Fingerprint, results[1].Fingerprint)
+}
@@ -0,0 +1,85 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package format
+
+import (
+	"context"
+	"io"
+	"os"
+
+	"github.com/spf13/afero"
+
+	"github.com/enterprise-contract/ec-cli/pkg/sink"
+)
+
+// NewWriterSink returns a sink writing reports to the given writer, for
+// instance to standard output.
+func NewWriterSink(w io.Writer) sink.ReportSink {
+	return writerSink{writer: w}
+}
+
+type writerSink struct {
+	writer io.Writer
+}
+
+func (s writerSink) Write(_ context.Context, _ string, data []byte) error {
+	_, err := s.writer.Write(data)
+	return err
+}
+
+// NewFileSink returns a sink writing reports to the file at the given
+// path, replacing any existing content.
+func NewFileSink(path string, fs afero.Fs) sink.ReportSink {
+	return fileSink{path: path, fs: fs}
+}
+
+type fileSink struct {
+	path string
+	fs   afero.Fs
+}
+
+func (s fileSink) Write(_ context.Context, _ string, data []byte) error {
+	file, err := s.fs.Create(s.path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	_, err = file.Write(data)
+	return err
+}
+
+// NewAppendFileSink returns a sink appending reports to the file at the
+// given path, creating it if needed, e.g. to add to a log or a summary written
+// by several steps.
+func NewAppendFileSink(path string, fs afero.Fs) sink.ReportSink {
+	return appendFileSink{path: path, fs: fs}
+}
+
+type appendFileSink struct {
+	path string
+	fs   afero.Fs
+}
+
+func (s appendFileSink) Write(_ context.Context, _ string, data []byte) error {
+	file, err := s.fs.OpenFile(s.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	_, err = file.Write(data)
+	return err
+}
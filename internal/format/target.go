@@ -17,24 +17,38 @@
 package format
 
 import (
+	"context"
+	"fmt"
 	"io"
 	"net/url"
 	"strconv"
 	"strings"
 
 	"github.com/spf13/afero"
+
+	"github.com/enterprise-contract/ec-cli/pkg/sink"
 )
 
-// Target represents a writer with a specified format.
+// templateFormat is the name of the format rendering the report using the
+// template given by the template option, see Options.Template
+const templateFormat = "template"
+
+// Target represents a sink with a specified format.
 type Target struct {
 	Format  string
 	Options Options
-	writer  io.Writer
+	// Template holds the content of the template file given in the options
+	Template string
+	sink     sink.ReportSink
 }
 
 // options that can be configured per Target
 type Options struct {
 	ShowSuccesses bool
+	VerboseRules  bool
+	Redact        []string
+	// Template is the path of a user-supplied template file
+	Template string
 }
 
 // mutate parses the given string as URL query parameters and sets the fields
@@ -53,30 +67,96 @@ func (o *Options) mutate(given string) error {
 		}
 	}
 
+	if v := vals.Get("verbose-rules"); v != "" {
+		if f, err := strconv.ParseBool(v); err == nil {
+			o.VerboseRules = f
+		} else {
+			return err
+		}
+	}
+
+	if v := vals.Get("template"); v != "" {
+		o.Template = v
+	}
+
+	if vals.Has("redact") {
+		o.Redact = nil
+		if v := vals.Get("redact"); v != "" {
+			o.Redact = strings.Split(v, ",")
+		}
+	}
+
 	return nil
 }
 
-// Write proxies the write operation to the underlying writer.
+// Write delivers the data to the underlying sink. Target implements io.Writer
+// for convenience, use Deliver to pass on a context.
 func (t *Target) Write(data []byte) (int, error) {
-	return t.writer.Write(data)
+	if err := t.Deliver(context.Background(), data); err != nil {
+		return 0, err
+	}
+	return len(data), nil
+}
+
+// Deliver passes the data rendered in the Target's format to the underlying
+// sink.
+func (t *Target) Deliver(ctx context.Context, data []byte) error {
+	return t.sink.Write(ctx, t.Format, data)
 }
 
 // TargetParser is responsible for creating Target objects.
 type TargetParser struct {
 	defaultFormat  string
-	defaultWriter  io.Writer
+	defaultSink    sink.ReportSink
 	defaultOptions Options
 	fs             afero.Fs
+	sinks          map[string]sink.ReportSink
+	formatSinks    map[string]formatSink
 }
 
-// NewTargetParser creates a new TargetParser with the given options.
+// formatSink renders the targets of a name in place of the format in the
+// given format and delivers them to the sink created for their path
+type formatSink struct {
+	format  string
+	newSink func(path string) sink.ReportSink
+}
+
+// NewTargetParser creates a new TargetParser with the given options. Reports
+// for targets without a path are written to the given writer.
 func NewTargetParser(targetName string, options Options, writer io.Writer, fs afero.Fs) TargetParser {
-	return TargetParser{defaultFormat: targetName, defaultOptions: options, defaultWriter: writer, fs: fs}
+	return TargetParser{defaultFormat: targetName, defaultOptions: options, defaultSink: NewWriterSink(writer), fs: fs}
+}
+
+// RegisterSink makes the given sink available to the targets of the parser
+// under the provided name. Targets with the path set to sink:<name>, e.g.
+// json=sink:name, are delivered to the sink. Sinks registered with the parser
+// take precedence over the ones registered using sink.Register. Registering a
+// sink with an empty name replaces the sink used for targets without a path.
+func (tm *TargetParser) RegisterSink(name string, s sink.ReportSink) {
+	if name == "" {
+		tm.defaultSink = s
+		return
+	}
+	if tm.sinks == nil {
+		tm.sinks = map[string]sink.ReportSink{}
+	}
+	tm.sinks[name] = s
+}
+
+// RegisterFormatSink makes the given name usable in place of a format. Targets
+// with the name, e.g. name=path, are rendered in the given format and delivered
+// to the sink created for the path, e.g. to push the report to a remote
+// location.
+func (tm *TargetParser) RegisterFormatSink(name string, format string, newSink func(path string) sink.ReportSink) {
+	if tm.formatSinks == nil {
+		tm.formatSinks = map[string]formatSink{}
+	}
+	tm.formatSinks[name] = formatSink{format: format, newSink: newSink}
 }
 
 // Parse creates a new Target given the provided target name.
 func (tm *TargetParser) Parse(given string) (*Target, error) {
-	target := Target{writer: tm.defaultWriter}
+	target := Target{sink: tm.defaultSink}
 
 	formatAndPath, opts, foundOpts := strings.Cut(given, "?")
 
@@ -94,24 +174,39 @@ func (tm *TargetParser) Parse(given string) (*Target, error) {
 		target.Format = tm.defaultFormat
 	}
 
-	if path != "" {
-		target.writer = &fileWriter{path: path, fs: tm.fs}
+	// The path of a template target is the template, unless the template is
+	// given by the option, e.g. template=report.tmpl is written to the default
+	// sink and template=report.txt?template=report.tmpl to report.txt
+	if target.Format == templateFormat && target.Options.Template == "" {
+		target.Options.Template, path = path, ""
 	}
 
-	return &target, nil
-}
-
-// fileWriter implements a simple Writer wrapper for afero.Fs.
-type fileWriter struct {
-	path string
-	fs   afero.Fs
-}
+	if fs, ok := tm.formatSinks[target.Format]; ok {
+		if path == "" {
+			return nil, fmt.Errorf("the %s target requires a location, e.g. %s=<location>", target.Format, target.Format)
+		}
+		target.Format = fs.format
+		target.sink = fs.newSink(path)
+	} else if name, ok := strings.CutPrefix(path, sink.Scheme); ok {
+		s, found := tm.sinks[name]
+		if !found {
+			s, found = sink.Lookup(name)
+		}
+		if !found {
+			return nil, fmt.Errorf("no sink is registered with the name %q", name)
+		}
+		target.sink = s
+	} else if path != "" {
+		target.sink = NewFileSink(path, tm.fs)
+	}
 
-func (w fileWriter) Write(data []byte) (int, error) {
-	file, err := w.fs.Create(w.path)
-	if err != nil {
-		return 0, err
+	if target.Options.Template != "" {
+		data, err := afero.ReadFile(tm.fs, target.Options.Template)
+		if err != nil {
+			return nil, fmt.Errorf("reading the template %s: %w", target.Options.Template, err)
+		}
+		target.Template = string(data)
 	}
-	defer file.Close()
-	return file.Write(data)
+
+	return &target, nil
 }
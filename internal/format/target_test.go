@@ -19,16 +19,19 @@
 package format
 
 import (
+	"bytes"
+	"context"
 	"testing"
 
 	"github.com/spf13/afero"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	"github.com/enterprise-contract/ec-cli/pkg/sink"
 )
 
 func TestTargetParser(t *testing.T) {
 	defaultFormat := "default"
-	defaultPath := "default.out"
 	defaultOptions := Options{
 		ShowSuccesses: false,
 	}
@@ -47,21 +50,25 @@ func TestTargetParser(t *testing.T) {
 		{name: "format and option", expectedFormat: "spam", expectedOptions: Options{ShowSuccesses: true}, targetName: "spam?show-successes=true"},
 		{name: "format no file with option", expectedFormat: "spam", expectedOptions: Options{ShowSuccesses: true}, targetName: "spam=?show-successes=true"},
 		{name: "format with file and option", expectedFormat: "spam", expectedOptions: Options{ShowSuccesses: true}, targetName: "spam=spam.out?show-successes=true", expectedPath: "spam.out"},
+		{name: "format with verbose rules option", expectedFormat: "spam", expectedOptions: Options{VerboseRules: true}, targetName: "spam?verbose-rules=true"},
+		{name: "format with multiple options", expectedFormat: "spam", expectedOptions: Options{ShowSuccesses: true, VerboseRules: true}, targetName: "spam?show-successes=true&verbose-rules=true"},
+		{name: "format with redact option", expectedFormat: "spam", expectedOptions: Options{Redact: []string{"registry", "signer"}}, targetName: "spam?redact=registry,signer"},
+		{name: "format with empty redact option", expectedFormat: "spam", expectedOptions: defaultOptions, targetName: "spam?redact="},
 	}
 
 	for _, c := range cases {
 		t.Run(c.name, func(t *testing.T) {
 			fs := afero.NewMemMapFs()
-			defaultWriter := fileWriter{path: defaultPath, fs: fs}
+			defaultWriter := &bytes.Buffer{}
 			parser := NewTargetParser(defaultFormat, defaultOptions, defaultWriter, fs)
 			target, err := parser.Parse(c.targetName)
 			require.NoError(t, err)
 
 			assert.Equal(t, c.expectedFormat, target.Format)
 			if c.expectedPath == "" {
-				assert.Equal(t, defaultWriter, target.writer)
+				assert.Equal(t, NewWriterSink(defaultWriter), target.sink)
 			} else {
-				assert.Equal(t, c.expectedPath, target.writer.(*fileWriter).path)
+				assert.Equal(t, c.expectedPath, target.sink.(fileSink).path)
 			}
 
 			assert.Equal(t, c.expectedOptions, target.Options)
@@ -69,12 +76,136 @@ func TestTargetParser(t *testing.T) {
 	}
 }
 
-func TestSimpleFileWriter(t *testing.T) {
+func TestFileSink(t *testing.T) {
 	fs := afero.NewMemMapFs()
-	writer := fileWriter{path: "out", fs: fs}
-	_, err := writer.Write([]byte("spam"))
-	assert.NoError(t, err)
+	sink := NewFileSink("out", fs)
+	assert.NoError(t, sink.Write(context.Background(), "spam", []byte("spam")))
 	actual, err := afero.ReadFile(fs, "out")
 	assert.NoError(t, err)
 	assert.Equal(t, "spam", string(actual))
 }
+
+func TestAppendFileSink(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	assert.NoError(t, afero.WriteFile(fs, "out", []byte("ham\n"), 0o644))
+	sink := NewAppendFileSink("out", fs)
+	assert.NoError(t, sink.Write(context.Background(), "spam", []byte("spam\n")))
+	assert.NoError(t, sink.Write(context.Background(), "spam", []byte("eggs\n")))
+	actual, err := afero.ReadFile(fs, "out")
+	assert.NoError(t, err)
+	assert.Equal(t, "ham\nspam\neggs\n", string(actual))
+}
+
+func TestRegisteredSink(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	parser := NewTargetParser("default", Options{}, &bytes.Buffer{}, fs)
+
+	received := map[string]string{}
+	parser.RegisterSink("custom", sink.SinkFunc(func(_ context.Context, format string, data []byte) error {
+		received[format] = string(data)
+		return nil
+	}))
+
+	target, err := parser.Parse("spam=sink:custom?show-successes=true")
+	require.NoError(t, err)
+	assert.True(t, target.Options.ShowSuccesses)
+
+	n, err := target.Write([]byte("eggs"))
+	require.NoError(t, err)
+	assert.Equal(t, 4, n)
+	assert.Equal(t, map[string]string{"spam": "eggs"}, received)
+
+	// a file with the name of the sink is not taken over by the sink
+	target, err = parser.Parse("spam=custom")
+	require.NoError(t, err)
+	require.NoError(t, target.Deliver(context.Background(), []byte("ham")))
+	content, err := afero.ReadFile(fs, "custom")
+	require.NoError(t, err)
+	assert.Equal(t, "ham", string(content))
+	assert.Equal(t, map[string]string{"spam": "eggs"}, received)
+
+	_, err = parser.Parse("spam=sink:missing")
+	assert.EqualError(t, err, `no sink is registered with the name "missing"`)
+}
+
+func TestGloballyRegisteredSink(t *testing.T) {
+	var received string
+	require.NoError(t, sink.Register("format-test", sink.SinkFunc(func(_ context.Context, format string, data []byte) error {
+		received = format + ":" + string(data)
+		return nil
+	})))
+
+	parser := NewTargetParser("default", Options{}, &bytes.Buffer{}, afero.NewMemMapFs())
+
+	target, err := parser.Parse("spam=sink:format-test")
+	require.NoError(t, err)
+	require.NoError(t, target.Deliver(context.Background(), []byte("eggs")))
+	assert.Equal(t, "spam:eggs", received)
+}
+
+func TestRegisteredDefaultSink(t *testing.T) {
+	defaultWriter := &bytes.Buffer{}
+	parser := NewTargetParser("default", Options{}, defaultWriter, afero.NewMemMapFs())
+
+	var received []byte
+	parser.RegisterSink("", sink.SinkFunc(func(_ context.Context, _ string, data []byte) error {
+		received = data
+		return nil
+	}))
+
+	target, err := parser.Parse("spam")
+	require.NoError(t, err)
+	require.NoError(t, target.Deliver(context.Background(), []byte("eggs")))
+
+	assert.Equal(t, "eggs", string(received))
+	assert.Empty(t, defaultWriter.String())
+}
+
+func TestRegisteredFormatSink(t *testing.T) {
+	parser := NewTargetParser("default", Options{}, &bytes.Buffer{}, afero.NewMemMapFs())
+
+	received := map[string]string{}
+	parser.RegisterFormatSink("remote", "json", func(path string) sink.ReportSink {
+		return sink.SinkFunc(func(_ context.Context, format string, data []byte) error {
+			received[path] = format + ":" + string(data)
+			return nil
+		})
+	})
+
+	target, err := parser.Parse("remote=registry.io/image:tag?show-successes=true")
+	require.NoError(t, err)
+	assert.Equal(t, "json", target.Format)
+	assert.True(t, target.Options.ShowSuccesses)
+	require.NoError(t, target.Deliver(context.Background(), []byte("eggs")))
+	assert.Equal(t, map[string]string{"registry.io/image:tag": "json:eggs"}, received)
+
+	_, err = parser.Parse("remote")
+	assert.EqualError(t, err, "the remote target requires a location, e.g. remote=<location>")
+}
+
+func TestTemplateTarget(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "report.tmpl", []byte("{{ .Success }}"), 0400))
+	parser := NewTargetParser("default", Options{}, &bytes.Buffer{}, fs)
+
+	target, err := parser.Parse("template=out.txt?template=report.tmpl")
+	require.NoError(t, err)
+	assert.Equal(t, "template", target.Format)
+	assert.Equal(t, "report.tmpl", target.Options.Template)
+	assert.Equal(t, "{{ .Success }}", target.Template)
+
+	assert.Equal(t, "out.txt", target.sink.(fileSink).path)
+
+	target, err = parser.Parse("template=report.tmpl")
+	require.NoError(t, err)
+	assert.Equal(t, "template", target.Format)
+	assert.Equal(t, "report.tmpl", target.Options.Template)
+	assert.Equal(t, "{{ .Success }}", target.Template)
+	assert.Equal(t, parser.defaultSink, target.sink)
+
+	_, err = parser.Parse("template?template=missing.tmpl")
+	assert.ErrorContains(t, err, "reading the template missing.tmpl: ")
+
+	_, err = parser.Parse("template=missing.tmpl")
+	assert.ErrorContains(t, err, "reading the template missing.tmpl: ")
+}
@@ -0,0 +1,87 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package http
+
+import (
+	"net/http"
+	"sync"
+
+	"golang.org/x/time/rate"
+)
+
+// hostLimiters holds a rate limiter for each host requests are sent to.
+type hostLimiters struct {
+	mu       sync.Mutex
+	limit    rate.Limit
+	limiters map[string]*rate.Limiter
+}
+
+var limiters = &hostLimiters{limit: rate.Inf, limiters: map[string]*rate.Limiter{}}
+
+// SetHostRateLimit limits the requests sent to any single host to the given
+// number of requests per second. A value of zero, or less, removes the limit.
+func SetHostRateLimit(perSecond float64) {
+	limiters.mu.Lock()
+	defer limiters.mu.Unlock()
+
+	limiters.limit = rate.Inf
+	if perSecond > 0 {
+		limiters.limit = rate.Limit(perSecond)
+	}
+	limiters.limiters = map[string]*rate.Limiter{}
+}
+
+// limiter returns the rate limiter for the host, or nil if requests are not
+// limited.
+func (h *hostLimiters) limiter(host string) *rate.Limiter {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.limit == rate.Inf {
+		return nil
+	}
+
+	l, ok := h.limiters[host]
+	if !ok {
+		l = rate.NewLimiter(h.limit, 1)
+		h.limiters[host] = l
+	}
+
+	return l
+}
+
+type rateLimitingRoundTripper struct {
+	base     http.RoundTripper
+	limiters *hostLimiters
+}
+
+// NewRateLimitingRoundTripper returns a RoundTripper that delays requests so
+// that the rate limit set by SetHostRateLimit is not exceeded for any host. The
+// wait is cut short, with an error, if the context of the request is canceled.
+func NewRateLimitingRoundTripper(transport http.RoundTripper) http.RoundTripper {
+	return &rateLimitingRoundTripper{transport, limiters}
+}
+
+func (t *rateLimitingRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	if l := t.limiters.limiter(req.URL.Host); l != nil {
+		if err := l.Wait(req.Context()); err != nil {
+			return nil, err
+		}
+	}
+
+	return t.base.RoundTrip(req)
+}
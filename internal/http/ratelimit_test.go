@@ -0,0 +1,80 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package http
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRateLimitingRoundTripper(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(server.Close)
+
+	t.Cleanup(func() { SetHostRateLimit(0) })
+
+	client := http.Client{Transport: NewRateLimitingRoundTripper(http.DefaultTransport)}
+	fetch := func(n int) time.Duration {
+		start := time.Now()
+		for i := 0; i < n; i++ {
+			resp, err := client.Get(server.URL)
+			require.NoError(t, err)
+			resp.Body.Close()
+		}
+		return time.Since(start)
+	}
+
+	// the first request is sent right away, each of the following 4 requests
+	// is delayed by 50ms
+	SetHostRateLimit(20)
+	assert.GreaterOrEqual(t, fetch(5), 190*time.Millisecond)
+}
+
+func TestRateLimitingRoundTripperCanceled(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(server.Close)
+
+	SetHostRateLimit(0.1)
+	t.Cleanup(func() { SetHostRateLimit(0) })
+
+	client := http.Client{Transport: NewRateLimitingRoundTripper(http.DefaultTransport)}
+	resp, err := client.Get(server.URL)
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+	require.NoError(t, err)
+
+	start := time.Now()
+	_, err = client.Do(req)
+	assert.Error(t, err)
+	assert.Less(t, time.Since(start), time.Second)
+}
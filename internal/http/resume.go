@@ -0,0 +1,144 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package http
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+)
+
+type resumingRoundTripper struct {
+	base http.RoundTripper
+}
+
+// NewResumingRoundTripper returns a RoundTripper that resumes downloads
+// interrupted while reading the response body. The remainder of the content
+// is requested using a HTTP range request, continuing from the last received
+// byte. Servers without support for range requests respond with the full
+// content, in which case the already received bytes are skipped. The number
+// of times a download is resumed is limited by DefaultRetry.MaxRetry.
+func NewResumingRoundTripper(transport http.RoundTripper) http.RoundTripper {
+	return &resumingRoundTripper{transport}
+}
+
+func (t *resumingRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		return resp, err
+	}
+
+	if req.Method != http.MethodGet || req.Header.Get("Range") != "" || resp.StatusCode != http.StatusOK {
+		return resp, nil
+	}
+
+	resp.Body = &resumingBody{
+		transport:    t.base,
+		req:          req,
+		body:         resp.Body,
+		etag:         resp.Header.Get("ETag"),
+		lastModified: resp.Header.Get("Last-Modified"),
+	}
+
+	return resp, nil
+}
+
+// resumingBody reads the response body, resuming the download if reading
+// fails before all of the content has been received.
+type resumingBody struct {
+	transport    http.RoundTripper
+	req          *http.Request
+	body         io.ReadCloser
+	etag         string
+	lastModified string
+	// received is the number of bytes read so far
+	received int64
+	resumed  int
+}
+
+func (b *resumingBody) Read(p []byte) (int, error) {
+	for {
+		n, err := b.body.Read(p)
+		b.received += int64(n)
+		if err == nil || errors.Is(err, io.EOF) || b.req.Context().Err() != nil || b.resumed >= DefaultRetry.MaxRetry {
+			return n, err
+		}
+
+		log.Debugf("Download of %s interrupted after %d bytes: %v", b.req.URL, b.received, err)
+		if rerr := b.resume(); rerr != nil {
+			return n, errors.Join(err, rerr)
+		}
+
+		if n > 0 {
+			return n, nil
+		}
+	}
+}
+
+func (b *resumingBody) Close() error {
+	return b.body.Close()
+}
+
+// resume replaces the response body with the remainder of the content,
+// starting at the last received byte.
+func (b *resumingBody) resume() error {
+	b.resumed++
+	_ = b.body.Close()
+
+	req := b.req.Clone(b.req.Context())
+	req.Header.Set("Range", fmt.Sprintf("bytes=%d-", b.received))
+	if b.etag != "" && !strings.HasPrefix(b.etag, "W/") {
+		req.Header.Set("If-Range", b.etag)
+	} else if b.lastModified != "" {
+		req.Header.Set("If-Range", b.lastModified)
+	}
+
+	resp, err := b.transport.RoundTrip(req)
+	if err != nil {
+		return err
+	}
+
+	switch resp.StatusCode {
+	case http.StatusPartialContent:
+		if !strings.HasPrefix(resp.Header.Get("Content-Range"), fmt.Sprintf("bytes %d-", b.received)) {
+			resp.Body.Close()
+			return fmt.Errorf("unable to resume the download of %s, unexpected content range %q", b.req.URL, resp.Header.Get("Content-Range"))
+		}
+		log.Debugf("Resuming the download of %s from byte %d", b.req.URL, b.received)
+	case http.StatusOK:
+		if resp.Header.Get("ETag") != b.etag || resp.Header.Get("Last-Modified") != b.lastModified {
+			resp.Body.Close()
+			return fmt.Errorf("unable to resume the download of %s, the content has changed", b.req.URL)
+		}
+		log.Debugf("Range requests not supported for %s, downloading it again", b.req.URL)
+		if _, err := io.CopyN(io.Discard, resp.Body, b.received); err != nil {
+			resp.Body.Close()
+			return err
+		}
+	default:
+		resp.Body.Close()
+		return fmt.Errorf("unable to resume the download of %s, unexpected status: %s", b.req.URL, resp.Status)
+	}
+
+	b.body = resp.Body
+
+	return nil
+}
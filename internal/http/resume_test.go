@@ -0,0 +1,121 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package http
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// interruptingServer serves the content, interrupting the first response
+// half-way through. The number of bytes sent is added to sent.
+func interruptingServer(t *testing.T, content []byte, ranges bool, etag func() string, sent *atomic.Int64) *httptest.Server {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", etag())
+		if requests.Add(1) == 1 {
+			w.Header().Set("Content-Length", strconv.Itoa(len(content)))
+			w.WriteHeader(http.StatusOK)
+			n, _ := w.Write(content[:len(content)/2])
+			sent.Add(int64(n))
+			w.(http.Flusher).Flush()
+			panic(http.ErrAbortHandler)
+		}
+
+		if !ranges {
+			n, _ := w.Write(content)
+			sent.Add(int64(n))
+			return
+		}
+
+		cw := &countingWriter{ResponseWriter: w, sent: sent}
+		http.ServeContent(cw, r, "", time.Time{}, bytes.NewReader(content))
+	}))
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+type countingWriter struct {
+	http.ResponseWriter
+	sent *atomic.Int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.ResponseWriter.Write(p)
+	c.sent.Add(int64(n))
+	return n, err
+}
+
+func TestResumingRoundTripper(t *testing.T) {
+	content := bytes.Repeat([]byte("0123456789abcdef"), 64*1024)
+	etag := func() string { return `"v1"` }
+
+	cases := []struct {
+		name   string
+		ranges bool
+		sent   int64
+	}{
+		{name: "range requests", ranges: true, sent: int64(len(content))},
+		{name: "no range requests", ranges: false, sent: int64(len(content) + len(content)/2)},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var sent atomic.Int64
+			server := interruptingServer(t, content, c.ranges, etag, &sent)
+
+			client := http.Client{Transport: NewResumingRoundTripper(http.DefaultTransport)}
+			resp, err := client.Get(server.URL)
+			require.NoError(t, err)
+			defer resp.Body.Close()
+
+			received, err := io.ReadAll(resp.Body)
+			require.NoError(t, err)
+			assert.Equal(t, content, received)
+			assert.Equal(t, c.sent, sent.Load())
+		})
+	}
+}
+
+func TestResumingRoundTripperChangedContent(t *testing.T) {
+	content := bytes.Repeat([]byte("0123456789abcdef"), 64*1024)
+	var version atomic.Int32
+	etag := func() string { return `"v` + strconv.Itoa(int(version.Add(1))) + `"` }
+
+	var sent atomic.Int64
+	server := interruptingServer(t, content, true, etag, &sent)
+
+	client := http.Client{Transport: NewResumingRoundTripper(http.DefaultTransport)}
+	resp, err := client.Get(server.URL)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	_, err = io.ReadAll(resp.Body)
+	assert.ErrorContains(t, err, "the content has changed")
+}
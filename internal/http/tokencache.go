@@ -0,0 +1,294 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package http
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/sync/singleflight"
+)
+
+// defaultTokenLifetime is the lifetime of a token without an expires_in, as
+// given by the token authentication specification of the registry API.
+const defaultTokenLifetime = 60 * time.Second
+
+// tokenExpiryMargin is subtracted from the lifetime of a token so that a token
+// is not used just as it expires.
+const tokenExpiryMargin = 10 * time.Second
+
+// tokenResponse is a response of a token endpoint
+type tokenResponse struct {
+	status  int
+	header  http.Header
+	body    []byte
+	token   string
+	expires time.Time
+}
+
+// tokenCache holds the tokens issued by the token endpoints of registries, by
+// the token request.
+type tokenCache struct {
+	mu      sync.Mutex
+	enabled bool
+	// realms are the URLs of the token endpoints, as announced by registries
+	// in the WWW-Authenticate header of their responses
+	realms  map[string]bool
+	tokens  map[string]tokenResponse
+	flights singleflight.Group
+}
+
+var tokens = &tokenCache{enabled: true, realms: map[string]bool{}, tokens: map[string]tokenResponse{}}
+
+// SetTokenCaching enables or disables the caching of the bearer tokens used to
+// access registries. Any cached tokens are dropped.
+func SetTokenCaching(enabled bool) {
+	tokens.mu.Lock()
+	defer tokens.mu.Unlock()
+
+	tokens.enabled = enabled
+	tokens.realms = map[string]bool{}
+	tokens.tokens = map[string]tokenResponse{}
+}
+
+func (c *tokenCache) isEnabled() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.enabled
+}
+
+func (c *tokenCache) isRealm(u string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.realms[u]
+}
+
+func (c *tokenCache) addRealm(u string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.realms[u] = true
+}
+
+func (c *tokenCache) get(key string) (tokenResponse, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	t, ok := c.tokens[key]
+	if !ok || !time.Now().Before(t.expires) {
+		return tokenResponse{}, false
+	}
+
+	return t, true
+}
+
+func (c *tokenCache) put(key string, t tokenResponse) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.tokens[key] = t
+}
+
+// evict drops the cached responses holding the given token
+func (c *tokenCache) evict(token string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for key, t := range c.tokens {
+		if t.token == token {
+			delete(c.tokens, key)
+		}
+	}
+}
+
+type tokenCachingRoundTripper struct {
+	base  http.RoundTripper
+	cache *tokenCache
+}
+
+// NewTokenCachingRoundTripper returns a RoundTripper that caches the responses
+// of the token endpoints of registries, so that the bearer token obtained for
+// a registry and scope is reused by all the operations of a run until the token
+// expires. Token endpoints are recognized by the realm of the WWW-Authenticate
+// header of registry responses. Tokens are cached by the full token request,
+// i.e. the token endpoint, the service and scope, and the credentials, so a
+// token is never reused for another registry or scope. Concurrent requests
+// for the same token are sent once. A token rejected by a registry is dropped
+// from the cache. See SetTokenCaching to disable the cache.
+func NewTokenCachingRoundTripper(transport http.RoundTripper) http.RoundTripper {
+	return &tokenCachingRoundTripper{transport, tokens}
+}
+
+func (t *tokenCachingRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	if !t.cache.isEnabled() {
+		return t.base.RoundTrip(req)
+	}
+
+	if t.cache.isRealm(realmOf(req)) {
+		return t.token(req)
+	}
+
+	resp, err := t.base.RoundTrip(req)
+	if err != nil || resp.StatusCode != http.StatusUnauthorized {
+		return resp, err
+	}
+
+	if token, ok := strings.CutPrefix(req.Header.Get("Authorization"), "Bearer "); ok {
+		t.cache.evict(token)
+	}
+
+	for _, challenge := range resp.Header.Values("WWW-Authenticate") {
+		if realm := bearerRealm(challenge); realm != "" {
+			t.cache.addRealm(realm)
+		}
+	}
+
+	return resp, nil
+}
+
+// token returns the response of the token endpoint, from the cache if a token
+// for the same request has been issued and has not expired yet.
+func (t *tokenCachingRoundTripper) token(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		if body, err = io.ReadAll(req.Body); err != nil {
+			return nil, err
+		}
+		req.Body.Close()
+	}
+
+	key := tokenKey(req, body)
+	if cached, ok := t.cache.get(key); ok {
+		log.Debugf("Using the cached token from %s", req.URL.Host)
+		return cached.response(req), nil
+	}
+
+	v, err, _ := t.cache.flights.Do(key, func() (any, error) {
+		r := req.Clone(req.Context())
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		resp, err := t.base.RoundTrip(r)
+		if err != nil {
+			return tokenResponse{}, err
+		}
+		defer resp.Body.Close()
+
+		data, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return tokenResponse{}, err
+		}
+
+		tr := tokenResponse{status: resp.StatusCode, header: resp.Header, body: data}
+		if resp.StatusCode != http.StatusOK {
+			return tr, nil
+		}
+
+		var issued struct {
+			Token       string `json:"token"`
+			AccessToken string `json:"access_token"`
+			ExpiresIn   int    `json:"expires_in"`
+		}
+		if err := json.Unmarshal(data, &issued); err != nil {
+			return tr, nil
+		}
+
+		lifetime := defaultTokenLifetime
+		if issued.ExpiresIn > 0 {
+			lifetime = time.Duration(issued.ExpiresIn) * time.Second
+		}
+		tr.token = issued.AccessToken
+		if tr.token == "" {
+			tr.token = issued.Token
+		}
+		if tr.token != "" && lifetime > tokenExpiryMargin {
+			tr.expires = time.Now().Add(lifetime - tokenExpiryMargin)
+			t.cache.put(key, tr)
+		}
+
+		return tr, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return v.(tokenResponse).response(req), nil
+}
+
+// response returns a new HTTP response for the request from the token
+// response.
+func (t tokenResponse) response(req *http.Request) *http.Response {
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", t.status, http.StatusText(t.status)),
+		StatusCode:    t.status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        t.header.Clone(),
+		Body:          io.NopCloser(bytes.NewReader(t.body)),
+		ContentLength: int64(len(t.body)),
+		Request:       req,
+	}
+}
+
+// realmOf returns the URL of the request without the query, to be compared
+// with the realm of a token endpoint.
+func realmOf(req *http.Request) string {
+	u := *req.URL
+	u.RawQuery = ""
+	u.Fragment = ""
+	return u.String()
+}
+
+// tokenKey identifies the token request by its method, URL, including the
+// service and scope, credentials and body. The credentials and body are
+// hashed so they are not held as is in the cache.
+func tokenKey(req *http.Request, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(req.Header.Get("Authorization")))
+	h.Write([]byte{0})
+	h.Write(body)
+	return req.Method + " " + req.URL.String() + " " + hex.EncodeToString(h.Sum(nil))
+}
+
+// bearerRealm returns the realm of a Bearer WWW-Authenticate challenge, e.g.
+// https://auth.io/token from Bearer realm="https://auth.io/token",service="registry.io"
+func bearerRealm(challenge string) string {
+	scheme, params, ok := strings.Cut(strings.TrimSpace(challenge), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return ""
+	}
+
+	for _, param := range strings.Split(params, ",") {
+		key, value, ok := strings.Cut(strings.TrimSpace(param), "=")
+		if ok && strings.EqualFold(key, "realm") {
+			return strings.Trim(value, `"`)
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,147 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package http
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// tokenServer is a registry issuing a new token, valid for the given number of
+// seconds, on each token request
+type tokenServer struct {
+	*httptest.Server
+	expiresIn int
+	issued    atomic.Int32
+	// revoked tokens are rejected by the registry
+	revoked sync.Map
+}
+
+func newTokenServer(t *testing.T, expiresIn int) *tokenServer {
+	s := &tokenServer{expiresIn: expiresIn}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/token" {
+			n := s.issued.Add(1)
+			fmt.Fprintf(w, `{"token": "token-%d-%s", "expires_in": %d}`, n, r.URL.Query().Get("scope"), s.expiresIn)
+			return
+		}
+
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if _, revoked := s.revoked.Load(token); !ok || revoked {
+			w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Bearer realm="%s/token",service="registry"`, s.URL))
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/vnd.oci.image.manifest.v1+json")
+		w.Header().Set("Docker-Content-Digest", "sha256:4e388ab32b10dc8dbc7e28144f552830adc74787c1e2c0824032078a79f227fb")
+		w.Header().Set("Content-Length", "2")
+	}))
+	t.Cleanup(s.Close)
+
+	SetTokenCaching(true)
+	t.Cleanup(func() { SetTokenCaching(true) })
+
+	return s
+}
+
+func (s *tokenServer) head(t *testing.T, repository string) {
+	ref, err := name.ParseReference(strings.TrimPrefix(s.URL, "http://")+"/"+repository+":latest", name.Insecure)
+	require.NoError(t, err)
+
+	_, err = remote.Head(ref, remote.WithTransport(NewTokenCachingRoundTripper(http.DefaultTransport)))
+	require.NoError(t, err)
+}
+
+func TestTokenCachingRoundTripper(t *testing.T) {
+	s := newTokenServer(t, 300)
+
+	s.head(t, "spam")
+	s.head(t, "spam")
+	assert.Equal(t, int32(1), s.issued.Load())
+
+	// another scope needs another token
+	s.head(t, "eggs")
+	assert.Equal(t, int32(2), s.issued.Load())
+	s.head(t, "eggs")
+	assert.Equal(t, int32(2), s.issued.Load())
+}
+
+func TestTokenCachingRoundTripperConcurrent(t *testing.T) {
+	s := newTokenServer(t, 300)
+
+	// learn the realm of the token endpoint
+	s.head(t, "spam")
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.head(t, "eggs")
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, int32(2), s.issued.Load())
+}
+
+func TestTokenCachingRoundTripperExpired(t *testing.T) {
+	// tokens expiring within the expiry margin are not cached
+	s := newTokenServer(t, 5)
+
+	s.head(t, "spam")
+	s.head(t, "spam")
+	assert.Equal(t, int32(2), s.issued.Load())
+}
+
+func TestTokenCachingRoundTripperRejected(t *testing.T) {
+	s := newTokenServer(t, 300)
+
+	s.head(t, "spam")
+	s.revoked.Store("token-1-repository:spam:pull", true)
+	s.head(t, "spam")
+	assert.Equal(t, int32(2), s.issued.Load())
+}
+
+func TestTokenCachingRoundTripperDisabled(t *testing.T) {
+	s := newTokenServer(t, 300)
+	SetTokenCaching(false)
+
+	s.head(t, "spam")
+	s.head(t, "spam")
+	assert.Equal(t, int32(2), s.issued.Load())
+}
+
+func TestBearerRealm(t *testing.T) {
+	assert.Equal(t, "https://auth.io/token", bearerRealm(`Bearer realm="https://auth.io/token",service="registry.io"`))
+	assert.Equal(t, "https://auth.io/token", bearerRealm(`bearer service="registry.io", realm="https://auth.io/token"`))
+	assert.Equal(t, "", bearerRealm(`Basic realm="registry.io"`))
+	assert.Equal(t, "", bearerRealm(""))
+}
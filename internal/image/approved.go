@@ -0,0 +1,121 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package image
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/spf13/afero"
+
+	"github.com/enterprise-contract/ec-cli/internal/logging"
+	"github.com/enterprise-contract/ec-cli/internal/policy/source"
+	"github.com/enterprise-contract/ec-cli/internal/utils"
+)
+
+// ApprovedDigests is a set of approved image digests, e.g. sha256:<hex>.
+type ApprovedDigests map[string]bool
+
+// Contains returns true if the given digest is approved.
+func (a ApprovedDigests) Contains(digest string) bool {
+	return a[digest]
+}
+
+// LoadApprovedDigests reads the list of approved digests from the given
+// location, a path of a local file or a URL of a file that is downloaded using
+// the same mechanism used for fetching policy sources, e.g. a file in a git
+// repository.
+func LoadApprovedDigests(ctx context.Context, location string) (ApprovedDigests, error) {
+	fs := utils.FS(ctx)
+
+	path := location
+	if exists, err := afero.Exists(fs, location); err != nil || !exists {
+		if path, err = downloadApprovedDigests(ctx, location); err != nil {
+			return nil, err
+		}
+	}
+
+	data, err := afero.ReadFile(fs, path)
+	if err != nil {
+		return nil, fmt.Errorf("reading approved digests from %s: %w", logging.RedactURL(location), err)
+	}
+
+	return parseApprovedDigests(data)
+}
+
+func downloadApprovedDigests(ctx context.Context, url string) (string, error) {
+	fs := utils.FS(ctx)
+	redacted := logging.RedactURL(url)
+
+	dir, err := afero.TempDir(fs, afero.GetTempDir(fs, ""), "approved-digests-")
+	if err != nil {
+		return "", err
+	}
+
+	// the trailing slash makes the downloader place the file within the
+	// directory
+	if _, err := source.Download(ctx, dir+"/", url, false); err != nil {
+		return "", fmt.Errorf("fetching approved digests from %s: %w", redacted, err)
+	}
+
+	entries, err := afero.ReadDir(fs, dir)
+	if err != nil {
+		return "", err
+	}
+	if len(entries) != 1 || entries[0].IsDir() {
+		return "", fmt.Errorf("fetching approved digests from %s: expected a single file to be downloaded, found %d entries", redacted, len(entries))
+	}
+
+	return filepath.Join(dir, entries[0].Name()), nil
+}
+
+// parseApprovedDigests parses a list with a digest or an image reference
+// including a digest per line. Empty lines and lines starting with # are
+// ignored.
+func parseApprovedDigests(data []byte) (ApprovedDigests, error) {
+	approved := ApprovedDigests{}
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		digest := line
+		if _, d, found := strings.Cut(line, "@"); found {
+			digest = d
+		}
+
+		if _, err := v1.NewHash(digest); err != nil {
+			return nil, fmt.Errorf("invalid approved digest %q on line %d, expected a digest such as sha256:<hex> or an image reference with a digest", line, n)
+		}
+
+		approved[digest] = true
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return approved, nil
+}
@@ -0,0 +1,93 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package image
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/enterprise-contract/go-gather/metadata"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/enterprise-contract/ec-cli/internal/policy/source"
+	"github.com/enterprise-contract/ec-cli/internal/utils"
+)
+
+type mockDownloader struct {
+	mock.Mock
+}
+
+func (m *mockDownloader) Download(_ context.Context, dest string, sourceUrl string, showMsg bool) (metadata.Metadata, error) {
+	args := m.Called(dest, sourceUrl, showMsg)
+
+	return nil, args.Error(0)
+}
+
+const approvedList = `# approved images
+sha256:4e388ab32b10dc8dbc7e28144f552830adc74787c1e2c0824032078a79f227fb
+
+registry.io/repository/image@sha256:a8b3d1d2f1d5b33e2c3bd3e12f14d8c9f11e6d0e3c4a7b6d5e4f3a2b1c0d9e8f
+`
+
+func TestLoadApprovedDigests(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	ctx := utils.WithFS(context.Background(), fs)
+	require.NoError(t, afero.WriteFile(fs, "approved.txt", []byte(approvedList), 0400))
+
+	approved, err := LoadApprovedDigests(ctx, "approved.txt")
+	require.NoError(t, err)
+
+	assert.Equal(t, ApprovedDigests{
+		"sha256:4e388ab32b10dc8dbc7e28144f552830adc74787c1e2c0824032078a79f227fb": true,
+		"sha256:a8b3d1d2f1d5b33e2c3bd3e12f14d8c9f11e6d0e3c4a7b6d5e4f3a2b1c0d9e8f": true,
+	}, approved)
+	assert.True(t, approved.Contains("sha256:4e388ab32b10dc8dbc7e28144f552830adc74787c1e2c0824032078a79f227fb"))
+	assert.False(t, approved.Contains("sha256:0000"))
+	assert.False(t, approved.Contains(""))
+}
+
+func TestLoadApprovedDigestsFromURL(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	ctx := utils.WithFS(context.Background(), fs)
+
+	url := "git::https://github.com/org/repo//approved.txt"
+	dl := mockDownloader{}
+	ctx = context.WithValue(ctx, source.DownloaderFuncKey, &dl)
+	dl.On("Download", mock.Anything, url, false).Return(nil).Run(func(args mock.Arguments) {
+		dest := args.String(0)
+		require.NoError(t, afero.WriteFile(fs, filepath.Join(dest, "approved.txt"), []byte(approvedList), 0600))
+	})
+
+	approved, err := LoadApprovedDigests(ctx, url)
+	require.NoError(t, err)
+	assert.Len(t, approved, 2)
+}
+
+func TestLoadApprovedDigestsInvalid(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	ctx := utils.WithFS(context.Background(), fs)
+	require.NoError(t, afero.WriteFile(fs, "approved.txt", []byte("sha256:4e388ab32b10dc8dbc7e28144f552830adc74787c1e2c0824032078a79f227fb\nregistry.io/image:tag\n"), 0400))
+
+	_, err := LoadApprovedDigests(ctx, "approved.txt")
+	assert.EqualError(t, err, `invalid approved digest "registry.io/image:tag" on line 2, expected a digest such as sha256:<hex> or an image reference with a digest`)
+}
@@ -0,0 +1,53 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package image
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+)
+
+// ParseImageConfig returns the config of an image, as provided in the policy
+// input, from the given image config file, e.g. as saved by skopeo inspect
+// --config. The config, i.e. the part holding the labels, is also accepted on
+// its own.
+func ParseImageConfig(data []byte) (json.RawMessage, error) {
+	var doc map[string]json.RawMessage
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("parsing the image config: %w", err)
+	}
+
+	var config v1.Config
+	if c, ok := doc["config"]; ok {
+		// the whole image config file
+		if err := json.Unmarshal(c, &config); err != nil {
+			return nil, fmt.Errorf("parsing the image config: %w", err)
+		}
+	} else if err := json.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("parsing the image config: %w", err)
+	}
+
+	if reflect.DeepEqual(config, v1.Config{}) {
+		return nil, errors.New("no image config found, expected an image config file or its config section")
+	}
+
+	return json.Marshal(config)
+}
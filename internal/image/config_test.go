@@ -0,0 +1,68 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package image
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseImageConfig(t *testing.T) {
+	cases := []struct {
+		name     string
+		data     string
+		expected string
+		err      string
+	}{
+		{
+			name:     "config file",
+			data:     `{"architecture": "amd64", "os": "linux", "config": {"Labels": {"vendor": "Spam"}}}`,
+			expected: `{"Labels": {"vendor": "Spam"}}`,
+		},
+		{
+			name:     "config section",
+			data:     `{"Labels": {"vendor": "Spam"}, "User": "1001"}`,
+			expected: `{"Labels": {"vendor": "Spam"}, "User": "1001"}`,
+		},
+		{
+			name: "not an image config",
+			data: `{"schemaVersion": 2}`,
+			err:  "no image config found, expected an image config file or its config section",
+		},
+		{
+			name: "invalid",
+			data: `spam`,
+			err:  "parsing the image config: invalid character 's' looking for beginning of value",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			config, err := ParseImageConfig([]byte(c.data))
+			if c.err != "" {
+				assert.EqualError(t, err, c.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.JSONEq(t, c.expected, string(config))
+		})
+	}
+}
@@ -19,9 +19,11 @@ package image
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"sort"
 	"time"
 
+	"github.com/google/go-containerregistry/pkg/name"
 	app "github.com/konflux-ci/application-api/api/v1alpha1"
 	"github.com/qri-io/jsonpointer"
 	log "github.com/sirupsen/logrus"
@@ -75,6 +77,12 @@ func ValidateImage(ctx context.Context, comp app.SnapshotComponent, snap *app.Sn
 
 	out.Signatures = a.Signatures()
 
+	if created, err := a.ImageCreated(ctx); err != nil {
+		log.Debugf("Unable to determine the creation time of the image: %s", err)
+	} else {
+		out.ImageCreated = created
+	}
+
 	out.Attestations = a.Attestations()
 
 	out.SetAttestationSyntaxCheckFromError(a.ValidateAttestationSyntax(ctx))
@@ -99,39 +107,116 @@ func ValidateImage(ctx context.Context, comp app.SnapshotComponent, snap *app.Sn
 		return out, nil
 	}
 
+	if err := a.FetchSBOMs(ctx); err != nil {
+		log.Debugf("Unable to fetch the SBOMs attached to the image: %s", err)
+	}
+
 	inputPath, inputJSON, err := a.WriteInputFile(ctx)
 	if err != nil {
 		log.Debug("Problem writing input files!")
 		return nil, err
 	}
 
+	target := func() string {
+		digest, err := a.ResolveDigest(ctx)
+		if err != nil {
+			log.Debugf("Problem parsing digest from image")
+		}
+		return digest
+	}
+
+	if err := evaluate(ctx, out, evaluators, comp, inputPath, target); err != nil {
+		return nil, err
+	}
+
+	out.PolicyInput = inputJSON
+
+	return out, nil
+}
+
+// ValidateImageConfig evaluates the policy against the image using only the
+// given image config, without accessing the registry. The image signature and
+// the attestations are not verified, the input holds only the reference and
+// the config of the image, e.g. for checking its labels offline.
+func ValidateImageConfig(ctx context.Context, comp app.SnapshotComponent, snap *app.SnapshotSpec, config json.RawMessage, evaluators []evaluator.Evaluator, detailed bool) (*output.Output, error) {
+	log.Debugf("Validating the config of image %s", comp.ContainerImage)
+
+	// The checks requiring access to the registry are not performed
+	out := &output.Output{
+		ImageURL:                  comp.ContainerImage,
+		Detailed:                  detailed,
+		ImageAccessibleCheck:      output.VerificationStatus{Passed: true},
+		ImageSignatureCheck:       output.VerificationStatus{Passed: true},
+		AttestationSignatureCheck: output.VerificationStatus{Passed: true},
+		AttestationSyntaxCheck:    output.VerificationStatus{Passed: true},
+	}
+
+	a, err := application_snapshot_image.NewOfflineApplicationSnapshotImage(comp, *snap, config)
+	if err != nil {
+		return nil, err
+	}
+
+	inputPath, inputJSON, err := a.WriteInputFile(ctx)
+	if err != nil {
+		log.Debug("Problem writing input files!")
+		return nil, err
+	}
+
+	target := func() string {
+		if digest, ok := a.GetReference().(name.Digest); ok {
+			return digest.DigestStr()
+		}
+		return ""
+	}
+
+	if err := evaluate(ctx, out, evaluators, comp, inputPath, target); err != nil {
+		return nil, err
+	}
+
+	out.PolicyInput = inputJSON
+
+	return out, nil
+}
+
+// evaluate runs the evaluators against the input and sets the outcome as the
+// policy check of the output.
+func evaluate(ctx context.Context, out *output.Output, evaluators []evaluator.Evaluator, comp app.SnapshotComponent, inputPath string, target func() string) error {
 	var allResults []evaluator.Outcome
+	// Track if any of the evaluators found at least one rule applicable to the
+	// image.
+	applicable := false
+
+	// The target is resolved only when there is a policy to evaluate, it
+	// requires access to the registry
+	if len(evaluators) > 0 {
+		out.Target = target()
+	}
 
 	for _, e := range evaluators {
 		// Todo maybe: Handle each one concurrently
-		target := evaluator.EvaluationTarget{Inputs: []string{inputPath}}
-		if digest, err := a.ResolveDigest(ctx); err != nil {
-			log.Debugf("Problem parsing digest from image")
-		} else {
-			target.Target = digest
-		}
-		results, data, err := e.Evaluate(ctx, target)
+		results, data, err := e.Evaluate(ctx, evaluator.EvaluationTarget{Inputs: []string{inputPath}, Target: out.Target})
 		log.Debug("\n\nRunning conftest policy check\n\n")
 
+		if errors.Is(err, evaluator.ErrNoApplicableRules) {
+			log.Debugf("No policy rules applicable to image %s", comp.ContainerImage)
+			continue
+		}
+
 		if err != nil {
 			log.Debug("Problem running conftest policy check!")
-			return nil, err
+			return err
 		}
+		applicable = true
 		allResults = append(allResults, results...)
 		out.Data = append(out.Data, data)
 	}
 
-	out.PolicyInput = inputJSON
+	out.NoApplicableRules = len(evaluators) > 0 && !applicable
 
 	log.Debug("Conftest policy check complete")
 	out.SetPolicyCheck(allResults)
 
-	return out, nil
+	return nil
 }
 
 func resolveAndSetImageUrl(ctx context.Context, url string, asi *application_snapshot_image.ApplicationSnapshotImage) (string, error) {
@@ -79,6 +79,7 @@ func TestBuiltinChecks(t *testing.T) {
 				c.On("Head", ref).Return(&gcr.Descriptor{MediaType: types.OCIManifestSchema1}, nil)
 				c.On("VerifyImageSignatures", refNoTag, mock.Anything).Return([]oci.Signature{validSignature}, true, nil)
 				c.On("VerifyImageAttestations", refNoTag, mock.Anything).Return([]oci.Signature{validAttestation}, true, nil)
+				withoutSBOMs(c)
 			},
 			component:          app.SnapshotComponent{ContainerImage: imageRef},
 			expectedViolations: []evaluator.Result{},
@@ -105,6 +106,7 @@ func TestBuiltinChecks(t *testing.T) {
 				c.On("Head", ref).Return(&gcr.Descriptor{MediaType: types.OCIManifestSchema1}, nil)
 				c.On("VerifyImageSignatures", refNoTag, mock.Anything).Return(nil, false, errors.New("no image signatures client error"))
 				c.On("VerifyImageAttestations", refNoTag, mock.Anything).Return([]oci.Signature{validAttestation}, true, nil)
+				withoutSBOMs(c)
 			},
 			component: app.SnapshotComponent{ContainerImage: imageRef},
 			expectedViolations: []evaluator.Result{
@@ -121,6 +123,7 @@ func TestBuiltinChecks(t *testing.T) {
 				c.On("Head", ref).Return(&gcr.Descriptor{MediaType: types.OCIManifestSchema1}, nil)
 				c.On("VerifyImageSignatures", refNoTag, mock.Anything).Return(validSignature, true, nil)
 				c.On("VerifyImageAttestations", refNoTag, mock.Anything).Return(nil, false, errors.New("no image attestations client error"))
+				withoutSBOMs(c)
 			},
 			component: app.SnapshotComponent{ContainerImage: imageRef},
 			expectedViolations: []evaluator.Result{
@@ -285,6 +288,14 @@ func withImageConfig(ctx context.Context, url string) context.Context {
 	return fake.WithTestImageConfig(ctx, resolved)
 }
 
+// withoutSBOMs sets up the client so that no SBOMs are attached to the image
+func withoutSBOMs(c *fake.FakeClient) {
+	c.On("Referrers", mock.Anything, "").Return(nil, errors.New("no referrers"))
+	c.On("Image", mock.MatchedBy(func(ref name.Reference) bool {
+		return strings.HasSuffix(ref.Identifier(), ".sbom")
+	})).Return(nil, errors.New("no SBOM attached"))
+}
+
 type mockEvaluator struct {
 	mock.Mock
 }
@@ -315,6 +326,7 @@ func TestEvaluatorLifecycle(t *testing.T) {
 	client.On("VerifyImageSignatures", refNoTag, mock.Anything).Return([]oci.Signature{validSignature}, true, nil)
 	client.On("VerifyImageAttestations", refNoTag, mock.Anything).Return([]oci.Signature{validAttestation}, true, nil)
 	client.On("ResolveDigest", refNoTag).Return("@sha256:"+imageDigest, nil)
+	withoutSBOMs(&client)
 	ctx = ecoci.WithClient(ctx, &client)
 
 	component := app.SnapshotComponent{
@@ -346,3 +358,71 @@ func TestEvaluatorLifecycle(t *testing.T) {
 
 	require.NoError(t, err)
 }
+
+func TestNoApplicableRules(t *testing.T) {
+	ctx := context.Background()
+	client := fake.FakeClient{}
+	client.On("Head", mock.Anything).Return(&v1.Descriptor{MediaType: types.OCIManifestSchema1}, nil)
+	client.On("Image", name.MustParseReference(imageRegistry+"@sha256:"+imageDigest), mock.Anything).Return(empty.Image, nil)
+	client.On("VerifyImageSignatures", refNoTag, mock.Anything).Return([]oci.Signature{validSignature}, true, nil)
+	client.On("VerifyImageAttestations", refNoTag, mock.Anything).Return([]oci.Signature{validAttestation}, true, nil)
+	client.On("ResolveDigest", refNoTag).Return("@sha256:"+imageDigest, nil)
+	withoutSBOMs(&client)
+	ctx = ecoci.WithClient(ctx, &client)
+
+	component := app.SnapshotComponent{
+		ContainerImage: imageRef,
+	}
+
+	p, err := policy.NewOfflinePolicy(ctx, policy.Now)
+	require.NoError(t, err)
+
+	noRules := &mockEvaluator{}
+	noRules.On("Evaluate", ctx, mock.Anything).Return([]evaluator.Outcome(nil), evaluator.Data(nil), evaluator.ErrNoApplicableRules)
+
+	someRules := &mockEvaluator{}
+	someRules.On("Evaluate", ctx, mock.Anything).Return([]evaluator.Outcome{{Successes: []evaluator.Result{{Message: "Pass"}}}}, evaluator.Data{}, nil)
+
+	snap := app.SnapshotSpec{Components: []app.SnapshotComponent{component}}
+
+	out, err := ValidateImage(ctx, component, &snap, p, []evaluator.Evaluator{noRules}, false)
+	require.NoError(t, err)
+	assert.True(t, out.NoApplicableRules)
+
+	out, err = ValidateImage(ctx, component, &snap, p, []evaluator.Evaluator{noRules, someRules}, false)
+	require.NoError(t, err)
+	assert.False(t, out.NoApplicableRules)
+}
+
+func TestValidateImageConfig(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	ctx := utils.WithFS(context.Background(), fs)
+	// no calls to the registry are expected
+	ctx = ecoci.WithClient(ctx, &fake.FakeClient{})
+
+	component := app.SnapshotComponent{ContainerImage: imageRef}
+	snap := app.SnapshotSpec{Components: []app.SnapshotComponent{component}}
+
+	e := &mockEvaluator{}
+	var input []byte
+	e.On("Evaluate", ctx, mock.Anything).Run(func(args mock.Arguments) {
+		var err error
+		input, err = afero.ReadFile(fs, args.Get(1).([]string)[0])
+		require.NoError(t, err)
+	}).Return([]evaluator.Outcome{{Failures: []evaluator.Result{{Message: "Missing label"}}}}, evaluator.Data{}, nil)
+
+	out, err := ValidateImageConfig(ctx, component, &snap, json.RawMessage(`{"Labels":{"vendor":"Spam"}}`), []evaluator.Evaluator{e}, false)
+	require.NoError(t, err)
+
+	assert.Equal(t, input, out.PolicyInput)
+	var policyInput struct {
+		Image struct {
+			Ref    string         `json:"ref"`
+			Config map[string]any `json:"config"`
+		} `json:"image"`
+	}
+	require.NoError(t, json.Unmarshal(out.PolicyInput, &policyInput))
+	assert.Equal(t, imageRef, policyInput.Image.Ref)
+	assert.Equal(t, map[string]any{"Labels": map[string]any{"vendor": "Spam"}}, policyInput.Image.Config)
+	assert.Equal(t, []evaluator.Result{{Message: "Missing label"}}, out.Violations())
+}
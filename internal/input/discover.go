@@ -0,0 +1,242 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package input
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+
+	"github.com/enterprise-contract/ec-cli/internal/utils"
+)
+
+// File is a single input to validate. Label identifies the input in the
+// report, it is the path of the file with the index of the document appended
+// for multi-document YAML files. Path is the location of the file handed to
+// the evaluator.
+type File struct {
+	Label string
+	Path  string
+}
+
+// DiscoverOptions control which files are picked up by Discover.
+type DiscoverOptions struct {
+	// Recursive enables descending into subdirectories.
+	Recursive bool
+	// Include holds glob patterns, at least one of which must match a file for
+	// it to be included. All files are included if empty.
+	Include []string
+	// Exclude holds glob patterns, none of which must match a file for it to be
+	// included.
+	Exclude []string
+}
+
+// Discover returns the JSON and YAML files found in the given directory. Glob
+// patterns are matched against the path relative to the directory and against
+// the file name. Symbolic links to files are followed, symbolic links to
+// directories are not, which prevents loops. Each document of a multi-document
+// YAML file is returned as a separate File, written to a temporary directory
+// removed by the returned cleanup function once the files are evaluated.
+func Discover(ctx context.Context, dir string, opts DiscoverOptions) ([]File, func(), error) {
+	for _, pattern := range append(append([]string{}, opts.Include...), opts.Exclude...) {
+		if _, err := filepath.Match(pattern, ""); err != nil {
+			return nil, nil, fmt.Errorf("invalid glob pattern %q: %w", pattern, err)
+		}
+	}
+
+	fs := utils.FS(ctx)
+
+	// created on the first multi-document YAML file, shared by the documents
+	// of all files
+	var tmpDir string
+	documentsDir := func() (string, error) {
+		if tmpDir != "" {
+			return tmpDir, nil
+		}
+
+		var err error
+		tmpDir, err = afero.TempDir(fs, afero.GetTempDir(fs, ""), "input-files-")
+		return tmpDir, err
+	}
+	cleanup := func() {
+		if tmpDir == "" {
+			return
+		}
+		if err := fs.RemoveAll(tmpDir); err != nil {
+			log.Debugf("Unable to remove the temporary directory %s: %v", tmpDir, err)
+		}
+	}
+
+	var files []File
+	err := afero.Walk(fs, dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if info.IsDir() {
+			if path != dir && !opts.Recursive {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		if info.Mode()&os.ModeSymlink != 0 {
+			target, err := fs.Stat(path)
+			if err != nil {
+				log.Debugf("Skipping unresolvable symbolic link %s: %v", path, err)
+				return nil
+			}
+			if target.IsDir() {
+				log.Debugf("Skipping symbolic link to directory %s", path)
+				return nil
+			}
+		}
+
+		if !utils.HasJsonOrYamlExt(path) {
+			log.Debugf("Skipping non-manifest file %s", path)
+			return nil
+		}
+
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+
+		if len(opts.Include) > 0 && !matchesAny(opts.Include, rel) {
+			log.Debugf("Skipping %s, not matched by include patterns", path)
+			return nil
+		}
+
+		if matchesAny(opts.Exclude, rel) {
+			log.Debugf("Skipping %s, matched by exclude patterns", path)
+			return nil
+		}
+
+		documents, err := splitDocuments(ctx, path, documentsDir)
+		if err != nil {
+			return err
+		}
+		files = append(files, documents...)
+
+		return nil
+	})
+	if err != nil {
+		cleanup()
+		return nil, nil, err
+	}
+
+	if len(files) == 0 {
+		cleanup()
+		return nil, nil, fmt.Errorf("no JSON or YAML files found in %s", dir)
+	}
+
+	return files, cleanup, nil
+}
+
+// matchesAny returns true if any of the patterns matches the relative path or
+// the file name.
+func matchesAny(patterns []string, rel string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := filepath.Match(pattern, rel); ok {
+			return true
+		}
+		if ok, _ := filepath.Match(pattern, filepath.Base(rel)); ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+// splitDocuments returns a File for each of the documents within a YAML file.
+// Files with a single document, and JSON files, are returned as is. The
+// documents are written to the directory returned by documentsDir.
+func splitDocuments(ctx context.Context, path string, documentsDir func() (string, error)) ([]File, error) {
+	if filepath.Ext(path) == ".json" {
+		return []File{{Label: path, Path: path}}, nil
+	}
+
+	fs := utils.FS(ctx)
+	f, err := fs.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var documents [][]byte
+	reader := utilyaml.NewYAMLReader(bufio.NewReader(f))
+	for {
+		doc, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("reading %s: %w", path, err)
+		}
+		if len(bytes.TrimSpace(doc)) == 0 {
+			continue
+		}
+		documents = append(documents, doc)
+	}
+
+	if len(documents) <= 1 {
+		return []File{{Label: path, Path: path}}, nil
+	}
+
+	dir, err := documentsDir()
+	if err != nil {
+		return nil, err
+	}
+
+	files := make([]File, 0, len(documents))
+	for i, doc := range documents {
+		docPath, err := writeDocument(fs, dir, doc)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, File{Label: fmt.Sprintf("%s[%d]", path, i), Path: docPath})
+	}
+
+	return files, nil
+}
+
+// writeDocument writes the document to a new file within the directory. The
+// file has the .yaml extension so the document is parsed as YAML when
+// evaluated.
+func writeDocument(fs afero.Fs, dir string, doc []byte) (string, error) {
+	f, err := afero.TempFile(fs, dir, "input-file-*.yaml")
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	if _, err := f.Write(doc); err != nil {
+		_ = fs.Remove(f.Name())
+		return "", err
+	}
+
+	return f.Name(), nil
+}
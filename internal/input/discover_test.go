@@ -0,0 +1,155 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package input
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/enterprise-contract/ec-cli/internal/utils"
+)
+
+func TestDiscover(t *testing.T) {
+	files := map[string]string{
+		"/dir/a.json":          `{"a": 1}`,
+		"/dir/b.yaml":          "b: 1\n",
+		"/dir/multi.yaml":      "kind: One\n---\n---\nkind: Two\n",
+		"/dir/README.md":       "# not a manifest",
+		"/dir/sub/c.yml":       "c: 1\n",
+		"/dir/sub/c.test.yaml": "c: 2\n",
+	}
+
+	cases := []struct {
+		name     string
+		opts     DiscoverOptions
+		expected []string
+		err      string
+	}{
+		{
+			name:     "top level only",
+			expected: []string{"/dir/a.json", "/dir/b.yaml", "/dir/multi.yaml[0]", "/dir/multi.yaml[1]"},
+		},
+		{
+			name:     "recursive",
+			opts:     DiscoverOptions{Recursive: true},
+			expected: []string{"/dir/a.json", "/dir/b.yaml", "/dir/multi.yaml[0]", "/dir/multi.yaml[1]", "/dir/sub/c.test.yaml", "/dir/sub/c.yml"},
+		},
+		{
+			name:     "include",
+			opts:     DiscoverOptions{Recursive: true, Include: []string{"sub/*"}},
+			expected: []string{"/dir/sub/c.test.yaml", "/dir/sub/c.yml"},
+		},
+		{
+			name:     "exclude",
+			opts:     DiscoverOptions{Recursive: true, Exclude: []string{"*.test.yaml", "multi.yaml"}},
+			expected: []string{"/dir/a.json", "/dir/b.yaml", "/dir/sub/c.yml"},
+		},
+		{
+			name: "nothing matched",
+			opts: DiscoverOptions{Include: []string{"*.nope"}},
+			err:  "no JSON or YAML files found in /dir",
+		},
+		{
+			name: "invalid pattern",
+			opts: DiscoverOptions{Exclude: []string{"["}},
+			err:  `invalid glob pattern "["`,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			fs := afero.NewMemMapFs()
+			for name, content := range files {
+				require.NoError(t, afero.WriteFile(fs, name, []byte(content), 0644))
+			}
+			ctx := utils.WithFS(context.Background(), fs)
+
+			discovered, cleanup, err := Discover(ctx, "/dir", c.opts)
+			if c.err != "" {
+				assert.ErrorContains(t, err, c.err)
+				return
+			}
+			require.NoError(t, err)
+
+			labels := make([]string, 0, len(discovered))
+			for _, f := range discovered {
+				labels = append(labels, f.Label)
+				content, err := afero.ReadFile(fs, f.Path)
+				require.NoError(t, err)
+				assert.NotEmpty(t, content)
+			}
+			assert.Equal(t, c.expected, labels)
+
+			cleanup()
+			for _, f := range discovered {
+				exists, err := afero.Exists(fs, f.Path)
+				require.NoError(t, err)
+				assert.Equal(t, f.Label == f.Path, exists, f.Label)
+			}
+		})
+	}
+}
+
+func TestDiscoverDocumentsDirectory(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "/dir/one.yaml", []byte("kind: One\n---\nkind: Two\n"), 0644))
+	require.NoError(t, afero.WriteFile(fs, "/dir/two.yaml", []byte("kind: Three\n---\nkind: Four\n"), 0644))
+	ctx := utils.WithFS(context.Background(), fs)
+
+	discovered, cleanup, err := Discover(ctx, "/dir", DiscoverOptions{})
+	require.NoError(t, err)
+	require.Len(t, discovered, 4)
+
+	dir := filepath.Dir(discovered[0].Path)
+	for _, f := range discovered {
+		assert.Equal(t, dir, filepath.Dir(f.Path), "documents written to the same directory")
+	}
+
+	cleanup()
+	exists, err := afero.DirExists(fs, dir)
+	require.NoError(t, err)
+	assert.False(t, exists)
+	exists, err = afero.Exists(fs, "/dir/one.yaml")
+	require.NoError(t, err)
+	assert.True(t, exists)
+}
+
+func TestDiscoverSymlinkLoop(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "a.yaml"), []byte("a: 1\n"), 0600))
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "sub"), 0700))
+	require.NoError(t, os.Symlink(dir, filepath.Join(dir, "sub", "loop")))
+	require.NoError(t, os.Symlink(filepath.Join(dir, "a.yaml"), filepath.Join(dir, "sub", "link.yaml")))
+
+	ctx := utils.WithFS(context.Background(), afero.NewOsFs())
+
+	discovered, cleanup, err := Discover(ctx, dir, DiscoverOptions{Recursive: true})
+	require.NoError(t, err)
+	assert.Equal(t, []File{
+		{Label: filepath.Join(dir, "a.yaml"), Path: filepath.Join(dir, "a.yaml")},
+		{Label: filepath.Join(dir, "sub", "link.yaml"), Path: filepath.Join(dir, "sub", "link.yaml")},
+	}, discovered)
+	cleanup()
+}
@@ -0,0 +1,78 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package input
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/afero"
+
+	"github.com/enterprise-contract/ec-cli/internal/logging"
+	"github.com/enterprise-contract/ec-cli/internal/policy/source"
+	"github.com/enterprise-contract/ec-cli/internal/utils"
+)
+
+// Fetch downloads the input from the given url using the same mechanism used
+// for fetching policy sources, e.g. honoring the proxy and TLS configuration.
+// The returned files are labeled with the url. Each document of a
+// multi-document YAML file is returned as a separate File. Files without a
+// JSON or YAML extension are treated as YAML.
+func Fetch(ctx context.Context, url string) ([]File, error) {
+	fs := utils.FS(ctx)
+	redacted := logging.RedactURL(url)
+
+	dir, err := afero.TempDir(fs, afero.GetTempDir(fs, ""), "input-url-")
+	if err != nil {
+		return nil, err
+	}
+
+	// the trailing slash makes the downloader place the file within the
+	// directory
+	if _, err := source.Download(ctx, dir+"/", url, false); err != nil {
+		return nil, fmt.Errorf("fetching input from %s: %w", redacted, err)
+	}
+
+	entries, err := afero.ReadDir(fs, dir)
+	if err != nil {
+		return nil, err
+	}
+	if len(entries) != 1 || entries[0].IsDir() {
+		return nil, fmt.Errorf("fetching input from %s: expected a single file to be downloaded, found %d entries", redacted, len(entries))
+	}
+
+	path := filepath.Join(dir, entries[0].Name())
+	if !utils.HasJsonOrYamlExt(path) {
+		if err := fs.Rename(path, path+".yaml"); err != nil {
+			return nil, err
+		}
+		path += ".yaml"
+	}
+
+	files, err := splitDocuments(ctx, path, func() (string, error) { return dir, nil })
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range files {
+		files[i].Label = url + strings.TrimPrefix(files[i].Label, path)
+	}
+
+	return files, nil
+}
@@ -0,0 +1,120 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package input
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/enterprise-contract/go-gather/metadata"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/enterprise-contract/ec-cli/internal/policy/source"
+	"github.com/enterprise-contract/ec-cli/internal/utils"
+)
+
+type mockDownloader struct {
+	mock.Mock
+}
+
+func (m *mockDownloader) Download(_ context.Context, dest string, sourceUrl string, showMsg bool) (metadata.Metadata, error) {
+	args := m.Called(dest, sourceUrl, showMsg)
+
+	return nil, args.Error(0)
+}
+
+func TestFetch(t *testing.T) {
+	cases := []struct {
+		name     string
+		url      string
+		file     string
+		content  string
+		expected []string
+		err      string
+	}{
+		{
+			name:     "single document",
+			url:      "https://example.com/deploy.yaml",
+			file:     "deploy.yaml",
+			content:  "kind: Deployment\n",
+			expected: []string{"https://example.com/deploy.yaml"},
+		},
+		{
+			name:     "multiple documents",
+			url:      "https://example.com/all.yaml",
+			file:     "all.yaml",
+			content:  "kind: One\n---\nkind: Two\n",
+			expected: []string{"https://example.com/all.yaml[0]", "https://example.com/all.yaml[1]"},
+		},
+		{
+			name:     "no extension",
+			url:      "https://example.com/manifest",
+			file:     "manifest",
+			content:  `{"kind": "Deployment"}`,
+			expected: []string{"https://example.com/manifest"},
+		},
+		{
+			name: "download failure",
+			url:  "https://example.com/missing.yaml",
+			err:  "fetching input from https://example.com/REDACTED: response code error: 404",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			fs := afero.NewMemMapFs()
+			ctx := utils.WithFS(context.Background(), fs)
+
+			dl := mockDownloader{}
+			ctx = context.WithValue(ctx, source.DownloaderFuncKey, &dl)
+
+			call := dl.On("Download", mock.Anything, c.url, false)
+			if c.err != "" {
+				call.Return(errors.New("response code error: 404"))
+			} else {
+				call.Return(nil).Run(func(args mock.Arguments) {
+					dest := args.String(0)
+					require.NoError(t, afero.WriteFile(fs, filepath.Join(dest, c.file), []byte(c.content), 0600))
+				})
+			}
+
+			files, err := Fetch(ctx, c.url)
+			if c.err != "" {
+				assert.EqualError(t, err, c.err)
+				return
+			}
+			require.NoError(t, err)
+
+			labels := make([]string, 0, len(files))
+			for _, f := range files {
+				labels = append(labels, f.Label)
+				assert.True(t, utils.HasJsonOrYamlExt(f.Path))
+				exists, err := afero.Exists(fs, f.Path)
+				require.NoError(t, err)
+				assert.True(t, exists)
+			}
+			assert.Equal(t, c.expected, labels)
+		})
+	}
+}
@@ -21,6 +21,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"sync"
 	"time"
 
 	ecc "github.com/enterprise-contract/enterprise-contract-controller/api/v1alpha1"
@@ -36,6 +37,7 @@ type Input struct {
 	FilePath     string             `json:"filepath"`
 	Violations   []evaluator.Result `json:"violations"`
 	Warnings     []evaluator.Result `json:"warnings"`
+	Infos        []evaluator.Result `json:"infos,omitempty"`
 	Successes    []evaluator.Result `json:"successes"`
 	Success      bool               `json:"success"`
 	SuccessCount int                `json:"success-count"`
@@ -117,29 +119,57 @@ func NewReport(inputs []Input, policy policy.Policy, data any, policyInput [][]b
 	}, nil
 }
 
-// WriteAll writes the report to all the given targets.
+// WriteAll writes the report to all the given targets. The targets are
+// rendered concurrently and written in the order given so that the output to a
+// shared destination is predictable. A failure to render or write one target
+// does not prevent the others from being written.
 func (r Report) WriteAll(targets []string, p format.TargetParser) (allErrors error) {
 	if len(targets) == 0 {
 		targets = append(targets, JSON)
 	}
-	for _, targetName := range targets {
+
+	type rendered struct {
+		target *format.Target
+		data   []byte
+		err    error
+	}
+
+	results := make([]rendered, len(targets))
+
+	var wg sync.WaitGroup
+	for i, targetName := range targets {
 		target, err := p.Parse(targetName)
 		if err != nil {
-			allErrors = errors.Join(allErrors, err)
+			results[i].err = err
 			continue
 		}
+		results[i].target = target
 
-		data, err := r.toFormat(target.Format)
-		if err != nil {
-			allErrors = errors.Join(allErrors, err)
-			continue
-		}
+		wg.Add(1)
+		go func(i int, target *format.Target) {
+			defer wg.Done()
+
+			data, err := r.toFormat(target.Format)
+			if err != nil {
+				results[i].err = err
+				return
+			}
 
-		if !bytes.HasSuffix(data, []byte{'\n'}) {
-			data = append(data, "\n"...)
+			if !bytes.HasSuffix(data, []byte{'\n'}) {
+				data = append(data, "\n"...)
+			}
+			results[i].data = data
+		}(i, target)
+	}
+	wg.Wait()
+
+	for _, res := range results {
+		if res.err != nil {
+			allErrors = errors.Join(allErrors, res.err)
+			continue
 		}
 
-		if _, err := target.Write(data); err != nil {
+		if _, err := res.target.Write(res.data); err != nil {
 			allErrors = errors.Join(allErrors, err)
 		}
 	}
@@ -0,0 +1,36 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// Package kms registers the sigstore KMS providers, making keys kept in a KMS
+// usable wherever a key reference is accepted, i.e. as the public key of the
+// policy, the keys of the policy sources and the VSA signing key. The keys are
+// referenced by URIs of the form:
+//
+//	awskms://[ENDPOINT]/[ID/ALIAS/ARN]
+//	gcpkms://projects/[PROJECT]/locations/[LOCATION]/keyRings/[KEYRING]/cryptoKeys/[KEY]
+//	azurekms://[VAULT_NAME][VAULT_URI]/[KEY]
+//	hashivault://[KEY]
+//
+// The providers are configured with the environment variables of the
+// respective SDKs, e.g. AWS_REGION or VAULT_ADDR and VAULT_TOKEN.
+package kms
+
+import (
+	_ "github.com/sigstore/sigstore/pkg/signature/kms/aws"
+	_ "github.com/sigstore/sigstore/pkg/signature/kms/azure"
+	_ "github.com/sigstore/sigstore/pkg/signature/kms/gcp"
+	_ "github.com/sigstore/sigstore/pkg/signature/kms/hashivault"
+)
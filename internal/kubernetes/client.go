@@ -18,15 +18,19 @@ package kubernetes
 
 import (
 	"context"
+	"encoding/base64"
 	"errors"
+	"fmt"
 
 	ecc "github.com/enterprise-contract/enterprise-contract-controller/api/v1alpha1"
 	app "github.com/konflux-ci/application-api/api/v1alpha1"
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
@@ -39,8 +43,14 @@ const clientContextKey contextKey = "ec.kubernetes.client"
 type Client interface {
 	FetchEnterpriseContractPolicy(ctx context.Context, ref string) (*ecc.EnterpriseContractPolicy, error)
 	FetchSnapshot(ctx context.Context, ref string) (*app.Snapshot, error)
+	FetchFiles(ctx context.Context, ref string) (map[string][]byte, error)
 }
 
+var (
+	configMapsResource = schema.GroupVersionResource{Version: "v1", Resource: "configmaps"}
+	secretsResource    = schema.GroupVersionResource{Version: "v1", Resource: "secrets"}
+)
+
 type kubernetesClient struct {
 	client dynamic.Interface
 }
@@ -165,3 +175,88 @@ func (k *kubernetesClient) FetchSnapshot(ctx context.Context, ref string) (*app.
 
 	return &snapshot, nil
 }
+
+// FetchFiles gets the files held by the ConfigMap, or if there is no such
+// ConfigMap the Secret, from the given reference in a Kubernetes cluster. The
+// files are keyed by their names, i.e. the keys of the ConfigMap or Secret.
+//
+// The reference is expected to be in the format [<namespace>/]<name>. If it does not contain
+// a namespace, the current namespace is used.
+func (k *kubernetesClient) FetchFiles(ctx context.Context, ref string) (map[string][]byte, error) {
+	if len(ref) == 0 {
+		return nil, errors.New("ConfigMap or Secret reference cannot be empty")
+	}
+	log.Debugf("Raw ConfigMap or Secret reference: %q", ref)
+
+	name, err := NamespacedName(ref)
+	if err != nil {
+		return nil, err
+	}
+	log.Debugf("Parsed ConfigMap or Secret reference: %v", name)
+	if name.Namespace == "" {
+		return nil, errors.New("unable to determine namespace for ConfigMap or Secret")
+	}
+
+	files := map[string][]byte{}
+
+	configMap, err := k.client.Resource(configMapsResource).Namespace(name.Namespace).Get(ctx, name.Name, v1.GetOptions{})
+	switch {
+	case err == nil:
+		data, _, err := unstructured.NestedStringMap(configMap.UnstructuredContent(), "data")
+		if err != nil {
+			return nil, err
+		}
+		for file, content := range data {
+			files[file] = []byte(content)
+		}
+
+		binaryData, _, err := unstructured.NestedStringMap(configMap.UnstructuredContent(), "binaryData")
+		if err != nil {
+			return nil, err
+		}
+		if err := decodeFiles(files, binaryData); err != nil {
+			return nil, err
+		}
+
+		log.Debugf("ConfigMap successfully fetched from cluster: %v", name)
+
+		return files, nil
+	case !apierrors.IsNotFound(err):
+		log.Debugf("Failed to fetch the ConfigMap from cluster: %s", err)
+		return nil, err
+	}
+
+	secret, err := k.client.Resource(secretsResource).Namespace(name.Namespace).Get(ctx, name.Name, v1.GetOptions{})
+	if err != nil {
+		log.Debugf("Failed to fetch the Secret from cluster: %s", err)
+		if apierrors.IsNotFound(err) {
+			return nil, fmt.Errorf("no ConfigMap or Secret %q found in namespace %q", name.Name, name.Namespace)
+		}
+		return nil, err
+	}
+
+	data, _, err := unstructured.NestedStringMap(secret.UnstructuredContent(), "data")
+	if err != nil {
+		return nil, err
+	}
+	if err := decodeFiles(files, data); err != nil {
+		return nil, err
+	}
+
+	log.Debugf("Secret successfully fetched from cluster: %v", name)
+
+	return files, nil
+}
+
+// decodeFiles adds the base64 encoded files to the files
+func decodeFiles(files map[string][]byte, encoded map[string]string) error {
+	for file, content := range encoded {
+		decoded, err := base64.StdEncoding.DecodeString(content)
+		if err != nil {
+			return fmt.Errorf("unable to decode %s: %w", file, err)
+		}
+		files[file] = decoded
+	}
+
+	return nil
+}
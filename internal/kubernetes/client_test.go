@@ -28,6 +28,7 @@ import (
 	app "github.com/konflux-ci/application-api/api/v1alpha1"
 	"github.com/stretchr/testify/assert"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/dynamic/fake"
@@ -76,6 +77,33 @@ var testSnapshot = app.Snapshot{
 	},
 }
 
+var testConfigMap = &unstructured.Unstructured{Object: map[string]any{
+	"apiVersion": "v1",
+	"kind":       "ConfigMap",
+	"metadata": map[string]any{
+		"name":      "policy",
+		"namespace": "test",
+	},
+	"data": map[string]any{
+		"main.rego": "package main",
+	},
+	"binaryData": map[string]any{
+		"data.json": "eyJkYXRhIjogdHJ1ZX0=",
+	},
+}}
+
+var testSecret = &unstructured.Unstructured{Object: map[string]any{
+	"apiVersion": "v1",
+	"kind":       "Secret",
+	"metadata": map[string]any{
+		"name":      "private-policy",
+		"namespace": "test",
+	},
+	"data": map[string]any{
+		"private.rego": "cGFja2FnZSBwcml2YXRl",
+	},
+}}
+
 var testKubeconfig = []byte(`
 apiVersion: v1
 kind: Config
@@ -100,7 +128,7 @@ func init() {
 		panic(err)
 	}
 
-	fakeClient = fake.NewSimpleDynamicClient(scheme, &testECP, &testSnapshot)
+	fakeClient = fake.NewSimpleDynamicClient(scheme, &testECP, &testSnapshot, testConfigMap, testSecret)
 }
 
 func Test_FetchEnterpriseContractPolicy(t *testing.T) {
@@ -213,3 +241,56 @@ func Test_FetchSnapshot(t *testing.T) {
 		})
 	}
 }
+
+func Test_FetchFiles(t *testing.T) {
+	testCases := []struct {
+		name  string
+		ref   string
+		files map[string][]byte
+		err   string
+	}{
+		{
+			name: "fetch-config-map",
+			ref:  "test/policy",
+			files: map[string][]byte{
+				"main.rego": []byte("package main"),
+				"data.json": []byte(`{"data": true}`),
+			},
+		},
+		{
+			name: "fetch-secret-with-name-only",
+			ref:  "private-policy",
+			files: map[string][]byte{
+				"private.rego": []byte("package private"),
+			},
+		},
+		{
+			name: "fetch-not-found",
+			ref:  "missing/policy",
+			err:  `no ConfigMap or Secret "policy" found in namespace "missing"`,
+		},
+	}
+
+	for _, c := range testCases {
+		t.Run(c.name, func(t *testing.T) {
+			k := kubernetesClient{
+				client: fakeClient,
+			}
+
+			kubeconfigFile := path.Join(t.TempDir(), "KUBECONFIG")
+			err := os.WriteFile(kubeconfigFile, testKubeconfig, 0400)
+			assert.NoError(t, err)
+			t.Setenv("KUBECONFIG", kubeconfigFile)
+
+			got, err := k.FetchFiles(context.TODO(), c.ref)
+
+			if c.err == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorContains(t, err, c.err)
+			}
+
+			assert.Equal(t, c.files, got)
+		})
+	}
+}
@@ -22,6 +22,7 @@ import (
 	"os"
 	"path/filepath"
 	"runtime"
+	"slices"
 
 	"github.com/go-logr/logr"
 	log "github.com/sirupsen/logrus"
@@ -43,7 +44,11 @@ import (
 // We're expecting only one of the bool params to be set, but if
 // there are multiple set we'll accept it and the more verbose
 // option will take precedence.
-func InitLogging(verbose, quiet, debug, trace bool, logfile string) {
+//
+// The redaction mode controls how much detail of URLs is logged,
+// see RedactURL. Strict redaction is used unless "relaxed" is
+// given.
+func InitLogging(verbose, quiet, debug, trace bool, logfile, redaction string) {
 	var level log.Level
 	var v string
 	switch {
@@ -68,6 +73,8 @@ func InitLogging(verbose, quiet, debug, trace bool, logfile string) {
 
 	log.SetLevel(level)
 
+	setRedaction(redaction)
+
 	// The problem with klog is that it'll log to stdout/stderr, we want to
 	// control the logging and log via logrus instead. This accomplishes that
 	// but at the cost of loosing log levels, i.e. all klog messages will be
@@ -89,6 +96,10 @@ func InitLogging(verbose, quiet, debug, trace bool, logfile string) {
 			fmt.Fprintf(os.Stderr, "Unable to create log file %q, log lines will appear on standard error. Error was: %s\n", logfile, err.Error())
 		}
 	}
+
+	if redaction != "" && !slices.Contains(RedactionModes, redaction) {
+		log.Warnf("Unknown log redaction mode %q, using %q", redaction, RedactionStrict)
+	}
 }
 
 func setupDebugMode() {
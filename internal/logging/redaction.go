@@ -0,0 +1,107 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package logging
+
+import (
+	"net/url"
+	"strings"
+	"sync/atomic"
+)
+
+// Redaction modes control how much of a URL is retained when it is logged.
+// "strict" keeps only the scheme and the host, "relaxed" keeps the path and
+// the names of query parameters as well. Credentials and the values of query
+// parameters are never logged.
+const (
+	RedactionStrict  = "strict"
+	RedactionRelaxed = "relaxed"
+)
+
+var RedactionModes = []string{RedactionStrict, RedactionRelaxed}
+
+const redacted = "REDACTED"
+
+// relaxedRedaction is false by default so that strict redaction applies unless
+// explicitly relaxed.
+var relaxedRedaction atomic.Bool
+
+// setRedaction sets the redaction mode, any value other than "relaxed" results
+// in strict redaction.
+func setRedaction(mode string) {
+	relaxedRedaction.Store(mode == RedactionRelaxed)
+}
+
+// RedactURL returns the given URL, in any of the forms accepted for policy and
+// data sources, with the details removed according to the redaction mode.
+func RedactURL(s string) string {
+	return redactURL(s, relaxedRedaction.Load())
+}
+
+// RedactCredentials returns the given URL, in any of the forms accepted for
+// policy and data sources, with the user info and the values of the query
+// parameters, which may hold credentials, removed. This is the relaxed
+// redaction regardless of the redaction mode.
+func RedactCredentials(s string) string {
+	return redactURL(s, true)
+}
+
+func redactURL(s string, relaxed bool) string {
+	// go-getter URLs may force the getter using a prefix, e.g. git::
+	forced := ""
+	if i := strings.Index(s, "::"); i > 0 && !strings.Contains(s[:i], "/") {
+		forced, s = s[:i+2], s[i+2:]
+	}
+
+	// Source URLs are often given without a scheme, e.g. github.com/org/repo
+	schemeless := !strings.Contains(s, "://")
+	if schemeless {
+		s = "schemeless://" + s
+	}
+
+	u, err := url.Parse(s)
+	if err != nil {
+		// Not much can be done to tell the details apart
+		return forced + redacted
+	}
+
+	if u.User != nil {
+		u.User = url.User(redacted)
+	}
+	u.Fragment = ""
+	u.RawFragment = ""
+
+	if relaxed {
+		q := u.Query()
+		for k := range q {
+			q[k] = []string{redacted}
+		}
+		u.RawQuery = q.Encode()
+	} else {
+		if u.Path != "" {
+			u.Path = "/" + redacted
+		}
+		u.RawPath = ""
+		u.RawQuery = ""
+	}
+
+	redactedURL := u.String()
+	if schemeless {
+		redactedURL = strings.TrimPrefix(redactedURL, "schemeless://")
+	}
+
+	return forced + redactedURL
+}
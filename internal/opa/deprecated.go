@@ -0,0 +1,117 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package opa
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/open-policy-agent/opa/ast"
+	"github.com/spf13/afero"
+)
+
+// deprecatedData holds the references to data no longer provided by ec, and
+// what to use instead. The include and exclude criteria, and the collections,
+// are applied by ec itself, rules are not expected to check them.
+var deprecatedData = map[string]string{
+	"data.config.policy.include":     "the include criteria are applied by ec, remove the reference",
+	"data.config.policy.exclude":     "the exclude criteria are applied by ec, remove the reference",
+	"data.config.policy.collections": "the collections are applied by ec, remove the reference",
+}
+
+// Deprecation is a use of a deprecated rego built-in function, or of
+// deprecated data, within a policy.
+type Deprecation struct {
+	File    string `json:"file"`
+	Row     int    `json:"row"`
+	Message string `json:"message"`
+}
+
+func (d Deprecation) String() string {
+	return fmt.Sprintf("%s:%d: %s", d.File, d.Row, d.Message)
+}
+
+// CheckDeprecations finds all the rego files, tests excluded, and returns the
+// uses of deprecated built-in functions and deprecated data within them,
+// sorted by location.
+func CheckDeprecations(afs afero.Fs, dir string) ([]Deprecation, error) {
+	regoPaths, regoContents, err := regoFiles(afs, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	deprecations := []Deprecation{}
+	for i := range regoPaths {
+		mod, err := ast.ParseModule(regoPaths[i], regoContents[i])
+		if err != nil {
+			return nil, err
+		}
+
+		deprecations = append(deprecations, moduleDeprecations(mod)...)
+	}
+
+	sort.SliceStable(deprecations, func(i, j int) bool {
+		if deprecations[i].File != deprecations[j].File {
+			return deprecations[i].File < deprecations[j].File
+		}
+		return deprecations[i].Row < deprecations[j].Row
+	})
+
+	return deprecations, nil
+}
+
+func moduleDeprecations(mod *ast.Module) []Deprecation {
+	deprecations := []Deprecation{}
+	add := func(loc *ast.Location, message string) {
+		d := Deprecation{Message: message}
+		if loc != nil {
+			d.File = loc.File
+			d.Row = loc.Row
+		}
+		deprecations = append(deprecations, d)
+	}
+
+	ast.WalkExprs(mod, func(e *ast.Expr) bool {
+		// Built-in functions called within other terms, e.g. x := re_match(...),
+		// are found by the walk of the terms below
+		if !e.IsCall() {
+			return false
+		}
+		if b, ok := ast.BuiltinMap[e.Operator().String()]; ok && b.IsDeprecated() {
+			add(e.Location, fmt.Sprintf("use of the deprecated built-in function %s", b.Name))
+		}
+		return false
+	})
+
+	ast.WalkTerms(mod, func(t *ast.Term) bool {
+		switch v := t.Value.(type) {
+		case ast.Call:
+			if b, ok := ast.BuiltinMap[v[0].String()]; ok && b.IsDeprecated() {
+				add(t.Location, fmt.Sprintf("use of the deprecated built-in function %s", b.Name))
+			}
+		case ast.Ref:
+			for ref, replacement := range deprecatedData {
+				if v.HasPrefix(ast.MustParseRef(ref)) {
+					add(t.Location, fmt.Sprintf("use of the deprecated data %s, %s", ref, replacement))
+				}
+			}
+		}
+		return false
+	})
+
+	return deprecations
+}
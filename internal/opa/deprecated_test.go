@@ -0,0 +1,83 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package opa
+
+import (
+	"testing"
+
+	hd "github.com/MakeNowJust/heredoc"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckDeprecations(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	files := map[string]string{
+		"policy/spam.rego": hd.Doc(`
+			package spam
+
+			import future.keywords.contains
+			import future.keywords.if
+
+			deny contains msg if {
+				re_match("^spam", input.name)
+				msg := "spam"
+			}
+
+			warn contains msg if {
+				excluded := data.config.policy.exclude[_]
+				diff := set_diff({1, 2}, {excluded})
+				count(diff) > 0
+				msg := "bacon"
+			}
+		`),
+		"policy/more/bacon.rego": hd.Doc(`
+			package more.bacon
+
+			import future.keywords.contains
+			import future.keywords.if
+
+			deny contains msg if {
+				regex.match("^bacon", input.name)
+				msg := "bacon"
+			}
+		`),
+		"policy/spam_test.rego": hd.Doc(`
+			package spam
+
+			test_spam if {
+				re_match("^spam", "spam")
+			}
+		`),
+	}
+	for name, content := range files {
+		require.NoError(t, afero.WriteFile(fs, name, []byte(content), 0400))
+	}
+
+	deprecations, err := CheckDeprecations(fs, "policy")
+	require.NoError(t, err)
+
+	assert.Equal(t, []Deprecation{
+		{File: "spam.rego", Row: 7, Message: "use of the deprecated built-in function re_match"},
+		{File: "spam.rego", Row: 12, Message: "use of the deprecated data data.config.policy.exclude, the exclude criteria are applied by ec, remove the reference"},
+		{File: "spam.rego", Row: 13, Message: "use of the deprecated built-in function set_diff"},
+	}, deprecations)
+	assert.Equal(t, "spam.rego:7: use of the deprecated built-in function re_match", deprecations[0].String())
+}
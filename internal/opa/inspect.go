@@ -23,12 +23,15 @@ import (
 	"io/fs"
 	"path/filepath"
 	"regexp"
+	"sort"
 	"strings"
 
 	"github.com/open-policy-agent/opa/ast"
 	"github.com/open-policy-agent/opa/ast/json"
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/afero"
+
+	"github.com/enterprise-contract/ec-cli/internal/opa/rule"
 )
 
 func inspectSingle(path, module string) ([]*ast.AnnotationsRef, error) {
@@ -157,8 +160,9 @@ func checkRules(rules []*ast.AnnotationsRef) error {
 	return nil
 }
 
-// Finds all the rego files, inspects each one and returns a list the inspect data
-func InspectDir(afs afero.Fs, dir string) ([]*ast.AnnotationsRef, error) {
+// regoFiles returns the paths, relative to dir, and the contents of all the
+// rego files, tests excluded, found in dir
+func regoFiles(afs afero.Fs, dir string) ([]string, []string, error) {
 	regoPaths := []string{}
 	regoContents := []string{}
 
@@ -196,13 +200,23 @@ func InspectDir(afs afero.Fs, dir string) ([]*ast.AnnotationsRef, error) {
 		return nil
 	})
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	// Ensure that we have actual rules, and a directory without rego files.
 	if len(regoPaths) == 0 {
 		log.Debug("No rego files found after cloning policy url.")
-		return nil, errors.New("no rego files found in policy subdirectory")
+		return nil, nil, errors.New("no rego files found in policy subdirectory")
+	}
+
+	return regoPaths, regoContents, nil
+}
+
+// Finds all the rego files, inspects each one and returns a list the inspect data
+func InspectDir(afs afero.Fs, dir string) ([]*ast.AnnotationsRef, error) {
+	regoPaths, regoContents, err := regoFiles(afs, dir)
+	if err != nil {
+		return nil, err
 	}
 
 	// Inspect all rego files found
@@ -225,6 +239,64 @@ func InspectDir(afs afero.Fs, dir string) ([]*ast.AnnotationsRef, error) {
 	return result, nil
 }
 
+// CheckDuplicateCodes returns an error if two or more rules, possibly from
+// different sources, share the same code. Reports and filtering of results
+// rely on codes being unique. The error lists the locations of all definitions
+// of each duplicated code.
+func CheckDuplicateCodes(results map[string][]*ast.AnnotationsRef) error {
+	definitions := map[string][]string{}
+
+	sources := make([]string, 0, len(results))
+	for src := range results {
+		sources = append(sources, src)
+	}
+	sort.Strings(sources)
+
+	for _, src := range sources {
+		seen := map[*ast.Annotations]bool{}
+		for _, a := range results[src] {
+			// The chain of annotations for a rule includes the package and
+			// document scoped annotations, look only at rule annotations,
+			// and only once for rules with multiple bodies.
+			if a.GetRule() == nil || a.Annotations == nil || seen[a.Annotations] {
+				continue
+			}
+			seen[a.Annotations] = true
+
+			info := rule.RuleInfo(a)
+			if info.ShortName == "" {
+				continue
+			}
+
+			location := src
+			if a.Location != nil {
+				location = fmt.Sprintf("%s/%s:%d", src, a.Location.File, a.Location.Row)
+			}
+			definitions[info.Code] = append(definitions[info.Code], location)
+		}
+	}
+
+	codes := make([]string, 0, len(definitions))
+	for code, locations := range definitions {
+		if len(locations) > 1 {
+			codes = append(codes, code)
+		}
+	}
+
+	if len(codes) == 0 {
+		return nil
+	}
+
+	sort.Strings(codes)
+
+	var allErrors error
+	for _, code := range codes {
+		allErrors = errors.Join(allErrors, fmt.Errorf("duplicate rule code %q defined at: %s", code, strings.Join(definitions[code], ", ")))
+	}
+
+	return allErrors
+}
+
 // wrapperFs turns afero.Fs into fs.FS so it can be used in certain functions
 // provided by the fs package, e.g fs.WalkDir.
 type wrapperFs struct {
@@ -27,6 +27,7 @@ import (
 
 	hd "github.com/MakeNowJust/heredoc"
 	"github.com/gkampitakis/go-snaps/snaps"
+	"github.com/open-policy-agent/opa/ast"
 	"github.com/spf13/afero"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -238,3 +239,91 @@ func TestCheckRules(t *testing.T) {
 		})
 	}
 }
+
+func TestCheckDuplicateCodes(t *testing.T) {
+	inspect := func(files map[string]string) []*ast.AnnotationsRef {
+		paths := make([]string, 0, len(files))
+		modules := make([]string, 0, len(files))
+		for p, m := range files {
+			paths = append(paths, p)
+			modules = append(modules, m)
+		}
+		results, err := inspectMultiple(paths, modules)
+		require.NoError(t, err)
+		return results
+	}
+
+	spam := hd.Doc(`
+		package policy.release.spam
+
+		import rego.v1
+
+		# METADATA
+		# title: Enough spam
+		# custom:
+		#   short_name: enough
+		deny contains msg if {
+			input.spam_count > 42
+			msg := "plenty spam"
+		}
+
+		# METADATA
+		# title: Too little spam
+		# custom:
+		#   short_name: enough
+		deny contains msg if {
+			input.spam_count < 1
+			msg := "no spam"
+		}
+	`)
+
+	unique := hd.Doc(`
+		package policy.release.bacon
+
+		import rego.v1
+
+		# METADATA
+		# title: Enough bacon
+		# custom:
+		#   short_name: enough
+		deny contains msg if {
+			input.bacon_count > 42
+			msg := "plenty bacon"
+		}
+
+		# METADATA
+		# custom:
+		#   short_name: crispy
+		warn contains msg if {
+			input.crispy == false
+			msg := "soggy bacon"
+		}
+
+		warn contains msg if {
+			input.crispy == "meh"
+			msg := "meh bacon"
+		}
+	`)
+
+	t.Run("unique", func(t *testing.T) {
+		assert.NoError(t, CheckDuplicateCodes(map[string][]*ast.AnnotationsRef{
+			"source": inspect(map[string]string{"bacon.rego": unique}),
+		}))
+	})
+
+	t.Run("duplicate within source", func(t *testing.T) {
+		err := CheckDuplicateCodes(map[string][]*ast.AnnotationsRef{
+			"source": inspect(map[string]string{"spam.rego": spam, "bacon.rego": unique}),
+		})
+		assert.EqualError(t, err, `duplicate rule code "spam.enough" defined at: source/spam.rego:9, source/spam.rego:18`)
+	})
+
+	t.Run("duplicate across sources", func(t *testing.T) {
+		err := CheckDuplicateCodes(map[string][]*ast.AnnotationsRef{
+			"one": inspect(map[string]string{"bacon.rego": unique}),
+			"two": inspect(map[string]string{"more/bacon.rego": unique}),
+		})
+		assert.EqualError(t, err, `duplicate rule code "bacon.crispy" defined at: one/bacon.rego:17, two/more/bacon.rego:17`+"\n"+
+			`duplicate rule code "bacon.enough" defined at: one/bacon.rego:9, two/more/bacon.rego:9`)
+	})
+}
@@ -58,6 +58,24 @@ func effectiveOn(a *ast.AnnotationsRef) string {
 	return customAnnotationString(a, "effective_on")
 }
 
+// severity returns the severity level of the violations of the rule, e.g.
+// high or critical.
+func severity(a *ast.AnnotationsRef) string {
+	return customAnnotationString(a, "severity")
+}
+
+// verbose returns true if the rule is annotated as producing verbose messages,
+// i.e. messages that should be collapsed to a summary by default.
+func verbose(a *ast.AnnotationsRef) bool {
+	if a == nil || a.Annotations == nil || a.Annotations.Custom == nil {
+		return false
+	}
+	if value, ok := a.Annotations.Custom["verbose"].(bool); ok {
+		return value
+	}
+	return false
+}
+
 func solution(a *ast.AnnotationsRef) string {
 	return xrefRegExp.ReplaceAllString(customAnnotationString(a, "solution"), "$1")
 }
@@ -251,6 +269,29 @@ func dependsOn(a *ast.AnnotationsRef) []string {
 	}
 }
 
+func requiredRuleData(a *ast.AnnotationsRef) []string {
+	if a == nil {
+		return []string{}
+	}
+
+	required, ok := a.Annotations.Custom["required_rule_data"]
+
+	if !ok {
+		return []string{}
+	}
+
+	switch r := required.(type) {
+	case []any:
+		ret := make([]string, 0, len(r))
+		for _, v := range r {
+			ret = append(ret, fmt.Sprint(v))
+		}
+		return ret
+	default:
+		return []string{fmt.Sprint(r)}
+	}
+}
+
 type RuleKind string
 
 const (
@@ -269,9 +310,12 @@ type Info struct {
 	EffectiveOn      string
 	Kind             RuleKind
 	Package          string
+	RequiredRuleData []string
+	Severity         string
 	ShortName        string
 	Solution         string
 	Title            string
+	Verbose          bool
 }
 
 func RuleInfo(a *ast.AnnotationsRef) Info {
@@ -286,7 +330,10 @@ func RuleInfo(a *ast.AnnotationsRef) Info {
 		Solution:         solution(a),
 		Kind:             kind(a),
 		Package:          packageName(a),
+		RequiredRuleData: requiredRuleData(a),
+		Severity:         severity(a),
 		ShortName:        shortName(a),
 		Title:            title(a),
+		Verbose:          verbose(a),
 	}
 }
@@ -302,6 +302,16 @@ func TestEffectiveOn(t *testing.T) {
 	}
 }
 
+func TestSeverity(t *testing.T) {
+	assert.Equal(t, "", severity(nil))
+	assert.Equal(t, "critical", severity(annotationRef(heredoc.Doc(`
+		package a
+		# METADATA
+		# custom:
+		#   severity: critical
+		deny() { true }`))))
+}
+
 func TestSolution(t *testing.T) {
 	cases := []struct {
 		name       string
@@ -341,6 +351,56 @@ func TestSolution(t *testing.T) {
 	}
 }
 
+func TestVerbose(t *testing.T) {
+	cases := []struct {
+		name       string
+		annotation *ast.AnnotationsRef
+		expected   bool
+	}{
+		{
+			name:       "empty",
+			annotation: nil,
+			expected:   false,
+		},
+		{
+			name: "without verbose annotation",
+			annotation: annotationRef(heredoc.Doc(`
+				package a
+				# METADATA
+				# custom:
+				#   short_name: a
+				deny() { true }`)),
+			expected: false,
+		},
+		{
+			name: "with verbose annotation",
+			annotation: annotationRef(heredoc.Doc(`
+				package a
+				# METADATA
+				# custom:
+				#   verbose: true
+				deny() { true }`)),
+			expected: true,
+		},
+		{
+			name: "with non-boolean verbose annotation",
+			annotation: annotationRef(heredoc.Doc(`
+				package a
+				# METADATA
+				# custom:
+				#   verbose: yes please
+				deny() { true }`)),
+			expected: false,
+		},
+	}
+
+	for i, c := range cases {
+		t.Run(fmt.Sprintf("[%d] - %s", i, c.name), func(t *testing.T) {
+			assert.Equal(t, c.expected, verbose(c.annotation))
+		})
+	}
+}
+
 func TestCollections(t *testing.T) {
 	cases := []struct {
 		name       string
@@ -614,3 +674,52 @@ func TestDependsOn(t *testing.T) {
 		})
 	}
 }
+
+func TestRequiredRuleData(t *testing.T) {
+	cases := []struct {
+		name       string
+		annotation *ast.AnnotationsRef
+		expected   []string
+	}{
+		{
+			name:       "nothing",
+			annotation: nil,
+			expected:   []string{},
+		},
+		{
+			name: "no required_rule_data annotation",
+			annotation: annotationRef(heredoc.Doc(`
+				package a
+				deny() { true }`)),
+			expected: []string{},
+		},
+		{
+			name: "single required_rule_data annotation",
+			annotation: annotationRef(heredoc.Doc(`
+				package a
+				# METADATA
+				# custom:
+				#   required_rule_data: allowed_registries
+				deny() { true }`)),
+			expected: []string{"allowed_registries"},
+		},
+		{
+			name: "multiple required_rule_data annotation",
+			annotation: annotationRef(heredoc.Doc(`
+				package a
+				# METADATA
+				# custom:
+				#   required_rule_data:
+				#     - allowed_registries
+				#     - disallowed_packages
+				deny() { true }`)),
+			expected: []string{"allowed_registries", "disallowed_packages"},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			assert.Equal(t, c.expected, requiredRuleData(c.annotation))
+		})
+	}
+}
@@ -0,0 +1,237 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// Functions for running the rego unit tests of policy sources
+package opa
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/jstemmer/go-junit-report/v2/junit"
+	"github.com/open-policy-agent/opa/tester"
+	"sigs.k8s.io/yaml"
+)
+
+// Outcomes of a test
+const (
+	TestPass  = "pass"
+	TestFail  = "fail"
+	TestError = "error"
+	TestSkip  = "skip"
+)
+
+// Formats the test report can be written in
+const (
+	TestReportText  = "text"
+	TestReportJSON  = "json"
+	TestReportYAML  = "yaml"
+	TestReportJUnit = "junit"
+)
+
+var TestReportFormats = []string{TestReportText, TestReportJSON, TestReportYAML, TestReportJUnit}
+
+// TestResult is the result of a rego unit test of a policy source.
+type TestResult struct {
+	// Source is the URL of the policy source the test is in
+	Source  string `json:"source"`
+	Package string `json:"package"`
+	Name    string `json:"name"`
+	// File is the path of the file of the test within the policy source
+	File     string        `json:"file,omitempty"`
+	Row      int           `json:"row,omitempty"`
+	Outcome  string        `json:"outcome"`
+	Error    string        `json:"error,omitempty"`
+	Output   string        `json:"output,omitempty"`
+	Duration time.Duration `json:"duration"`
+}
+
+// RunTests runs the rego unit tests, i.e. the rules prefixed with test_, of
+// the policy source fetched into the directory, with the documents of the data
+// directories. Only the tests matching the filter regular expression are run
+// when a filter is given, as with opa test --run.
+func RunTests(ctx context.Context, sourceUrl, dir string, dataDirs []string, filter string) ([]TestResult, error) {
+	modules, store, err := tester.Load(append([]string{dir}, dataDirs...), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	ch, err := tester.NewRunner().
+		SetStore(store).
+		CapturePrintOutput(true).
+		RaiseBuiltinErrors(true).
+		Filter(filter).
+		Run(ctx, modules)
+	if err != nil {
+		return nil, err
+	}
+
+	var results []TestResult
+	for r := range ch {
+		result := TestResult{
+			Source:   sourceUrl,
+			Package:  strings.TrimPrefix(r.Package, "data."),
+			Name:     r.Name,
+			Output:   string(r.Output),
+			Duration: r.Duration,
+		}
+
+		if r.Location != nil {
+			result.File = r.Location.File
+			if rel, err := filepath.Rel(dir, r.Location.File); err == nil && filepath.IsLocal(rel) {
+				result.File = filepath.ToSlash(rel)
+			}
+			result.Row = r.Location.Row
+		}
+
+		switch {
+		case r.Skip:
+			result.Outcome = TestSkip
+		case r.Error != nil:
+			result.Outcome = TestError
+			result.Error = r.Error.Error()
+		case r.Fail:
+			result.Outcome = TestFail
+		default:
+			result.Outcome = TestPass
+		}
+
+		results = append(results, result)
+	}
+
+	return results, nil
+}
+
+// TestReport holds the results of the rego unit tests of the policy sources.
+type TestReport struct {
+	Success bool         `json:"success"`
+	Passed  int          `json:"passed"`
+	Failed  int          `json:"failed"`
+	Errors  int          `json:"errors"`
+	Skipped int          `json:"skipped"`
+	Results []TestResult `json:"results"`
+}
+
+// NewTestReport returns the report of the given results, successful if none
+// of the tests failed or errored.
+func NewTestReport(results []TestResult) TestReport {
+	r := TestReport{Results: results}
+	for _, result := range results {
+		switch result.Outcome {
+		case TestPass:
+			r.Passed++
+		case TestFail:
+			r.Failed++
+		case TestError:
+			r.Errors++
+		case TestSkip:
+			r.Skipped++
+		}
+	}
+	r.Success = r.Failed == 0 && r.Errors == 0
+
+	return r
+}
+
+// Format returns the report in the given format.
+func (r TestReport) Format(format string) ([]byte, error) {
+	switch format {
+	case TestReportText:
+		var b strings.Builder
+		r.writeText(&b)
+		return []byte(b.String()), nil
+	case TestReportJSON:
+		return json.Marshal(r)
+	case TestReportYAML:
+		return yaml.Marshal(r)
+	case TestReportJUnit:
+		return xml.Marshal(r.toJUnit())
+	default:
+		return nil, fmt.Errorf("%q is not a valid test report format, expected one of: %s", format, strings.Join(TestReportFormats, ", "))
+	}
+}
+
+func (r TestReport) writeText(w io.Writer) {
+	for _, result := range r.Results {
+		fmt.Fprintf(w, "%s: %s.%s (%s)\n", strings.ToUpper(result.Outcome), result.Package, result.Name, result.Source)
+		if result.File != "" {
+			fmt.Fprintf(w, "  %s:%d\n", result.File, result.Row)
+		}
+		if result.Error != "" {
+			fmt.Fprintf(w, "  %s\n", result.Error)
+		}
+		if result.Outcome != TestPass && result.Output != "" {
+			for _, line := range strings.Split(strings.TrimSpace(result.Output), "\n") {
+				fmt.Fprintf(w, "  %s\n", line)
+			}
+		}
+	}
+
+	total := len(r.Results)
+	fmt.Fprintf(w, "PASS: %d/%d\n", r.Passed, total)
+	if r.Failed > 0 {
+		fmt.Fprintf(w, "FAIL: %d/%d\n", r.Failed, total)
+	}
+	if r.Errors > 0 {
+		fmt.Fprintf(w, "ERROR: %d/%d\n", r.Errors, total)
+	}
+	if r.Skipped > 0 {
+		fmt.Fprintf(w, "SKIPPED: %d/%d\n", r.Skipped, total)
+	}
+}
+
+// toJUnit returns the report in JUnit XML format, with a test suite for each
+// of the policy sources
+func (r TestReport) toJUnit() junit.Testsuites {
+	report := junit.Testsuites{}
+
+	var suite *junit.Testsuite
+	for _, result := range r.Results {
+		if suite == nil || suite.Name != result.Source {
+			if suite != nil {
+				report.AddSuite(*suite)
+			}
+			suite = &junit.Testsuite{Name: result.Source}
+		}
+
+		c := junit.Testcase{
+			Name:      result.Name,
+			Classname: result.Package,
+			Time:      fmt.Sprintf("%.3f", result.Duration.Seconds()),
+		}
+		switch result.Outcome {
+		case TestFail:
+			c.Failure = &junit.Result{Message: "test failed", Data: result.Output}
+		case TestError:
+			c.Error = &junit.Result{Message: result.Error, Data: result.Output}
+		case TestSkip:
+			c.Skipped = &junit.Result{Message: "test skipped"}
+		}
+		suite.AddTestcase(c)
+	}
+
+	if suite != nil {
+		report.AddSuite(*suite)
+	}
+
+	return report
+}
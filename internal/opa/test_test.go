@@ -0,0 +1,130 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package opa
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testPolicy = `package release
+
+import rego.v1
+
+deny contains "not allowed" if {
+	not input.kind in data.allowed_kinds
+}
+`
+
+const testPolicyTests = `package release_test
+
+import rego.v1
+
+import data.release
+
+test_allowed if {
+	count(release.deny) == 0 with input as {"kind": "Pod"}
+}
+
+test_not_allowed if {
+	count(release.deny) == 0 with input as {"kind": "Secret"}
+}
+`
+
+func writeTestSources(t *testing.T) (string, string) {
+	policyDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(policyDir, "release.rego"), []byte(testPolicy), 0o600))
+	require.NoError(t, os.MkdirAll(filepath.Join(policyDir, "release"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(policyDir, "release", "release_test.rego"), []byte(testPolicyTests), 0o600))
+
+	dataDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dataDir, "data.json"), []byte(`{"allowed_kinds": ["Pod"]}`), 0o600))
+
+	return policyDir, dataDir
+}
+
+func TestRunTests(t *testing.T) {
+	policyDir, dataDir := writeTestSources(t)
+
+	results, err := RunTests(context.Background(), "git::example.com/policy", policyDir, []string{dataDir}, "")
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+
+	outcomes := map[string]TestResult{}
+	for _, r := range results {
+		outcomes[r.Name] = r
+	}
+
+	assert.Equal(t, TestPass, outcomes["test_allowed"].Outcome)
+	assert.Equal(t, TestFail, outcomes["test_not_allowed"].Outcome)
+	for _, r := range results {
+		assert.Equal(t, "git::example.com/policy", r.Source)
+		assert.Equal(t, "release_test", r.Package)
+		assert.Equal(t, "release/release_test.rego", r.File)
+	}
+
+	report := NewTestReport(results)
+	assert.False(t, report.Success)
+	assert.Equal(t, 1, report.Passed)
+	assert.Equal(t, 1, report.Failed)
+}
+
+func TestRunTestsFilter(t *testing.T) {
+	policyDir, dataDir := writeTestSources(t)
+
+	results, err := RunTests(context.Background(), "git::example.com/policy", policyDir, []string{dataDir}, "test_allowed")
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, "test_allowed", results[0].Name)
+	assert.True(t, NewTestReport(results).Success)
+}
+
+func TestTestReportFormat(t *testing.T) {
+	report := NewTestReport([]TestResult{
+		{Source: "a", Package: "release_test", Name: "test_ok", Outcome: TestPass},
+		{Source: "a", Package: "release_test", Name: "test_bad", File: "release_test.rego", Row: 7, Outcome: TestFail},
+		{Source: "b", Package: "other_test", Name: "test_error", Outcome: TestError, Error: "eval_conflict_error"},
+	})
+
+	text, err := report.Format(TestReportText)
+	require.NoError(t, err)
+	assert.Equal(t, `PASS: release_test.test_ok (a)
+FAIL: release_test.test_bad (a)
+  release_test.rego:7
+ERROR: other_test.test_error (b)
+  eval_conflict_error
+PASS: 1/3
+FAIL: 1/3
+ERROR: 1/3
+`, string(text))
+
+	junit, err := report.Format(TestReportJUnit)
+	require.NoError(t, err)
+	assert.Contains(t, string(junit), `<testsuites tests="3" errors="1" failures="1">`)
+	assert.Contains(t, string(junit), `<testsuite name="a" tests="2" failures="1" errors="0" id="0" time="">`)
+	assert.Contains(t, string(junit), `<testsuite name="b" tests="1" failures="0" errors="1" id="0" time="">`)
+
+	_, err = report.Format("table")
+	assert.EqualError(t, err, `"table" is not a valid test report format, expected one of: text, json, yaml, junit`)
+}
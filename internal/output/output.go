@@ -20,7 +20,9 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"slices"
 	"sort"
+	"time"
 
 	"github.com/sigstore/cosign/v2/pkg/cosign"
 	log "github.com/sirupsen/logrus"
@@ -39,6 +41,65 @@ const missingAttestationMessage = "No image attestations found matching the give
 	"Verify the correct public key was provided, " +
 	"and one or more attestations were created. Error: %s"
 
+// Possible ways of reporting an image for which none of the policy rules were
+// applicable.
+const (
+	NoApplicableRulesPass = "pass"
+	NoApplicableRulesWarn = "warn"
+	NoApplicableRulesFail = "fail"
+)
+
+var NoApplicableRulesModes = []string{
+	NoApplicableRulesPass,
+	NoApplicableRulesWarn,
+	NoApplicableRulesFail,
+}
+
+// Possible ways of handling an image without a verifiable signature.
+const (
+	UnsignedImageAllow = "allow"
+	UnsignedImageWarn  = "warn"
+	UnsignedImageDeny  = "deny"
+)
+
+var UnsignedImageModes = []string{
+	UnsignedImageAllow,
+	UnsignedImageWarn,
+	UnsignedImageDeny,
+}
+
+// Possible ways of reporting an image signature made before the image was
+// created, or for which that cannot be determined.
+const (
+	SignatureTimeIgnore = "ignore"
+	SignatureTimeWarn   = "warn"
+	SignatureTimeFail   = "fail"
+)
+
+var SignatureTimeModes = []string{
+	SignatureTimeIgnore,
+	SignatureTimeWarn,
+	SignatureTimeFail,
+}
+
+// Possible ways of handling an error evaluating an image, as opposed to the
+// image violating the policy.
+const (
+	// EvaluationErrorAbort stops the validation with the error, no report is
+	// written
+	EvaluationErrorAbort = "abort"
+	// EvaluationErrorFail reports the error for the image and fails it
+	EvaluationErrorFail = "fail"
+	// EvaluationErrorWarn reports the error for the image without failing it
+	EvaluationErrorWarn = "warn"
+)
+
+var EvaluationErrorModes = []string{
+	EvaluationErrorAbort,
+	EvaluationErrorFail,
+	EvaluationErrorWarn,
+}
+
 // VerificationStatus represents the status of a verification check.
 type VerificationStatus struct {
 	Passed bool              `json:"passed"`
@@ -88,6 +149,10 @@ type Output struct {
 	Data                      []evaluator.Data            `json:"-"`
 	Policy                    policy.Policy               `json:"-"`
 	PolicyInput               []byte                      `json:"-"`
+	Target                    string                      `json:"-"`
+	NoApplicableRules         bool                        `json:"-"`
+	UnsignedImage             string                      `json:"-"`
+	ImageCreated              *time.Time                  `json:"-"`
 }
 
 // SetImageAccessibleCheck sets the passed and result.message fields of the ImageAccessibleCheck to the given values.
@@ -189,6 +254,184 @@ func (o *Output) SetAttestationSyntaxCheckFromError(err error) {
 	o.AttestationSyntaxCheck.Result = result
 }
 
+// unsignedImagePermitted returns true if the image signature check failed and
+// the unsigned image mode permits such images.
+func (o Output) unsignedImagePermitted() bool {
+	return !o.ImageSignatureCheck.Passed && o.ImageSignatureCheck.Result != nil &&
+		(o.UnsignedImage == UnsignedImageAllow || o.UnsignedImage == UnsignedImageWarn)
+}
+
+// unsignedImageResult returns the result of the failed image signature check
+// labeled as permitted by the unsigned image mode.
+func (o Output) unsignedImageResult() evaluator.Result {
+	result := *o.ImageSignatureCheck.Result
+	result.Message = fmt.Sprintf("Image signature check failed, the image is permitted by the unsigned image mode %q: %s", o.UnsignedImage, result.Message)
+	return result
+}
+
+// NoApplicableRulesResult returns the result used to report that none of the
+// policy rules were applicable to the image.
+func (o Output) NoApplicableRulesResult() evaluator.Result {
+	result := evaluator.Result{
+		Message: "No policy rules were applicable to the image. Check the include and exclude criteria of the policy configuration.",
+		Metadata: map[string]interface{}{
+			"code":        "builtin.policy.no_applicable_rules",
+			"title":       "Policy rules are applicable",
+			"description": "At least one of the policy rules is applicable to the image.",
+		},
+	}
+	if !o.Detailed {
+		keepSomeMetadataSingle(result)
+	}
+	return result
+}
+
+// ImageDigestPinnedResult returns the result of checking that the image was
+// referenced by digest, rather than only by a mutable tag.
+func (o Output) ImageDigestPinnedResult(pinned bool) evaluator.Result {
+	message := "Pass"
+	if !pinned {
+		message = "Image reference is not pinned by digest. Reference the image by digest, e.g. registry.io/repository/image@sha256:<digest>, to prevent validating an image the tag may no longer point to."
+	}
+	result := evaluator.Result{
+		Message: message,
+		Metadata: map[string]interface{}{
+			"code":        "builtin.image.digest_pinned",
+			"title":       "Image reference is pinned by digest",
+			"description": "The image is referenced by digest and not only by a mutable tag.",
+		},
+	}
+	if !o.Detailed {
+		keepSomeMetadataSingle(result)
+	}
+	return result
+}
+
+// ApprovedDigestResult returns the result of checking that the digest of the
+// image is in the list of approved digests.
+func (o Output) ApprovedDigestResult(digest string, approved bool) evaluator.Result {
+	message := "Pass"
+	if !approved {
+		message = fmt.Sprintf("Image digest %q is not in the list of approved digests.", digest)
+	}
+	result := evaluator.Result{
+		Message: message,
+		Metadata: map[string]interface{}{
+			"code":        "builtin.image.approved_digest",
+			"title":       "Image digest is approved",
+			"description": "The digest of the image is in the list of approved digests.",
+		},
+	}
+	if !o.Detailed {
+		keepSomeMetadataSingle(result)
+	}
+	return result
+}
+
+// HasAttestationType returns true if the image has an attestation of the given
+// predicate type.
+func (o Output) HasAttestationType(predicateType string) bool {
+	return slices.ContainsFunc(o.Attestations, func(a attestation.Attestation) bool {
+		return a.PredicateType() == predicateType
+	})
+}
+
+// RequiredAttestationTypeResult returns the result of checking that the image
+// has an attestation of the given, required, predicate type.
+func (o Output) RequiredAttestationTypeResult(predicateType string, present bool) evaluator.Result {
+	message := "Pass"
+	if !present {
+		message = fmt.Sprintf("No attestation of the required predicate type %q was found.", predicateType)
+	}
+	result := evaluator.Result{
+		Message: message,
+		Metadata: map[string]interface{}{
+			"code":        "builtin.attestation.required_type",
+			"title":       "Attestation of the required type is present",
+			"description": "The image has an attestation of each of the predicate types required by the selected rule collections.",
+			"term":        predicateType,
+		},
+	}
+	if !o.Detailed {
+		keepSomeMetadataSingle(result)
+	}
+	return result
+}
+
+// AttestationSignersResults returns a violation for each attestation signed by
+// fewer than the given number of distinct signers. Signers are told apart by
+// the identity of their certificate.
+func (o Output) AttestationSignersResults(minSigners int) []evaluator.Result {
+	var results []evaluator.Result
+	for _, att := range o.Attestations {
+		signers := map[string]bool{}
+		for _, sig := range att.Signatures() {
+			signers[sig.Identity()] = true
+		}
+
+		if len(signers) >= minSigners {
+			continue
+		}
+
+		result := evaluator.Result{
+			Message: fmt.Sprintf("Attestation of predicate type %q is signed by %d distinct signer(s), at least %d are required.",
+				att.PredicateType(), len(signers), minSigners),
+			Metadata: map[string]interface{}{
+				"code":        "builtin.attestation.signer_count",
+				"title":       "Attestation has enough distinct signers",
+				"description": "The attestation is signed by at least the required number of distinct signers.",
+			},
+		}
+		if !o.Detailed {
+			keepSomeMetadataSingle(result)
+		}
+		results = append(results, result)
+	}
+
+	return results
+}
+
+// SignatureTimeResults returns a result for each image signature made before
+// the image was created, which suggests the signature was replayed or
+// backdated. When the creation time of the image, or the time of a signature,
+// is not known a result saying so is returned instead, as the order cannot be
+// verified.
+func (o Output) SignatureTimeResults() []evaluator.Result {
+	var messages []string
+	if o.ImageCreated == nil && len(o.Signatures) > 0 {
+		messages = append(messages, "Unable to verify that the image signatures were made after the image was created, the image config holds no creation time.")
+	} else {
+		for _, sig := range o.Signatures {
+			switch {
+			case sig.SignedAt == nil:
+				messages = append(messages, fmt.Sprintf("Unable to verify that the image signature of signer %q was made after the image was created, the signature has no timestamp from a transparency log entry or a certificate.",
+					sig.Identity()))
+			case sig.SignedAt.Before(*o.ImageCreated):
+				messages = append(messages, fmt.Sprintf("Image signature of signer %q was made at %s, before the image was created at %s. The signature may have been replayed or backdated.",
+					sig.Identity(), sig.SignedAt.Format(time.RFC3339), o.ImageCreated.Format(time.RFC3339)))
+			}
+		}
+	}
+
+	var results []evaluator.Result
+	for _, message := range messages {
+		result := evaluator.Result{
+			Message: message,
+			Metadata: map[string]interface{}{
+				"code":        "builtin.image.signature_time",
+				"title":       "Image signature was made after the image was created",
+				"description": "The time of each image signature is not before the creation time of the image.",
+			},
+		}
+		if !o.Detailed {
+			keepSomeMetadataSingle(result)
+		}
+		results = append(results, result)
+	}
+
+	return results
+}
+
 // SetPolicyCheck sets the PolicyCheck and ExitCode to the results and exit code of the Results
 func (o *Output) SetPolicyCheck(results []evaluator.Outcome) {
 	for r := range results {
@@ -221,8 +464,8 @@ func keepSomeMetadata(results []evaluator.Result) {
 // **Updated to include "term" by default as per the acceptance criteria.**
 func keepSomeMetadataSingle(result evaluator.Result) {
 	for key := range result.Metadata {
-		// Retain "code", "effective_on", and "term" keys
-		if key == "code" || key == "effective_on" || key == "term" {
+		// Retain "code", "effective_on", "term" and "verbose" keys
+		if key == "code" || key == "effective_on" || key == "term" || key == "verbose" {
 			continue
 		}
 		delete(result.Metadata, key)
@@ -241,7 +484,9 @@ func (o Output) addCheckResultsToViolations(violations []evaluator.Result) []eva
 // Violations aggregates and returns all violations.
 func (o Output) Violations() []evaluator.Result {
 	violations := make([]evaluator.Result, 0, 10)
-	violations = o.ImageSignatureCheck.addToViolations(violations)
+	if !o.unsignedImagePermitted() {
+		violations = o.ImageSignatureCheck.addToViolations(violations)
+	}
 	violations = o.ImageAccessibleCheck.addToViolations(violations)
 	violations = o.AttestationSignatureCheck.addToViolations(violations)
 	violations = o.AttestationSyntaxCheck.addToViolations(violations)
@@ -258,10 +503,24 @@ func (o Output) Warnings() []evaluator.Result {
 		warnings = append(warnings, result.Warnings...)
 	}
 
+	if o.unsignedImagePermitted() && o.UnsignedImage == UnsignedImageWarn {
+		warnings = append(warnings, o.unsignedImageResult())
+	}
+
 	warnings = sortResults(warnings)
 	return warnings
 }
 
+// Infos aggregates and returns the results reclassified as informational.
+func (o Output) Infos() []evaluator.Result {
+	var infos []evaluator.Result
+	for _, result := range o.PolicyCheck {
+		infos = append(infos, result.Infos...)
+	}
+
+	return sortResults(infos)
+}
+
 // Successes aggregates and returns all successes.
 func (o Output) Successes() []evaluator.Result {
 	successes := make([]evaluator.Result, 0, 10)
@@ -270,6 +529,9 @@ func (o Output) Successes() []evaluator.Result {
 	}
 
 	successes = o.ImageSignatureCheck.addToSuccesses(successes)
+	if o.unsignedImagePermitted() && o.UnsignedImage == UnsignedImageAllow {
+		successes = append(successes, o.unsignedImageResult())
+	}
 	successes = o.AttestationSignatureCheck.addToSuccesses(successes)
 	successes = o.AttestationSyntaxCheck.addToSuccesses(successes)
 
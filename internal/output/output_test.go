@@ -25,12 +25,15 @@ import (
 	"fmt"
 	"reflect"
 	"testing"
+	"time"
 	"unsafe"
 
+	"github.com/in-toto/in-toto-golang/in_toto"
 	"github.com/sigstore/cosign/v2/pkg/cosign"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/enterprise-contract/ec-cli/internal/attestation"
 	"github.com/enterprise-contract/ec-cli/internal/evaluator"
 	"github.com/enterprise-contract/ec-cli/internal/policy"
 	"github.com/enterprise-contract/ec-cli/internal/signature"
@@ -1041,3 +1044,209 @@ func TestSetAttestationSyntaxCheckFromError(t *testing.T) {
 		})
 	}
 }
+
+type signedAttestation struct {
+	signatures []signature.EntitySignature
+}
+
+func (a signedAttestation) Type() string {
+	return in_toto.StatementInTotoV01
+}
+
+func (a signedAttestation) PredicateType() string {
+	return "https://slsa.dev/provenance/v0.2"
+}
+
+func (a signedAttestation) Statement() []byte {
+	return []byte("{}")
+}
+
+func (a signedAttestation) Signatures() []signature.EntitySignature {
+	return a.signatures
+}
+
+func (a signedAttestation) Subject() []in_toto.Subject {
+	return nil
+}
+
+func TestAttestationSignersResults(t *testing.T) {
+	keyless := func(san, issuer string) signature.EntitySignature {
+		return signature.EntitySignature{
+			Certificate: "---CERTIFICATE---",
+			Metadata: map[string]string{
+				"Subject Alternative Name": san,
+				"Fulcio Issuer (V2)":       issuer,
+			},
+		}
+	}
+
+	alice := keyless("Email Addresses:alice@example.com", "https://accounts.example.com")
+	bob := keyless("Email Addresses:bob@example.com", "https://accounts.example.com")
+	bobElsewhere := keyless("Email Addresses:bob@example.com", "https://other.example.com")
+	key := signature.EntitySignature{Signature: "sig1"}
+	sameKey := signature.EntitySignature{Signature: "sig2"}
+
+	cases := []struct {
+		name       string
+		signatures []signature.EntitySignature
+		minSigners int
+		expected   []evaluator.Result
+	}{
+		{name: "enough distinct signers", signatures: []signature.EntitySignature{alice, bob}, minSigners: 2},
+		{name: "same identity different issuer", signatures: []signature.EntitySignature{bob, bobElsewhere}, minSigners: 2},
+		{name: "public key signatures are a single signer", signatures: []signature.EntitySignature{key, sameKey, alice}, minSigners: 2},
+		{
+			name:       "same signer twice",
+			signatures: []signature.EntitySignature{alice, alice},
+			minSigners: 2,
+			expected: []evaluator.Result{{
+				Message:  `Attestation of predicate type "https://slsa.dev/provenance/v0.2" is signed by 1 distinct signer(s), at least 2 are required.`,
+				Metadata: map[string]interface{}{"code": "builtin.attestation.signer_count"},
+			}},
+		},
+		{
+			name:       "public key signatures below threshold",
+			signatures: []signature.EntitySignature{key, sameKey},
+			minSigners: 2,
+			expected: []evaluator.Result{{
+				Message:  `Attestation of predicate type "https://slsa.dev/provenance/v0.2" is signed by 1 distinct signer(s), at least 2 are required.`,
+				Metadata: map[string]interface{}{"code": "builtin.attestation.signer_count"},
+			}},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			o := Output{Attestations: []attestation.Attestation{signedAttestation{signatures: c.signatures}}}
+			assert.Equal(t, c.expected, o.AttestationSignersResults(c.minSigners))
+		})
+	}
+}
+
+func TestSignatureTimeResults(t *testing.T) {
+	created := time.Date(2024, 5, 6, 7, 8, 9, 0, time.UTC)
+	before := created.Add(-time.Hour)
+	after := created.Add(time.Hour)
+
+	signed := func(keyID string, at *time.Time) signature.EntitySignature {
+		return signature.EntitySignature{KeyID: keyID, SignedAt: at}
+	}
+
+	cases := []struct {
+		name       string
+		created    *time.Time
+		signatures []signature.EntitySignature
+		expected   []evaluator.Result
+	}{
+		{name: "signed after creation", created: &created, signatures: []signature.EntitySignature{signed("a", &after)}},
+		{name: "signed at creation", created: &created, signatures: []signature.EntitySignature{signed("a", &created)}},
+		{name: "no signatures"},
+		{
+			name:       "signed before creation",
+			created:    &created,
+			signatures: []signature.EntitySignature{signed("a", &after), signed("b", &before)},
+			expected: []evaluator.Result{{
+				Message:  `Image signature of signer "key:b" was made at 2024-05-06T06:08:09Z, before the image was created at 2024-05-06T07:08:09Z. The signature may have been replayed or backdated.`,
+				Metadata: map[string]interface{}{"code": "builtin.image.signature_time"},
+			}},
+		},
+		{
+			name:       "unknown signature time",
+			created:    &created,
+			signatures: []signature.EntitySignature{signed("a", nil)},
+			expected: []evaluator.Result{{
+				Message:  `Unable to verify that the image signature of signer "key:a" was made after the image was created, the signature has no timestamp from a transparency log entry or a certificate.`,
+				Metadata: map[string]interface{}{"code": "builtin.image.signature_time"},
+			}},
+		},
+		{
+			name:       "unknown creation time",
+			signatures: []signature.EntitySignature{signed("a", &after), signed("b", &before)},
+			expected: []evaluator.Result{{
+				Message:  "Unable to verify that the image signatures were made after the image was created, the image config holds no creation time.",
+				Metadata: map[string]interface{}{"code": "builtin.image.signature_time"},
+			}},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			o := Output{ImageCreated: c.created, Signatures: c.signatures}
+			assert.Equal(t, c.expected, o.SignatureTimeResults())
+		})
+	}
+}
+
+func TestRequiredAttestationTypeResult(t *testing.T) {
+	o := Output{Attestations: []attestation.Attestation{signedAttestation{}}}
+
+	assert.True(t, o.HasAttestationType("https://slsa.dev/provenance/v0.2"))
+	assert.False(t, o.HasAttestationType("https://spdx.dev/Document"))
+
+	assert.Equal(t, evaluator.Result{
+		Message: "Pass",
+		Metadata: map[string]interface{}{
+			"code": "builtin.attestation.required_type",
+			"term": "https://slsa.dev/provenance/v0.2",
+		},
+	}, o.RequiredAttestationTypeResult("https://slsa.dev/provenance/v0.2", true))
+
+	o.Detailed = true
+	assert.Equal(t, evaluator.Result{
+		Message: `No attestation of the required predicate type "https://spdx.dev/Document" was found.`,
+		Metadata: map[string]interface{}{
+			"code":        "builtin.attestation.required_type",
+			"title":       "Attestation of the required type is present",
+			"description": "The image has an attestation of each of the predicate types required by the selected rule collections.",
+			"term":        "https://spdx.dev/Document",
+		},
+	}, o.RequiredAttestationTypeResult("https://spdx.dev/Document", false))
+}
+
+func TestUnsignedImage(t *testing.T) {
+	failed := VerificationStatus{
+		Passed: false,
+		Result: &evaluator.Result{Message: "no signatures found", Metadata: map[string]any{"code": "builtin.image.signature_check"}},
+	}
+	permitted := func(mode string) []evaluator.Result {
+		return []evaluator.Result{{
+			Message:  fmt.Sprintf("Image signature check failed, the image is permitted by the unsigned image mode %q: no signatures found", mode),
+			Metadata: map[string]any{"code": "builtin.image.signature_check"},
+		}}
+	}
+
+	cases := []struct {
+		mode       string
+		violations []evaluator.Result
+		warnings   []evaluator.Result
+		successes  []evaluator.Result
+	}{
+		{mode: "", violations: []evaluator.Result{*failed.Result}},
+		{mode: UnsignedImageDeny, violations: []evaluator.Result{*failed.Result}},
+		{mode: UnsignedImageWarn, warnings: permitted(UnsignedImageWarn)},
+		{mode: UnsignedImageAllow, successes: permitted(UnsignedImageAllow)},
+	}
+
+	for _, c := range cases {
+		t.Run(c.mode, func(t *testing.T) {
+			o := Output{ImageSignatureCheck: failed, UnsignedImage: c.mode}
+
+			assert.Equal(t, nilIfEmpty(c.violations), nilIfEmpty(o.Violations()))
+			assert.Equal(t, nilIfEmpty(c.warnings), nilIfEmpty(o.Warnings()))
+			assert.Equal(t, nilIfEmpty(c.successes), nilIfEmpty(o.Successes()))
+		})
+	}
+
+	t.Run("signed image", func(t *testing.T) {
+		o := Output{ImageSignatureCheck: VerificationStatus{Passed: true}, UnsignedImage: UnsignedImageWarn}
+		assert.Empty(t, o.Violations())
+		assert.Empty(t, o.Warnings())
+	})
+}
+
+func nilIfEmpty(results []evaluator.Result) []evaluator.Result {
+	if len(results) == 0 {
+		return nil
+	}
+	return results
+}
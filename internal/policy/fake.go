@@ -27,6 +27,7 @@ import (
 type FakeKubernetesClient struct {
 	Policy     ecc.EnterpriseContractPolicySpec
 	Snapshot   app.SnapshotSpec
+	Files      map[string][]byte
 	FetchError bool
 }
 
@@ -43,3 +44,10 @@ func (c *FakeKubernetesClient) FetchSnapshot(ctx context.Context, ref string) (*
 	}
 	return &app.Snapshot{Spec: c.Snapshot}, nil
 }
+
+func (c *FakeKubernetesClient) FetchFiles(ctx context.Context, ref string) (map[string][]byte, error) {
+	if c.FetchError {
+		return nil, errors.New("no fetching for you")
+	}
+	return c.Files, nil
+}
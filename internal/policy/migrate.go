@@ -0,0 +1,85 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package policy
+
+import (
+	"fmt"
+	"slices"
+
+	ecc "github.com/enterprise-contract/enterprise-contract-controller/api/v1alpha1"
+)
+
+// Migrate returns a copy of the given policy spec with the deprecated fields
+// replaced by their current equivalents, along with a description of each
+// change made. The top level configuration, which applies only to the sources
+// without any include or exclude criteria of their own, is moved to the config
+// of each such source. The deprecated collections become include entries with
+// the "@" prefix.
+func Migrate(spec ecc.EnterpriseContractPolicySpec) (ecc.EnterpriseContractPolicySpec, []string) {
+	migrated := *spec.DeepCopy()
+
+	cfg := migrated.Configuration
+	if cfg == nil {
+		return migrated, nil
+	}
+
+	var changes []string
+
+	include := slices.Clone(cfg.Include)
+	for _, collection := range cfg.Collections {
+		include = append(include, fmt.Sprintf("@%s", collection))
+		changes = append(changes, fmt.Sprintf("collection %q converted to include %q", collection, "@"+collection))
+	}
+	exclude := slices.Clone(cfg.Exclude)
+
+	migrated.Configuration = nil
+	changes = append(changes, "removed the deprecated configuration")
+
+	if len(include) == 0 && len(exclude) == 0 {
+		return migrated, changes
+	}
+
+	if len(migrated.Sources) == 0 {
+		changes = append(changes, "no sources to move the include and exclude criteria of the configuration to, they are dropped")
+		return migrated, changes
+	}
+
+	for i := range migrated.Sources {
+		src := &migrated.Sources[i]
+		name := src.Name
+		if name == "" {
+			name = fmt.Sprintf("#%d", i)
+		}
+
+		if src.Config != nil && (len(src.Config.Include) > 0 || len(src.Config.Exclude) > 0) {
+			changes = append(changes, fmt.Sprintf("source %s has its own config, the configuration did not apply to it and is not moved", name))
+			continue
+		}
+
+		if src.VolatileConfig != nil && (len(src.VolatileConfig.Include) > 0 || len(src.VolatileConfig.Exclude) > 0) {
+			changes = append(changes, fmt.Sprintf("source %s has volatile config, the configuration applied to it only while none of the volatile criteria were in effect, it now applies at all times", name))
+		}
+
+		src.Config = &ecc.SourceConfig{
+			Include: slices.Clone(include),
+			Exclude: slices.Clone(exclude),
+		}
+		changes = append(changes, fmt.Sprintf("moved the include and exclude criteria of the configuration to the config of source %s", name))
+	}
+
+	return migrated, changes
+}
@@ -0,0 +1,119 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package policy
+
+import (
+	"testing"
+
+	ecc "github.com/enterprise-contract/enterprise-contract-controller/api/v1alpha1"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMigrate(t *testing.T) {
+	cases := []struct {
+		name     string
+		spec     ecc.EnterpriseContractPolicySpec
+		expected ecc.EnterpriseContractPolicySpec
+		changes  []string
+	}{
+		{
+			name: "nothing to migrate",
+			spec: ecc.EnterpriseContractPolicySpec{
+				Sources: []ecc.Source{{Policy: []string{"policy"}, Config: &ecc.SourceConfig{Include: []string{"@minimal"}}}},
+			},
+			expected: ecc.EnterpriseContractPolicySpec{
+				Sources: []ecc.Source{{Policy: []string{"policy"}, Config: &ecc.SourceConfig{Include: []string{"@minimal"}}}},
+			},
+		},
+		{
+			name: "configuration and collections",
+			spec: ecc.EnterpriseContractPolicySpec{
+				Sources: []ecc.Source{
+					{Name: "release", Policy: []string{"release"}},
+					{Policy: []string{"other"}, Config: &ecc.SourceConfig{Exclude: []string{"spam"}}},
+				},
+				Configuration: &ecc.EnterpriseContractPolicyConfiguration{
+					Include:     []string{"bacon"},
+					Exclude:     []string{"eggs"},
+					Collections: []string{"minimal"},
+				},
+			},
+			expected: ecc.EnterpriseContractPolicySpec{
+				Sources: []ecc.Source{
+					{Name: "release", Policy: []string{"release"}, Config: &ecc.SourceConfig{
+						Include: []string{"bacon", "@minimal"},
+						Exclude: []string{"eggs"},
+					}},
+					{Policy: []string{"other"}, Config: &ecc.SourceConfig{Exclude: []string{"spam"}}},
+				},
+			},
+			changes: []string{
+				`collection "minimal" converted to include "@minimal"`,
+				"removed the deprecated configuration",
+				"moved the include and exclude criteria of the configuration to the config of source release",
+				"source #1 has its own config, the configuration did not apply to it and is not moved",
+			},
+		},
+		{
+			name: "volatile config",
+			spec: ecc.EnterpriseContractPolicySpec{
+				Sources: []ecc.Source{
+					{Policy: []string{"policy"}, VolatileConfig: &ecc.VolatileSourceConfig{Exclude: []ecc.VolatileCriteria{{Value: "spam"}}}},
+				},
+				Configuration: &ecc.EnterpriseContractPolicyConfiguration{Exclude: []string{"eggs"}},
+			},
+			expected: ecc.EnterpriseContractPolicySpec{
+				Sources: []ecc.Source{
+					{
+						Policy:         []string{"policy"},
+						Config:         &ecc.SourceConfig{Exclude: []string{"eggs"}},
+						VolatileConfig: &ecc.VolatileSourceConfig{Exclude: []ecc.VolatileCriteria{{Value: "spam"}}},
+					},
+				},
+			},
+			changes: []string{
+				"removed the deprecated configuration",
+				"source #0 has volatile config, the configuration applied to it only while none of the volatile criteria were in effect, it now applies at all times",
+				"moved the include and exclude criteria of the configuration to the config of source #0",
+			},
+		},
+		{
+			name: "empty configuration",
+			spec: ecc.EnterpriseContractPolicySpec{
+				Sources:       []ecc.Source{{Policy: []string{"policy"}}},
+				Configuration: &ecc.EnterpriseContractPolicyConfiguration{},
+			},
+			expected: ecc.EnterpriseContractPolicySpec{
+				Sources: []ecc.Source{{Policy: []string{"policy"}}},
+			},
+			changes: []string{"removed the deprecated configuration"},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			original := *c.spec.DeepCopy()
+
+			migrated, changes := Migrate(c.spec)
+			assert.Equal(t, c.expected, migrated)
+			assert.Equal(t, c.changes, changes)
+			assert.Equal(t, original, c.spec, "the given spec must not be modified")
+		})
+	}
+}
@@ -85,6 +85,7 @@ type Policy interface {
 	AttestationTime(time.Time)
 	Identity() cosign.Identity
 	Keyless() bool
+	RequireRekor() bool
 	SigstoreOpts() (SigstoreOpts, error)
 }
 
@@ -96,6 +97,7 @@ type policy struct {
 	attestationTime *time.Time
 	identity        cosign.Identity
 	ignoreRekor     bool
+	requireRekor    bool
 }
 
 // PublicKeyPEM returns the PublicKey in PEM format.
@@ -134,6 +136,12 @@ func (p *policy) Keyless() bool {
 	return p.PublicKey == ""
 }
 
+// RequireRekor returns whether or not each signature and attestation must be
+// looked up in the Rekor transparency log, verifying its inclusion proof.
+func (p *policy) RequireRekor() bool {
+	return p.requireRekor
+}
+
 func (p *policy) SigstoreOpts() (SigstoreOpts, error) {
 	pk, err := p.PublicKeyPEM()
 	if err != nil {
@@ -160,6 +168,7 @@ type Options struct {
 	PolicyRef     string
 	PublicKey     string
 	RekorURL      string
+	RequireRekor  bool
 }
 
 // NewOfflinePolicy construct and return a new instance of Policy that is used
@@ -248,6 +257,11 @@ func NewPolicy(ctx context.Context, opts Options) (Policy, error) {
 	}
 
 	p.ignoreRekor = opts.IgnoreRekor
+	p.requireRekor = opts.RequireRekor
+
+	if p.requireRekor && p.RekorUrl == "" {
+		return nil, errors.New("a Rekor URL is required to look up signatures in the transparency log, set the rekorUrl in the policy or use --rekor-url")
+	}
 
 	if opts.PublicKey != "" && opts.PublicKey != p.PublicKey {
 		p.PublicKey = opts.PublicKey
@@ -418,6 +432,16 @@ func parseEffectiveTime(choosenTime string) (*time.Time, error) {
 	}
 }
 
+// trustedMaterialError wraps the error encountered while loading trusted
+// material from the TUF root, e.g. if the root is misconfigured or expired.
+func trustedMaterialError(what string, err error) error {
+	root := os.Getenv("TUF_ROOT")
+	if root == "" {
+		root = "the default location"
+	}
+	return fmt.Errorf("unable to load %s from the TUF root in %s, check that the TUF root is initialized and not expired, see ec sigstore initialize and ec sigstore refresh: %w", what, root, err)
+}
+
 // checkOpts returns an instance based on attributes of the Policy.
 func checkOpts(ctx context.Context, p *policy) (*cosign.CheckOpts, error) {
 	var err error
@@ -435,15 +459,15 @@ func checkOpts(ctx context.Context, p *policy) (*cosign.CheckOpts, error) {
 
 		// Get Fulcio certificates
 		if opts.RootCerts, err = fulcio.GetRoots(); err != nil {
-			return nil, err
+			return nil, trustedMaterialError("Fulcio root certificates", err)
 		}
 		if opts.IntermediateCerts, err = fulcio.GetIntermediates(); err != nil {
-			return nil, err
+			return nil, trustedMaterialError("Fulcio intermediate certificates", err)
 		}
 
 		// Get Certificate Transparency Log public keys
 		if opts.CTLogPubKeys, err = cosign.GetCTLogPubs(ctx); err != nil {
-			return nil, err
+			return nil, trustedMaterialError("Certificate Transparency Log public keys", err)
 		}
 		log.Debug("Retrieved Rekor public keys")
 	}
@@ -471,7 +495,7 @@ func checkOpts(ctx context.Context, p *policy) (*cosign.CheckOpts, error) {
 		}
 
 		if opts.RekorPubKeys, err = cosign.GetRekorPubs(ctx); err != nil {
-			return nil, err
+			return nil, trustedMaterialError("Rekor public keys", err)
 		}
 		log.Debug("Retrieved Rekor public keys")
 	}
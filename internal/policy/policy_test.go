@@ -267,6 +267,7 @@ func TestCheckOpts(t *testing.T) {
 		policyRef       string
 		rekorUrl        string
 		ignoreRekor     bool
+		requireRekor    bool
 		publicKey       string
 		remotePublicKey string
 		identity        cosign.Identity
@@ -297,6 +298,18 @@ func TestCheckOpts(t *testing.T) {
 			ignoreRekor: true,
 			publicKey:   utils.TestPublicKey,
 		},
+		{
+			name:         "require rekor",
+			rekorUrl:     utils.TestRekorURL,
+			requireRekor: true,
+			publicKey:    utils.TestPublicKey,
+		},
+		{
+			name:         "require rekor without rekor URL",
+			requireRekor: true,
+			publicKey:    utils.TestPublicKey,
+			err:          "a Rekor URL is required to look up signatures in the transparency log",
+		},
 		{
 			name:          "keyless",
 			rekorUrl:      utils.TestRekorURL,
@@ -390,6 +403,7 @@ func TestCheckOpts(t *testing.T) {
 				PolicyRef:     c.policyRef,
 				RekorURL:      c.rekorUrl,
 				IgnoreRekor:   c.ignoreRekor,
+				RequireRekor:  c.requireRekor,
 				PublicKey:     c.publicKey,
 				EffectiveTime: Now,
 				Identity:      c.identity,
@@ -403,6 +417,7 @@ func TestCheckOpts(t *testing.T) {
 
 			opts, err := p.CheckOpts()
 			assert.NoError(t, err)
+			assert.Equal(t, c.requireRekor, p.RequireRekor())
 
 			if c.ignoreRekor {
 				assert.Nil(t, opts.RekorPubKeys)
@@ -0,0 +1,301 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package source
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/enterprise-contract/go-gather/metadata"
+	fileMetadata "github.com/enterprise-contract/go-gather/metadata/file"
+	getter "github.com/hashicorp/go-getter"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/enterprise-contract/ec-cli/internal/logging"
+	"github.com/enterprise-contract/ec-cli/internal/utils"
+)
+
+// azureStorageVersion is the version of the Azure Blob Storage API used, it
+// needs to be at least 2017-11-09 for OAuth tokens to be accepted
+const azureStorageVersion = "2023-11-03"
+
+// azureBlobEndpoint returns the endpoint of the blob service of the Azure
+// storage account
+var azureBlobEndpoint = func(account string) string {
+	return fmt.Sprintf("https://%s.blob.core.windows.net", account)
+}
+
+// azureCredential returns the credential used to access Azure Blob Storage,
+// found by the default Azure credential chain, i.e. environment variables,
+// workload identity, managed identity or the Azure CLI
+var azureCredential = func() (azcore.TokenCredential, error) {
+	return azidentity.NewDefaultAzureCredential(nil)
+}
+
+// bucketSourceURL returns the go-getter URL for the source in a cloud object
+// storage bucket, for sources given as s3://<bucket>/<path> or
+// gs://<bucket>/<path>. The region of a S3 bucket is given by the region
+// query parameter, or taken from the AWS_REGION or AWS_DEFAULT_REGION
+// environment variables, us-east-1 is used by default.
+func bucketSourceURL(sourceUrl string) (string, bool) {
+	u, err := url.Parse(sourceUrl)
+	if err != nil || u.Host == "" {
+		return "", false
+	}
+
+	switch u.Scheme {
+	case "s3":
+		q := u.Query()
+		region := q.Get("region")
+		q.Del("region")
+		if region == "" {
+			region = os.Getenv("AWS_REGION")
+		}
+		if region == "" {
+			region = os.Getenv("AWS_DEFAULT_REGION")
+		}
+		if region == "" {
+			region = "us-east-1"
+		}
+
+		s := fmt.Sprintf("s3::https://s3-%s.amazonaws.com/%s%s", region, u.Host, u.EscapedPath())
+		if len(q) > 0 {
+			s += "?" + q.Encode()
+		}
+		return s, true
+	case "gs":
+		s := fmt.Sprintf("gcs::https://www.googleapis.com/storage/v1/%s%s", u.Host, u.EscapedPath())
+		if u.RawQuery != "" {
+			s += "?" + u.RawQuery
+		}
+		return s, true
+	}
+
+	return "", false
+}
+
+// fetchBucket downloads the object, or all of the objects with the path as
+// prefix, from a S3 or GCS bucket using the credentials found by the default
+// credential chain of the cloud provider.
+func fetchBucket(ctx context.Context, dest, sourceUrl, getterUrl string, showMsg bool) (metadata.Metadata, error) {
+	msg := fmt.Sprintf("Downloading %s to %s", logging.RedactURL(sourceUrl), dest)
+	log.Debug(msg)
+	if showMsg {
+		fmt.Println(msg)
+	}
+
+	client := &getter.Client{
+		Ctx:  ctx,
+		Src:  getterUrl,
+		Dst:  dest,
+		Mode: getter.ClientModeAny,
+		Getters: map[string]getter.Getter{
+			"s3":  new(getter.S3Getter),
+			"gcs": new(getter.GCSGetter),
+		},
+	}
+
+	if err := client.Get(); err != nil {
+		return nil, fmt.Errorf("unable to fetch %s: %w", logging.RedactURL(sourceUrl), err)
+	}
+
+	return &fileMetadata.FileMetadata{Path: dest}, nil
+}
+
+// azureBlobSource returns the storage account, container and path of a source
+// given as azblob://<container>/<path>, the storage account is taken from the
+// AZURE_STORAGE_ACCOUNT environment variable.
+func azureBlobSource(sourceUrl string) (account, container, blob string, ok bool) {
+	u, err := url.Parse(sourceUrl)
+	if err != nil || u.Scheme != "azblob" || u.Host == "" {
+		return "", "", "", false
+	}
+
+	return os.Getenv("AZURE_STORAGE_ACCOUNT"), u.Host, strings.Trim(u.Path, "/"), true
+}
+
+// azureBlobList is the response of the List Blobs operation
+type azureBlobList struct {
+	Blobs struct {
+		Blob []struct {
+			Name string `xml:"Name"`
+		} `xml:"Blob"`
+	} `xml:"Blobs"`
+	NextMarker string `xml:"NextMarker"`
+}
+
+// fetchAzureBlob downloads the blob, or all of the blobs with the path as
+// prefix, from an Azure Blob Storage container using the credential found by
+// the default Azure credential chain.
+func fetchAzureBlob(ctx context.Context, dest, sourceUrl, account, container, blob string, showMsg bool) (metadata.Metadata, error) {
+	if account == "" {
+		return nil, fmt.Errorf("unable to fetch %s: the storage account must be set in the AZURE_STORAGE_ACCOUNT environment variable", logging.RedactURL(sourceUrl))
+	}
+
+	msg := fmt.Sprintf("Downloading %s to %s", logging.RedactURL(sourceUrl), dest)
+	log.Debug(msg)
+	if showMsg {
+		fmt.Println(msg)
+	}
+
+	cred, err := azureCredential()
+	if err != nil {
+		return nil, fmt.Errorf("unable to fetch %s: %w", logging.RedactURL(sourceUrl), err)
+	}
+
+	token, err := cred.GetToken(ctx, policy.TokenRequestOptions{Scopes: []string{"https://storage.azure.com/.default"}})
+	if err != nil {
+		return nil, fmt.Errorf("unable to fetch %s: %w", logging.RedactURL(sourceUrl), err)
+	}
+
+	c := azureBlobClient{
+		endpoint:  azureBlobEndpoint(account),
+		container: container,
+		token:     token.Token,
+	}
+
+	names, err := c.list(ctx, blob)
+	if err != nil {
+		return nil, fmt.Errorf("unable to fetch %s: %w", logging.RedactURL(sourceUrl), err)
+	}
+
+	files := map[string]string{}
+	for _, name := range names {
+		rel := strings.TrimPrefix(name, blob+"/")
+		if blob == "" {
+			rel = name
+		}
+		if !filepath.IsLocal(rel) {
+			return nil, fmt.Errorf("unable to fetch %s: the blob name %q is not a local path", logging.RedactURL(sourceUrl), name)
+		}
+		files[name] = filepath.Join(dest, rel)
+	}
+	if len(files) == 0 {
+		// Not a prefix, the path is a single blob
+		files[blob] = filepath.Join(dest, path.Base(blob))
+	}
+
+	for name, target := range files {
+		if err := c.download(ctx, name, target); err != nil {
+			return nil, fmt.Errorf("unable to fetch %s: %w", logging.RedactURL(sourceUrl), err)
+		}
+	}
+
+	return &fileMetadata.FileMetadata{Path: dest}, nil
+}
+
+type azureBlobClient struct {
+	endpoint  string
+	container string
+	token     string
+}
+
+func (c azureBlobClient) do(ctx context.Context, u string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+c.token)
+	req.Header.Set("x-ms-version", azureStorageVersion)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("unexpected response: %s", resp.Status)
+	}
+
+	return resp, nil
+}
+
+// list returns the names of the blobs within the path as a prefix
+func (c azureBlobClient) list(ctx context.Context, prefix string) ([]string, error) {
+	if prefix != "" {
+		prefix += "/"
+	}
+
+	var names []string
+	marker := ""
+	for {
+		q := url.Values{"restype": {"container"}, "comp": {"list"}, "prefix": {prefix}}
+		if marker != "" {
+			q.Set("marker", marker)
+		}
+
+		resp, err := c.do(ctx, fmt.Sprintf("%s/%s?%s", c.endpoint, url.PathEscape(c.container), q.Encode()))
+		if err != nil {
+			return nil, err
+		}
+
+		var list azureBlobList
+		err = xml.NewDecoder(resp.Body).Decode(&list)
+		resp.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+
+		for _, b := range list.Blobs.Blob {
+			names = append(names, b.Name)
+		}
+
+		if list.NextMarker == "" {
+			return names, nil
+		}
+		marker = list.NextMarker
+	}
+}
+
+// download saves the blob to the target file
+func (c azureBlobClient) download(ctx context.Context, name, target string) error {
+	blob := (&url.URL{Path: name}).EscapedPath()
+	resp, err := c.do(ctx, fmt.Sprintf("%s/%s/%s", c.endpoint, url.PathEscape(c.container), blob))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	fs := utils.FS(ctx)
+	if err := fs.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+		return err
+	}
+
+	f, err := fs.Create(target)
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.Copy(f, resp.Body); err != nil {
+		f.Close()
+		return err
+	}
+
+	return f.Close()
+}
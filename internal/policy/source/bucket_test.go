@@ -0,0 +1,192 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package source
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/enterprise-contract/ec-cli/internal/utils"
+)
+
+func TestBucketSourceURL(t *testing.T) {
+	cases := []struct {
+		name     string
+		env      map[string]string
+		source   string
+		expected string
+	}{
+		{
+			name:     "S3 default region",
+			source:   "s3://bucket/policy/bundle.tar.gz",
+			expected: "s3::https://s3-us-east-1.amazonaws.com/bucket/policy/bundle.tar.gz",
+		},
+		{
+			name:     "S3 region from environment",
+			env:      map[string]string{"AWS_REGION": "eu-west-1"},
+			source:   "s3://bucket/policy",
+			expected: "s3::https://s3-eu-west-1.amazonaws.com/bucket/policy",
+		},
+		{
+			name:     "S3 region parameter",
+			env:      map[string]string{"AWS_DEFAULT_REGION": "eu-west-1"},
+			source:   "s3://bucket/policy?region=ap-south-1&aws_profile=ci",
+			expected: "s3::https://s3-ap-south-1.amazonaws.com/bucket/policy?aws_profile=ci",
+		},
+		{
+			name:     "GCS",
+			source:   "gs://bucket/policy/data",
+			expected: "gcs::https://www.googleapis.com/storage/v1/bucket/policy/data",
+		},
+		{
+			name:   "not a bucket",
+			source: "oci::registry.io/policy:latest",
+		},
+		{
+			name:   "Azure",
+			source: "azblob://container/policy",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			t.Setenv("AWS_REGION", "")
+			t.Setenv("AWS_DEFAULT_REGION", "")
+			for k, v := range c.env {
+				t.Setenv(k, v)
+			}
+
+			u, ok := bucketSourceURL(c.source)
+			assert.Equal(t, c.expected != "", ok)
+			assert.Equal(t, c.expected, u)
+		})
+	}
+}
+
+type fakeCredential struct{}
+
+func (fakeCredential) GetToken(_ context.Context, opts policy.TokenRequestOptions) (azcore.AccessToken, error) {
+	if len(opts.Scopes) != 1 || opts.Scopes[0] != "https://storage.azure.com/.default" {
+		return azcore.AccessToken{}, fmt.Errorf("unexpected scopes: %v", opts.Scopes)
+	}
+	return azcore.AccessToken{Token: "token"}, nil
+}
+
+func TestFetchAzureBlob(t *testing.T) {
+	blobs := map[string]string{
+		"policy/main.rego":    "package main",
+		"policy/lib/lib.rego": "package lib",
+		"data/rule_data.yml":  "rule_data: {}",
+		"other/ignored.rego":  "package ignored",
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token" || r.Header.Get("x-ms-version") == "" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+
+		if r.URL.Path == "/container" && r.URL.Query().Get("comp") == "list" {
+			prefix := r.URL.Query().Get("prefix")
+			_, _ = fmt.Fprint(w, `<?xml version="1.0" encoding="utf-8"?><EnumerationResults><Blobs>`)
+			for name := range blobs {
+				if len(name) > len(prefix) && name[:len(prefix)] == prefix {
+					_, _ = fmt.Fprintf(w, "<Blob><Name>%s</Name></Blob>", name)
+				}
+			}
+			_, _ = fmt.Fprint(w, `</Blobs><NextMarker /></EnumerationResults>`)
+			return
+		}
+
+		content, ok := blobs[r.URL.Path[len("/container/"):]]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte(content))
+	}))
+	t.Cleanup(server.Close)
+
+	originalEndpoint, originalCredential := azureBlobEndpoint, azureCredential
+	t.Cleanup(func() {
+		azureBlobEndpoint, azureCredential = originalEndpoint, originalCredential
+	})
+	azureBlobEndpoint = func(account string) string {
+		assert.Equal(t, "account", account)
+		return server.URL
+	}
+	azureCredential = func() (azcore.TokenCredential, error) {
+		return fakeCredential{}, nil
+	}
+
+	t.Setenv("AZURE_STORAGE_ACCOUNT", "account")
+
+	t.Run("prefix", func(t *testing.T) {
+		fs := afero.NewMemMapFs()
+		ctx := utils.WithFS(context.Background(), fs)
+
+		_, err := Download(ctx, "/work/policy", "azblob://container/policy", false)
+		require.NoError(t, err)
+
+		for file, expected := range map[string]string{"/work/policy/main.rego": "package main", "/work/policy/lib/lib.rego": "package lib"} {
+			content, err := afero.ReadFile(fs, file)
+			require.NoError(t, err)
+			assert.Equal(t, expected, string(content))
+		}
+		exists, err := afero.Exists(fs, "/work/policy/ignored.rego")
+		require.NoError(t, err)
+		assert.False(t, exists)
+	})
+
+	t.Run("single blob", func(t *testing.T) {
+		fs := afero.NewMemMapFs()
+		ctx := utils.WithFS(context.Background(), fs)
+
+		_, err := Download(ctx, "/work/data", "azblob://container/data/rule_data.yml", false)
+		require.NoError(t, err)
+
+		content, err := afero.ReadFile(fs, "/work/data/rule_data.yml")
+		require.NoError(t, err)
+		assert.Equal(t, "rule_data: {}", string(content))
+	})
+
+	t.Run("missing", func(t *testing.T) {
+		ctx := utils.WithFS(context.Background(), afero.NewMemMapFs())
+
+		_, err := Download(ctx, "/work/data", "azblob://container/missing.yml", false)
+		assert.ErrorContains(t, err, "404 Not Found")
+	})
+
+	t.Run("no account", func(t *testing.T) {
+		t.Setenv("AZURE_STORAGE_ACCOUNT", "")
+		ctx := utils.WithFS(context.Background(), afero.NewMemMapFs())
+
+		_, err := Download(ctx, "/work/data", "azblob://container/policy", false)
+		assert.ErrorContains(t, err, "AZURE_STORAGE_ACCOUNT")
+	})
+}
@@ -0,0 +1,447 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package source
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/enterprise-contract/go-gather/metadata"
+	fileMetadata "github.com/enterprise-contract/go-gather/metadata/file"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
+
+	"github.com/enterprise-contract/ec-cli/internal/logging"
+	"github.com/enterprise-contract/ec-cli/internal/utils"
+)
+
+type bundleWriterKey struct{}
+
+type bundleDirKey struct{}
+
+var (
+	ErrSourceNotInBundle = errors.New("source is not in the bundle")
+	ErrOffline           = errors.New("network access is not allowed offline")
+)
+
+const (
+	// bundleManifest is the name of the file describing the content of a bundle
+	bundleManifest = "bundle.json"
+	// BundlePolicy is the name of the policy configuration within a bundle
+	BundlePolicy = "policy.yaml"
+)
+
+// BundleSource is a source within a bundle
+type BundleSource struct {
+	URL string `json:"url"`
+	// Path of the content of the source within the bundle
+	Path string `json:"path"`
+	// Digest of the content of the source, see ContentDigest
+	Digest string `json:"digest"`
+}
+
+// BundleManifest describes the content of a bundle
+type BundleManifest struct {
+	Created time.Time      `json:"created"`
+	Sources []BundleSource `json:"sources"`
+	// Keys are the paths of the public keys within the bundle
+	Keys []string `json:"keys,omitempty"`
+	// Policy is the path of the policy configuration within the bundle, if any
+	Policy string `json:"policy,omitempty"`
+}
+
+// offline is true when the sources are fetched exclusively from the bundle in
+// use, see UseBundle
+var offline atomic.Bool
+
+// SetOffline sets whether the sources are fetched exclusively from the bundle
+// in use, without any network access.
+func SetOffline(enabled bool) {
+	offline.Store(enabled)
+}
+
+// IsOffline returns true if the sources are fetched exclusively from the
+// bundle in use.
+func IsOffline() bool {
+	return offline.Load()
+}
+
+// BundleDir returns the directory of the bundle in use, within the user's
+// cache directory, i.e. $XDG_CACHE_HOME/ec/bundle.
+func BundleDir() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(dir, "ec", "bundle"), nil
+}
+
+// BundleWriter collects the sources fetched, the policy configuration and the
+// public keys into a bundle, to be written using Write.
+type BundleWriter struct {
+	// dir is the directory the content of the bundle is collected in
+	dir      string
+	manifest BundleManifest
+	mu       sync.Mutex
+}
+
+// NewBundleWriter returns a BundleWriter collecting the content of the bundle
+// in the given directory.
+func NewBundleWriter(dir string) *BundleWriter {
+	return &BundleWriter{dir: dir}
+}
+
+// WithBundleWriter returns a context in which all sources fetched are added to
+// the bundle.
+func WithBundleWriter(ctx context.Context, b *BundleWriter) context.Context {
+	return context.WithValue(ctx, bundleWriterKey{}, b)
+}
+
+func bundleWriterFrom(ctx context.Context) *BundleWriter {
+	if b, ok := ctx.Value(bundleWriterKey{}).(*BundleWriter); ok {
+		return b
+	}
+	return nil
+}
+
+// recorded wraps the download function to add the content of the fetched
+// sources to the bundle.
+func (b *BundleWriter) recorded(ctx context.Context, dl func(string, string) (metadata.Metadata, error)) func(string, string) (metadata.Metadata, error) {
+	return func(sourceUrl string, dest string) (metadata.Metadata, error) {
+		m, err := dl(sourceUrl, dest)
+		if err != nil {
+			return m, err
+		}
+
+		afs := utils.FS(ctx)
+		path := filepath.Join("sources", cacheKey(sourceUrl))
+		if err := copyDir(afs, dest, filepath.Join(b.dir, path)); err != nil {
+			return m, fmt.Errorf("adding %s to the bundle: %w", logging.RedactURL(sourceUrl), err)
+		}
+
+		digest, err := ContentDigest(afs, dest)
+		if err != nil {
+			return m, err
+		}
+
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		if !slices.ContainsFunc(b.manifest.Sources, func(s BundleSource) bool { return s.URL == sourceUrl }) {
+			b.manifest.Sources = append(b.manifest.Sources, BundleSource{URL: sourceUrl, Path: filepath.ToSlash(path), Digest: digest})
+		}
+
+		return m, nil
+	}
+}
+
+// AddPolicy adds the policy configuration to the bundle.
+func (b *BundleWriter) AddPolicy(afs afero.Fs, config []byte) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if err := afero.WriteFile(afs, filepath.Join(b.dir, BundlePolicy), config, 0o644); err != nil {
+		return err
+	}
+	b.manifest.Policy = BundlePolicy
+
+	return nil
+}
+
+// AddKey adds the public key file to the bundle, named as the file.
+func (b *BundleWriter) AddKey(afs afero.Fs, file string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	path := filepath.ToSlash(filepath.Join("keys", filepath.Base(file)))
+	if slices.Contains(b.manifest.Keys, path) {
+		return fmt.Errorf("a key named %s is already in the bundle", filepath.Base(file))
+	}
+
+	key, err := afero.ReadFile(afs, file)
+	if err != nil {
+		return err
+	}
+
+	if err := afs.MkdirAll(filepath.Join(b.dir, "keys"), 0o755); err != nil {
+		return err
+	}
+	if err := afero.WriteFile(afs, filepath.Join(b.dir, path), key, 0o644); err != nil {
+		return err
+	}
+	b.manifest.Keys = append(b.manifest.Keys, path)
+
+	return nil
+}
+
+// Write writes the bundle as a gzip compressed tarball to the given path, and
+// returns its manifest.
+func (b *BundleWriter) Write(afs afero.Fs, path string) (BundleManifest, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	slices.SortFunc(b.manifest.Sources, func(a, b BundleSource) int {
+		return strings.Compare(a.URL, b.URL)
+	})
+	b.manifest.Created = time.Now().UTC()
+
+	data, err := json.MarshalIndent(b.manifest, "", "  ")
+	if err != nil {
+		return BundleManifest{}, err
+	}
+	if err := afero.WriteFile(afs, filepath.Join(b.dir, bundleManifest), data, 0o644); err != nil {
+		return BundleManifest{}, err
+	}
+
+	f, err := afs.Create(path)
+	if err != nil {
+		return BundleManifest{}, err
+	}
+	defer f.Close()
+
+	gz := gzip.NewWriter(f)
+	tw := tar.NewWriter(gz)
+
+	err = afero.Walk(afs, b.dir, func(p string, info fs.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(b.dir, p)
+		if err != nil || rel == "." {
+			return err
+		}
+
+		if !info.IsDir() && !info.Mode().IsRegular() {
+			return nil
+		}
+
+		hdr, err := tar.FileInfoHeader(info, "")
+		if err != nil {
+			return err
+		}
+		hdr.Name = filepath.ToSlash(rel)
+		if info.IsDir() {
+			hdr.Name += "/"
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+
+		if info.IsDir() {
+			return nil
+		}
+
+		in, err := afs.Open(p)
+		if err != nil {
+			return err
+		}
+		defer in.Close()
+
+		_, err = io.Copy(tw, in)
+		return err
+	})
+	if err != nil {
+		return BundleManifest{}, err
+	}
+
+	if err := tw.Close(); err != nil {
+		return BundleManifest{}, err
+	}
+	if err := gz.Close(); err != nil {
+		return BundleManifest{}, err
+	}
+
+	return b.manifest, f.Close()
+}
+
+// UseBundle verifies the bundle at the given path and installs it as the bundle
+// the sources are fetched from when offline, replacing any bundle previously
+// in use. Returns the directory the bundle is installed in and its manifest.
+func UseBundle(afs afero.Fs, path string) (string, BundleManifest, error) {
+	dir, err := BundleDir()
+	if err != nil {
+		return "", BundleManifest{}, err
+	}
+
+	tmp := fmt.Sprintf("%s.%d", dir, time.Now().UnixNano())
+	manifest, err := ExtractBundle(afs, path, tmp)
+	if err != nil {
+		_ = afs.RemoveAll(tmp)
+		return "", BundleManifest{}, err
+	}
+
+	if err := afs.RemoveAll(dir); err != nil {
+		_ = afs.RemoveAll(tmp)
+		return "", BundleManifest{}, err
+	}
+
+	return dir, manifest, afs.Rename(tmp, dir)
+}
+
+// ExtractBundle extracts the bundle at the given path into the directory,
+// verifying the content of the sources against the digests recorded in the
+// bundle. Returns the manifest of the bundle. Use WithBundleDir to fetch the
+// sources from the extracted bundle instead of the bundle in use.
+func ExtractBundle(afs afero.Fs, path, dir string) (BundleManifest, error) {
+	if err := extractBundle(afs, path, dir); err != nil {
+		return BundleManifest{}, fmt.Errorf("reading the bundle %s: %w", path, err)
+	}
+
+	manifest, err := readBundleManifest(afs, dir)
+	if err != nil {
+		return BundleManifest{}, fmt.Errorf("reading the bundle %s: %w", path, err)
+	}
+
+	for _, s := range manifest.Sources {
+		digest, err := ContentDigest(afs, filepath.Join(dir, filepath.FromSlash(s.Path)))
+		if err != nil || digest != s.Digest {
+			return BundleManifest{}, fmt.Errorf("%w: %s in the bundle %s", ErrDigestMismatch, logging.RedactURL(s.URL), path)
+		}
+	}
+
+	return manifest, nil
+}
+
+// WithBundleDir returns a context in which the sources are fetched, when
+// offline, from the bundle extracted into the given directory, see
+// ExtractBundle, instead of the bundle in use.
+func WithBundleDir(ctx context.Context, dir string) context.Context {
+	return context.WithValue(ctx, bundleDirKey{}, dir)
+}
+
+// extractBundle extracts the directories and the regular files of the bundle
+// tarball into the directory
+func extractBundle(afs afero.Fs, path, dir string) error {
+	f, err := afs.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return err
+	}
+	defer gz.Close()
+
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		name := filepath.FromSlash(strings.TrimSuffix(hdr.Name, "/"))
+		if !filepath.IsLocal(name) {
+			return fmt.Errorf("the path %q is not within the bundle", hdr.Name)
+		}
+		target := filepath.Join(dir, name)
+
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			if err := afs.MkdirAll(target, 0o755); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			if err := afs.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+				return err
+			}
+			out, err := afs.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o644)
+			if err != nil {
+				return err
+			}
+			if _, err := io.Copy(out, tr); err != nil {
+				out.Close()
+				return err
+			}
+			if err := out.Close(); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func readBundleManifest(afs afero.Fs, dir string) (BundleManifest, error) {
+	data, err := afero.ReadFile(afs, filepath.Join(dir, bundleManifest))
+	if err != nil {
+		return BundleManifest{}, err
+	}
+
+	var manifest BundleManifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return BundleManifest{}, err
+	}
+
+	for _, s := range manifest.Sources {
+		if !filepath.IsLocal(filepath.FromSlash(s.Path)) {
+			return BundleManifest{}, fmt.Errorf("the path %q of %s is not within the bundle", s.Path, logging.RedactURL(s.URL))
+		}
+	}
+
+	return manifest, nil
+}
+
+// fromBundle returns a download function copying the sources from the bundle
+// in use, or the one given by WithBundleDir, failing for any source not in the
+// bundle.
+func fromBundle(ctx context.Context) func(string, string) (metadata.Metadata, error) {
+	return func(sourceUrl string, dest string) (metadata.Metadata, error) {
+		afs := utils.FS(ctx)
+
+		dir, ok := ctx.Value(bundleDirKey{}).(string)
+		if !ok {
+			var err error
+			if dir, err = BundleDir(); err != nil {
+				return nil, err
+			}
+		}
+
+		manifest, err := readBundleManifest(afs, dir)
+		if err != nil {
+			return nil, fmt.Errorf("no bundle in use, see ec bundle use: %w", err)
+		}
+
+		i := slices.IndexFunc(manifest.Sources, func(s BundleSource) bool { return s.URL == sourceUrl })
+		if i == -1 {
+			return nil, fmt.Errorf("%w: %s", ErrSourceNotInBundle, logging.RedactURL(sourceUrl))
+		}
+
+		log.Debugf("Copying %s from the bundle", logging.RedactURL(sourceUrl))
+		if err := copyDir(afs, filepath.Join(dir, filepath.FromSlash(manifest.Sources[i].Path)), dest); err != nil {
+			return nil, err
+		}
+
+		return &fileMetadata.FileMetadata{Path: dest}, nil
+	}
+}
@@ -0,0 +1,273 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package source
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sync/atomic"
+	"time"
+
+	"github.com/enterprise-contract/go-gather/metadata"
+	gitMetadata "github.com/enterprise-contract/go-gather/metadata/git"
+	ociMetadata "github.com/enterprise-contract/go-gather/metadata/oci"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
+
+	"github.com/enterprise-contract/ec-cli/internal/logging"
+	"github.com/enterprise-contract/ec-cli/internal/utils"
+)
+
+// cacheTTL is how long a source fetched to the persistent cache is reused,
+// the persistent cache is not used when zero
+var cacheTTL atomic.Int64
+
+// SetCacheTTL sets how long a source fetched to the persistent cache is reused
+// before it is fetched again. Sources pinned to a revision are reused
+// regardless. The persistent cache is not used when the TTL is zero.
+func SetCacheTTL(ttl time.Duration) {
+	cacheTTL.Store(int64(ttl))
+}
+
+// CacheDir returns the directory of the persistent cache of the sources,
+// within the user's cache directory, i.e. $XDG_CACHE_HOME/ec/sources.
+func CacheDir() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(dir, "ec", "sources"), nil
+}
+
+// cacheEntry describes a source in the persistent cache
+type cacheEntry struct {
+	URL string `json:"url"`
+	// Kind is the kind of the source, git or oci
+	Kind string `json:"kind"`
+	// Revision is the commit, or the image digest, the source was fetched at
+	Revision string `json:"revision"`
+	// Digest is the digest of the content, see ContentDigest
+	Digest  string    `json:"digest"`
+	Fetched time.Time `json:"fetched"`
+}
+
+// metadata returns the metadata of the source as it was when fetched
+func (e cacheEntry) metadata() metadata.Metadata {
+	if e.Kind == "oci" {
+		return &ociMetadata.OCIMetadata{Digest: e.Revision}
+	}
+
+	return &gitMetadata.GitMetadata{LatestCommit: e.Revision}
+}
+
+// pinnedRevision matches the source URLs pinned to a revision, by image digest
+// or by git commit
+var pinnedRevision = regexp.MustCompile(`@sha256:[0-9a-f]{64}|[?&]ref=[0-9a-f]{40}\b`)
+
+// cached wraps the download function to reuse the sources from the persistent
+// cache. Only git and OCI sources are cached, and the content of a source is
+// verified before it is reused. Failing to use the persistent cache is not an
+// error, the source is fetched instead.
+func cached(ctx context.Context, dl func(string, string) (metadata.Metadata, error)) func(string, string) (metadata.Metadata, error) {
+	ttl := time.Duration(cacheTTL.Load())
+	if ttl <= 0 {
+		return dl
+	}
+
+	root, err := CacheDir()
+	if err != nil {
+		log.Debugf("Not using the persistent cache: %v", err)
+		return dl
+	}
+
+	return func(sourceUrl string, dest string) (metadata.Metadata, error) {
+		afs := utils.FS(ctx)
+		dir := filepath.Join(root, cacheKey(sourceUrl))
+
+		if entry, ok := readCacheEntry(afs, dir, sourceUrl, ttl); ok {
+			if err := copyDir(afs, filepath.Join(dir, "content"), dest); err == nil {
+				log.Debugf("Persistent cache hit: %s at %s", logging.RedactURL(sourceUrl), entry.Revision)
+				return entry.metadata(), nil
+			} else {
+				log.Debugf("Unable to copy %s from the persistent cache: %v", logging.RedactURL(sourceUrl), err)
+			}
+		}
+
+		m, err := dl(sourceUrl, dest)
+		if err != nil {
+			return m, err
+		}
+
+		if err := writeCacheEntry(afs, dir, sourceUrl, m, dest); err != nil {
+			log.Debugf("Unable to store %s in the persistent cache: %v", logging.RedactURL(sourceUrl), err)
+		}
+
+		return m, nil
+	}
+}
+
+// cacheKey returns the name of the directory of the source in the persistent
+// cache
+func cacheKey(sourceUrl string) string {
+	sum := sha256.Sum256([]byte(sourceUrl))
+	return hex.EncodeToString(sum[:])
+}
+
+// readCacheEntry returns the entry of the source in the persistent cache if it
+// can be reused, i.e. it has not expired and its content is intact.
+func readCacheEntry(afs afero.Fs, dir, sourceUrl string, ttl time.Duration) (cacheEntry, bool) {
+	data, err := afero.ReadFile(afs, filepath.Join(dir, "entry.json"))
+	if err != nil {
+		return cacheEntry{}, false
+	}
+
+	var entry cacheEntry
+	if err := json.Unmarshal(data, &entry); err != nil || entry.URL != sourceUrl {
+		return cacheEntry{}, false
+	}
+
+	if !pinnedRevision.MatchString(sourceUrl) && time.Since(entry.Fetched) > ttl {
+		log.Debugf("Persistent cache entry of %s has expired", logging.RedactURL(sourceUrl))
+		return cacheEntry{}, false
+	}
+
+	digest, err := ContentDigest(afs, filepath.Join(dir, "content"))
+	if err != nil || digest != entry.Digest {
+		log.Debugf("Persistent cache entry of %s is corrupted", logging.RedactURL(sourceUrl))
+		return cacheEntry{}, false
+	}
+
+	return entry, true
+}
+
+// writeCacheEntry stores the fetched source in the persistent cache, replacing
+// any previous entry of the source
+func writeCacheEntry(afs afero.Fs, dir, sourceUrl string, m metadata.Metadata, src string) error {
+	entry := cacheEntry{URL: sourceUrl, Fetched: time.Now().UTC()}
+	if entry.Kind, entry.Revision = sourceRevision(m); entry.Kind == "" {
+		return nil
+	}
+
+	var err error
+	if entry.Digest, err = ContentDigest(afs, src); err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	// Populate a new entry next to the previous one and swap them, so that a
+	// concurrent run does not see a partial entry
+	tmp := fmt.Sprintf("%s.%d", dir, time.Now().UnixNano())
+	if err := copyDir(afs, src, filepath.Join(tmp, "content")); err != nil {
+		_ = afs.RemoveAll(tmp)
+		return err
+	}
+	if err := afero.WriteFile(afs, filepath.Join(tmp, "entry.json"), data, 0o644); err != nil {
+		_ = afs.RemoveAll(tmp)
+		return err
+	}
+
+	if err := afs.RemoveAll(dir); err != nil {
+		_ = afs.RemoveAll(tmp)
+		return err
+	}
+
+	return afs.Rename(tmp, dir)
+}
+
+// sourceRevision returns the kind of the source, git or oci, and the commit or
+// the image digest it was fetched at. Both are empty for other sources.
+func sourceRevision(m metadata.Metadata) (kind, revision string) {
+	switch m := m.(type) {
+	case *gitMetadata.GitMetadata:
+		return "git", m.LatestCommit
+	case *ociMetadata.OCIMetadata:
+		return "oci", m.Digest
+	}
+
+	return "", ""
+}
+
+// copyDir copies the regular files and the directories from the source to the
+// destination directory. The metadata of version control systems is not
+// copied, it is not part of the content and the git metadata holds the url of
+// the remote which can include the credentials.
+func copyDir(afs afero.Fs, src, dest string) error {
+	return afero.Walk(afs, src, func(p string, info fs.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if isVCSMetadata(info) {
+			return filepath.SkipDir
+		}
+
+		rel, err := filepath.Rel(src, p)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dest, rel)
+
+		if info.IsDir() {
+			return afs.MkdirAll(target, 0o755)
+		}
+
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+
+		in, err := afs.Open(p)
+		if err != nil {
+			return err
+		}
+		defer in.Close()
+
+		out, err := afs.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, info.Mode().Perm()|0o600)
+		if err != nil {
+			return err
+		}
+
+		if _, err := io.Copy(out, in); err != nil {
+			out.Close()
+			return err
+		}
+
+		return out.Close()
+	})
+}
+
+// CleanCache removes all of the sources from the persistent cache
+func CleanCache(afs afero.Fs) (string, error) {
+	dir, err := CacheDir()
+	if err != nil {
+		return "", err
+	}
+
+	return dir, afs.RemoveAll(dir)
+}
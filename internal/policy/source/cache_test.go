@@ -0,0 +1,128 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package source
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/enterprise-contract/go-gather/metadata"
+	gitMetadata "github.com/enterprise-contract/go-gather/metadata/git"
+	ociMetadata "github.com/enterprise-contract/go-gather/metadata/oci"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/enterprise-contract/ec-cli/internal/utils"
+)
+
+func TestPersistentCache(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", "/cache")
+	t.Cleanup(func() { SetCacheTTL(0) })
+
+	const pinned = "oci::registry.io/policy@sha256:4e388ab32b10dc8dbc7e28144f552830adc74787c1e2c0824032078a79f227fb"
+
+	setup := func(t *testing.T, ttl time.Duration, m metadata.Metadata) (afero.Fs, func(string, string) (metadata.Metadata, error), *int) {
+		fs := afero.NewMemMapFs()
+		ctx := utils.WithFS(context.Background(), fs)
+		fetched := 0
+		SetCacheTTL(ttl)
+		dl := cached(ctx, func(_ string, dest string) (metadata.Metadata, error) {
+			fetched++
+			return m, afero.WriteFile(fs, filepath.Join(dest, "policy", "policy.rego"), []byte("package policy"), 0400)
+		})
+
+		return fs, dl, &fetched
+	}
+
+	fetch := func(t *testing.T, fs afero.Fs, dl func(string, string) (metadata.Metadata, error), url, dest string) metadata.Metadata {
+		m, err := dl(url, dest)
+		require.NoError(t, err)
+		content, err := afero.ReadFile(fs, filepath.Join(dest, "policy", "policy.rego"))
+		require.NoError(t, err)
+		assert.Equal(t, "package policy", string(content))
+		return m
+	}
+
+	t.Run("disabled", func(t *testing.T) {
+		fs, dl, fetched := setup(t, 0, &gitMetadata.GitMetadata{LatestCommit: "abc"})
+		fetch(t, fs, dl, "git::https://example.com/policy", "/work/1")
+		fetch(t, fs, dl, "git::https://example.com/policy", "/work/2")
+		assert.Equal(t, 2, *fetched)
+		exists, err := afero.DirExists(fs, "/cache/ec/sources")
+		require.NoError(t, err)
+		assert.False(t, exists)
+	})
+
+	t.Run("reused", func(t *testing.T) {
+		fs, dl, fetched := setup(t, time.Hour, &gitMetadata.GitMetadata{LatestCommit: "abc"})
+		fetch(t, fs, dl, "git::https://example.com/policy", "/work/1")
+		m := fetch(t, fs, dl, "git::https://example.com/policy", "/work/2")
+		assert.Equal(t, 1, *fetched)
+		assert.Equal(t, &gitMetadata.GitMetadata{LatestCommit: "abc"}, m)
+
+		fetch(t, fs, dl, "git::https://example.com/other", "/work/3")
+		assert.Equal(t, 2, *fetched)
+	})
+
+	t.Run("expired", func(t *testing.T) {
+		fs, dl, fetched := setup(t, time.Nanosecond, &ociMetadata.OCIMetadata{Digest: "sha256:abc"})
+		fetch(t, fs, dl, "oci::registry.io/policy:latest", "/work/1")
+		time.Sleep(time.Millisecond)
+		fetch(t, fs, dl, "oci::registry.io/policy:latest", "/work/2")
+		assert.Equal(t, 2, *fetched)
+	})
+
+	t.Run("pinned", func(t *testing.T) {
+		fs, dl, fetched := setup(t, time.Nanosecond, &ociMetadata.OCIMetadata{Digest: "sha256:abc"})
+		fetch(t, fs, dl, pinned, "/work/1")
+		time.Sleep(time.Millisecond)
+		m := fetch(t, fs, dl, pinned, "/work/2")
+		assert.Equal(t, 1, *fetched)
+		assert.Equal(t, &ociMetadata.OCIMetadata{Digest: "sha256:abc"}, m)
+	})
+
+	t.Run("corrupted", func(t *testing.T) {
+		fs, dl, fetched := setup(t, time.Hour, &ociMetadata.OCIMetadata{Digest: "sha256:abc"})
+		fetch(t, fs, dl, pinned, "/work/1")
+		require.NoError(t, afero.WriteFile(fs, filepath.Join("/cache/ec/sources", cacheKey(pinned), "content", "policy", "policy.rego"), []byte("package evil"), 0600))
+		fetch(t, fs, dl, pinned, "/work/2")
+		assert.Equal(t, 2, *fetched)
+	})
+
+	t.Run("local sources", func(t *testing.T) {
+		fs, dl, fetched := setup(t, time.Hour, nil)
+		fetch(t, fs, dl, "./policy", "/work/1")
+		fetch(t, fs, dl, "./policy", "/work/2")
+		assert.Equal(t, 2, *fetched)
+	})
+
+	t.Run("clean", func(t *testing.T) {
+		fs, dl, _ := setup(t, time.Hour, &gitMetadata.GitMetadata{LatestCommit: "abc"})
+		fetch(t, fs, dl, "git::https://example.com/policy", "/work/1")
+		dir, err := CleanCache(fs)
+		require.NoError(t, err)
+		assert.Equal(t, "/cache/ec/sources", dir)
+		exists, err := afero.DirExists(fs, dir)
+		require.NoError(t, err)
+		assert.False(t, exists)
+	})
+}
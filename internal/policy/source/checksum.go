@@ -0,0 +1,132 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package source
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"regexp"
+	"strings"
+
+	"github.com/enterprise-contract/go-gather/metadata"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
+
+	"github.com/enterprise-contract/ec-cli/internal/logging"
+	"github.com/enterprise-contract/ec-cli/internal/utils"
+)
+
+var ErrChecksumMismatch = errors.New("checksum of the source does not match")
+
+// checksumParameter matches the go-getter style checksum parameter of a source
+// URL, e.g. ?checksum=sha256:<hex>
+var checksumParameter = regexp.MustCompile(`([?&])checksum=([^&]*)&?`)
+
+// splitChecksum returns the source URL without the checksum parameter, and the
+// checksum, empty if the URL has none.
+func splitChecksum(sourceUrl string) (string, string) {
+	m := checksumParameter.FindStringSubmatchIndex(sourceUrl)
+	if m == nil {
+		return sourceUrl, ""
+	}
+
+	checksum := sourceUrl[m[4]:m[5]]
+	rest := sourceUrl[m[1]:]
+	if rest == "" {
+		// Drop the separator preceding the parameter as well
+		return sourceUrl[:m[0]], checksum
+	}
+
+	return sourceUrl[:m[2]+1] + rest, checksum
+}
+
+// checksummed wraps the download function to verify the content of the
+// sources that carry a checksum in their URL. The checksum, given as
+// sha256:<hex>, is either the digest of the content, see ContentDigest, or for
+// a source of a single file the SHA-256 of the file. The checksum parameter is
+// removed from the URL downloaded.
+func checksummed(ctx context.Context, dl func(string, string) (metadata.Metadata, error)) func(string, string) (metadata.Metadata, error) {
+	return func(sourceUrl string, dest string) (metadata.Metadata, error) {
+		sourceUrl, checksum := splitChecksum(sourceUrl)
+		if checksum == "" {
+			return dl(sourceUrl, dest)
+		}
+
+		if !strings.HasPrefix(checksum, "sha256:") {
+			return nil, fmt.Errorf("unsupported checksum %q of the source %s, expected sha256:<hex>", checksum, logging.RedactURL(sourceUrl))
+		}
+
+		m, err := dl(sourceUrl, dest)
+		if err != nil {
+			return m, err
+		}
+
+		afs := utils.FS(ctx)
+		digest, err := ContentDigest(afs, dest)
+		if err != nil {
+			return nil, err
+		}
+		if digest == checksum {
+			return m, nil
+		}
+
+		if fileDigest, ok := singleFileDigest(afs, dest); ok && fileDigest == checksum {
+			return m, nil
+		}
+
+		log.Debugf("Content of %s has the digest %s", logging.RedactURL(sourceUrl), digest)
+
+		return nil, fmt.Errorf("%w: %s, expected %s", ErrChecksumMismatch, logging.RedactURL(sourceUrl), checksum)
+	}
+}
+
+// singleFileDigest returns the SHA-256 of the only file within the directory
+func singleFileDigest(afs afero.Fs, dir string) (string, bool) {
+	var file string
+	count := 0
+	err := afero.Walk(afs, dir, func(p string, info fs.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() {
+			file = p
+			count++
+		}
+		return nil
+	})
+	if err != nil || count != 1 {
+		return "", false
+	}
+
+	f, err := afs.Open(file)
+	if err != nil {
+		return "", false
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", false
+	}
+
+	return "sha256:" + hex.EncodeToString(h.Sum(nil)), true
+}
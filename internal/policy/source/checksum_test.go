@@ -0,0 +1,94 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package source
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"path/filepath"
+	"testing"
+
+	"github.com/enterprise-contract/go-gather/metadata"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/enterprise-contract/ec-cli/internal/utils"
+)
+
+func TestSplitChecksum(t *testing.T) {
+	cases := []struct {
+		url      string
+		expected string
+		checksum string
+	}{
+		{"https://example.com/policy.tar.gz", "https://example.com/policy.tar.gz", ""},
+		{"https://example.com/policy.tar.gz?checksum=sha256:abc", "https://example.com/policy.tar.gz", "sha256:abc"},
+		{"https://example.com/p?checksum=sha256:abc&ref=main", "https://example.com/p?ref=main", "sha256:abc"},
+		{"https://example.com/p?ref=main&checksum=sha256:abc", "https://example.com/p?ref=main", "sha256:abc"},
+		{"https://example.com/p?a=1&checksum=sha256:abc&b=2", "https://example.com/p?a=1&b=2", "sha256:abc"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.url, func(t *testing.T) {
+			url, checksum := splitChecksum(c.url)
+			assert.Equal(t, c.expected, url)
+			assert.Equal(t, c.checksum, checksum)
+		})
+	}
+}
+
+func TestChecksummed(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	ctx := utils.WithFS(context.Background(), fs)
+
+	content := []byte("package policy")
+	fileSum := sha256.Sum256(content)
+	fileChecksum := "sha256:" + hex.EncodeToString(fileSum[:])
+
+	var downloaded []string
+	dl := checksummed(ctx, func(sourceUrl string, dest string) (metadata.Metadata, error) {
+		downloaded = append(downloaded, sourceUrl)
+		return nil, afero.WriteFile(fs, filepath.Join(dest, "policy.rego"), content, 0o644)
+	})
+
+	_, err := dl("https://example.com/a/b/policy.rego", "/none")
+	require.NoError(t, err)
+
+	_, err = dl("https://example.com/a/b/policy.rego?checksum="+fileChecksum, "/file")
+	require.NoError(t, err)
+
+	digest, err := ContentDigest(fs, "/file")
+	require.NoError(t, err)
+	_, err = dl("https://example.com/a/b/policy.rego?checksum="+digest, "/tree")
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{
+		"https://example.com/a/b/policy.rego",
+		"https://example.com/a/b/policy.rego",
+		"https://example.com/a/b/policy.rego",
+	}, downloaded)
+
+	_, err = dl("https://example.com/a/b/policy.rego?checksum=sha256:0000", "/mismatch")
+	assert.ErrorIs(t, err, ErrChecksumMismatch)
+
+	_, err = dl("https://example.com/a/b/policy.rego?checksum=md5:0000", "/unsupported")
+	assert.EqualError(t, err, `unsupported checksum "md5:0000" of the source https://example.com/REDACTED, expected sha256:<hex>`)
+}
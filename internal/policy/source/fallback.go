@@ -0,0 +1,109 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package source
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/enterprise-contract/ec-cli/internal/logging"
+)
+
+type fallbacksKey struct{}
+
+// Fallbacks maps a source URL to the ordered list of source URLs to try when
+// the source can not be fetched.
+type Fallbacks map[string][]string
+
+// usedFallbacks records the fallback source URL used for a source URL that
+// could not be fetched.
+var usedFallbacks sync.Map
+
+// ParseFallbacks parses the fallbacks given as <source>=<fallback> pairs. A
+// source can be given multiple times, its fallbacks are tried in the order
+// given.
+func ParseFallbacks(values []string) (Fallbacks, error) {
+	fallbacks := Fallbacks{}
+	for _, v := range values {
+		src, fallback, found := strings.Cut(v, "=")
+		if !found || src == "" || fallback == "" {
+			return nil, fmt.Errorf("invalid policy fallback %q, expected <source>=<fallback>", v)
+		}
+		fallbacks[src] = append(fallbacks[src], fallback)
+	}
+
+	return fallbacks, nil
+}
+
+// WithFallbacks returns a context with the fallback sources used by GetPolicy
+// when a source can not be fetched.
+func WithFallbacks(ctx context.Context, fallbacks Fallbacks) context.Context {
+	return context.WithValue(ctx, fallbacksKey{}, fallbacks)
+}
+
+func fallbacksFrom(ctx context.Context) Fallbacks {
+	if f, ok := ctx.Value(fallbacksKey{}).(Fallbacks); ok {
+		return f
+	}
+	return nil
+}
+
+// UsedFallbacks returns the source URLs that could not be fetched mapped to
+// the fallback source URL that was used instead.
+func UsedFallbacks() map[string]string {
+	used := map[string]string{}
+	usedFallbacks.Range(func(k, v any) bool {
+		used[k.(string)] = v.(string)
+		return true
+	})
+
+	if len(used) == 0 {
+		return nil
+	}
+
+	return used
+}
+
+// getPolicyWithFallbacks fetches the policy source, and if that fails, each of
+// its fallback sources in order until one succeeds.
+func getPolicyWithFallbacks(ctx context.Context, p *PolicyUrl, get func(*PolicyUrl) (string, error)) (string, error) {
+	dir, err := get(p)
+	fallbacks := fallbacksFrom(ctx)[p.Url]
+	if err == nil || len(fallbacks) == 0 {
+		return dir, err
+	}
+
+	log.Warnf("Unable to fetch the %s source %s, trying its fallback sources: %v", p.Kind, logging.RedactURL(p.Url), err)
+
+	allErrors := err
+	for _, f := range fallbacks {
+		dir, err = get(&PolicyUrl{Url: f, Kind: p.Kind})
+		if err == nil {
+			usedFallbacks.Store(p.Url, f)
+			log.Warnf("Using the fallback %s source %s instead of %s", p.Kind, logging.RedactURL(f), logging.RedactURL(p.Url))
+			return dir, nil
+		}
+		allErrors = errors.Join(allErrors, err)
+	}
+
+	return "", allErrors
+}
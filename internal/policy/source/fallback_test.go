@@ -0,0 +1,87 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package source
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseFallbacks(t *testing.T) {
+	fallbacks, err := ParseFallbacks([]string{
+		"oci::registry.io/policy:latest=oci::mirror.io/policy:latest",
+		"oci::registry.io/policy:latest=git::https://example.com/policy.git",
+		"oci::registry.io/data:latest=oci::mirror.io/data:latest?ref=a=b",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, Fallbacks{
+		"oci::registry.io/policy:latest": {"oci::mirror.io/policy:latest", "git::https://example.com/policy.git"},
+		"oci::registry.io/data:latest":   {"oci::mirror.io/data:latest?ref=a=b"},
+	}, fallbacks)
+
+	for _, invalid := range []string{"oci::registry.io/policy:latest", "=fallback", "source="} {
+		_, err := ParseFallbacks([]string{invalid})
+		assert.ErrorContains(t, err, "expected <source>=<fallback>")
+	}
+}
+
+func TestGetPolicyWithFallbacks(t *testing.T) {
+	primary := "https://fallback.example.com/primary.git"
+	unreachable := "https://fallback.example.com/unreachable.git"
+	fallback := "https://fallback.example.com/fallback.git"
+
+	dl := mockDownloader{}
+	dl.On("Download", mock.Anything, primary, false).Return(errors.New("primary is down"))
+	dl.On("Download", mock.Anything, unreachable, false).Return(errors.New("unreachable is down"))
+	dl.On("Download", mock.Anything, fallback, false).Return(nil)
+
+	ctx := usingDownloader(context.Background(), &dl)
+	ctx = WithFallbacks(ctx, Fallbacks{primary: {unreachable, fallback}})
+
+	p := PolicyUrl{Url: primary, Kind: PolicyKind}
+	dir, err := p.GetPolicy(ctx, "/tmp/ec-work-1234", false)
+	require.NoError(t, err)
+	assert.Regexp(t, "^/tmp/ec-work-1234/policy/[0-9a-f]+$", dir)
+
+	assert.Equal(t, fallback, UsedFallbacks()[primary])
+	mock.AssertExpectationsForObjects(t, &dl)
+}
+
+func TestGetPolicyWithFailingFallbacks(t *testing.T) {
+	primary := "https://fallback.example.com/failing-primary.git"
+	fallback := "https://fallback.example.com/failing-fallback.git"
+
+	dl := mockDownloader{}
+	dl.On("Download", mock.Anything, primary, false).Return(errors.New("primary is down"))
+	dl.On("Download", mock.Anything, fallback, false).Return(errors.New("fallback is down"))
+
+	ctx := usingDownloader(context.Background(), &dl)
+	ctx = WithFallbacks(ctx, Fallbacks{primary: {fallback}})
+
+	p := PolicyUrl{Url: primary, Kind: PolicyKind}
+	_, err := p.GetPolicy(ctx, "/tmp/ec-work-1234", false)
+	assert.EqualError(t, err, "primary is down\nfallback is down")
+
+	assert.NotContains(t, UsedFallbacks(), primary)
+}
@@ -24,10 +24,15 @@ package source
 import (
 	"context"
 	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
 	"strings"
 
 	getter "github.com/hashicorp/go-getter"
 	log "github.com/sirupsen/logrus"
+
+	"github.com/enterprise-contract/ec-cli/internal/logging"
 )
 
 // SourceIsFile returns true if go-getter thinks the src looks like a file path.
@@ -38,6 +43,33 @@ func SourceIsFile(src string) bool {
 	return err == nil && strings.HasPrefix(normalizedUrl, "file") && !SourceIsGit(src)
 }
 
+// forcedGetter matches the getter forced by the prefix of a source url, e.g.
+// git:: in git::https://...
+var forcedGetter = regexp.MustCompile(`^[A-Za-z0-9]+::`)
+
+// SourceIsLocal returns true if the src is fetched with the access of the ec
+// process rather than over the network, i.e. a file path, or a ConfigMap or a
+// Secret in the Kubernetes cluster, see kubernetesSource. Paths that exist on
+// the local filesystem are local regardless of their form, or of the getter
+// forced for them, e.g. git::file:///path/.git.
+func SourceIsLocal(src string) bool {
+	if _, ok := kubernetesSource(src); ok {
+		return true
+	}
+
+	for forcedGetter.MatchString(src) {
+		src = forcedGetter.ReplaceAllString(src, "")
+	}
+
+	lower := strings.ToLower(src)
+	if strings.HasPrefix(lower, "file:") || filepath.IsAbs(src) || strings.HasPrefix(src, ".") || strings.HasPrefix(src, "~") {
+		return true
+	}
+
+	_, err := os.Stat(src)
+	return err == nil
+}
+
 // SourceIsGit returns true if go-getter thinks the src looks like a git url
 func SourceIsGit(src string) bool {
 	normalizedUrl, err := getter.Detect(src, ".", []getter.Detector{
@@ -62,10 +94,10 @@ func GoGetterDownload(ctx context.Context, tmpDir, src string) (string, error) {
 	}
 	configDir, err := c.GetPolicy(ctx, tmpDir, false)
 	if err != nil {
-		log.Debugf("Failed to download policy config from %s", c.Url)
+		log.Debugf("Failed to download policy config from %s", logging.RedactURL(c.Url))
 		return "", err
 	}
-	log.Debugf("Downloaded policy config from %s to %s", c.Url, configDir)
+	log.Debugf("Downloaded policy config from %s to %s", logging.RedactURL(c.Url), configDir)
 
 	// Look for a suitable file to use for the config
 	configFile, err := choosePolicyFile(ctx, configDir)
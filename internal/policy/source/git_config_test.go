@@ -47,6 +47,40 @@ func TestSourceIsFile(t *testing.T) {
 	}
 }
 
+func TestSourceIsLocal(t *testing.T) {
+	dir := t.TempDir()
+
+	tests := []struct {
+		src  string
+		want bool
+	}{
+		{src: "", want: false},
+		{src: "k8s://ns/policy", want: true},
+		{src: "k8s://secret", want: true},
+		{src: "file::/etc/policy", want: true},
+		{src: "file:///etc/policy", want: true},
+		{src: "git::file:///etc/policy/.git", want: true},
+		{src: "git::/etc/policy/.git", want: true},
+		{src: "git::./policy", want: true},
+		{src: "oci::" + dir, want: true},
+		{src: "/etc/policy", want: true},
+		{src: "./policy", want: true},
+		{src: "../policy", want: true},
+		{src: "~/policy", want: true},
+		{src: dir, want: true},
+		{src: "github.com/foo/bar//policy", want: false},
+		{src: "git::https://foo.bar/asdf", want: false},
+		{src: "oci::quay.io/foo/bar:latest", want: false},
+		{src: "quay.io/foo/bar:latest", want: false},
+		{src: "https://foo.bar/policy.tar.gz", want: false},
+		{src: "s3::https://s3.amazonaws.com/bucket/policy", want: false},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, SourceIsLocal(tt.src), "SourceIsLocal(%s) = %v, want %v", tt.src, SourceIsLocal(tt.src), tt.want)
+	}
+}
+
 func TestSourceIsGit(t *testing.T) {
 	tests := []struct {
 		src  string
@@ -0,0 +1,312 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package source
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	gogather "github.com/enterprise-contract/go-gather"
+	"github.com/enterprise-contract/go-gather/metadata"
+	httpMetadata "github.com/enterprise-contract/go-gather/metadata/http"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
+
+	echttp "github.com/enterprise-contract/ec-cli/internal/http"
+	"github.com/enterprise-contract/ec-cli/internal/logging"
+	"github.com/enterprise-contract/ec-cli/internal/utils"
+)
+
+type httpOptionsKey struct{}
+
+// HTTPOptions configures how the sources hosted on HTTP servers, e.g. policy
+// bundles on an artifact server, are fetched.
+type HTTPOptions struct {
+	// Headers to send, keyed by the host of the source, e.g. Authorization
+	Headers map[string]http.Header
+	// CABundle is the path to a PEM file with the certificates of the
+	// certificate authorities trusted in addition to the system ones
+	CABundle string
+}
+
+// ParseHTTPHeaders parses the headers given as <host>=<name>: <value> pairs.
+// Environment variables in the value are expanded, e.g. for a bearer token in
+// "Authorization: Bearer ${TOKEN}".
+func ParseHTTPHeaders(values []string) (map[string]http.Header, error) {
+	headers := map[string]http.Header{}
+	for _, v := range values {
+		host, header, found := strings.Cut(v, "=")
+		if !found || host == "" {
+			return nil, fmt.Errorf("invalid policy source header %q, expected <host>=<name>: <value>", v)
+		}
+
+		name, value, found := strings.Cut(header, ":")
+		name = strings.TrimSpace(name)
+		if !found || name == "" {
+			return nil, fmt.Errorf("invalid policy source header %q, expected <host>=<name>: <value>", v)
+		}
+
+		if _, ok := headers[host]; !ok {
+			headers[host] = http.Header{}
+		}
+		headers[host].Add(name, os.ExpandEnv(strings.TrimSpace(value)))
+	}
+
+	return headers, nil
+}
+
+// WithHTTPOptions returns a context in which the sources hosted on HTTP servers
+// are fetched using the given options.
+func WithHTTPOptions(ctx context.Context, opts HTTPOptions) context.Context {
+	return context.WithValue(ctx, httpOptionsKey{}, opts)
+}
+
+func httpOptionsFrom(ctx context.Context) HTTPOptions {
+	if o, ok := ctx.Value(httpOptionsKey{}).(HTTPOptions); ok {
+		return o
+	}
+	return HTTPOptions{}
+}
+
+// httpSourceURL returns the URL of the file a source hosted on an HTTPS server
+// points to. Sources classified as git repositories, e.g.
+// https://github.com/org/repo, and insecure sources are not HTTP sources.
+func httpSourceURL(sourceUrl string) (*url.URL, bool) {
+	if t, err := gogather.ClassifyURI(sourceUrl); err != nil || t != gogather.HTTPURI {
+		return nil, false
+	}
+
+	rest := strings.TrimPrefix(sourceUrl, "http::")
+	if !strings.Contains(rest, "://") {
+		rest = "https://" + rest
+	}
+
+	u, err := url.Parse(rest)
+	if err != nil || u.Scheme != "https" {
+		return nil, false
+	}
+
+	return u, true
+}
+
+// httpEntry describes the last fetched content of an HTTP source in the
+// persistent cache
+type httpEntry struct {
+	URL  string `json:"url"`
+	ETag string `json:"etag"`
+	File string `json:"file"`
+}
+
+// fetchHTTP downloads the file an HTTP source points to into the destination
+// directory. When the persistent cache is used, the file is stored in it along
+// with its ETag, and on the following fetches it is copied from the cache if
+// the server confirms that it is unchanged.
+func fetchHTTP(ctx context.Context, dest string, u *url.URL, showMsg bool) (metadata.Metadata, error) {
+	sourceUrl := u.String()
+	file := path.Base(u.Path)
+	if file == "." || file == "/" {
+		return nil, fmt.Errorf("the HTTP source %s does not point to a file", logging.RedactURL(sourceUrl))
+	}
+
+	msg := fmt.Sprintf("Downloading %s to %s", logging.RedactURL(sourceUrl), dest)
+	log.Debug(msg)
+	if showMsg {
+		fmt.Println(msg)
+	}
+
+	opts := httpOptionsFrom(ctx)
+	client, err := opts.client()
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, sourceUrl, nil)
+	if err != nil {
+		return nil, err
+	}
+	for name, values := range opts.Headers[u.Host] {
+		for _, v := range values {
+			req.Header.Add(name, v)
+		}
+	}
+
+	afs := utils.FS(ctx)
+	dir, entry, cached := readHTTPEntry(afs, sourceUrl)
+	if cached {
+		req.Header.Set("If-None-Match", entry.ETag)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("unable to fetch %s: %w", logging.RedactURL(sourceUrl), err)
+	}
+	defer resp.Body.Close()
+
+	target := filepath.Join(dest, file)
+	switch {
+	case resp.StatusCode == http.StatusNotModified && cached:
+		log.Debugf("HTTP source %s is unchanged, using the persistent cache", logging.RedactURL(sourceUrl))
+		if err := copyDir(afs, filepath.Join(dir, "content"), dest); err != nil {
+			return nil, err
+		}
+	case resp.StatusCode == http.StatusOK:
+		if err := afs.MkdirAll(dest, 0o755); err != nil {
+			return nil, err
+		}
+		f, err := afs.Create(target)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := io.Copy(f, resp.Body); err != nil {
+			f.Close()
+			return nil, fmt.Errorf("unable to fetch %s: %w", logging.RedactURL(sourceUrl), err)
+		}
+		if err := f.Close(); err != nil {
+			return nil, err
+		}
+
+		if etag := resp.Header.Get("ETag"); etag != "" && dir != "" {
+			if err := writeHTTPEntry(afs, dir, httpEntry{URL: sourceUrl, ETag: etag, File: file}, dest); err != nil {
+				log.Debugf("Unable to store %s in the persistent cache: %v", logging.RedactURL(sourceUrl), err)
+			}
+		}
+	default:
+		return nil, fmt.Errorf("unable to fetch %s: %s", logging.RedactURL(sourceUrl), resp.Status)
+	}
+
+	return httpMetadata.HTTPMetadata{
+		StatusCode:    resp.StatusCode,
+		ContentLength: resp.ContentLength,
+		Destination:   target,
+		Headers:       resp.Header,
+	}, nil
+}
+
+// client returns the HTTP client trusting the CA bundle, if given
+func (o HTTPOptions) client() (*http.Client, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+
+	if o.CABundle != "" {
+		pem, err := os.ReadFile(o.CABundle)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read the CA bundle: %w", err)
+		}
+
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in the CA bundle %s", o.CABundle)
+		}
+
+		transport.TLSClientConfig.RootCAs = pool
+	}
+
+	var rt http.RoundTripper = echttp.NewResumingRoundTripper(echttp.NewRateLimitingRoundTripper(transport))
+	if log.IsLevelEnabled(log.TraceLevel) {
+		rt = echttp.NewTracingRoundTripper(rt)
+	}
+
+	return &http.Client{Transport: rt, CheckRedirect: o.checkRedirect}, nil
+}
+
+// checkRedirect removes the headers configured for the host of the original
+// request when redirected to another host, so that the credentials of one host
+// are not sent to another one
+func (o HTTPOptions) checkRedirect(req *http.Request, via []*http.Request) error {
+	// Same as the default policy of http.Client
+	if len(via) >= 10 {
+		return errors.New("stopped after 10 redirects")
+	}
+
+	if host := via[0].URL.Host; req.URL.Host != host {
+		for name := range o.Headers[host] {
+			req.Header.Del(name)
+		}
+	}
+
+	return nil
+}
+
+// readHTTPEntry returns the directory of the HTTP source in the persistent
+// cache, empty if the persistent cache is not used, and the entry of the
+// source, if the content for it is available
+func readHTTPEntry(afs afero.Fs, sourceUrl string) (string, httpEntry, bool) {
+	if time.Duration(cacheTTL.Load()) <= 0 {
+		return "", httpEntry{}, false
+	}
+
+	root, err := CacheDir()
+	if err != nil {
+		return "", httpEntry{}, false
+	}
+	dir := filepath.Join(root, "http", cacheKey(sourceUrl))
+
+	data, err := afero.ReadFile(afs, filepath.Join(dir, "entry.json"))
+	if err != nil {
+		return dir, httpEntry{}, false
+	}
+
+	var entry httpEntry
+	if err := json.Unmarshal(data, &entry); err != nil || entry.URL != sourceUrl || entry.ETag == "" {
+		return dir, httpEntry{}, false
+	}
+
+	if ok, err := afero.Exists(afs, filepath.Join(dir, "content", entry.File)); err != nil || !ok {
+		return dir, httpEntry{}, false
+	}
+
+	return dir, entry, true
+}
+
+// writeHTTPEntry stores the fetched file of the HTTP source in the persistent
+// cache, replacing any previous entry of the source
+func writeHTTPEntry(afs afero.Fs, dir string, entry httpEntry, src string) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	tmp := fmt.Sprintf("%s.%d", dir, time.Now().UnixNano())
+	if err := copyDir(afs, src, filepath.Join(tmp, "content")); err != nil {
+		_ = afs.RemoveAll(tmp)
+		return err
+	}
+	if err := afero.WriteFile(afs, filepath.Join(tmp, "entry.json"), data, 0o644); err != nil {
+		_ = afs.RemoveAll(tmp)
+		return err
+	}
+
+	if err := afs.RemoveAll(dir); err != nil {
+		_ = afs.RemoveAll(tmp)
+		return err
+	}
+
+	return afs.Rename(tmp, dir)
+}
@@ -0,0 +1,237 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package source
+
+import (
+	"context"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	httpMetadata "github.com/enterprise-contract/go-gather/metadata/http"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/enterprise-contract/ec-cli/internal/utils"
+)
+
+func TestParseHTTPHeaders(t *testing.T) {
+	t.Setenv("TOKEN", "secret")
+
+	headers, err := ParseHTTPHeaders([]string{
+		"artifacts.example.com=Authorization: Bearer ${TOKEN}",
+		"artifacts.example.com=X-Custom: a",
+		"nexus.example.com:8443=X-Custom:b",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]http.Header{
+		"artifacts.example.com":  {"Authorization": {"Bearer secret"}, "X-Custom": {"a"}},
+		"nexus.example.com:8443": {"X-Custom": {"b"}},
+	}, headers)
+
+	for _, invalid := range []string{"Authorization: Bearer x", "=Authorization: Bearer x", "artifacts.example.com=Bearer x", "artifacts.example.com=: x"} {
+		_, err := ParseHTTPHeaders([]string{invalid})
+		assert.ErrorContains(t, err, "expected <host>=<name>: <value>", invalid)
+	}
+}
+
+func TestHTTPSourceURL(t *testing.T) {
+	cases := []struct {
+		source   string
+		expected string
+	}{
+		{source: "https://artifacts.example.com/repository/policy/bundle.tar.gz", expected: "https://artifacts.example.com/repository/policy/bundle.tar.gz"},
+		{source: "http::https://artifacts.example.com/data.json", expected: "https://artifacts.example.com/data.json"},
+		{source: "http::artifacts.example.com/data.json", expected: "https://artifacts.example.com/data.json"},
+		{source: "http://artifacts.example.com/repository/policy/bundle.tar.gz"},
+		{source: "https://github.com/org/repo"},
+		{source: "git::https://artifacts.example.com/repo.git"},
+		{source: "oci::registry.io/policy:latest"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.source, func(t *testing.T) {
+			u, ok := httpSourceURL(c.source)
+			if c.expected == "" {
+				assert.False(t, ok)
+				return
+			}
+			require.True(t, ok)
+			assert.Equal(t, c.expected, u.String())
+		})
+	}
+}
+
+// caBundle writes the certificate of the test server to a CA bundle file
+func caBundle(t *testing.T, server *httptest.Server) string {
+	bundle := filepath.Join(t.TempDir(), "ca.pem")
+	require.NoError(t, os.WriteFile(bundle, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw}), 0o600))
+
+	return bundle
+}
+
+func TestFetchHTTP(t *testing.T) {
+	var authorization []string
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization = append(authorization, r.Header.Get("Authorization"))
+		if r.URL.Path != "/policy/data.json" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte(`{"data": true}`))
+	}))
+	t.Cleanup(server.Close)
+
+	headers, err := ParseHTTPHeaders([]string{server.Listener.Addr().String() + "=Authorization: Bearer token"})
+	require.NoError(t, err)
+
+	fs := afero.NewMemMapFs()
+	ctx := utils.WithFS(context.Background(), fs)
+	ctx = WithHTTPOptions(ctx, HTTPOptions{Headers: headers, CABundle: caBundle(t, server)})
+
+	m, err := Download(ctx, "/work/data", server.URL+"/policy/data.json", false)
+	require.NoError(t, err)
+	assert.Equal(t, "/work/data/data.json", m.(httpMetadata.HTTPMetadata).Destination)
+
+	content, err := afero.ReadFile(fs, "/work/data/data.json")
+	require.NoError(t, err)
+	assert.Equal(t, `{"data": true}`, string(content))
+
+	_, err = Download(ctx, "/work/missing", server.URL+"/policy/missing.json", false)
+	assert.ErrorContains(t, err, "404 Not Found")
+
+	assert.Equal(t, []string{"Bearer token", "Bearer token"}, authorization)
+}
+
+func TestFetchHTTPUntrusted(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	t.Cleanup(server.Close)
+
+	ctx := utils.WithFS(context.Background(), afero.NewMemMapFs())
+
+	_, err := Download(ctx, "/work/data", server.URL+"/data.json", false)
+	assert.ErrorContains(t, err, "certificate")
+}
+
+func TestFetchHTTPInvalidCABundle(t *testing.T) {
+	bundle := filepath.Join(t.TempDir(), "ca.pem")
+	require.NoError(t, os.WriteFile(bundle, []byte("not a certificate"), 0o600))
+
+	ctx := utils.WithFS(context.Background(), afero.NewMemMapFs())
+	ctx = WithHTTPOptions(ctx, HTTPOptions{CABundle: bundle})
+
+	_, err := Download(ctx, "/work/data", "https://artifacts.example.com/data.json", false)
+	assert.ErrorContains(t, err, "no certificates found in the CA bundle")
+}
+
+func TestFetchHTTPConditional(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", "/cache")
+	t.Cleanup(func() { SetCacheTTL(0) })
+	SetCacheTTL(time.Hour)
+
+	var served, revalidated int
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			revalidated++
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		served++
+		w.Header().Set("ETag", `"v1"`)
+		_, _ = w.Write([]byte(`{"data": true}`))
+	}))
+	t.Cleanup(server.Close)
+
+	fs := afero.NewMemMapFs()
+	ctx := utils.WithFS(context.Background(), fs)
+	ctx = WithHTTPOptions(ctx, HTTPOptions{CABundle: caBundle(t, server)})
+
+	for _, dest := range []string{"/work/1", "/work/2"} {
+		_, err := Download(ctx, dest, server.URL+"/data.json", false)
+		require.NoError(t, err)
+
+		content, err := afero.ReadFile(fs, filepath.Join(dest, "data.json"))
+		require.NoError(t, err)
+		assert.Equal(t, `{"data": true}`, string(content))
+	}
+
+	assert.Equal(t, 1, served)
+	assert.Equal(t, 1, revalidated)
+}
+
+func TestFetchHTTPRedirect(t *testing.T) {
+	var headers []http.Header
+	other := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		headers = append(headers, r.Header.Clone())
+		_, _ = w.Write([]byte(`{"data": true}`))
+	}))
+	t.Cleanup(other.Close)
+
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		headers = append(headers, r.Header.Clone())
+		switch r.URL.Path {
+		case "/same/data.json":
+			http.Redirect(w, r, "/policy/data.json", http.StatusFound)
+		case "/other/data.json":
+			http.Redirect(w, r, other.URL+"/policy/data.json", http.StatusFound)
+		default:
+			_, _ = w.Write([]byte(`{"data": true}`))
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	configured, err := ParseHTTPHeaders([]string{
+		server.Listener.Addr().String() + "=Authorization: Bearer token",
+		server.Listener.Addr().String() + "=X-Api-Key: key",
+	})
+	require.NoError(t, err)
+
+	// The test servers share the same certificate
+	ctx := utils.WithFS(context.Background(), afero.NewMemMapFs())
+	ctx = WithHTTPOptions(ctx, HTTPOptions{Headers: configured, CABundle: caBundle(t, server)})
+
+	t.Run("same host", func(t *testing.T) {
+		headers = nil
+		_, err := Download(ctx, "/work/same", server.URL+"/same/data.json", false)
+		require.NoError(t, err)
+
+		require.Len(t, headers, 2)
+		for _, h := range headers {
+			assert.Equal(t, "Bearer token", h.Get("Authorization"))
+			assert.Equal(t, "key", h.Get("X-Api-Key"))
+		}
+	})
+
+	t.Run("other host", func(t *testing.T) {
+		headers = nil
+		_, err := Download(ctx, "/work/other", server.URL+"/other/data.json", false)
+		require.NoError(t, err)
+
+		require.Len(t, headers, 2)
+		assert.Equal(t, "Bearer token", headers[0].Get("Authorization"))
+		assert.Equal(t, "key", headers[0].Get("X-Api-Key"))
+		assert.Empty(t, headers[1].Get("Authorization"))
+		assert.Empty(t, headers[1].Get("X-Api-Key"))
+	})
+}
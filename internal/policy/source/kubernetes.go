@@ -0,0 +1,76 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package source
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/enterprise-contract/go-gather/metadata"
+	fileMetadata "github.com/enterprise-contract/go-gather/metadata/file"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
+
+	"github.com/enterprise-contract/ec-cli/internal/kubernetes"
+	"github.com/enterprise-contract/ec-cli/internal/logging"
+	"github.com/enterprise-contract/ec-cli/internal/utils"
+)
+
+// kubernetesSource returns the reference of the ConfigMap or Secret of a
+// source given as k8s://[<namespace>/]<name>
+func kubernetesSource(sourceUrl string) (string, bool) {
+	return strings.CutPrefix(sourceUrl, "k8s://")
+}
+
+// fetchKubernetes writes the files held by the ConfigMap, or the Secret, of
+// the source into the destination directory.
+func fetchKubernetes(ctx context.Context, dest, sourceUrl, ref string, showMsg bool) (metadata.Metadata, error) {
+	msg := fmt.Sprintf("Downloading %s to %s", logging.RedactURL(sourceUrl), dest)
+	log.Debug(msg)
+	if showMsg {
+		fmt.Println(msg)
+	}
+
+	client, err := kubernetes.NewClient(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("unable to fetch %s: %w", logging.RedactURL(sourceUrl), err)
+	}
+
+	files, err := client.FetchFiles(ctx, ref)
+	if err != nil {
+		return nil, fmt.Errorf("unable to fetch %s: %w", logging.RedactURL(sourceUrl), err)
+	}
+
+	fs := utils.FS(ctx)
+	if err := fs.MkdirAll(dest, 0o755); err != nil {
+		return nil, err
+	}
+
+	for name, content := range files {
+		if !filepath.IsLocal(name) || strings.ContainsRune(name, filepath.Separator) {
+			return nil, fmt.Errorf("unable to fetch %s: the key %q is not a file name", logging.RedactURL(sourceUrl), name)
+		}
+
+		if err := afero.WriteFile(fs, filepath.Join(dest, name), content, 0o644); err != nil {
+			return nil, err
+		}
+	}
+
+	return &fileMetadata.FileMetadata{Path: dest}, nil
+}
@@ -0,0 +1,93 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package source
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	ecc "github.com/enterprise-contract/enterprise-contract-controller/api/v1alpha1"
+	app "github.com/konflux-ci/application-api/api/v1alpha1"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/enterprise-contract/ec-cli/internal/kubernetes"
+	"github.com/enterprise-contract/ec-cli/internal/utils"
+)
+
+type fakeKubernetesClient struct {
+	files map[string]map[string][]byte
+}
+
+func (c fakeKubernetesClient) FetchEnterpriseContractPolicy(context.Context, string) (*ecc.EnterpriseContractPolicy, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (c fakeKubernetesClient) FetchSnapshot(context.Context, string) (*app.Snapshot, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (c fakeKubernetesClient) FetchFiles(_ context.Context, ref string) (map[string][]byte, error) {
+	if files, ok := c.files[ref]; ok {
+		return files, nil
+	}
+	return nil, errors.New("not found")
+}
+
+func TestFetchKubernetes(t *testing.T) {
+	client := fakeKubernetesClient{files: map[string]map[string][]byte{
+		"policies/release": {
+			"main.rego":     []byte("package main"),
+			"rule_data.yml": []byte("rule_data: {}"),
+		},
+		"policies/invalid": {
+			"../main.rego": []byte("package main"),
+		},
+	}}
+
+	t.Run("files", func(t *testing.T) {
+		fs := afero.NewMemMapFs()
+		ctx := utils.WithFS(kubernetes.WithClient(context.Background(), client), fs)
+
+		_, err := Download(ctx, "/work/policy", "k8s://policies/release", false)
+		require.NoError(t, err)
+
+		for file, expected := range map[string]string{"/work/policy/main.rego": "package main", "/work/policy/rule_data.yml": "rule_data: {}"} {
+			content, err := afero.ReadFile(fs, file)
+			require.NoError(t, err)
+			assert.Equal(t, expected, string(content))
+		}
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		ctx := utils.WithFS(kubernetes.WithClient(context.Background(), client), afero.NewMemMapFs())
+
+		_, err := Download(ctx, "/work/policy", "k8s://policies/missing", false)
+		assert.EqualError(t, err, "unable to fetch k8s://policies/REDACTED: not found")
+	})
+
+	t.Run("invalid file name", func(t *testing.T) {
+		ctx := utils.WithFS(kubernetes.WithClient(context.Background(), client), afero.NewMemMapFs())
+
+		_, err := Download(ctx, "/work/policy", "k8s://policies/invalid", false)
+		assert.EqualError(t, err, `unable to fetch k8s://policies/REDACTED: the key "../main.rego" is not a file name`)
+	})
+}
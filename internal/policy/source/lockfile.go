@@ -0,0 +1,293 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package source
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/enterprise-contract/go-gather/metadata"
+	"github.com/spf13/afero"
+	"sigs.k8s.io/yaml"
+
+	"github.com/enterprise-contract/ec-cli/internal/logging"
+	"github.com/enterprise-contract/ec-cli/internal/utils"
+)
+
+type lockfileKey struct{}
+
+var (
+	ErrSourceNotLocked = errors.New("source is not in the lockfile")
+	ErrDigestMismatch  = errors.New("digest of the source differs from the lockfile")
+)
+
+// LockedSource pins a source URL to the digest of its content.
+type LockedSource struct {
+	URL    string `json:"url"`
+	Digest string `json:"digest"`
+	// Revision is the commit, or the image digest, the source was fetched at.
+	// OCI sources are fetched by the image digest.
+	Revision string `json:"revision,omitempty"`
+	// Location, if set, is fetched instead of the URL, e.g. a mirror or a
+	// local copy of the source
+	Location string `json:"location,omitempty"`
+}
+
+// Lockfile records the digests of the content of the sources. When set in the
+// context only the sources in the lockfile can be fetched, and fetching fails
+// if the content of a source differs from the recorded digest.
+type Lockfile struct {
+	Sources []LockedSource `json:"sources"`
+	// update records the digests of the fetched sources instead of verifying
+	// them
+	update bool
+	mu     sync.Mutex
+}
+
+// LoadLockfile reads the lockfile from the given path.
+func LoadLockfile(fs afero.Fs, path string) (*Lockfile, error) {
+	content, err := afero.ReadFile(fs, path)
+	if err != nil {
+		return nil, err
+	}
+
+	var l Lockfile
+	if err := yaml.Unmarshal(content, &l); err != nil {
+		return nil, fmt.Errorf("reading the lockfile %s: %w", path, err)
+	}
+
+	for i, s := range l.Sources {
+		if s.URL == "" || s.Digest == "" {
+			return nil, fmt.Errorf("invalid source at index %d in the lockfile %s, url and digest are required", i, path)
+		}
+	}
+
+	return &l, nil
+}
+
+// NewLockfileUpdate returns an empty lockfile recording the digests of all
+// sources fetched, to be written using Write.
+func NewLockfileUpdate() *Lockfile {
+	return &Lockfile{update: true}
+}
+
+// Write writes the lockfile to the given path, with the sources ordered by
+// their URL.
+func (l *Lockfile) Write(fs afero.Fs, path string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	slices.SortFunc(l.Sources, func(a, b LockedSource) int {
+		return strings.Compare(a.URL, b.URL)
+	})
+
+	content, err := yaml.Marshal(l)
+	if err != nil {
+		return err
+	}
+
+	return afero.WriteFile(fs, path, content, 0644)
+}
+
+// WithLockfile returns a context in which the sources are fetched as pinned by
+// the lockfile.
+func WithLockfile(ctx context.Context, l *Lockfile) context.Context {
+	return context.WithValue(ctx, lockfileKey{}, l)
+}
+
+func lockfileFrom(ctx context.Context) *Lockfile {
+	if l, ok := ctx.Value(lockfileKey{}).(*Lockfile); ok {
+		return l
+	}
+	return nil
+}
+
+// Find returns the locked source with the given URL.
+func (l *Lockfile) Find(url string) (LockedSource, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	i := slices.IndexFunc(l.Sources, func(s LockedSource) bool {
+		return s.URL == url
+	})
+	if i == -1 {
+		return LockedSource{}, false
+	}
+
+	return l.Sources[i], true
+}
+
+func (l *Lockfile) record(url, digest, revision string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if i := slices.IndexFunc(l.Sources, func(s LockedSource) bool { return s.URL == url }); i != -1 {
+		l.Sources[i].Digest = digest
+		l.Sources[i].Revision = revision
+		return
+	}
+
+	l.Sources = append(l.Sources, LockedSource{URL: url, Digest: digest, Revision: revision})
+}
+
+// pinnedURL returns the URL of an OCI source pinned to the given image digest,
+// keeping any path within the artifact. Other sources, and sources already
+// pinned by digest, are returned as is.
+func pinnedURL(sourceUrl, digest string) string {
+	var prefix string
+	switch {
+	case strings.HasPrefix(sourceUrl, "oci::"):
+		prefix = "oci::"
+	case strings.HasPrefix(sourceUrl, "oci://"):
+		prefix = "oci://"
+	default:
+		return sourceUrl
+	}
+
+	rest := strings.TrimPrefix(sourceUrl, prefix)
+	if scheme, r, found := strings.Cut(rest, "://"); found {
+		prefix, rest = prefix+scheme+"://", r
+	}
+
+	ref, subpath, found := strings.Cut(rest, "//")
+	if strings.Contains(ref, "@") || !strings.HasPrefix(digest, "sha256:") {
+		return sourceUrl
+	}
+
+	pinned := prefix + ref + "@" + digest
+	if found {
+		pinned += "//" + subpath
+	}
+
+	return pinned
+}
+
+// locked wraps the download function to fetch the sources as pinned by the
+// lockfile, or to record their digests when updating the lockfile.
+func (l *Lockfile) locked(ctx context.Context, dl func(string, string) (metadata.Metadata, error)) func(string, string) (metadata.Metadata, error) {
+	return func(sourceUrl string, dest string) (metadata.Metadata, error) {
+		fs := utils.FS(ctx)
+
+		if l.update {
+			m, err := dl(sourceUrl, dest)
+			if err != nil {
+				return m, err
+			}
+
+			digest, err := ContentDigest(fs, dest)
+			if err != nil {
+				return m, err
+			}
+			_, revision := sourceRevision(m)
+			l.record(sourceUrl, digest, revision)
+
+			return m, nil
+		}
+
+		locked, ok := l.Find(sourceUrl)
+		if !ok {
+			return nil, fmt.Errorf("%w: %s", ErrSourceNotLocked, logging.RedactURL(sourceUrl))
+		}
+
+		from := sourceUrl
+		if locked.Location != "" {
+			from = locked.Location
+		} else if locked.Revision != "" {
+			from = pinnedURL(sourceUrl, locked.Revision)
+		}
+
+		m, err := dl(from, dest)
+		if err != nil {
+			return m, err
+		}
+
+		digest, err := ContentDigest(fs, dest)
+		if err != nil {
+			return m, err
+		}
+
+		if digest != locked.Digest {
+			return nil, fmt.Errorf("%w: %s, expected %s, got %s", ErrDigestMismatch, logging.RedactURL(sourceUrl), locked.Digest, digest)
+		}
+
+		return m, nil
+	}
+}
+
+// vcsMetadata are the names of the directories holding the metadata of version
+// control systems. They are not part of the content of a source, e.g. the git
+// index and pack files differ with each clone of the same commit.
+var vcsMetadata = []string{".git", ".hg", ".svn", ".bzr"}
+
+// isVCSMetadata returns true if the file is a directory holding the metadata
+// of a version control system
+func isVCSMetadata(info fs.FileInfo) bool {
+	return info.IsDir() && slices.Contains(vcsMetadata, info.Name())
+}
+
+// ContentDigest returns the digest of the content of the directory, or file,
+// computed over the paths and the contents of all files in it. The metadata of
+// version control systems is not part of the content.
+func ContentDigest(afs afero.Fs, path string) (string, error) {
+	h := sha256.New()
+
+	err := afero.Walk(afs, path, func(p string, info fs.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if isVCSMetadata(info) {
+			return filepath.SkipDir
+		}
+		if info.IsDir() {
+			return nil
+		}
+
+		rel, err := filepath.Rel(path, p)
+		if err != nil {
+			return err
+		}
+
+		f, err := afs.Open(p)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		fh := sha256.New()
+		if _, err := io.Copy(fh, f); err != nil {
+			return err
+		}
+
+		fmt.Fprintf(h, "%s %x\n", filepath.ToSlash(rel), fh.Sum(nil))
+
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return "sha256:" + hex.EncodeToString(h.Sum(nil)), nil
+}
@@ -0,0 +1,231 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package source
+
+import (
+	"context"
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/enterprise-contract/go-gather/metadata"
+	ociMetadata "github.com/enterprise-contract/go-gather/metadata/oci"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/enterprise-contract/ec-cli/internal/utils"
+)
+
+// contentDownloader writes the content for the source URL into the
+// destination
+type contentDownloader struct {
+	fs      afero.Fs
+	content map[string]string
+	fetched []string
+}
+
+func (d *contentDownloader) Download(_ context.Context, dest string, sourceUrl string, _ bool) (metadata.Metadata, error) {
+	d.fetched = append(d.fetched, sourceUrl)
+	if err := d.fs.MkdirAll(dest, 0755); err != nil {
+		return nil, err
+	}
+	return nil, afero.WriteFile(d.fs, path.Join(dest, "policy.rego"), []byte(d.content[sourceUrl]), 0400)
+}
+
+func TestContentDigest(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "/a/policy.rego", []byte("package a"), 0400))
+	require.NoError(t, afero.WriteFile(fs, "/a/nested/data.json", []byte("{}"), 0400))
+	require.NoError(t, afero.WriteFile(fs, "/b/policy.rego", []byte("package a"), 0400))
+	require.NoError(t, afero.WriteFile(fs, "/b/nested/data.json", []byte("{}"), 0400))
+	require.NoError(t, afero.WriteFile(fs, "/c/policy.rego", []byte("package c"), 0400))
+	require.NoError(t, afero.WriteFile(fs, "/c/nested/data.json", []byte("{}"), 0400))
+
+	a, err := ContentDigest(fs, "/a")
+	require.NoError(t, err)
+	assert.Regexp(t, "^sha256:[0-9a-f]{64}$", a)
+
+	b, err := ContentDigest(fs, "/b")
+	require.NoError(t, err)
+	assert.Equal(t, a, b)
+
+	c, err := ContentDigest(fs, "/c")
+	require.NoError(t, err)
+	assert.NotEqual(t, a, c)
+}
+
+func TestContentDigestGitClone(t *testing.T) {
+	repo := t.TempDir()
+	r, err := git.PlainInit(repo, false)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(path.Join(repo, "policy.rego"), []byte("package policy"), 0o644))
+	w, err := r.Worktree()
+	require.NoError(t, err)
+	_, err = w.Add("policy.rego")
+	require.NoError(t, err)
+	_, err = w.Commit("policy", &git.CommitOptions{Author: &object.Signature{Name: "ec", Email: "ec@example.com", When: time.Now()}})
+	require.NoError(t, err)
+
+	clone := func() string {
+		dir := t.TempDir()
+		_, err := git.PlainClone(dir, false, &git.CloneOptions{URL: repo})
+		require.NoError(t, err)
+		return dir
+	}
+
+	fs := afero.NewOsFs()
+	first, err := ContentDigest(fs, clone())
+	require.NoError(t, err)
+	second := clone()
+	digest, err := ContentDigest(fs, second)
+	require.NoError(t, err)
+	assert.Equal(t, first, digest)
+
+	// Only the files of the repository are copied
+	copied := path.Join(t.TempDir(), "copied")
+	require.NoError(t, copyDir(fs, second, copied))
+	assert.NoDirExists(t, path.Join(copied, ".git"))
+	assert.FileExists(t, path.Join(copied, "policy.rego"))
+	digest, err = ContentDigest(fs, copied)
+	require.NoError(t, err)
+	assert.Equal(t, first, digest)
+}
+
+func TestLockfile(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	dl := &contentDownloader{fs: fs, content: map[string]string{
+		"oci::registry.io/lock/policy:latest": "package policy",
+		"oci::mirror.io/lock/policy:latest":   "package policy",
+		"oci::registry.io/lock/changed:1":     "package changed",
+	}}
+
+	ctx := utils.WithFS(context.Background(), fs)
+	ctx = context.WithValue(ctx, DownloaderFuncKey, dl)
+
+	// record the digests
+	update := NewLockfileUpdate()
+	for _, url := range []string{"oci::registry.io/lock/policy:latest", "oci::registry.io/lock/changed:1"} {
+		p := PolicyUrl{Url: url, Kind: PolicyKind}
+		_, err := p.GetPolicy(WithLockfile(ctx, update), "/tmp/ec-work-update", false)
+		require.NoError(t, err)
+	}
+	require.NoError(t, update.Write(fs, "ec.lock"))
+
+	lockfile, err := LoadLockfile(fs, "ec.lock")
+	require.NoError(t, err)
+	require.Len(t, lockfile.Sources, 2)
+	assert.Equal(t, "oci::registry.io/lock/changed:1", lockfile.Sources[0].URL)
+	assert.Equal(t, "oci::registry.io/lock/policy:latest", lockfile.Sources[1].URL)
+
+	// fetch from a mirror
+	lockfile.Sources[1].Location = "oci::mirror.io/lock/policy:latest"
+	// the content changed since the lockfile was written
+	dl.content["oci::registry.io/lock/changed:1"] = "package tampered"
+	dl.fetched = nil
+	ctx = WithLockfile(ctx, lockfile)
+
+	p := PolicyUrl{Url: "oci::registry.io/lock/policy:latest", Kind: PolicyKind}
+	_, err = p.GetPolicy(ctx, "/tmp/ec-work-verify", false)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"oci::mirror.io/lock/policy:latest"}, dl.fetched)
+
+	p = PolicyUrl{Url: "oci::registry.io/lock/changed:1", Kind: PolicyKind}
+	_, err = p.GetPolicy(ctx, "/tmp/ec-work-verify", false)
+	assert.ErrorIs(t, err, ErrDigestMismatch)
+	assert.ErrorContains(t, err, "expected "+lockfile.Sources[0].Digest+", got sha256:")
+
+	p = PolicyUrl{Url: "oci::registry.io/lock/unknown:latest", Kind: PolicyKind}
+	_, err = p.GetPolicy(ctx, "/tmp/ec-work-verify", false)
+	assert.ErrorIs(t, err, ErrSourceNotLocked)
+}
+
+// ociDownloader writes a policy into the destination and reports the digest of
+// the OCI artifact fetched
+type ociDownloader struct {
+	fs      afero.Fs
+	digest  string
+	fetched []string
+}
+
+func (d *ociDownloader) Download(_ context.Context, dest string, sourceUrl string, _ bool) (metadata.Metadata, error) {
+	d.fetched = append(d.fetched, sourceUrl)
+	if err := d.fs.MkdirAll(dest, 0755); err != nil {
+		return nil, err
+	}
+	return &ociMetadata.OCIMetadata{Digest: d.digest}, afero.WriteFile(d.fs, path.Join(dest, "policy.rego"), []byte("package policy"), 0400)
+}
+
+func TestLockfileRevision(t *testing.T) {
+	const digest = "sha256:4e388ab32b10dc8dbc7e28144f552830adc74787c1e2c0824032078a79f227fb"
+	fs := afero.NewMemMapFs()
+	dl := &ociDownloader{fs: fs, digest: digest}
+
+	ctx := utils.WithFS(context.Background(), fs)
+	ctx = context.WithValue(ctx, DownloaderFuncKey, dl)
+
+	update := NewLockfileUpdate()
+	p := PolicyUrl{Url: "oci::registry.io/revision/policy:latest//policy", Kind: PolicyKind}
+	_, err := p.GetPolicy(WithLockfile(ctx, update), "/tmp/ec-work-update", false)
+	require.NoError(t, err)
+	require.Len(t, update.Sources, 1)
+	assert.Equal(t, digest, update.Sources[0].Revision)
+	require.NoError(t, update.Write(fs, "ec.lock"))
+	lockfile, err := LoadLockfile(fs, "ec.lock")
+	require.NoError(t, err)
+
+	dl.fetched = nil
+	p = PolicyUrl{Url: "oci::registry.io/revision/policy:latest//policy", Kind: PolicyKind}
+	_, err = p.GetPolicy(WithLockfile(ctx, lockfile), "/tmp/ec-work-verify", false)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"oci::registry.io/revision/policy:latest@" + digest + "//policy"}, dl.fetched)
+}
+
+func TestPinnedURL(t *testing.T) {
+	const digest = "sha256:4e388ab32b10dc8dbc7e28144f552830adc74787c1e2c0824032078a79f227fb"
+
+	cases := []struct {
+		url      string
+		revision string
+		expected string
+	}{
+		{url: "oci::registry.io/policy:latest", revision: digest, expected: "oci::registry.io/policy:latest@" + digest},
+		{url: "oci://registry.io/policy", revision: digest, expected: "oci://registry.io/policy@" + digest},
+		{url: "oci::https://registry.io/policy:1//policy/lib", revision: digest, expected: "oci::https://registry.io/policy:1@" + digest + "//policy/lib"},
+		{url: "oci::registry.io/policy@" + digest, revision: digest, expected: "oci::registry.io/policy@" + digest},
+		{url: "git::https://example.com/policy.git//policy", revision: "0123456789abcdef0123456789abcdef01234567", expected: "git::https://example.com/policy.git//policy"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.url, func(t *testing.T) {
+			assert.Equal(t, c.expected, pinnedURL(c.url, c.revision))
+		})
+	}
+}
+
+func TestLoadLockfileInvalid(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "ec.lock", []byte("sources:\n- url: oci::registry.io/policy:latest\n"), 0400))
+
+	_, err := LoadLockfile(fs, "ec.lock")
+	assert.EqualError(t, err, "invalid source at index 0 in the lockfile ec.lock, url and digest are required")
+}
@@ -0,0 +1,258 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package source
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"strings"
+
+	"github.com/enterprise-contract/go-gather/metadata"
+	"github.com/open-policy-agent/opa/bundle"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
+
+	"github.com/enterprise-contract/ec-cli/internal/logging"
+	"github.com/enterprise-contract/ec-cli/internal/utils"
+)
+
+// opaBundleKeyID is the identifier of the signing key of an OPA bundle in the
+// verification configuration, the key given for the source is used regardless
+// of the key identifier in the signature
+const opaBundleKeyID = "ec"
+
+var ErrInvalidOPABundle = errors.New("invalid OPA bundle")
+
+// isOPABundle returns true if the source URL points to an OPA bundle, i.e. a
+// gzipped tarball, e.g. https://example.com/bundle.tar.gz.
+func isOPABundle(sourceUrl string) bool {
+	sourceUrl, _ = splitChecksum(sourceUrl)
+	sourceUrl, _, _ = strings.Cut(sourceUrl, "?")
+
+	return strings.HasSuffix(sourceUrl, ".tar.gz") || strings.HasSuffix(sourceUrl, ".tgz")
+}
+
+// opaBundles wraps the download function to extract the OPA bundles the
+// sources point to. The bundle is read, and when a signing key is given for
+// the source, its signature verified, before its modules and data are written
+// to the destination directory. The roots of the bundle manifest are enforced
+// when reading the bundle.
+func opaBundles(ctx context.Context, dl func(string, string) (metadata.Metadata, error)) func(string, string) (metadata.Metadata, error) {
+	return func(sourceUrl string, dest string) (metadata.Metadata, error) {
+		if !isOPABundle(sourceUrl) {
+			return dl(sourceUrl, dest)
+		}
+
+		afs := utils.FS(ctx)
+
+		download := dest + ".bundle"
+		defer func() {
+			_ = afs.RemoveAll(download)
+		}()
+
+		m, err := dl(sourceUrl, download)
+		if err != nil {
+			return m, err
+		}
+
+		archive, err := bundleArchive(afs, download)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s: %w", ErrInvalidOPABundle, logging.RedactURL(sourceUrl), err)
+		}
+
+		var config *bundle.VerificationConfig
+		if key, ok := sourceKeysFrom(ctx)[sourceUrl]; ok {
+			if config, err = bundleVerificationConfig(afs, key); err != nil {
+				return nil, err
+			}
+		}
+
+		b, err := readOPABundle(afs, archive, config)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s: %w", ErrInvalidOPABundle, logging.RedactURL(sourceUrl), err)
+		}
+		if config != nil {
+			log.Debugf("Verified the signature of the OPA bundle %s", logging.RedactURL(sourceUrl))
+		}
+
+		if err := writeOPABundle(afs, b, dest); err != nil {
+			return nil, err
+		}
+
+		return m, nil
+	}
+}
+
+// bundleArchive returns the path of the downloaded bundle archive. Depending
+// on the kind of the source, the download is either the archive itself or a
+// directory holding it.
+func bundleArchive(afs afero.Fs, download string) (string, error) {
+	info, err := afs.Stat(download)
+	if err != nil {
+		return "", err
+	}
+	if !info.IsDir() {
+		return download, nil
+	}
+
+	var archives []string
+	err = afero.Walk(afs, download, func(path string, info fs.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() && isOPABundle(path) {
+			archives = append(archives, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+
+	if len(archives) != 1 {
+		return "", fmt.Errorf("expected one bundle archive, found %d", len(archives))
+	}
+
+	return archives[0], nil
+}
+
+// readOPABundle reads the bundle from the archive, verifying its signature
+// with the given configuration, if any.
+func readOPABundle(afs afero.Fs, archive string, config *bundle.VerificationConfig) (bundle.Bundle, error) {
+	f, err := afs.Open(archive)
+	if err != nil {
+		return bundle.Bundle{}, err
+	}
+	defer f.Close()
+
+	r := bundle.NewReader(f)
+	if config != nil {
+		r = r.WithBundleVerificationConfig(config)
+	} else {
+		r = r.WithSkipBundleVerification(true)
+	}
+
+	return r.Read()
+}
+
+// writeOPABundle writes the Rego modules of the bundle, at their path within
+// the bundle, and its data, as data.json, to the destination directory. The
+// WebAssembly modules of a bundle built for the wasm target are written, with
+// the manifest describing their entrypoints, at their path within the bundle
+// as well.
+func writeOPABundle(afs afero.Fs, b bundle.Bundle, dest string) error {
+	if err := afs.MkdirAll(dest, 0o755); err != nil {
+		return err
+	}
+
+	write := func(p string, content []byte) error {
+		path := filepath.Join(dest, filepath.FromSlash(strings.TrimPrefix(p, "/")))
+		if err := afs.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			return err
+		}
+		return afero.WriteFile(afs, path, content, 0o644)
+	}
+
+	for _, m := range b.Modules {
+		if err := write(m.Path, m.Raw); err != nil {
+			return err
+		}
+	}
+
+	if len(b.WasmModules) > 0 {
+		for _, m := range b.WasmModules {
+			if err := write(m.Path, m.Raw); err != nil {
+				return err
+			}
+		}
+
+		manifest, err := json.Marshal(b.Manifest)
+		if err != nil {
+			return err
+		}
+		if err := write(bundle.ManifestExt, manifest); err != nil {
+			return err
+		}
+	}
+
+	if len(b.Data) == 0 {
+		return nil
+	}
+
+	data, err := json.Marshal(b.Data)
+	if err != nil {
+		return err
+	}
+
+	return write("data.json", data)
+}
+
+// bundleVerificationConfig returns the configuration to verify the signature
+// of a bundle with the public key, given as a path to a PEM file or in PEM. The
+// signing algorithm is derived from the key, RSA keys are expected to sign with
+// RS256, and ECDSA keys with the algorithm matching their curve.
+func bundleVerificationConfig(afs afero.Fs, key string) (*bundle.VerificationConfig, error) {
+	if !strings.Contains(key, "-----BEGIN PUBLIC KEY-----") {
+		content, err := afero.ReadFile(afs, key)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read the OPA bundle signing key: %w", err)
+		}
+		key = string(content)
+	}
+
+	block, _ := pem.Decode([]byte(key))
+	if block == nil {
+		return nil, errors.New("the OPA bundle signing key is not PEM encoded")
+	}
+	pub, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse the OPA bundle signing key: %w", err)
+	}
+
+	var algorithm string
+	switch k := pub.(type) {
+	case *rsa.PublicKey:
+		algorithm = "RS256"
+	case *ecdsa.PublicKey:
+		switch k.Curve {
+		case elliptic.P256():
+			algorithm = "ES256"
+		case elliptic.P384():
+			algorithm = "ES384"
+		case elliptic.P521():
+			algorithm = "ES512"
+		}
+	}
+	if algorithm == "" {
+		return nil, fmt.Errorf("unsupported OPA bundle signing key of type %T", pub)
+	}
+
+	keys := map[string]*bundle.KeyConfig{
+		opaBundleKeyID: {Key: key, Algorithm: algorithm},
+	}
+
+	return bundle.NewVerificationConfig(keys, opaBundleKeyID, "", nil), nil
+}
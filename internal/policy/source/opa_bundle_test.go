@@ -0,0 +1,243 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package source
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"path/filepath"
+	"testing"
+
+	"github.com/enterprise-contract/go-gather/metadata"
+	"github.com/open-policy-agent/opa/ast"
+	"github.com/open-policy-agent/opa/bundle"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/enterprise-contract/ec-cli/internal/utils"
+)
+
+func TestIsOPABundle(t *testing.T) {
+	assert.True(t, isOPABundle("https://example.com/bundle.tar.gz"))
+	assert.True(t, isOPABundle("https://example.com/bundle.tgz?checksum=sha256:abc"))
+	assert.True(t, isOPABundle("/path/to/bundle.tar.gz"))
+	assert.False(t, isOPABundle("https://example.com/policy.rego"))
+	assert.False(t, isOPABundle("oci::registry.io/policy:latest"))
+	assert.False(t, isOPABundle("github.com/org/repo//policy?ref=main"))
+}
+
+// testKey returns a new RSA key pair, PEM encoded
+func testKey(t *testing.T) (string, string) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	private := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+
+	der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	require.NoError(t, err)
+	public := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})
+
+	return string(private), string(public)
+}
+
+// testBundle returns the archive of an OPA bundle with the given module,
+// signed with the private key, if given
+func testBundle(t *testing.T, module string, privateKey string) []byte {
+	b := bundle.Bundle{
+		Manifest: bundle.Manifest{Roots: &[]string{"release"}},
+		Modules: []bundle.ModuleFile{{
+			URL:    "/release/policy.rego",
+			Path:   "/release/policy.rego",
+			Raw:    []byte(module),
+			Parsed: ast.MustParseModule(module),
+		}},
+		Data: map[string]any{"release": map[string]any{"allowed": []any{"a", "b"}}},
+	}
+
+	if privateKey != "" {
+		require.NoError(t, b.GenerateSignature(bundle.NewSigningConfig(privateKey, "RS256", ""), "key", false))
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, bundle.NewWriter(&buf).Write(b))
+
+	return buf.Bytes()
+}
+
+func TestOPABundles(t *testing.T) {
+	private, public := testKey(t)
+	otherPrivate, _ := testKey(t)
+
+	module := "package release\n\nimport rego.v1\n\ndeny contains \"denied\" if false\n"
+
+	cases := []struct {
+		name     string
+		archive  []byte
+		key      string
+		expected string
+	}{
+		{
+			name:    "unsigned",
+			archive: testBundle(t, module, ""),
+		},
+		{
+			name:    "signed",
+			archive: testBundle(t, module, private),
+			key:     public,
+		},
+		{
+			name:     "signed with another key",
+			archive:  testBundle(t, module, otherPrivate),
+			key:      public,
+			expected: "invalid OPA bundle: https://example.com/REDACTED: failed to verify message",
+		},
+		{
+			name:     "not signed",
+			archive:  testBundle(t, module, ""),
+			key:      public,
+			expected: "invalid OPA bundle: https://example.com/REDACTED: bundle missing .signatures.json file",
+		},
+		{
+			name:     "outside of the roots",
+			archive:  testBundle(t, "package pipeline\n\nimport rego.v1\n\ndeny contains \"denied\" if false\n", ""),
+			expected: "invalid OPA bundle: https://example.com/REDACTED: manifest roots [release] do not permit 'package pipeline'",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			fs := afero.NewMemMapFs()
+			ctx := utils.WithFS(context.Background(), fs)
+			if c.key != "" {
+				ctx = WithSourceKeys(ctx, SourceKeys{"https://example.com/bundle.tar.gz": c.key})
+			}
+
+			dl := opaBundles(ctx, func(sourceUrl string, dest string) (metadata.Metadata, error) {
+				return nil, afero.WriteFile(fs, filepath.Join(dest, "bundle.tar.gz"), c.archive, 0o644)
+			})
+
+			_, err := dl("https://example.com/bundle.tar.gz", "/dest")
+			if c.expected != "" {
+				assert.ErrorContains(t, err, c.expected)
+				return
+			}
+			require.NoError(t, err)
+
+			rego, err := afero.ReadFile(fs, "/dest/release/policy.rego")
+			require.NoError(t, err)
+			assert.Equal(t, module, string(rego))
+
+			data, err := afero.ReadFile(fs, "/dest/data.json")
+			require.NoError(t, err)
+			assert.JSONEq(t, `{"release": {"allowed": ["a", "b"]}}`, string(data))
+
+			exists, err := afero.Exists(fs, "/dest.bundle")
+			require.NoError(t, err)
+			assert.False(t, exists)
+		})
+	}
+}
+
+func TestOPABundlesWasm(t *testing.T) {
+	b := bundle.Bundle{
+		Manifest: bundle.Manifest{
+			Roots: &[]string{""},
+			WasmResolvers: []bundle.WasmResolver{{
+				Entrypoint: "release/deny",
+				Module:     "/policy.wasm",
+			}},
+		},
+		WasmModules: []bundle.WasmModuleFile{{
+			URL:  "/policy.wasm",
+			Path: "/policy.wasm",
+			Raw:  []byte("\x00asm"),
+		}},
+		Data: map[string]any{},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, bundle.NewWriter(&buf).Write(b))
+
+	fs := afero.NewMemMapFs()
+	ctx := utils.WithFS(context.Background(), fs)
+
+	dl := opaBundles(ctx, func(sourceUrl string, dest string) (metadata.Metadata, error) {
+		return nil, afero.WriteFile(fs, filepath.Join(dest, "bundle.tar.gz"), buf.Bytes(), 0o644)
+	})
+
+	_, err := dl("https://example.com/bundle.tar.gz", "/dest")
+	require.NoError(t, err)
+
+	wasm, err := afero.ReadFile(fs, "/dest/policy.wasm")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("\x00asm"), wasm)
+
+	manifest, err := afero.ReadFile(fs, "/dest/.manifest")
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"revision": "", "roots": [""], "wasm": [{"entrypoint": "release/deny", "module": "/policy.wasm"}]}`, string(manifest))
+}
+
+func TestOPABundlesOtherSources(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	ctx := utils.WithFS(context.Background(), fs)
+
+	var downloaded []string
+	dl := opaBundles(ctx, func(sourceUrl string, dest string) (metadata.Metadata, error) {
+		downloaded = append(downloaded, dest)
+		return nil, nil
+	})
+
+	_, err := dl("oci::registry.io/policy:latest", "/dest")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"/dest"}, downloaded)
+}
+
+type bundleDownloader struct {
+	fs      afero.Fs
+	archive []byte
+}
+
+func (d *bundleDownloader) Download(_ context.Context, dest string, _ string, _ bool) (metadata.Metadata, error) {
+	return nil, afero.WriteFile(d.fs, filepath.Join(dest, "bundle.tar.gz"), d.archive, 0o644)
+}
+
+func TestGetPolicyOPABundle(t *testing.T) {
+	private, public := testKey(t)
+
+	fs := afero.NewMemMapFs()
+	ctx := utils.WithFS(context.Background(), fs)
+	ctx = context.WithValue(ctx, DownloaderFuncKey, &bundleDownloader{
+		fs:      fs,
+		archive: testBundle(t, "package release\n\nimport rego.v1\n\ndeny contains \"denied\" if false\n", private),
+	})
+	ctx = WithSourceKeys(ctx, SourceKeys{"https://example.com/signed/bundle.tar.gz": public})
+
+	p := PolicyUrl{Url: "https://example.com/signed/bundle.tar.gz", Kind: PolicyKind}
+	dir, err := p.GetPolicy(ctx, "/tmp/ec-work-bundle", false)
+	require.NoError(t, err)
+
+	exists, err := afero.Exists(fs, filepath.Join(dir, "release", "policy.rego"))
+	require.NoError(t, err)
+	assert.True(t, exists)
+}
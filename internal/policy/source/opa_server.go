@@ -0,0 +1,71 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package source
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// opaServerScheme is the prefix of the URL of a policy source evaluated by a
+// remote OPA server, e.g. opa+https://opa.example.com/v1/data/release
+const opaServerScheme = "opa+"
+
+// ErrRemoteOPAServer is returned when fetching a policy source evaluated by a
+// remote OPA server, such sources have no files to fetch.
+var ErrRemoteOPAServer = errors.New("the policy source is evaluated by a remote OPA server and cannot be fetched")
+
+// RemoteOPAServer returns the URL of the OPA server evaluating the policy
+// source, if the source is given as an opa+http:// or opa+https:// URL. Only
+// servers queried over HTTPS are evaluated, opa+http:// URLs are recognized so
+// that such sources are reported as invalid rather than fetched.
+func RemoteOPAServer(sourceUrl string) (*url.URL, bool) {
+	rest, ok := strings.CutPrefix(sourceUrl, opaServerScheme)
+	if !ok {
+		return nil, false
+	}
+
+	u, err := url.Parse(rest)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, false
+	}
+
+	return u, true
+}
+
+// DoHTTP sends the HTTP request with the options from the context, see
+// WithHTTPOptions: the certificate authorities from the CA bundle are trusted
+// and the headers configured for the host of the request are added, and
+// removed when the request is redirected to another host.
+func DoHTTP(ctx context.Context, req *http.Request) (*http.Response, error) {
+	opts := httpOptionsFrom(ctx)
+	client, err := opts.client()
+	if err != nil {
+		return nil, err
+	}
+
+	for name, values := range opts.Headers[req.URL.Host] {
+		for _, v := range values {
+			req.Header.Add(name, v)
+		}
+	}
+
+	return client.Do(req.WithContext(ctx))
+}
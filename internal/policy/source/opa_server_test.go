@@ -0,0 +1,96 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package source
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRemoteOPAServer(t *testing.T) {
+	cases := []struct {
+		source   string
+		expected string
+	}{
+		{source: "opa+https://opa.example.com/v1/data/release", expected: "https://opa.example.com/v1/data/release"},
+		{source: "opa+http://localhost:8181/v1/data/release", expected: "http://localhost:8181/v1/data/release"},
+		{source: "https://opa.example.com/v1/data/release"},
+		{source: "opa+oci://registry.io/policy:latest"},
+		{source: "opa+https://"},
+		{source: "git::https://github.com/org/repo"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.source, func(t *testing.T) {
+			u, ok := RemoteOPAServer(c.source)
+			if c.expected == "" {
+				assert.False(t, ok)
+				return
+			}
+			require.True(t, ok)
+			assert.Equal(t, c.expected, u.String())
+		})
+	}
+}
+
+func TestRemoteOPAServerNotFetched(t *testing.T) {
+	s := PolicyUrl{Url: "opa+https://opa.example.com/v1/data/release", Kind: PolicyKind}
+
+	_, err := s.GetPolicy(context.Background(), t.TempDir(), false)
+	assert.ErrorIs(t, err, ErrRemoteOPAServer)
+
+	assert.NoError(t, PreFetch(context.Background(), t.TempDir(), []PolicySource{&s}))
+}
+
+func TestDoHTTP(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(server.Close)
+
+	u, err := url.Parse(server.URL)
+	require.NoError(t, err)
+
+	req, err := http.NewRequest(http.MethodPost, server.URL, nil)
+	require.NoError(t, err)
+	resp, err := DoHTTP(context.Background(), req)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+	ctx := WithHTTPOptions(context.Background(), HTTPOptions{
+		Headers: map[string]http.Header{u.Host: {"Authorization": {"Bearer secret"}}},
+	})
+	req, err = http.NewRequest(http.MethodPost, server.URL, nil)
+	require.NoError(t, err)
+	resp, err = DoHTTP(ctx, req)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+}
@@ -0,0 +1,91 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package source
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/sync/semaphore"
+)
+
+// PreFetchConcurrency is the number of sources PreFetch fetches at a time
+const PreFetchConcurrency = 8
+
+// PreFetch fetches the distinct sources to the work directory in parallel,
+// at most PreFetchConcurrency at a time. The fetched sources populate the
+// download cache, so that subsequent GetPolicy calls for the same sources, in
+// any work directory, do not fetch them again. Progress is logged as the
+// sources are fetched, and the errors of all sources that could not be fetched
+// are returned.
+func PreFetch(ctx context.Context, workDir string, sources []PolicySource) error {
+	distinct := make([]PolicySource, 0, len(sources))
+	seen := map[string]bool{}
+	for _, s := range sources {
+		if seen[s.PolicyUrl()] {
+			continue
+		}
+		// The sources evaluated by a remote OPA server are not fetched
+		if _, ok := RemoteOPAServer(s.PolicyUrl()); ok {
+			continue
+		}
+		seen[s.PolicyUrl()] = true
+		distinct = append(distinct, s)
+	}
+
+	total := len(distinct)
+	if total == 0 {
+		return nil
+	}
+
+	sem := semaphore.NewWeighted(PreFetchConcurrency)
+
+	var (
+		mu      sync.Mutex
+		wg      sync.WaitGroup
+		done    int
+		allErrs error
+	)
+	for _, s := range distinct {
+		if err := sem.Acquire(ctx, 1); err != nil {
+			mu.Lock()
+			allErrs = errors.Join(allErrs, err)
+			mu.Unlock()
+			break
+		}
+
+		wg.Add(1)
+		go func(s PolicySource) {
+			defer wg.Done()
+			defer sem.Release(1)
+
+			_, err := s.GetPolicy(ctx, workDir, false)
+
+			mu.Lock()
+			defer mu.Unlock()
+			done++
+			allErrs = errors.Join(allErrs, err)
+			log.Infof("Fetched %d of %d policy sources", done, total)
+		}(s)
+	}
+
+	wg.Wait()
+
+	return allErrs
+}
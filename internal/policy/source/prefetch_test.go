@@ -0,0 +1,134 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package source
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/enterprise-contract/go-gather/metadata"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/enterprise-contract/ec-cli/internal/utils"
+)
+
+// concurrentDownloader records the number of downloads of each source and the
+// maximum number of downloads in progress at a time
+type concurrentDownloader struct {
+	mu        sync.Mutex
+	downloads map[string]int
+	running   atomic.Int32
+	max       atomic.Int32
+}
+
+func (d *concurrentDownloader) Download(_ context.Context, _ string, sourceUrl string, _ bool) (metadata.Metadata, error) {
+	running := d.running.Add(1)
+	defer d.running.Add(-1)
+	for {
+		m := d.max.Load()
+		if running <= m || d.max.CompareAndSwap(m, running) {
+			break
+		}
+	}
+
+	d.mu.Lock()
+	d.downloads[sourceUrl]++
+	d.mu.Unlock()
+
+	time.Sleep(10 * time.Millisecond)
+
+	if strings.Contains(sourceUrl, "missing") {
+		return nil, fmt.Errorf("not found: %s", sourceUrl)
+	}
+
+	return nil, nil
+}
+
+func clearDownloadCache() {
+	downloadCache.Range(func(key, _ any) bool {
+		downloadCache.Delete(key)
+
+		return true
+	})
+}
+
+func TestPreFetch(t *testing.T) {
+	clearDownloadCache()
+
+	dl := &concurrentDownloader{downloads: map[string]int{}}
+	// symlinks to the prefetched sources require a file system supporting them
+	ctx := utils.WithFS(context.Background(), afero.NewOsFs())
+	ctx = context.WithValue(ctx, DownloaderFuncKey, dl)
+
+	sources := []PolicySource{}
+	for i := 0; i < 3*PreFetchConcurrency; i++ {
+		url := fmt.Sprintf("oci::registry.io/prefetch/policy-%d:1", i)
+		// every source is given twice
+		sources = append(sources, &PolicyUrl{Url: url, Kind: PolicyKind}, &PolicyUrl{Url: url, Kind: PolicyKind})
+	}
+
+	err := PreFetch(ctx, t.TempDir(), sources)
+	assert.NoError(t, err)
+
+	assert.Len(t, dl.downloads, 3*PreFetchConcurrency)
+	for url, n := range dl.downloads {
+		assert.Equal(t, 1, n, url)
+	}
+	assert.LessOrEqual(t, dl.max.Load(), int32(PreFetchConcurrency))
+	assert.Greater(t, dl.max.Load(), int32(1))
+
+	// the prefetched sources are reused
+	_, err = sources[0].GetPolicy(ctx, t.TempDir(), false)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, dl.downloads[sources[0].PolicyUrl()])
+}
+
+func TestPreFetchErrors(t *testing.T) {
+	clearDownloadCache()
+
+	dl := &concurrentDownloader{downloads: map[string]int{}}
+	ctx := utils.WithFS(context.Background(), afero.NewMemMapFs())
+	ctx = context.WithValue(ctx, DownloaderFuncKey, dl)
+
+	err := PreFetch(ctx, "/tmp/ec-work-prefetch", []PolicySource{
+		&PolicyUrl{Url: "oci::registry.io/prefetch/missing-1:1", Kind: PolicyKind},
+		&PolicyUrl{Url: "oci::registry.io/prefetch/present:1", Kind: PolicyKind},
+		&PolicyUrl{Url: "oci::registry.io/prefetch/missing-2:1", Kind: DataKind},
+	})
+
+	assert.ErrorContains(t, err, "not found: oci::registry.io/prefetch/missing-1:1")
+	assert.ErrorContains(t, err, "not found: oci::registry.io/prefetch/missing-2:1")
+	assert.Len(t, dl.downloads, 3)
+
+	assert.NoError(t, PreFetch(ctx, "/tmp/ec-work-prefetch", nil))
+
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	err = PreFetch(canceled, "/tmp/ec-work-prefetch", []PolicySource{
+		&PolicyUrl{Url: "oci::registry.io/prefetch/canceled:1", Kind: PolicyKind},
+	})
+	assert.True(t, errors.Is(err, context.Canceled))
+}
@@ -0,0 +1,170 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package source
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/enterprise-contract/go-gather/metadata"
+	"github.com/open-policy-agent/opa/ast"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
+
+	"github.com/enterprise-contract/ec-cli/internal/logging"
+	"github.com/enterprise-contract/ec-cli/internal/utils"
+)
+
+type regoVersionsKey struct{}
+
+// Versions of the Rego language the policy of a source can be written in. The
+// version is detected for each module when not given for the source.
+const (
+	RegoV0 = "v0"
+	RegoV1 = "v1"
+)
+
+// RegoVersions maps a source URL to the version of the Rego language its
+// policy is written in.
+type RegoVersions map[string]string
+
+// ParseRegoVersions parses the versions given as <source>=<version> pairs.
+func ParseRegoVersions(values []string) (RegoVersions, error) {
+	versions := RegoVersions{}
+	for _, v := range values {
+		src, version, found := strings.Cut(v, "=")
+		if !found || src == "" || (version != RegoV0 && version != RegoV1) {
+			return nil, fmt.Errorf("invalid rego version %q, expected <source>=<%s|%s>", v, RegoV0, RegoV1)
+		}
+		versions[src] = version
+	}
+
+	return versions, nil
+}
+
+// WithRegoVersions returns a context in which the policy of the sources is
+// read as written in the given version of the Rego language, instead of
+// detecting the version.
+func WithRegoVersions(ctx context.Context, versions RegoVersions) context.Context {
+	return context.WithValue(ctx, regoVersionsKey{}, versions)
+}
+
+func regoVersionsFrom(ctx context.Context) RegoVersions {
+	if v, ok := ctx.Value(regoVersionsKey{}).(RegoVersions); ok {
+		return v
+	}
+	return nil
+}
+
+// regoCompatible wraps the download function to make the Rego v1 modules of
+// the sources, i.e. modules written for OPA 1.0, loadable by the policy engine
+// which reads modules as Rego v0. Rego v1 is a subset of Rego v0 with the
+// rego.v1 import, so the import is added to those modules. A module is taken
+// as Rego v1 if it can only be parsed as such, or if the source is given as
+// Rego v1, see WithRegoVersions.
+func regoCompatible(ctx context.Context, dl func(string, string) (metadata.Metadata, error)) func(string, string) (metadata.Metadata, error) {
+	return func(sourceUrl string, dest string) (metadata.Metadata, error) {
+		m, err := dl(sourceUrl, dest)
+		if err != nil {
+			return m, err
+		}
+
+		version := regoVersionsFrom(ctx)[sourceUrl]
+		if version == RegoV0 {
+			return m, nil
+		}
+
+		afs := utils.FS(ctx)
+		if _, err := afs.Stat(dest); errors.Is(err, fs.ErrNotExist) {
+			return m, nil
+		}
+
+		err = afero.Walk(afs, dest, func(path string, info fs.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			if info.IsDir() || filepath.Ext(path) != ".rego" {
+				return nil
+			}
+
+			content, err := afero.ReadFile(afs, path)
+			if err != nil {
+				return err
+			}
+
+			shimmed, ok := withRegoV1Import(path, content, version == RegoV1)
+			if !ok {
+				return nil
+			}
+			log.Debugf("Reading %s of source %s as Rego v1", path, logging.RedactURL(sourceUrl))
+
+			// The downloaded files might be read-only
+			if err := afs.Remove(path); err != nil {
+				return err
+			}
+			return afero.WriteFile(afs, path, shimmed, info.Mode().Perm()|0o200)
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		return m, nil
+	}
+}
+
+// withRegoV1Import returns the content of the Rego v1 module with the rego.v1
+// import added. The import is added on the line of the package declaration to
+// keep the locations within the module, e.g. in error messages, unchanged. The
+// module is not changed, returning false, if it is not a Rego v1 module, or if
+// it already has the import. Unless forced, modules that can be parsed as Rego
+// v0 are not taken as Rego v1.
+func withRegoV1Import(path string, content []byte, force bool) ([]byte, bool) {
+	if !force {
+		if _, err := ast.ParseModuleWithOpts(path, string(content), ast.ParserOptions{RegoVersion: ast.RegoV0}); err == nil {
+			return nil, false
+		}
+	}
+
+	module, err := ast.ParseModuleWithOpts(path, string(content), ast.ParserOptions{RegoVersion: ast.RegoV1})
+	if err != nil || module.Package == nil || len(module.Package.Path) == 0 {
+		return nil, false
+	}
+
+	if slices.ContainsFunc(module.Imports, func(i *ast.Import) bool {
+		return ast.RegoV1CompatibleRef.Equal(i.Path.Value)
+	}) {
+		return nil, false
+	}
+
+	loc := module.Package.Path[len(module.Package.Path)-1].Location
+	if loc == nil {
+		return nil, false
+	}
+	end := loc.Offset + len(loc.Text)
+
+	shimmed := make([]byte, 0, len(content)+len(" import rego.v1"))
+	shimmed = append(shimmed, content[:end]...)
+	shimmed = append(shimmed, " import rego.v1"...)
+	shimmed = append(shimmed, content[end:]...)
+
+	return shimmed, true
+}
@@ -0,0 +1,132 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package source
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/enterprise-contract/go-gather/metadata"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/enterprise-contract/ec-cli/internal/utils"
+)
+
+func TestParseRegoVersions(t *testing.T) {
+	versions, err := ParseRegoVersions([]string{"oci::registry.io/policy:latest=v1", "github.com/org/policy=v0"})
+	require.NoError(t, err)
+	assert.Equal(t, RegoVersions{"oci::registry.io/policy:latest": RegoV1, "github.com/org/policy": RegoV0}, versions)
+
+	for _, invalid := range []string{"oci::registry.io/policy:latest", "=v1", "github.com/org/policy=v2"} {
+		_, err := ParseRegoVersions([]string{invalid})
+		assert.EqualError(t, err, `invalid rego version "`+invalid+`", expected <source>=<v0|v1>`)
+	}
+}
+
+func TestWithRegoV1Import(t *testing.T) {
+	cases := []struct {
+		name     string
+		module   string
+		force    bool
+		expected string
+	}{
+		{
+			name:     "rego v1",
+			module:   "package release.attestation # the package\n\ndeny contains msg if {\n\tmsg := \"denied\"\n}\n",
+			expected: "package release.attestation import rego.v1 # the package\n\ndeny contains msg if {\n\tmsg := \"denied\"\n}\n",
+		},
+		{
+			name:   "rego v1 import",
+			module: "package release\n\nimport rego.v1\n\ndeny contains msg if {\n\tmsg := \"denied\"\n}\n",
+		},
+		{
+			name:   "rego v1 import forced",
+			module: "package release\n\nimport rego.v1\n\ndeny contains msg if {\n\tmsg := \"denied\"\n}\n",
+			force:  true,
+		},
+		{
+			name:   "rego v0",
+			module: "package release\n\ndeny[msg] {\n\tmsg := \"denied\"\n}\n",
+		},
+		{
+			name:   "rego v0 forced",
+			module: "package release\n\ndeny[msg] {\n\tmsg := \"denied\"\n}\n",
+			force:  true,
+		},
+		{
+			name:     "compatible forced",
+			module:   "package release\n\nallowed := [\"a\", \"b\"]\n",
+			force:    true,
+			expected: "package release import rego.v1\n\nallowed := [\"a\", \"b\"]\n",
+		},
+		{
+			name:   "invalid",
+			module: "package",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			shimmed, ok := withRegoV1Import("policy.rego", []byte(c.module), c.force)
+			assert.Equal(t, c.expected != "", ok)
+			if ok {
+				assert.Equal(t, c.expected, string(shimmed))
+			}
+		})
+	}
+}
+
+func TestRegoCompatible(t *testing.T) {
+	v0 := "package release\n\ndeny[msg] {\n\tmsg := \"denied\"\n}\n"
+	v1 := "package release\n\ndeny contains msg if {\n\tmsg := \"denied\"\n}\n"
+
+	fs := afero.NewMemMapFs()
+	ctx := utils.WithFS(context.Background(), fs)
+	ctx = WithRegoVersions(ctx, RegoVersions{"github.com/org/v0": RegoV0})
+
+	dl := regoCompatible(ctx, func(sourceUrl string, dest string) (metadata.Metadata, error) {
+		for name, content := range map[string]string{"v0.rego": v0, "v1/v1.rego": v1, "data.json": "{}"} {
+			if err := afero.WriteFile(fs, filepath.Join(dest, name), []byte(content), 0o400); err != nil {
+				return nil, err
+			}
+		}
+		return nil, nil
+	})
+
+	_, err := dl("github.com/org/detected", "/detected")
+	require.NoError(t, err)
+
+	content, err := afero.ReadFile(fs, "/detected/v0.rego")
+	require.NoError(t, err)
+	assert.Equal(t, v0, string(content))
+
+	content, err = afero.ReadFile(fs, "/detected/v1/v1.rego")
+	require.NoError(t, err)
+	assert.Equal(t, "package release import rego.v1\n\ndeny contains msg if {\n\tmsg := \"denied\"\n}\n", string(content))
+
+	_, err = dl("github.com/org/v0", "/v0")
+	require.NoError(t, err)
+
+	content, err = afero.ReadFile(fs, "/v0/v1/v1.rego")
+	require.NoError(t, err)
+	assert.Equal(t, v1, string(content))
+}
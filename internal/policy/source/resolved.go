@@ -0,0 +1,129 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package source
+
+import (
+	"context"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/enterprise-contract/go-gather/metadata"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/enterprise-contract/ec-cli/internal/logging"
+	"github.com/enterprise-contract/ec-cli/internal/utils"
+)
+
+// ResolvedSource records what a policy or data source resolved to when it was
+// fetched, so that the exact content used in an evaluation can be identified.
+type ResolvedSource struct {
+	// URL is the source URL as fetched, the fallback source URL when the
+	// source given in the policy could not be fetched
+	URL string
+	// Kind is the kind of the source, policy or data
+	Kind string
+	// Type is the type of the source, git or oci, empty for other sources
+	Type string
+	// Revision is the commit SHA of git sources or the image digest of OCI
+	// sources
+	Revision string
+	// Ref is the source URL pinned to the revision, fetching it yields the
+	// same content, see PinnedRef
+	Ref string
+	// Digest is the digest of the content of the source, see ContentDigest
+	Digest    string
+	FetchedAt time.Time
+}
+
+// resolvedSources records the ResolvedSource by the source URL
+var resolvedSources sync.Map
+
+// now is replaced in tests
+var now = time.Now
+
+// ResolvedSources returns the sources fetched so far ordered by their URL.
+func ResolvedSources() []ResolvedSource {
+	var sources []ResolvedSource
+	resolvedSources.Range(func(_, v any) bool {
+		sources = append(sources, v.(ResolvedSource))
+		return true
+	})
+
+	slices.SortFunc(sources, func(a, b ResolvedSource) int {
+		return strings.Compare(a.URL, b.URL)
+	})
+
+	return sources
+}
+
+// resolved wraps the download function to record the revision and the content
+// digest of the fetched sources, see ResolvedSources.
+func resolved(ctx context.Context, kind policyKind, dl func(string, string) (metadata.Metadata, error)) func(string, string) (metadata.Metadata, error) {
+	return func(sourceUrl string, dest string) (metadata.Metadata, error) {
+		m, err := dl(sourceUrl, dest)
+		if err != nil {
+			return m, err
+		}
+
+		// The digest is informative, failing to compute it must not fail the
+		// fetch
+		digest, err := ContentDigest(utils.FS(ctx), dest)
+		if err != nil {
+			log.Debugf("Unable to compute the digest of the source %s: %v", logging.RedactURL(sourceUrl), err)
+		}
+
+		t, revision := sourceRevision(m)
+		resolvedSources.Store(sourceUrl, ResolvedSource{
+			URL:       sourceUrl,
+			Kind:      string(kind),
+			Type:      t,
+			Revision:  revision,
+			Ref:       PinnedRef(sourceUrl, t, revision),
+			Digest:    digest,
+			FetchedAt: now().UTC(),
+		})
+
+		return m, nil
+	}
+}
+
+// PinnedRef returns the source URL pinned to the revision it resolved to: OCI
+// sources are pinned by the image digest and git sources by setting the ref
+// parameter to the commit SHA. Other sources are returned as is.
+func PinnedRef(sourceUrl, sourceType, revision string) string {
+	if revision == "" {
+		return sourceUrl
+	}
+
+	switch sourceType {
+	case "oci":
+		return pinnedURL(sourceUrl, revision)
+	case "git":
+		base, query, _ := strings.Cut(sourceUrl, "?")
+		params, err := url.ParseQuery(query)
+		if err != nil {
+			return sourceUrl
+		}
+		params.Set("ref", revision)
+		return base + "?" + params.Encode()
+	}
+
+	return sourceUrl
+}
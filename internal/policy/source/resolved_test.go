@@ -0,0 +1,99 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package source
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/enterprise-contract/ec-cli/internal/utils"
+)
+
+func TestResolvedSources(t *testing.T) {
+	const digest = "sha256:4e388ab32b10dc8dbc7e28144f552830adc74787c1e2c0824032078a79f227fb"
+	t.Cleanup(ResetDownloadCache)
+	ResetDownloadCache()
+
+	fetchedAt := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	now = func() time.Time { return fetchedAt }
+	t.Cleanup(func() { now = time.Now })
+
+	fs := afero.NewMemMapFs()
+	ctx := utils.WithFS(context.Background(), fs)
+	ctx = context.WithValue(ctx, DownloaderFuncKey, &ociDownloader{fs: fs, digest: digest})
+
+	for _, p := range []PolicyUrl{
+		{Url: "oci::registry.io/resolved/policy:latest//policy", Kind: PolicyKind},
+		{Url: "oci::registry.io/resolved/data:1", Kind: DataKind},
+	} {
+		_, err := p.GetPolicy(ctx, "/tmp/ec-work-resolved", false)
+		require.NoError(t, err)
+	}
+
+	sources := ResolvedSources()
+	require.Len(t, sources, 2)
+
+	assert.Regexp(t, "^sha256:[0-9a-f]{64}$", sources[0].Digest)
+	sources[0].Digest = ""
+	assert.Equal(t, ResolvedSource{
+		URL:       "oci::registry.io/resolved/data:1",
+		Kind:      "data",
+		Type:      "oci",
+		Revision:  digest,
+		Ref:       "oci::registry.io/resolved/data:1@" + digest,
+		FetchedAt: fetchedAt,
+	}, sources[0])
+
+	assert.Equal(t, "oci::registry.io/resolved/policy:latest//policy", sources[1].URL)
+	assert.Equal(t, "policy", sources[1].Kind)
+	assert.Equal(t, "oci::registry.io/resolved/policy:latest@"+digest+"//policy", sources[1].Ref)
+
+	ResetDownloadCache()
+	assert.Empty(t, ResolvedSources())
+}
+
+func TestPinnedRef(t *testing.T) {
+	const digest = "sha256:4e388ab32b10dc8dbc7e28144f552830adc74787c1e2c0824032078a79f227fb"
+	const sha = "0123456789abcdef0123456789abcdef01234567"
+
+	cases := []struct {
+		url        string
+		sourceType string
+		revision   string
+		expected   string
+	}{
+		{url: "oci::registry.io/policy:latest", sourceType: "oci", revision: digest, expected: "oci::registry.io/policy:latest@" + digest},
+		{url: "git::https://example.com/policy.git//policy", sourceType: "git", revision: sha, expected: "git::https://example.com/policy.git//policy?ref=" + sha},
+		{url: "git::https://example.com/policy.git//policy?ref=main", sourceType: "git", revision: sha, expected: "git::https://example.com/policy.git//policy?ref=" + sha},
+		{url: "git::https://example.com/policy.git?depth=1&ref=main", sourceType: "git", revision: sha, expected: "git::https://example.com/policy.git?depth=1&ref=" + sha},
+		{url: "git::https://example.com/policy.git", sourceType: "git", expected: "git::https://example.com/policy.git"},
+		{url: "https://example.com/policy.tar.gz", expected: "https://example.com/policy.tar.gz"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.url, func(t *testing.T) {
+			assert.Equal(t, c.expected, PinnedRef(c.url, c.sourceType, c.revision))
+		})
+	}
+}
@@ -0,0 +1,175 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package source
+
+import (
+	"context"
+	"crypto"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/enterprise-contract/go-gather/metadata"
+	ociMetadata "github.com/enterprise-contract/go-gather/metadata/oci"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/sigstore/cosign/v2/pkg/cosign"
+	cosignSig "github.com/sigstore/cosign/v2/pkg/signature"
+	sigstoreSig "github.com/sigstore/sigstore/pkg/signature"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/enterprise-contract/ec-cli/internal/logging"
+	"github.com/enterprise-contract/ec-cli/internal/utils/oci"
+)
+
+type sourceKeysKey struct{}
+
+var (
+	ErrSourceNotSignable = errors.New("only OCI sources and OPA bundles can be verified with a signing key")
+	ErrSignatureMismatch = errors.New("no signature of the source matches its signing key")
+)
+
+// SourceKeys maps a source URL to the public key its content must be signed
+// with.
+type SourceKeys map[string]string
+
+// ParseSourceKeys parses the signing keys given as <source>=<key> pairs. The
+// key is a path to a public key file, a PEM encoded public key, or any key
+// reference supported by cosign, e.g. k8s://namespace/secret.
+func ParseSourceKeys(values []string) (SourceKeys, error) {
+	keys := SourceKeys{}
+	for _, v := range values {
+		src, key, found := strings.Cut(v, "=")
+		if !found || src == "" || key == "" {
+			return nil, fmt.Errorf("invalid policy source key %q, expected <source>=<key>", v)
+		}
+		if _, ok := keys[src]; ok {
+			return nil, fmt.Errorf("invalid policy source key %q, a key is already given for %s", v, src)
+		}
+		keys[src] = key
+	}
+
+	return keys, nil
+}
+
+// WithSourceKeys returns a context in which GetPolicy refuses to use the
+// sources that are not signed with their signing key.
+func WithSourceKeys(ctx context.Context, keys SourceKeys) context.Context {
+	return context.WithValue(ctx, sourceKeysKey{}, keys)
+}
+
+func sourceKeysFrom(ctx context.Context) SourceKeys {
+	if k, ok := ctx.Value(sourceKeysKey{}).(SourceKeys); ok {
+		return k
+	}
+	return nil
+}
+
+// verified wraps the download function to verify the signature of the sources
+// that have a signing key. The signature is verified for the image digest the
+// source was fetched at, so the content used is the content that was signed.
+func (k SourceKeys) verified(ctx context.Context, dl func(string, string) (metadata.Metadata, error)) func(string, string) (metadata.Metadata, error) {
+	return func(sourceUrl string, dest string) (metadata.Metadata, error) {
+		key, ok := k[sourceUrl]
+		if !ok {
+			return dl(sourceUrl, dest)
+		}
+
+		// The signature of an OPA bundle is verified when the bundle is
+		// extracted, see opaBundles
+		if isOPABundle(sourceUrl) {
+			return dl(sourceUrl, dest)
+		}
+
+		if IsOffline() {
+			return nil, fmt.Errorf("%w: the signature of %s can not be verified", ErrOffline, logging.RedactURL(sourceUrl))
+		}
+
+		repo, ok := ociRepository(sourceUrl)
+		if !ok {
+			return nil, fmt.Errorf("%w: %s", ErrSourceNotSignable, logging.RedactURL(sourceUrl))
+		}
+
+		m, err := dl(sourceUrl, dest)
+		if err != nil {
+			return m, err
+		}
+
+		om, ok := m.(*ociMetadata.OCIMetadata)
+		if !ok || om.Digest == "" {
+			return nil, fmt.Errorf("%w: %s", ErrSourceNotSignable, logging.RedactURL(sourceUrl))
+		}
+
+		if err := verifySignature(ctx, repo.Digest(om.Digest), key); err != nil {
+			return nil, fmt.Errorf("%w: %s: %w", ErrSignatureMismatch, logging.RedactURL(sourceUrl), err)
+		}
+		log.Debugf("Verified the signature of source %s at %s", logging.RedactURL(sourceUrl), om.Digest)
+
+		return m, nil
+	}
+}
+
+// verifySignature verifies that the image is signed with the given key. Only
+// the signature is verified, the transparency log is not consulted.
+func verifySignature(ctx context.Context, ref name.Digest, key string) error {
+	verifier, err := keyVerifier(ctx, key)
+	if err != nil {
+		return err
+	}
+
+	opts := cosign.CheckOpts{
+		SigVerifier:   verifier,
+		ClaimVerifier: cosign.SimpleClaimVerifier,
+		IgnoreTlog:    true,
+	}
+
+	_, _, err = oci.NewClient(ctx).VerifyImageSignatures(ref, &opts)
+	return err
+}
+
+func keyVerifier(ctx context.Context, key string) (sigstoreSig.Verifier, error) {
+	if strings.Contains(key, "-----BEGIN PUBLIC KEY-----") {
+		return cosignSig.LoadPublicKeyRaw([]byte(key), crypto.SHA256)
+	}
+
+	return cosignSig.PublicKeyFromKeyRef(ctx, key)
+}
+
+// ociRepository returns the repository of an OCI source URL, e.g.
+// oci::registry.io/policy:latest//subdir.
+func ociRepository(sourceUrl string) (name.Repository, bool) {
+	var rest string
+	switch {
+	case strings.HasPrefix(sourceUrl, "oci::"):
+		rest = strings.TrimPrefix(sourceUrl, "oci::")
+	case strings.HasPrefix(sourceUrl, "oci://"):
+		rest = strings.TrimPrefix(sourceUrl, "oci://")
+	default:
+		return name.Repository{}, false
+	}
+
+	if _, r, found := strings.Cut(rest, "://"); found {
+		rest = r
+	}
+	rest, _, _ = strings.Cut(rest, "//")
+
+	ref, err := name.ParseReference(rest)
+	if err != nil {
+		return name.Repository{}, false
+	}
+
+	return ref.Context(), true
+}
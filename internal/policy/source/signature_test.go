@@ -0,0 +1,122 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package source
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"errors"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/sigstore/sigstore/pkg/cryptoutils"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/enterprise-contract/ec-cli/internal/utils"
+	"github.com/enterprise-contract/ec-cli/internal/utils/oci"
+	"github.com/enterprise-contract/ec-cli/internal/utils/oci/fake"
+)
+
+func TestParseSourceKeys(t *testing.T) {
+	keys, err := ParseSourceKeys([]string{"oci::registry.io/policy:latest=cosign.pub", "oci::registry.io/data:latest=k8s://ns/key"})
+	require.NoError(t, err)
+	assert.Equal(t, SourceKeys{
+		"oci::registry.io/policy:latest": "cosign.pub",
+		"oci::registry.io/data:latest":   "k8s://ns/key",
+	}, keys)
+
+	_, err = ParseSourceKeys([]string{"oci::registry.io/policy:latest"})
+	assert.EqualError(t, err, `invalid policy source key "oci::registry.io/policy:latest", expected <source>=<key>`)
+
+	_, err = ParseSourceKeys([]string{"a=b", "a=c"})
+	assert.EqualError(t, err, `invalid policy source key "a=c", a key is already given for a`)
+}
+
+func TestSourceKeysVerified(t *testing.T) {
+	const digest = "sha256:4e388ab32b10dc8dbc7e28144f552830adc74787c1e2c0824032078a79f227fb"
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	pem, err := cryptoutils.MarshalPublicKeyToPEM(key.Public())
+	require.NoError(t, err)
+
+	fs := afero.NewMemMapFs()
+	ctx := utils.WithFS(context.Background(), fs)
+	ctx = context.WithValue(ctx, DownloaderFuncKey, &ociDownloader{fs: fs, digest: digest})
+
+	signed := name.MustParseReference("registry.io/signed/policy@" + digest)
+	unsigned := name.MustParseReference("registry.io/unsigned/policy@" + digest)
+	client := fake.FakeClient{}
+	client.On("VerifyImageSignatures", signed, mock.Anything).Return(nil, false, nil)
+	client.On("VerifyImageSignatures", unsigned, mock.Anything).Return(nil, false, errors.New("no matching signatures"))
+	ctx = oci.WithClient(ctx, &client)
+
+	ctx = WithSourceKeys(ctx, SourceKeys{
+		"oci::registry.io/signed/policy:latest//policy":   string(pem),
+		"oci::registry.io/unsigned/policy:latest//policy": string(pem),
+		"git::https://git.io/signed/policy.git":           string(pem),
+	})
+
+	p := PolicyUrl{Url: "oci::registry.io/signed/policy:latest//policy", Kind: PolicyKind}
+	_, err = p.GetPolicy(ctx, "/tmp/ec-work-signed", false)
+	assert.NoError(t, err)
+
+	p = PolicyUrl{Url: "oci::registry.io/unsigned/policy:latest//policy", Kind: PolicyKind}
+	_, err = p.GetPolicy(ctx, "/tmp/ec-work-unsigned", false)
+	assert.ErrorIs(t, err, ErrSignatureMismatch)
+
+	p = PolicyUrl{Url: "git::https://git.io/signed/policy.git", Kind: PolicyKind}
+	_, err = p.GetPolicy(ctx, "/tmp/ec-work-git", false)
+	assert.ErrorIs(t, err, ErrSourceNotSignable)
+
+	p = PolicyUrl{Url: "oci::registry.io/other/policy:latest", Kind: PolicyKind}
+	_, err = p.GetPolicy(ctx, "/tmp/ec-work-other", false)
+	assert.NoError(t, err)
+
+	client.AssertExpectations(t)
+}
+
+func TestOCIRepository(t *testing.T) {
+	cases := []struct {
+		url  string
+		repo string
+		ok   bool
+	}{
+		{url: "oci::registry.io/policy:latest", repo: "registry.io/policy", ok: true},
+		{url: "oci://registry.io/policy@sha256:4e388ab32b10dc8dbc7e28144f552830adc74787c1e2c0824032078a79f227fb//sub", repo: "registry.io/policy", ok: true},
+		{url: "oci::https://registry.io/org/policy:v1//sub", repo: "registry.io/org/policy", ok: true},
+		{url: "git::https://github.com/org/policy.git", ok: false},
+		{url: "registry.io/policy:latest", ok: false},
+	}
+
+	for _, c := range cases {
+		t.Run(c.url, func(t *testing.T) {
+			repo, ok := ociRepository(c.url)
+			assert.Equal(t, c.ok, ok)
+			if c.ok {
+				assert.Equal(t, c.repo, repo.String())
+			}
+		})
+	}
+}
@@ -29,6 +29,7 @@ import (
 	"path"
 	"path/filepath"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	ecc "github.com/enterprise-contract/enterprise-contract-controller/api/v1alpha1"
@@ -40,6 +41,8 @@ import (
 	"github.com/spf13/afero"
 
 	"github.com/enterprise-contract/ec-cli/internal/downloader"
+	"github.com/enterprise-contract/ec-cli/internal/logging"
+	"github.com/enterprise-contract/ec-cli/internal/tracing"
 	"github.com/enterprise-contract/ec-cli/internal/utils"
 )
 
@@ -77,6 +80,31 @@ type PolicyUrl struct {
 // downloadCache is a concurrent map used to cache downloaded files.
 var downloadCache sync.Map
 
+// downloadCacheHits and downloadCacheMisses count the lookups of the download
+// cache.
+var downloadCacheHits, downloadCacheMisses atomic.Int64
+
+// DownloadCacheStats returns the number of lookups of the download cache that
+// were served from the cache and the number that required a download.
+func DownloadCacheStats() (hits, misses int64) {
+	return downloadCacheHits.Load(), downloadCacheMisses.Load()
+}
+
+// ResetDownloadCache forgets the sources downloaded so far, including the
+// failed downloads, so that the sources are downloaded again when next used.
+// Long running processes use it to pick up changes to the sources, no source
+// must be in use while it is reset.
+func ResetDownloadCache() {
+	downloadCache.Range(func(key, _ any) bool {
+		downloadCache.Delete(key)
+		return true
+	})
+	resolvedSources.Range(func(key, _ any) bool {
+		resolvedSources.Delete(key)
+		return true
+	})
+}
+
 type cacheContent struct {
 	sourceUrl string
 	metadata  metadata.Metadata
@@ -85,20 +113,26 @@ type cacheContent struct {
 
 func getPolicyThroughCache(ctx context.Context, s PolicySource, workDir string, dl func(string, string) (metadata.Metadata, error)) (string, error) {
 	sourceUrl := s.PolicyUrl()
-	dest := uniqueDestination(workDir, s.Subdir(), sourceUrl)
+	dest := uniqueDestination(ctx, workDir, s.Subdir(), sourceUrl)
 
 	// Load or store the downloaded policy file from the given source URL.
 	// If the file is already in the download cache, it is loaded from there.
 	// Otherwise, it is downloaded from the source URL and stored in the cache.
-	dfn, _ := downloadCache.LoadOrStore(sourceUrl, sync.OnceValues(func() (string, cacheContent) {
-		log.Debugf("Download cache miss: %s", sourceUrl)
+	dfn, loaded := downloadCache.LoadOrStore(sourceUrl, sync.OnceValues(func() (string, cacheContent) {
+		log.Debugf("Download cache miss: %s", logging.RedactURL(sourceUrl))
 		// Checkout policy repo into work directory.
-		log.Debugf("Downloading policy files from source url %s to destination %s", sourceUrl, dest)
+		log.Debugf("Downloading policy files from source url %s to destination %s", logging.RedactURL(sourceUrl), dest)
 		m, err := dl(sourceUrl, dest)
 		c := &cacheContent{sourceUrl, m, err}
 		return dest, *c
 	}))
 
+	if loaded {
+		downloadCacheHits.Add(1)
+	} else {
+		downloadCacheMisses.Add(1)
+	}
+
 	d, c := dfn.(func() (string, cacheContent))()
 	if c.err != nil {
 		return "", c.err
@@ -120,10 +154,10 @@ func getPolicyThroughCache(ctx context.Context, s PolicySource, workDir string,
 			}
 			if c.metadata != nil {
 				if _, ok := c.metadata.(*gitMetadata.GitMetadata); ok {
-					log.Debugf("SHA for source(%s): %s\n", s.PolicyUrl(), c.metadata.(*gitMetadata.GitMetadata).LatestCommit)
+					log.Debugf("SHA for source(%s): %s\n", logging.RedactURL(s.PolicyUrl()), c.metadata.(*gitMetadata.GitMetadata).LatestCommit)
 				}
 				if _, ok := c.metadata.(*ociMetadata.OCIMetadata); ok {
-					log.Debugf("Image digest for source(%s): %s\n", s.PolicyUrl(), c.metadata.(*ociMetadata.OCIMetadata).Digest)
+					log.Debugf("Image digest for source(%s): %s\n", logging.RedactURL(s.PolicyUrl()), c.metadata.(*ociMetadata.OCIMetadata).Digest)
 				}
 			}
 			return dest, nil
@@ -131,7 +165,7 @@ func getPolicyThroughCache(ctx context.Context, s PolicySource, workDir string,
 			log.Debugf("Filesystem does not support symlinking: %q, re-downloading instead", fs.Name())
 			m, err := dl(sourceUrl, dest)
 			if _, ok := m.(*gitMetadata.GitMetadata); ok {
-				log.Debugf("SHA for source(%s): %s\n", s.PolicyUrl(), m.(*gitMetadata.GitMetadata).LatestCommit)
+				log.Debugf("SHA for source(%s): %s\n", logging.RedactURL(s.PolicyUrl()), m.(*gitMetadata.GitMetadata).LatestCommit)
 			}
 			return dest, err
 		}
@@ -139,26 +173,85 @@ func getPolicyThroughCache(ctx context.Context, s PolicySource, workDir string,
 
 	if c.metadata != nil {
 		if _, ok := c.metadata.(*gitMetadata.GitMetadata); ok {
-			log.Debugf("SHA for source(%s): %s\n", s.PolicyUrl(), c.metadata.(*gitMetadata.GitMetadata).LatestCommit)
+			log.Debugf("SHA for source(%s): %s\n", logging.RedactURL(s.PolicyUrl()), c.metadata.(*gitMetadata.GitMetadata).LatestCommit)
 		}
 		if _, ok := c.metadata.(*ociMetadata.OCIMetadata); ok {
-			log.Debugf("Image digest for source(%s): %s\n", s.PolicyUrl(), c.metadata.(*ociMetadata.OCIMetadata).Digest)
+			log.Debugf("Image digest for source(%s): %s\n", logging.RedactURL(s.PolicyUrl()), c.metadata.(*ociMetadata.OCIMetadata).Digest)
 		}
 	}
 	return d, c.err
 }
 
-// GetPolicies clones the repository for a given PolicyUrl
-func (p *PolicyUrl) GetPolicy(ctx context.Context, workDir string, showMsg bool) (string, error) {
+// GetPolicies clones the repository for a given PolicyUrl, falling back to the
+// fallback sources from the context if that fails, see WithFallbacks.
+func (p *PolicyUrl) GetPolicy(ctx context.Context, workDir string, showMsg bool) (dir string, err error) {
+	ctx, span := tracing.Start(ctx, "fetch", tracing.SourceURL.String(logging.RedactURL(p.Url)), tracing.SourceKind.String(string(p.Kind)))
+	defer func() { tracing.End(span, err) }()
+
+	if _, ok := RemoteOPAServer(p.Url); ok {
+		return "", fmt.Errorf("%w: %s", ErrRemoteOPAServer, logging.RedactURL(p.Url))
+	}
+
 	dl := func(source string, dest string) (metadata.Metadata, error) {
-		x := ctx.Value(DownloaderFuncKey)
-		if dl, ok := x.(downloaderFunc); ok {
-			return dl.Download(ctx, dest, source, showMsg)
-		}
-		return downloader.Download(ctx, dest, source, showMsg)
+		return Download(ctx, dest, source, showMsg)
+	}
+
+	if IsOffline() {
+		dl = fromBundle(ctx)
+	} else {
+		dl = cached(ctx, dl)
+	}
+
+	if b := bundleWriterFrom(ctx); b != nil {
+		dl = b.recorded(ctx, dl)
 	}
 
-	return getPolicyThroughCache(ctx, p, workDir, dl)
+	dl = checksummed(ctx, dl)
+
+	dl = opaBundles(ctx, dl)
+
+	if l := lockfileFrom(ctx); l != nil {
+		dl = l.locked(ctx, dl)
+	}
+
+	if k := sourceKeysFrom(ctx); k != nil {
+		dl = k.verified(ctx, dl)
+	}
+
+	dl = regoCompatible(ctx, dl)
+
+	dl = resolved(ctx, p.Kind, dl)
+
+	return getPolicyWithFallbacks(ctx, p, func(s *PolicyUrl) (string, error) {
+		return getPolicyThroughCache(ctx, s, workDir, dl)
+	})
+}
+
+// Download fetches the given source url into the destination directory using
+// the same mechanism used for fetching policy sources. Files hosted on HTTPS
+// servers are fetched with the options from the context, see WithHTTPOptions,
+// objects in S3, GCS and Azure Blob Storage buckets with the credentials of the
+// cloud provider, and the files of ConfigMaps and Secrets from the Kubernetes
+// cluster. The downloader can be replaced by setting a value for
+// DownloaderFuncKey in the context.
+func Download(ctx context.Context, dest string, sourceUrl string, showMsg bool) (metadata.Metadata, error) {
+	x := ctx.Value(DownloaderFuncKey)
+	if dl, ok := x.(downloaderFunc); ok {
+		return dl.Download(ctx, dest, sourceUrl, showMsg)
+	}
+	if ref, ok := kubernetesSource(sourceUrl); ok {
+		return fetchKubernetes(ctx, dest, sourceUrl, ref, showMsg)
+	}
+	if getterUrl, ok := bucketSourceURL(sourceUrl); ok {
+		return fetchBucket(ctx, dest, sourceUrl, getterUrl, showMsg)
+	}
+	if account, container, blob, ok := azureBlobSource(sourceUrl); ok {
+		return fetchAzureBlob(ctx, dest, sourceUrl, account, container, blob, showMsg)
+	}
+	if u, ok := httpSourceURL(sourceUrl); ok {
+		return fetchHTTP(ctx, dest, u, showMsg)
+	}
+	return downloader.Download(ctx, dest, sourceUrl, showMsg)
 }
 
 func (p *PolicyUrl) PolicyUrl() string {
@@ -170,14 +263,20 @@ func (p *PolicyUrl) Subdir() string {
 	return string(p.Kind)
 }
 
-func uniqueDestination(rootDir string, subdir string, sourceUrl string) string {
-	return path.Join(rootDir, subdir, uniqueDir(sourceUrl))
+func uniqueDestination(ctx context.Context, rootDir string, subdir string, sourceUrl string) string {
+	return path.Join(rootDir, subdir, uniqueDir(ctx, sourceUrl))
 }
 
 // uniqueDir generates a reasonably unique string using an SHA224 sum with a
-// timestamp appended to the input for some extra randomness
-func uniqueDir(input string) string {
-	return fmt.Sprintf("%x", sha256.Sum224([]byte(fmt.Sprintf("%s/%s", input, time.Now()))))[:9]
+// timestamp appended to the input for some extra randomness. When a seed is
+// set the seed is used instead of the timestamp, so the result is the same
+// between runs.
+func uniqueDir(ctx context.Context, input string) string {
+	var salt any = time.Now()
+	if seed, ok := utils.Seed(ctx); ok {
+		salt = seed
+	}
+	return fmt.Sprintf("%x", sha256.Sum224([]byte(fmt.Sprintf("%s/%s", input, salt))))[:9]
 }
 
 type inlineData struct {
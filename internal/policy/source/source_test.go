@@ -258,3 +258,38 @@ func TestGetPolicyThroughCache(t *testing.T) {
 		test(t, afero.NewMemMapFs(), 2)
 	})
 }
+
+func TestResetDownloadCache(t *testing.T) {
+	ResetDownloadCache()
+	ctx := utils.WithFS(context.Background(), afero.NewMemMapFs())
+
+	invocations := 0
+	dl := func(_, _ string) (metadata.Metadata, error) {
+		invocations++
+		return nil, errors.New("expected")
+	}
+
+	s := &PolicyUrl{Url: "https://example.com/policy.git", Kind: PolicyKind}
+	_, err := getPolicyThroughCache(ctx, s, "/work", dl)
+	assert.Error(t, err)
+	_, err = getPolicyThroughCache(ctx, s, "/work", dl)
+	assert.Error(t, err)
+	assert.Equal(t, 1, invocations)
+
+	ResetDownloadCache()
+	_, err = getPolicyThroughCache(ctx, s, "/work", dl)
+	assert.Error(t, err)
+	assert.Equal(t, 2, invocations)
+}
+
+func TestUniqueDirWithSeed(t *testing.T) {
+	ctx := utils.WithSeed(context.Background(), "42")
+
+	d1 := uniqueDir(ctx, "git::https://github.com/org/repo")
+	d2 := uniqueDir(ctx, "git::https://github.com/org/repo")
+	assert.Equal(t, d1, d2)
+	assert.Len(t, d1, 9)
+
+	assert.NotEqual(t, d1, uniqueDir(ctx, "git::https://github.com/org/other"))
+	assert.NotEqual(t, d1, uniqueDir(utils.WithSeed(context.Background(), "43"), "git::https://github.com/org/repo"))
+}
@@ -0,0 +1,134 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// Generates the Go code of the protocol buffer definitions given as arguments,
+// relative to the current directory. The definitions are compiled in-process,
+// so protoc is not needed, and the code is generated by the protoc-gen-go and
+// protoc-gen-go-grpc plugins.
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	"github.com/bufbuild/protocompile"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/pluginpb"
+)
+
+// plugins generating the code, protoc-gen-go is the version of the protobuf
+// module in use
+var plugins = []string{
+	"google.golang.org/protobuf/cmd/protoc-gen-go",
+	"google.golang.org/grpc/cmd/protoc-gen-go-grpc@v1.5.1",
+}
+
+func main() {
+	if err := generate(context.Background(), os.Args[1:]); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func generate(ctx context.Context, files []string) error {
+	compiler := protocompile.Compiler{
+		Resolver: protocompile.WithStandardImports(&protocompile.SourceResolver{
+			ImportPaths: []string{"."},
+		}),
+		// The comments are carried over to the generated code
+		SourceInfoMode: protocompile.SourceInfoStandard,
+	}
+
+	compiled, err := compiler.Compile(ctx, files...)
+	if err != nil {
+		return err
+	}
+
+	// The plugins need the definitions with all of their dependencies, the
+	// dependencies first
+	var all []*descriptorpb.FileDescriptorProto
+	seen := map[string]bool{}
+	var add func(protoreflect.FileDescriptor)
+	add = func(f protoreflect.FileDescriptor) {
+		if seen[f.Path()] {
+			return
+		}
+		seen[f.Path()] = true
+		imports := f.Imports()
+		for i := 0; i < imports.Len(); i++ {
+			add(imports.Get(i).FileDescriptor)
+		}
+		all = append(all, protodesc.ToFileDescriptorProto(f))
+	}
+	for _, f := range compiled {
+		add(f)
+	}
+
+	request, err := proto.Marshal(&pluginpb.CodeGeneratorRequest{
+		FileToGenerate: files,
+		Parameter:      proto.String("paths=source_relative"),
+		ProtoFile:      all,
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, plugin := range plugins {
+		if err := run(ctx, plugin, request); err != nil {
+			return fmt.Errorf("running %s: %w", plugin, err)
+		}
+	}
+
+	return nil
+}
+
+// run runs the plugin with the request, writing the generated files
+func run(ctx context.Context, plugin string, request []byte) error {
+	var stdout bytes.Buffer
+	cmd := exec.CommandContext(ctx, "go", "run", plugin)
+	cmd.Stdin = bytes.NewReader(request)
+	cmd.Stdout = &stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return err
+	}
+
+	var response pluginpb.CodeGeneratorResponse
+	if err := proto.Unmarshal(stdout.Bytes(), &response); err != nil {
+		return err
+	}
+	if response.Error != nil {
+		return fmt.Errorf("%s", response.GetError())
+	}
+
+	for _, f := range response.File {
+		if err := os.MkdirAll(filepath.Dir(f.GetName()), 0o755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(f.GetName(), []byte(f.GetContent()), 0o644); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
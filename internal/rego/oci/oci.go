@@ -36,6 +36,7 @@ import (
 	log "github.com/sirupsen/logrus"
 
 	"github.com/enterprise-contract/ec-cli/internal/fetchers/oci/files"
+	"github.com/enterprise-contract/ec-cli/internal/policy/source"
 	"github.com/enterprise-contract/ec-cli/internal/utils/oci"
 )
 
@@ -189,6 +190,11 @@ func ociBlob(bctx rego.BuiltinContext, a *ast.Term) (*ast.Term, error) {
 		return nil, nil
 	}
 
+	if source.IsOffline() {
+		log.Errorf("%s fetch layer: %s", ociBlobName, source.ErrOffline)
+		return nil, nil
+	}
+
 	rawLayer, err := oci.NewClient(bctx.Context).Layer(ref)
 	if err != nil {
 		log.Errorf("%s fetch layer: %s", ociBlobName, err)
@@ -238,6 +244,11 @@ func ociImageManifest(bctx rego.BuiltinContext, a *ast.Term) (*ast.Term, error)
 		return nil, nil
 	}
 
+	if source.IsOffline() {
+		log.Errorf("fetch image: %s", source.ErrOffline)
+		return nil, nil
+	}
+
 	image, err := oci.NewClient(bctx.Context).Image(ref)
 	if err != nil {
 		log.Errorf("fetch image: %s", err)
@@ -288,6 +299,11 @@ func ociImageFiles(bctx rego.BuiltinContext, refTerm *ast.Term, pathsTerm *ast.T
 		return nil, nil
 	}
 
+	if source.IsOffline() {
+		log.Errorf("fetch image: %s", source.ErrOffline)
+		return nil, nil
+	}
+
 	pathsArray, err := builtins.ArrayOperand(pathsTerm.Value, 1)
 	if err != nil {
 		log.Errorf("paths to array operand: %s", err)
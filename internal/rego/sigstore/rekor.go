@@ -0,0 +1,183 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// IMPORTANT: The rego functions in this file never return an error. Instead, they return no value
+// when an error is encountered. If they did return an error, opa would exit abruptly and it would
+// not produce a report of which policy rules succeeded/failed.
+
+package sigstore
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/open-policy-agent/opa/ast"
+	"github.com/open-policy-agent/opa/rego"
+	"github.com/open-policy-agent/opa/topdown/builtins"
+	"github.com/open-policy-agent/opa/types"
+	cosignOptions "github.com/sigstore/cosign/v2/cmd/cosign/cli/options"
+	"github.com/sigstore/cosign/v2/cmd/cosign/cli/rekor"
+	"github.com/sigstore/cosign/v2/pkg/cosign"
+	"github.com/sigstore/rekor/pkg/generated/models"
+
+	"github.com/enterprise-contract/ec-cli/internal/policy/source"
+)
+
+const sigstoreRekorEntryName = "ec.sigstore.rekor_entry"
+
+// rekorEntries caches the entries fetched from the Rekor transparency logs,
+// keyed by the URL of the log and the UUID of the entry. The entries of the log
+// never change, so they are kept for the lifetime of the process.
+var rekorEntries sync.Map
+
+type rekorEntryKey struct {
+	url  string
+	uuid string
+}
+
+func registerSigstoreRekorEntry() {
+	entry := types.Named("entry", types.NewObject([]*types.StaticProperty{
+		{Key: "uuid", Value: types.S},
+		{Key: "log_index", Value: types.N},
+		{Key: "log_id", Value: types.S},
+		{Key: "integrated_time", Value: types.N},
+		{Key: "body", Value: types.A},
+	}, nil)).Description("the log entry")
+
+	result := types.Named(
+		"result",
+		types.NewObject([]*types.StaticProperty{
+			{Key: "success", Value: types.Named("success", types.B).Description("true when the entry is found")},
+			{Key: "errors", Value: types.Named("errors", types.NewArray([]types.Type{types.S}, nil)).Description("errors fetching the entry")},
+			{Key: "entry", Value: entry},
+		}, nil),
+	).Description("the result of the request")
+
+	decl := rego.Function{
+		Name:        sigstoreRekorEntryName,
+		Description: "Fetch an entry from the Rekor transparency log.",
+		Decl: types.NewFunction(
+			types.Args(
+				types.Named("uuid", types.S).Description("UUID of the entry"),
+				types.Named("rekor_url", types.S).Description("URL of the Rekor transparency log, defaults to the public Rekor instance when empty"),
+			),
+			result,
+		),
+		// As per the documentation, enable memoization to ensure function evaluation is
+		// deterministic. But also mark it as non-deterministic because it does rely on external
+		// entities, i.e. Rekor. https://www.openpolicyagent.org/docs/latest/extensions/
+		Memoize:          true,
+		Nondeterministic: true,
+	}
+	rego.RegisterBuiltin2(&decl, sigstoreRekorEntry)
+}
+
+func sigstoreRekorEntry(bctx rego.BuiltinContext, uuidTerm *ast.Term, urlTerm *ast.Term) (*ast.Term, error) {
+	ctx := bctx.Context
+
+	uuid, err := builtins.StringOperand(uuidTerm.Value, 0)
+	if err != nil {
+		return rekorEntryFailedResult(fmt.Errorf("uuid parameter: %w", err))
+	}
+
+	rekorURL, err := builtins.StringOperand(urlTerm.Value, 1)
+	if err != nil {
+		return rekorEntryFailedResult(fmt.Errorf("rekor_url parameter: %w", err))
+	}
+
+	key := rekorEntryKey{url: string(rekorURL), uuid: string(uuid)}
+	if key.url == "" {
+		key.url = cosignOptions.DefaultRekorURL
+	}
+
+	if e, ok := rekorEntries.Load(key); ok {
+		return rekorEntryResult(key.uuid, e.(*models.LogEntryAnon))
+	}
+
+	if source.IsOffline() {
+		return rekorEntryFailedResult(fmt.Errorf("%w: the entry %s can not be fetched from %s", source.ErrOffline, key.uuid, key.url))
+	}
+
+	client, err := rekor.NewClient(key.url)
+	if err != nil {
+		return rekorEntryFailedResult(fmt.Errorf("new rekor client: %w", err))
+	}
+
+	// The body of the entry returned is verified to match the UUID
+	e, err := cosign.GetTlogEntry(ctx, client, key.uuid)
+	if err != nil {
+		return rekorEntryFailedResult(fmt.Errorf("fetch entry: %w", err))
+	}
+
+	rekorEntries.Store(key, e)
+
+	return rekorEntryResult(key.uuid, e)
+}
+
+func rekorEntryFailedResult(err error) (*ast.Term, error) {
+	return ast.ObjectTerm(
+		ast.Item(ast.StringTerm("success"), ast.BooleanTerm(false)),
+		ast.Item(ast.StringTerm("errors"), ast.ArrayTerm(ast.StringTerm(err.Error()))),
+	), nil
+}
+
+func rekorEntryResult(uuid string, e *models.LogEntryAnon) (*ast.Term, error) {
+	encoded, ok := e.Body.(string)
+	if !ok {
+		return rekorEntryFailedResult(fmt.Errorf("unexpected body of the entry: %T", e.Body))
+	}
+
+	decoded, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return rekorEntryFailedResult(fmt.Errorf("decode body: %w", err))
+	}
+
+	var body any
+	if err := json.Unmarshal(decoded, &body); err != nil {
+		return rekorEntryFailedResult(fmt.Errorf("unmarshal body: %w", err))
+	}
+
+	bodyValue, err := ast.InterfaceToValue(body)
+	if err != nil {
+		return rekorEntryFailedResult(fmt.Errorf("interface to value: %w", err))
+	}
+
+	var logIndex, integratedTime int64
+	var logID string
+	if e.LogIndex != nil {
+		logIndex = *e.LogIndex
+	}
+	if e.IntegratedTime != nil {
+		integratedTime = *e.IntegratedTime
+	}
+	if e.LogID != nil {
+		logID = *e.LogID
+	}
+
+	return ast.ObjectTerm(
+		ast.Item(ast.StringTerm("success"), ast.BooleanTerm(true)),
+		ast.Item(ast.StringTerm("errors"), ast.ArrayTerm()),
+		ast.Item(ast.StringTerm("entry"), ast.ObjectTerm(
+			ast.Item(ast.StringTerm("uuid"), ast.StringTerm(uuid)),
+			ast.Item(ast.StringTerm("log_index"), ast.IntNumberTerm(int(logIndex))),
+			ast.Item(ast.StringTerm("log_id"), ast.StringTerm(logID)),
+			ast.Item(ast.StringTerm("integrated_time"), ast.IntNumberTerm(int(integratedTime))),
+			ast.Item(ast.StringTerm("body"), ast.NewTerm(bodyValue)),
+		)),
+	), nil
+}
@@ -0,0 +1,104 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package sigstore
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/open-policy-agent/opa/ast"
+	"github.com/open-policy-agent/opa/rego"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/enterprise-contract/ec-cli/internal/policy/source"
+)
+
+func TestSigstoreRekorEntry(t *testing.T) {
+	body := []byte(`{"apiVersion": "0.0.1", "kind": "hashedrekord"}`)
+	// The UUID of an entry is the RFC 6962 leaf hash of its body
+	leaf := sha256.Sum256(append([]byte{0}, body...))
+	uuid := hex.EncodeToString(leaf[:])
+
+	requests := 0
+	rekor := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.URL.Path != "/api/v1/log/entries/"+uuid {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			uuid: map[string]any{
+				"body":           base64.StdEncoding.EncodeToString(body),
+				"integratedTime": 1700000000,
+				"logID":          strings.Repeat("a", 64),
+				"logIndex":       42,
+			},
+		})
+	}))
+	t.Cleanup(rekor.Close)
+
+	bctx := rego.BuiltinContext{Context: context.Background()}
+
+	result, err := sigstoreRekorEntry(bctx, ast.StringTerm(uuid), ast.StringTerm(rekor.URL))
+	require.NoError(t, err)
+	require.Equal(t, ast.BooleanTerm(true), result.Get(ast.StringTerm("success")))
+	require.Equal(t, ast.ArrayTerm(), result.Get(ast.StringTerm("errors")))
+
+	entry := result.Get(ast.StringTerm("entry"))
+	assert.Equal(t, ast.StringTerm(uuid), entry.Get(ast.StringTerm("uuid")))
+	assert.Equal(t, ast.IntNumberTerm(42), entry.Get(ast.StringTerm("log_index")))
+	assert.Equal(t, ast.StringTerm(strings.Repeat("a", 64)), entry.Get(ast.StringTerm("log_id")))
+	assert.Equal(t, ast.IntNumberTerm(1700000000), entry.Get(ast.StringTerm("integrated_time")))
+	assert.Equal(t, ast.StringTerm("hashedrekord"), entry.Get(ast.StringTerm("body")).Get(ast.StringTerm("kind")))
+
+	// The entry is cached
+	calls := requests
+	_, err = sigstoreRekorEntry(bctx, ast.StringTerm(uuid), ast.StringTerm(rekor.URL))
+	require.NoError(t, err)
+	assert.Equal(t, calls, requests)
+
+	missing := strings.Repeat("0", 64)
+	result, err = sigstoreRekorEntry(bctx, ast.StringTerm(missing), ast.StringTerm(rekor.URL))
+	require.NoError(t, err)
+	assert.Equal(t, ast.BooleanTerm(false), result.Get(ast.StringTerm("success")))
+
+	result, err = sigstoreRekorEntry(bctx, ast.NumberTerm("1"), ast.StringTerm(rekor.URL))
+	require.NoError(t, err)
+	assert.Equal(t, ast.BooleanTerm(false), result.Get(ast.StringTerm("success")))
+}
+
+func TestSigstoreRekorEntryOffline(t *testing.T) {
+	source.SetOffline(true)
+	t.Cleanup(func() { source.SetOffline(false) })
+
+	bctx := rego.BuiltinContext{Context: context.Background()}
+	result, err := sigstoreRekorEntry(bctx, ast.StringTerm(strings.Repeat("1", 64)), ast.StringTerm("https://rekor.local"))
+	require.NoError(t, err)
+	assert.Equal(t, ast.BooleanTerm(false), result.Get(ast.StringTerm("success")))
+	assert.Contains(t, result.Get(ast.StringTerm("errors")).String(), source.ErrOffline.Error())
+}
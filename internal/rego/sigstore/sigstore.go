@@ -35,6 +35,7 @@ import (
 
 	"github.com/enterprise-contract/ec-cli/internal/attestation"
 	"github.com/enterprise-contract/ec-cli/internal/policy"
+	"github.com/enterprise-contract/ec-cli/internal/policy/source"
 	"github.com/enterprise-contract/ec-cli/internal/signature"
 	ecoci "github.com/enterprise-contract/ec-cli/internal/utils/oci"
 )
@@ -106,6 +107,10 @@ func sigstoreVerifyImage(bctx rego.BuiltinContext, refTerm *ast.Term, optsTerm *
 		return signatureFailedResult(fmt.Errorf("new digest: %w", err))
 	}
 
+	if source.IsOffline() {
+		return signatureFailedResult(fmt.Errorf("%w: the signature of %s can not be verified", source.ErrOffline, ref))
+	}
+
 	checkOpts, err := parseCheckOpts(ctx, optsTerm)
 	if err != nil {
 		return signatureFailedResult(fmt.Errorf("opts parameter: %w", err))
@@ -167,6 +172,10 @@ func sigstoreVerifyAttestation(bctx rego.BuiltinContext, refTerm *ast.Term, opts
 		return attestationFailedResult(fmt.Errorf("new digest: %w", err))
 	}
 
+	if source.IsOffline() {
+		return attestationFailedResult(fmt.Errorf("%w: the attestation of %s can not be verified", source.ErrOffline, ref))
+	}
+
 	checkOpts, err := parseCheckOpts(ctx, optsTerm)
 	if err != nil {
 		return attestationFailedResult(fmt.Errorf("opts parameter: %w", err))
@@ -349,6 +358,7 @@ func attestationResult(attestations []oci.Signature, err error) (*ast.Term, erro
 }
 
 func init() {
+	registerSigstoreRekorEntry()
 	registerSigstoreVerifyImage()
 	registerSigstoreVerifyAttestation()
 }
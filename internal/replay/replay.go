@@ -0,0 +1,338 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// Package replay implements recording the policy inputs of the validated
+// components, along with the policies and the sources they were evaluated
+// with, and evaluating the recorded inputs again without network access, e.g.
+// to reproduce a failed validation locally.
+package replay
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sync"
+	"time"
+
+	app "github.com/konflux-ci/application-api/api/v1alpha1"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
+	"sigs.k8s.io/yaml"
+
+	"github.com/enterprise-contract/ec-cli/internal/evaluator"
+	"github.com/enterprise-contract/ec-cli/internal/output"
+	"github.com/enterprise-contract/ec-cli/internal/policy"
+	"github.com/enterprise-contract/ec-cli/internal/policy/source"
+	"github.com/enterprise-contract/ec-cli/internal/utils"
+)
+
+const (
+	// manifestFile is the name of the file describing the content of a replay
+	// directory
+	manifestFile = "replay.json"
+	// sourcesFile is the name of the bundle of the policy and data sources
+	// within a replay directory
+	sourcesFile = "sources.tar.gz"
+)
+
+// unsafeChars matches the characters not kept from the component names in the
+// names of the input files
+var unsafeChars = regexp.MustCompile(`[^a-zA-Z0-9._-]+`)
+
+// Policy is a policy configuration recorded in a replay directory.
+type Policy struct {
+	// Path of the policy configuration within the directory
+	Path string `json:"path"`
+	// EffectiveTime the policy was evaluated at
+	EffectiveTime time.Time `json:"effectiveTime"`
+}
+
+// Component is the policy input of a component recorded in a replay
+// directory.
+type Component struct {
+	Name           string `json:"name"`
+	ContainerImage string `json:"containerImage"`
+	// Target is the reference the policy was evaluated against, matched by the
+	// volatile configuration of the policy
+	Target string `json:"target,omitempty"`
+	// Input is the path of the policy input within the directory
+	Input string `json:"input"`
+	// Policy is the path of the policy configuration within the directory
+	Policy string `json:"policy"`
+}
+
+// Manifest describes the content of a replay directory.
+type Manifest struct {
+	Created    time.Time   `json:"created"`
+	Policies   []Policy    `json:"policies"`
+	Components []Component `json:"components"`
+}
+
+// Recorder records the policy inputs of the components, the policies they are
+// evaluated with and the sources fetched, to be written to a replay directory
+// using Write.
+type Recorder struct {
+	bundle   *source.BundleWriter
+	mu       sync.Mutex
+	policies map[policy.Policy]string
+	inputs   map[string][]byte
+	manifest Manifest
+}
+
+// NewRecorder returns a Recorder collecting the sources fetched in the given
+// directory.
+func NewRecorder(dir string) *Recorder {
+	return &Recorder{
+		bundle:   source.NewBundleWriter(dir),
+		policies: map[policy.Policy]string{},
+		inputs:   map[string][]byte{},
+	}
+}
+
+// Context returns a context in which all sources fetched are recorded.
+func (r *Recorder) Context(ctx context.Context) context.Context {
+	return source.WithBundleWriter(ctx, r.bundle)
+}
+
+// Add records the policy input of the component, evaluated with the policy
+// against the target reference.
+func (r *Recorder) Add(comp app.SnapshotComponent, p policy.Policy, target string, input []byte) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	policyPath, ok := r.policies[p]
+	if !ok {
+		policyPath = fmt.Sprintf("policies/%d.yaml", len(r.policies)+1)
+		r.policies[p] = policyPath
+		r.manifest.Policies = append(r.manifest.Policies, Policy{
+			Path:          policyPath,
+			EffectiveTime: p.EffectiveTime().UTC(),
+		})
+	}
+
+	inputPath := fmt.Sprintf("inputs/%d-%s.json", len(r.manifest.Components)+1, unsafeChars.ReplaceAllString(comp.Name, "_"))
+	r.inputs[inputPath] = input
+
+	r.manifest.Components = append(r.manifest.Components, Component{
+		Name:           comp.Name,
+		ContainerImage: comp.ContainerImage,
+		Target:         target,
+		Input:          inputPath,
+		Policy:         policyPath,
+	})
+}
+
+// Write writes the recorded policy inputs, policy configurations and sources
+// into the directory, along with the manifest describing them.
+func (r *Recorder) Write(afs afero.Fs, dir string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	write := func(name string, data []byte) error {
+		p := filepath.Join(dir, filepath.FromSlash(name))
+		if err := afs.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			return err
+		}
+		return afero.WriteFile(afs, p, data, 0o644)
+	}
+
+	for name, input := range r.inputs {
+		if err := write(name, input); err != nil {
+			return err
+		}
+	}
+
+	for p, name := range r.policies {
+		config, err := yaml.Marshal(p.Spec())
+		if err != nil {
+			return err
+		}
+		if err := write(name, config); err != nil {
+			return err
+		}
+	}
+
+	if _, err := r.bundle.Write(afs, filepath.Join(dir, sourcesFile)); err != nil {
+		return fmt.Errorf("writing the sources: %w", err)
+	}
+
+	r.manifest.Created = time.Now().UTC()
+	manifest, err := json.MarshalIndent(r.manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return write(manifestFile, manifest)
+}
+
+// ReadManifest reads the manifest of the replay directory.
+func ReadManifest(afs afero.Fs, dir string) (Manifest, error) {
+	data, err := afero.ReadFile(afs, filepath.Join(dir, manifestFile))
+	if err != nil {
+		return Manifest{}, fmt.Errorf("reading the replay directory %s: %w", dir, err)
+	}
+
+	var manifest Manifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return Manifest{}, fmt.Errorf("reading the replay directory %s: %w", dir, err)
+	}
+
+	paths := make([]string, 0, len(manifest.Policies)+len(manifest.Components))
+	for _, p := range manifest.Policies {
+		paths = append(paths, p.Path)
+	}
+	for _, c := range manifest.Components {
+		paths = append(paths, c.Input)
+	}
+	for _, p := range paths {
+		if !filepath.IsLocal(filepath.FromSlash(p)) {
+			return Manifest{}, fmt.Errorf("the path %q is not within the replay directory %s", p, dir)
+		}
+	}
+
+	return manifest, nil
+}
+
+// Result is the outcome of evaluating the recorded policy input of a
+// component.
+type Result struct {
+	Component Component
+	Policy    policy.Policy
+	Output    *output.Output
+}
+
+// Replay evaluates the policy inputs recorded in the directory with the
+// recorded policies. The sources are fetched exclusively from the recorded
+// bundle of sources, offline mode needs to be enabled, see source.SetOffline.
+func Replay(ctx context.Context, dir string, detailed bool) ([]Result, error) {
+	if !source.IsOffline() {
+		return nil, errors.New("the policy inputs can only be replayed offline")
+	}
+
+	afs := utils.FS(ctx)
+
+	manifest, err := ReadManifest(afs, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	workDir, err := utils.CreateWorkDir(afs)
+	if err != nil {
+		return nil, err
+	}
+	defer utils.CleanupWorkDir(afs, workDir)
+
+	sourcesDir := filepath.Join(workDir, "sources")
+	if _, err := source.ExtractBundle(afs, filepath.Join(dir, sourcesFile), sourcesDir); err != nil {
+		return nil, err
+	}
+	ctx = source.WithBundleDir(ctx, sourcesDir)
+
+	var allEvaluators []evaluator.Evaluator
+	defer func() {
+		for _, e := range allEvaluators {
+			e.Destroy()
+		}
+	}()
+
+	policies := map[string]policy.Policy{}
+	evaluators := map[string][]evaluator.Evaluator{}
+	for _, mp := range manifest.Policies {
+		config, err := afero.ReadFile(afs, filepath.Join(dir, filepath.FromSlash(mp.Path)))
+		if err != nil {
+			return nil, fmt.Errorf("reading the policy configuration %s: %w", mp.Path, err)
+		}
+
+		p, err := policy.NewInputPolicy(ctx, string(config), mp.EffectiveTime.Format(time.RFC3339Nano))
+		if err != nil {
+			return nil, fmt.Errorf("policy configuration %s: %w", mp.Path, err)
+		}
+		policies[mp.Path] = p
+
+		for _, sourceGroup := range p.Spec().Sources {
+			policySources, err := source.FetchPolicySources(sourceGroup)
+			if err != nil {
+				return nil, err
+			}
+
+			e, err := evaluator.NewConftestEvaluator(ctx, policySources, p, sourceGroup)
+			if err != nil {
+				return nil, err
+			}
+			allEvaluators = append(allEvaluators, e)
+			evaluators[mp.Path] = append(evaluators[mp.Path], e)
+		}
+	}
+
+	results := make([]Result, 0, len(manifest.Components))
+	for _, c := range manifest.Components {
+		p, ok := policies[c.Policy]
+		if !ok {
+			return nil, fmt.Errorf("the policy configuration %s of component %s is not recorded", c.Policy, c.Name)
+		}
+
+		out, err := evaluate(ctx, filepath.Join(dir, filepath.FromSlash(c.Input)), c, evaluators[c.Policy], detailed)
+		if err != nil {
+			return nil, fmt.Errorf("replaying the policy input %s of component %s: %w", path.Base(c.Input), c.Name, err)
+		}
+		out.Policy = p
+
+		results = append(results, Result{Component: c, Policy: p, Output: out})
+	}
+
+	return results, nil
+}
+
+// evaluate runs the evaluators against the recorded policy input, the same way
+// the policy input is evaluated when validating the image.
+func evaluate(ctx context.Context, inputPath string, c Component, evaluators []evaluator.Evaluator, detailed bool) (*output.Output, error) {
+	input, err := afero.ReadFile(utils.FS(ctx), inputPath)
+	if err != nil {
+		return nil, err
+	}
+
+	out := &output.Output{
+		ImageURL:    c.ContainerImage,
+		Detailed:    detailed,
+		PolicyInput: input,
+		Target:      c.Target,
+	}
+
+	var allResults []evaluator.Outcome
+	applicable := false
+	for _, e := range evaluators {
+		results, data, err := e.Evaluate(ctx, evaluator.EvaluationTarget{Inputs: []string{inputPath}, Target: c.Target})
+		if errors.Is(err, evaluator.ErrNoApplicableRules) {
+			log.Debugf("No policy rules applicable to component %s", c.Name)
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		applicable = true
+		allResults = append(allResults, results...)
+		out.Data = append(out.Data, data)
+	}
+
+	out.NoApplicableRules = len(evaluators) > 0 && !applicable
+	out.SetPolicyCheck(allResults)
+
+	return out, nil
+}
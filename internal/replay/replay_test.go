@@ -0,0 +1,107 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package replay
+
+import (
+	"context"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/enterprise-contract/go-gather/metadata"
+	ociMetadata "github.com/enterprise-contract/go-gather/metadata/oci"
+	app "github.com/konflux-ci/application-api/api/v1alpha1"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/enterprise-contract/ec-cli/internal/policy"
+	"github.com/enterprise-contract/ec-cli/internal/policy/source"
+	"github.com/enterprise-contract/ec-cli/internal/utils"
+)
+
+type fakeDownloader struct {
+	fs afero.Fs
+}
+
+func (d fakeDownloader) Download(_ context.Context, dest string, sourceUrl string, _ bool) (metadata.Metadata, error) {
+	if err := d.fs.MkdirAll(dest, 0755); err != nil {
+		return nil, err
+	}
+	return &ociMetadata.OCIMetadata{Digest: "sha256:4e388ab32b10dc8dbc7e28144f552830adc74787c1e2c0824032078a79f227fb"},
+		afero.WriteFile(d.fs, path.Join(dest, "policy.rego"), []byte("package "+path.Base(sourceUrl)), 0400)
+}
+
+func TestRecorderWrite(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", "/cache")
+
+	fs := afero.NewMemMapFs()
+	ctx := utils.WithFS(context.Background(), fs)
+	ctx = context.WithValue(ctx, source.DownloaderFuncKey, fakeDownloader{fs})
+
+	r := NewRecorder("/staging")
+	ctx = r.Context(ctx)
+
+	u := source.PolicyUrl{Url: "oci::registry.io/replay/release:1", Kind: source.PolicyKind}
+	_, err := u.GetPolicy(ctx, "/work", false)
+	require.NoError(t, err)
+
+	p, err := policy.NewInputPolicy(ctx, `{"sources": [{"policy": ["oci::registry.io/replay/release:1"]}]}`, "2024-01-02T03:04:05Z")
+	require.NoError(t, err)
+
+	r.Add(app.SnapshotComponent{Name: "my/component", ContainerImage: "registry.io/repo:tag"}, p, "sha256:abc", []byte(`{"image": {}}`))
+	r.Add(app.SnapshotComponent{Name: "other", ContainerImage: "registry.io/other:tag"}, p, "", []byte(`{}`))
+
+	require.NoError(t, r.Write(fs, "/replay"))
+
+	manifest, err := ReadManifest(fs, "/replay")
+	require.NoError(t, err)
+
+	assert.Equal(t, []Policy{{Path: "policies/1.yaml", EffectiveTime: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)}}, manifest.Policies)
+	assert.Equal(t, []Component{
+		{Name: "my/component", ContainerImage: "registry.io/repo:tag", Target: "sha256:abc", Input: "inputs/1-my_component.json", Policy: "policies/1.yaml"},
+		{Name: "other", ContainerImage: "registry.io/other:tag", Input: "inputs/2-other.json", Policy: "policies/1.yaml"},
+	}, manifest.Components)
+
+	input, err := afero.ReadFile(fs, "/replay/inputs/1-my_component.json")
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"image": {}}`, string(input))
+
+	config, err := afero.ReadFile(fs, "/replay/policies/1.yaml")
+	require.NoError(t, err)
+	assert.Contains(t, string(config), "oci::registry.io/replay/release:1")
+
+	sources, err := source.ExtractBundle(fs, "/replay/sources.tar.gz", "/extracted")
+	require.NoError(t, err)
+	require.Len(t, sources.Sources, 1)
+	assert.Equal(t, "oci::registry.io/replay/release:1", sources.Sources[0].URL)
+}
+
+func TestReadManifestOutsideOfDirectory(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "/replay/replay.json", []byte(`{"components": [{"name": "c", "input": "../input.json"}]}`), 0o644))
+
+	_, err := ReadManifest(fs, "/replay")
+	assert.ErrorContains(t, err, `the path "../input.json" is not within the replay directory /replay`)
+}
+
+func TestReplayOnline(t *testing.T) {
+	_, err := Replay(utils.WithFS(context.Background(), afero.NewMemMapFs()), "/replay", false)
+	assert.EqualError(t, err, "the policy inputs can only be replayed offline")
+}
@@ -0,0 +1,166 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	app "github.com/konflux-ci/application-api/api/v1alpha1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/structpb"
+
+	"github.com/enterprise-contract/ec-cli/api/v1alpha1"
+	"github.com/enterprise-contract/ec-cli/internal/applicationsnapshot"
+	"github.com/enterprise-contract/ec-cli/internal/evaluator"
+)
+
+// grpcServer serves the Validation service of the gRPC API
+type grpcServer struct {
+	v1alpha1.UnimplementedValidationServer
+	base   context.Context
+	server *Server
+}
+
+// GRPCServer returns a gRPC server serving the Validation service, see
+// api/v1alpha1/validation.proto, with the given options, e.g. the TLS
+// credentials. The calls are handled within the given context, not limited by
+// its deadline, and authenticated with the bearer token of the server, when
+// set, in the authorization metadata.
+func (s *Server) GRPCServer(ctx context.Context, opts ...grpc.ServerOption) *grpc.Server {
+	g := grpc.NewServer(append(opts, grpc.StreamInterceptor(s.streamAuth))...)
+	v1alpha1.RegisterValidationServer(g, &grpcServer{base: context.WithoutCancel(ctx), server: s})
+	return g
+}
+
+// streamAuth rejects the calls without the bearer token of the server
+func (s *Server) streamAuth(srv any, stream grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	var authorization string
+	if md, ok := metadata.FromIncomingContext(stream.Context()); ok {
+		if values := md.Get("authorization"); len(values) > 0 {
+			authorization = values[0]
+		}
+	}
+	if !s.authorized(authorization) {
+		return status.Error(codes.Unauthenticated, "a valid bearer token is required")
+	}
+
+	return handler(srv, stream)
+}
+
+func (g *grpcServer) Validate(req *v1alpha1.ValidateRequest, stream v1alpha1.Validation_ValidateServer) error {
+	// The values of the server's context, cancelled with the call
+	ctx, cancel := context.WithCancel(g.base)
+	defer cancel()
+	defer context.AfterFunc(stream.Context(), cancel)()
+
+	if g.server.options.RequestTimeout > 0 {
+		var cancelTimeout context.CancelFunc
+		ctx, cancelTimeout = context.WithTimeout(ctx, g.server.options.RequestTimeout)
+		defer cancelTimeout()
+	}
+
+	r := ValidateRequest{Policy: req.GetPolicy(), EffectiveTime: req.GetEffectiveTime()}
+	for _, c := range req.GetComponents() {
+		r.Snapshot.Components = append(r.Snapshot.Components, app.SnapshotComponent{
+			Name:           c.GetName(),
+			ContainerImage: c.GetContainerImage(),
+		})
+	}
+
+	var sendErr error
+	report, err := g.server.validateRequest(ctx, r, func(c applicationsnapshot.Component) {
+		if sendErr != nil {
+			return
+		}
+		result, err := componentResult(c)
+		if err == nil {
+			err = stream.Send(&v1alpha1.ValidateResponse{
+				Result: &v1alpha1.ValidateResponse_Component{Component: result},
+			})
+		}
+		if err != nil {
+			// No point in validating the rest of the components
+			sendErr = err
+			cancel()
+		}
+	})
+	if sendErr != nil {
+		return sendErr
+	}
+	if err != nil {
+		if errors.As(err, &requestError{}) {
+			return status.Error(codes.InvalidArgument, err.Error())
+		}
+		return status.Error(codes.Internal, err.Error())
+	}
+
+	data, err := json.Marshal(report)
+	if err != nil {
+		return status.Error(codes.Internal, err.Error())
+	}
+
+	return stream.Send(&v1alpha1.ValidateResponse{
+		Result: &v1alpha1.ValidateResponse_Report{Report: &v1alpha1.Report{Success: report.Success, Json: data}},
+	})
+}
+
+func componentResult(c applicationsnapshot.Component) (*v1alpha1.ComponentResult, error) {
+	violations, err := results(c.Violations)
+	if err != nil {
+		return nil, err
+	}
+	warnings, err := results(c.Warnings)
+	if err != nil {
+		return nil, err
+	}
+
+	return &v1alpha1.ComponentResult{
+		Name:            c.Name,
+		ContainerImage:  c.ContainerImage,
+		Success:         c.Success,
+		Violations:      violations,
+		Warnings:        warnings,
+		SuccessCount:    int32(c.SuccessCount),
+		EvaluationError: c.EvaluationError,
+	}, nil
+}
+
+func results(rs []evaluator.Result) ([]*v1alpha1.Result, error) {
+	converted := make([]*v1alpha1.Result, 0, len(rs))
+	for _, r := range rs {
+		// The metadata holds values of any type, e.g. slices of strings,
+		// converted through JSON the same way they are in the report
+		var metadata structpb.Struct
+		if len(r.Metadata) > 0 {
+			data, err := json.Marshal(r.Metadata)
+			if err != nil {
+				return nil, err
+			}
+			if err := metadata.UnmarshalJSON(data); err != nil {
+				return nil, err
+			}
+		}
+		converted = append(converted, &v1alpha1.Result{Message: r.Message, Metadata: &metadata})
+	}
+
+	return converted, nil
+}
@@ -0,0 +1,143 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/enterprise-contract/ec-cli/api/v1alpha1"
+	"github.com/enterprise-contract/ec-cli/internal/applicationsnapshot"
+	"github.com/enterprise-contract/ec-cli/internal/utils"
+)
+
+func grpcClient(t *testing.T, s *Server) v1alpha1.ValidationClient {
+	ctx := utils.WithFS(context.Background(), afero.NewMemMapFs())
+	listener := bufconn.Listen(1024 * 1024)
+	g := s.GRPCServer(ctx)
+	go func() {
+		_ = g.Serve(listener)
+	}()
+	t.Cleanup(g.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufconn",
+		grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) {
+			return listener.Dial()
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+
+	return v1alpha1.NewValidationClient(conn)
+}
+
+func TestGRPCValidate(t *testing.T) {
+	client := grpcClient(t, New(validator, Options{Workers: 2, IgnoreRekor: true}))
+
+	stream, err := client.Validate(context.Background(), &v1alpha1.ValidateRequest{
+		Components: []*v1alpha1.Component{
+			{Name: "good", ContainerImage: "registry.io/good:latest"},
+			{Name: "bad", ContainerImage: "registry.io/bad:latest"},
+			{Name: "broken", ContainerImage: "registry.io/broken:latest"},
+		},
+		Policy: policyJSON(t),
+	})
+	require.NoError(t, err)
+
+	var responses []*v1alpha1.ValidateResponse
+	for {
+		r, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		require.NoError(t, err)
+		responses = append(responses, r)
+	}
+
+	// A result per component, then the report
+	require.Len(t, responses, 4)
+	components := map[string]*v1alpha1.ComponentResult{}
+	for _, r := range responses[:3] {
+		c := r.GetComponent()
+		require.NotNil(t, c)
+		components[c.Name] = c
+	}
+
+	assert.True(t, components["good"].Success)
+	assert.Equal(t, int32(1), components["good"].SuccessCount)
+
+	assert.False(t, components["bad"].Success)
+	require.Len(t, components["bad"].Violations, 1)
+	assert.Equal(t, "fail", components["bad"].Violations[0].Message)
+	assert.Equal(t, "policy.bad", components["bad"].Violations[0].Metadata.AsMap()["code"])
+
+	assert.False(t, components["broken"].Success)
+	assert.Equal(t, "broken image", components["broken"].EvaluationError)
+
+	report := responses[3].GetReport()
+	require.NotNil(t, report)
+	assert.False(t, report.Success)
+
+	var r applicationsnapshot.Report
+	require.NoError(t, json.Unmarshal(report.Json, &r))
+	assert.Len(t, r.Components, 3)
+}
+
+func TestGRPCValidateInvalidArgument(t *testing.T) {
+	client := grpcClient(t, New(validator, Options{IgnoreRekor: true}))
+
+	stream, err := client.Validate(context.Background(), &v1alpha1.ValidateRequest{Policy: policyJSON(t)})
+	require.NoError(t, err)
+
+	_, err = stream.Recv()
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	assert.Contains(t, err.Error(), "the snapshot has no components to validate")
+}
+
+func TestGRPCValidateUnauthenticated(t *testing.T) {
+	client := grpcClient(t, New(validator, Options{IgnoreRekor: true, Token: "s3cr3t"}))
+
+	req := &v1alpha1.ValidateRequest{Policy: policyJSON(t)}
+
+	stream, err := client.Validate(context.Background(), req)
+	require.NoError(t, err)
+	_, err = stream.Recv()
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer s3cr3t")
+	stream, err = client.Validate(ctx, req)
+	require.NoError(t, err)
+	_, err = stream.Recv()
+	// Authorized, and then rejected for the missing components
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
@@ -0,0 +1,115 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package server
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+)
+
+// shutdownTimeout is how long the requests in flight are given to complete
+// when the server is stopped
+const shutdownTimeout = 30 * time.Second
+
+// Run serves the handler on the address until the context is done or SIGINT
+// or SIGTERM is received, then stops once the requests in flight complete. TLS
+// is used when the certificate and the key files are given. The requests are
+// handled within the given context, not limited by its deadline.
+func Run(ctx context.Context, address string, handler http.Handler, certFile, keyFile string) error {
+	base := context.WithoutCancel(ctx)
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	srv := &http.Server{
+		Addr:              address,
+		Handler:           handler,
+		BaseContext:       func(net.Listener) context.Context { return base },
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	errs := make(chan error, 1)
+	go func() {
+		if certFile != "" || keyFile != "" {
+			errs <- srv.ListenAndServeTLS(certFile, keyFile)
+		} else {
+			errs <- srv.ListenAndServe()
+		}
+	}()
+	log.Infof("Serving on %s", address)
+
+	select {
+	case err := <-errs:
+		return err
+	case <-ctx.Done():
+	}
+
+	log.Info("Stopping the server")
+	shutdownCtx, cancel := context.WithTimeout(base, shutdownTimeout)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+
+	return nil
+}
+
+// RunGRPC serves the gRPC server on the address until the context is done or
+// SIGINT or SIGTERM is received, then stops once the calls in flight complete.
+func RunGRPC(ctx context.Context, address string, srv *grpc.Server) error {
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return err
+	}
+
+	errs := make(chan error, 1)
+	go func() {
+		errs <- srv.Serve(listener)
+	}()
+	log.Infof("Serving gRPC on %s", address)
+
+	select {
+	case err := <-errs:
+		return err
+	case <-ctx.Done():
+	}
+
+	log.Info("Stopping the gRPC server")
+	stopped := make(chan struct{})
+	go func() {
+		srv.GracefulStop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-time.After(shutdownTimeout):
+		srv.Stop()
+	}
+
+	return nil
+}
@@ -0,0 +1,378 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// Package server serves the validation of snapshots over HTTP, so that
+// platforms can run the Enterprise Contract as a service instead of running the
+// CLI for each validation.
+package server
+
+import (
+	"cmp"
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	ecc "github.com/enterprise-contract/enterprise-contract-controller/api/v1alpha1"
+	app "github.com/konflux-ci/application-api/api/v1alpha1"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/enterprise-contract/ec-cli/internal/applicationsnapshot"
+	"github.com/enterprise-contract/ec-cli/internal/evaluator"
+	"github.com/enterprise-contract/ec-cli/internal/format"
+	"github.com/enterprise-contract/ec-cli/internal/logging"
+	"github.com/enterprise-contract/ec-cli/internal/output"
+	"github.com/enterprise-contract/ec-cli/internal/policy"
+	"github.com/enterprise-contract/ec-cli/internal/policy/source"
+	"github.com/enterprise-contract/ec-cli/internal/utils"
+	validate_utils "github.com/enterprise-contract/ec-cli/internal/validate"
+)
+
+// maxRequestSize limits the size of the body of a validation request
+const maxRequestSize = 10 << 20
+
+// ValidateFunc validates a component of the snapshot, see image.ValidateImage.
+type ValidateFunc func(context.Context, app.SnapshotComponent, *app.SnapshotSpec, policy.Policy, []evaluator.Evaluator, bool) (*output.Output, error)
+
+// Options configure the Server
+type Options struct {
+	// Workers is the number of components of a snapshot validated
+	// concurrently
+	Workers int
+	// RequestTimeout limits the time taken to validate the snapshot of a
+	// request, no limit when zero
+	RequestTimeout time.Duration
+	// SourceTTL is how long the fetched policy sources are reused across the
+	// requests before they are fetched again, reused until the server stops
+	// when zero
+	SourceTTL time.Duration
+	// IgnoreRekor skips the lookup of the signatures in the Rekor transparency
+	// log, see --ignore-rekor of ec validate image
+	IgnoreRekor bool
+	// Token is the bearer token the requests must present in the
+	// Authorization header, the requests are not authenticated when empty
+	Token string
+}
+
+// ValidateRequest is the body of the POST /validate request
+type ValidateRequest struct {
+	// Snapshot holds the components to validate
+	Snapshot app.SnapshotSpec `json:"snapshot"`
+	// Policy references the policy configuration the same way --policy of ec
+	// validate image does, except that the files of the server can not be
+	// referenced, neither by the policy nor by its sources
+	Policy string `json:"policy"`
+	// EffectiveTime is the time the policy is evaluated at, now by default
+	EffectiveTime string `json:"effectiveTime,omitempty"`
+}
+
+// ComponentFunc is called with the result of each component of the snapshot
+// as soon as the component is validated. The calls are not concurrent.
+type ComponentFunc func(applicationsnapshot.Component)
+
+// errorResponse is the body of the response to a failed request
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+// requestError is an error caused by the content of the request
+type requestError struct {
+	error
+}
+
+// Server handles the validation requests
+type Server struct {
+	options      Options
+	validate     ValidateFunc
+	newEvaluator func(context.Context, []source.PolicySource, evaluator.ConfigProvider, ecc.Source) (evaluator.Evaluator, error)
+	sources      *sourceCache
+	mux          *http.ServeMux
+}
+
+// New creates a Server validating the components with the given function
+func New(validate ValidateFunc, options Options) *Server {
+	s := &Server{
+		options:      options,
+		validate:     validate,
+		newEvaluator: evaluator.NewConftestEvaluator,
+		sources:      &sourceCache{ttl: options.SourceTTL},
+		mux:          http.NewServeMux(),
+	}
+
+	s.mux.HandleFunc("POST /validate", s.handleValidate)
+	s.mux.HandleFunc("GET /healthz", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	return s
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	// The health checks are not authenticated, as with the probes of
+	// Kubernetes
+	if r.URL.Path != "/healthz" && !s.authorized(r.Header.Get("Authorization")) {
+		w.Header().Set("WWW-Authenticate", "Bearer")
+		writeError(w, http.StatusUnauthorized, errors.New("a valid bearer token is required"))
+		return
+	}
+
+	s.mux.ServeHTTP(w, r)
+}
+
+// authorized returns true if the value of the Authorization header holds the
+// bearer token of the server, or if the server has no token.
+func (s *Server) authorized(authorization string) bool {
+	if s.options.Token == "" {
+		return true
+	}
+
+	token, ok := strings.CutPrefix(authorization, "Bearer ")
+	return ok && subtle.ConstantTimeCompare([]byte(token), []byte(s.options.Token)) == 1
+}
+
+// Close removes the policy sources fetched by the server
+func (s *Server) Close(ctx context.Context) {
+	s.sources.close(ctx)
+}
+
+func (s *Server) handleValidate(w http.ResponseWriter, r *http.Request) {
+	var req ValidateRequest
+	decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRequestSize))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("unable to parse the request: %w", err))
+		return
+	}
+
+	ctx := r.Context()
+	if s.options.RequestTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.options.RequestTimeout)
+		defer cancel()
+	}
+
+	report, err := s.validateRequest(ctx, req, nil)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.As(err, &requestError{}) {
+			status = http.StatusBadRequest
+		}
+		log.Debugf("Failed to validate the snapshot: %v", err)
+		writeError(w, status, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	p := format.NewTargetParser(applicationsnapshot.JSON, format.Options{}, w, utils.FS(ctx))
+	if err := report.WriteAll([]string{applicationsnapshot.JSON}, p); err != nil {
+		log.Errorf("Unable to write the report: %v", err)
+	}
+}
+
+// validateRequest validates the snapshot of the request against the policy of
+// the request, rejecting the requests referencing files of the server, or the
+// ConfigMaps and Secrets of the cluster, see checkSources.
+func (s *Server) validateRequest(ctx context.Context, req ValidateRequest, fn ComponentFunc) (*applicationsnapshot.Report, error) {
+	if len(req.Snapshot.Components) == 0 {
+		return nil, requestError{errors.New("the snapshot has no components to validate")}
+	}
+	if req.Policy == "" {
+		return nil, requestError{errors.New("the policy is required")}
+	}
+	if source.SourceIsFile(req.Policy) && utils.HasJsonOrYamlExt(req.Policy) {
+		return nil, requestError{errors.New("the policy can not reference a file of the server")}
+	}
+
+	return s.validateEach(ctx, &req.Snapshot, req.Policy, req.EffectiveTime, fn, true)
+}
+
+// Validate validates the components of the snapshot against the referenced
+// policy at the given effective time, now when empty, sharing the fetched
+// policy sources with the other validations. Components that can not be
+// evaluated are reported with the evaluation error, as with
+// --evaluation-errors=report. Errors caused by the policy are requestErrors.
+func (s *Server) Validate(ctx context.Context, snap *app.SnapshotSpec, policyRef string, effectiveTime string) (*applicationsnapshot.Report, error) {
+	return s.ValidateEach(ctx, snap, policyRef, effectiveTime, nil)
+}
+
+// ValidateEach is like Validate, calling the function, when given, with the
+// result of each component as soon as the component is validated.
+func (s *Server) ValidateEach(ctx context.Context, snap *app.SnapshotSpec, policyRef string, effectiveTime string, fn ComponentFunc) (*applicationsnapshot.Report, error) {
+	return s.validateEach(ctx, snap, policyRef, effectiveTime, fn, false)
+}
+
+// validateEach is ValidateEach, rejecting the local sources of the policy when
+// the policy is untrusted, i.e. given by the request.
+func (s *Server) validateEach(ctx context.Context, snap *app.SnapshotSpec, policyRef string, effectiveTime string, fn ComponentFunc, untrusted bool) (*applicationsnapshot.Report, error) {
+	policyConfiguration, err := validate_utils.GetPolicyConfig(ctx, policyRef)
+	if err != nil {
+		return nil, requestError{fmt.Errorf("loading the policy: %w", err)}
+	}
+
+	p, err := policy.NewPolicy(ctx, policy.Options{
+		EffectiveTime: cmp.Or(effectiveTime, policy.Now),
+		IgnoreRekor:   s.options.IgnoreRekor,
+		PolicyRef:     policyConfiguration,
+	})
+	if err != nil {
+		return nil, requestError{fmt.Errorf("loading the policy: %w", err)}
+	}
+
+	if untrusted {
+		if err := checkSources(p); err != nil {
+			return nil, err
+		}
+	}
+
+	var allSources []source.PolicySource
+	for _, sourceGroup := range p.Spec().Sources {
+		policySources, err := source.FetchPolicySources(sourceGroup)
+		if err != nil {
+			return nil, requestError{err}
+		}
+		allSources = append(allSources, policySources...)
+	}
+
+	dir, release, err := s.sources.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	if err := source.PreFetch(ctx, dir, allSources); err != nil {
+		// Fetched again by the next request, the evaluators report the
+		// sources that can not be fetched
+		log.Debugf("Unable to fetch some of the policy sources: %v", err)
+		s.sources.invalidate()
+	}
+
+	var evaluators []evaluator.Evaluator
+	defer func() {
+		for _, e := range evaluators {
+			e.Destroy()
+		}
+	}()
+	for _, sourceGroup := range p.Spec().Sources {
+		policySources, _ := source.FetchPolicySources(sourceGroup)
+		e, err := s.newEvaluator(ctx, policySources, p, sourceGroup)
+		if err != nil {
+			s.sources.invalidate()
+			return nil, err
+		}
+		evaluators = append(evaluators, e)
+	}
+
+	components := s.validateComponents(ctx, snap, p, evaluators, fn)
+
+	report, err := applicationsnapshot.NewReport("", components, p, nil, nil, false)
+	if err != nil {
+		return nil, err
+	}
+
+	return &report, nil
+}
+
+// checkSources rejects the policy and the data sources, of any of the source
+// groups of the policy, fetched with the access of the server rather than over
+// the network, e.g. a directory of the server or a Secret of the cluster, see
+// source.SourceIsLocal. The rules could otherwise disclose their content in
+// the results.
+func checkSources(p policy.Policy) error {
+	for _, sourceGroup := range p.Spec().Sources {
+		for _, url := range slices.Concat(sourceGroup.Policy, sourceGroup.Data) {
+			if source.SourceIsLocal(url) {
+				return requestError{fmt.Errorf("the source %s can not reference a file or a Kubernetes resource of the server", logging.RedactURL(url))}
+			}
+		}
+	}
+
+	return nil
+}
+
+func (s *Server) validateComponents(ctx context.Context, snap *app.SnapshotSpec, p policy.Policy, evaluators []evaluator.Evaluator, fn ComponentFunc) []applicationsnapshot.Component {
+	jobs := make(chan app.SnapshotComponent, len(snap.Components))
+	for _, c := range snap.Components {
+		jobs <- c
+	}
+	close(jobs)
+
+	var mu sync.Mutex
+	components := make([]applicationsnapshot.Component, 0, len(snap.Components))
+
+	var wg sync.WaitGroup
+	for i := 0; i < max(s.options.Workers, 1); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for comp := range jobs {
+				c := s.validateComponent(ctx, comp, snap, p, evaluators)
+				mu.Lock()
+				components = append(components, c)
+				if fn != nil {
+					fn(c)
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	// The workers complete in any order, ensure some consistency in output
+	slices.SortStableFunc(components, func(a, b applicationsnapshot.Component) int {
+		if c := cmp.Compare(b.ContainerImage, a.ContainerImage); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.Name, b.Name)
+	})
+
+	return components
+}
+
+func (s *Server) validateComponent(ctx context.Context, comp app.SnapshotComponent, snap *app.SnapshotSpec, p policy.Policy, evaluators []evaluator.Evaluator) applicationsnapshot.Component {
+	c := applicationsnapshot.Component{SnapshotComponent: comp}
+
+	out, err := s.validate(ctx, comp, snap, p, evaluators, false)
+	if err != nil {
+		log.Debugf("Reporting the evaluation error of component %q: %v", comp.ContainerImage, err)
+		c.EvaluationError = err.Error()
+		c.TimedOut = errors.Is(err, context.DeadlineExceeded)
+		return c
+	}
+
+	c.Violations = out.Violations()
+	c.Warnings = out.Warnings()
+	c.SuccessCount = len(out.Successes())
+	c.Signatures = out.Signatures
+	c.Attestations = out.Attestations
+	c.ContainerImage = out.ImageURL
+	c.Success = len(c.Violations) == 0
+
+	return c
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(errorResponse{Error: err.Error()}); err != nil {
+		log.Errorf("Unable to write the error response: %v", err)
+	}
+}
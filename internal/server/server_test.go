@@ -0,0 +1,248 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	ecc "github.com/enterprise-contract/enterprise-contract-controller/api/v1alpha1"
+	app "github.com/konflux-ci/application-api/api/v1alpha1"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/enterprise-contract/ec-cli/internal/applicationsnapshot"
+	"github.com/enterprise-contract/ec-cli/internal/evaluator"
+	"github.com/enterprise-contract/ec-cli/internal/output"
+	"github.com/enterprise-contract/ec-cli/internal/policy"
+	"github.com/enterprise-contract/ec-cli/internal/utils"
+)
+
+func validator(_ context.Context, comp app.SnapshotComponent, _ *app.SnapshotSpec, _ policy.Policy, _ []evaluator.Evaluator, _ bool) (*output.Output, error) {
+	if strings.Contains(comp.ContainerImage, "broken") {
+		return nil, errors.New("broken image")
+	}
+
+	outcome := evaluator.Outcome{
+		Successes: []evaluator.Result{{Message: "pass"}},
+	}
+	if strings.Contains(comp.ContainerImage, "bad") {
+		outcome.Failures = []evaluator.Result{{Message: "fail", Metadata: map[string]any{"code": "policy.bad"}}}
+	}
+
+	return &output.Output{
+		ImageAccessibleCheck:      output.VerificationStatus{Passed: true},
+		ImageSignatureCheck:       output.VerificationStatus{Passed: true},
+		AttestationSignatureCheck: output.VerificationStatus{Passed: true},
+		AttestationSyntaxCheck:    output.VerificationStatus{Passed: true},
+		PolicyCheck:               []evaluator.Outcome{outcome},
+		ImageURL:                  comp.ContainerImage,
+	}, nil
+}
+
+func policyJSON(t *testing.T) string {
+	p, err := json.Marshal(ecc.EnterpriseContractPolicySpec{PublicKey: utils.TestPublicKey})
+	require.NoError(t, err)
+	return string(p)
+}
+
+func serve(t *testing.T, s *Server, method string, body string) *httptest.ResponseRecorder {
+	ctx := utils.WithFS(context.Background(), afero.NewMemMapFs())
+	req := httptest.NewRequest(method, "/validate", strings.NewReader(body)).WithContext(ctx)
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestValidate(t *testing.T) {
+	s := New(validator, Options{Workers: 2, IgnoreRekor: true})
+
+	body, err := json.Marshal(ValidateRequest{
+		Snapshot: app.SnapshotSpec{
+			Components: []app.SnapshotComponent{
+				{Name: "good", ContainerImage: "registry.io/good:latest"},
+				{Name: "bad", ContainerImage: "registry.io/bad:latest"},
+				{Name: "broken", ContainerImage: "registry.io/broken:latest"},
+			},
+		},
+		Policy: policyJSON(t),
+	})
+	require.NoError(t, err)
+
+	rec := serve(t, s, http.MethodPost, string(body))
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+
+	var report applicationsnapshot.Report
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &report))
+	assert.False(t, report.Success)
+	require.Len(t, report.Components, 3)
+
+	components := map[string]applicationsnapshot.Component{}
+	for _, c := range report.Components {
+		components[c.Name] = c
+	}
+
+	assert.True(t, components["good"].Success)
+
+	assert.False(t, components["bad"].Success)
+	require.Len(t, components["bad"].Violations, 1)
+	assert.Equal(t, "policy.bad", components["bad"].Violations[0].Metadata["code"])
+
+	assert.False(t, components["broken"].Success)
+	assert.Equal(t, "broken image", components["broken"].EvaluationError)
+}
+
+func TestValidateBadRequest(t *testing.T) {
+	component := `"snapshot": {"components": [{"name": "a", "containerImage": "registry.io/a:latest"}]}`
+
+	cases := []struct {
+		name     string
+		body     string
+		expected string
+	}{
+		{
+			name:     "not JSON",
+			body:     "spam",
+			expected: "unable to parse the request",
+		},
+		{
+			name:     "unknown field",
+			body:     `{"spam": true}`,
+			expected: "unable to parse the request",
+		},
+		{
+			name:     "no components",
+			body:     `{"policy": "{}"}`,
+			expected: "the snapshot has no components to validate",
+		},
+		{
+			name:     "no policy",
+			body:     `{` + component + `}`,
+			expected: "the policy is required",
+		},
+		{
+			name:     "policy file",
+			body:     `{` + component + `, "policy": "/etc/policy.yaml"}`,
+			expected: "the policy can not reference a file of the server",
+		},
+		{
+			name:     "invalid policy",
+			body:     `{` + component + `, "policy": "{\"publicKey\": \"spam\"}"}`,
+			expected: "loading the policy",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			s := New(validator, Options{IgnoreRekor: true})
+			rec := serve(t, s, http.MethodPost, c.body)
+			assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+			var resp errorResponse
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+			assert.Contains(t, resp.Error, c.expected)
+		})
+	}
+}
+
+func TestValidateLocalSources(t *testing.T) {
+	cases := []struct {
+		name   string
+		source ecc.Source
+	}{
+		{name: "policy directory", source: ecc.Source{Policy: []string{"/etc/policy"}}},
+		{name: "relative data directory", source: ecc.Source{Policy: []string{"github.com/org/policy//policy"}, Data: []string{"../data"}}},
+		{name: "forced file getter", source: ecc.Source{Data: []string{"file::/var/run/secrets"}}},
+		{name: "forced git getter", source: ecc.Source{Policy: []string{"git::file:///srv/policy/.git"}}},
+		{name: "kubernetes secret", source: ecc.Source{Data: []string{"k8s://ns/secret"}}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			p, err := json.Marshal(ecc.EnterpriseContractPolicySpec{
+				PublicKey: utils.TestPublicKey,
+				Sources:   []ecc.Source{{Policy: []string{"oci::registry.io/policy:latest"}}, c.source},
+			})
+			require.NoError(t, err)
+
+			body, err := json.Marshal(ValidateRequest{
+				Snapshot: app.SnapshotSpec{Components: []app.SnapshotComponent{{Name: "a", ContainerImage: "registry.io/a:latest"}}},
+				Policy:   string(p),
+			})
+			require.NoError(t, err)
+
+			rec := serve(t, New(validator, Options{IgnoreRekor: true}), http.MethodPost, string(body))
+			assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+			var resp errorResponse
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+			assert.Contains(t, resp.Error, "can not reference a file or a Kubernetes resource of the server")
+		})
+	}
+}
+
+func TestValidateToken(t *testing.T) {
+	s := New(validator, Options{IgnoreRekor: true, Token: "s3cr3t"})
+
+	cases := []struct {
+		name          string
+		authorization string
+		expected      int
+	}{
+		{name: "no token", expected: http.StatusUnauthorized},
+		{name: "wrong token", authorization: "Bearer spam", expected: http.StatusUnauthorized},
+		{name: "not a bearer token", authorization: "Basic s3cr3t", expected: http.StatusUnauthorized},
+		// The request is authorized, and then rejected for its body
+		{name: "valid token", authorization: "Bearer s3cr3t", expected: http.StatusBadRequest},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/validate", strings.NewReader("{}"))
+			if c.authorization != "" {
+				req.Header.Set("Authorization", c.authorization)
+			}
+			rec := httptest.NewRecorder()
+			s.ServeHTTP(rec, req)
+			assert.Equal(t, c.expected, rec.Code)
+		})
+	}
+
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+}
+
+func TestValidateMethodNotAllowed(t *testing.T) {
+	rec := serve(t, New(validator, Options{}), http.MethodGet, "")
+	assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+}
+
+func TestHealthz(t *testing.T) {
+	rec := httptest.NewRecorder()
+	New(validator, Options{}).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+}
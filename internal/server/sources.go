@@ -0,0 +1,108 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package server
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/enterprise-contract/ec-cli/internal/policy/source"
+	"github.com/enterprise-contract/ec-cli/internal/utils"
+)
+
+var now = time.Now
+
+// sourceCache holds the directory the policy sources are fetched to, shared by
+// all requests. The sources are fetched once and reused by the requests until
+// the directory expires, or a source fails to be fetched. The directory and the
+// download cache are then replaced, once no request is using them.
+type sourceCache struct {
+	mu      sync.RWMutex
+	ttl     time.Duration
+	dir     string
+	expires time.Time
+	stale   atomic.Bool
+}
+
+// acquire returns the directory to fetch the policy sources to, refreshing it
+// as needed. The returned function must be called once the request no longer
+// uses the sources.
+func (c *sourceCache) acquire(ctx context.Context) (string, func(), error) {
+	for {
+		c.mu.RLock()
+		if c.valid() {
+			return c.dir, c.mu.RUnlock, nil
+		}
+		c.mu.RUnlock()
+
+		if err := c.refresh(ctx); err != nil {
+			return "", nil, err
+		}
+	}
+}
+
+// invalidate marks the sources to be fetched again by the next request, e.g.
+// after a source failed to be fetched.
+func (c *sourceCache) invalidate() {
+	c.stale.Store(true)
+}
+
+// close removes the fetched sources
+func (c *sourceCache) close(ctx context.Context) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.dir != "" {
+		utils.CleanupWorkDir(utils.FS(ctx), c.dir)
+		c.dir = ""
+	}
+}
+
+func (c *sourceCache) valid() bool {
+	return c.dir != "" && !c.stale.Load() && (c.ttl <= 0 || now().Before(c.expires))
+}
+
+func (c *sourceCache) refresh(ctx context.Context) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// Another request might have refreshed the sources meanwhile
+	if c.valid() {
+		return nil
+	}
+
+	fs := utils.FS(ctx)
+	if c.dir != "" {
+		log.Debugf("Refreshing the policy sources fetched to %s", c.dir)
+		utils.CleanupWorkDir(fs, c.dir)
+		c.dir = ""
+	}
+	source.ResetDownloadCache()
+
+	dir, err := utils.CreateWorkDir(fs)
+	if err != nil {
+		return err
+	}
+	c.dir = dir
+	c.expires = now().Add(c.ttl)
+	c.stale.Store(false)
+
+	return nil
+}
@@ -0,0 +1,87 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package server
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/enterprise-contract/ec-cli/internal/utils"
+)
+
+func TestSourceCache(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	ctx := utils.WithFS(context.Background(), fs)
+
+	current := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	t.Cleanup(func() { now = time.Now })
+	now = func() time.Time { return current }
+
+	c := &sourceCache{ttl: time.Minute}
+
+	acquire := func() string {
+		dir, release, err := c.acquire(ctx)
+		require.NoError(t, err)
+		release()
+		return dir
+	}
+
+	first := acquire()
+	assert.Equal(t, first, acquire(), "sources are reused")
+
+	current = current.Add(2 * time.Minute)
+	second := acquire()
+	assert.NotEqual(t, first, second, "expired sources are fetched again")
+	exists, err := afero.DirExists(fs, first)
+	require.NoError(t, err)
+	assert.False(t, exists)
+
+	c.invalidate()
+	third := acquire()
+	assert.NotEqual(t, second, third, "invalidated sources are fetched again")
+
+	c.close(ctx)
+	exists, err = afero.DirExists(fs, third)
+	require.NoError(t, err)
+	assert.False(t, exists)
+}
+
+func TestSourceCacheWithoutTTL(t *testing.T) {
+	ctx := utils.WithFS(context.Background(), afero.NewMemMapFs())
+
+	t.Cleanup(func() { now = time.Now })
+	now = func() time.Time { return time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC) }
+
+	c := &sourceCache{}
+	first, release, err := c.acquire(ctx)
+	require.NoError(t, err)
+	release()
+
+	now = func() time.Time { return time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC) }
+	second, release, err := c.acquire(ctx)
+	require.NoError(t, err)
+	release()
+
+	assert.Equal(t, first, second)
+}
@@ -151,3 +151,34 @@ func addCertificateMetadataTo(where *map[string]string, cer *x509.Certificate) e
 
 	return nil
 }
+
+// identityFrom returns the identity of the signer the certificate was issued
+// to, as matched by the --certificate-identity and --certificate-oidc-issuer
+// options of keyless verification. Returns nil when the certificate holds
+// neither a subject alternative name nor a Fulcio issuer.
+func identityFrom(cer *x509.Certificate) (*CertificateIdentity, error) {
+	var id CertificateIdentity
+	if sans := cryptoutils.GetSubjectAlternateNames(cer); len(sans) > 0 {
+		id.Subject = sans[0]
+	}
+
+	for _, issuer := range []extract{
+		certificateMetadata["Fulcio Issuer (V2)"],
+		certificateMetadata["Fulcio Issuer"],
+	} {
+		v, err := issuer(cer)
+		if err != nil {
+			return nil, err
+		}
+		if v != "" {
+			id.Issuer = v
+			break
+		}
+	}
+
+	if id == (CertificateIdentity{}) {
+		return nil, nil
+	}
+
+	return &id, nil
+}
@@ -73,3 +73,41 @@ func TestNewEntitySignature(t *testing.T) {
 
 	snaps.MatchSnapshot(t, es)
 }
+
+func TestIdentityFrom(t *testing.T) {
+	cases := []struct {
+		name     string
+		cert     []byte
+		expected *CertificateIdentity
+	}{
+		{
+			name: "Fulcio certificate",
+			cert: ChainguardReleaseCert,
+			expected: &CertificateIdentity{
+				Subject: "https://github.com/chainguard-images/images/.github/workflows/release.yaml@refs/heads/main",
+				Issuer:  "https://token.actions.githubusercontent.com",
+			},
+		},
+		{
+			name:     "no Fulcio issuer",
+			cert:     OtherNameSAN,
+			expected: &CertificateIdentity{Subject: "http://foobar.dev"},
+		},
+		{
+			name: "no identity",
+			cert: SigstoreChainCert,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			p, _ := pem.Decode(c.cert)
+			cer, err := x509.ParseCertificate(p.Bytes)
+			require.NoError(t, err)
+
+			id, err := identityFrom(cer)
+			require.NoError(t, err)
+			assert.Equal(t, c.expected, id)
+		})
+	}
+}
@@ -0,0 +1,318 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package signature
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/notaryproject/notation-go"
+	"github.com/notaryproject/notation-go/dir"
+	"github.com/notaryproject/notation-go/registry"
+	"github.com/notaryproject/notation-go/verifier"
+	"github.com/notaryproject/notation-go/verifier/trustpolicy"
+	"github.com/notaryproject/notation-go/verifier/truststore"
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
+
+	"github.com/enterprise-contract/ec-cli/internal/utils/oci"
+)
+
+// The verifiers of image signatures, see WithVerifier.
+const (
+	// CosignVerifier verifies cosign signatures, the default.
+	CosignVerifier = "cosign"
+	// NotationVerifier verifies Notation (Notary Project) signatures against
+	// a Notation trust policy, see WithNotationOptions.
+	NotationVerifier = "notation"
+)
+
+// Verifiers lists the supported verifiers of image signatures.
+var Verifiers = []string{CosignVerifier, NotationVerifier}
+
+// maxNotationSignatures is the maximum number of Notation signatures of an
+// image evaluated before the verification fails.
+const maxNotationSignatures = 50
+
+// maxNotationSignatureSize is the maximum size of a Notation signature
+// envelope, the same limit as the one used by notation.
+const maxNotationSignatureSize = 32 * 1024 * 1024
+
+type contextKey string
+
+const (
+	verifierKey        contextKey = "ec.signature.verifier"
+	notationOptionsKey contextKey = "ec.signature.notation_options"
+)
+
+// WithVerifier returns a context in which image signatures are verified by
+// the given verifier, one of Verifiers.
+func WithVerifier(ctx context.Context, verifier string) context.Context {
+	return context.WithValue(ctx, verifierKey, verifier)
+}
+
+// Verifier returns the verifier of image signatures set on the context,
+// CosignVerifier by default.
+func Verifier(ctx context.Context) string {
+	if v, ok := ctx.Value(verifierKey).(string); ok && v != "" {
+		return v
+	}
+
+	return CosignVerifier
+}
+
+// NotationOptions configures the verification of Notation signatures.
+type NotationOptions struct {
+	// TrustPolicy is the trust policy document the signatures are verified
+	// against. When nil, the trust policy and the trust store of the notation
+	// configuration, i.e. $XDG_CONFIG_HOME/notation, are used.
+	TrustPolicy *trustpolicy.Document
+	// TrustStore is the directory of the trust store, holding the trusted
+	// certificates in x509/<type>/<name>/ directories as referenced by the
+	// trust policy. Defaults to the directory of the notation configuration.
+	TrustStore string
+}
+
+// WithNotationOptions returns a context in which Notation signatures are
+// verified with the given options.
+func WithNotationOptions(ctx context.Context, opts NotationOptions) context.Context {
+	return context.WithValue(ctx, notationOptionsKey, opts)
+}
+
+func notationOptions(ctx context.Context) NotationOptions {
+	if opts, ok := ctx.Value(notationOptionsKey).(NotationOptions); ok {
+		return opts
+	}
+
+	return NotationOptions{}
+}
+
+// LoadNotationTrustPolicy reads the Notation trust policy document, i.e.
+// trustpolicy.json, from the file at the given path.
+func LoadNotationTrustPolicy(fs afero.Fs, path string) (*trustpolicy.Document, error) {
+	content, err := afero.ReadFile(fs, path)
+	if err != nil {
+		return nil, err
+	}
+
+	var doc trustpolicy.Document
+	if err := json.Unmarshal(content, &doc); err != nil {
+		return nil, fmt.Errorf("reading the Notation trust policy from %s: %w", path, err)
+	}
+
+	if err := doc.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid Notation trust policy %s: %w", path, err)
+	}
+
+	return &doc, nil
+}
+
+// VerifyNotation verifies the Notation signatures attached to the image as
+// OCI referrers against the trust policy from the context, see
+// WithNotationOptions. It returns the verified signature.
+func VerifyNotation(ctx context.Context, ref name.Digest) ([]EntitySignature, error) {
+	v, err := notationVerifier(notationOptions(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("creating the Notation verifier: %w", err)
+	}
+
+	repo := &notationRepository{client: oci.NewClient(ctx), repository: ref.Context()}
+	_, outcomes, err := notation.Verify(ctx, v, repo, notation.VerifyOptions{
+		ArtifactReference:    ref.String(),
+		MaxSignatureAttempts: maxNotationSignatures,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("verifying the Notation signatures of %s: %w", ref, err)
+	}
+
+	signatures := make([]EntitySignature, 0, len(outcomes))
+	for _, o := range outcomes {
+		es, err := notationEntitySignature(o)
+		if err != nil {
+			return nil, err
+		}
+		signatures = append(signatures, es)
+	}
+	log.Debugf("Verified %d Notation signature(s) of %s", len(signatures), ref)
+
+	return signatures, nil
+}
+
+func notationVerifier(opts NotationOptions) (notation.Verifier, error) {
+	if opts.TrustPolicy == nil {
+		return verifier.NewFromConfig()
+	}
+
+	trustStore := dir.ConfigFS()
+	if opts.TrustStore != "" {
+		trustStore = dir.NewSysFS(opts.TrustStore)
+	}
+
+	return verifier.New(opts.TrustPolicy, truststore.NewX509TrustStore(trustStore), nil)
+}
+
+// notationEntitySignature creates the EntitySignature of a verified Notation
+// signature, the certificate being the signing certificate of the chain in
+// the signature envelope.
+func notationEntitySignature(outcome *notation.VerificationOutcome) (EntitySignature, error) {
+	es := EntitySignature{
+		Metadata: map[string]string{},
+	}
+
+	if outcome.EnvelopeContent == nil {
+		return EntitySignature{}, errors.New("no content in the verified Notation signature")
+	}
+	signer := outcome.EnvelopeContent.SignerInfo
+	es.Signature = base64.StdEncoding.EncodeToString(signer.Signature)
+
+	if !signer.SignedAttributes.SigningTime.IsZero() {
+		signedAt := signer.SignedAttributes.SigningTime.UTC()
+		es.SignedAt = &signedAt
+	}
+
+	for i, c := range signer.CertificateChain {
+		if i > 0 {
+			es.Chain = append(es.Chain, certificatePEM(c))
+			continue
+		}
+
+		es.Certificate = certificatePEM(c)
+		es.KeyID = hex.EncodeToString(c.SubjectKeyId)
+		if err := addCertificateMetadataTo(&es.Metadata, c); err != nil {
+			return EntitySignature{}, err
+		}
+	}
+
+	return es, nil
+}
+
+func certificatePEM(c *x509.Certificate) string {
+	return string(pem.EncodeToMemory(&pem.Block{
+		Type:  "CERTIFICATE",
+		Bytes: c.Raw,
+	}))
+}
+
+// notationRepository is the repository of the image the Notation signatures
+// are read from, through the OCI client, making use of its caching and of the
+// registry credentials.
+type notationRepository struct {
+	client     oci.Client
+	repository name.Repository
+}
+
+func (r *notationRepository) Resolve(_ context.Context, reference string) (ocispec.Descriptor, error) {
+	var ref name.Reference
+	var err error
+	if _, e := digest.Parse(reference); e == nil {
+		ref, err = name.NewDigest(r.repository.Name() + "@" + reference)
+	} else {
+		ref, err = name.NewTag(r.repository.Name() + ":" + reference)
+	}
+	if err != nil {
+		return ocispec.Descriptor{}, err
+	}
+
+	desc, err := r.client.Head(ref)
+	if err != nil {
+		return ocispec.Descriptor{}, err
+	}
+
+	return ociDescriptor(*desc), nil
+}
+
+func (r *notationRepository) ListSignatures(_ context.Context, desc ocispec.Descriptor, fn func(signatureManifests []ocispec.Descriptor) error) error {
+	index, err := r.client.Referrers(r.repository.Digest(desc.Digest.String()), registry.ArtifactTypeNotation)
+	if err != nil {
+		return err
+	}
+
+	manifest, err := index.IndexManifest()
+	if err != nil {
+		return err
+	}
+
+	signatures := make([]ocispec.Descriptor, 0, len(manifest.Manifests))
+	for _, m := range manifest.Manifests {
+		if m.ArtifactType == registry.ArtifactTypeNotation {
+			signatures = append(signatures, ociDescriptor(m))
+		}
+	}
+
+	return fn(signatures)
+}
+
+func (r *notationRepository) FetchSignatureBlob(_ context.Context, desc ocispec.Descriptor) ([]byte, ocispec.Descriptor, error) {
+	img, err := r.client.Image(r.repository.Digest(desc.Digest.String()))
+	if err != nil {
+		return nil, ocispec.Descriptor{}, err
+	}
+
+	manifest, err := img.Manifest()
+	if err != nil {
+		return nil, ocispec.Descriptor{}, err
+	}
+	if len(manifest.Layers) != 1 {
+		return nil, ocispec.Descriptor{}, fmt.Errorf("the Notation signature manifest %s has %d layers, expected exactly one signature envelope", desc.Digest, len(manifest.Layers))
+	}
+	blob := manifest.Layers[0]
+	if blob.Size > maxNotationSignatureSize {
+		return nil, ocispec.Descriptor{}, fmt.Errorf("the Notation signature envelope %s is too large: %d bytes", blob.Digest, blob.Size)
+	}
+
+	layer, err := img.LayerByDigest(blob.Digest)
+	if err != nil {
+		return nil, ocispec.Descriptor{}, err
+	}
+	rc, err := layer.Compressed()
+	if err != nil {
+		return nil, ocispec.Descriptor{}, err
+	}
+	defer rc.Close()
+
+	content, err := io.ReadAll(io.LimitReader(rc, maxNotationSignatureSize))
+	if err != nil {
+		return nil, ocispec.Descriptor{}, err
+	}
+
+	return content, ociDescriptor(blob), nil
+}
+
+func (r *notationRepository) PushSignature(context.Context, string, []byte, ocispec.Descriptor, map[string]string) (ocispec.Descriptor, ocispec.Descriptor, error) {
+	return ocispec.Descriptor{}, ocispec.Descriptor{}, errors.New("pushing Notation signatures is not supported")
+}
+
+func ociDescriptor(d v1.Descriptor) ocispec.Descriptor {
+	return ocispec.Descriptor{
+		MediaType:    string(d.MediaType),
+		ArtifactType: d.ArtifactType,
+		Digest:       digest.Digest(d.Digest.String()),
+		Size:         d.Size,
+		Annotations:  d.Annotations,
+	}
+}
@@ -0,0 +1,213 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package signature
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/static"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+	"github.com/notaryproject/notation-core-go/signature/jws"
+	"github.com/notaryproject/notation-go"
+	"github.com/notaryproject/notation-go/registry"
+	"github.com/notaryproject/notation-go/signer"
+	"github.com/notaryproject/notation-go/verifier/trustpolicy"
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/enterprise-contract/ec-cli/internal/utils/oci"
+	"github.com/enterprise-contract/ec-cli/internal/utils/oci/fake"
+)
+
+const trustPolicy = `{
+  "version": "1.0",
+  "trustPolicies": [
+    {
+      "name": "test",
+      "registryScopes": ["*"],
+      "signatureVerification": {"level": "strict"},
+      "trustStores": ["ca:test"],
+      "trustedIdentities": ["*"]
+    }
+  ]
+}`
+
+func TestVerifier(t *testing.T) {
+	assert.Equal(t, CosignVerifier, Verifier(context.Background()))
+	assert.Equal(t, NotationVerifier, Verifier(WithVerifier(context.Background(), NotationVerifier)))
+}
+
+func TestLoadNotationTrustPolicy(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "trustpolicy.json", []byte(trustPolicy), 0o644))
+	require.NoError(t, afero.WriteFile(fs, "invalid.json", []byte(`{"version": "0.1"}`), 0o644))
+	require.NoError(t, afero.WriteFile(fs, "garbage.json", []byte(`garbage`), 0o644))
+
+	doc, err := LoadNotationTrustPolicy(fs, "trustpolicy.json")
+	require.NoError(t, err)
+	assert.Equal(t, "test", doc.TrustPolicies[0].Name)
+
+	_, err = LoadNotationTrustPolicy(fs, "invalid.json")
+	assert.ErrorContains(t, err, "invalid Notation trust policy invalid.json")
+
+	_, err = LoadNotationTrustPolicy(fs, "garbage.json")
+	assert.ErrorContains(t, err, "reading the Notation trust policy from garbage.json")
+
+	_, err = LoadNotationTrustPolicy(fs, "missing.json")
+	assert.Error(t, err)
+}
+
+// notationCertificates returns the signing key and the certificate chain of
+// the signing certificate issued by a root CA.
+func notationCertificates(t *testing.T) (*ecdsa.PrivateKey, []*x509.Certificate) {
+	rootKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	rootTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "Root", Organization: []string{"Test"}},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	rootDER, err := x509.CreateCertificate(rand.Reader, rootTemplate, rootTemplate, rootKey.Public(), rootKey)
+	require.NoError(t, err)
+	root, err := x509.ParseCertificate(rootDER)
+	require.NoError(t, err)
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	leafTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(2),
+		Subject:               pkix.Name{CommonName: "Signer", Organization: []string{"Test"}},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageCodeSigning},
+		BasicConstraintsValid: true,
+		SubjectKeyId:          []byte{1, 2, 3, 4},
+	}
+	leafDER, err := x509.CreateCertificate(rand.Reader, leafTemplate, root, key.Public(), rootKey)
+	require.NoError(t, err)
+	leaf, err := x509.ParseCertificate(leafDER)
+	require.NoError(t, err)
+
+	return key, []*x509.Certificate{leaf, root}
+}
+
+func TestVerifyNotation(t *testing.T) {
+	ctx := context.Background()
+	key, chain := notationCertificates(t)
+
+	trustStore := t.TempDir()
+	store := filepath.Join(trustStore, "truststore", "x509", "ca", "test")
+	require.NoError(t, os.MkdirAll(store, 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(store, "root.pem"), pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: chain[1].Raw}), 0o644))
+
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "trustpolicy.json", []byte(trustPolicy), 0o644))
+	doc, err := LoadNotationTrustPolicy(fs, "trustpolicy.json")
+	require.NoError(t, err)
+
+	subject := ocispec.Descriptor{
+		MediaType: ocispec.MediaTypeImageManifest,
+		Digest:    digest.FromString("image"),
+		Size:      123,
+	}
+	ref, err := name.NewDigest("registry.io/repository/image@" + subject.Digest.String())
+	require.NoError(t, err)
+
+	s, err := signer.NewGenericSigner(key, chain)
+	require.NoError(t, err)
+	envelope, _, err := s.Sign(ctx, subject, notation.SignerSignOptions{SignatureMediaType: jws.MediaTypeEnvelope})
+	require.NoError(t, err)
+
+	sigImage, err := mutate.AppendLayers(mutate.MediaType(empty.Image, types.OCIManifestSchema1), static.NewLayer(envelope, jws.MediaTypeEnvelope))
+	require.NoError(t, err)
+	sigImage = mutate.ConfigMediaType(sigImage, registry.ArtifactTypeNotation)
+	sigDigest, err := sigImage.Digest()
+	require.NoError(t, err)
+
+	referrers := mutate.IndexMediaType(empty.Index, types.OCIImageIndex)
+	referrers = mutate.AppendManifests(referrers, mutate.IndexAddendum{
+		Add: sigImage,
+	})
+
+	client := fake.FakeClient{}
+	client.On("Head", mock.Anything).Return(&v1.Descriptor{
+		MediaType: types.OCIManifestSchema1,
+		Digest:    v1.Hash{Algorithm: "sha256", Hex: subject.Digest.Encoded()},
+		Size:      subject.Size,
+	}, nil)
+	client.On("Referrers", ref, registry.ArtifactTypeNotation).Return(referrers, nil)
+	client.On("Image", ref.Context().Digest(sigDigest.String())).Return(sigImage, nil)
+	ctx = oci.WithClient(ctx, &client)
+
+	t.Run("verified", func(t *testing.T) {
+		ctx := WithNotationOptions(ctx, NotationOptions{TrustPolicy: doc, TrustStore: trustStore})
+		signatures, err := VerifyNotation(ctx, ref)
+		require.NoError(t, err)
+		require.Len(t, signatures, 1)
+		assert.Equal(t, "01020304", signatures[0].KeyID)
+		assert.Contains(t, signatures[0].Certificate, "BEGIN CERTIFICATE")
+		assert.Len(t, signatures[0].Chain, 1)
+		assert.NotNil(t, signatures[0].SignedAt)
+	})
+
+	t.Run("untrusted", func(t *testing.T) {
+		untrusted := &trustpolicy.Document{
+			Version: "1.0",
+			TrustPolicies: []trustpolicy.TrustPolicy{{
+				Name:                  "other",
+				RegistryScopes:        []string{"*"},
+				SignatureVerification: trustpolicy.SignatureVerification{VerificationLevel: "strict"},
+				TrustStores:           []string{"ca:other"},
+				TrustedIdentities:     []string{"*"},
+			}},
+		}
+		other := filepath.Join(trustStore, "truststore", "x509", "ca", "other")
+		require.NoError(t, os.MkdirAll(other, 0o755))
+		_, otherChain := notationCertificates(t)
+		require.NoError(t, os.WriteFile(filepath.Join(other, "root.pem"), pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: otherChain[1].Raw}), 0o644))
+
+		ctx := WithNotationOptions(ctx, NotationOptions{TrustPolicy: untrusted, TrustStore: trustStore})
+		_, err := VerifyNotation(ctx, ref)
+		assert.ErrorContains(t, err, "verifying the Notation signatures of "+ref.String())
+	})
+}
@@ -19,6 +19,8 @@ package signature
 import (
 	"encoding/hex"
 	"encoding/pem"
+	"fmt"
+	"time"
 
 	"github.com/sigstore/cosign/v2/pkg/oci"
 )
@@ -29,6 +31,25 @@ type EntitySignature struct {
 	Certificate string            `json:"certificate,omitempty"`
 	Chain       []string          `json:"chain,omitempty"`
 	Metadata    map[string]string `json:"metadata,omitempty"`
+	// CertificateIdentity is the identity of the signer from the certificate,
+	// present only for signatures made with a certificate, i.e. keyless.
+	CertificateIdentity *CertificateIdentity `json:"identity,omitempty"`
+	// TransparencyLog is the entry of the signature in the Rekor transparency
+	// log, present only when the signature was looked up in the log, see
+	// VerifyInTransparencyLog.
+	TransparencyLog *TransparencyLogEntry `json:"transparencyLog,omitempty"`
+	// SignedAt is the time the signature was made, if known. It is the time
+	// of the transparency log entry, or lacking one the start of the validity
+	// of the certificate.
+	SignedAt *time.Time `json:"-"`
+}
+
+// CertificateIdentity is the signer identity verified by keyless
+// verification: the subject alternative name and the OIDC issuer from the
+// Fulcio certificate.
+type CertificateIdentity struct {
+	Subject string `json:"subject,omitempty"`
+	Issuer  string `json:"issuer,omitempty"`
 }
 
 // NewEntitySignature creates a new EntitySignature from the given Signature.
@@ -57,6 +78,26 @@ func NewEntitySignature(sig oci.Signature) (EntitySignature, error) {
 		if err := addCertificateMetadataTo(&es.Metadata, cert); err != nil {
 			return EntitySignature{}, err
 		}
+
+		if es.CertificateIdentity, err = identityFrom(cert); err != nil {
+			return EntitySignature{}, err
+		}
+	}
+
+	if l, ok := sig.(loggedSignature); ok {
+		es.TransparencyLog = l.transparencyLogEntry()
+	}
+
+	bundle, err := sig.Bundle()
+	if err != nil {
+		return EntitySignature{}, err
+	}
+	if bundle != nil && bundle.Payload.IntegratedTime > 0 {
+		signedAt := time.Unix(bundle.Payload.IntegratedTime, 0).UTC()
+		es.SignedAt = &signedAt
+	} else if cert != nil && !cert.NotBefore.IsZero() {
+		signedAt := cert.NotBefore.UTC()
+		es.SignedAt = &signedAt
 	}
 
 	chain, err := sig.Chain()
@@ -74,3 +115,29 @@ func NewEntitySignature(sig oci.Signature) (EntitySignature, error) {
 	}
 	return es, nil
 }
+
+// Identity returns a value identifying the signer. For signatures made with a
+// certificate this is the subject alternative name, or the subject key ID when
+// there is none, and the issuer of the certificate. Otherwise it is the key ID,
+// which is empty for signatures verified with a public key, making all of
+// those the same signer.
+func (es EntitySignature) Identity() string {
+	if es.Certificate == "" {
+		return "key:" + es.KeyID
+	}
+
+	issuer := es.Metadata["Fulcio Issuer (V2)"]
+	if issuer == "" {
+		issuer = es.Metadata["Fulcio Issuer"]
+	}
+	if issuer == "" {
+		issuer = es.Metadata["Issuer"]
+	}
+
+	subject := es.Metadata["Subject Alternative Name"]
+	if subject == "" {
+		subject = es.KeyID
+	}
+
+	return fmt.Sprintf("certificate:%s;%s", subject, issuer)
+}
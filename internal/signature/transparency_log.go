@@ -0,0 +1,135 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package signature
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/sigstore/cosign/v2/pkg/cosign"
+	"github.com/sigstore/cosign/v2/pkg/cosign/bundle"
+	"github.com/sigstore/cosign/v2/pkg/oci"
+	"github.com/sigstore/rekor/pkg/generated/models"
+	"github.com/sigstore/sigstore/pkg/cryptoutils"
+)
+
+// TransparencyLogEntry is the entry of a signature in the Rekor transparency
+// log, as found by looking it up in the log.
+type TransparencyLogEntry struct {
+	LogIndex       int64     `json:"logIndex"`
+	LogID          string    `json:"logID"`
+	IntegratedTime time.Time `json:"integratedTime"`
+}
+
+// ociSignature allows embedding oci.Signature, which has a Signature method
+// that would otherwise conflict with the name of the embedded field.
+type ociSignature = oci.Signature
+
+// loggedSignature is a signature found in the transparency log, its bundle is
+// the one of the verified log entry.
+type loggedSignature struct {
+	ociSignature
+	entry *models.LogEntryAnon
+}
+
+func (l loggedSignature) Bundle() (*bundle.RekorBundle, error) {
+	return bundle.EntryToBundle(l.entry), nil
+}
+
+func (l loggedSignature) transparencyLogEntry() *TransparencyLogEntry {
+	return &TransparencyLogEntry{
+		LogIndex:       *l.entry.LogIndex,
+		LogID:          *l.entry.LogID,
+		IntegratedTime: time.Unix(*l.entry.IntegratedTime, 0).UTC(),
+	}
+}
+
+// VerifyInTransparencyLog looks up the signature in the Rekor transparency log
+// of the Rekor client in the given options. The inclusion proof and the signed
+// entry timestamp of each entry found are verified with the Rekor public keys
+// in the options. The returned signature holds the earliest verified entry,
+// which is recorded in the EntitySignature created from it. This is done
+// regardless of the signature carrying a bundle, as the bundle holds only the
+// signed entry timestamp, and not the proof of the inclusion in the log.
+func VerifyInTransparencyLog(ctx context.Context, sig oci.Signature, opts *cosign.CheckOpts) (oci.Signature, error) {
+	if opts.RekorClient == nil {
+		return nil, errors.New("no Rekor URL configured to look up the signature in the transparency log")
+	}
+
+	b64sig, err := sig.Base64Signature()
+	if err != nil {
+		return nil, err
+	}
+
+	payload, err := sig.Payload()
+	if err != nil {
+		return nil, err
+	}
+
+	pem, err := signerPEM(sig, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := cosign.FindTlogEntry(ctx, opts.RekorClient, b64sig, payload, pem)
+	if err != nil {
+		return nil, fmt.Errorf("unable to find the signature in the transparency log: %w", err)
+	}
+
+	var earliest *models.LogEntryAnon
+	var errs error
+	for i := range entries {
+		e := &entries[i]
+		if err := cosign.VerifyTLogEntryOffline(ctx, e, opts.RekorPubKeys); err != nil {
+			errs = errors.Join(errs, err)
+			continue
+		}
+		if earliest == nil || *e.IntegratedTime < *earliest.IntegratedTime {
+			earliest = e
+		}
+	}
+
+	if earliest == nil {
+		return nil, fmt.Errorf("no verified entry of the signature in the transparency log: %w", errs)
+	}
+
+	return loggedSignature{sig, earliest}, nil
+}
+
+// signerPEM returns the PEM encoded public key, or the certificate, the
+// signature was made with, as recorded in the transparency log.
+func signerPEM(sig oci.Signature, opts *cosign.CheckOpts) ([]byte, error) {
+	if opts.SigVerifier != nil {
+		pub, err := opts.SigVerifier.PublicKey(opts.PKOpts...)
+		if err != nil {
+			return nil, err
+		}
+		return cryptoutils.MarshalPublicKeyToPEM(pub)
+	}
+
+	cert, err := sig.Cert()
+	if err != nil {
+		return nil, err
+	}
+	if cert == nil {
+		return nil, errors.New("no public key or certificate to look up the signature in the transparency log")
+	}
+
+	return cryptoutils.MarshalCertificateToPEM(cert)
+}
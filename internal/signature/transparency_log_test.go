@@ -0,0 +1,175 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package signature
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/sigstore/cosign/v2/cmd/cosign/cli/rekor"
+	"github.com/sigstore/cosign/v2/pkg/cosign"
+	"github.com/sigstore/cosign/v2/pkg/oci/static"
+	"github.com/sigstore/rekor/pkg/generated/models"
+	"github.com/sigstore/sigstore/pkg/tuf"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/transparency-dev/merkle/rfc6962"
+)
+
+// logEntry creates an entry of a log with a single entry, signed with the
+// given key
+func logEntry(t *testing.T, key *ecdsa.PrivateKey, logID string) (string, models.LogEntryAnon) {
+	body := base64.StdEncoding.EncodeToString([]byte(`{"kind":"hashedrekord"}`))
+	bodyBytes, err := base64.StdEncoding.DecodeString(body)
+	require.NoError(t, err)
+	leaf := rfc6962.DefaultHasher.HashLeaf(bodyBytes)
+	root := hex.EncodeToString(leaf)
+
+	integratedTime := int64(1700000000)
+	logIndex := int64(0)
+	treeSize := int64(1)
+
+	// canonical JSON of the bundle payload, i.e. with sorted keys
+	payload := fmt.Sprintf(`{"body":%q,"integratedTime":%d,"logID":%q,"logIndex":%d}`, body, integratedTime, logID, logIndex)
+	digest := sha256.Sum256([]byte(payload))
+	set, err := ecdsa.SignASN1(rand.Reader, key, digest[:])
+	require.NoError(t, err)
+
+	return hex.EncodeToString(leaf), models.LogEntryAnon{
+		Body:           body,
+		IntegratedTime: &integratedTime,
+		LogIndex:       &logIndex,
+		LogID:          &logID,
+		Verification: &models.LogEntryAnonVerification{
+			InclusionProof: &models.InclusionProof{
+				RootHash: &root,
+				Hashes:   []string{},
+				LogIndex: &logIndex,
+				TreeSize: &treeSize,
+			},
+			SignedEntryTimestamp: set,
+		},
+	}
+}
+
+func TestVerifyInTransparencyLog(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	logID, err := cosign.GetTransparencyLogID(key.Public())
+	require.NoError(t, err)
+
+	otherKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	cases := []struct {
+		name    string
+		entries func() []map[string]models.LogEntryAnon
+		err     string
+	}{
+		{
+			name: "verified entry",
+			entries: func() []map[string]models.LogEntryAnon {
+				uuid, e := logEntry(t, key, logID)
+				return []map[string]models.LogEntryAnon{{uuid: e}}
+			},
+		},
+		{
+			name: "not found",
+			entries: func() []map[string]models.LogEntryAnon {
+				return []map[string]models.LogEntryAnon{}
+			},
+			err: "unable to find the signature in the transparency log: signature not found in transparency log",
+		},
+		{
+			name: "invalid signed entry timestamp",
+			entries: func() []map[string]models.LogEntryAnon {
+				uuid, e := logEntry(t, otherKey, logID)
+				return []map[string]models.LogEntryAnon{{uuid: e}}
+			},
+			err: "no verified entry of the signature in the transparency log: verifying signedEntryTimestamp: unable to verify SET",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, "/api/v1/log/entries/retrieve", r.URL.Path)
+				w.Header().Set("Content-Type", "application/json")
+				assert.NoError(t, json.NewEncoder(w).Encode(c.entries()))
+			}))
+			t.Cleanup(server.Close)
+
+			client, err := rekor.NewClient(server.URL)
+			require.NoError(t, err)
+
+			opts := cosign.CheckOpts{
+				RekorClient: client,
+				RekorPubKeys: &cosign.TrustedTransparencyLogPubKeys{
+					Keys: map[string]cosign.TransparencyLogPubKey{
+						logID: {PubKey: key.Public(), Status: tuf.Active},
+					},
+				},
+			}
+
+			sig, err := static.NewSignature(
+				[]byte(`image`),
+				base64.StdEncoding.EncodeToString([]byte("signature")),
+				static.WithCertChain(ChainguardReleaseCert, SigstoreChainCert),
+			)
+			require.NoError(t, err)
+
+			logged, err := VerifyInTransparencyLog(context.Background(), sig, &opts)
+			if c.err != "" {
+				assert.EqualError(t, err, c.err)
+				return
+			}
+			require.NoError(t, err)
+
+			es, err := NewEntitySignature(logged)
+			require.NoError(t, err)
+
+			integratedTime := time.Unix(1700000000, 0).UTC()
+			assert.Equal(t, &TransparencyLogEntry{
+				LogIndex:       0,
+				LogID:          logID,
+				IntegratedTime: integratedTime,
+			}, es.TransparencyLog)
+			assert.Equal(t, &integratedTime, es.SignedAt)
+		})
+	}
+}
+
+func TestVerifyInTransparencyLogWithoutClient(t *testing.T) {
+	sig, err := static.NewSignature([]byte(`image`), "signature")
+	require.NoError(t, err)
+
+	_, err = VerifyInTransparencyLog(context.Background(), sig, &cosign.CheckOpts{})
+	assert.EqualError(t, err, "no Rekor URL configured to look up the signature in the transparency log")
+}
@@ -0,0 +1,104 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// Package tracing emits OpenTelemetry traces of the major phases of ec, i.e.
+// fetching the sources, compiling the policy, evaluating each component and
+// rendering the report, exported to an OTLP endpoint.
+package tracing
+
+import (
+	"context"
+	"os"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/enterprise-contract/ec-cli/internal/version"
+)
+
+const tracerName = "github.com/enterprise-contract/ec-cli"
+
+// Attributes of the spans
+const (
+	SourceURL       = attribute.Key("ec.source.url")
+	SourceKind      = attribute.Key("ec.source.kind")
+	ComponentName   = attribute.Key("ec.component.name")
+	ComponentImage  = attribute.Key("ec.component.image")
+	ComponentDigest = attribute.Key("ec.component.digest")
+	Verdict         = attribute.Key("ec.verdict")
+	Violations      = attribute.Key("ec.violations")
+	Warnings        = attribute.Key("ec.warnings")
+	OutputFormats   = attribute.Key("ec.output")
+)
+
+// Setup configures the export of the traces to the OTLP endpoint given as a
+// URL, e.g. http://localhost:4317. When no endpoint is given the standard
+// OTEL_EXPORTER_OTLP_ENDPOINT and OTEL_EXPORTER_OTLP_TRACES_ENDPOINT
+// environment variables are honored. Without an endpoint tracing is a no-op.
+// The returned function exports any pending spans and stops the export.
+func Setup(ctx context.Context, endpoint string) (func(context.Context) error, error) {
+	noop := func(context.Context) error { return nil }
+
+	var opts []otlptracegrpc.Option
+	if endpoint != "" {
+		opts = append(opts, otlptracegrpc.WithEndpointURL(endpoint))
+	} else if os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT") == "" && os.Getenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT") == "" {
+		return noop, nil
+	}
+
+	exporter, err := otlptracegrpc.New(ctx, opts...)
+	if err != nil {
+		return noop, err
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewWithAttributes(
+		semconv.SchemaURL,
+		semconv.ServiceName("ec"),
+		semconv.ServiceVersion(version.Version),
+	))
+	if err != nil {
+		return noop, err
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+	)
+	otel.SetTracerProvider(provider)
+
+	return provider.Shutdown, nil
+}
+
+// Start starts a span with the given name and attributes, as a child of the
+// span in the context, if any.
+func Start(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return otel.Tracer(tracerName).Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// End ends the span, recording the error, if any, as the status of the span.
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
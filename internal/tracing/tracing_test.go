@@ -0,0 +1,78 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package tracing
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestSetupNoop(t *testing.T) {
+	t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", "")
+	t.Setenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT", "")
+
+	provider := otel.GetTracerProvider()
+	shutdown, err := Setup(context.Background(), "")
+	require.NoError(t, err)
+	assert.Same(t, provider, otel.GetTracerProvider())
+	assert.NoError(t, shutdown(context.Background()))
+}
+
+func TestSetup(t *testing.T) {
+	provider := otel.GetTracerProvider()
+	t.Cleanup(func() { otel.SetTracerProvider(provider) })
+
+	shutdown, err := Setup(context.Background(), "http://127.0.0.1:4317")
+	require.NoError(t, err)
+	assert.IsType(t, &sdktrace.TracerProvider{}, otel.GetTracerProvider())
+	assert.NoError(t, shutdown(context.Background()))
+}
+
+func TestStartEnd(t *testing.T) {
+	provider := otel.GetTracerProvider()
+	t.Cleanup(func() { otel.SetTracerProvider(provider) })
+
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+
+	ctx, parent := Start(context.Background(), "ec validate image")
+	_, span := Start(ctx, "fetch", SourceURL.String("https://example.com"))
+	End(span, errors.New("kaboom"))
+	End(parent, nil)
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 2)
+
+	fetch := spans[0]
+	assert.Equal(t, "fetch", fetch.Name())
+	assert.Equal(t, parent.SpanContext().SpanID(), fetch.Parent().SpanID())
+	assert.Contains(t, fetch.Attributes(), SourceURL.String("https://example.com"))
+	assert.Equal(t, codes.Error, fetch.Status().Code)
+	assert.Equal(t, "kaboom", fetch.Status().Description)
+
+	assert.Equal(t, codes.Unset, spans[1].Status().Code)
+}
@@ -90,7 +90,10 @@ func CleanupWorkDir(fs afero.Fs, path string) {
 
 type ioContextKey int
 
-const fsKey ioContextKey = 0
+const (
+	fsKey ioContextKey = iota
+	seedKey
+)
 
 func FS(ctx context.Context) afero.Fs {
 	if fs, ok := ctx.Value(fsKey).(afero.Fs); ok {
@@ -104,6 +107,18 @@ func WithFS(ctx context.Context, fs afero.Fs) context.Context {
 	return context.WithValue(ctx, fsKey, fs)
 }
 
+// WithSeed sets the seed from which the values that are otherwise random are
+// derived, making the run reproducible.
+func WithSeed(ctx context.Context, seed string) context.Context {
+	return context.WithValue(ctx, seedKey, seed)
+}
+
+// Seed returns the seed set by WithSeed, if any.
+func Seed(ctx context.Context) (string, bool) {
+	seed, ok := ctx.Value(seedKey).(string)
+	return seed, ok
+}
+
 // create a file in a temp dir with contents of data
 func WriteTempFile(ctx context.Context, data, prefix string) (string, error) {
 	fs := FS(ctx)
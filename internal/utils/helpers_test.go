@@ -270,3 +270,12 @@ func TestHasJsonOrYamlExt(t *testing.T) {
 		assert.Equal(t, tt.want, HasJsonOrYamlExt(tt.src))
 	}
 }
+
+func TestSeed(t *testing.T) {
+	_, ok := Seed(context.Background())
+	assert.False(t, ok)
+
+	seed, ok := Seed(WithSeed(context.Background(), "42"))
+	assert.True(t, ok)
+	assert.Equal(t, "42", seed)
+}
@@ -21,17 +21,19 @@ import (
 	"fmt"
 	"os"
 	"path"
+	"slices"
 	"strconv"
 	"sync"
 
-	"github.com/google/go-containerregistry/pkg/authn"
 	"github.com/google/go-containerregistry/pkg/name"
 	v1 "github.com/google/go-containerregistry/pkg/v1"
 	"github.com/google/go-containerregistry/pkg/v1/cache"
 	"github.com/google/go-containerregistry/pkg/v1/remote"
 	"github.com/sigstore/cosign/v2/pkg/cosign"
 	"github.com/sigstore/cosign/v2/pkg/oci"
+	"github.com/sigstore/cosign/v2/pkg/oci/mutate"
 	ociremote "github.com/sigstore/cosign/v2/pkg/oci/remote"
+	"github.com/sigstore/cosign/v2/pkg/oci/static"
 	log "github.com/sirupsen/logrus"
 
 	"github.com/enterprise-contract/ec-cli/internal/http"
@@ -39,8 +41,10 @@ import (
 
 // imageRefTransport is used to inject the type of transport to use with the
 // remote.WithTransport function. By default, remote.DefaultTransport is
-// equivalent to http.DefaultTransport, with a reduced timeout and keep-alive
-var imageRefTransport = remote.WithTransport(remote.DefaultTransport)
+// equivalent to http.DefaultTransport, with a reduced timeout and keep-alive,
+// here limited to the configured request rate per registry and reusing the
+// registry tokens across operations
+var imageRefTransport = remote.WithTransport(http.NewTokenCachingRoundTripper(http.NewRateLimitingRoundTripper(remote.DefaultTransport)))
 
 type contextKey string
 
@@ -50,7 +54,7 @@ var imgCache = sync.OnceValue(initCache)
 
 func init() {
 	if log.IsLevelEnabled(log.TraceLevel) {
-		imageRefTransport = remote.WithTransport(http.NewTracingRoundTripper(remote.DefaultTransport))
+		imageRefTransport = remote.WithTransport(http.NewTokenCachingRoundTripper(http.NewTracingRoundTripper(http.NewRateLimitingRoundTripper(remote.DefaultTransport))))
 	}
 }
 
@@ -85,7 +89,7 @@ func createRemoteOptions(ctx context.Context) []remote.Option {
 	return []remote.Option{
 		imageRefTransport,
 		remote.WithContext(ctx),
-		remote.WithAuthFromKeychain(authn.DefaultKeychain),
+		remote.WithAuthFromKeychain(Keychain(ctx)),
 		remote.WithRetryBackoff(backoff),
 	}
 }
@@ -98,6 +102,9 @@ type Client interface {
 	Image(name.Reference) (v1.Image, error)
 	Layer(name.Digest) (v1.Layer, error)
 	Index(name.Reference) (v1.ImageIndex, error)
+	AttachAttestation(name.Digest, []byte) error
+	WriteImage(name.Reference, v1.Image) error
+	Referrers(name.Digest, string) (v1.ImageIndex, error)
 }
 
 func WithClient(ctx context.Context, client Client) context.Context {
@@ -201,3 +208,40 @@ func (c *defaultClient) Index(ref name.Reference) (v1.ImageIndex, error) {
 
 	return index, nil
 }
+
+// AttachAttestation attaches the DSSE envelope of an attestation to the image,
+// keeping the attestations already attached to it.
+func (c *defaultClient) AttachAttestation(ref name.Digest, envelope []byte) error {
+	opts := ociremote.WithRemoteOptions(c.opts...)
+
+	se, err := ociremote.SignedEntity(ref, opts)
+	if err != nil {
+		return err
+	}
+
+	att, err := static.NewAttestation(envelope)
+	if err != nil {
+		return err
+	}
+
+	if se, err = mutate.AttachAttestationToEntity(se, att); err != nil {
+		return err
+	}
+
+	return ociremote.WriteAttestations(ref.Repository, se, opts)
+}
+
+func (c *defaultClient) WriteImage(ref name.Reference, img v1.Image) error {
+	return remote.Write(ref, img, c.opts...)
+}
+
+// Referrers returns the index of the manifests referring to the image, only
+// those of the artifact type if given.
+func (c *defaultClient) Referrers(ref name.Digest, artifactType string) (v1.ImageIndex, error) {
+	opts := c.opts
+	if artifactType != "" {
+		opts = append(slices.Clone(opts), remote.WithFilter("artifactType", artifactType))
+	}
+
+	return remote.Referrers(ref, opts...)
+}
@@ -35,6 +35,7 @@ import (
 	"github.com/google/go-containerregistry/pkg/v1/random"
 	"github.com/google/go-containerregistry/pkg/v1/remote"
 	"github.com/google/go-containerregistry/pkg/v1/types"
+	ociremote "github.com/sigstore/cosign/v2/pkg/oci/remote"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
@@ -175,6 +176,44 @@ func TestLayer(t *testing.T) {
 	assert.Equal(t, fetchCount, blobDownloadCount)
 }
 
+func TestAttachAttestation(t *testing.T) {
+	img, err := random.Image(1024, 1)
+	require.NoError(t, err)
+
+	registry := httptest.NewServer(registry.New(registry.Logger(log.New(io.Discard, "", 0))))
+	t.Cleanup(registry.Close)
+
+	u, err := url.Parse(registry.URL)
+	require.NoError(t, err)
+
+	ref, err := name.ParseReference(fmt.Sprintf("localhost:%s/repository/image:tag", u.Port()))
+	require.NoError(t, err)
+	require.NoError(t, remote.Push(ref, img))
+
+	digest, err := img.Digest()
+	require.NoError(t, err)
+	digestRef := ref.Context().Digest(digest.String())
+
+	client := defaultClient{}
+	require.NoError(t, client.AttachAttestation(digestRef, []byte(`{"payload":"b25l"}`)))
+	require.NoError(t, client.AttachAttestation(digestRef, []byte(`{"payload":"dHdv"}`)))
+
+	se, err := ociremote.SignedImage(digestRef)
+	require.NoError(t, err)
+	atts, err := se.Attestations()
+	require.NoError(t, err)
+	sigs, err := atts.Get()
+	require.NoError(t, err)
+
+	var payloads []string
+	for _, s := range sigs {
+		p, err := s.Payload()
+		require.NoError(t, err)
+		payloads = append(payloads, string(p))
+	}
+	assert.Equal(t, []string{`{"payload":"b25l"}`, `{"payload":"dHdv"}`}, payloads)
+}
+
 func TestScopedAuth(t *testing.T) {
 	cases := []struct {
 		repository string
@@ -143,3 +143,22 @@ func (m *FakeClient) Index(ref name.Reference) (v1.ImageIndex, error) {
 	}
 	return index, args.Error(1)
 }
+
+func (m *FakeClient) AttachAttestation(ref name.Digest, envelope []byte) error {
+	args := m.Called(ref, envelope)
+	return args.Error(0)
+}
+
+func (m *FakeClient) WriteImage(ref name.Reference, img v1.Image) error {
+	args := m.Called(ref, img)
+	return args.Error(0)
+}
+
+func (m *FakeClient) Referrers(ref name.Digest, artifactType string) (v1.ImageIndex, error) {
+	args := m.Called(ref, artifactType)
+	var index v1.ImageIndex
+	if maybeIndex, ok := args.Get(0).(v1.ImageIndex); ok {
+		index = maybeIndex
+	}
+	return index, args.Error(1)
+}
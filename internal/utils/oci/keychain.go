@@ -0,0 +1,113 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package oci
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/spf13/afero"
+)
+
+const keychainContextKey contextKey = "ec.oci.keychain"
+
+// Credentials holds the credentials for registries, keyed by the registry
+// host, optionally followed by a repository path, e.g. registry.io or
+// registry.io/org/repository.
+type Credentials map[string]authn.AuthConfig
+
+// LoadCredentials reads the credentials from a file in the format of the
+// Docker config.json file, i.e. {"auths": {"registry.io": {"auth": "..."}}}.
+func LoadCredentials(fs afero.Fs, path string) (Credentials, error) {
+	content, err := afero.ReadFile(fs, path)
+	if err != nil {
+		return nil, err
+	}
+
+	var config struct {
+		Auths Credentials `json:"auths"`
+	}
+	if err := json.Unmarshal(content, &config); err != nil {
+		return nil, fmt.Errorf("reading registry credentials from %s: %w", path, err)
+	}
+
+	credentials := make(Credentials, len(config.Auths))
+	for key, auth := range config.Auths {
+		credentials[normalizeCredentialsKey(key)] = auth
+	}
+
+	return credentials, nil
+}
+
+// normalizeCredentialsKey removes the scheme and API version from keys given as
+// URLs, as the Docker CLI does for Docker Hub, e.g. https://index.docker.io/v1/.
+func normalizeCredentialsKey(key string) string {
+	key = strings.TrimPrefix(strings.TrimPrefix(key, "https://"), "http://")
+	key = strings.TrimSuffix(strings.TrimSuffix(key, "/"), "/v1")
+	if key == "docker.io" {
+		return name.DefaultRegistry
+	}
+
+	return key
+}
+
+// Resolve returns the credentials for the resource. The credentials given for
+// the longest matching repository path within the registry of the resource are
+// used. Credentials are never used for a registry other than the one they are
+// given for.
+func (c Credentials) Resolve(target authn.Resource) (authn.Authenticator, error) {
+	registry := target.RegistryStr()
+
+	path := registry
+	if repo, ok := target.(name.Repository); ok {
+		path = registry + "/" + repo.RepositoryStr()
+	}
+
+	for {
+		if auth, ok := c[path]; ok {
+			return authn.FromConfig(auth), nil
+		}
+
+		i := strings.LastIndex(path, "/")
+		if i == -1 {
+			return authn.Anonymous, nil
+		}
+		path = path[:i]
+	}
+}
+
+// WithCredentials returns a context in which the given credentials are used by
+// the client created by NewClient, before the credentials from the Docker
+// configuration.
+func WithCredentials(ctx context.Context, credentials Credentials) context.Context {
+	return context.WithValue(ctx, keychainContextKey, credentials)
+}
+
+// Keychain returns the keychain resolving the credentials for registries, the
+// credentials given via WithCredentials followed by the credentials from the
+// Docker configuration.
+func Keychain(ctx context.Context) authn.Keychain {
+	if c, ok := ctx.Value(keychainContextKey).(Credentials); ok && len(c) > 0 {
+		return authn.NewMultiKeychain(c, authn.DefaultKeychain)
+	}
+
+	return authn.DefaultKeychain
+}
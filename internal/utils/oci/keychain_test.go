@@ -0,0 +1,171 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package oci
+
+import (
+	"context"
+	"encoding/base64"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadCredentials(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "config.json", []byte(`{"auths": {
+		"registry.io": {"auth": "`+base64.StdEncoding.EncodeToString([]byte("spam:eggs"))+`"},
+		"https://index.docker.io/v1/": {"username": "ham", "password": "bacon"},
+		"registry.io/org/repo": {"username": "org", "password": "secret"}
+	}}`), 0400))
+
+	credentials, err := LoadCredentials(fs, "config.json")
+	require.NoError(t, err)
+	users := map[string]string{}
+	for key, auth := range credentials {
+		users[key] = auth.Username + ":" + auth.Password
+	}
+	assert.Equal(t, map[string]string{
+		"registry.io":          "spam:eggs",
+		"index.docker.io":      "ham:bacon",
+		"registry.io/org/repo": "org:secret",
+	}, users)
+
+	require.NoError(t, afero.WriteFile(fs, "invalid.json", []byte(`{`), 0400))
+	_, err = LoadCredentials(fs, "invalid.json")
+	assert.ErrorContains(t, err, "reading registry credentials from invalid.json: ")
+}
+
+func TestCredentialsResolve(t *testing.T) {
+	credentials := Credentials{
+		"registry.io":          {Username: "registry"},
+		"registry.io/org/repo": {Username: "repo"},
+		"index.docker.io":      {Username: "hub"},
+	}
+
+	cases := []struct {
+		name     string
+		resource authn.Resource
+		expected authn.Authenticator
+	}{
+		{name: "registry", resource: name.MustParseReference("registry.io/spam/eggs").Context(), expected: authn.FromConfig(authn.AuthConfig{Username: "registry"})},
+		{name: "repository", resource: name.MustParseReference("registry.io/org/repo").Context(), expected: authn.FromConfig(authn.AuthConfig{Username: "repo"})},
+		{name: "not a repository prefix", resource: name.MustParseReference("registry.io/org/repository").Context(), expected: authn.FromConfig(authn.AuthConfig{Username: "registry"})},
+		{name: "docker hub", resource: name.MustParseReference("ubuntu").Context(), expected: authn.FromConfig(authn.AuthConfig{Username: "hub"})},
+		{name: "other registry", resource: name.MustParseReference("registry.io.evil.com/org/repo").Context(), expected: authn.Anonymous},
+		{name: "registry only", resource: name.MustParseReference("registry.io/spam").Context().Registry, expected: authn.FromConfig(authn.AuthConfig{Username: "registry"})},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			auth, err := credentials.Resolve(c.resource)
+			require.NoError(t, err)
+			assert.Equal(t, c.expected, auth)
+		})
+	}
+}
+
+// authRegistry is a registry requiring basic authentication that records the
+// credentials it received
+type authRegistry struct {
+	*httptest.Server
+	mu       sync.Mutex
+	received map[string]bool
+}
+
+func newAuthRegistry(t *testing.T) *authRegistry {
+	r := &authRegistry{received: map[string]bool{}}
+	handler := registry.New(registry.Logger(log.New(io.Discard, "", 0)))
+	r.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		user, _, ok := req.BasicAuth()
+		if !ok {
+			w.Header().Set("WWW-Authenticate", `Basic realm="test"`)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		r.mu.Lock()
+		r.received[user] = true
+		r.mu.Unlock()
+		handler.ServeHTTP(w, req)
+	}))
+	t.Cleanup(r.Close)
+
+	return r
+}
+
+func (r *authRegistry) host(t *testing.T) string {
+	u, err := url.Parse(r.URL)
+	require.NoError(t, err)
+	return u.Host
+}
+
+func TestClientUsesRegistryCredentials(t *testing.T) {
+	transport := imageRefTransport
+	imageRefTransport = remote.WithTransport(remote.DefaultTransport)
+	t.Cleanup(func() { imageRefTransport = transport })
+
+	one := newAuthRegistry(t)
+	two := newAuthRegistry(t)
+
+	credentials := Credentials{
+		one.host(t): {Username: "one", Password: "secret-one"},
+		two.host(t): {Username: "two", Password: "secret-two"},
+	}
+
+	refs := []name.Reference{}
+	for _, r := range []*authRegistry{one, two} {
+		ref, err := name.ParseReference(r.host(t) + "/repository/image:tag")
+		require.NoError(t, err)
+
+		img, err := random.Image(512, 1)
+		require.NoError(t, err)
+		require.NoError(t, remote.Write(ref, img, remote.WithAuth(authn.FromConfig(credentials[r.host(t)]))))
+		refs = append(refs, ref)
+	}
+
+	one.received, two.received = map[string]bool{}, map[string]bool{}
+
+	client := NewClient(WithCredentials(context.Background(), credentials))
+
+	var wg sync.WaitGroup
+	for _, ref := range refs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := client.Head(ref)
+			assert.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, map[string]bool{"one": true}, one.received)
+	assert.Equal(t, map[string]bool{"two": true}, two.received)
+}
@@ -56,6 +56,23 @@ func SetupTemplateWithGlob(glob []string, efs embed.FS) (*template.Template, err
 	return t, nil
 }
 
+// RenderFromText parses the given text as a template, with the standard set of
+// helper functions available, and executes it with the given input. The name
+// is used to identify the template in the errors.
+func RenderFromText(input any, name, text string) ([]byte, error) {
+	t, err := template.New(name).Funcs(templateHelpers).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("parsing the template: %w", err)
+	}
+
+	var buf bytes.Buffer
+	if err := t.Execute(&buf, input); err != nil {
+		return nil, fmt.Errorf("executing the template: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
 // Here we do the ExecuteTemplate for the caller and return just the output
 func RenderFromTemplates(input any, efs embed.FS) ([]byte, error) {
 	return RenderFromTemplatesWithMain(input, defaultMainTemplate, efs)
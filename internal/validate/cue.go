@@ -0,0 +1,65 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package validate
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/cuecontext"
+	cueerrors "cuelang.org/go/cue/errors"
+	"github.com/spf13/afero"
+
+	"github.com/enterprise-contract/ec-cli/internal/utils"
+)
+
+// IsCUEFile returns true if the given value is the path of a CUE file, i.e. it
+// has the .cue extension.
+func IsCUEFile(value string) bool {
+	return filepath.Ext(value) == ".cue"
+}
+
+// ReadCUE evaluates the CUE file to a concrete value and returns it as decoded
+// from JSON. Syntax errors and violated constraints are returned with their
+// positions in the file.
+func ReadCUE(ctx context.Context, fileName string) (any, error) {
+	content, err := afero.ReadFile(utils.FS(ctx), fileName)
+	if err != nil {
+		return nil, err
+	}
+
+	v := cuecontext.New().CompileBytes(content, cue.Filename(fileName))
+	if err := v.Validate(cue.Concrete(true)); err != nil {
+		return nil, fmt.Errorf("invalid CUE in %s:\n%s", fileName, strings.TrimSpace(cueerrors.Details(err, nil)))
+	}
+
+	data, err := v.MarshalJSON()
+	if err != nil {
+		return nil, fmt.Errorf("invalid CUE in %s:\n%s", fileName, strings.TrimSpace(cueerrors.Details(err, nil)))
+	}
+
+	var value any
+	if err := json.Unmarshal(data, &value); err != nil {
+		return nil, err
+	}
+
+	return value, nil
+}
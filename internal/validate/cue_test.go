@@ -0,0 +1,102 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package validate
+
+import (
+	"context"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/enterprise-contract/ec-cli/internal/utils"
+)
+
+func TestIsCUEFile(t *testing.T) {
+	assert.True(t, IsCUEFile("/data/rule_data.cue"))
+	assert.False(t, IsCUEFile("/data/rule_data.yaml"))
+	assert.False(t, IsCUEFile("cue"))
+}
+
+func TestReadCUE(t *testing.T) {
+	cases := []struct {
+		name     string
+		content  string
+		expected any
+		err      string
+	}{
+		{
+			name: "concrete",
+			content: `#Registry: =~"^[a-z.]+$"
+allowed_registries: [...#Registry] & ["registry.io", "quay.io"]
+max_age: 30 * 24
+`,
+			expected: map[string]any{
+				"allowed_registries": []any{"registry.io", "quay.io"},
+				"max_age":            float64(720),
+			},
+		},
+		{
+			name: "constraint violated",
+			content: `#Registry: =~"^[a-z.]+$"
+allowed_registries: [...#Registry] & ["Registry.io"]
+`,
+			err: `invalid CUE in /rule_data.cue:
+allowed_registries.0: invalid value "Registry.io" (out of bound =~"^[a-z.]+$"):
+    /rule_data.cue:1:12
+    /rule_data.cue:2:25
+    /rule_data.cue:2:39`,
+		},
+		{
+			name:    "not concrete",
+			content: `max_age: int`,
+			err: `invalid CUE in /rule_data.cue:
+max_age: incomplete value int:
+    /rule_data.cue:1:10`,
+		},
+		{
+			name:    "syntax error",
+			content: `max_age: {`,
+			err:     "invalid CUE in /rule_data.cue:\n",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			fs := afero.NewMemMapFs()
+			require.NoError(t, afero.WriteFile(fs, "/rule_data.cue", []byte(c.content), 0644))
+			ctx := utils.WithFS(context.Background(), fs)
+
+			value, err := ReadCUE(ctx, "/rule_data.cue")
+			if c.err != "" {
+				assert.ErrorContains(t, err, c.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, c.expected, value)
+		})
+	}
+
+	t.Run("missing file", func(t *testing.T) {
+		ctx := utils.WithFS(context.Background(), afero.NewMemMapFs())
+		_, err := ReadCUE(ctx, "/missing.cue")
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,257 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// Package vsa produces SLSA Verification Summary Attestations (VSA) of the
+// components of a report, so that the consumers of an image can rely on the
+// signed outcome of the validation instead of validating the image again.
+package vsa
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/in-toto/in-toto-golang/in_toto"
+	cosignSig "github.com/sigstore/cosign/v2/pkg/signature"
+	"github.com/sigstore/cosign/v2/pkg/types"
+	"github.com/sigstore/sigstore/pkg/signature"
+	"github.com/sigstore/sigstore/pkg/signature/dsse"
+	"github.com/sigstore/sigstore/pkg/signature/options"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
+
+	"github.com/enterprise-contract/ec-cli/internal/applicationsnapshot"
+	"github.com/enterprise-contract/ec-cli/internal/utils/oci"
+)
+
+const (
+	// PredicateType is the predicate type of the SLSA VSA
+	PredicateType = "https://slsa.dev/verification_summary/v1"
+	// VerifierID identifies ec as the verifier in the VSA
+	VerifierID = "https://enterprisecontract.dev/ec-cli"
+
+	Passed = "PASSED"
+	Failed = "FAILED"
+)
+
+// Predicate is the SLSA VSA predicate, see
+// https://slsa.dev/spec/v1.0/verification_summary
+type Predicate struct {
+	Verifier           Verifier             `json:"verifier"`
+	TimeVerified       time.Time            `json:"timeVerified"`
+	ResourceURI        string               `json:"resourceUri"`
+	Policy             ResourceDescriptor   `json:"policy"`
+	InputAttestations  []ResourceDescriptor `json:"inputAttestations,omitempty"`
+	VerificationResult string               `json:"verificationResult"`
+	// VerifiedLevels is always empty, ec does not assess the SLSA levels
+	VerifiedLevels []string `json:"verifiedLevels"`
+}
+
+type Verifier struct {
+	ID      string            `json:"id"`
+	Version map[string]string `json:"version,omitempty"`
+}
+
+type ResourceDescriptor struct {
+	URI    string            `json:"uri,omitempty"`
+	Digest map[string]string `json:"digest"`
+}
+
+// Options of the VSAs produced by Generate.
+type Options struct {
+	// SigningKey is the reference of the key the VSAs are signed with, any
+	// reference supported by cosign, e.g. a path, k8s://namespace/secret or a
+	// KMS key such as awskms://, see the kms package
+	SigningKey string
+	// Upload attaches the VSAs to the images in the registry
+	Upload bool
+	// OutputDir is the directory the VSAs are written to, if set
+	OutputDir string
+}
+
+// Generate produces a signed VSA for each component of the report, and writes
+// them to the output directory and attaches them to the images as given in the
+// options.
+func Generate(ctx context.Context, fs afero.Fs, report *applicationsnapshot.Report, opts Options) error {
+	signer, err := LoadSigner(ctx, opts.SigningKey)
+	if err != nil {
+		return fmt.Errorf("loading the VSA signing key: %w", err)
+	}
+
+	var allErrors error
+	for _, c := range report.Components {
+		if err := generate(ctx, fs, report, c, signer, opts); err != nil {
+			allErrors = errors.Join(allErrors, fmt.Errorf("VSA of the image %s: %w", c.ContainerImage, err))
+		}
+	}
+
+	return allErrors
+}
+
+func generate(ctx context.Context, fs afero.Fs, report *applicationsnapshot.Report, c applicationsnapshot.Component, signer signature.Signer, opts Options) error {
+	ref, err := imageDigest(ctx, c.ContainerImage)
+	if err != nil {
+		return err
+	}
+
+	statement, err := NewStatement(report, c, ref)
+	if err != nil {
+		return err
+	}
+
+	envelope, err := Sign(ctx, statement, signer)
+	if err != nil {
+		return err
+	}
+
+	if opts.OutputDir != "" {
+		hash, err := v1.NewHash(ref.DigestStr())
+		if err != nil {
+			return err
+		}
+		path := filepath.Join(opts.OutputDir, fmt.Sprintf("%s-%s.vsa.json", hash.Algorithm, hash.Hex))
+		if err := fs.MkdirAll(opts.OutputDir, 0o755); err != nil {
+			return err
+		}
+		if err := afero.WriteFile(fs, path, envelope, 0o644); err != nil {
+			return err
+		}
+		log.Debugf("Wrote the VSA of %s to %s", ref, path)
+	}
+
+	if opts.Upload {
+		if err := oci.NewClient(ctx).AttachAttestation(ref, envelope); err != nil {
+			return fmt.Errorf("attaching the VSA: %w", err)
+		}
+		log.Debugf("Attached the VSA to %s", ref)
+	}
+
+	return nil
+}
+
+// NewStatement returns the in-toto statement of the VSA of the component, with
+// the image digest as its subject.
+func NewStatement(report *applicationsnapshot.Report, c applicationsnapshot.Component, ref name.Digest) (in_toto.Statement, error) {
+	hash, err := v1.NewHash(ref.DigestStr())
+	if err != nil {
+		return in_toto.Statement{}, err
+	}
+
+	policy, err := policyDescriptor(report)
+	if err != nil {
+		return in_toto.Statement{}, err
+	}
+
+	result := Failed
+	if c.Success {
+		result = Passed
+	}
+
+	inputs := make([]ResourceDescriptor, 0, len(c.Attestations))
+	for _, a := range c.Attestations {
+		sum := sha256.Sum256(a.Statement())
+		inputs = append(inputs, ResourceDescriptor{Digest: map[string]string{"sha256": hex.EncodeToString(sum[:])}})
+	}
+
+	return in_toto.Statement{
+		StatementHeader: in_toto.StatementHeader{
+			Type:          in_toto.StatementInTotoV01,
+			PredicateType: PredicateType,
+			Subject: []in_toto.Subject{
+				{Name: ref.Context().Name(), Digest: map[string]string{hash.Algorithm: hash.Hex}},
+			},
+		},
+		Predicate: Predicate{
+			Verifier: Verifier{
+				ID:      VerifierID,
+				Version: map[string]string{"ec-cli": report.EcVersion},
+			},
+			TimeVerified:       report.Created().UTC(),
+			ResourceURI:        ref.String(),
+			Policy:             policy,
+			InputAttestations:  inputs,
+			VerificationResult: result,
+			VerifiedLevels:     []string{},
+		},
+	}, nil
+}
+
+// policyDescriptor describes the policy of the report by its first policy
+// source and the digest of the policy configuration.
+func policyDescriptor(report *applicationsnapshot.Report) (ResourceDescriptor, error) {
+	policy, err := json.Marshal(report.Policy)
+	if err != nil {
+		return ResourceDescriptor{}, err
+	}
+	sum := sha256.Sum256(policy)
+
+	descriptor := ResourceDescriptor{Digest: map[string]string{"sha256": hex.EncodeToString(sum[:])}}
+	for _, s := range report.Policy.Sources {
+		if len(s.Policy) > 0 {
+			descriptor.URI = s.Policy[0]
+			break
+		}
+	}
+
+	return descriptor, nil
+}
+
+// Sign signs the statement, returning the DSSE envelope.
+func Sign(ctx context.Context, statement in_toto.Statement, signer signature.Signer) ([]byte, error) {
+	payload, err := json.Marshal(statement)
+	if err != nil {
+		return nil, err
+	}
+
+	return dsse.WrapSigner(signer, types.IntotoPayloadType).SignMessage(bytes.NewReader(payload), options.WithContext(ctx))
+}
+
+// LoadSigner loads the signing key, the password of an encrypted key is read
+// from the COSIGN_PASSWORD environment variable.
+func LoadSigner(ctx context.Context, keyRef string) (signature.Signer, error) {
+	return cosignSig.SignerVerifierFromKeyRef(ctx, keyRef, func(bool) ([]byte, error) {
+		return []byte(os.Getenv("COSIGN_PASSWORD")), nil
+	})
+}
+
+// imageDigest returns the reference of the image by its digest, resolving the
+// digest of an image given by tag.
+func imageDigest(ctx context.Context, image string) (name.Digest, error) {
+	ref, err := name.ParseReference(image)
+	if err != nil {
+		return name.Digest{}, err
+	}
+
+	if d, ok := ref.(name.Digest); ok {
+		return d, nil
+	}
+
+	digest, err := oci.NewClient(ctx).ResolveDigest(ref)
+	if err != nil {
+		return name.Digest{}, fmt.Errorf("resolving the digest: %w", err)
+	}
+
+	return ref.Context().Digest(digest), nil
+}
@@ -0,0 +1,134 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package vsa
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	app "github.com/konflux-ci/application-api/api/v1alpha1"
+	"github.com/secure-systems-lab/go-securesystemslib/dsse"
+	"github.com/sigstore/cosign/v2/pkg/cosign"
+	cosignSig "github.com/sigstore/cosign/v2/pkg/signature"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/enterprise-contract/ec-cli/internal/applicationsnapshot"
+	"github.com/enterprise-contract/ec-cli/internal/policy"
+	"github.com/enterprise-contract/ec-cli/internal/utils/oci"
+	"github.com/enterprise-contract/ec-cli/internal/utils/oci/fake"
+)
+
+const digest = "sha256:4e388ab32b10dc8dbc7e28144f552830adc74787c1e2c0824032078a79f227fb"
+
+// signingKey writes a cosign key pair, returning the paths of the private and
+// the public key
+func signingKey(t *testing.T) (string, string) {
+	t.Setenv("COSIGN_PASSWORD", "password")
+	keys, err := cosign.GenerateKeyPair(func(bool) ([]byte, error) { return []byte("password"), nil })
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	private := filepath.Join(dir, "cosign.key")
+	public := filepath.Join(dir, "cosign.pub")
+	require.NoError(t, os.WriteFile(private, keys.PrivateBytes, 0o600))
+	require.NoError(t, os.WriteFile(public, keys.PublicBytes, 0o600))
+
+	return private, public
+}
+
+func TestGenerate(t *testing.T) {
+	private, public := signingKey(t)
+
+	p, err := policy.NewInertPolicy(context.Background(), `{"sources":[{"policy":["oci::registry.io/policy:latest"]}]}`)
+	require.NoError(t, err)
+
+	report, err := applicationsnapshot.NewReport("snappy", []applicationsnapshot.Component{
+		{
+			SnapshotComponent: app.SnapshotComponent{Name: "pinned", ContainerImage: "registry.io/repository/pinned@" + digest},
+			Success:           true,
+		},
+		{
+			SnapshotComponent: app.SnapshotComponent{Name: "tagged", ContainerImage: "registry.io/repository/tagged:latest"},
+		},
+	}, p, nil, nil, false)
+	require.NoError(t, err)
+
+	client := fake.FakeClient{}
+	client.On("ResolveDigest", name.MustParseReference("registry.io/repository/tagged:latest")).Return(digest, nil)
+	client.On("AttachAttestation", name.MustParseReference("registry.io/repository/pinned@"+digest), mock.Anything).Return(nil)
+	client.On("AttachAttestation", name.MustParseReference("registry.io/repository/tagged@"+digest), mock.Anything).Return(nil)
+	ctx := oci.WithClient(context.Background(), &client)
+
+	fs := afero.NewMemMapFs()
+	require.NoError(t, Generate(ctx, fs, &report, Options{SigningKey: private, Upload: true, OutputDir: "/vsa"}))
+	client.AssertExpectations(t)
+
+	envelope, err := afero.ReadFile(fs, "/vsa/sha256-4e388ab32b10dc8dbc7e28144f552830adc74787c1e2c0824032078a79f227fb.vsa.json")
+	require.NoError(t, err)
+
+	verifier, err := cosignSig.PublicKeyFromKeyRef(ctx, public)
+	require.NoError(t, err)
+	var env dsse.Envelope
+	require.NoError(t, json.Unmarshal(envelope, &env))
+	require.Len(t, env.Signatures, 1)
+	payload, err := base64.StdEncoding.DecodeString(env.Payload)
+	require.NoError(t, err)
+	signature, err := base64.StdEncoding.DecodeString(env.Signatures[0].Sig)
+	require.NoError(t, err)
+	assert.NoError(t, verifier.VerifySignature(bytes.NewReader(signature), bytes.NewReader(dsse.PAE(env.PayloadType, payload))))
+
+	var statement struct {
+		PredicateType string `json:"predicateType"`
+		Subject       []struct {
+			Name   string            `json:"name"`
+			Digest map[string]string `json:"digest"`
+		} `json:"subject"`
+		Predicate Predicate `json:"predicate"`
+	}
+	require.NoError(t, json.Unmarshal(payload, &statement))
+	assert.Equal(t, PredicateType, statement.PredicateType)
+	require.Len(t, statement.Subject, 1)
+	assert.Equal(t, "registry.io/repository/tagged", statement.Subject[0].Name)
+	assert.Equal(t, map[string]string{"sha256": "4e388ab32b10dc8dbc7e28144f552830adc74787c1e2c0824032078a79f227fb"}, statement.Subject[0].Digest)
+	assert.Equal(t, Failed, statement.Predicate.VerificationResult)
+	assert.Equal(t, VerifierID, statement.Predicate.Verifier.ID)
+	assert.Equal(t, "registry.io/repository/tagged@"+digest, statement.Predicate.ResourceURI)
+	assert.Equal(t, "oci::registry.io/policy:latest", statement.Predicate.Policy.URI)
+	assert.Equal(t, report.Created().UTC(), statement.Predicate.TimeVerified.UTC())
+}
+
+func TestGenerateInvalidKey(t *testing.T) {
+	p, err := policy.NewInertPolicy(context.Background(), "")
+	require.NoError(t, err)
+
+	report, err := applicationsnapshot.NewReport("snappy", nil, p, nil, nil, false)
+	require.NoError(t, err)
+
+	err = Generate(context.Background(), afero.NewMemMapFs(), &report, Options{SigningKey: "/does/not/exist.key"})
+	assert.ErrorContains(t, err, "loading the VSA signing key")
+}
@@ -0,0 +1,225 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// Package waiver implements waiving violations of the policy: a waiver file
+// lists the rule codes to waive for images or components, optionally only the
+// violations with a given fingerprint, each with a justification and an
+// expiry. Waived violations are reported as waived and
+// do not fail the validation.
+package waiver
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/afero"
+	"sigs.k8s.io/yaml"
+
+	"github.com/enterprise-contract/ec-cli/internal/applicationsnapshot"
+	"github.com/enterprise-contract/ec-cli/internal/evaluator"
+)
+
+// dateFormat is the format of the expiry without a time of day
+const dateFormat = "2006-01-02"
+
+// Waiver waives the violations of a rule for an image, a component or both.
+type Waiver struct {
+	// Code is the code of the rule, e.g. tasks.required_tasks_found
+	Code string `json:"code"`
+	// Image is the image repository, or the image reference, the waiver
+	// applies to
+	Image string `json:"image,omitempty"`
+	// Component is the name of the component the waiver applies to
+	Component string `json:"component,omitempty"`
+	// Fingerprint restricts the waiver to the violations with the
+	// fingerprint, i.e. to a single violation of the rule, see the
+	// fingerprint package
+	Fingerprint string `json:"fingerprint,omitempty"`
+	// Expires is the date, or the RFC3339 time, from which the waiver no
+	// longer applies
+	Expires string `json:"expires"`
+	// Justification explains why the violations are waived
+	Justification string `json:"justification"`
+}
+
+// File holds the waivers as read from a waiver file.
+type File struct {
+	Waivers []Waiver `json:"waivers"`
+}
+
+// ExpiresAt returns the time the waiver expires.
+func (w Waiver) ExpiresAt() (time.Time, error) {
+	if t, err := time.Parse(dateFormat, w.Expires); err == nil {
+		return t, nil
+	}
+
+	return time.Parse(time.RFC3339, w.Expires)
+}
+
+func (w Waiver) validate() error {
+	var errs error
+	if w.Code == "" {
+		errs = errors.Join(errs, errors.New("the code is required"))
+	}
+	if w.Image == "" && w.Component == "" {
+		errs = errors.Join(errs, errors.New("the image or the component is required"))
+	}
+	if w.Justification == "" {
+		errs = errors.Join(errs, errors.New("the justification is required"))
+	}
+	if _, err := w.ExpiresAt(); err != nil {
+		errs = errors.Join(errs, fmt.Errorf("invalid expiry %q, expected a date such as 2006-01-02 or an RFC3339 time", w.Expires))
+	}
+
+	return errs
+}
+
+// Load reads the waivers from the file at the given path.
+func Load(fs afero.Fs, path string) (*File, error) {
+	data, err := afero.ReadFile(fs, path)
+	if err != nil {
+		return nil, fmt.Errorf("reading waivers from %s: %w", path, err)
+	}
+
+	var f File
+	if err := yaml.UnmarshalStrict(data, &f); err != nil {
+		return nil, fmt.Errorf("parsing waivers from %s: %w", path, err)
+	}
+
+	var errs error
+	for i, w := range f.Waivers {
+		if err := w.validate(); err != nil {
+			errs = errors.Join(errs, fmt.Errorf("invalid waiver %d in %s: %w", i+1, path, err))
+		}
+	}
+	if errs != nil {
+		return nil, errs
+	}
+
+	return &f, nil
+}
+
+// Write writes the waivers to the file at the given path.
+func (f *File) Write(fs afero.Fs, path string) error {
+	data, err := yaml.Marshal(f)
+	if err != nil {
+		return err
+	}
+
+	return afero.WriteFile(fs, path, data, 0644)
+}
+
+// Add adds the waiver unless the file already has a waiver for the same code,
+// image, component and fingerprint. Returns true if the waiver was added.
+func (f *File) Add(w Waiver) bool {
+	for _, existing := range f.Waivers {
+		if existing.Code == w.Code && existing.Image == w.Image && existing.Component == w.Component && existing.Fingerprint == w.Fingerprint {
+			return false
+		}
+	}
+	f.Waivers = append(f.Waivers, w)
+
+	return true
+}
+
+// Repository returns the image reference without the tag and the digest.
+func Repository(ref string) string {
+	repository, _, _ := strings.Cut(ref, "@")
+	if i := strings.LastIndex(repository, ":"); i > strings.LastIndex(repository, "/") {
+		repository = repository[:i]
+	}
+
+	return repository
+}
+
+// matches returns true if the waiver applies to the violation of the
+// component at the given time.
+func (w Waiver) matches(c applicationsnapshot.Component, v evaluator.Result, at time.Time) bool {
+	if code, _ := v.Metadata["code"].(string); code != w.Code {
+		return false
+	}
+	if w.Component != "" && w.Component != c.Name {
+		return false
+	}
+	if w.Fingerprint != "" && w.Fingerprint != v.Fingerprint {
+		return false
+	}
+	if w.Image != "" && w.Image != c.ContainerImage && w.Image != Repository(c.ContainerImage) {
+		return false
+	}
+
+	expires, err := w.ExpiresAt()
+
+	return err == nil && at.Before(expires)
+}
+
+// Apply moves the violations of the component waived at the given time to
+// the waived results of the component, recording the waiver in the metadata of
+// the result. The component succeeds if all of its violations are waived.
+func (f *File) Apply(c *applicationsnapshot.Component, at time.Time) {
+	if f == nil {
+		return
+	}
+
+	violations := make([]evaluator.Result, 0, len(c.Violations))
+	for _, v := range c.Violations {
+		waiver, found := f.find(*c, v, at)
+		if !found {
+			violations = append(violations, v)
+			continue
+		}
+
+		metadata := make(map[string]any, len(v.Metadata)+1)
+		for k, val := range v.Metadata {
+			metadata[k] = val
+		}
+		metadata["waiver"] = map[string]any{
+			"expires":       waiver.Expires,
+			"justification": waiver.Justification,
+		}
+		v.Metadata = metadata
+		c.Waived = append(c.Waived, v)
+	}
+	c.Violations = violations
+
+	if len(c.Waived) > 0 && len(c.Violations) == 0 && c.EvaluationError == "" {
+		c.Success = true
+	}
+}
+
+func (f *File) find(c applicationsnapshot.Component, v evaluator.Result, at time.Time) (Waiver, bool) {
+	for _, w := range f.Waivers {
+		if w.matches(c, v, at) {
+			return w, true
+		}
+	}
+
+	return Waiver{}, false
+}
+
+// Expired returns the waivers expired at the given time.
+func (f *File) Expired(at time.Time) []Waiver {
+	var expired []Waiver
+	for _, w := range f.Waivers {
+		if expires, err := w.ExpiresAt(); err == nil && !at.Before(expires) {
+			expired = append(expired, w)
+		}
+	}
+
+	return expired
+}
@@ -0,0 +1,164 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package waiver
+
+import (
+	"testing"
+	"time"
+
+	app "github.com/konflux-ci/application-api/api/v1alpha1"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/enterprise-contract/ec-cli/internal/applicationsnapshot"
+	"github.com/enterprise-contract/ec-cli/internal/evaluator"
+)
+
+func TestLoad(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "waivers.yaml", []byte(`
+waivers:
+- code: tasks.required_tasks_found
+  image: registry.io/repository/image
+  expires: 2026-12-31
+  justification: Tasks added in the next release
+- code: test.no_failed_tests
+  component: spam
+  expires: 2026-12-31T12:00:00Z
+  justification: Flaky test
+`), 0644))
+
+	f, err := Load(fs, "waivers.yaml")
+	require.NoError(t, err)
+	assert.Equal(t, []Waiver{
+		{Code: "tasks.required_tasks_found", Image: "registry.io/repository/image", Expires: "2026-12-31", Justification: "Tasks added in the next release"},
+		{Code: "test.no_failed_tests", Component: "spam", Expires: "2026-12-31T12:00:00Z", Justification: "Flaky test"},
+	}, f.Waivers)
+
+	require.NoError(t, afero.WriteFile(fs, "invalid.yaml", []byte(`
+waivers:
+- code: tasks.required_tasks_found
+  expires: next year
+`), 0644))
+	_, err = Load(fs, "invalid.yaml")
+	assert.EqualError(t, err, "invalid waiver 1 in invalid.yaml: the image or the component is required\n"+
+		"the justification is required\n"+
+		`invalid expiry "next year", expected a date such as 2006-01-02 or an RFC3339 time`)
+
+	require.NoError(t, afero.WriteFile(fs, "unknown.yaml", []byte(`waivers: [{code: a, reason: b}]`), 0644))
+	_, err = Load(fs, "unknown.yaml")
+	assert.ErrorContains(t, err, `parsing waivers from unknown.yaml: error unmarshaling JSON: while decoding JSON: json: unknown field "reason"`)
+
+	_, err = Load(fs, "missing.yaml")
+	assert.ErrorContains(t, err, "reading waivers from missing.yaml: ")
+}
+
+func TestApply(t *testing.T) {
+	violation := func(code string) evaluator.Result {
+		return evaluator.Result{Message: "violated", Metadata: map[string]any{"code": code}}
+	}
+
+	f := &File{Waivers: []Waiver{
+		{Code: "a.b", Image: "registry.io/spam", Expires: "2026-12-31", Justification: "because"},
+		{Code: "c.d", Component: "bacon", Expires: "2026-12-31", Justification: "why not"},
+		{Code: "e.f", Image: "registry.io/spam@sha256:1", Component: "spam", Expires: "2026-01-01", Justification: "expired"},
+	}}
+
+	newComponent := func(name, image string) applicationsnapshot.Component {
+		return applicationsnapshot.Component{
+			SnapshotComponent: app.SnapshotComponent{Name: name, ContainerImage: image},
+			Violations:        []evaluator.Result{violation("a.b"), violation("c.d"), violation("e.f")},
+		}
+	}
+	at := time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	spam := newComponent("spam", "registry.io/spam@sha256:1")
+	f.Apply(&spam, at)
+	assert.Equal(t, []evaluator.Result{violation("c.d"), violation("e.f")}, spam.Violations)
+	assert.Equal(t, []evaluator.Result{{Message: "violated", Metadata: map[string]any{
+		"code":   "a.b",
+		"waiver": map[string]any{"expires": "2026-12-31", "justification": "because"},
+	}}}, spam.Waived)
+	assert.False(t, spam.Success)
+
+	bacon := newComponent("bacon", "registry.io/spam:latest")
+	bacon.Violations = bacon.Violations[:2]
+	f.Apply(&bacon, at)
+	assert.Empty(t, bacon.Violations)
+	assert.Len(t, bacon.Waived, 2)
+	assert.True(t, bacon.Success)
+
+	expired := newComponent("bacon", "registry.io/spam:latest")
+	f.Apply(&expired, time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC))
+	assert.Len(t, expired.Violations, 3)
+	assert.Empty(t, expired.Waived)
+
+	var none *File
+	none.Apply(&expired, at)
+	assert.Len(t, expired.Violations, 3)
+
+	assert.Equal(t, []Waiver{f.Waivers[2]}, f.Expired(at))
+}
+
+func TestApplyFingerprint(t *testing.T) {
+	f := &File{Waivers: []Waiver{
+		{Code: "a.b", Component: "spam", Fingerprint: "f00d", Expires: "2026-12-31", Justification: "because"},
+	}}
+
+	c := applicationsnapshot.Component{
+		SnapshotComponent: app.SnapshotComponent{Name: "spam", ContainerImage: "registry.io/spam@sha256:1"},
+		Violations: []evaluator.Result{
+			{Message: "waived", Metadata: map[string]any{"code": "a.b"}, Fingerprint: "f00d"},
+			{Message: "new", Metadata: map[string]any{"code": "a.b"}, Fingerprint: "beef"},
+		},
+	}
+	f.Apply(&c, time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC))
+
+	require.Len(t, c.Waived, 1)
+	assert.Equal(t, "waived", c.Waived[0].Message)
+	require.Len(t, c.Violations, 1)
+	assert.Equal(t, "new", c.Violations[0].Message)
+	assert.False(t, c.Success)
+}
+
+func TestAdd(t *testing.T) {
+	f := &File{}
+	w := Waiver{Code: "a.b", Image: "registry.io/spam", Expires: "2026-12-31", Justification: "because"}
+	assert.True(t, f.Add(w))
+	w.Justification = "again"
+	assert.False(t, f.Add(w))
+	w.Component = "spam"
+	assert.True(t, f.Add(w))
+	w.Fingerprint = "f00d"
+	assert.True(t, f.Add(w))
+	assert.Len(t, f.Waivers, 3)
+
+	fs := afero.NewMemMapFs()
+	require.NoError(t, f.Write(fs, "waivers.yaml"))
+	loaded, err := Load(fs, "waivers.yaml")
+	require.NoError(t, err)
+	assert.Equal(t, f, loaded)
+}
+
+func TestRepository(t *testing.T) {
+	assert.Equal(t, "registry.io/spam", Repository("registry.io/spam:latest@sha256:1"))
+	assert.Equal(t, "registry.io:5000/spam", Repository("registry.io:5000/spam"))
+	assert.Equal(t, "registry.io:5000/spam", Repository("registry.io:5000/spam:v1"))
+}
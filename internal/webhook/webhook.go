@@ -0,0 +1,329 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// Package webhook implements a Kubernetes validating admission webhook denying
+// the Pods and the Deployments with container images that do not conform to
+// an EnterpriseContractPolicy.
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	app "github.com/konflux-ci/application-api/api/v1alpha1"
+	log "github.com/sirupsen/logrus"
+	admissionv1 "k8s.io/api/admission/v1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/enterprise-contract/ec-cli/internal/applicationsnapshot"
+	"github.com/enterprise-contract/ec-cli/internal/image"
+	"github.com/enterprise-contract/ec-cli/internal/kubernetes"
+)
+
+// maxReviewSize limits the size of the body of an admission review
+const maxReviewSize = 10 << 20
+
+var now = time.Now
+
+// Validator validates the components of a snapshot against a policy, see
+// server.Server
+type Validator interface {
+	Validate(ctx context.Context, snap *app.SnapshotSpec, policyRef string, effectiveTime string) (*applicationsnapshot.Report, error)
+}
+
+// Options configure the Webhook
+type Options struct {
+	// Policy references the EnterpriseContractPolicy the images are validated
+	// against, in the namespace/name form
+	Policy string
+	// ResultTTL is how long the result of the validation of an image is reused
+	// for the same revision of the policy, not reused when zero
+	ResultTTL time.Duration
+	// Timeout limits the time taken to review a request, no limit when zero
+	Timeout time.Duration
+}
+
+// result of the validation of an image
+type result struct {
+	// reason the image was denied, empty when the image is allowed
+	reason  string
+	expires time.Time
+}
+
+// Webhook reviews the admission requests
+type Webhook struct {
+	options   Options
+	validator Validator
+	mu        sync.Mutex
+	results   map[string]result
+	mux       *http.ServeMux
+}
+
+// New creates a Webhook validating the images with the validator
+func New(validator Validator, options Options) *Webhook {
+	w := &Webhook{
+		options:   options,
+		validator: validator,
+		results:   map[string]result{},
+		mux:       http.NewServeMux(),
+	}
+
+	w.mux.HandleFunc("POST /validate", w.handleReview)
+	w.mux.HandleFunc("GET /healthz", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	return w
+}
+
+func (w *Webhook) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
+	w.mux.ServeHTTP(rw, r)
+}
+
+func (w *Webhook) handleReview(rw http.ResponseWriter, r *http.Request) {
+	var review admissionv1.AdmissionReview
+	if err := json.NewDecoder(http.MaxBytesReader(rw, r.Body, maxReviewSize)).Decode(&review); err != nil {
+		http.Error(rw, fmt.Sprintf("unable to parse the admission review: %v", err), http.StatusBadRequest)
+		return
+	}
+	if review.Request == nil {
+		http.Error(rw, "the admission review has no request", http.StatusBadRequest)
+		return
+	}
+
+	ctx := r.Context()
+	if w.options.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, w.options.Timeout)
+		defer cancel()
+	}
+
+	// The response must be of the same version of the AdmissionReview
+	review.TypeMeta = metav1.TypeMeta{APIVersion: admissionv1.SchemeGroupVersion.String(), Kind: "AdmissionReview"}
+	review.Response = w.review(ctx, review.Request)
+	review.Response.UID = review.Request.UID
+	review.Request = nil
+
+	rw.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(rw).Encode(review); err != nil {
+		log.Errorf("Unable to write the admission review: %v", err)
+	}
+}
+
+// review allows the request when all of the images of the object conform to
+// the policy
+func (w *Webhook) review(ctx context.Context, req *admissionv1.AdmissionRequest) *admissionv1.AdmissionResponse {
+	if req.Operation != admissionv1.Create && req.Operation != admissionv1.Update {
+		return allow()
+	}
+
+	images, err := imagesOf(req)
+	if err != nil {
+		return deny(http.StatusBadRequest, err.Error())
+	}
+	if len(images) == 0 {
+		return allow()
+	}
+
+	reasons, err := w.validate(ctx, images)
+	if err != nil {
+		log.Debugf("Unable to validate the images of %s %s/%s: %v", req.Kind.Kind, req.Namespace, req.Name, err)
+		return deny(http.StatusInternalServerError, err.Error())
+	}
+	if len(reasons) > 0 {
+		return deny(http.StatusForbidden, strings.Join(reasons, "; "))
+	}
+
+	return allow()
+}
+
+// validate returns the reasons for denying the images, reusing the results
+// of the images validated against the same revision of the policy
+func (w *Webhook) validate(ctx context.Context, images []string) ([]string, error) {
+	client, err := kubernetes.NewClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ecp, err := client.FetchEnterpriseContractPolicy(ctx, w.options.Policy)
+	if err != nil {
+		return nil, fmt.Errorf("unable to fetch the policy %s: %w", w.options.Policy, err)
+	}
+
+	// Validated by digest, so that the image validated is the one the
+	// result is kept for
+	var pending []app.SnapshotComponent
+	keys := make(map[string]string, len(images))
+	reasons := []string{}
+	for _, i := range images {
+		ref, err := image.ParseAndResolve(ctx, i)
+		if err != nil {
+			reasons = append(reasons, fmt.Sprintf("image %s: unable to resolve the digest: %v", i, err))
+			continue
+		}
+		ref.Tag = ""
+		pinned := ref.String()
+
+		key := pinned + "@" + ecp.ResourceVersion
+		keys[pinned] = key
+		if r, ok := w.result(key); ok {
+			log.Debugf("Reusing the result of image %s", pinned)
+			if r.reason != "" {
+				reasons = append(reasons, r.reason)
+			}
+			continue
+		}
+
+		pending = append(pending, app.SnapshotComponent{Name: i, ContainerImage: pinned})
+	}
+
+	if len(pending) > 0 {
+		policy, err := json.Marshal(ecp.Spec)
+		if err != nil {
+			return nil, err
+		}
+
+		report, err := w.validator.Validate(ctx, &app.SnapshotSpec{Components: pending}, string(policy), "")
+		if err != nil {
+			return nil, err
+		}
+
+		for _, c := range report.Components {
+			reason := denialReason(c)
+			if reason != "" {
+				reasons = append(reasons, reason)
+			}
+			// Evaluation errors might be transient, the image is
+			// validated again on the next request
+			if key, ok := keys[c.ContainerImage]; ok && c.EvaluationError == "" {
+				w.store(key, reason)
+			}
+		}
+	}
+
+	slices.Sort(reasons)
+	return reasons, nil
+}
+
+func (w *Webhook) result(key string) (result, bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	r, ok := w.results[key]
+	if ok && !now().Before(r.expires) {
+		delete(w.results, key)
+		return result{}, false
+	}
+
+	return r, ok
+}
+
+func (w *Webhook) store(key, reason string) {
+	if w.options.ResultTTL <= 0 {
+		return
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	t := now()
+	for k, r := range w.results {
+		if !t.Before(r.expires) {
+			delete(w.results, k)
+		}
+	}
+	w.results[key] = result{reason: reason, expires: t.Add(w.options.ResultTTL)}
+}
+
+// denialReason describes why the image of the component is denied, empty when
+// the image conforms to the policy
+func denialReason(c applicationsnapshot.Component) string {
+	switch {
+	case c.EvaluationError != "":
+		return fmt.Sprintf("image %s: %s", c.ContainerImage, c.EvaluationError)
+	case c.Success:
+		return ""
+	}
+
+	codes := make([]string, 0, len(c.Violations))
+	for _, v := range c.Violations {
+		if code, ok := v.Metadata["code"].(string); ok && code != "" {
+			codes = append(codes, code)
+		} else {
+			codes = append(codes, v.Message)
+		}
+	}
+
+	return fmt.Sprintf("image %s violates the policy: %s", c.ContainerImage, strings.Join(codes, ", "))
+}
+
+// imagesOf returns the distinct images of the containers of the Pod or the
+// Deployment in the request, none for other kinds of objects
+func imagesOf(req *admissionv1.AdmissionRequest) ([]string, error) {
+	var spec corev1.PodSpec
+	switch req.Kind {
+	case metav1.GroupVersionKind{Version: "v1", Kind: "Pod"}:
+		var pod corev1.Pod
+		if err := json.Unmarshal(req.Object.Raw, &pod); err != nil {
+			return nil, fmt.Errorf("unable to parse the Pod: %w", err)
+		}
+		spec = pod.Spec
+	case metav1.GroupVersionKind{Group: "apps", Version: "v1", Kind: "Deployment"}:
+		var deployment appsv1.Deployment
+		if err := json.Unmarshal(req.Object.Raw, &deployment); err != nil {
+			return nil, fmt.Errorf("unable to parse the Deployment: %w", err)
+		}
+		spec = deployment.Spec.Template.Spec
+	default:
+		return nil, nil
+	}
+
+	var images []string
+	for _, c := range spec.InitContainers {
+		images = append(images, c.Image)
+	}
+	for _, c := range spec.Containers {
+		images = append(images, c.Image)
+	}
+	for _, c := range spec.EphemeralContainers {
+		images = append(images, c.Image)
+	}
+	slices.Sort(images)
+
+	return slices.Compact(images), nil
+}
+
+func allow() *admissionv1.AdmissionResponse {
+	return &admissionv1.AdmissionResponse{Allowed: true}
+}
+
+func deny(code int32, message string) *admissionv1.AdmissionResponse {
+	return &admissionv1.AdmissionResponse{
+		Allowed: false,
+		Result: &metav1.Status{
+			Status:  metav1.StatusFailure,
+			Code:    code,
+			Message: message,
+		},
+	}
+}
@@ -0,0 +1,330 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	ecc "github.com/enterprise-contract/enterprise-contract-controller/api/v1alpha1"
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	app "github.com/konflux-ci/application-api/api/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	admissionv1 "k8s.io/api/admission/v1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/enterprise-contract/ec-cli/internal/applicationsnapshot"
+	"github.com/enterprise-contract/ec-cli/internal/evaluator"
+	"github.com/enterprise-contract/ec-cli/internal/image"
+	"github.com/enterprise-contract/ec-cli/internal/kubernetes"
+)
+
+const (
+	goodImage = "registry.io/good@sha256:0000000000000000000000000000000000000000000000000000000000000001"
+	badImage  = "registry.io/bad@sha256:0000000000000000000000000000000000000000000000000000000000000002"
+)
+
+type fakeKubernetesClient struct {
+	kubernetes.Client
+	revision string
+	err      error
+}
+
+func (c *fakeKubernetesClient) FetchEnterpriseContractPolicy(context.Context, string) (*ecc.EnterpriseContractPolicy, error) {
+	if c.err != nil {
+		return nil, c.err
+	}
+	return &ecc.EnterpriseContractPolicy{
+		ObjectMeta: metav1.ObjectMeta{ResourceVersion: c.revision},
+		Spec:       ecc.EnterpriseContractPolicySpec{PublicKey: "key"},
+	}, nil
+}
+
+type fakeValidator struct {
+	validated []string
+	err       error
+}
+
+func (v *fakeValidator) Validate(_ context.Context, snap *app.SnapshotSpec, policyRef string, _ string) (*applicationsnapshot.Report, error) {
+	if v.err != nil {
+		return nil, v.err
+	}
+	if !strings.Contains(policyRef, `"publicKey":"key"`) {
+		return nil, errors.New("unexpected policy")
+	}
+
+	report := applicationsnapshot.Report{}
+	for _, c := range snap.Components {
+		v.validated = append(v.validated, c.ContainerImage)
+		component := applicationsnapshot.Component{SnapshotComponent: c, Success: true}
+		switch {
+		case strings.Contains(c.ContainerImage, "bad"):
+			component.Success = false
+			component.Violations = []evaluator.Result{{Message: "bad", Metadata: map[string]any{"code": "policy.bad"}}}
+		case strings.Contains(c.ContainerImage, "broken"):
+			component.Success = false
+			component.EvaluationError = "broken image"
+		}
+		report.Components = append(report.Components, component)
+	}
+
+	return &report, nil
+}
+
+func pod(t *testing.T, images ...string) []byte {
+	p := corev1.Pod{}
+	for _, i := range images {
+		p.Spec.Containers = append(p.Spec.Containers, corev1.Container{Image: i})
+	}
+	raw, err := json.Marshal(p)
+	require.NoError(t, err)
+	return raw
+}
+
+func podReview(t *testing.T, images ...string) admissionv1.AdmissionReview {
+	return admissionv1.AdmissionReview{
+		TypeMeta: metav1.TypeMeta{APIVersion: "admission.k8s.io/v1", Kind: "AdmissionReview"},
+		Request: &admissionv1.AdmissionRequest{
+			UID:       types.UID("uid"),
+			Kind:      metav1.GroupVersionKind{Version: "v1", Kind: "Pod"},
+			Operation: admissionv1.Create,
+			Object:    runtime.RawExtension{Raw: pod(t, images...)},
+		},
+	}
+}
+
+func send(t *testing.T, ctx context.Context, w *Webhook, review admissionv1.AdmissionReview) *admissionv1.AdmissionResponse {
+	body, err := json.Marshal(review)
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodPost, "/validate", bytes.NewReader(body)).WithContext(ctx)
+	rec := httptest.NewRecorder()
+	w.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	var response admissionv1.AdmissionReview
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+	require.NotNil(t, response.Response)
+	assert.Equal(t, "AdmissionReview", response.Kind)
+	assert.Equal(t, review.Request.UID, response.Response.UID)
+	assert.Nil(t, response.Request)
+
+	return response.Response
+}
+
+func TestReview(t *testing.T) {
+	ctx := kubernetes.WithClient(context.Background(), &fakeKubernetesClient{revision: "1"})
+
+	cases := []struct {
+		name    string
+		images  []string
+		allowed bool
+		code    int32
+		message string
+	}{
+		{
+			name:    "conforming image",
+			images:  []string{goodImage},
+			allowed: true,
+		},
+		{
+			name:    "violating image",
+			images:  []string{goodImage, badImage},
+			code:    http.StatusForbidden,
+			message: "image " + badImage + " violates the policy: policy.bad",
+		},
+		{
+			name:    "image not evaluated",
+			images:  []string{"registry.io/broken@sha256:0000000000000000000000000000000000000000000000000000000000000003"},
+			code:    http.StatusForbidden,
+			message: "broken image",
+		},
+		{
+			name:    "invalid image",
+			images:  []string{"registry.io/INVALID"},
+			code:    http.StatusForbidden,
+			message: "image registry.io/INVALID: unable to resolve the digest",
+		},
+		{
+			name:    "no containers",
+			allowed: true,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			w := New(&fakeValidator{}, Options{Policy: "ec/default"})
+			response := send(t, ctx, w, podReview(t, c.images...))
+
+			assert.Equal(t, c.allowed, response.Allowed)
+			if c.allowed {
+				assert.Nil(t, response.Result)
+			} else {
+				require.NotNil(t, response.Result)
+				assert.Equal(t, c.code, response.Result.Code)
+				assert.Contains(t, response.Result.Message, c.message)
+			}
+		})
+	}
+}
+
+func TestReviewDeployment(t *testing.T) {
+	ctx := kubernetes.WithClient(context.Background(), &fakeKubernetesClient{revision: "1"})
+
+	deployment := appsv1.Deployment{}
+	deployment.Spec.Template.Spec.InitContainers = []corev1.Container{{Image: badImage}}
+	deployment.Spec.Template.Spec.Containers = []corev1.Container{{Image: goodImage}}
+	raw, err := json.Marshal(deployment)
+	require.NoError(t, err)
+
+	validator := &fakeValidator{}
+	response := send(t, ctx, New(validator, Options{Policy: "ec/default"}), admissionv1.AdmissionReview{
+		Request: &admissionv1.AdmissionRequest{
+			UID:       types.UID("uid"),
+			Kind:      metav1.GroupVersionKind{Group: "apps", Version: "v1", Kind: "Deployment"},
+			Operation: admissionv1.Update,
+			Object:    runtime.RawExtension{Raw: raw},
+		},
+	})
+
+	assert.False(t, response.Allowed)
+	assert.Equal(t, []string{badImage, goodImage}, validator.validated)
+}
+
+func TestReviewIgnored(t *testing.T) {
+	validator := &fakeValidator{}
+	w := New(validator, Options{Policy: "ec/default"})
+
+	review := podReview(t, badImage)
+	review.Request.Operation = admissionv1.Delete
+	assert.True(t, send(t, context.Background(), w, review).Allowed)
+
+	review = podReview(t, badImage)
+	review.Request.Kind = metav1.GroupVersionKind{Version: "v1", Kind: "ConfigMap"}
+	assert.True(t, send(t, context.Background(), w, review).Allowed)
+
+	assert.Empty(t, validator.validated)
+}
+
+func TestReviewResolvesDigest(t *testing.T) {
+	ctx := kubernetes.WithClient(context.Background(), &fakeKubernetesClient{revision: "1"})
+	ctx = context.WithValue(ctx, image.RemoteHead, func(name.Reference, ...remote.Option) (*v1.Descriptor, error) {
+		return &v1.Descriptor{Digest: v1.Hash{Algorithm: "sha256", Hex: strings.Repeat("0", 63) + "1"}}, nil
+	})
+
+	validator := &fakeValidator{}
+	response := send(t, ctx, New(validator, Options{Policy: "ec/default"}), podReview(t, "registry.io/good:latest"))
+
+	assert.True(t, response.Allowed)
+	assert.Equal(t, []string{goodImage}, validator.validated)
+}
+
+func TestReviewFailure(t *testing.T) {
+	cases := []struct {
+		name      string
+		client    *fakeKubernetesClient
+		validator *fakeValidator
+		message   string
+	}{
+		{
+			name:      "policy not fetched",
+			client:    &fakeKubernetesClient{err: errors.New("expected")},
+			validator: &fakeValidator{},
+			message:   "unable to fetch the policy ec/default: expected",
+		},
+		{
+			name:      "validation failure",
+			client:    &fakeKubernetesClient{},
+			validator: &fakeValidator{err: errors.New("expected")},
+			message:   "expected",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			ctx := kubernetes.WithClient(context.Background(), c.client)
+			response := send(t, ctx, New(c.validator, Options{Policy: "ec/default"}), podReview(t, goodImage))
+
+			assert.False(t, response.Allowed)
+			require.NotNil(t, response.Result)
+			assert.Equal(t, int32(http.StatusInternalServerError), response.Result.Code)
+			assert.Equal(t, c.message, response.Result.Message)
+		})
+	}
+}
+
+func TestResultCache(t *testing.T) {
+	current := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	t.Cleanup(func() { now = time.Now })
+	now = func() time.Time { return current }
+
+	client := &fakeKubernetesClient{revision: "1"}
+	ctx := kubernetes.WithClient(context.Background(), client)
+	validator := &fakeValidator{}
+	w := New(validator, Options{Policy: "ec/default", ResultTTL: time.Minute})
+
+	broken := "registry.io/broken@sha256:0000000000000000000000000000000000000000000000000000000000000003"
+	review := func() bool {
+		return send(t, ctx, w, podReview(t, goodImage, badImage, broken)).Allowed
+	}
+
+	assert.False(t, review())
+	assert.Equal(t, []string{badImage, broken, goodImage}, validator.validated)
+
+	// The results are reused, except for the image not evaluated
+	validator.validated = nil
+	assert.False(t, review())
+	assert.Equal(t, []string{broken}, validator.validated)
+
+	// A new revision of the policy
+	client.revision = "2"
+	validator.validated = nil
+	assert.False(t, review())
+	assert.Equal(t, []string{badImage, broken, goodImage}, validator.validated)
+
+	// The results expired
+	current = current.Add(2 * time.Minute)
+	validator.validated = nil
+	assert.False(t, review())
+	assert.Equal(t, []string{badImage, broken, goodImage}, validator.validated)
+}
+
+func TestInvalidReview(t *testing.T) {
+	w := New(&fakeValidator{}, Options{})
+
+	for _, body := range []string{"spam", "{}"} {
+		rec := httptest.NewRecorder()
+		w.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/validate", strings.NewReader(body)))
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	}
+}
@@ -0,0 +1,69 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package schema
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCycloneDXBOM(t *testing.T) {
+	cases := []struct {
+		name  string
+		bom   string
+		valid bool
+	}{
+		{
+			name: "valid",
+			bom: `{
+				"bomFormat": "CycloneDX",
+				"specVersion": "1.5",
+				"components": [
+					{"type": "library", "name": "spam", "version": "1.0", "purl": "pkg:rpm/redhat/spam@1.0",
+					 "licenses": [{"license": {"id": "Apache-2.0"}}]}
+				]
+			}`,
+			valid: true,
+		},
+		{name: "minimal", bom: `{"bomFormat": "CycloneDX", "specVersion": "1.6"}`, valid: true},
+		{name: "missing spec version", bom: `{"bomFormat": "CycloneDX"}`},
+		{name: "wrong format", bom: `{"bomFormat": "SPDX", "specVersion": "1.6"}`},
+		{name: "unknown attribute", bom: `{"bomFormat": "CycloneDX", "specVersion": "1.6", "spam": true}`},
+		{name: "component without name", bom: `{"bomFormat": "CycloneDX", "specVersion": "1.6", "components": [{"type": "library"}]}`},
+		{name: "unknown license", bom: `{"bomFormat": "CycloneDX", "specVersion": "1.6", "components": [
+			{"type": "library", "name": "spam", "licenses": [{"license": {"id": "not-a-license"}}]}]}`},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var v any
+			require.NoError(t, json.Unmarshal([]byte(c.bom), &v))
+
+			err := CycloneDX_BOM_v1_6.Validate(v)
+			if c.valid {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+			}
+		})
+	}
+}
@@ -30,6 +30,22 @@ var SLSA_Provenance_v0_2 *jsonschema.Schema
 
 var SLSA_Provenance_v0_2_URI = "https://slsa.dev/provenance/v0.2"
 
+// The CycloneDX schema refers to the schemas of the SPDX license identifiers
+// and of the JSON signature format
+//
+//go:embed cyclonedx_bom_v1.6.json
+var cyclonedx_bom_v1_6_json string
+
+//go:embed cyclonedx_spdx.json
+var cyclonedx_spdx_json string
+
+//go:embed cyclonedx_jsf_v0.82.json
+var cyclonedx_jsf_v0_82_json string
+
+var CycloneDX_BOM_v1_6 *jsonschema.Schema
+
+var CycloneDX_BOM_v1_6_URI = "http://cyclonedx.org/schema/bom-1.6.schema.json"
+
 func init() {
 	compiler := jsonschema.NewCompiler()
 	compiler.AssertFormat = true
@@ -38,4 +54,15 @@ func init() {
 		panic(err)
 	}
 	SLSA_Provenance_v0_2 = compiler.MustCompile(SLSA_Provenance_v0_2_URI)
+
+	for uri, schema := range map[string]string{
+		CycloneDX_BOM_v1_6_URI:                             cyclonedx_bom_v1_6_json,
+		"http://cyclonedx.org/schema/spdx.schema.json":     cyclonedx_spdx_json,
+		"http://cyclonedx.org/schema/jsf-0.82.schema.json": cyclonedx_jsf_v0_82_json,
+	} {
+		if err := compiler.AddResource(uri, strings.NewReader(schema)); err != nil {
+			panic(err)
+		}
+	}
+	CycloneDX_BOM_v1_6 = compiler.MustCompile(CycloneDX_BOM_v1_6_URI)
 }
@@ -0,0 +1,79 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// Package sink allows programs embedding ec to deliver the rendered reports
+// to destinations of their own, e.g. a database or a message queue. Sinks are
+// registered by name and are selected in output targets using the sink:
+// scheme, e.g. --output json=sink:<name>, so that they never take the place
+// of a file with the same name.
+package sink
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// Scheme is the prefix of the path of output targets delivered to a sink
+const Scheme = "sink:"
+
+// ReportSink delivers a rendered report. It is invoked once for each requested
+// output format with the bytes of the report rendered in that format.
+type ReportSink interface {
+	Write(ctx context.Context, format string, data []byte) error
+}
+
+// SinkFunc allows a plain function to be used as a ReportSink.
+type SinkFunc func(ctx context.Context, format string, data []byte) error
+
+func (f SinkFunc) Write(ctx context.Context, format string, data []byte) error {
+	return f(ctx, format, data)
+}
+
+var (
+	mu    sync.RWMutex
+	sinks = map[string]ReportSink{}
+)
+
+// Register makes the sink available under the given name, targets with the
+// path sink:<name> are delivered to it. An error is returned if the name is
+// empty or if a sink with the same name is already registered.
+func Register(name string, s ReportSink) error {
+	if name == "" {
+		return errors.New("the name of the sink must not be empty")
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	if _, ok := sinks[name]; ok {
+		return fmt.Errorf("a sink with the name %q is already registered", name)
+	}
+	sinks[name] = s
+
+	return nil
+}
+
+// Lookup returns the sink registered under the given name.
+func Lookup(name string) (ReportSink, bool) {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	s, ok := sinks[name]
+
+	return s, ok
+}
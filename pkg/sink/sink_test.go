@@ -0,0 +1,53 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package sink
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegister(t *testing.T) {
+	var received string
+	s := SinkFunc(func(_ context.Context, format string, data []byte) error {
+		received = format + ":" + string(data)
+		return nil
+	})
+
+	require.NoError(t, Register("test-register", s))
+	t.Cleanup(func() {
+		mu.Lock()
+		defer mu.Unlock()
+		delete(sinks, "test-register")
+	})
+
+	found, ok := Lookup("test-register")
+	require.True(t, ok)
+	require.NoError(t, found.Write(context.Background(), "json", []byte("{}")))
+	assert.Equal(t, "json:{}", received)
+
+	assert.EqualError(t, Register("test-register", s), `a sink with the name "test-register" is already registered`)
+	assert.EqualError(t, Register("", s), "the name of the sink must not be empty")
+
+	_, ok = Lookup("missing")
+	assert.False(t, ok)
+}